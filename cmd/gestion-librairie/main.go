@@ -1,11 +1,25 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
 
 	"github.com/felver-dev/bookstore/internal/cli"
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/portail"
+	"github.com/felver-dev/bookstore/internal/rapports"
 	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
 	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/synchro"
+	"github.com/felver-dev/bookstore/internal/tui"
+	"github.com/felver-dev/bookstore/internal/validators"
 )
 
 func main() {
@@ -13,25 +27,334 @@ func main() {
 	// INITIALISATION DE L'APPLICATION
 	// ========================================
 
+	// 0. Charger la configuration (fichier, variables d'environnement, options)
+	// L'application refuse de démarrer si la configuration est invalide
+	cfg, err := config.Charger(os.Args[1:])
+	if err != nil {
+		log.Fatal("Erreur de configuration : ", err)
+	}
+
 	// 1. Créer les systèmes de stockage pour chaque type de données
 	// Chaque service aura son propre fichier JSON
-	stockageLivres := storage.NewJSONStorage("data/livres.json")
-	stockageMembres := storage.NewJSONStorage("data/membres.json")
-	stockageEmprunts := storage.NewJSONStorage("data/emprunts.json")
+	stockageLivres := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Livres), storage.SCHEMA_LIVRES)
+	stockageMembres := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Membres), storage.SCHEMA_MEMBRES)
+	stockageEmprunts := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Emprunts), storage.SCHEMA_EMPRUNTS)
+	stockageGenres := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Genres), storage.SCHEMA_GENRES)
+	stockageContributeurs := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Contributeurs), storage.SCHEMA_CONTRIBUTEURS)
+	stockageSeries := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Series), storage.SCHEMA_SERIES)
+	stockageReservations := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Reservations), storage.SCHEMA_RESERVATIONS)
+	stockageRecherches := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Recherches), storage.SCHEMA_RECHERCHES)
+	stockageAcquisitions := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Acquisitions), storage.SCHEMA_ACQUISITIONS)
+	stockageInventaires := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Inventaires), storage.SCHEMA_INVENTAIRES)
+	journalEmprunts := storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal))
+	stockageInstantanes := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Instantanes), storage.SCHEMA_INSTANTANES)
+	stockageSuccursales := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Succursales), storage.SCHEMA_SUCCURSALES)
+	stockageSynchro := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Synchro), storage.SCHEMA_SYNCHRO)
+	stockagePEB := storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.PEB), storage.SCHEMA_PEB)
+
+	// Les derniers numéros attribués sont gardés à part : un numéro n'est jamais
+	// redonné, même après la purge de l'enregistrement qui le portait
+	sequences, err := storage.NewSequences(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Sequences), storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		log.Fatal("Erreur de chargement des numéros : ", err)
+	}
 
 	// 2. Créer les services (la logique métier de notre application)
 	// Ces services contiennent toutes les règles de gestion de la librairie
-	gestionnaireL := services.NouveauGestionnaireLivres(stockageLivres)
-	gestionnaireM := services.NouveauGestionnaireMembres(stockageMembres)
-	gestionnaireE := services.NouveauGestionnaireEmprunts(stockageEmprunts, gestionnaireL, gestionnaireM)
+	validateur := validators.NouveauValidateur(cfg.Validation.AnneePublicationMin)
+	gestionnaireSU := services.NouveauGestionnaireSuccursales(stockageSuccursales, sequences)
+	if cfg.Succursale != "" {
+		if err := gestionnaireSU.ChoisirSuccursale(cfg.Succursale); err != nil {
+			log.Fatal("Erreur de configuration : ", err)
+		}
+	}
+	gestionnaireG := services.NouveauGestionnaireGenres(stockageGenres, sequences, cfg.Validation.Genres)
+	gestionnaireC := services.NouveauGestionnaireContributeurs(stockageContributeurs, sequences)
+	gestionnaireL := services.NouveauGestionnaireLivres(stockageLivres, sequences, validateur, gestionnaireG, gestionnaireC, gestionnaireSU)
+	gestionnaireM := services.NouveauGestionnaireMembres(stockageMembres, sequences, cfg.Emprunts.LimiteSimultanes, gestionnaireSU)
+	gestionnaireR := services.NouveauGestionnaireReservations(stockageReservations, sequences, gestionnaireL, gestionnaireM, cfg.Emprunts.DelaiRetraitJours)
+	gestionnaireE, err := services.NouveauGestionnaireEmprunts(stockageEmprunts, journalEmprunts, stockageInstantanes, sequences, gestionnaireL, gestionnaireM, gestionnaireR, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err != nil {
+		log.Fatal("Erreur de chargement des emprunts : ", err)
+	}
+	gestionnaireS := services.NouveauGestionnaireSeries(stockageSeries, sequences, gestionnaireL)
+	gestionnaireRC := services.NouveauGestionnaireRecherches(stockageRecherches)
+	gestionnaireA := services.NouveauGestionnaireAcquisitions(stockageAcquisitions, sequences, gestionnaireL)
+	gestionnaireI := services.NouveauGestionnaireInventaires(stockageInventaires, sequences, gestionnaireL, gestionnaireR)
+	gestionnairePEB := services.NouveauGestionnairePEB(stockagePEB, sequences, gestionnaireE)
+	gestionnaireSY := services.NouveauGestionnaireSynchro(stockageSynchro, gestionnaireE)
+	for _, url := range cfg.Synchro.Pairs {
+		if gestionnaireSY.TrouverPair(url) == nil {
+			if _, err := gestionnaireSY.AjouterPair(url); err != nil {
+				log.Fatal("Erreur de configuration : ", err)
+			}
+		}
+	}
+
+	// Avec -rapport, le programme produit le rapport demandé sans ouvrir d'interface
+	// (pratique dans une tâche planifiée en début de mois)
+	if cfg.Rapport != "" {
+		genererRapport(cfg, gestionnaireE)
+		return
+	}
+
+	// Avec -verifier (ou -reparer), le programme contrôle les données puis s'arrête ;
+	// le code de sortie vaut 1 s'il reste des corrections à appliquer
+	if cfg.Verifier || cfg.Reparer {
+		os.Exit(verifierIntegrite(gestionnaireE, cfg.Reparer))
+	}
+
+	// Avec -synchroniser, le poste échange ses événements avec chacun de ses
+	// pairs puis s'arrête ; le code de sortie vaut 1 si un pair n'a pas répondu
+	if cfg.Synchroniser {
+		os.Exit(synchroniserPairs(cfg, gestionnaireSY))
+	}
+
+	// Des numéros en double (fichiers fusionnés ou copiés à la main) rendent
+	// ambigus les codes-barres et les cartes : on le signale dès le démarrage
+	if doublons := gestionnaireE.VerifierIntegrite().Doublons(); doublons > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d identifiant(s) en double dans les données : lancez le programme avec -verifier pour les voir, -reparer pour les renuméroter\n", doublons)
+	}
+	if conflits := gestionnaireSY.ConflitsATraiter(); conflits > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d livre(s) prêté(s) sur deux postes à la fois : voir le menu 🔄 Synchronisation entre postes\n", conflits)
+	}
 
 	// 3. Créer l'interface utilisateur en ligne de commande
 	// Elle va utiliser tous les services pour offrir un menu complet
-	cliApp := cli.NewCLI(gestionnaireL, gestionnaireM, gestionnaireE)
+	cliApp := cli.NewCLI(cfg, gestionnaireL, gestionnaireM, gestionnaireE, gestionnaireR, gestionnaireG, gestionnaireC, gestionnaireS, gestionnaireRC, gestionnaireA, gestionnaireI, gestionnaireSU, gestionnaireSY, gestionnairePEB)
+
+	// Le portail des membres et la synchronisation tournent à côté de l'écran du
+	// comptoir. Tous se partagent un verrou : l'écran le tient, sauf pendant
+	// qu'il attend une saisie.
+	var verrou sync.Locker
+	synchroEnFond := len(cfg.Synchro.Pairs) > 0 && cfg.Synchro.DelaiSecondes > 0
+	if cfg.Portail.Adresse != "" || cfg.Synchro.Adresse != "" || synchroEnFond {
+		verrou = &sync.Mutex{}
+		verrou.Lock()
+	}
+	if cfg.Synchro.Adresse != "" {
+		defer demarrerSynchro(cfg, gestionnaireSY, verrou).Close()
+	}
+	if synchroEnFond {
+		go synchroniserEnFond(cfg, gestionnaireSY, verrou)
+	}
+	if cfg.Portail.Adresse != "" {
+		serveur := demarrerPortail(cfg, gestionnaireL, gestionnaireM, gestionnaireE, gestionnaireR, gestionnaireRC, verrou)
+
+		// Sans écran au comptoir, le portail est servi jusqu'à l'arrêt du programme
+		if cfg.Interface == config.INTERFACE_PORTAIL {
+			verrou.Unlock()
+			select {}
+		}
+		defer serveur.Close()
+	}
+
+	// Le libre-service remplace toute l'interface du personnel : aucun menu
+	// d'administration n'y est accessible et Ctrl-C ne permet pas d'en sortir
+	if cfg.Interface == config.INTERFACE_KIOSQUE {
+		demarrerKiosque(cfg, gestionnaireL, gestionnaireM, gestionnaireE, verrou)
+		return
+	}
 
 	// 4. Démarrer l'application
+	// Un script remplace le clavier ; les saisies sont réécrites dans la sortie
+	// pour qu'elle se lise comme une session interactive
+	if cfg.Script != "" {
+		fichier, err := os.Open(cfg.Script)
+		if err != nil {
+			log.Fatal("Erreur d'ouverture du script : ", err)
+		}
+		defer fichier.Close()
+		cliApp.UtiliserConsole(cli.NouvelleConsole(fichier, os.Stdout, true))
+	} else if !tui.EstTerminal(os.Stdin) {
+		cliApp.UtiliserConsole(cli.NouvelleConsole(os.Stdin, os.Stdout, true))
+	} else {
+		cliApp.MasquerSaisieAvec(masquerTerminal)
+	}
+	if verrou != nil {
+		cliApp.PartagerVerrou(verrou)
+	}
+
+	// L'interface plein écran a besoin d'un vrai terminal : si l'entrée est redirigée
+	// (script, tube), on retombe sur les menus numérotés
+	if cfg.Script == "" && cfg.Interface == config.INTERFACE_PLEIN_ECRAN && tui.EstTerminal(os.Stdin) {
+		app := tui.NouvelleApp(gestionnaireL, gestionnaireM, gestionnaireE, gestionnaireG, validateur)
+		if verrou != nil {
+			app.PartagerVerrou(verrou)
+		}
+		if err := app.Run(cliApp.Run); err != nil {
+			log.Fatal("Erreur de l'interface plein écran :", err)
+		}
+		return
+	}
+
 	// Si une erreur se produit, on arrête le programme
 	if err := cliApp.Run(); err != nil {
 		log.Fatal("Erreur lors du démarrage :", err)
 	}
 }
+
+// demarrerKiosque lance le libre-service sur le terminal (ou sur le script fourni)
+func demarrerKiosque(cfg *config.Config, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts, verrou sync.Locker) {
+	kiosque := cli.NouveauKiosque(cfg, gl, gm, ge)
+
+	if cfg.Script != "" {
+		fichier, err := os.Open(cfg.Script)
+		if err != nil {
+			log.Fatal("Erreur d'ouverture du script : ", err)
+		}
+		defer fichier.Close()
+		kiosque.UtiliserConsole(cli.NouvelleConsole(fichier, os.Stdout, true))
+	} else if tui.EstTerminal(os.Stdin) {
+		// Les touches de contrôle ne font plus sortir du libre-service ;
+		// à défaut (système non pris en charge), Ctrl-C est au moins ignoré
+		if deverrouiller, err := tui.VerrouillerTerminal(os.Stdin); err == nil {
+			defer deverrouiller()
+		} else {
+			signal.Ignore(os.Interrupt)
+		}
+		kiosque.MasquerSaisieAvec(masquerTerminal)
+	} else {
+		kiosque.UtiliserConsole(cli.NouvelleConsole(os.Stdin, os.Stdout, true))
+	}
+
+	if verrou != nil {
+		kiosque.PartagerVerrou(verrou)
+	}
+
+	if err := kiosque.Run(); err != nil {
+		log.Fatal("Erreur du libre-service :", err)
+	}
+}
+
+// demarrerPortail ouvre l'adresse d'écoute avant de lancer l'interface, pour
+// signaler tout de suite un port déjà pris, puis sert le portail en arrière-plan
+func demarrerPortail(cfg *config.Config, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts, gr *services.GestionnaireReservations, grc *services.GestionnaireRecherches, verrou sync.Locker) *http.Server {
+	ecouteur, err := net.Listen("tcp", cfg.Portail.Adresse)
+	if err != nil {
+		log.Fatal("Impossible de démarrer le portail des membres : ", err)
+	}
+
+	serveur := &http.Server{
+		Handler:           portail.NouveauServeur(cfg, gl, gm, ge, gr, grc, verrou).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := serveur.Serve(ecouteur); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Erreur du portail des membres : ", err)
+		}
+	}()
+
+	fmt.Printf("🌐 Portail des membres : http://%s/\n", ecouteur.Addr())
+	return serveur
+}
+
+// demarrerSynchro ouvre le service de synchronisation, auquel les autres
+// postes viennent chercher les événements de celui-ci et déposer les leurs
+func demarrerSynchro(cfg *config.Config, gsy *services.GestionnaireSynchro, verrou sync.Locker) *http.Server {
+	ecouteur, err := net.Listen("tcp", cfg.Synchro.Adresse)
+	if err != nil {
+		log.Fatal("Impossible de démarrer la synchronisation : ", err)
+	}
+
+	serveur := &http.Server{
+		Handler:           synchro.NouveauServeur(gsy, cfg.Synchro.Cle, verrou).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := serveur.Serve(ecouteur); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Erreur de la synchronisation : ", err)
+		}
+	}()
+
+	fmt.Printf("🔄 Synchronisation entre postes : http://%s/ (poste %s)\n", ecouteur.Addr(), gsy.Instance())
+	return serveur
+}
+
+// synchroniserEnFond synchronise le poste avec chacun de ses pairs à intervalle
+// régulier. Un pair injoignable n'interrompt rien : l'erreur est notée et
+// visible dans le menu de synchronisation, l'échange reprendra au tour suivant.
+func synchroniserEnFond(cfg *config.Config, gsy *services.GestionnaireSynchro, verrou sync.Locker) {
+	for {
+		for _, url := range cfg.Synchro.Pairs {
+			synchro.Synchroniser(gsy, url, cfg.Synchro.Cle, verrou)
+		}
+		time.Sleep(time.Duration(cfg.Synchro.DelaiSecondes) * time.Second)
+	}
+}
+
+// synchroniserPairs échange les événements avec chaque pair de la
+// configuration (option -synchroniser) et retourne le code de sortie
+func synchroniserPairs(cfg *config.Config, gsy *services.GestionnaireSynchro) int {
+	code := 0
+	for _, url := range cfg.Synchro.Pairs {
+		resultat, err := synchro.Synchroniser(gsy, url, cfg.Synchro.Cle, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s : %v\n", url, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("✅ %s : %d événement(s) reçu(s), %d envoyé(s), %d livre(s) et %d membre(s) ajoutés\n",
+			url, resultat.Recus.Integres, resultat.Envoyes.Integres, resultat.Recus.Livres, resultat.Recus.Membres)
+		for _, conflit := range append(resultat.Recus.Conflits, resultat.Envoyes.Conflits...) {
+			fmt.Printf("⚠️  %s\n", conflit)
+		}
+		for _, code := range append(resultat.Recus.CodesEnDouble, resultat.Envoyes.CodesEnDouble...) {
+			fmt.Printf("⚠️  %s porté par deux livres ou membres : étiquette ou carte à refaire\n", code)
+		}
+	}
+	return code
+}
+
+// genererRapport écrit le rapport HTML et PDF de la période donnée par -rapport ;
+// « - » désigne le mois dernier
+func genererRapport(cfg *config.Config, ge *services.GestionnaireEmprunts) {
+	saisie := cfg.Rapport
+	if saisie == "-" {
+		saisie = ""
+	}
+	periode, err := statistiques.LirePeriode(saisie, time.Now())
+	if err != nil {
+		log.Fatal("Erreur : ", err)
+	}
+
+	chemins, err := rapports.Collecter(periode, ge).Enregistrer(cfg.Rapports.Dossier)
+	if err != nil {
+		log.Fatal("Erreur lors de la génération du rapport : ", err)
+	}
+	for _, chemin := range chemins {
+		fmt.Printf("📄 %s\n", chemin)
+	}
+}
+
+// verifierIntegrite affiche le contrôle d'intégrité et, si demandé, applique
+// les corrections ; il retourne le code de sortie du programme. Les anomalies
+// non réparables (historique d'un livre purgé...) sont seulement signalées.
+func verifierIntegrite(ge *services.GestionnaireEmprunts, reparer bool) int {
+	rapport := ge.VerifierIntegrite()
+	cli.AfficherRapportIntegrite(os.Stdout, rapport)
+	if rapport.Reparables() == 0 {
+		return 0
+	}
+
+	if !reparer {
+		fmt.Println("\nℹ️  Rien n'a été modifié : relancez avec -reparer pour appliquer ces corrections.")
+		return 1
+	}
+
+	rapport, err := ge.ReparerIntegrite()
+	if err != nil {
+		log.Fatal("Erreur lors de la réparation : ", err)
+	}
+	fmt.Printf("\n✅ %d correction(s) appliquée(s).\n", rapport.Reparables())
+	return 0
+}
+
+// masquerTerminal coupe l'écho du terminal le temps de saisir un code PIN
+func masquerTerminal() func() {
+	restaurer, err := tui.MasquerSaisie(os.Stdin)
+	if err != nil {
+		return func() {}
+	}
+	return restaurer
+}
@@ -0,0 +1,208 @@
+package affichage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// FORMATS DE SORTIE
+// ========================================
+
+const (
+	FORMAT_TABLEAU  = "tableau"
+	FORMAT_CSV      = "csv"
+	FORMAT_JSON     = "json"
+	FORMAT_MARKDOWN = "markdown"
+)
+
+// FORMATS liste les formats disponibles, dans l'ordre de présentation
+var FORMATS = []string{FORMAT_TABLEAU, FORMAT_CSV, FORMAT_JSON, FORMAT_MARKDOWN}
+
+// Format rend un tableau de résultats sur une sortie
+type Format interface {
+	Rendre(w io.Writer, t *Tableau) error
+}
+
+// NouveauFormat retourne le format demandé ; largeur ne concerne que le format tableau
+func NouveauFormat(nom string, largeur int) (Format, error) {
+	switch nom {
+	case FORMAT_TABLEAU:
+		return formatTableau{largeur: largeur}, nil
+	case FORMAT_CSV:
+		return formatCSV{}, nil
+	case FORMAT_JSON:
+		return formatJSON{}, nil
+	case FORMAT_MARKDOWN:
+		return formatMarkdown{}, nil
+	}
+	return nil, fmt.Errorf("format inconnu '%s' (formats disponibles : %s)", nom, strings.Join(FORMATS, ", "))
+}
+
+// ========================================
+// TABLEAU EN TEXTE (BORDURES)
+// ========================================
+
+type formatTableau struct {
+	largeur int
+}
+
+func (f formatTableau) Rendre(w io.Writer, t *Tableau) error {
+	largeurs := t.largeursColonnes(f.largeur)
+
+	bordure := func(gauche, milieu, droite string) string {
+		segments := make([]string, len(largeurs))
+		for i, l := range largeurs {
+			segments[i] = strings.Repeat("─", l+2)
+		}
+		return gauche + strings.Join(segments, milieu) + droite + "\n"
+	}
+
+	ligne := func(cellules []string) string {
+		contenus := make([]string, len(cellules))
+		for i, cellule := range cellules {
+			if t.Colonnes[i].Numerique {
+				contenus[i] = AjusterADroite(cellule, largeurs[i])
+			} else {
+				contenus[i] = Ajuster(cellule, largeurs[i])
+			}
+		}
+		return "│ " + strings.Join(contenus, " │ ") + " │\n"
+	}
+
+	titres := make([]string, len(t.Colonnes))
+	for i, colonne := range t.Colonnes {
+		titres[i] = colonne.Titre
+	}
+
+	var sortie strings.Builder
+	sortie.WriteString(bordure("┌", "┬", "┐"))
+	sortie.WriteString(ligne(titres))
+	sortie.WriteString(bordure("├", "┼", "┤"))
+	for _, cellules := range t.Lignes {
+		sortie.WriteString(ligne(cellules))
+	}
+	sortie.WriteString(bordure("└", "┴", "┘"))
+
+	_, err := io.WriteString(w, sortie.String())
+	return err
+}
+
+// ========================================
+// CSV
+// ========================================
+
+type formatCSV struct{}
+
+func (formatCSV) Rendre(w io.Writer, t *Tableau) error {
+	ecrivain := csv.NewWriter(w)
+
+	titres := make([]string, len(t.Colonnes))
+	for i, colonne := range t.Colonnes {
+		titres[i] = colonne.Titre
+	}
+	if err := ecrivain.Write(titres); err != nil {
+		return err
+	}
+	if err := ecrivain.WriteAll(t.Lignes); err != nil {
+		return err
+	}
+	return ecrivain.Error()
+}
+
+// ========================================
+// JSON (tableau d'objets, un champ par colonne)
+// ========================================
+
+type formatJSON struct{}
+
+// objetJSON conserve l'ordre des colonnes dans les objets produits
+type objetJSON struct {
+	colonnes []Colonne
+	cellules []string
+}
+
+func (o objetJSON) MarshalJSON() ([]byte, error) {
+	var sortie strings.Builder
+	sortie.WriteString("{")
+	for i, colonne := range o.colonnes {
+		cle := colonne.Cle
+		if cle == "" {
+			cle = colonne.Titre
+		}
+		var valeur interface{} = o.cellules[i]
+		if colonne.Numerique {
+			if n, err := strconv.ParseFloat(o.cellules[i], 64); err == nil {
+				valeur = n
+			}
+		}
+
+		cleJSON, err := json.Marshal(cle)
+		if err != nil {
+			return nil, err
+		}
+		valeurJSON, err := json.Marshal(valeur)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			sortie.WriteString(",")
+		}
+		sortie.Write(cleJSON)
+		sortie.WriteString(":")
+		sortie.Write(valeurJSON)
+	}
+	sortie.WriteString("}")
+	return []byte(sortie.String()), nil
+}
+
+func (formatJSON) Rendre(w io.Writer, t *Tableau) error {
+	objets := make([]objetJSON, 0, len(t.Lignes))
+	for _, cellules := range t.Lignes {
+		objets = append(objets, objetJSON{colonnes: t.Colonnes, cellules: cellules})
+	}
+
+	encodeur := json.NewEncoder(w)
+	encodeur.SetIndent("", "  ")
+	return encodeur.Encode(objets)
+}
+
+// ========================================
+// MARKDOWN
+// ========================================
+
+type formatMarkdown struct{}
+
+func (formatMarkdown) Rendre(w io.Writer, t *Tableau) error {
+	echapper := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+	}
+
+	var sortie strings.Builder
+	titres := make([]string, len(t.Colonnes))
+	separateurs := make([]string, len(t.Colonnes))
+	for i, colonne := range t.Colonnes {
+		titres[i] = echapper(colonne.Titre)
+		separateurs[i] = "---"
+		if colonne.Numerique {
+			separateurs[i] = "--:"
+		}
+	}
+	sortie.WriteString("| " + strings.Join(titres, " | ") + " |\n")
+	sortie.WriteString("|" + strings.Join(separateurs, "|") + "|\n")
+
+	for _, cellules := range t.Lignes {
+		contenus := make([]string, len(cellules))
+		for i, cellule := range cellules {
+			contenus[i] = echapper(cellule)
+		}
+		sortie.WriteString("| " + strings.Join(contenus, " | ") + " |\n")
+	}
+
+	_, err := io.WriteString(w, sortie.String())
+	return err
+}
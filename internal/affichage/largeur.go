@@ -0,0 +1,139 @@
+package affichage
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ========================================
+// LARGEUR D'AFFICHAGE DU TEXTE
+// Un caractère n'occupe pas toujours une colonne : les idéogrammes et la plupart
+// des emoji en occupent deux, les accents combinants et les sélecteurs de variante aucune
+// ========================================
+
+const (
+	selecteurEmoji     = '\uFE0F' // demande l'affichage en emoji (donc sur deux colonnes)
+	lienSansChasse     = '\u200D' // relie plusieurs emoji en un seul pictogramme
+	pointsDeSuspension = "…"
+)
+
+// plagesLarges liste les caractères affichés sur deux colonnes
+// (East Asian Width W et F, et emoji en présentation graphique)
+var plagesLarges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+	{0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+	{0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+	{0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A}, {0x1F200, 0x1F251}, {0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x3FFFD},
+}
+
+func largeurRune(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+
+	for _, plage := range plagesLarges {
+		if r < plage[0] {
+			break
+		}
+		if r <= plage[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// graphemes découpe le texte en groupes affichés comme un seul caractère
+// (caractère de base suivi de ses accents, sélecteurs de variante et emoji liés),
+// avec la largeur de chaque groupe
+func graphemes(s string) ([]string, []int) {
+	var groupes []string
+	var largeurs []int
+
+	for i := 0; i < len(s); {
+		r, taille := utf8.DecodeRuneInString(s[i:])
+		debut := i
+		largeur := largeurRune(r)
+		i += taille
+
+	suite:
+		for i < len(s) {
+			suivant, tailleSuivant := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case suivant == selecteurEmoji:
+				if largeur == 1 {
+					largeur = 2
+				}
+			case suivant == lienSansChasse:
+				// Le pictogramme lié fait partie du même groupe
+				if i+tailleSuivant < len(s) {
+					_, tailleLie := utf8.DecodeRuneInString(s[i+tailleSuivant:])
+					tailleSuivant += tailleLie
+				}
+			case largeurRune(suivant) != 0:
+				break suite
+			}
+			i += tailleSuivant
+		}
+		groupes = append(groupes, s[debut:i])
+		largeurs = append(largeurs, largeur)
+	}
+
+	return groupes, largeurs
+}
+
+// Largeur retourne le nombre de colonnes occupées par le texte dans un terminal
+func Largeur(s string) int {
+	total := 0
+	_, largeurs := graphemes(s)
+	for _, l := range largeurs {
+		total += l
+	}
+	return total
+}
+
+// Tronquer coupe le texte pour qu'il tienne dans la largeur donnée, en terminant par « … ».
+// La coupe se fait toujours entre deux caractères affichés.
+func Tronquer(s string, largeur int) string {
+	if largeur <= 0 {
+		return ""
+	}
+	if Largeur(s) <= largeur {
+		return s
+	}
+
+	groupes, largeurs := graphemes(s)
+	var resultat strings.Builder
+	occupe := 0
+	for i, groupe := range groupes {
+		if occupe+largeurs[i] > largeur-1 {
+			break
+		}
+		resultat.WriteString(groupe)
+		occupe += largeurs[i]
+	}
+	return resultat.String() + pointsDeSuspension
+}
+
+// Ajuster tronque ou complète le texte avec des espaces pour occuper exactement la largeur
+func Ajuster(s string, largeur int) string {
+	s = Tronquer(s, largeur)
+	return s + strings.Repeat(" ", largeur-Largeur(s))
+}
+
+// AjusterADroite fait de même en alignant le texte à droite (nombres)
+func AjusterADroite(s string, largeur int) string {
+	s = Tronquer(s, largeur)
+	return strings.Repeat(" ", largeur-Largeur(s)) + s
+}
@@ -0,0 +1,174 @@
+package affichage
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	eAccentCombinant = "e\u0301"                                    // « é » écrit avec un accent combinant
+	famille          = "\U0001F468\u200D\U0001F469\u200D\U0001F467" // famille : trois emoji liés par des ZWJ
+	coeur            = "\u2764\uFE0F"                               // cœur : caractère étroit suivi du sélecteur emoji
+)
+
+func TestPlagesLargesTriees(t *testing.T) {
+	// largeurRune s'arrête à la première plage qui commence après le caractère
+	for i, plage := range plagesLarges {
+		if plage[0] > plage[1] {
+			t.Errorf("plage %d inversée : %X-%X", i, plage[0], plage[1])
+		}
+		if i > 0 && plage[0] <= plagesLarges[i-1][1] {
+			t.Errorf("plage %X-%X pas après %X-%X", plage[0], plage[1], plagesLarges[i-1][0], plagesLarges[i-1][1])
+		}
+	}
+}
+
+func TestLargeurRune(t *testing.T) {
+	cas := []struct {
+		r       rune
+		largeur int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'\t', 0},
+		{'\u0085', 0}, // contrôle C1
+		{'\u0301', 0}, // accent aigu combinant
+		{'\u20DD', 0}, // cercle englobant
+		{'\u200D', 0}, // liant sans chasse
+		{'\u00AD', 0}, // trait d'union conditionnel
+		{'日', 2},
+		{'한', 2},
+		{'ア', 2},
+		{'Ａ', 2}, // pleine chasse
+		{'ｱ', 1}, // demi-chasse
+		{'📚', 2},
+		{'🧹', 2},
+		{'⌚', 2},      // première plage après les jamos
+		{'\u1100', 2}, // bornes d'une plage
+		{'\u115F', 2},
+		{'\u1160', 1},
+		{'\U00020000', 2}, // idéogramme du plan supplémentaire
+		{'✔', 1},
+		{'\u2764', 1}, // étroit sans sélecteur de variante
+	}
+	for _, c := range cas {
+		if largeur := largeurRune(c.r); largeur != c.largeur {
+			t.Errorf("largeurRune(%U %q) = %d, attendu %d", c.r, c.r, largeur, c.largeur)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	cas := []struct {
+		nom      string
+		texte    string
+		groupes  []string
+		largeurs []int
+	}{
+		{"ASCII", "ab", []string{"a", "b"}, []int{1, 1}},
+		{"accent combinant", eAccentCombinant + "t", []string{eAccentCombinant, "t"}, []int{1, 1}},
+		{"plusieurs accents", "a\u0301\u0323b", []string{"a\u0301\u0323", "b"}, []int{1, 1}},
+		{"idéogrammes", "日本", []string{"日", "本"}, []int{2, 2}},
+		{"emoji lié par ZWJ", "a" + famille + "b", []string{"a", famille, "b"}, []int{1, 2, 1}},
+		{"sélecteur de variante", coeur + "!", []string{coeur, "!"}, []int{2, 1}},
+		{"sélecteur sur un emoji déjà large", "📚\uFE0F", []string{"📚\uFE0F"}, []int{2}},
+		{"ZWJ en fin de texte", "📚\u200D", []string{"📚\u200D"}, []int{2}},
+		{"accent seul en début de texte", "\u0301a", []string{"\u0301", "a"}, []int{0, 1}},
+		{"texte vide", "", nil, nil},
+	}
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			groupes, largeurs := graphemes(c.texte)
+			if !reflect.DeepEqual(groupes, c.groupes) || !reflect.DeepEqual(largeurs, c.largeurs) {
+				t.Errorf("graphemes(%q) = %q %v, attendu %q %v", c.texte, groupes, largeurs, c.groupes, c.largeurs)
+			}
+		})
+	}
+}
+
+func TestLargeur(t *testing.T) {
+	cas := []struct {
+		texte   string
+		largeur int
+	}{
+		{"", 0},
+		{"Camus", 5},
+		{"L'" + eAccentCombinant + "tranger", 10},
+		{"L'Étranger", 10},
+		{"東京物語", 8},
+		{"📚 Livres", 9},
+		{famille, 2},
+		{coeur + coeur, 4},
+		{"\U0001F5D1\uFE0F  Retirer", 11}, // \U0001F5D1 étroit devient large avec FE0F
+	}
+	for _, c := range cas {
+		if largeur := Largeur(c.texte); largeur != c.largeur {
+			t.Errorf("Largeur(%q) = %d, attendu %d", c.texte, largeur, c.largeur)
+		}
+	}
+}
+
+func TestTronquer(t *testing.T) {
+	cas := []struct {
+		nom     string
+		texte   string
+		largeur int
+		attendu string
+	}{
+		{"tient juste", "Camus", 5, "Camus"},
+		{"une colonne de trop", "Camus!", 5, "Camu…"},
+		{"largeur nulle", "Camus", 0, ""},
+		{"largeur négative", "Camus", -3, ""},
+		{"largeur 1", "Camus", 1, "…"},
+		{"idéogrammes coupés sur une frontière", "東京物語", 5, "東京…"},
+		// Il reste une colonne avant « … » : l'idéogramme suivant n'y tient pas
+		{"idéogramme à cheval sur la limite", "東京物語", 6, "東京…"},
+		{"idéogrammes qui tiennent", "東京物語", 8, "東京物語"},
+		{"accent combinant gardé avec sa lettre", eAccentCombinant + "cole", 3, eAccentCombinant + "c…"},
+		{"emoji lié jamais coupé", "a" + famille + "bc", 3, "a…"},
+		{"emoji lié gardé entier", "a" + famille + "bc", 4, "a" + famille + "…"},
+		{"sélecteur de variante gardé", coeur + "abc", 4, coeur + "a…"},
+		{"sélecteur à cheval sur la limite", "a" + coeur + "bc", 3, "a…"},
+	}
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			obtenu := Tronquer(c.texte, c.largeur)
+			if obtenu != c.attendu {
+				t.Errorf("Tronquer(%q, %d) = %q, attendu %q", c.texte, c.largeur, obtenu, c.attendu)
+			}
+			if c.largeur > 0 && Largeur(obtenu) > c.largeur {
+				t.Errorf("Tronquer(%q, %d) occupe %d colonnes", c.texte, c.largeur, Largeur(obtenu))
+			}
+		})
+	}
+}
+
+func TestAjuster(t *testing.T) {
+	cas := []struct {
+		texte   string
+		largeur int
+		gauche  string
+		droite  string
+	}{
+		{"Zola", 6, "Zola  ", "  Zola"},
+		{"Zola", 4, "Zola", "Zola"},
+		{"Balzac", 4, "Bal…", "Bal…"},
+		// La troncature laisse une colonne libre devant un idéogramme : elle est comblée
+		{"東京物語", 6, "東京… ", " 東京…"},
+		{"東京", 5, "東京 ", " 東京"},
+		{eAccentCombinant + "t" + eAccentCombinant, 5, eAccentCombinant + "t" + eAccentCombinant + "  ", "  " + eAccentCombinant + "t" + eAccentCombinant},
+		{famille, 3, famille + " ", " " + famille},
+		{"", 3, "   ", "   "},
+	}
+	for _, c := range cas {
+		if obtenu := Ajuster(c.texte, c.largeur); obtenu != c.gauche {
+			t.Errorf("Ajuster(%q, %d) = %q, attendu %q", c.texte, c.largeur, obtenu, c.gauche)
+		}
+		if obtenu := AjusterADroite(c.texte, c.largeur); obtenu != c.droite {
+			t.Errorf("AjusterADroite(%q, %d) = %q, attendu %q", c.texte, c.largeur, obtenu, c.droite)
+		}
+		if largeur := Largeur(Ajuster(c.texte, c.largeur)); largeur != c.largeur {
+			t.Errorf("Ajuster(%q, %d) occupe %d colonnes", c.texte, c.largeur, largeur)
+		}
+	}
+}
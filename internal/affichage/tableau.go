@@ -0,0 +1,98 @@
+package affichage
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// TABLEAUX DE RÉSULTATS
+// Un même tableau peut être rendu en texte, CSV, JSON ou Markdown
+// ========================================
+
+const LARGEUR_PAR_DEFAUT = 80
+
+type Colonne struct {
+	Titre string
+	Cle   string // nom du champ en JSON
+
+	// Numerique aligne la colonne à droite et l'exporte comme nombre en JSON
+	Numerique bool
+
+	// Min est la largeur en dessous de laquelle la colonne n'est jamais réduite
+	// quand le tableau doit tenir dans le terminal (0 = largeur du titre)
+	Min int
+}
+
+type Tableau struct {
+	Colonnes []Colonne
+	Lignes   [][]string
+}
+
+// NouveauTableau crée un tableau vide avec ses colonnes
+func NouveauTableau(colonnes ...Colonne) *Tableau {
+	return &Tableau{Colonnes: colonnes}
+}
+
+// AjouterLigne ajoute une ligne ; les cellules manquantes restent vides
+func (t *Tableau) AjouterLigne(cellules ...string) {
+	ligne := make([]string, len(t.Colonnes))
+	copy(ligne, cellules)
+	t.Lignes = append(t.Lignes, ligne)
+}
+
+// LargeurTerminal retourne la largeur disponible pour l'affichage :
+// la variable COLUMNS si elle est définie, sinon la taille du terminal, sinon 80 colonnes
+func LargeurTerminal() int {
+	if colonnes, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && colonnes > 0 {
+		return colonnes
+	}
+	if colonnes, _, err := TailleTerminal(os.Stdout); err == nil && colonnes > 0 {
+		return colonnes
+	}
+	return LARGEUR_PAR_DEFAUT
+}
+
+// largeursColonnes calcule la largeur de chaque colonne pour que le tableau
+// (bordures comprises) tienne dans la largeur donnée. Les colonnes les plus larges
+// sont réduites en premier, sans descendre sous leur minimum.
+func (t *Tableau) largeursColonnes(largeurMax int) []int {
+	largeurs := make([]int, len(t.Colonnes))
+	minimums := make([]int, len(t.Colonnes))
+	total := 3*len(t.Colonnes) + 1 // "│ " + " │ " entre colonnes + " │"
+
+	for i, colonne := range t.Colonnes {
+		largeurs[i] = Largeur(colonne.Titre)
+		for _, ligne := range t.Lignes {
+			if l := Largeur(ligne[i]); l > largeurs[i] {
+				largeurs[i] = l
+			}
+		}
+
+		minimums[i] = colonne.Min
+		if minimums[i] == 0 {
+			minimums[i] = Largeur(colonne.Titre)
+		}
+		if minimums[i] > largeurs[i] {
+			minimums[i] = largeurs[i]
+		}
+		total += largeurs[i]
+	}
+
+	for total > largeurMax {
+		plusLarge := -1
+		for i := range largeurs {
+			if largeurs[i] > minimums[i] && (plusLarge < 0 || largeurs[i] > largeurs[plusLarge]) {
+				plusLarge = i
+			}
+		}
+		if plusLarge < 0 {
+			break // impossible de réduire davantage : le terminal repliera les lignes
+		}
+		largeurs[plusLarge]--
+		total--
+	}
+
+	return largeurs
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package affichage
+
+import (
+	"fmt"
+	"os"
+)
+
+// TailleTerminal n'est pas disponible sur ce système : LargeurTerminal se rabat sur $COLUMNS
+func TailleTerminal(f *os.File) (int, int, error) {
+	return 0, 0, fmt.Errorf("taille du terminal indisponible")
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package affichage
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// TailleTerminal retourne le nombre de colonnes et de lignes du terminal associé au fichier
+func TailleTerminal(f *os.File) (int, int, error) {
+	var taille struct {
+		Lignes, Colonnes, X, Y uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&taille)))
+	if errno != 0 {
+		return 0, 0, errno
+	}
+	return int(taille.Colonnes), int(taille.Lignes), nil
+}
@@ -0,0 +1,469 @@
+// ==========================================
+// internal/analyses/analyses.go
+// INDICATEURS DE LA BIBLIOTHÈQUE
+// ==========================================
+
+package analyses
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// ========================================
+// INDICATEURS
+// Calculés à partir de l'historique des emprunts, sur une période.
+// Le fonds considéré est le catalogue actuel (livres retirés exclus).
+// ========================================
+
+// Analyse regroupe tous les indicateurs d'une période ; elle se sérialise en JSON
+type Analyse struct {
+	Periode        statistiques.Periode `json:"periode"`
+	CalculeLe      time.Time            `json:"calcule_le"`
+	MoisInactivite int                  `json:"mois_inactivite"`
+
+	RotationTitres []RotationTitre `json:"rotation_titres"`
+	RotationGenres []RotationGenre `json:"rotation_genres"`
+	LivresDormants []LivreDormant  `json:"livres_dormants"`
+	DelaisRetour   []DelaiRetour   `json:"delais_retour"`
+	Cohortes       []Cohorte       `json:"cohortes"`
+	Affluence      Affluence       `json:"affluence"`
+	Circulation    Circulation     `json:"circulation"`
+}
+
+// RotationTitre compte les emprunts d'un titre rapportés à ses exemplaires
+// (les livres de même ISBN sont des exemplaires du même titre)
+type RotationTitre struct {
+	Titre       string  `json:"titre"`
+	Auteur      string  `json:"auteur"`
+	ISBN        string  `json:"isbn"`
+	Exemplaires int     `json:"exemplaires"`
+	Emprunts    int     `json:"emprunts"`
+	Rotation    float64 `json:"rotation"` // emprunts par exemplaire sur la période
+}
+
+// RotationGenre compte les emprunts d'un genre principal rapportés à ses livres
+type RotationGenre struct {
+	Genre    string  `json:"genre"`
+	Livres   int     `json:"livres"`
+	Emprunts int     `json:"emprunts"`
+	Rotation float64 `json:"rotation"`
+}
+
+// LivreDormant n'a pas été emprunté depuis au moins MoisInactivite mois
+// alors qu'il est au catalogue depuis au moins aussi longtemps
+type LivreDormant struct {
+	LivreID         int        `json:"livre_id"`
+	Titre           string     `json:"titre"`
+	Genre           string     `json:"genre"`
+	DateAjout       time.Time  `json:"date_ajout"`
+	DernierEmprunt  *time.Time `json:"dernier_emprunt"`          // nil : jamais emprunté, ou emprunts effacés de l'historique
+	EmprunteAvant   *time.Time `json:"emprunte_avant,omitempty"` // emprunts effacés par le nettoyage : le dernier date d'avant ce jour
+	MoisSansEmprunt int        `json:"mois_sans_emprunt"`        // au moins, si les emprunts ont été effacés
+}
+
+// DelaiRetour mesure le temps mis à rendre les livres d'un genre, retours de la période
+type DelaiRetour struct {
+	Genre        string  `json:"genre"`
+	Retours      int     `json:"retours"`
+	MoyenneJours float64 `json:"moyenne_jours"`
+	EnRetard     int     `json:"en_retard"`
+}
+
+// Cohorte regroupe les membres inscrits le même mois ; Echeances compte leurs
+// emprunts à rendre pendant la période (déjà rendus, ou dont la date est passée)
+type Cohorte struct {
+	Mois      string  `json:"mois"` // AAAA-MM d'inscription
+	Membres   int     `json:"membres"`
+	Echeances int     `json:"echeances"`
+	ALHeure   int     `json:"a_l_heure"`
+	Taux      float64 `json:"taux"` // pourcentage de retours à l'heure
+}
+
+// Affluence répartit les emprunts de la période par jour de la semaine et par heure
+type Affluence struct {
+	ParJour  []Creneau `json:"par_jour"`  // du lundi au dimanche
+	ParHeure []Creneau `json:"par_heure"` // de 0 h à 23 h
+	Pointes  []Creneau `json:"pointes"`   // les créneaux jour + heure les plus chargés
+}
+
+// Creneau compte les emprunts d'un jour, d'une heure ou des deux
+type Creneau struct {
+	Libelle  string `json:"libelle"`
+	Emprunts int    `json:"emprunts"`
+}
+
+// Circulation mesure la part du fonds qui sort
+type Circulation struct {
+	Fonds       int     `json:"fonds"`
+	EnPret      int     `json:"en_pret"`      // actuellement empruntés
+	PartEnPret  float64 `json:"part_en_pret"` // pourcentage du fonds
+	Sortis      int     `json:"sortis"`       // livres empruntés au moins une fois sur la période
+	PartSortie  float64 `json:"part_sortie"`  // pourcentage du fonds
+	PartMoyenne float64 `json:"part_moyenne"` // part du fonds en prêt, en moyenne sur les jours de la période
+}
+
+// POINTES_MAX limite la liste des créneaux les plus chargés
+const POINTES_MAX = 5
+
+var joursSemaine = [7]string{"lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi", "dimanche"}
+
+// Analyser calcule tous les indicateurs de la période. Un livre est dormant s'il
+// n'a pas été emprunté depuis moisInactivite mois (à compter d'aujourd'hui).
+func Analyser(periode statistiques.Periode, moisInactivite int, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts) Analyse {
+	maintenant := time.Now()
+	catalogue := gl.ListerLivres()
+	emprunts := ge.ListerEmprunts()
+
+	livres := make(map[int]models.Livre, len(catalogue))
+	for _, livre := range catalogue {
+		livres[livre.ID] = livre
+	}
+
+	return Analyse{
+		Periode:        periode,
+		CalculeLe:      maintenant,
+		MoisInactivite: moisInactivite,
+		RotationTitres: rotationTitres(periode, catalogue, emprunts),
+		RotationGenres: rotationGenres(periode, catalogue, livres, emprunts),
+		LivresDormants: livresDormants(maintenant, moisInactivite, catalogue, emprunts, ge.DernierNettoyage()),
+		DelaisRetour:   delaisRetour(periode, gl, emprunts),
+		Cohortes:       cohortes(periode, maintenant, gm, emprunts),
+		Affluence:      affluence(periode, emprunts),
+		Circulation:    circulation(periode, maintenant, livres, emprunts),
+	}
+}
+
+// ========================================
+// ROTATION
+// ========================================
+
+// CleTitre identifie un titre : son ISBN, ou le livre lui-même s'il n'en a pas
+func CleTitre(livre models.Livre) string {
+	if isbn := strings.TrimSpace(livre.ISBN); isbn != "" {
+		return isbn
+	}
+	return fmt.Sprintf("id:%d", livre.ID)
+}
+
+func rotationTitres(periode statistiques.Periode, catalogue []models.Livre, emprunts []models.Emprunt) []RotationTitre {
+	titres := make(map[string]*RotationTitre)
+	cleDuLivre := make(map[int]string, len(catalogue))
+	for _, livre := range catalogue {
+		cle := CleTitre(livre)
+		cleDuLivre[livre.ID] = cle
+		if titres[cle] == nil {
+			titres[cle] = &RotationTitre{Titre: livre.Titre, Auteur: livre.Auteur, ISBN: livre.ISBN}
+		}
+		titres[cle].Exemplaires++
+	}
+
+	for _, emprunt := range emprunts {
+		if cle, ok := cleDuLivre[emprunt.LivreID]; ok && periode.Contient(emprunt.DateEmprunt) {
+			titres[cle].Emprunts++
+		}
+	}
+
+	resultat := make([]RotationTitre, 0, len(titres))
+	for _, titre := range titres {
+		titre.Rotation = float64(titre.Emprunts) / float64(titre.Exemplaires)
+		resultat = append(resultat, *titre)
+	}
+	sort.Slice(resultat, func(i, j int) bool {
+		if resultat[i].Rotation != resultat[j].Rotation {
+			return resultat[i].Rotation > resultat[j].Rotation
+		}
+		return resultat[i].Titre < resultat[j].Titre
+	})
+	return resultat
+}
+
+func rotationGenres(periode statistiques.Periode, catalogue []models.Livre, livres map[int]models.Livre, emprunts []models.Emprunt) []RotationGenre {
+	genres := make(map[string]*RotationGenre)
+	for _, livre := range catalogue {
+		if genres[livre.Genre] == nil {
+			genres[livre.Genre] = &RotationGenre{Genre: livre.Genre}
+		}
+		genres[livre.Genre].Livres++
+	}
+
+	for _, emprunt := range emprunts {
+		if livre, ok := livres[emprunt.LivreID]; ok && periode.Contient(emprunt.DateEmprunt) {
+			genres[livre.Genre].Emprunts++
+		}
+	}
+
+	resultat := make([]RotationGenre, 0, len(genres))
+	for _, genre := range genres {
+		genre.Rotation = float64(genre.Emprunts) / float64(genre.Livres)
+		resultat = append(resultat, *genre)
+	}
+	sort.Slice(resultat, func(i, j int) bool {
+		if resultat[i].Rotation != resultat[j].Rotation {
+			return resultat[i].Rotation > resultat[j].Rotation
+		}
+		return resultat[i].Genre < resultat[j].Genre
+	})
+	return resultat
+}
+
+// ========================================
+// LIVRES DORMANTS
+// ========================================
+
+// livresDormants retient les livres sans emprunt depuis moisInactivite mois.
+// nettoyage est la date limite du dernier nettoyage de l'historique (zéro s'il
+// n'y en a pas eu) : les emprunts terminés commencés avant ont quitté la liste.
+func livresDormants(maintenant time.Time, moisInactivite int, catalogue []models.Livre, emprunts []models.Emprunt, nettoyage time.Time) []LivreDormant {
+	limite := maintenant.AddDate(0, -moisInactivite, 0)
+
+	dernierEmprunt := make(map[int]time.Time)
+	for _, emprunt := range emprunts {
+		if emprunt.DateEmprunt.After(dernierEmprunt[emprunt.LivreID]) {
+			dernierEmprunt[emprunt.LivreID] = emprunt.DateEmprunt
+		}
+	}
+
+	resultat := []LivreDormant{}
+	for _, livre := range catalogue {
+		// RÈGLE MÉTIER : un livre arrivé récemment n'a pas encore eu le temps de sortir
+		if livre.DateAjout.After(limite) {
+			continue
+		}
+
+		depuis := livre.DateAjout
+		dormant := LivreDormant{LivreID: livre.ID, Titre: livre.Titre, Genre: livre.Genre, DateAjout: livre.DateAjout}
+		if dernier, ok := dernierEmprunt[livre.ID]; ok {
+			if dernier.After(limite) {
+				continue
+			}
+			dormant.DernierEmprunt = &dernier
+			depuis = dernier
+		} else if livre.EmpruntsArchives > 0 {
+			// RÈGLE MÉTIER : un livre dont les emprunts ont été effacés a été
+			// emprunté avant la date du nettoyage, sans qu'on sache quand ; il
+			// n'est dormant que si cette date est elle-même assez ancienne
+			if nettoyage.IsZero() || nettoyage.After(limite) {
+				continue
+			}
+			dormant.EmprunteAvant = &nettoyage
+			depuis = nettoyage
+		}
+		dormant.MoisSansEmprunt = moisEcoules(depuis, maintenant)
+		resultat = append(resultat, dormant)
+	}
+
+	// Les plus anciens d'abord
+	sort.Slice(resultat, func(i, j int) bool {
+		if resultat[i].MoisSansEmprunt != resultat[j].MoisSansEmprunt {
+			return resultat[i].MoisSansEmprunt > resultat[j].MoisSansEmprunt
+		}
+		return resultat[i].Titre < resultat[j].Titre
+	})
+	return resultat
+}
+
+// moisEcoules compte les mois entiers entre deux dates
+func moisEcoules(debut, fin time.Time) int {
+	mois := (fin.Year()-debut.Year())*12 + int(fin.Month()) - int(debut.Month())
+	if fin.Day() < debut.Day() {
+		mois--
+	}
+	return max(mois, 0)
+}
+
+// ========================================
+// DÉLAIS DE RETOUR ET PONCTUALITÉ
+// ========================================
+
+func delaisRetour(periode statistiques.Periode, gl *services.GestionnaireLivres, emprunts []models.Emprunt) []DelaiRetour {
+	genres := make(map[string]*DelaiRetour)
+	totalJours := make(map[string]float64)
+
+	for _, emprunt := range emprunts {
+		// Un livre perdu n'est pas revenu : il ne compte pas parmi les retours
+		retour := emprunt.DateRetourEffectif
+		if !emprunt.EstRendu() || !periode.Contient(*retour) {
+			continue
+		}
+
+		// Les livres retirés depuis gardent leur genre dans l'historique
+		genre := "Non classé"
+		if livre, _ := gl.TrouverLivreParID(emprunt.LivreID); livre != nil && livre.Genre != "" {
+			genre = livre.Genre
+		}
+		if genres[genre] == nil {
+			genres[genre] = &DelaiRetour{Genre: genre}
+		}
+		genres[genre].Retours++
+		totalJours[genre] += retour.Sub(emprunt.DateEmprunt).Hours() / 24
+		if retour.After(emprunt.DateRetourPrevu) {
+			genres[genre].EnRetard++
+		}
+	}
+
+	resultat := make([]DelaiRetour, 0, len(genres))
+	for nom, genre := range genres {
+		genre.MoyenneJours = totalJours[nom] / float64(genre.Retours)
+		resultat = append(resultat, *genre)
+	}
+	sort.Slice(resultat, func(i, j int) bool {
+		if resultat[i].MoyenneJours != resultat[j].MoyenneJours {
+			return resultat[i].MoyenneJours > resultat[j].MoyenneJours
+		}
+		return resultat[i].Genre < resultat[j].Genre
+	})
+	return resultat
+}
+
+func cohortes(periode statistiques.Periode, maintenant time.Time, gm *services.GestionnaireMembres, emprunts []models.Emprunt) []Cohorte {
+	parMois := make(map[string]*Cohorte)
+	membresVus := make(map[int]bool)
+
+	for _, emprunt := range emprunts {
+		if !periode.Contient(emprunt.DateRetourPrevu) {
+			continue
+		}
+		// Une échéance encore à venir ne dit rien de la ponctualité
+		rendu := emprunt.EstRendu()
+		if emprunt.DateRetourEffectif == nil && emprunt.DateRetourPrevu.After(maintenant) {
+			continue
+		}
+
+		membre, _ := gm.TrouverMembreParID(emprunt.MembreID)
+		if membre == nil {
+			continue
+		}
+		mois := membre.DateInscription.Format("2006-01")
+		if parMois[mois] == nil {
+			parMois[mois] = &Cohorte{Mois: mois}
+		}
+		cohorte := parMois[mois]
+
+		cohorte.Echeances++
+		if rendu && !emprunt.DateRetourEffectif.After(emprunt.DateRetourPrevu) {
+			cohorte.ALHeure++
+		}
+		if !membresVus[membre.ID] {
+			membresVus[membre.ID] = true
+			cohorte.Membres++
+		}
+	}
+
+	resultat := make([]Cohorte, 0, len(parMois))
+	for _, cohorte := range parMois {
+		cohorte.Taux = float64(cohorte.ALHeure) / float64(cohorte.Echeances) * 100
+		resultat = append(resultat, *cohorte)
+	}
+	sort.Slice(resultat, func(i, j int) bool {
+		return resultat[i].Mois < resultat[j].Mois
+	})
+	return resultat
+}
+
+// ========================================
+// AFFLUENCE ET CIRCULATION
+// ========================================
+
+func affluence(periode statistiques.Periode, emprunts []models.Emprunt) Affluence {
+	var parJour [7]int
+	var parHeure [24]int
+	var parCreneau [7][24]int
+
+	for _, emprunt := range emprunts {
+		if !periode.Contient(emprunt.DateEmprunt) {
+			continue
+		}
+		date := emprunt.DateEmprunt.Local()
+		jour := (int(date.Weekday()) + 6) % 7 // lundi = 0
+		parJour[jour]++
+		parHeure[date.Hour()]++
+		parCreneau[jour][date.Hour()]++
+	}
+
+	var resultat Affluence
+	for jour, nombre := range parJour {
+		resultat.ParJour = append(resultat.ParJour, Creneau{Libelle: joursSemaine[jour], Emprunts: nombre})
+	}
+	for heure, nombre := range parHeure {
+		resultat.ParHeure = append(resultat.ParHeure, Creneau{Libelle: fmt.Sprintf("%02d h", heure), Emprunts: nombre})
+	}
+
+	resultat.Pointes = []Creneau{}
+	for jour := range parCreneau {
+		for heure, nombre := range parCreneau[jour] {
+			if nombre > 0 {
+				resultat.Pointes = append(resultat.Pointes, Creneau{
+					Libelle:  fmt.Sprintf("%s %02d h–%02d h", joursSemaine[jour], heure, (heure+1)%24),
+					Emprunts: nombre,
+				})
+			}
+		}
+	}
+	// Tri stable : à égalité, l'ordre de la semaine est conservé
+	sort.SliceStable(resultat.Pointes, func(i, j int) bool {
+		return resultat.Pointes[i].Emprunts > resultat.Pointes[j].Emprunts
+	})
+	if len(resultat.Pointes) > POINTES_MAX {
+		resultat.Pointes = resultat.Pointes[:POINTES_MAX]
+	}
+	return resultat
+}
+
+func circulation(periode statistiques.Periode, maintenant time.Time, livres map[int]models.Livre, emprunts []models.Emprunt) Circulation {
+	resultat := Circulation{Fonds: len(livres)}
+	if resultat.Fonds == 0 {
+		return resultat
+	}
+
+	// La moyenne porte sur les jours écoulés de la période
+	debut, fin := periode.Debut, periode.Fin
+	if fin.IsZero() || fin.After(maintenant) {
+		fin = maintenant
+	}
+	joursPret := 0.0
+
+	enPret, sortis := make(map[int]bool), make(map[int]bool)
+	for _, emprunt := range emprunts {
+		if _, auCatalogue := livres[emprunt.LivreID]; !auCatalogue {
+			continue
+		}
+		if emprunt.DateRetourEffectif == nil {
+			enPret[emprunt.LivreID] = true
+		}
+		if periode.Contient(emprunt.DateEmprunt) {
+			sortis[emprunt.LivreID] = true
+		}
+
+		// Jours passés hors du rayon pendant la période
+		sortie, retour := emprunt.DateEmprunt, maintenant
+		if emprunt.DateRetourEffectif != nil {
+			retour = *emprunt.DateRetourEffectif
+		}
+		if !debut.IsZero() && sortie.Before(debut) {
+			sortie = debut
+		}
+		if retour.After(fin) {
+			retour = fin
+		}
+		if retour.After(sortie) {
+			joursPret += retour.Sub(sortie).Hours() / 24
+		}
+	}
+
+	resultat.EnPret = len(enPret)
+	resultat.Sortis = len(sortis)
+	resultat.PartEnPret = float64(resultat.EnPret) / float64(resultat.Fonds) * 100
+	resultat.PartSortie = float64(resultat.Sortis) / float64(resultat.Fonds) * 100
+	if !debut.IsZero() && fin.After(debut) {
+		joursPeriode := fin.Sub(debut).Hours() / 24
+		resultat.PartMoyenne = joursPret / (joursPeriode * float64(resultat.Fonds)) * 100
+	}
+	return resultat
+}
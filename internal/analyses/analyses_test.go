@@ -0,0 +1,192 @@
+package analyses
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+	"github.com/felver-dev/bookstore/internal/storage"
+)
+
+func date(annee int, mois time.Month, jour int) time.Time {
+	return time.Date(annee, mois, jour, 12, 0, 0, 0, time.UTC)
+}
+
+var annee2026 = statistiques.Periode{Debut: date(2026, 1, 1), Fin: date(2027, 1, 1)}
+
+// catalogueDeTest : Alpha en deux exemplaires, Charlie arrivé récemment, Delta
+// dont les emprunts ont été effacés par le nettoyage de l'historique, Echo jamais
+// emprunté et Fox dont seuls les anciens emprunts ont été effacés
+func catalogueDeTest() []models.Livre {
+	return []models.Livre{
+		{ID: 1, Titre: "Alpha", ISBN: "isbn-a", Genre: "Roman", DateAjout: date(2025, 1, 10)},
+		{ID: 2, Titre: "Alpha", ISBN: "isbn-a", Genre: "Roman", DateAjout: date(2025, 1, 10)},
+		{ID: 3, Titre: "Bravo", ISBN: "isbn-b", Genre: "Policier", DateAjout: date(2025, 3, 1)},
+		{ID: 4, Titre: "Charlie", Genre: "Roman", DateAjout: date(2026, 9, 1)},
+		{ID: 5, Titre: "Delta", ISBN: "isbn-d", Genre: "Poésie", DateAjout: date(2024, 6, 20), NombreEmprunts: 2, EmpruntsArchives: 2},
+		{ID: 6, Titre: "Echo", ISBN: "isbn-e", Genre: "Poésie", DateAjout: date(2024, 6, 20)},
+		{ID: 7, Titre: "Fox", ISBN: "isbn-f", Genre: "Roman", DateAjout: date(2024, 1, 5), NombreEmprunts: 2, EmpruntsArchives: 1},
+	}
+}
+
+// empruntsDeTest : ce qu'il reste de l'historique après le nettoyage
+func empruntsDeTest() []models.Emprunt {
+	return []models.Emprunt{
+		{ID: 11, LivreID: 1, DateEmprunt: date(2026, 2, 10)},
+		{ID: 12, LivreID: 2, DateEmprunt: date(2026, 3, 5)},
+		{ID: 13, LivreID: 1, DateEmprunt: date(2026, 9, 20)},
+		{ID: 14, LivreID: 3, DateEmprunt: date(2025, 12, 20)}, // avant la période
+		{ID: 15, LivreID: 3, DateEmprunt: date(2026, 1, 15)},
+		{ID: 16, LivreID: 7, DateEmprunt: date(2025, 11, 3)}, // avant la période
+		{ID: 17, LivreID: 99, DateEmprunt: date(2026, 5, 1)}, // livre retiré du catalogue
+	}
+}
+
+func TestRotationTitres(t *testing.T) {
+	var obtenu []string
+	for _, titre := range rotationTitres(annee2026, catalogueDeTest(), empruntsDeTest()) {
+		obtenu = append(obtenu, titre.Titre)
+		attendu := map[string][2]float64{ // exemplaires, emprunts
+			"Alpha": {2, 3}, "Bravo": {1, 1}, "Charlie": {1, 0}, "Delta": {1, 0}, "Echo": {1, 0}, "Fox": {1, 0},
+		}[titre.Titre]
+		if float64(titre.Exemplaires) != attendu[0] || float64(titre.Emprunts) != attendu[1] || titre.Rotation != attendu[1]/attendu[0] {
+			t.Errorf("%s : %d exemplaire(s), %d emprunt(s), rotation %.2f ; attendu %v", titre.Titre, titre.Exemplaires, titre.Emprunts, titre.Rotation, attendu)
+		}
+	}
+	// Alpha : 3 emprunts pour 2 exemplaires, Bravo : 1 pour 1, puis les titres sans emprunt
+	if attendu := []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo", "Fox"}; !reflect.DeepEqual(obtenu, attendu) {
+		t.Errorf("ordre %v, attendu %v", obtenu, attendu)
+	}
+}
+
+func TestRotationGenres(t *testing.T) {
+	catalogue := catalogueDeTest()
+	livres := make(map[int]models.Livre)
+	for _, livre := range catalogue {
+		livres[livre.ID] = livre
+	}
+
+	attendu := []RotationGenre{
+		{Genre: "Policier", Livres: 1, Emprunts: 1, Rotation: 1},
+		{Genre: "Roman", Livres: 4, Emprunts: 3, Rotation: 0.75},
+		{Genre: "Poésie", Livres: 2, Emprunts: 0, Rotation: 0},
+	}
+	if obtenu := rotationGenres(annee2026, catalogue, livres, empruntsDeTest()); !reflect.DeepEqual(obtenu, attendu) {
+		t.Errorf("rotation par genre %+v, attendu %+v", obtenu, attendu)
+	}
+}
+
+func TestLivresDormants(t *testing.T) {
+	// Six mois sans emprunt : la limite est le 15/04/2026
+	maintenant := date(2026, 10, 15)
+
+	type dormant struct {
+		titre          string
+		dernier, avant time.Time // zéro : inconnu
+		mois           int
+	}
+	cas := []struct {
+		nom       string
+		nettoyage time.Time
+		attendus  []dormant
+	}{
+		{"historique nettoyé avant la limite", date(2025, 10, 1), []dormant{
+			{"Echo", time.Time{}, time.Time{}, 27}, // jamais emprunté, au catalogue depuis le 20/06/2024
+			{"Delta", time.Time{}, date(2025, 10, 1), 12},
+			{"Fox", date(2025, 11, 3), time.Time{}, 11}, // l'emprunt resté dans l'historique fait foi
+			{"Bravo", date(2026, 1, 15), time.Time{}, 9},
+			{"Alpha", date(2026, 3, 5), time.Time{}, 7}, // le second exemplaire ; le premier est sorti en septembre
+		}},
+		// Delta a pu sortir jusqu'au 01/06/2026, après la limite : rien ne dit qu'il dort
+		{"historique nettoyé après la limite", date(2026, 6, 1), []dormant{
+			{"Echo", time.Time{}, time.Time{}, 27},
+			{"Fox", date(2025, 11, 3), time.Time{}, 11},
+			{"Bravo", date(2026, 1, 15), time.Time{}, 9},
+			{"Alpha", date(2026, 3, 5), time.Time{}, 7},
+		}},
+		// Compteurs archivés sans date de nettoyage connue : Delta n'est pas « jamais emprunté »
+		{"date du nettoyage inconnue", time.Time{}, []dormant{
+			{"Echo", time.Time{}, time.Time{}, 27},
+			{"Fox", date(2025, 11, 3), time.Time{}, 11},
+			{"Bravo", date(2026, 1, 15), time.Time{}, 9},
+			{"Alpha", date(2026, 3, 5), time.Time{}, 7},
+		}},
+	}
+	for _, c := range cas {
+		var obtenus []dormant
+		for _, livre := range livresDormants(maintenant, 6, catalogueDeTest(), empruntsDeTest(), c.nettoyage) {
+			d := dormant{titre: livre.Titre, mois: livre.MoisSansEmprunt}
+			if livre.DernierEmprunt != nil {
+				d.dernier = *livre.DernierEmprunt
+			}
+			if livre.EmprunteAvant != nil {
+				d.avant = *livre.EmprunteAvant
+			}
+			obtenus = append(obtenus, d)
+		}
+		if !reflect.DeepEqual(obtenus, c.attendus) {
+			t.Errorf("%s :\n obtenu  %+v\n attendu %+v", c.nom, obtenus, c.attendus)
+		}
+	}
+}
+
+// membresDeTest ouvre un gestionnaire de membres sur des inscriptions datées
+func membresDeTest(t *testing.T, membres []models.Membre) *services.GestionnaireMembres {
+	t.Helper()
+	dossier := t.TempDir()
+	chemin := func(fichier, schema string) storage.Storage {
+		return storage.NewJSONStorage(filepath.Join(dossier, fichier), schema)
+	}
+	if err := chemin("membres.json", storage.SCHEMA_MEMBRES).Sauvegarder(membres); err != nil {
+		t.Fatal(err)
+	}
+	sq, err := storage.NewSequences(chemin("sequences.json", storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsu := services.NouveauGestionnaireSuccursales(chemin("succursales.json", storage.SCHEMA_SUCCURSALES), sq)
+	return services.NouveauGestionnaireMembres(chemin("membres.json", storage.SCHEMA_MEMBRES), sq, 3, gsu)
+}
+
+func TestCohortes(t *testing.T) {
+	gm := membresDeTest(t, []models.Membre{
+		{ID: 1, Nom: "Zoé Dupont", DateInscription: date(2025, 11, 5), Actif: true},
+		{ID: 2, Nom: "Marc Durand", DateInscription: date(2025, 11, 20), Actif: true},
+		{ID: 3, Nom: "Léa Martin", DateInscription: date(2026, 2, 1), Actif: true},
+		// Ses emprunts ont tous été effacés par le nettoyage : il n'apparaît nulle part
+		{ID: 4, Nom: "Paul Bernard", DateInscription: date(2026, 2, 10), Actif: true, NombreEmprunts: 3, EmpruntsArchives: 3},
+	})
+	rendu := func(jour time.Time) *time.Time { return &jour }
+	emprunts := []models.Emprunt{
+		{ID: 1, MembreID: 1, DateRetourPrevu: date(2026, 3, 1), DateRetourEffectif: rendu(date(2026, 2, 28)), Statut: models.STATUT_RENDU},
+		{ID: 2, MembreID: 1, DateRetourPrevu: date(2026, 5, 1), DateRetourEffectif: rendu(date(2026, 5, 3)), Statut: models.STATUT_RENDU},
+		{ID: 3, MembreID: 2, DateRetourPrevu: date(2026, 6, 1), Statut: models.STATUT_EN_RETARD},
+		{ID: 4, MembreID: 2, DateRetourPrevu: date(2026, 11, 1), Statut: models.STATUT_EN_COURS}, // échéance à venir
+		{ID: 5, MembreID: 2, DateRetourPrevu: date(2026, 7, 1), DateRetourEffectif: rendu(date(2026, 6, 20)), Statut: models.STATUT_PERDU},
+		{ID: 6, MembreID: 3, DateRetourPrevu: date(2026, 4, 1), DateRetourEffectif: rendu(date(2026, 4, 1)), Statut: models.STATUT_RENDU},
+		{ID: 7, MembreID: 3, DateRetourPrevu: date(2025, 12, 1), DateRetourEffectif: rendu(date(2025, 12, 1)), Statut: models.STATUT_RENDU}, // avant la période
+		{ID: 8, MembreID: 99, DateRetourPrevu: date(2026, 3, 1), DateRetourEffectif: rendu(date(2026, 3, 1)), Statut: models.STATUT_RENDU},
+	}
+
+	// Novembre 2025 : 4 échéances, seule la première rendue à temps (le livre
+	// perdu et celui jamais rendu comptent comme en retard) ; février 2026 : 1 sur 1
+	attendu := []Cohorte{
+		{Mois: "2025-11", Membres: 2, Echeances: 4, ALHeure: 1, Taux: 25},
+		{Mois: "2026-02", Membres: 1, Echeances: 1, ALHeure: 1, Taux: 100},
+	}
+	obtenu := cohortes(annee2026, date(2026, 10, 15), gm, emprunts)
+	if len(obtenu) != len(attendu) {
+		t.Fatalf("cohortes %+v, attendu %+v", obtenu, attendu)
+	}
+	for i := range attendu {
+		o, a := obtenu[i], attendu[i]
+		if o.Mois != a.Mois || o.Membres != a.Membres || o.Echeances != a.Echeances || o.ALHeure != a.ALHeure || math.Abs(o.Taux-a.Taux) > 1e-9 {
+			t.Errorf("cohorte %d : %+v, attendu %+v", i, o, a)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// ==========================================
+// internal/analyses/export.go
+// EXPORT DES INDICATEURS (CSV ET JSON)
+// ==========================================
+
+package analyses
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// Enregistrer écrit un CSV par indicateur et un fichier indicateurs.json complet
+// dans <dossier>/indicateurs-<période>/, et retourne les chemins des fichiers créés
+func (a Analyse) Enregistrer(dossier string) ([]string, error) {
+	dossier = filepath.Join(dossier, "indicateurs-"+a.Periode.Nom())
+	if err := os.MkdirAll(dossier, 0755); err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier %s : %v", dossier, err)
+	}
+
+	csv, err := affichage.NouveauFormat(affichage.FORMAT_CSV, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var chemins []string
+	for _, vue := range a.Vues() {
+		chemin := filepath.Join(dossier, vue.Nom+".csv")
+		if err := ecrireFichier(chemin, func(f *os.File) error { return csv.Rendre(f, vue.Tableau) }); err != nil {
+			return chemins, err
+		}
+		chemins = append(chemins, chemin)
+	}
+
+	chemin := filepath.Join(dossier, "indicateurs.json")
+	err = ecrireFichier(chemin, func(f *os.File) error {
+		encodeur := json.NewEncoder(f)
+		encodeur.SetIndent("", "  ")
+		return encodeur.Encode(a)
+	})
+	if err != nil {
+		return chemins, err
+	}
+	return append(chemins, chemin), nil
+}
+
+func ecrireFichier(chemin string, ecrire func(*os.File) error) error {
+	fichier, err := os.Create(chemin)
+	if err != nil {
+		return fmt.Errorf("impossible de créer %s : %v", chemin, err)
+	}
+	err = ecrire(fichier)
+	if errFermeture := fichier.Close(); err == nil {
+		err = errFermeture
+	}
+	if err != nil {
+		return fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// ==========================================
+// internal/analyses/tableaux.go
+// MISE EN TABLEAU DES INDICATEURS
+// ==========================================
+
+package analyses
+
+import (
+	"strconv"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// Vue est un indicateur mis en tableau : le même tableau sert à l'affichage
+// dans le terminal et à l'export CSV
+type Vue struct {
+	Nom     string // nom de fichier, par exemple « rotation-titres »
+	Titre   string
+	Tableau *affichage.Tableau
+}
+
+// Vues retourne tous les indicateurs sous forme de tableaux, dans l'ordre du menu
+func (a Analyse) Vues() []Vue {
+	return []Vue{
+		{"rotation-titres", "Rotation par titre", a.TableauRotationTitres()},
+		{"rotation-genres", "Rotation par genre", a.TableauRotationGenres()},
+		{"livres-dormants", "Livres dormants", a.TableauLivresDormants()},
+		{"delais-retour", "Délai de retour par genre", a.TableauDelaisRetour()},
+		{"cohortes", "Ponctualité par cohorte d'inscription", a.TableauCohortes()},
+		{"affluence-jours", "Emprunts par jour de la semaine", tableauCreneaux("Jour", a.Affluence.ParJour)},
+		{"affluence-heures", "Emprunts par heure", tableauCreneaux("Heure", a.Affluence.ParHeure)},
+		{"circulation", "Part du fonds en circulation", a.TableauCirculation()},
+	}
+}
+
+func deuxDecimales(valeur float64) string {
+	return strconv.FormatFloat(valeur, 'f', 2, 64)
+}
+
+func uneDecimale(valeur float64) string {
+	return strconv.FormatFloat(valeur, 'f', 1, 64)
+}
+
+// TableauRotationTitres liste les titres du plus au moins emprunté, par exemplaire
+func (a Analyse) TableauRotationTitres() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 20},
+		affichage.Colonne{Titre: "Auteur", Cle: "auteur", Min: 12},
+		affichage.Colonne{Titre: "ISBN", Cle: "isbn"},
+		affichage.Colonne{Titre: "Ex.", Cle: "exemplaires", Numerique: true},
+		affichage.Colonne{Titre: "Emprunts", Cle: "emprunts", Numerique: true},
+		affichage.Colonne{Titre: "Rotation", Cle: "rotation", Numerique: true},
+	)
+	for _, titre := range a.RotationTitres {
+		tableau.AjouterLigne(titre.Titre, titre.Auteur, titre.ISBN,
+			strconv.Itoa(titre.Exemplaires), strconv.Itoa(titre.Emprunts), deuxDecimales(titre.Rotation))
+	}
+	return tableau
+}
+
+// TableauRotationGenres liste les genres du plus au moins emprunté, par livre
+func (a Analyse) TableauRotationGenres() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Genre", Cle: "genre", Min: 15},
+		affichage.Colonne{Titre: "Livres", Cle: "livres", Numerique: true},
+		affichage.Colonne{Titre: "Emprunts", Cle: "emprunts", Numerique: true},
+		affichage.Colonne{Titre: "Rotation", Cle: "rotation", Numerique: true},
+	)
+	for _, genre := range a.RotationGenres {
+		tableau.AjouterLigne(genre.Genre, strconv.Itoa(genre.Livres), strconv.Itoa(genre.Emprunts), deuxDecimales(genre.Rotation))
+	}
+	return tableau
+}
+
+// TableauLivresDormants liste les livres qui ne sortent plus, les plus anciens d'abord
+func (a Analyse) TableauLivresDormants() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 20},
+		affichage.Colonne{Titre: "Genre", Cle: "genre", Min: 10},
+		affichage.Colonne{Titre: "Ajouté le", Cle: "date_ajout"},
+		affichage.Colonne{Titre: "Dernier emprunt", Cle: "dernier_emprunt"},
+		affichage.Colonne{Titre: "Mois", Cle: "mois_sans_emprunt", Numerique: true},
+	)
+	for _, livre := range a.LivresDormants {
+		dernier := "jamais"
+		switch {
+		case livre.DernierEmprunt != nil:
+			dernier = livre.DernierEmprunt.Format("02/01/2006")
+		case livre.EmprunteAvant != nil:
+			dernier = "avant le " + livre.EmprunteAvant.Format("02/01/2006")
+		}
+		tableau.AjouterLigne(strconv.Itoa(livre.LivreID), livre.Titre, livre.Genre,
+			livre.DateAjout.Format("02/01/2006"), dernier, strconv.Itoa(livre.MoisSansEmprunt))
+	}
+	return tableau
+}
+
+// TableauDelaisRetour liste les genres du plus lent au plus rapide à revenir
+func (a Analyse) TableauDelaisRetour() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Genre", Cle: "genre", Min: 15},
+		affichage.Colonne{Titre: "Retours", Cle: "retours", Numerique: true},
+		affichage.Colonne{Titre: "Jours (moy.)", Cle: "moyenne_jours", Numerique: true},
+		affichage.Colonne{Titre: "En retard", Cle: "en_retard", Numerique: true},
+	)
+	for _, delai := range a.DelaisRetour {
+		tableau.AjouterLigne(delai.Genre, strconv.Itoa(delai.Retours), uneDecimale(delai.MoyenneJours), strconv.Itoa(delai.EnRetard))
+	}
+	return tableau
+}
+
+// TableauCohortes liste les cohortes d'inscription de la plus ancienne à la plus récente
+func (a Analyse) TableauCohortes() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Inscription", Cle: "mois"},
+		affichage.Colonne{Titre: "Membres", Cle: "membres", Numerique: true},
+		affichage.Colonne{Titre: "Échéances", Cle: "echeances", Numerique: true},
+		affichage.Colonne{Titre: "À l'heure", Cle: "a_l_heure", Numerique: true},
+		affichage.Colonne{Titre: "Taux (%)", Cle: "taux", Numerique: true},
+	)
+	for _, cohorte := range a.Cohortes {
+		tableau.AjouterLigne(cohorte.Mois, strconv.Itoa(cohorte.Membres), strconv.Itoa(cohorte.Echeances),
+			strconv.Itoa(cohorte.ALHeure), uneDecimale(cohorte.Taux))
+	}
+	return tableau
+}
+
+func tableauCreneaux(titre string, creneaux []Creneau) *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: titre, Cle: "libelle", Min: 10},
+		affichage.Colonne{Titre: "Emprunts", Cle: "emprunts", Numerique: true},
+	)
+	for _, creneau := range creneaux {
+		tableau.AjouterLigne(creneau.Libelle, strconv.Itoa(creneau.Emprunts))
+	}
+	return tableau
+}
+
+// TableauPointes liste les créneaux (jour et heure) les plus chargés
+func (a Analyse) TableauPointes() *affichage.Tableau {
+	return tableauCreneaux("Créneau", a.Affluence.Pointes)
+}
+
+// TableauCirculation présente la circulation comme une liste d'indicateurs
+func (a Analyse) TableauCirculation() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Indicateur", Cle: "indicateur", Min: 30},
+		affichage.Colonne{Titre: "Valeur", Cle: "valeur", Numerique: true},
+	)
+	c := a.Circulation
+	tableau.AjouterLigne("Livres au catalogue", strconv.Itoa(c.Fonds))
+	tableau.AjouterLigne("Actuellement empruntés", strconv.Itoa(c.EnPret))
+	tableau.AjouterLigne("Part du fonds en prêt (%)", uneDecimale(c.PartEnPret))
+	tableau.AjouterLigne("Livres sortis sur la période", strconv.Itoa(c.Sortis))
+	tableau.AjouterLigne("Part du fonds sortie (%)", uneDecimale(c.PartSortie))
+	if !a.Periode.Debut.IsZero() {
+		tableau.AjouterLigne("Part moyenne en prêt (%)", uneDecimale(c.PartMoyenne))
+	}
+	return tableau
+}
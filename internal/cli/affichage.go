@@ -0,0 +1,46 @@
+// ==========================================
+// internal/cli/affichage.go
+// RENDU DES LISTES (TABLEAU, CSV, JSON, MARKDOWN)
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// afficherResultats rend un tableau dans le format choisi.
+// Le total n'accompagne que le format tableau pour que les autres formats
+// restent directement exploitables (copier-coller, redirection).
+func (cli *CLI) afficherResultats(tableau *affichage.Tableau, total string) {
+	largeur := cli.config.Affichage.Largeur
+	if largeur == 0 {
+		largeur = affichage.LargeurTerminal()
+	}
+
+	format, err := affichage.NouveauFormat(cli.format, largeur)
+	if err != nil {
+		cli.AfficherErreur(err.Error())
+		return
+	}
+
+	fmt.Fprintln(cli.sortie)
+	if err := format.Rendre(cli.sortie, tableau); err != nil {
+		cli.AfficherErreur(fmt.Sprintf("Erreur d'affichage : %v", err))
+		return
+	}
+
+	if cli.format == affichage.FORMAT_TABLEAU && total != "" {
+		fmt.Fprintf(cli.sortie, "\n%s\n", total)
+	}
+}
+
+// choisirFormat change le format des listes pour le reste de la session
+func (cli *CLI) choisirFormat() {
+	cli.AfficherTitre("🖨️ FORMAT DES LISTES")
+	choix := cli.LireChoixDansListe("Formats disponibles :", affichage.FORMATS)
+	cli.format = affichage.FORMATS[choix]
+	cli.AfficherSucces(fmt.Sprintf("Les listes seront affichées au format %s.", cli.format))
+}
@@ -3,9 +3,11 @@ package cli
 import (
 	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ========================================
@@ -13,30 +15,192 @@ import (
 // Ces fonctions gèrent toutes les interactions avec l'utilisateur
 // ========================================
 
+// Console regroupe l'entrée et la sortie de l'interface.
+// Un seul lecteur tamponné est partagé par toutes les saisies : les lignes
+// déjà lues ne sont jamais perdues, même quand l'entrée est un fichier ou un tube.
+type Console struct {
+	entree *bufio.Reader
+	sortie io.Writer
+
+	// echo réécrit chaque ligne lue après l'invite, pour que la sortie d'un script
+	// se lise comme une session au clavier
+	echo bool
+
+	// lignes reçoit les lignes lues en arrière-plan, dès qu'une saisie avec délai
+	// a été demandée : la lecture bloquante ne peut pas être interrompue autrement
+	lignes chan lecture
+
+	// masquer coupe l'écho du terminal le temps d'une saisie secrète (nil : pas de terminal)
+	masquer func() (restaurer func())
+
+	// verrou protège les données partagées avec le portail des membres : la console
+	// le tient sauf pendant qu'elle attend une saisie (nil : pas de partage)
+	verrou sync.Locker
+}
+
+type lecture struct {
+	ligne string
+	err   error
+}
+
+// finDeSaisie signale que l'entrée est épuisée. Elle remonte jusqu'à Run
+// sous forme de panique pour interrompre proprement les boucles de saisie.
+type finDeSaisie struct{}
+
+// NouvelleConsole crée une console sur l'entrée et la sortie données
+func NouvelleConsole(entree io.Reader, sortie io.Writer, echo bool) *Console {
+	return &Console{
+		entree: bufio.NewReader(entree),
+		sortie: sortie,
+		echo:   echo,
+	}
+}
+
+// MasquerSaisieAvec fournit la fonction qui coupe l'écho du terminal pour les codes PIN
+func (c *Console) MasquerSaisieAvec(masquer func() (restaurer func())) {
+	c.masquer = masquer
+}
+
+// PartagerVerrou relâche le verrou donné pendant chaque attente de saisie ;
+// l'appelant doit le tenir avant de lancer les menus
+func (c *Console) PartagerVerrou(verrou sync.Locker) {
+	c.verrou = verrou
+}
+
+// attendre relâche le verrou partagé et retourne la fonction qui le reprend
+func (c *Console) attendre() (reprendre func()) {
+	if c.verrou == nil {
+		return func() {}
+	}
+	c.verrou.Unlock()
+	return c.verrou.Lock
+}
+
 // LireEntree lit une ligne d'entrée utilisateur et supprime les espaces
-func LireEntree() string {
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return strings.TrimSpace(scanner.Text())
+func (c *Console) LireEntree() string {
+	return c.terminerLecture(c.lireLigne(), false)
+}
+
+// LireEntreeAvecDelai lit une ligne comme LireEntree, mais abandonne au bout du délai.
+// Le booléen est faux si rien n'a été saisi à temps.
+func (c *Console) LireEntreeAvecDelai(message string, delai time.Duration) (string, bool) {
+	fmt.Fprint(c.sortie, message)
+	return c.lireAvecDelai(delai, false)
+}
+
+// LireSecret lit un code sans l'afficher à l'écran ni dans la sortie d'un script
+func (c *Console) LireSecret(message string) string {
+	fmt.Fprint(c.sortie, message)
+	if c.masquer != nil {
+		defer c.masquer()()
+	}
+	return c.terminerLecture(c.lireLigne(), true)
+}
+
+// LireSecretAvecDelai lit un code comme LireSecret, mais abandonne au bout du délai
+func (c *Console) LireSecretAvecDelai(message string, delai time.Duration) (string, bool) {
+	fmt.Fprint(c.sortie, message)
+	if c.masquer != nil {
+		defer c.masquer()()
+	}
+	return c.lireAvecDelai(delai, true)
+}
+
+func (c *Console) lireAvecDelai(delai time.Duration, secret bool) (string, bool) {
+	c.demarrerLectureDeFond()
+
+	l, ok := c.attendreLigne(delai)
+	if !ok {
+		fmt.Fprintln(c.sortie)
+		return "", false
+	}
+	return c.terminerLecture(l, secret), true
+}
+
+func (c *Console) attendreLigne(delai time.Duration) (lecture, bool) {
+	defer c.attendre()()
+
+	select {
+	case l, ok := <-c.lignes:
+		if !ok {
+			l = lecture{err: io.EOF}
+		}
+		return l, true
+	case <-time.After(delai):
+		return lecture{}, false
+	}
+}
+
+// demarrerLectureDeFond confie la lecture de l'entrée à une goroutine ;
+// toutes les saisies suivantes passent alors par le canal
+func (c *Console) demarrerLectureDeFond() {
+	if c.lignes != nil {
+		return
+	}
+
+	c.lignes = make(chan lecture)
+	go func() {
+		for {
+			ligne, err := c.entree.ReadString('\n')
+			c.lignes <- lecture{ligne, err}
+			if err != nil {
+				close(c.lignes)
+				return
+			}
+		}
+	}()
+}
+
+func (c *Console) lireLigne() lecture {
+	defer c.attendre()()
+
+	if c.lignes == nil {
+		ligne, err := c.entree.ReadString('\n')
+		return lecture{ligne, err}
+	}
+
+	l, ok := <-c.lignes
+	if !ok {
+		return lecture{err: io.EOF}
+	}
+	return l
+}
+
+func (c *Console) terminerLecture(l lecture, secret bool) string {
+	if l.err != nil && l.ligne == "" {
+		// Plus rien à lire : inutile de reposer la question
+		fmt.Fprintln(c.sortie)
+		panic(finDeSaisie{})
+	}
+
+	ligne := strings.TrimSpace(l.ligne)
+	if c.echo {
+		if secret {
+			fmt.Fprintln(c.sortie, strings.Repeat("•", len([]rune(ligne))))
+		} else {
+			fmt.Fprintln(c.sortie, ligne)
+		}
+	}
+	return ligne
 }
 
 // LireEntreeObligatoire lit une entrée qui ne peut pas être vide
 // Continue à demander tant que l'utilisateur n'entre rien
-func LireEntreeObligatoire(message string) string {
+func (c *Console) LireEntreeObligatoire(message string) string {
 	for {
-		fmt.Print(message)
-		entree := LireEntree()
+		fmt.Fprint(c.sortie, message)
+		entree := c.LireEntree()
 		if entree != "" {
 			return entree
 		}
-		fmt.Println("❌ Cette information est obligatoire.")
+		fmt.Fprintln(c.sortie, "❌ Cette information est obligatoire.")
 	}
 }
 
 // LireEntreeEntier lit un nombre entier avec validation
-func LireEntreeEntier(message string) (int, error) {
-	fmt.Print(message)
-	entreeStr := LireEntree()
+func (c *Console) LireEntreeEntier(message string) (int, error) {
+	fmt.Fprint(c.sortie, message)
+	entreeStr := c.LireEntree()
 
 	if entreeStr == "" {
 		return 0, fmt.Errorf("aucune valeur saisie")
@@ -52,88 +216,88 @@ func LireEntreeEntier(message string) (int, error) {
 }
 
 // LireEntreeEntierObligatoire lit un entier obligatoire avec validation
-func LireEntreeEntierObligatoire(message string) int {
+func (c *Console) LireEntreeEntierObligatoire(message string) int {
 	for {
-		valeur, err := LireEntreeEntier(message)
+		valeur, err := c.LireEntreeEntier(message)
 		if err == nil {
 			return valeur
 		}
-		fmt.Printf("❌ Erreur : %s\n", err.Error())
+		fmt.Fprintf(c.sortie, "❌ Erreur : %s\n", err.Error())
 	}
 }
 
 // LireEntreeEntierAvecLimites lit un entier dans une plage donnée
-func LireEntreeEntierAvecLimites(message string, min, max int) int {
+func (c *Console) LireEntreeEntierAvecLimites(message string, min, max int) int {
 	for {
-		valeur := LireEntreeEntierObligatoire(message)
+		valeur := c.LireEntreeEntierObligatoire(message)
 		if valeur >= min && valeur <= max {
 			return valeur
 		}
-		fmt.Printf("❌ La valeur doit être entre %d et %d.\n", min, max)
+		fmt.Fprintf(c.sortie, "❌ La valeur doit être entre %d et %d.\n", min, max)
 	}
 }
 
 // LireConfirmation demande une confirmation oui/non
-func LireConfirmation(message string) bool {
-	fmt.Print(message + " (oui/non) : ")
-	reponse := strings.ToLower(LireEntree())
+func (c *Console) LireConfirmation(message string) bool {
+	fmt.Fprint(c.sortie, message+" (oui/non) : ")
+	reponse := strings.ToLower(c.LireEntree())
 
 	// Accepter plusieurs variantes de "oui"
 	return reponse == "oui" || reponse == "o" || reponse == "yes" || reponse == "y"
 }
 
 // LireChoixDansListe affiche une liste d'options et demande à l'utilisateur de choisir
-func LireChoixDansListe(message string, options []string) int {
-	fmt.Println(message)
+func (c *Console) LireChoixDansListe(message string, options []string) int {
+	fmt.Fprintln(c.sortie, message)
 	for i, option := range options {
-		fmt.Printf("%d. %s\n", i+1, option)
+		fmt.Fprintf(c.sortie, "%d. %s\n", i+1, option)
 	}
 
-	return LireEntreeEntierAvecLimites("Votre choix : ", 1, len(options)) - 1 // Retourner l'index (0-based)
+	return c.LireEntreeEntierAvecLimites("Votre choix : ", 1, len(options)) - 1 // Retourner l'index (0-based)
 }
 
 // AfficherSeparateur affiche une ligne de séparation visuelle
-func AfficherSeparateur(caractere string, longueur int) {
-	fmt.Println(strings.Repeat(caractere, longueur))
+func (c *Console) AfficherSeparateur(caractere string, longueur int) {
+	fmt.Fprintln(c.sortie, strings.Repeat(caractere, longueur))
 }
 
 // AfficherTitre affiche un titre encadré
-func AfficherTitre(titre string) {
+func (c *Console) AfficherTitre(titre string) {
 	longueur := len(titre) + 4
 	if longueur < 40 {
 		longueur = 40
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", longueur))
-	fmt.Printf("  %s\n", titre)
-	fmt.Println(strings.Repeat("=", longueur))
+	fmt.Fprintln(c.sortie, "\n"+strings.Repeat("=", longueur))
+	fmt.Fprintf(c.sortie, "  %s\n", titre)
+	fmt.Fprintln(c.sortie, strings.Repeat("=", longueur))
 }
 
 // AfficherSucces affiche un message de succès avec un emoji
-func AfficherSucces(message string) {
-	fmt.Printf("\n✅ %s\n", message)
+func (c *Console) AfficherSucces(message string) {
+	fmt.Fprintf(c.sortie, "\n✅ %s\n", message)
 }
 
 // AfficherErreur affiche un message d'erreur avec un emoji
-func AfficherErreur(message string) {
-	fmt.Printf("\n❌ %s\n", message)
+func (c *Console) AfficherErreur(message string) {
+	fmt.Fprintf(c.sortie, "\n❌ %s\n", message)
 }
 
 // AfficherInfo affiche un message d'information avec un emoji
-func AfficherInfo(message string) {
-	fmt.Printf("\nℹ️  %s\n", message)
+func (c *Console) AfficherInfo(message string) {
+	fmt.Fprintf(c.sortie, "\nℹ️  %s\n", message)
 }
 
 // AfficherAvertissement affiche un avertissement avec un emoji
-func AfficherAvertissement(message string) {
-	fmt.Printf("\n⚠️  %s\n", message)
+func (c *Console) AfficherAvertissement(message string) {
+	fmt.Fprintf(c.sortie, "\n⚠️  %s\n", message)
 }
 
 // AttendreEntree affiche un message et attend que l'utilisateur appuie sur Entrée
-func AttendreEntree(message string) {
+func (c *Console) AttendreEntree(message string) {
 	if message == "" {
 		message = "Appuyez sur Entrée pour continuer..."
 	}
-	fmt.Println(message)
-	LireEntree()
+	fmt.Fprintln(c.sortie, message)
+	c.LireEntree()
 }
@@ -0,0 +1,307 @@
+// ==========================================
+// internal/cli/kiosque.go
+// LIBRE-SERVICE : EMPRUNTS, RETOURS ET PROLONGATIONS PAR LES MEMBRES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// KIOSQUE
+// Aucun menu d'administration n'est accessible : le membre s'identifie avec
+// sa carte et son code PIN, n'agit que sur ses propres emprunts, et la session
+// se ferme d'elle-même après un délai sans saisie.
+// ========================================
+
+type Kiosque struct {
+	*Console
+
+	config               *config.Config
+	gestionnaireLivres   *services.GestionnaireLivres
+	gestionnaireMembres  *services.GestionnaireMembres
+	gestionnaireEmprunts *services.GestionnaireEmprunts
+}
+
+// NouveauKiosque crée le libre-service sur l'entrée et la sortie standard
+func NouveauKiosque(cfg *config.Config, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts) *Kiosque {
+	return &Kiosque{
+		Console: NouvelleConsole(os.Stdin, os.Stdout, false),
+
+		config:               cfg,
+		gestionnaireLivres:   gl,
+		gestionnaireMembres:  gm,
+		gestionnaireEmprunts: ge,
+	}
+}
+
+// UtiliserConsole remplace l'entrée et la sortie standard
+func (k *Kiosque) UtiliserConsole(console *Console) {
+	k.Console = console
+}
+
+// Run accueille les membres les uns après les autres jusqu'à la fin de l'entrée
+func (k *Kiosque) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(finDeSaisie); !ok {
+				panic(r)
+			}
+			fmt.Fprintln(k.sortie, "👋 Libre-service arrêté.")
+			err = nil
+		}
+	}()
+
+	for {
+		k.AfficherTitre("📚 LIBRE-SERVICE - EMPRUNTS ET RETOURS")
+		fmt.Fprintln(k.sortie, "Scannez votre carte de membre pour commencer.")
+		fmt.Fprint(k.sortie, "\n💳 Carte : ")
+
+		// L'écran d'accueil attend sans limite de temps
+		carte := k.LireEntree()
+		if carte == "" {
+			continue
+		}
+
+		pin, ok := k.LireSecretAvecDelai("🔢 Code PIN : ", k.delai())
+		if !ok {
+			k.AfficherAvertissement("Délai dépassé, veuillez recommencer.")
+			continue
+		}
+
+		membre, err := k.gestionnaireMembres.AuthentifierMembre(carte, pin)
+		if err != nil {
+			k.AfficherErreur(err.Error())
+			continue
+		}
+
+		k.session(membre)
+	}
+}
+
+func (k *Kiosque) delai() time.Duration {
+	return time.Duration(k.config.Kiosque.DelaiSecondes) * time.Second
+}
+
+// session propose les opérations au membre identifié et se termine
+// par le reçu, que le membre quitte ou que le délai soit dépassé
+func (k *Kiosque) session(membre *models.Membre) {
+	recu := nouveauRecu(membre)
+	defer k.terminerSession(membre, recu)
+
+	for {
+		k.AfficherTitre(fmt.Sprintf("👋 Bonjour %s", membre.Nom))
+		k.afficherEmpruntsEnCours(membre.ID)
+
+		fmt.Fprintln(k.sortie, "\n1. 📚 Emprunter des livres")
+		fmt.Fprintln(k.sortie, "2. 📤 Rendre des livres")
+		fmt.Fprintln(k.sortie, "3. 📅 Prolonger un emprunt")
+		fmt.Fprintln(k.sortie, "0. 🚪 Terminer et imprimer le reçu")
+		k.AfficherSeparateur("-", 50)
+
+		choix, ok := k.LireEntreeAvecDelai("Votre choix : ", k.delai())
+		if !ok {
+			k.signalerInactivite()
+			return
+		}
+
+		actif := true
+		switch choix {
+		case "1":
+			actif = k.emprunter(membre, recu)
+		case "2":
+			actif = k.rendre(membre, recu)
+		case "3":
+			actif = k.prolonger(membre, recu)
+		case "0":
+			return
+		default:
+			k.AfficherErreur("Choix invalide.")
+		}
+
+		if !actif {
+			k.signalerInactivite()
+			return
+		}
+	}
+}
+
+func (k *Kiosque) signalerInactivite() {
+	k.AfficherAvertissement(fmt.Sprintf("Session fermée après %d secondes d'inactivité.", k.config.Kiosque.DelaiSecondes))
+}
+
+// afficherEmpruntsEnCours liste les emprunts du membre avec leur date de retour
+func (k *Kiosque) afficherEmpruntsEnCours(membreID int) []models.Emprunt {
+	enCours := k.empruntsEnCours(membreID)
+	if len(enCours) == 0 {
+		fmt.Fprintln(k.sortie, "Vous n'avez aucun emprunt en cours.")
+		return nil
+	}
+
+	fmt.Fprintln(k.sortie, "Vos emprunts en cours :")
+	for i, emprunt := range enCours {
+		ligne := fmt.Sprintf("%d. %s — à rendre le %s", i+1, emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006"))
+		if emprunt.EstEnRetard() {
+			ligne += " ⚠️  EN RETARD"
+		}
+		fmt.Fprintln(k.sortie, ligne)
+	}
+	return enCours
+}
+
+func (k *Kiosque) empruntsEnCours(membreID int) []models.Emprunt {
+	var enCours []models.Emprunt
+	for _, emprunt := range k.gestionnaireEmprunts.ListerEmpruntsParMembre(membreID) {
+		if emprunt.DateRetourEffectif == nil {
+			enCours = append(enCours, emprunt)
+		}
+	}
+	return enCours
+}
+
+// emprunter prête chaque livre scanné ; retourne faux si le délai est dépassé
+func (k *Kiosque) emprunter(membre *models.Membre, recu *recu) bool {
+	k.AfficherTitre("📚 EMPRUNTER")
+	fmt.Fprintln(k.sortie, "Scannez vos livres un par un, puis validez une ligne vide.")
+
+	for {
+		code, ok := k.LireEntreeAvecDelai("📖 Livre : ", k.delai())
+		if !ok {
+			return false
+		}
+		if code == "" {
+			return true
+		}
+
+		emprunt, err := k.gestionnaireEmprunts.EmprunterParCode(code, membre.NumeroCarte)
+		if err != nil {
+			fmt.Fprintf(k.sortie, "   ❌ %s\n", err.Error())
+			continue
+		}
+
+		retour := emprunt.DateRetourPrevu.Format("02/01/2006")
+		fmt.Fprintf(k.sortie, "   ✅ %s — à rendre le %s\n", emprunt.TitreLivre, retour)
+		recu.ajouter("EMPRUNT", emprunt.TitreLivre, "à rendre le "+retour)
+	}
+}
+
+// rendre enregistre le retour de chaque livre scanné, s'il est emprunté par le membre
+func (k *Kiosque) rendre(membre *models.Membre, recu *recu) bool {
+	k.AfficherTitre("📤 RENDRE")
+	fmt.Fprintln(k.sortie, "Scannez les livres rendus un par un, puis validez une ligne vide.")
+
+	for {
+		code, ok := k.LireEntreeAvecDelai("📖 Livre : ", k.delai())
+		if !ok {
+			return false
+		}
+		if code == "" {
+			return true
+		}
+
+		emprunt, err := k.retournerPourMembre(code, membre)
+		if err != nil {
+			fmt.Fprintf(k.sortie, "   ❌ %s\n", err.Error())
+			continue
+		}
+
+		detail := ""
+		if emprunt.DateRetourEffectif.After(emprunt.DateRetourPrevu) {
+			detail = "rendu en retard"
+		}
+		fmt.Fprintf(k.sortie, "   ✅ %s — merci !\n", emprunt.TitreLivre)
+		if k.gestionnaireEmprunts.MiseDeCote(emprunt.LivreID) != nil {
+			fmt.Fprintln(k.sortie, "   📌 Ce livre est réservé : déposez-le à l'accueil, pas en rayon.")
+		}
+		recu.ajouter("RETOUR", emprunt.TitreLivre, detail)
+	}
+}
+
+// retournerPourMembre enregistre le retour du livre scanné.
+// RÈGLE MÉTIER : au libre-service, un membre ne rend que ses propres emprunts ;
+// le livre d'un autre se rend à l'accueil, qui vérifie ce qui est rapporté.
+func (k *Kiosque) retournerPourMembre(code string, membre *models.Membre) (models.Emprunt, error) {
+	livre, _ := k.gestionnaireLivres.TrouverLivreParCode(code)
+	if livre == nil {
+		return models.Emprunt{}, fmt.Errorf("aucun livre ne correspond au code '%s'", models.NormaliserCode(code))
+	}
+	emprunt, _ := k.gestionnaireEmprunts.TrouverEmpruntActifParLivre(livre.ID)
+	if emprunt == nil {
+		return models.Emprunt{}, fmt.Errorf("le livre '%s' n'est pas emprunté", livre.Titre)
+	}
+	if emprunt.MembreID != membre.ID {
+		return models.Emprunt{}, fmt.Errorf("le livre '%s' n'est pas emprunté sur votre carte : rendez-le à l'accueil", livre.Titre)
+	}
+
+	empruntID := emprunt.ID
+	if err := k.gestionnaireEmprunts.RetournerLivre(empruntID); err != nil {
+		return models.Emprunt{}, err
+	}
+	emprunt, _ = k.gestionnaireEmprunts.TrouverEmpruntParID(empruntID)
+	return *emprunt, nil
+}
+
+// prolonger propose les emprunts du membre et prolonge celui qu'il choisit
+func (k *Kiosque) prolonger(membre *models.Membre, recu *recu) bool {
+	k.AfficherTitre("📅 PROLONGER UN EMPRUNT")
+
+	enCours := k.afficherEmpruntsEnCours(membre.ID)
+	if len(enCours) == 0 {
+		_, ok := k.LireEntreeAvecDelai("Appuyez sur Entrée pour revenir...", k.delai())
+		return ok
+	}
+
+	saisie, ok := k.LireEntreeAvecDelai(fmt.Sprintf("\nNuméro de l'emprunt à prolonger (1-%d, Entrée pour revenir) : ", len(enCours)), k.delai())
+	if !ok {
+		return false
+	}
+	if saisie == "" {
+		return true
+	}
+
+	numero, err := strconv.Atoi(saisie)
+	if err != nil || numero < 1 || numero > len(enCours) {
+		k.AfficherErreur("Numéro invalide.")
+		return true
+	}
+
+	emprunt, err := k.gestionnaireEmprunts.ProlongerEmpruntMembre(enCours[numero-1].ID, membre.ID)
+	if err != nil {
+		k.AfficherErreur(err.Error())
+		return true
+	}
+
+	retour := emprunt.DateRetourPrevu.Format("02/01/2006")
+	k.AfficherSucces(fmt.Sprintf("« %s » est à rendre le %s.", emprunt.TitreLivre, retour))
+	recu.ajouter("PROLONGÉ", emprunt.TitreLivre, "à rendre le "+retour)
+	return true
+}
+
+// terminerSession affiche et enregistre le reçu s'il y a eu au moins une opération
+func (k *Kiosque) terminerSession(membre *models.Membre, recu *recu) {
+	if len(recu.operations) == 0 {
+		fmt.Fprintf(k.sortie, "\n👋 Au revoir %s !\n", membre.Nom)
+		return
+	}
+
+	recu.enCours = k.empruntsEnCours(membre.ID)
+
+	fmt.Fprintln(k.sortie)
+	recu.ecrireTexte(k.sortie)
+
+	chemin, err := recu.enregistrer(k.config.Kiosque.DossierRecus, k.config.Kiosque.RecuPDF)
+	if err != nil {
+		k.AfficherErreur(err.Error())
+		return
+	}
+	fmt.Fprintf(k.sortie, "\n🧾 Reçu enregistré : %s\n", chemin)
+}
@@ -6,10 +6,18 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/models"
 	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
 )
 
 // ========================================
@@ -18,28 +26,77 @@ import (
 // ========================================
 
 type CLI struct {
+	*Console
+
+	config               *config.Config
 	gestionnaireLivres   *services.GestionnaireLivres
 	gestionnaireMembres  *services.GestionnaireMembres
 	gestionnaireEmprunts *services.GestionnaireEmprunts
+	gestionnaireGenres   *services.GestionnaireGenres
+
+	gestionnaireContributeurs *services.GestionnaireContributeurs
+	gestionnaireSeries        *services.GestionnaireSeries
+	gestionnaireReservations  *services.GestionnaireReservations
+	gestionnaireRecherches    *services.GestionnaireRecherches
+	gestionnaireAcquisitions  *services.GestionnaireAcquisitions
+	gestionnaireInventaires   *services.GestionnaireInventaires
+	gestionnaireSuccursales   *services.GestionnaireSuccursales
+	gestionnaireSynchro       *services.GestionnaireSynchro
+	gestionnairePEB           *services.GestionnairePEB
+
+	format string // format des listes, modifiable en cours de session
 }
 
 // NewCLI crée une nouvelle instance de l'interface CLI
-func NewCLI(gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts) *CLI {
+func NewCLI(cfg *config.Config, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts, gr *services.GestionnaireReservations, gg *services.GestionnaireGenres, gc *services.GestionnaireContributeurs, gs *services.GestionnaireSeries, grc *services.GestionnaireRecherches, ga *services.GestionnaireAcquisitions, gi *services.GestionnaireInventaires, gsu *services.GestionnaireSuccursales, gsy *services.GestionnaireSynchro, gp *services.GestionnairePEB) *CLI {
 	return &CLI{
+		Console: NouvelleConsole(os.Stdin, os.Stdout, false),
+
+		config:               cfg,
 		gestionnaireLivres:   gl,
 		gestionnaireMembres:  gm,
 		gestionnaireEmprunts: ge,
+		gestionnaireGenres:   gg,
+
+		gestionnaireContributeurs: gc,
+		gestionnaireSeries:        gs,
+		gestionnaireReservations:  gr,
+		gestionnaireRecherches:    grc,
+		gestionnaireAcquisitions:  ga,
+		gestionnaireInventaires:   gi,
+		gestionnaireSuccursales:   gsu,
+		gestionnaireSynchro:       gsy,
+		gestionnairePEB:           gp,
+
+		format: cfg.Affichage.Format,
 	}
 }
 
-// Run démarre l'application et affiche le menu principal
-func (cli *CLI) Run() error {
-	fmt.Println("📚 Bienvenue dans le Système de Gestion de Librairie !")
-	fmt.Println("📁 Données sauvegardées dans le dossier 'data/'")
+// UtiliserConsole remplace l'entrée et la sortie standard,
+// pour piloter l'application depuis un script ou un test
+func (cli *CLI) UtiliserConsole(console *Console) {
+	cli.Console = console
+}
+
+// Run démarre l'application et affiche le menu principal.
+// La fin de l'entrée (fin d'un script, Ctrl-D) termine la session normalement.
+func (cli *CLI) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(finDeSaisie); !ok {
+				panic(r)
+			}
+			fmt.Fprintln(cli.sortie, "👋 Fin de la saisie. Toutes les données ont été sauvegardées.")
+			err = nil
+		}
+	}()
+
+	fmt.Fprintln(cli.sortie, "📚 Bienvenue dans le Système de Gestion de Librairie !")
+	fmt.Fprintf(cli.sortie, "📁 Données sauvegardées dans le dossier '%s/'\n", cli.config.Donnees.Dossier)
 
 	for {
 		cli.afficherMenuPrincipal()
-		choix := LireEntreeEntierAvecLimites("Votre choix : ", 0, 4)
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 16)
 
 		var err error
 		switch choix {
@@ -50,17 +107,41 @@ func (cli *CLI) Run() error {
 		case 3:
 			err = cli.menuEmprunts()
 		case 4:
-			cli.afficherStatistiques()
+			err = cli.afficherStatistiques()
+		case 5:
+			err = cli.menuGenres()
+		case 6:
+			err = cli.menuContributeurs()
+		case 7:
+			err = cli.menuSeries()
+		case 8:
+			cli.choisirFormat()
+		case 9:
+			err = cli.menuCodesBarres()
+		case 10:
+			err = cli.genererRapport()
+		case 11:
+			err = cli.menuIndicateurs()
+		case 12:
+			err = cli.menuAcquisitions()
+		case 13:
+			err = cli.controlerIntegrite()
+		case 14:
+			err = cli.menuSuccursales()
+		case 15:
+			err = cli.menuSynchro()
+		case 16:
+			err = cli.menuPEB()
 		case 0:
-			fmt.Println("\n👋 Au revoir ! Toutes les données ont été sauvegardées.")
+			fmt.Fprintln(cli.sortie, "\n👋 Au revoir ! Toutes les données ont été sauvegardées.")
 			return nil
 		}
 
 		if err != nil {
-			AfficherErreur(err.Error())
+			cli.AfficherErreur(err.Error())
 		}
 
-		AttendreEntree("")
+		cli.AttendreEntree("")
 	}
 }
 
@@ -69,13 +150,25 @@ func (cli *CLI) Run() error {
 // ========================================
 
 func (cli *CLI) afficherMenuPrincipal() {
-	AfficherTitre("📚 GESTION DE LIBRAIRIE - MENU PRINCIPAL")
-	fmt.Println("1. 📖 Gestion des Livres")
-	fmt.Println("2. 👥 Gestion des Membres")
-	fmt.Println("3. 📋 Gestion des Emprunts")
-	fmt.Println("4. 📊 Statistiques")
-	fmt.Println("0. 🚪 Quitter")
-	AfficherSeparateur("-", 50)
+	cli.AfficherTitre("📚 GESTION DE LIBRAIRIE - MENU PRINCIPAL")
+	fmt.Fprintln(cli.sortie, "1. 📖 Gestion des Livres")
+	fmt.Fprintln(cli.sortie, "2. 👥 Gestion des Membres")
+	fmt.Fprintln(cli.sortie, "3. 📋 Gestion des Emprunts")
+	fmt.Fprintln(cli.sortie, "4. 📊 Statistiques")
+	fmt.Fprintln(cli.sortie, "5. 🏷️  Genres et sujets")
+	fmt.Fprintln(cli.sortie, "6. ✍️  Auteurs et contributeurs")
+	fmt.Fprintln(cli.sortie, "7. 📚 Séries, oeuvres et éditions")
+	fmt.Fprintf(cli.sortie, "8. 🖨️  Format des listes (actuel : %s)\n", cli.format)
+	fmt.Fprintln(cli.sortie, "9. 🏷️  Codes-barres, étiquettes et cartes")
+	fmt.Fprintln(cli.sortie, "10. 🗂️  Rapport d'activité (HTML et PDF)")
+	fmt.Fprintln(cli.sortie, "11. 📈 Indicateurs (rotation, livres dormants, affluence…)")
+	fmt.Fprintln(cli.sortie, "12. 🛒 Acquisitions (commandes, budgets, conseils d'achat)")
+	fmt.Fprintln(cli.sortie, "13. 🩺 Contrôle d'intégrité des données")
+	fmt.Fprintln(cli.sortie, "14. 🏢 Succursales et transferts")
+	fmt.Fprintln(cli.sortie, "15. 🔄 Synchronisation entre postes")
+	fmt.Fprintln(cli.sortie, "16. 🔁 Prêts entre bibliothèques")
+	fmt.Fprintln(cli.sortie, "0. 🚪 Quitter")
+	cli.AfficherSeparateur("-", 50)
 }
 
 // ========================================
@@ -84,17 +177,21 @@ func (cli *CLI) afficherMenuPrincipal() {
 
 func (cli *CLI) menuLivres() error {
 	for {
-		AfficherTitre("📖 GESTION DES LIVRES")
-		fmt.Println("1. ➕ Ajouter un livre")
-		fmt.Println("2. 📋 Lister tous les livres")
-		fmt.Println("3. 📗 Lister les livres disponibles")
-		fmt.Println("4. 🔍 Rechercher des livres")
-		fmt.Println("5. ✏️  Modifier un livre")
-		fmt.Println("6. 🗑️  Supprimer un livre")
-		fmt.Println("0. ⬅️  Retour au menu principal")
-		AfficherSeparateur("-", 50)
-
-		choix := LireEntreeEntierAvecLimites("Votre choix : ", 0, 6)
+		cli.AfficherTitre("📖 GESTION DES LIVRES")
+		fmt.Fprintln(cli.sortie, "1. ➕ Ajouter un livre")
+		fmt.Fprintln(cli.sortie, "2. 📋 Lister tous les livres")
+		fmt.Fprintln(cli.sortie, "3. 📗 Lister les livres disponibles")
+		fmt.Fprintln(cli.sortie, "4. 🔍 Rechercher des livres")
+		fmt.Fprintln(cli.sortie, "5. ✏️  Modifier un livre")
+		fmt.Fprintln(cli.sortie, "6. 🗑️  Retirer un livre (désherbage, perte)")
+		fmt.Fprintln(cli.sortie, "7. ♻️  Restaurer un livre retiré")
+		fmt.Fprintln(cli.sortie, "8. 📦 Lister les livres retirés")
+		fmt.Fprintln(cli.sortie, "9. 🧹 Purger les livres retirés")
+		fmt.Fprintln(cli.sortie, "10. 🔎 Inventaire des rayons")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 10)
 
 		var err error
 		switch choix {
@@ -109,38 +206,39 @@ func (cli *CLI) menuLivres() error {
 		case 5:
 			err = cli.modifierLivre()
 		case 6:
-			err = cli.supprimerLivre()
+			err = cli.retirerLivre()
+		case 7:
+			err = cli.restaurerLivre()
+		case 8:
+			cli.listerLivresRetires()
+		case 9:
+			err = cli.purgerLivresRetires()
+		case 10:
+			err = cli.menuInventaires()
 		case 0:
 			return nil
 		}
 
 		if err != nil {
-			AfficherErreur(err.Error())
+			cli.AfficherErreur(err.Error())
 		}
 
-		AttendreEntree("")
+		cli.AttendreEntree("")
 	}
 }
 
 func (cli *CLI) ajouterLivre() error {
-	AfficherTitre("➕ AJOUTER UN LIVRE")
+	cli.AfficherTitre("➕ AJOUTER UN LIVRE")
 
 	// Saisir les informations du livre
-	titre := LireEntreeObligatoire("Titre du livre : ")
-	auteur := LireEntreeObligatoire("Auteur : ")
-	isbn := LireEntreeObligatoire("ISBN (10 ou 13 caractères) : ")
+	titre := cli.LireEntreeObligatoire("Titre du livre : ")
+	auteur := cli.LireEntreeObligatoire("Auteur(s) (séparés par ';') : ")
+	isbn := cli.LireEntreeObligatoire("ISBN (10 ou 13 caractères) : ")
 
-	// Proposer une liste de genres
-	genres := []string{
-		"Roman", "Science-fiction", "Fantasy", "Policier", "Thriller",
-		"Romance", "Historique", "Biographie", "Essai", "Poésie",
-		"Théâtre", "Bande dessinée", "Manga", "Jeunesse", "Documentaire",
-		"Guide pratique", "Cuisine", "Art", "Sport", "Autre",
-	}
-	indexGenre := LireChoixDansListe("Choisissez le genre :", genres)
-	genre := genres[indexGenre]
+	// Proposer les genres de la taxonomie
+	genre := cli.choisirGenre("Choisissez le genre principal :")
 
-	datePublication := LireEntreeObligatoire("Date de publication (JJ/MM/AAAA) : ")
+	datePublication := cli.LireEntreeObligatoire("Date de publication (JJ/MM/AAAA) : ")
 
 	// Appeler le service pour ajouter le livre
 	err := cli.gestionnaireLivres.AjouterLivre(titre, auteur, isbn, genre, datePublication)
@@ -148,17 +246,17 @@ func (cli *CLI) ajouterLivre() error {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Livre '%s' ajouté avec succès !", titre))
+	cli.AfficherSucces(fmt.Sprintf("Livre '%s' ajouté avec succès !", titre))
 	return nil
 }
 
 func (cli *CLI) listerLivres() {
-	AfficherTitre("📋 LISTE DE TOUS LES LIVRES")
+	cli.AfficherTitre("📋 LISTE DE TOUS LES LIVRES")
 
 	livres := cli.gestionnaireLivres.ListerLivres()
 
 	if len(livres) == 0 {
-		AfficherInfo("Aucun livre enregistré.")
+		cli.AfficherInfo("Aucun livre enregistré.")
 		return
 	}
 
@@ -166,12 +264,12 @@ func (cli *CLI) listerLivres() {
 }
 
 func (cli *CLI) listerLivresDisponibles() {
-	AfficherTitre("📗 LIVRES DISPONIBLES À L'EMPRUNT")
+	cli.AfficherTitre("📗 LIVRES DISPONIBLES À L'EMPRUNT")
 
-	livres := cli.gestionnaireLivres.listerLivresDisponibles()
+	livres := cli.gestionnaireLivres.ListerLivresDisponibles()
 
 	if len(livres) == 0 {
-		AfficherInfo("Aucun livre disponible actuellement.")
+		cli.AfficherInfo("Aucun livre disponible actuellement.")
 		return
 	}
 
@@ -179,16 +277,19 @@ func (cli *CLI) listerLivresDisponibles() {
 }
 
 func (cli *CLI) rechercherLivres() {
-	AfficherTitre("🔍 RECHERCHER DES LIVRES")
+	cli.AfficherTitre("🔍 RECHERCHER DES LIVRES")
 
-	terme := LireEntreeObligatoire("Terme de recherche (titre, auteur ou genre) : ")
+	terme := cli.LireEntreeObligatoire("Terme de recherche (titre, auteur, contributeur ou genre) : ")
 
 	resultats := cli.gestionnaireLivres.RechercherLivres(terme)
+	if err := cli.gestionnaireRecherches.NoterRecherche(terme, len(resultats)); err != nil {
+		cli.AfficherAvertissement(fmt.Sprintf("Recherche non notée pour le conseiller d'achat : %v", err))
+	}
 
-	fmt.Printf("\n🎯 %d résultat(s) trouvé(s) pour '%s' :\n", len(resultats), terme)
+	fmt.Fprintf(cli.sortie, "\n🎯 %d résultat(s) trouvé(s) pour '%s' :\n", len(resultats), terme)
 
 	if len(resultats) == 0 {
-		AfficherInfo("Aucun livre correspondant.")
+		cli.AfficherInfo("Aucun livre correspondant.")
 		return
 	}
 
@@ -196,9 +297,9 @@ func (cli *CLI) rechercherLivres() {
 }
 
 func (cli *CLI) modifierLivre() error {
-	AfficherTitre("✏️ MODIFIER UN LIVRE")
+	cli.AfficherTitre("✏️ MODIFIER UN LIVRE")
 
-	id := LireEntreeEntierObligatoire("ID du livre à modifier : ")
+	id := cli.LireEntreeEntierObligatoire("ID du livre à modifier : ")
 
 	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(id)
 	if livre == nil {
@@ -206,26 +307,26 @@ func (cli *CLI) modifierLivre() error {
 	}
 
 	// Afficher les informations actuelles
-	fmt.Println("\nInformations actuelles :")
-	livre.AfficherDetails()
+	fmt.Fprintln(cli.sortie, "\nInformations actuelles :")
+	livre.AfficherDetails(cli.sortie)
 
-	AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
+	cli.AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
 
 	// Saisir les nouvelles valeurs
-	fmt.Printf("Nouveau titre (%s) : ", livre.Titre)
-	nouveauTitre := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouveau titre (%s) : ", livre.Titre)
+	nouveauTitre := cli.LireEntree()
 
-	fmt.Printf("Nouvel auteur (%s) : ", livre.Auteur)
-	nouvelAuteur := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouvel(s) auteur(s), séparés par ';' (%s) : ", livre.Auteur)
+	nouvelAuteur := cli.LireEntree()
 
-	fmt.Printf("Nouvel ISBN (%s) : ", livre.ISBN)
-	nouvelISBN := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouvel ISBN (%s) : ", livre.ISBN)
+	nouvelISBN := cli.LireEntree()
 
-	fmt.Printf("Nouveau genre (%s) : ", livre.Genre)
-	nouveauGenre := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouveau genre (%s) : ", livre.Genre)
+	nouveauGenre := cli.LireEntree()
 
-	fmt.Printf("Nouvelle date de publication (%s) : ", livre.DatePublication.Format("02/01/2006"))
-	nouvelleDateStr := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouvelle date de publication (%s) : ", livre.DatePublication.Format("02/01/2006"))
+	nouvelleDateStr := cli.LireEntree()
 
 	// Appeler le service pour modifier
 	err := cli.gestionnaireLivres.ModifierLivre(id, nouveauTitre, nouvelAuteur, nouvelISBN, nouveauGenre, nouvelleDateStr)
@@ -233,59 +334,147 @@ func (cli *CLI) modifierLivre() error {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Livre ID %d modifié avec succès !", id))
+	cli.AfficherSucces(fmt.Sprintf("Livre ID %d modifié avec succès !", id))
 	return nil
 }
 
-func (cli *CLI) supprimerLivre() error {
-	AfficherTitre("🗑️ SUPPRIMER UN LIVRE")
+func (cli *CLI) retirerLivre() error {
+	cli.AfficherTitre("🗑️ RETIRER UN LIVRE")
 
-	id := LireEntreeEntierObligatoire("ID du livre à supprimer : ")
+	id := cli.LireEntreeEntierObligatoire("ID du livre à retirer : ")
 
 	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(id)
 	if livre == nil {
 		return fmt.Errorf("aucun livre trouvé avec l'ID %d", id)
 	}
 
-	// Afficher le livre à supprimer
-	fmt.Println("\nLivre à supprimer :")
-	livre.AfficherDetails()
+	// Afficher le livre à retirer
+	fmt.Fprintln(cli.sortie, "\nLivre à retirer :")
+	livre.AfficherDetails(cli.sortie)
+
+	libelles := make([]string, len(models.MOTIFS_RETRAIT_LIVRE))
+	for i, motif := range models.MOTIFS_RETRAIT_LIVRE {
+		libelles[i] = models.LibelleMotif(motif)
+	}
+	motif := models.MOTIFS_RETRAIT_LIVRE[cli.LireChoixDansListe("\nMotif du retrait :", libelles)]
+
+	fmt.Fprint(cli.sortie, "Raison (facultatif) : ")
+	raison := cli.LireEntree()
+
+	cli.AfficherInfo("Le livre sera masqué du catalogue mais son historique d'emprunts sera conservé.")
+	if !livre.Disponible && motif == models.MOTIF_PERDU {
+		cli.AfficherInfo("Le livre est emprunté : son emprunt sera clos, sans retour attendu.")
+	}
 
 	// Demander confirmation
-	if !LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir supprimer ce livre ?") {
-		AfficherInfo("Suppression annulée.")
+	if !cli.LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir retirer ce livre ?") {
+		cli.AfficherInfo("Retrait annulé.")
 		return nil
 	}
 
 	titre := livre.Titre
-	err := cli.gestionnaireLivres.SupprimerLivre(id)
+	err := cli.gestionnaireEmprunts.RetirerLivre(id, motif, raison)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Livre '%s' (ID: %d) supprimé avec succès !", titre, id))
+	cli.AfficherSucces(fmt.Sprintf("Livre '%s' (ID: %d) retiré du catalogue !", titre, id))
 	return nil
 }
 
+func (cli *CLI) restaurerLivre() error {
+	cli.AfficherTitre("♻️ RESTAURER UN LIVRE")
+
+	retires := cli.gestionnaireLivres.ListerLivresRetires()
+	if len(retires) == 0 {
+		cli.AfficherInfo("Aucun livre retiré.")
+		return nil
+	}
+
+	cli.afficherTableauRetraitsLivres(retires)
+
+	id := cli.LireEntreeEntierObligatoire("\nID du livre à restaurer : ")
+
+	if err := cli.gestionnaireEmprunts.RestaurerLivre(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Livre ID %d remis au catalogue !", id))
+	return nil
+}
+
+func (cli *CLI) listerLivresRetires() {
+	cli.AfficherTitre("📦 LIVRES RETIRÉS")
+
+	retires := cli.gestionnaireLivres.ListerLivresRetires()
+	if len(retires) == 0 {
+		cli.AfficherInfo("Aucun livre retiré.")
+		return
+	}
+
+	cli.afficherTableauRetraitsLivres(retires)
+}
+
+func (cli *CLI) purgerLivresRetires() error {
+	cli.AfficherTitre("🧹 PURGER LES LIVRES RETIRÉS")
+
+	conservation := cli.config.Conservation.LivresRetiresJours
+	if conservation <= 0 {
+		return fmt.Errorf("aucune règle de conservation définie (conservation.livres_retires_jours), purge impossible")
+	}
+
+	cli.AfficherAvertissement(fmt.Sprintf("La fiche des livres retirés depuis plus de %d jour(s) sera effacée définitivement.", conservation))
+	cli.AfficherInfo("Seuls le numéro et le titre sont gardés : les emprunts passés restent dans l'historique.")
+
+	if !cli.LireConfirmation("Confirmer la purge ?") {
+		cli.AfficherInfo("Purge annulée.")
+		return nil
+	}
+
+	purges, err := cli.gestionnaireLivres.PurgerLivresRetires(conservation)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%d livre(s) purgé(s).", len(purges)))
+	for _, livre := range purges {
+		fmt.Fprintf(cli.sortie, "  • %s (ID %d) - %s\n", livre.Titre, livre.ID, livre.Retrait.String())
+	}
+	return nil
+}
+
+func (cli *CLI) afficherTableauRetraitsLivres(livres []models.Livre) {
+	fmt.Fprintln(cli.sortie)
+	for _, livre := range livres {
+		fmt.Fprintf(cli.sortie, "• [%d] %s par %s - %s\n", livre.ID, livre.Titre, livre.Auteur, livre.Retrait.String())
+	}
+	fmt.Fprintf(cli.sortie, "\nTotal : %d livre(s) retiré(s)\n", len(livres))
+}
+
 // ========================================
 // SOUS-MENU MEMBRES
 // ========================================
 
 func (cli *CLI) menuMembres() error {
 	for {
-		AfficherTitre("👥 GESTION DES MEMBRES")
-		fmt.Println("1. ➕ Inscrire un membre")
-		fmt.Println("2. 📋 Lister tous les membres")
-		fmt.Println("3. ✅ Lister les membres actifs")
-		fmt.Println("4. 🔍 Rechercher des membres")
-		fmt.Println("5. ✏️  Modifier un membre")
-		fmt.Println("6. ⛔ Suspendre un membre")
-		fmt.Println("7. ✅ Réactiver un membre")
-		fmt.Println("8. 🗑️  Supprimer un membre")
-		fmt.Println("0. ⬅️  Retour au menu principal")
-		AfficherSeparateur("-", 50)
-
-		choix := LireEntreeEntierAvecLimites("Votre choix : ", 0, 8)
+		cli.AfficherTitre("👥 GESTION DES MEMBRES")
+		fmt.Fprintln(cli.sortie, "1. ➕ Inscrire un membre")
+		fmt.Fprintln(cli.sortie, "2. 📋 Lister tous les membres")
+		fmt.Fprintln(cli.sortie, "3. ✅ Lister les membres actifs")
+		fmt.Fprintln(cli.sortie, "4. 🔍 Rechercher des membres")
+		fmt.Fprintln(cli.sortie, "5. ✏️  Modifier un membre")
+		fmt.Fprintln(cli.sortie, "6. ⛔ Suspendre un membre")
+		fmt.Fprintln(cli.sortie, "7. ✅ Réactiver un membre")
+		fmt.Fprintln(cli.sortie, "8. 🗑️  Radier un membre")
+		fmt.Fprintln(cli.sortie, "9. ♻️  Réinscrire un membre radié")
+		fmt.Fprintln(cli.sortie, "10. 📦 Lister les membres radiés")
+		fmt.Fprintln(cli.sortie, "11. 🧹 Purger les membres radiés")
+		fmt.Fprintln(cli.sortie, "12. 🔢 Définir le code PIN du libre-service")
+		fmt.Fprintln(cli.sortie, "13. 💡 Suggestions de lecture")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 13)
 
 		var err error
 		switch choix {
@@ -304,42 +493,52 @@ func (cli *CLI) menuMembres() error {
 		case 7:
 			err = cli.reactiverMembre()
 		case 8:
-			err = cli.supprimerMembre()
+			err = cli.radierMembre()
+		case 9:
+			err = cli.restaurerMembre()
+		case 10:
+			cli.listerMembresRadies()
+		case 11:
+			err = cli.purgerMembresRadies()
+		case 12:
+			err = cli.definirPIN()
+		case 13:
+			err = cli.suggererLectures()
 		case 0:
 			return nil
 		}
 
 		if err != nil {
-			AfficherErreur(err.Error())
+			cli.AfficherErreur(err.Error())
 		}
 
-		AttendreEntree("")
+		cli.AttendreEntree("")
 	}
 }
 
 func (cli *CLI) inscrireMembre() error {
-	AfficherTitre("➕ INSCRIRE UN MEMBRE")
+	cli.AfficherTitre("➕ INSCRIRE UN MEMBRE")
 
-	nom := LireEntreeObligatoire("Nom complet : ")
-	email := LireEntreeObligatoire("Adresse email : ")
-	telephone := LireEntreeObligatoire("Numéro de téléphone : ")
+	nom := cli.LireEntreeObligatoire("Nom complet : ")
+	email := cli.LireEntreeObligatoire("Adresse email : ")
+	telephone := cli.LireEntreeObligatoire("Numéro de téléphone : ")
 
 	err := cli.gestionnaireMembres.AjouterMembre(nom, email, telephone)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Membre '%s' inscrit avec succès !", nom))
+	cli.AfficherSucces(fmt.Sprintf("Membre '%s' inscrit avec succès !", nom))
 	return nil
 }
 
 func (cli *CLI) listerMembres() {
-	AfficherTitre("📋 LISTE DE TOUS LES MEMBRES")
+	cli.AfficherTitre("📋 LISTE DE TOUS LES MEMBRES")
 
 	membres := cli.gestionnaireMembres.ListerMembres()
 
 	if len(membres) == 0 {
-		AfficherInfo("Aucun membre inscrit.")
+		cli.AfficherInfo("Aucun membre inscrit.")
 		return
 	}
 
@@ -347,12 +546,12 @@ func (cli *CLI) listerMembres() {
 }
 
 func (cli *CLI) listerMembresActifs() {
-	AfficherTitre("✅ MEMBRES ACTIFS")
+	cli.AfficherTitre("✅ MEMBRES ACTIFS")
 
 	membres := cli.gestionnaireMembres.ListerMembresActifs()
 
 	if len(membres) == 0 {
-		AfficherInfo("Aucun membre actif.")
+		cli.AfficherInfo("Aucun membre actif.")
 		return
 	}
 
@@ -360,16 +559,16 @@ func (cli *CLI) listerMembresActifs() {
 }
 
 func (cli *CLI) rechercherMembres() {
-	AfficherTitre("🔍 RECHERCHER DES MEMBRES")
+	cli.AfficherTitre("🔍 RECHERCHER DES MEMBRES")
 
-	terme := LireEntreeObligatoire("Terme de recherche (nom ou email) : ")
+	terme := cli.LireEntreeObligatoire("Terme de recherche (nom ou email) : ")
 
 	resultats := cli.gestionnaireMembres.RechercherMembres(terme)
 
-	fmt.Printf("\n🎯 %d résultat(s) trouvé(s) pour '%s' :\n", len(resultats), terme)
+	fmt.Fprintf(cli.sortie, "\n🎯 %d résultat(s) trouvé(s) pour '%s' :\n", len(resultats), terme)
 
 	if len(resultats) == 0 {
-		AfficherInfo("Aucun membre correspondant.")
+		cli.AfficherInfo("Aucun membre correspondant.")
 		return
 	}
 
@@ -377,9 +576,9 @@ func (cli *CLI) rechercherMembres() {
 }
 
 func (cli *CLI) modifierMembre() error {
-	AfficherTitre("✏️ MODIFIER UN MEMBRE")
+	cli.AfficherTitre("✏️ MODIFIER UN MEMBRE")
 
-	id := LireEntreeEntierObligatoire("ID du membre à modifier : ")
+	id := cli.LireEntreeEntierObligatoire("ID du membre à modifier : ")
 
 	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(id)
 	if membre == nil {
@@ -387,127 +586,228 @@ func (cli *CLI) modifierMembre() error {
 	}
 
 	// Afficher les informations actuelles
-	fmt.Println("\nInformations actuelles :")
-	membre.AfficherDetails()
+	fmt.Fprintln(cli.sortie, "\nInformations actuelles :")
+	membre.AfficherDetails(cli.sortie)
 
-	AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
+	cli.AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
 
 	// Saisir les nouvelles valeurs
-	fmt.Printf("Nouveau nom (%s) : ", membre.Nom)
-	nouveauNom := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouveau nom (%s) : ", membre.Nom)
+	nouveauNom := cli.LireEntree()
 
-	fmt.Printf("Nouvel email (%s) : ", membre.Email)
-	nouvelEmail := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouvel email (%s) : ", membre.Email)
+	nouvelEmail := cli.LireEntree()
 
-	fmt.Printf("Nouveau téléphone (%s) : ", membre.Telephone)
-	nouveauTelephone := LireEntree()
+	fmt.Fprintf(cli.sortie, "Nouveau téléphone (%s) : ", membre.Telephone)
+	nouveauTelephone := cli.LireEntree()
 
 	err := cli.gestionnaireMembres.ModifierMembre(id, nouveauNom, nouvelEmail, nouveauTelephone)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Membre ID %d modifié avec succès !", id))
+	cli.AfficherSucces(fmt.Sprintf("Membre ID %d modifié avec succès !", id))
 	return nil
 }
 
-func (cli *CLI) suspendireMembre() error {
-	AfficherTitre("⛔ SUSPENDRE UN MEMBRE")
+func (cli *CLI) suspendirMembre() error {
+	cli.AfficherTitre("⛔ SUSPENDRE UN MEMBRE")
 
-	id := LireEntreeEntierObligatoire("ID du membre à suspendre : ")
+	id := cli.LireEntreeEntierObligatoire("ID du membre à suspendre : ")
 
 	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(id)
 	if membre == nil {
 		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	fmt.Println("\nMembre à suspendre :")
-	membre.AfficherDetails()
+	fmt.Fprintln(cli.sortie, "\nMembre à suspendre :")
+	membre.AfficherDetails(cli.sortie)
 
-	if !LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir suspendre ce membre ?") {
-		AfficherInfo("Suspension annulée.")
+	if !cli.LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir suspendre ce membre ?") {
+		cli.AfficherInfo("Suspension annulée.")
 		return nil
 	}
 
-	err := cli.gestionnaireMembres.SuspendirMembre(id)
+	err := cli.gestionnaireEmprunts.SuspendreMembre(id)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) suspendu avec succès !", membre.Nom, id))
+	cli.AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) suspendu avec succès !", membre.Nom, id))
 	return nil
 }
 
 func (cli *CLI) reactiverMembre() error {
-	AfficherTitre("✅ RÉACTIVER UN MEMBRE")
+	cli.AfficherTitre("✅ RÉACTIVER UN MEMBRE")
 
-	id := LireEntreeEntierObligatoire("ID du membre à réactiver : ")
+	id := cli.LireEntreeEntierObligatoire("ID du membre à réactiver : ")
 
 	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(id)
 	if membre == nil {
 		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	err := cli.gestionnaireMembres.ReactiverMembre(id)
+	err := cli.gestionnaireEmprunts.ReactiverMembre(id)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) réactivé avec succès !", membre.Nom, id))
+	cli.AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) réactivé avec succès !", membre.Nom, id))
 	return nil
 }
 
-func (cli *CLI) supprimerMembre() error {
-	AfficherTitre("🗑️ SUPPRIMER UN MEMBRE")
+func (cli *CLI) radierMembre() error {
+	cli.AfficherTitre("🗑️ RADIER UN MEMBRE")
 
-	id := LireEntreeEntierObligatoire("ID du membre à supprimer : ")
+	id := cli.LireEntreeEntierObligatoire("ID du membre à radier : ")
 
 	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(id)
 	if membre == nil {
 		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	// Afficher le membre à supprimer
-	fmt.Println("\nMembre à supprimer :")
-	membre.AfficherDetails()
+	// Afficher le membre à radier
+	fmt.Fprintln(cli.sortie, "\nMembre à radier :")
+	membre.AfficherDetails(cli.sortie)
+
+	fmt.Fprint(cli.sortie, "Raison (facultatif) : ")
+	raison := cli.LireEntree()
 
-	if !LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir supprimer ce membre ?") {
-		AfficherInfo("Suppression annulée.")
+	if !cli.LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir radier ce membre ?") {
+		cli.AfficherInfo("Radiation annulée.")
 		return nil
 	}
 
 	nom := membre.Nom
-	err := cli.gestionnaireMembres.SupprimerMembre(id)
+	err := cli.gestionnaireMembres.RadierMembre(id, raison)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) supprimé avec succès !", nom, id))
+	cli.AfficherSucces(fmt.Sprintf("Membre '%s' (ID: %d) radié ! Son historique est conservé.", nom, id))
+	return nil
+}
+
+func (cli *CLI) restaurerMembre() error {
+	cli.AfficherTitre("♻️ RÉINSCRIRE UN MEMBRE")
+
+	radies := cli.gestionnaireMembres.ListerMembresRadies()
+	if len(radies) == 0 {
+		cli.AfficherInfo("Aucun membre radié.")
+		return nil
+	}
+
+	cli.afficherTableauRadiations(radies)
+
+	id := cli.LireEntreeEntierObligatoire("\nID du membre à réinscrire : ")
+
+	if err := cli.gestionnaireMembres.RestaurerMembre(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Membre ID %d réinscrit !", id))
 	return nil
 }
 
+func (cli *CLI) listerMembresRadies() {
+	cli.AfficherTitre("📦 MEMBRES RADIÉS")
+
+	radies := cli.gestionnaireMembres.ListerMembresRadies()
+	if len(radies) == 0 {
+		cli.AfficherInfo("Aucun membre radié.")
+		return
+	}
+
+	cli.afficherTableauRadiations(radies)
+}
+
+func (cli *CLI) purgerMembresRadies() error {
+	cli.AfficherTitre("🧹 PURGER LES MEMBRES RADIÉS")
+
+	conservation := cli.config.Conservation.MembresRadiesJours
+	if conservation <= 0 {
+		return fmt.Errorf("aucune règle de conservation définie (conservation.membres_radies_jours), purge impossible")
+	}
+
+	cli.AfficherAvertissement(fmt.Sprintf("Les membres radiés depuis plus de %d jour(s) seront supprimés définitivement.", conservation))
+	cli.AfficherInfo("Les emprunts passés restent dans l'historique avec le nom du membre.")
+
+	if !cli.LireConfirmation("Confirmer la purge ?") {
+		cli.AfficherInfo("Purge annulée.")
+		return nil
+	}
+
+	purges, err := cli.gestionnaireMembres.PurgerMembresRadies(conservation)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%d membre(s) purgé(s).", len(purges)))
+	for _, membre := range purges {
+		fmt.Fprintf(cli.sortie, "  • %s (ID %d) - %s\n", membre.Nom, membre.ID, membre.Retrait.String())
+	}
+	return nil
+}
+
+// definirPIN laisse le membre choisir son code au comptoir ; un nouveau code débloque la carte
+func (cli *CLI) definirPIN() error {
+	cli.AfficherTitre("🔢 CODE PIN DU LIBRE-SERVICE")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette saisie")
+	}
+	fmt.Fprintf(cli.sortie, "Membre : %s (%s)\n", membre.Nom, membre.NumeroCarte)
+	if membre.EchecsPIN >= services.ESSAIS_PIN_MAX {
+		cli.AfficherAvertissement("La carte est actuellement bloquée : le nouveau code la débloquera.")
+	}
+
+	pin := cli.LireSecret("Nouveau code PIN (4 à 8 chiffres) : ")
+	if cli.LireSecret("Confirmez le code PIN : ") != pin {
+		return fmt.Errorf("les deux codes saisis sont différents")
+	}
+
+	if err := cli.gestionnaireMembres.DefinirPIN(membre.ID, pin); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Code PIN enregistré pour %s.", membre.Nom))
+	return nil
+}
+
+func (cli *CLI) afficherTableauRadiations(membres []models.Membre) {
+	fmt.Fprintln(cli.sortie)
+	for _, membre := range membres {
+		fmt.Fprintf(cli.sortie, "• [%d] %s <%s> - %s\n", membre.ID, membre.Nom, membre.Email, membre.Retrait.String())
+	}
+	fmt.Fprintf(cli.sortie, "\nTotal : %d membre(s) radié(s)\n", len(membres))
+}
+
 // ========================================
 // SOUS-MENU EMPRUNTS COMPLET AVEC TOUTES LES NOUVELLES FONCTIONNALITÉS
 // ========================================
 
 func (cli *CLI) menuEmprunts() error {
 	for {
-		AfficherTitre("📋 GESTION DES EMPRUNTS")
-		fmt.Println("1. 📚 Emprunter un livre")
-		fmt.Println("2. 📤 Retourner un livre")
-		fmt.Println("3. 📋 Lister tous les emprunts")
-		fmt.Println("4. 📘 Lister les emprunts en cours")
-		fmt.Println("5. ⚠️  Lister les emprunts en retard")
-		fmt.Println("6. 👤 Emprunts d'un membre")
-		fmt.Println("7. 📖 Historique d'un livre")
-		fmt.Println("8. 📅 Prolonger un emprunt")
-		fmt.Println("9. 📅 Emprunts à rendre aujourd'hui")
-		fmt.Println("10. ❌ Annuler un emprunt")
-		fmt.Println("11. 📊 Rapport détaillé des emprunts")
-		fmt.Println("0. ⬅️  Retour au menu principal")
-		AfficherSeparateur("-", 50)
-
-		choix := LireEntreeEntierAvecLimites("Votre choix : ", 0, 11)
+		cli.AfficherTitre("📋 GESTION DES EMPRUNTS")
+		fmt.Fprintln(cli.sortie, "1. 📚 Emprunter un livre")
+		fmt.Fprintln(cli.sortie, "2. 📤 Retourner un livre")
+		fmt.Fprintln(cli.sortie, "3. 📋 Lister tous les emprunts")
+		fmt.Fprintln(cli.sortie, "4. 📘 Lister les emprunts en cours")
+		fmt.Fprintln(cli.sortie, "5. ⚠️  Lister les emprunts en retard")
+		fmt.Fprintln(cli.sortie, "6. 👤 Emprunts d'un membre")
+		fmt.Fprintln(cli.sortie, "7. 📖 Historique d'un livre")
+		fmt.Fprintln(cli.sortie, "8. 📅 Prolonger un emprunt")
+		fmt.Fprintln(cli.sortie, "9. 📅 Emprunts à rendre aujourd'hui")
+		fmt.Fprintln(cli.sortie, "10. ❌ Annuler un emprunt")
+		fmt.Fprintln(cli.sortie, "11. 📊 Rapport détaillé des emprunts")
+		fmt.Fprintln(cli.sortie, "12. ⚡ Prêt rapide (scan de la carte puis des livres)")
+		fmt.Fprintln(cli.sortie, "13. 📌 Réservations")
+		fmt.Fprintln(cli.sortie, "14. 🧾 Journal des emprunts (historique, état à une date)")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 14)
 
 		var err error
 		switch choix {
@@ -533,86 +833,153 @@ func (cli *CLI) menuEmprunts() error {
 			err = cli.annulerEmprunt()
 		case 11:
 			cli.genererRapportEmprunts()
+		case 12:
+			err = cli.pretRapide()
+		case 13:
+			err = cli.menuReservations()
+		case 14:
+			err = cli.menuJournal()
 		case 0:
 			return nil
 		}
 
 		if err != nil {
-			AfficherErreur(err.Error())
+			cli.AfficherErreur(err.Error())
 		}
 
-		AttendreEntree("")
+		cli.AttendreEntree("")
 	}
 }
 
 func (cli *CLI) emprunterLivre() error {
-	AfficherTitre("📚 EMPRUNTER UN LIVRE")
+	cli.AfficherTitre("📚 EMPRUNTER UN LIVRE")
 
 	// Afficher les livres disponibles
 	livresDisponibles := cli.gestionnaireLivres.ListerLivresDisponibles()
 	if len(livresDisponibles) == 0 {
-		AfficherInfo("Aucun livre disponible actuellement.")
+		cli.AfficherInfo("Aucun livre disponible actuellement.")
 		return nil
 	}
 
-	fmt.Println("\nLivres disponibles :")
+	fmt.Fprintln(cli.sortie, "\nLivres disponibles :")
 	cli.afficherTableauLivres(livresDisponibles)
 
-	livreID := LireEntreeEntierObligatoire("\nID du livre à emprunter : ")
+	// La douchette tape le code suivi d'Entrée : un code scanné et un ID saisi sont lus pareil
+	codeLivre := cli.LireEntreeObligatoire("\nID ou code-barres du livre à emprunter : ")
 
 	// Afficher les membres actifs
 	membresActifs := cli.gestionnaireMembres.ListerMembresActifs()
 	if len(membresActifs) == 0 {
-		AfficherInfo("Aucun membre actif.")
+		cli.AfficherInfo("Aucun membre actif.")
 		return nil
 	}
 
-	fmt.Println("\nMembres actifs :")
+	fmt.Fprintln(cli.sortie, "\nMembres actifs :")
 	cli.afficherTableauMembres(membresActifs)
 
-	membreID := LireEntreeEntierObligatoire("\nID du membre : ")
+	carteMembre := cli.LireEntreeObligatoire("\nID ou carte du membre : ")
 
-	err := cli.gestionnaireEmprunts.EmprunterLivre(livreID, membreID)
+	emprunt, err := cli.gestionnaireEmprunts.EmprunterParCode(codeLivre, carteMembre)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces("Emprunt enregistré avec succès ! 📚")
-	AfficherInfo(fmt.Sprintf("Le livre doit être rendu dans %d jours.", models.DUREE_EMPRUNT_JOURS))
+	cli.AfficherSucces("Emprunt enregistré avec succès ! 📚")
+	cli.AfficherInfo(fmt.Sprintf("« %s » doit être rendu le %s.", emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006")))
 	return nil
 }
 
 func (cli *CLI) retournerLivre() error {
-	AfficherTitre("📤 RETOURNER UN LIVRE")
+	cli.AfficherTitre("📤 RETOURNER UN LIVRE")
 
 	// Afficher les emprunts en cours
 	empruntsEnCours := cli.gestionnaireEmprunts.ListerEmpruntsEnCours()
 	if len(empruntsEnCours) == 0 {
-		AfficherInfo("Aucun emprunt en cours.")
+		cli.AfficherInfo("Aucun emprunt en cours.")
 		return nil
 	}
 
-	fmt.Println("\nEmprunts en cours :")
+	fmt.Fprintln(cli.sortie, "\nEmprunts en cours :")
 	cli.afficherTableauEmprunts(empruntsEnCours)
 
-	empruntID := LireEntreeEntierObligatoire("\nID de l'emprunt à retourner : ")
+	saisie := cli.LireEntreeObligatoire("\nID de l'emprunt ou code-barres du livre : ")
+
+	// Un numéro d'emprunt existant garde la priorité, sinon la saisie est un code de livre
+	if empruntID, err := strconv.Atoi(saisie); err == nil {
+		if emprunt, _ := cli.gestionnaireEmprunts.TrouverEmpruntParID(empruntID); emprunt != nil {
+			if err := cli.gestionnaireEmprunts.RetournerLivre(empruntID); err != nil {
+				return err
+			}
+			cli.AfficherSucces("Retour enregistré avec succès ! 📤")
+			cli.signalerMiseDeCote(emprunt.LivreID)
+			return nil
+		}
+	}
 
-	err := cli.gestionnaireEmprunts.RetournerLivre(empruntID)
+	emprunt, err := cli.gestionnaireEmprunts.RetournerParCode(saisie)
 	if err != nil {
 		return err
 	}
 
-	AfficherSucces("Retour enregistré avec succès ! 📤")
+	cli.AfficherSucces(fmt.Sprintf("Retour de « %s » enregistré avec succès ! 📤", emprunt.TitreLivre))
+	cli.signalerMiseDeCote(emprunt.LivreID)
+	return nil
+}
+
+// pretRapide enchaîne les prêts pour un même membre : la carte est scannée une fois,
+// puis chaque livre scanné est prêté immédiatement. Scanner une autre carte change de membre.
+func (cli *CLI) pretRapide() error {
+	cli.AfficherTitre("⚡ PRÊT RAPIDE")
+	fmt.Fprintln(cli.sortie, "Scannez la carte du membre, puis les livres un par un.")
+	fmt.Fprintln(cli.sortie, "Validez une ligne vide pour terminer.")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("\n💳 Carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette carte")
+	}
+	cli.afficherMembrePretRapide(membre)
+
+	prets := 0
+	for {
+		fmt.Fprint(cli.sortie, "📖 Livre : ")
+		code := cli.LireEntree()
+		if code == "" {
+			break
+		}
+
+		if autre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(code); autre != nil && autre.NumeroCarte == models.NormaliserCode(code) {
+			membre = autre
+			cli.afficherMembrePretRapide(membre)
+			continue
+		}
+
+		emprunt, err := cli.gestionnaireEmprunts.EmprunterParCode(code, membre.NumeroCarte)
+		if err != nil {
+			fmt.Fprintf(cli.sortie, "   ❌ %s\n", err.Error())
+			continue
+		}
+		prets++
+		fmt.Fprintf(cli.sortie, "   ✅ %s — à rendre le %s\n", emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006"))
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%d livre(s) prêté(s).", prets))
 	return nil
 }
 
+func (cli *CLI) afficherMembrePretRapide(membre *models.Membre) {
+	// Relire le membre : ses compteurs évoluent à chaque prêt
+	membre, _ = cli.gestionnaireMembres.TrouverMembreParID(membre.ID)
+	fmt.Fprintf(cli.sortie, "👤 %s (%s) — %d/%d emprunt(s) en cours\n",
+		membre.Nom, membre.NumeroCarte, membre.EmpruntsActifs, cli.gestionnaireMembres.LimiteEmprunts())
+}
+
 func (cli *CLI) listerEmprunts() {
-	AfficherTitre("📋 LISTE DE TOUS LES EMPRUNTS")
+	cli.AfficherTitre("📋 LISTE DE TOUS LES EMPRUNTS")
 
 	emprunts := cli.gestionnaireEmprunts.ListerEmprunts()
 
 	if len(emprunts) == 0 {
-		AfficherInfo("Aucun emprunt enregistré.")
+		cli.AfficherInfo("Aucun emprunt enregistré.")
 		return
 	}
 
@@ -620,12 +987,12 @@ func (cli *CLI) listerEmprunts() {
 }
 
 func (cli *CLI) listerEmpruntsEnCours() {
-	AfficherTitre("📘 EMPRUNTS EN COURS")
+	cli.AfficherTitre("📘 EMPRUNTS EN COURS")
 
 	emprunts := cli.gestionnaireEmprunts.ListerEmpruntsEnCours()
 
 	if len(emprunts) == 0 {
-		AfficherInfo("Aucun emprunt en cours.")
+		cli.AfficherInfo("Aucun emprunt en cours.")
 		return
 	}
 
@@ -633,44 +1000,44 @@ func (cli *CLI) listerEmpruntsEnCours() {
 }
 
 func (cli *CLI) listerEmpruntsEnRetard() {
-	AfficherTitre("⚠️ EMPRUNTS EN RETARD")
+	cli.AfficherTitre("⚠️ EMPRUNTS EN RETARD")
 
 	emprunts := cli.gestionnaireEmprunts.ListerEmpruntsEnRetard()
 
 	if len(emprunts) == 0 {
-		AfficherSucces("Aucun emprunt en retard ! 🎉")
+		cli.AfficherSucces("Aucun emprunt en retard ! 🎉")
 		return
 	}
 
-	AfficherAvertissement(fmt.Sprintf("%d emprunt(s) en retard détecté(s) !", len(emprunts)))
+	cli.AfficherAvertissement(fmt.Sprintf("%d emprunt(s) en retard détecté(s) !", len(emprunts)))
 	cli.afficherTableauEmprunts(emprunts)
 
 	// Afficher les détails des retards
-	fmt.Println("\nDétails des retards :")
+	fmt.Fprintln(cli.sortie, "\nDétails des retards :")
 	for _, emprunt := range emprunts {
 		joursRetard := emprunt.CalculerJoursRetard()
-		fmt.Printf("• %s (%s) - %d jour(s) de retard\n",
+		fmt.Fprintf(cli.sortie, "• %s (%s) - %d jour(s) de retard\n",
 			emprunt.TitreLivre, emprunt.NomMembre, joursRetard)
 	}
 }
 
 func (cli *CLI) listerEmpruntsParMembre() {
-	AfficherTitre("👤 EMPRUNTS D'UN MEMBRE")
+	cli.AfficherTitre("👤 EMPRUNTS D'UN MEMBRE")
 
-	membreID := LireEntreeEntierObligatoire("ID du membre : ")
+	membreID := cli.LireEntreeEntierObligatoire("ID du membre : ")
 
 	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(membreID)
 	if membre == nil {
-		AfficherErreur(fmt.Sprintf("Aucun membre trouvé avec l'ID %d", membreID))
+		cli.AfficherErreur(fmt.Sprintf("Aucun membre trouvé avec l'ID %d", membreID))
 		return
 	}
 
 	emprunts := cli.gestionnaireEmprunts.ListerEmpruntsParMembre(membreID)
 
-	fmt.Printf("\nEmprunts de %s :\n", membre.Nom)
+	fmt.Fprintf(cli.sortie, "\nEmprunts de %s :\n", membre.Nom)
 
 	if len(emprunts) == 0 {
-		AfficherInfo("Aucun emprunt pour ce membre.")
+		cli.AfficherInfo("Aucun emprunt pour ce membre.")
 		return
 	}
 
@@ -680,6 +1047,7 @@ func (cli *CLI) listerEmpruntsParMembre() {
 	enCours := 0
 	rendus := 0
 	enRetard := 0
+	perdus := 0
 
 	for _, emprunt := range emprunts {
 		switch emprunt.Statut {
@@ -689,11 +1057,16 @@ func (cli *CLI) listerEmpruntsParMembre() {
 			rendus++
 		case models.STATUT_EN_RETARD:
 			enRetard++
+		case models.STATUT_PERDU:
+			perdus++
 		}
 	}
 
-	fmt.Printf("\nRésumé : %d total | %d en cours | %d rendus | %d en retard\n",
+	fmt.Fprintf(cli.sortie, "\nRésumé : %d total | %d en cours | %d rendus | %d en retard\n",
 		len(emprunts), enCours, rendus, enRetard)
+	if perdus > 0 {
+		fmt.Fprintf(cli.sortie, "%d livre(s) perdu(s) pendant l'emprunt\n", perdus)
+	}
 }
 
 // ========================================
@@ -701,29 +1074,29 @@ func (cli *CLI) listerEmpruntsParMembre() {
 // ========================================
 
 func (cli *CLI) listerHistoriqueLivre() {
-	AfficherTitre("📖 HISTORIQUE D'UN LIVRE")
+	cli.AfficherTitre("📖 HISTORIQUE D'UN LIVRE")
 
-	livreID := LireEntreeEntierObligatoire("ID du livre : ")
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
 
 	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
 	if livre == nil {
-		AfficherErreur(fmt.Sprintf("Aucun livre trouvé avec l'ID %d", livreID))
+		cli.AfficherErreur(fmt.Sprintf("Aucun livre trouvé avec l'ID %d", livreID))
 		return
 	}
 
 	emprunts := cli.gestionnaireEmprunts.ListerEmpruntsParLivre(livreID)
 
-	fmt.Printf("\nHistorique des emprunts de '%s' :\n", livre.Titre)
+	fmt.Fprintf(cli.sortie, "\nHistorique des emprunts de '%s' :\n", livre.Titre)
 
 	if len(emprunts) == 0 {
-		AfficherInfo("Ce livre n'a jamais été emprunté.")
+		cli.AfficherInfo("Ce livre n'a jamais été emprunté.")
 		return
 	}
 
 	cli.afficherTableauEmprunts(emprunts)
 
 	// Afficher des statistiques sur ce livre
-	fmt.Printf("\nStatistiques : %d emprunt(s) au total\n", len(emprunts))
+	fmt.Fprintf(cli.sortie, "\nStatistiques : %d emprunt(s) au total\n", len(emprunts))
 
 	// Calculer la durée moyenne d'emprunt pour ce livre
 	var totalJours int
@@ -739,23 +1112,23 @@ func (cli *CLI) listerHistoriqueLivre() {
 
 	if count > 0 {
 		dureeM := float64(totalJours) / float64(count)
-		fmt.Printf("Durée moyenne d'emprunt : %.1f jours\n", dureeM)
+		fmt.Fprintf(cli.sortie, "Durée moyenne d'emprunt : %.1f jours\n", dureeM)
 	}
 }
 
 func (cli *CLI) prolongerEmprunt() error {
-	AfficherTitre("📅 PROLONGER UN EMPRUNT")
+	cli.AfficherTitre("📅 PROLONGER UN EMPRUNT")
 
 	empruntsEnCours := cli.gestionnaireEmprunts.ListerEmpruntsEnCours()
 	if len(empruntsEnCours) == 0 {
-		AfficherInfo("Aucun emprunt en cours à prolonger.")
+		cli.AfficherInfo("Aucun emprunt en cours à prolonger.")
 		return nil
 	}
 
-	fmt.Println("\nEmprunts en cours :")
+	fmt.Fprintln(cli.sortie, "\nEmprunts en cours :")
 	cli.afficherTableauEmprunts(empruntsEnCours)
 
-	empruntID := LireEntreeEntierObligatoire("\nID de l'emprunt à prolonger : ")
+	empruntID := cli.LireEntreeEntierObligatoire("\nID de l'emprunt à prolonger : ")
 
 	// Vérifier que l'emprunt existe
 	emprunt, _ := cli.gestionnaireEmprunts.TrouverEmpruntParID(empruntID)
@@ -764,14 +1137,14 @@ func (cli *CLI) prolongerEmprunt() error {
 	}
 
 	// Afficher les détails de l'emprunt
-	fmt.Println("\nEmprunt à prolonger :")
-	emprunt.AfficherDetails()
+	fmt.Fprintln(cli.sortie, "\nEmprunt à prolonger :")
+	emprunt.AfficherDetails(cli.sortie)
 
-	jours := LireEntreeEntierAvecLimites("\nNombre de jours supplémentaires (1-30) : ", 1, 30)
+	jours := cli.LireEntreeEntierAvecLimites("\nNombre de jours supplémentaires (1-30) : ", 1, 30)
 
 	// Demander confirmation
-	if !LireConfirmation(fmt.Sprintf("Confirmer la prolongation de %d jour(s) ?", jours)) {
-		AfficherInfo("Prolongation annulée.")
+	if !cli.LireConfirmation(fmt.Sprintf("Confirmer la prolongation de %d jour(s) ?", jours)) {
+		cli.AfficherInfo("Prolongation annulée.")
 		return nil
 	}
 
@@ -780,12 +1153,12 @@ func (cli *CLI) prolongerEmprunt() error {
 		return err
 	}
 
-	AfficherSucces(fmt.Sprintf("Emprunt prolongé de %d jour(s) ! 📅", jours))
+	cli.AfficherSucces(fmt.Sprintf("Emprunt prolongé de %d jour(s) ! 📅", jours))
 
 	// Afficher la nouvelle date limite
 	empruntMisAJour, _ := cli.gestionnaireEmprunts.TrouverEmpruntParID(empruntID)
 	if empruntMisAJour != nil {
-		AfficherInfo(fmt.Sprintf("Nouvelle date limite : %s",
+		cli.AfficherInfo(fmt.Sprintf("Nouvelle date limite : %s",
 			empruntMisAJour.DateRetourPrevu.Format("02/01/2006")))
 	}
 
@@ -793,34 +1166,34 @@ func (cli *CLI) prolongerEmprunt() error {
 }
 
 func (cli *CLI) listerEmpruntsARendreAujourdhui() {
-	AfficherTitre("📅 EMPRUNTS À RENDRE AUJOURD'HUI")
+	cli.AfficherTitre("📅 EMPRUNTS À RENDRE AUJOURD'HUI")
 
 	emprunts := cli.gestionnaireEmprunts.ObtenirEmpruntsARendreAujourdhui()
 
 	if len(emprunts) == 0 {
-		AfficherSucces("Aucun emprunt à rendre aujourd'hui ! 🎉")
+		cli.AfficherSucces("Aucun emprunt à rendre aujourd'hui ! 🎉")
 		return
 	}
 
-	AfficherAvertissement(fmt.Sprintf("%d emprunt(s) à rendre aujourd'hui :", len(emprunts)))
+	cli.AfficherAvertissement(fmt.Sprintf("%d emprunt(s) à rendre aujourd'hui :", len(emprunts)))
 	cli.afficherTableauEmprunts(emprunts)
 
-	fmt.Println("\nRappel : Ces emprunts deviennent en retard à partir de demain !")
+	fmt.Fprintln(cli.sortie, "\nRappel : Ces emprunts deviennent en retard à partir de demain !")
 }
 
 func (cli *CLI) annulerEmprunt() error {
-	AfficherTitre("❌ ANNULER UN EMPRUNT")
+	cli.AfficherTitre("❌ ANNULER UN EMPRUNT")
 
 	empruntsEnCours := cli.gestionnaireEmprunts.ListerEmpruntsEnCours()
 	if len(empruntsEnCours) == 0 {
-		AfficherInfo("Aucun emprunt en cours à annuler.")
+		cli.AfficherInfo("Aucun emprunt en cours à annuler.")
 		return nil
 	}
 
-	fmt.Println("\nEmprunts en cours :")
+	fmt.Fprintln(cli.sortie, "\nEmprunts en cours :")
 	cli.afficherTableauEmprunts(empruntsEnCours)
 
-	empruntID := LireEntreeEntierObligatoire("\nID de l'emprunt à annuler : ")
+	empruntID := cli.LireEntreeEntierObligatoire("\nID de l'emprunt à annuler : ")
 
 	// Vérifier que l'emprunt existe
 	emprunt, _ := cli.gestionnaireEmprunts.TrouverEmpruntParID(empruntID)
@@ -829,23 +1202,23 @@ func (cli *CLI) annulerEmprunt() error {
 	}
 
 	// Afficher les détails de l'emprunt
-	fmt.Println("\nEmprunt à annuler :")
-	emprunt.AfficherDetails()
+	fmt.Fprintln(cli.sortie, "\nEmprunt à annuler :")
+	emprunt.AfficherDetails(cli.sortie)
 
-	AfficherAvertissement("⚠️ ATTENTION : L'annulation d'un emprunt est une action administrative exceptionnelle.")
-	AfficherInfo("Le livre redeviendra disponible et les compteurs du membre seront mis à jour.")
+	cli.AfficherAvertissement("⚠️ ATTENTION : L'annulation d'un emprunt est une action administrative exceptionnelle.")
+	cli.AfficherInfo("Le livre redeviendra disponible et les compteurs du membre seront mis à jour.")
 
 	// Demander confirmation avec double vérification
-	if !LireConfirmation("Êtes-vous sûr de vouloir annuler cet emprunt ?") {
-		AfficherInfo("Annulation de l'emprunt abandonnée.")
+	if !cli.LireConfirmation("Êtes-vous sûr de vouloir annuler cet emprunt ?") {
+		cli.AfficherInfo("Annulation de l'emprunt abandonnée.")
 		return nil
 	}
 
 	// Deuxième confirmation
-	fmt.Print("Pour confirmer, tapez 'ANNULER' en majuscules : ")
-	confirmation := LireEntree()
+	fmt.Fprint(cli.sortie, "Pour confirmer, tapez 'ANNULER' en majuscules : ")
+	confirmation := cli.LireEntree()
 	if confirmation != "ANNULER" {
-		AfficherInfo("Annulation de l'emprunt abandonnée.")
+		cli.AfficherInfo("Annulation de l'emprunt abandonnée.")
 		return nil
 	}
 
@@ -854,46 +1227,40 @@ func (cli *CLI) annulerEmprunt() error {
 		return err
 	}
 
-	AfficherSucces("Emprunt annulé avec succès ! ❌")
-	AfficherInfo("Le livre est maintenant disponible pour un nouvel emprunt.")
+	cli.AfficherSucces("Emprunt annulé avec succès ! ❌")
+	cli.AfficherInfo("Le livre est maintenant disponible pour un nouvel emprunt.")
 
 	return nil
 }
 
 func (cli *CLI) genererRapportEmprunts() {
-	AfficherTitre("📊 RAPPORT DÉTAILLÉ DES EMPRUNTS")
+	cli.AfficherTitre("📊 RAPPORT DÉTAILLÉ DES EMPRUNTS")
 
 	rapport := cli.gestionnaireEmprunts.ExporterRapportEmprunts()
-	fmt.Println(rapport)
+	fmt.Fprintln(cli.sortie, rapport)
 
 	// Afficher des statistiques supplémentaires
-	stats := cli.gestionnaireEmprunts.ObtenirStatistiques()
+	stats := cli.gestionnaireEmprunts.ObtenirStatistiques(statistiques.Periode{}, 0)
 
-	fmt.Println("\n=== STATISTIQUES AVANCÉES ===")
+	fmt.Fprintln(cli.sortie, "\n=== STATISTIQUES AVANCÉES ===")
 
-	if duree, ok := stats["duree_moyenne_jours"].(float64); ok && duree > 0 {
-		fmt.Printf("Durée moyenne des emprunts : %.1f jours\n", duree)
+	if stats.DureeMoyenneJours > 0 {
+		fmt.Fprintf(cli.sortie, "Durée moyenne des emprunts : %.1f jours\n", stats.DureeMoyenneJours)
 	}
 
-	if membrePlusActifMap, ok := stats["membre_plus_actif"].(map[string]interface{}); ok {
-		nom := membrePlusActifMap["nom"].(string)
-		count := membrePlusActifMap["emprunts"].(int)
-		fmt.Printf("Membre le plus actif : %s (%d emprunts)\n", nom, count)
+	if len(stats.TopMembres) > 0 {
+		fmt.Fprintf(cli.sortie, "Membre le plus actif : %s (%d emprunts)\n", stats.TopMembres[0].Nom, stats.TopMembres[0].Emprunts)
 	}
 
-	if livrePlusEmprunteMap, ok := stats["livre_plus_emprunte"].(map[string]interface{}); ok {
-		titre := livrePlusEmprunteMap["titre"].(string)
-		count := livrePlusEmprunteMap["emprunts"].(int)
-		fmt.Printf("Livre le plus emprunté : %s (%d emprunts)\n", titre, count)
+	if len(stats.TopLivres) > 0 {
+		fmt.Fprintf(cli.sortie, "Livre le plus emprunté : %s (%d emprunts)\n", stats.TopLivres[0].Nom, stats.TopLivres[0].Emprunts)
 	}
 
-	// Afficher les emprunts par mois si disponible
-	if empruntsParMois, ok := stats["emprunts_par_mois"].(map[string]int); ok {
-		fmt.Println("\n=== EMPRUNTS PAR MOIS (12 derniers mois) ===")
-		for mois, count := range empruntsParMois {
-			if count > 0 {
-				fmt.Printf("%s : %d emprunt(s)\n", mois, count)
-			}
+	// Afficher les emprunts par mois, du plus ancien au plus récent
+	fmt.Fprintln(cli.sortie, "\n=== EMPRUNTS PAR MOIS (12 derniers mois) ===")
+	for _, mois := range stats.ParMois {
+		if mois.Valeur > 0 {
+			fmt.Fprintf(cli.sortie, "%s : %d emprunt(s)\n", mois.Libelle, mois.Valeur)
 		}
 	}
 }
@@ -902,89 +1269,147 @@ func (cli *CLI) genererRapportEmprunts() {
 // STATISTIQUES COMPLÈTES
 // ========================================
 
-func (cli *CLI) afficherStatistiques() {
-	AfficherTitre("📊 STATISTIQUES COMPLÈTES DE LA LIBRAIRIE")
+// afficherStatistiques présente les livres, les membres et l'activité d'une période ;
+// au format json, le tout est écrit tel que le calculent les gestionnaires
+func (cli *CLI) afficherStatistiques() error {
+	cli.AfficherTitre("📊 STATISTIQUES COMPLÈTES DE LA LIBRAIRIE")
+
+	fmt.Fprintf(cli.sortie, "Période (%s, vide = toute l'activité) : ", statistiques.FORMATS_PERIODE)
+	var periode statistiques.Periode
+	if saisie := cli.LireEntree(); saisie != "" {
+		var err error
+		if periode, err = statistiques.LirePeriode(saisie, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// En réseau, les chiffres peuvent se limiter à une succursale
+	succursaleID := 0
+	if cli.gestionnaireSuccursales.EstEnReseau() {
+		fmt.Fprint(cli.sortie, "Code de la succursale (vide = tout le réseau) : ")
+		if code := cli.LireEntree(); code != "" {
+			succursale := cli.gestionnaireSuccursales.TrouverSuccursaleParCode(code)
+			if succursale == nil {
+				return fmt.Errorf("aucune succursale ne porte le code '%s'", models.NormaliserCodeSuccursale(code))
+			}
+			succursaleID = succursale.ID
+		}
+	}
+
+	statsLivres := cli.gestionnaireLivres.ObtenirStatistiques(periode, succursaleID)
+	statsMembres := cli.gestionnaireMembres.ObtenirStatistiques(periode, succursaleID)
+	statsEmprunts := cli.gestionnaireEmprunts.ObtenirStatistiques(periode, succursaleID)
+
+	if cli.format == affichage.FORMAT_JSON {
+		encodeur := json.NewEncoder(cli.sortie)
+		encodeur.SetIndent("", "  ")
+		return encodeur.Encode(struct {
+			Livres   statistiques.Livres   `json:"livres"`
+			Membres  statistiques.Membres  `json:"membres"`
+			Emprunts statistiques.Emprunts `json:"emprunts"`
+		}{statsLivres, statsMembres, statsEmprunts})
+	}
+
+	fmt.Fprintf(cli.sortie, "\n🗓️  Période : %s\n", periode.Libelle())
+	if succursaleID != 0 {
+		fmt.Fprintf(cli.sortie, "🏢 Succursale : %s\n", cli.gestionnaireSuccursales.NomSuccursale(succursaleID))
+	}
+	fmt.Fprintln(cli.sortie)
 
 	// Statistiques des livres
-	statsLivres := cli.gestionnaireLivres.ObtenirStatistiques()
-	fmt.Printf("📖 LIVRES :\n")
-	fmt.Printf("   Total : %d livre(s)\n", statsLivres["total"])
-	if statsLivres["total"].(int) > 0 {
-		fmt.Printf("   Disponibles : %d\n", statsLivres["disponibles"])
-		fmt.Printf("   Empruntés : %d\n", statsLivres["empruntes"])
-
-		if plusEmprunte, existe := statsLivres["plus_emprunte"]; existe {
-			livre := plusEmprunte.(models.Livre)
-			fmt.Printf("   Plus emprunté : %s (%d emprunt(s))\n", livre.Titre, livre.NombreEmprunts)
+	fmt.Fprintf(cli.sortie, "📖 LIVRES :\n")
+	fmt.Fprintf(cli.sortie, "   Total : %d livre(s)\n", statsLivres.Total)
+	if statsLivres.Retires > 0 {
+		fmt.Fprintf(cli.sortie, "   Retirés du fonds : %d\n", statsLivres.Retires)
+	}
+	if statsLivres.Total > 0 {
+		fmt.Fprintf(cli.sortie, "   Disponibles : %d\n", statsLivres.Disponibles)
+		fmt.Fprintf(cli.sortie, "   Empruntés : %d\n", statsLivres.Empruntes)
+		if statsLivres.EnTransit > 0 {
+			fmt.Fprintf(cli.sortie, "   En transit : %d\n", statsLivres.EnTransit)
+		}
+		if periode != (statistiques.Periode{}) {
+			fmt.Fprintf(cli.sortie, "   Entrés au catalogue sur la période : %d\n", statsLivres.Ajoutes)
 		}
 
-		// Afficher les genres les plus populaires
-		if parGenre, existe := statsLivres["par_genre"].(map[string]int); existe {
-			fmt.Println("\n   Répartition par genre :")
-			for genre, count := range parGenre {
-				fmt.Printf("     %s : %d livre(s)\n", genre, count)
+		if livre := statsLivres.PlusEmprunte; livre != nil {
+			fmt.Fprintf(cli.sortie, "   Plus emprunté : %s (%d emprunt(s))\n", livre.Nom, livre.Emprunts)
+		}
+
+		// Afficher la répartition par genre, sous-genres inclus
+		if len(statsLivres.ParGenreCumule) > 0 {
+			fmt.Fprintln(cli.sortie, "\n   Répartition par genre (sous-genres inclus) :")
+			for _, genre := range statsLivres.ParGenreCumule {
+				indentation := strings.Repeat("  ", cli.gestionnaireGenres.Profondeur(genre.ID))
+				fmt.Fprintf(cli.sortie, "     %s%s : %d livre(s)\n", indentation, genre.Libelle, genre.Nombre)
 			}
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(cli.sortie)
 
 	// Statistiques des membres
-	statsMembres := cli.gestionnaireMembres.ObtenirStatistiques()
-	fmt.Printf("👥 MEMBRES :\n")
-	fmt.Printf("   Total : %d membre(s)\n", statsMembres["total"])
-	if statsMembres["total"].(int) > 0 {
-		fmt.Printf("   Actifs : %d\n", statsMembres["actifs"])
-		fmt.Printf("   Suspendus : %d\n", statsMembres["suspendus"])
-
-		if plusActif, existe := statsMembres["plus_actif"]; existe {
-			membre := plusActif.(models.Membre)
-			fmt.Printf("   Plus actif : %s (%d emprunt(s))\n", membre.Nom, membre.NombreEmprunts)
+	fmt.Fprintf(cli.sortie, "👥 MEMBRES :\n")
+	fmt.Fprintf(cli.sortie, "   Total : %d membre(s)\n", statsMembres.Total)
+	if statsMembres.Radies > 0 {
+		fmt.Fprintf(cli.sortie, "   Radiés : %d\n", statsMembres.Radies)
+	}
+	if statsMembres.Total > 0 {
+		fmt.Fprintf(cli.sortie, "   Actifs : %d\n", statsMembres.Actifs)
+		fmt.Fprintf(cli.sortie, "   Suspendus : %d\n", statsMembres.Suspendus)
+		if periode != (statistiques.Periode{}) {
+			fmt.Fprintf(cli.sortie, "   Inscrits sur la période : %d\n", statsMembres.Inscrits)
+		}
+
+		if membre := statsMembres.PlusActif; membre != nil {
+			fmt.Fprintf(cli.sortie, "   Plus actif : %s (%d emprunt(s))\n", membre.Nom, membre.Emprunts)
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(cli.sortie)
 
 	// Statistiques des emprunts
-	statsEmprunts := cli.gestionnaireEmprunts.ObtenirStatistiques()
-	fmt.Printf("📋 EMPRUNTS :\n")
-	fmt.Printf("   Total : %d emprunt(s)\n", statsEmprunts["total"])
-	if statsEmprunts["total"].(int) > 0 {
-		fmt.Printf("   En cours : %d\n", statsEmprunts["en_cours"])
-		fmt.Printf("   Rendus : %d\n", statsEmprunts["rendus"])
-		fmt.Printf("   En retard : %d\n", statsEmprunts["en_retard"])
-
-		if duree, existe := statsEmprunts["duree_moyenne_jours"].(float64); existe && duree > 0 {
-			fmt.Printf("   Durée moyenne : %.1f jours\n", duree)
+	fmt.Fprintf(cli.sortie, "📋 EMPRUNTS :\n")
+	fmt.Fprintf(cli.sortie, "   Total : %d emprunt(s)\n", statsEmprunts.Total)
+	if statsEmprunts.Total > 0 {
+		fmt.Fprintf(cli.sortie, "   En cours : %d\n", statsEmprunts.EnCours)
+		fmt.Fprintf(cli.sortie, "   Rendus : %d\n", statsEmprunts.Rendus)
+		fmt.Fprintf(cli.sortie, "   En retard : %d\n", statsEmprunts.EnRetard)
+		if statsEmprunts.Perdus > 0 {
+			fmt.Fprintf(cli.sortie, "   Perdus : %d\n", statsEmprunts.Perdus)
+		}
+
+		if statsEmprunts.DureeMoyenneJours > 0 {
+			fmt.Fprintf(cli.sortie, "   Durée moyenne : %.1f jours\n", statsEmprunts.DureeMoyenneJours)
 		}
 	}
 
 	// Alertes et recommandations
-	fmt.Println("\n=== ALERTES ET RECOMMANDATIONS ===")
+	fmt.Fprintln(cli.sortie, "\n=== ALERTES ET RECOMMANDATIONS ===")
 
 	// Vérifier les emprunts en retard
 	empruntsEnRetard := cli.gestionnaireEmprunts.ListerEmpruntsEnRetard()
 	if len(empruntsEnRetard) > 0 {
-		AfficherAvertissement(fmt.Sprintf("%d emprunt(s) en retard nécessitent un suivi", len(empruntsEnRetard)))
+		cli.AfficherAvertissement(fmt.Sprintf("%d emprunt(s) en retard nécessitent un suivi", len(empruntsEnRetard)))
 	}
 
 	// Vérifier les emprunts à rendre aujourd'hui
 	empruntsAujourdhui := cli.gestionnaireEmprunts.ObtenirEmpruntsARendreAujourdhui()
 	if len(empruntsAujourdhui) > 0 {
-		AfficherInfo(fmt.Sprintf("%d emprunt(s) à rendre aujourd'hui", len(empruntsAujourdhui)))
+		cli.AfficherInfo(fmt.Sprintf("%d emprunt(s) à rendre aujourd'hui", len(empruntsAujourdhui)))
 	}
 
 	// Taux d'occupation de la librairie
-	if statsLivres["total"].(int) > 0 {
-		tauxOccupation := float64(statsLivres["empruntes"].(int)) / float64(statsLivres["total"].(int)) * 100
-		fmt.Printf("\n📈 Taux d'occupation : %.1f%% des livres sont actuellement empruntés\n", tauxOccupation)
-
-		if tauxOccupation > 80 {
-			AfficherInfo("Excellente fréquentation ! Considérez l'ajout de nouveaux livres.")
-		} else if tauxOccupation < 20 {
-			AfficherInfo("Faible taux d'emprunt. Envisagez des actions de promotion.")
+	if statsLivres.Total > 0 {
+		fmt.Fprintf(cli.sortie, "\n📈 Taux d'occupation : %.1f%% des livres sont actuellement empruntés\n", statsLivres.TauxOccupation)
+
+		if statsLivres.TauxOccupation > 80 {
+			cli.AfficherInfo("Excellente fréquentation ! Considérez l'ajout de nouveaux livres (voir les conseils d'achat).")
+		} else if statsLivres.TauxOccupation < 20 {
+			cli.AfficherInfo("Faible taux d'emprunt. Envisagez des actions de promotion.")
 		}
 	}
+	return nil
 }
 
 // ========================================
@@ -992,115 +1417,57 @@ func (cli *CLI) afficherStatistiques() {
 // ========================================
 
 func (cli *CLI) afficherTableauLivres(livres []models.Livre) {
-	fmt.Printf("\n")
-	fmt.Printf("│ %-3s │ %-25s │ %-20s │ %-15s │ %-12s │\n", "ID", "Titre", "Auteur", "Genre", "Statut")
-	fmt.Printf("├%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 22),
-		strings.Repeat("─", 17),
-		strings.Repeat("─", 14))
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "Auteur", Cle: "auteur", Min: 10},
+		affichage.Colonne{Titre: "Genre", Cle: "genre", Min: 8},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
 
 	for _, livre := range livres {
-		titre := livre.Titre
-		if len(titre) > 25 {
-			titre = titre[:22] + "..."
-		}
-
-		auteur := livre.Auteur
-		if len(auteur) > 20 {
-			auteur = auteur[:17] + "..."
-		}
-
-		genre := livre.Genre
-		if len(genre) > 15 {
-			genre = genre[:12] + "..."
-		}
-
 		statut := "📗 Disponible"
 		if !livre.EstDisponible() {
 			statut = "📕 Emprunté"
 		}
-
-		fmt.Printf("│ %-3d │ %-25s │ %-20s │ %-15s │ %-12s │\n",
-			livre.ID, titre, auteur, genre, statut)
+		tableau.AjouterLigne(strconv.Itoa(livre.ID), livre.Titre, livre.Auteur, livre.Genre, statut)
 	}
 
-	fmt.Printf("└%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 22),
-		strings.Repeat("─", 17),
-		strings.Repeat("─", 14))
-
-	fmt.Printf("\nTotal : %d livre(s)\n", len(livres))
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d livre(s)", len(livres)))
 }
 
 func (cli *CLI) afficherTableauMembres(membres []models.Membre) {
-	fmt.Printf("\n")
-	fmt.Printf("│ %-3s │ %-25s │ %-25s │ %-9s │ %-12s │\n", "ID", "Nom", "Email", "Emprunts", "Statut")
-	fmt.Printf("├%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 11),
-		strings.Repeat("─", 14))
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Nom", Cle: "nom", Min: 10},
+		affichage.Colonne{Titre: "Email", Cle: "email", Min: 12},
+		affichage.Colonne{Titre: "Emprunts", Cle: "emprunts"},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
 
 	for _, membre := range membres {
-		nom := membre.Nom
-		if len(nom) > 25 {
-			nom = nom[:22] + "..."
-		}
-
-		email := membre.Email
-		if len(email) > 25 {
-			email = email[:22] + "..."
-		}
-
-		emprunts := fmt.Sprintf("%d/%d", membre.EmpruntsActifs, models.LIMITE_EMPRUNTS_SIMULTANES)
+		emprunts := fmt.Sprintf("%d/%d", membre.EmpruntsActifs, cli.config.Emprunts.LimiteSimultanes)
 
 		statut := "✅ Actif"
 		if !membre.Actif {
 			statut = "❌ Suspendu"
 		}
-
-		fmt.Printf("│ %-3d │ %-25s │ %-25s │ %-9s │ %-12s │\n",
-			membre.ID, nom, email, emprunts, statut)
+		tableau.AjouterLigne(strconv.Itoa(membre.ID), membre.Nom, membre.Email, emprunts, statut)
 	}
 
-	fmt.Printf("└%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 11),
-		strings.Repeat("─", 14))
-
-	fmt.Printf("\nTotal : %d membre(s)\n", len(membres))
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d membre(s)", len(membres)))
 }
 
 func (cli *CLI) afficherTableauEmprunts(emprunts []models.Emprunt) {
-	fmt.Printf("\n")
-	fmt.Printf("│ %-3s │ %-25s │ %-20s │ %-10s │ %-12s │\n", "ID", "Livre", "Membre", "Emprunté", "Statut")
-	fmt.Printf("├%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 22),
-		strings.Repeat("─", 12),
-		strings.Repeat("─", 14))
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Livre", Cle: "livre", Min: 12},
+		affichage.Colonne{Titre: "Membre", Cle: "membre", Min: 10},
+		affichage.Colonne{Titre: "Emprunté", Cle: "date_emprunt"},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
 
 	for _, emprunt := range emprunts {
-		titre := emprunt.TitreLivre
-		if len(titre) > 25 {
-			titre = titre[:22] + "..."
-		}
-
-		nom := emprunt.NomMembre
-		if len(nom) > 20 {
-			nom = nom[:17] + "..."
-		}
-
-		dateEmprunt := emprunt.DateEmprunt.Format("02/01/2006")
-
 		var statut string
 		switch emprunt.Statut {
 		case models.STATUT_EN_COURS:
@@ -1110,20 +1477,15 @@ func (cli *CLI) afficherTableauEmprunts(emprunts []models.Emprunt) {
 		case models.STATUT_EN_RETARD:
 			joursRetard := emprunt.CalculerJoursRetard()
 			statut = fmt.Sprintf("⚠️ %d j retard", joursRetard)
+		case models.STATUT_PERDU:
+			statut = "❌ Perdu"
 		default:
 			statut = emprunt.Statut
 		}
 
-		fmt.Printf("│ %-3d │ %-25s │ %-20s │ %-10s │ %-12s │\n",
-			emprunt.ID, titre, nom, dateEmprunt, statut)
+		tableau.AjouterLigne(strconv.Itoa(emprunt.ID), emprunt.TitreLivre, emprunt.NomMembre,
+			emprunt.DateEmprunt.Format("02/01/2006"), statut)
 	}
 
-	fmt.Printf("└%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", 5),
-		strings.Repeat("─", 27),
-		strings.Repeat("─", 22),
-		strings.Repeat("─", 12),
-		strings.Repeat("─", 14))
-
-	fmt.Printf("\nTotal : %d emprunt(s)\n", len(emprunts))
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d emprunt(s)", len(emprunts)))
 }
@@ -0,0 +1,569 @@
+// ==========================================
+// internal/cli/menu_acquisitions.go
+// MENU DES ACQUISITIONS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// ========================================
+// SOUS-MENU ACQUISITIONS
+// Du conseil d'achat à la réception : les livres reçus entrent au catalogue
+// avec leur prix et leur fournisseur.
+// ========================================
+
+func (cli *CLI) menuAcquisitions() error {
+	for {
+		cli.AfficherTitre("🛒 ACQUISITIONS")
+		fmt.Fprintln(cli.sortie, "1. 🏢 Lister les fournisseurs")
+		fmt.Fprintln(cli.sortie, "2. ➕ Ajouter un fournisseur")
+		fmt.Fprintln(cli.sortie, "3. 📋 Lister les commandes")
+		fmt.Fprintln(cli.sortie, "4. 🔍 Détail d'une commande")
+		fmt.Fprintln(cli.sortie, "5. 🆕 Nouvelle commande")
+		fmt.Fprintln(cli.sortie, "6. ✏️  Compléter une commande en préparation")
+		fmt.Fprintln(cli.sortie, "7. ✉️  Passer une commande")
+		fmt.Fprintln(cli.sortie, "8. 📦 Réceptionner une livraison")
+		fmt.Fprintln(cli.sortie, "9. ❌ Annuler ou solder une commande")
+		fmt.Fprintln(cli.sortie, "10. 💰 Budgets de l'année")
+		fmt.Fprintln(cli.sortie, "11. ✏️  Définir un budget")
+		fmt.Fprintln(cli.sortie, "12. 📊 Dépenses par fournisseur")
+		fmt.Fprintln(cli.sortie, "13. 💶 Coût par emprunt")
+		fmt.Fprintln(cli.sortie, "14. 💡 Conseils d'achat et bon de commande")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 14)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerFournisseurs()
+		case 2:
+			err = cli.ajouterFournisseur()
+		case 3:
+			cli.listerCommandes()
+		case 4:
+			err = cli.afficherCommande()
+		case 5:
+			err = cli.nouvelleCommande()
+		case 6:
+			err = cli.completerCommande()
+		case 7:
+			err = cli.passerCommande()
+		case 8:
+			err = cli.receptionnerCommande()
+		case 9:
+			err = cli.annulerCommande()
+		case 10:
+			err = cli.afficherBudgets()
+		case 11:
+			err = cli.definirBudget()
+		case 12:
+			err = cli.afficherDepensesFournisseurs()
+		case 13:
+			cli.afficherCoutsParEmprunt()
+		case 14:
+			err = cli.conseillerAchats()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+// ========================================
+// FOURNISSEURS
+// ========================================
+
+func (cli *CLI) listerFournisseurs() {
+	cli.AfficherTitre("🏢 FOURNISSEURS")
+
+	fournisseurs := cli.gestionnaireAcquisitions.ListerFournisseurs()
+	if len(fournisseurs) == 0 {
+		cli.AfficherInfo("Aucun fournisseur enregistré.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Nom", Cle: "nom", Min: 12},
+		affichage.Colonne{Titre: "Email", Cle: "email", Min: 10},
+		affichage.Colonne{Titre: "Téléphone", Cle: "telephone"},
+	)
+	for _, fournisseur := range fournisseurs {
+		tableau.AjouterLigne(strconv.Itoa(fournisseur.ID), fournisseur.Nom, fournisseur.Email, fournisseur.Telephone)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d fournisseur(s)", len(fournisseurs)))
+}
+
+func (cli *CLI) ajouterFournisseur() error {
+	cli.AfficherTitre("➕ AJOUTER UN FOURNISSEUR")
+
+	nom := cli.LireEntreeObligatoire("Nom du fournisseur : ")
+	fmt.Fprint(cli.sortie, "Adresse email (facultative) : ")
+	email := cli.LireEntree()
+	fmt.Fprint(cli.sortie, "Numéro de téléphone (facultatif) : ")
+	telephone := cli.LireEntree()
+
+	fournisseur, err := cli.gestionnaireAcquisitions.AjouterFournisseur(nom, email, telephone)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Fournisseur '%s' ajouté (ID : %d).", fournisseur.Nom, fournisseur.ID))
+	return nil
+}
+
+// ========================================
+// COMMANDES
+// ========================================
+
+func (cli *CLI) listerCommandes() {
+	cli.AfficherTitre("📋 COMMANDES")
+
+	commandes := cli.gestionnaireAcquisitions.ListerCommandes()
+	if len(commandes) == 0 {
+		cli.AfficherInfo("Aucune commande enregistrée.")
+		return
+	}
+	cli.afficherTableauCommandes(commandes)
+}
+
+func (cli *CLI) afficherTableauCommandes(commandes []models.Commande) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Fournisseur", Cle: "fournisseur", Min: 12},
+		affichage.Colonne{Titre: "Créée le", Cle: "date_creation"},
+		affichage.Colonne{Titre: "Ex.", Cle: "exemplaires", Numerique: true},
+		affichage.Colonne{Titre: "Montant", Cle: "montant", Numerique: true},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
+
+	for _, commande := range commandes {
+		exemplaires := 0
+		for _, ligne := range commande.Lignes {
+			exemplaires += ligne.Quantite
+		}
+		tableau.AjouterLigne(strconv.Itoa(commande.ID), commande.NomFournisseur, commande.DateCreation.Format("02/01/2006"),
+			strconv.Itoa(exemplaires), commande.Total().String(), models.LibelleStatutCommande(commande.Statut))
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d commande(s)", len(commandes)))
+}
+
+func (cli *CLI) afficherCommande() error {
+	cli.AfficherTitre("🔍 DÉTAIL D'UNE COMMANDE")
+
+	commande, err := cli.choisirCommande("ID de la commande : ")
+	if err != nil {
+		return err
+	}
+	cli.afficherLignesCommande(commande)
+	return nil
+}
+
+// choisirCommande lit l'ID d'une commande et la retrouve
+func (cli *CLI) choisirCommande(message string) (*models.Commande, error) {
+	id := cli.LireEntreeEntierObligatoire(message)
+	commande := cli.gestionnaireAcquisitions.TrouverCommandeParID(id)
+	if commande == nil {
+		return nil, fmt.Errorf("aucune commande trouvée avec l'ID %d", id)
+	}
+	return commande, nil
+}
+
+func (cli *CLI) afficherLignesCommande(commande *models.Commande) {
+	fmt.Fprintf(cli.sortie, "\nCommande #%d chez %s — %s\n", commande.ID, commande.NomFournisseur, models.LibelleStatutCommande(commande.Statut))
+	if len(commande.Lignes) == 0 {
+		cli.AfficherInfo("La commande ne contient encore aucune ligne.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Ligne", Cle: "numero", Numerique: true},
+		affichage.Colonne{Titre: "ISBN", Cle: "isbn"},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "Qté", Cle: "quantite", Numerique: true},
+		affichage.Colonne{Titre: "Reçus", Cle: "recus", Numerique: true},
+		affichage.Colonne{Titre: "Prix", Cle: "prix_unitaire", Numerique: true},
+		affichage.Colonne{Titre: "Total", Cle: "total", Numerique: true},
+		affichage.Colonne{Titre: "Budget", Cle: "budget", Min: 8},
+	)
+	for _, ligne := range commande.Lignes {
+		tableau.AjouterLigne(strconv.Itoa(ligne.Numero), ligne.ISBN, ligne.Titre, strconv.Itoa(ligne.Quantite),
+			strconv.Itoa(ligne.Recus()), ligne.PrixUnitaire.String(), ligne.Total().String(), cli.nomBudget(ligne.BudgetID))
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %s", commande.Total()))
+}
+
+func (cli *CLI) nomBudget(id int) string {
+	if budget := cli.gestionnaireAcquisitions.TrouverBudgetParID(id); budget != nil {
+		return budget.Fonds
+	}
+	return "hors budget"
+}
+
+func (cli *CLI) nouvelleCommande() error {
+	cli.AfficherTitre("🆕 NOUVELLE COMMANDE")
+
+	fournisseurs := cli.gestionnaireAcquisitions.ListerFournisseurs()
+	if len(fournisseurs) == 0 {
+		cli.AfficherInfo("Ajoutez d'abord un fournisseur.")
+		return nil
+	}
+	for _, fournisseur := range fournisseurs {
+		fmt.Fprintf(cli.sortie, "%d. %s\n", fournisseur.ID, fournisseur.Nom)
+	}
+
+	commande, err := cli.gestionnaireAcquisitions.CreerCommande(cli.LireEntreeEntierObligatoire("ID du fournisseur : "))
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Commande #%d créée chez %s.", commande.ID, commande.NomFournisseur))
+	return cli.saisirLignesCommande(commande.ID)
+}
+
+func (cli *CLI) completerCommande() error {
+	cli.AfficherTitre("✏️  COMPLÉTER UNE COMMANDE")
+
+	commande, err := cli.choisirCommande("ID de la commande : ")
+	if err != nil {
+		return err
+	}
+	if commande.Statut != models.COMMANDE_BROUILLON {
+		return fmt.Errorf("la commande #%d a déjà été passée, elle ne peut plus être modifiée", commande.ID)
+	}
+	cli.afficherLignesCommande(commande)
+
+	if numero, err := cli.LireEntreeEntier("\nLigne à supprimer (vide = aucune) : "); err == nil {
+		if err := cli.gestionnaireAcquisitions.SupprimerLigne(commande.ID, numero); err != nil {
+			return err
+		}
+		cli.AfficherSucces(fmt.Sprintf("Ligne %d supprimée.", numero))
+	}
+	return cli.saisirLignesCommande(commande.ID)
+}
+
+// saisirLignesCommande ajoute des lignes jusqu'à un ISBN vide. Pour un livre
+// déjà au catalogue, la fiche existante suffit ; sinon on la saisit comme pour
+// un ajout, elle sera créée à la réception.
+func (cli *CLI) saisirLignesCommande(commandeID int) error {
+	fmt.Fprintln(cli.sortie, "\nSaisissez les lignes de la commande (ISBN vide pour terminer).")
+	for {
+		fmt.Fprint(cli.sortie, "\nISBN : ")
+		isbn := cli.LireEntree()
+		if isbn == "" {
+			break
+		}
+
+		var titre, auteur, genre, datePublication string
+		if livre, _ := cli.gestionnaireLivres.TrouverLivreParISBN(isbn); livre != nil {
+			cli.AfficherInfo(fmt.Sprintf("Déjà au catalogue : « %s » de %s.", livre.Titre, livre.Auteur))
+		} else {
+			titre = cli.LireEntreeObligatoire("Titre du livre : ")
+			auteur = cli.LireEntreeObligatoire("Auteur(s) (séparés par ';') : ")
+			genre = cli.choisirGenre("Choisissez le genre principal :")
+			datePublication = cli.LireEntreeObligatoire("Date de publication (JJ/MM/AAAA) : ")
+		}
+
+		quantite := cli.LireEntreeEntierAvecLimites("Quantité : ", 1, 999)
+		prix, err := models.LireMontant(cli.LireEntreeObligatoire("Prix unitaire (€) : "))
+		for err != nil {
+			fmt.Fprintf(cli.sortie, "❌ Erreur : %s\n", err.Error())
+			prix, err = models.LireMontant(cli.LireEntreeObligatoire("Prix unitaire (€) : "))
+		}
+
+		ligne, err := cli.gestionnaireAcquisitions.AjouterLigne(commandeID, isbn, titre, auteur, genre, datePublication, quantite, prix)
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+			continue
+		}
+		cli.AfficherSucces(fmt.Sprintf("Ligne %d : %d × « %s » à %s (budget : %s).",
+			ligne.Numero, ligne.Quantite, ligne.Titre, ligne.PrixUnitaire, cli.nomBudget(ligne.BudgetID)))
+	}
+
+	cli.afficherLignesCommande(cli.gestionnaireAcquisitions.TrouverCommandeParID(commandeID))
+	cli.AfficherInfo("La commande reste en préparation tant qu'elle n'est pas passée (option 7).")
+	return nil
+}
+
+func (cli *CLI) passerCommande() error {
+	cli.AfficherTitre("✉️  PASSER UNE COMMANDE")
+
+	commande, err := cli.choisirCommande("ID de la commande : ")
+	if err != nil {
+		return err
+	}
+	cli.afficherLignesCommande(commande)
+
+	if !cli.LireConfirmation(fmt.Sprintf("\nEnvoyer la commande #%d à %s ?", commande.ID, commande.NomFournisseur)) {
+		cli.AfficherInfo("Commande non passée.")
+		return nil
+	}
+	if err := cli.gestionnaireAcquisitions.PasserCommande(commande.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Commande #%d passée : %s engagés.", commande.ID, commande.Total()))
+	return nil
+}
+
+// receptionnerCommande demande, ligne par ligne, combien d'exemplaires sont
+// arrivés ; chacun reçoit un ID et un code-barres à coller sur le livre
+func (cli *CLI) receptionnerCommande() error {
+	cli.AfficherTitre("📦 RÉCEPTIONNER UNE LIVRAISON")
+
+	enAttente := cli.gestionnaireAcquisitions.ListerCommandesEnAttente()
+	if len(enAttente) == 0 {
+		cli.AfficherInfo("Aucune commande n'attend de livraison.")
+		return nil
+	}
+	cli.afficherTableauCommandes(enAttente)
+
+	commande, err := cli.choisirCommande("\nID de la commande livrée : ")
+	if err != nil {
+		return err
+	}
+	if !commande.EnAttente() {
+		return fmt.Errorf("la commande #%d n'attend pas de livraison (%s)", commande.ID, models.LibelleStatutCommande(commande.Statut))
+	}
+
+	var recus []models.Livre
+	for _, ligne := range commande.Lignes {
+		restant := ligne.Restant()
+		if restant == 0 {
+			continue
+		}
+
+		quantite := restant
+		for {
+			fmt.Fprintf(cli.sortie, "Reçus pour « %s » (%d attendu(s), vide = %d) : ", ligne.Titre, restant, restant)
+			saisie := cli.LireEntree()
+			if saisie == "" {
+				break
+			}
+			if n, err := strconv.Atoi(saisie); err == nil && n >= 0 && n <= restant {
+				quantite = n
+				break
+			}
+			fmt.Fprintf(cli.sortie, "❌ La valeur doit être entre 0 et %d.\n", restant)
+		}
+		if quantite == 0 {
+			continue
+		}
+
+		livres, err := cli.gestionnaireAcquisitions.Recevoir(commande.ID, ligne.Numero, quantite)
+		recus = append(recus, livres...)
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+	}
+
+	if len(recus) == 0 {
+		cli.AfficherInfo("Aucun livre réceptionné.")
+		return nil
+	}
+
+	fmt.Fprintln(cli.sortie, "\nLivres entrés au catalogue :")
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Code-barres", Cle: "code_barres"},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "Prix", Cle: "prix", Numerique: true},
+	)
+	for _, livre := range recus {
+		tableau.AjouterLigne(strconv.Itoa(livre.ID), livre.CodeBarres, livre.Titre, livre.Acquisition.Prix.String())
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d exemplaire(s)", len(recus)))
+
+	commande = cli.gestionnaireAcquisitions.TrouverCommandeParID(commande.ID)
+	cli.AfficherSucces(fmt.Sprintf("Commande #%d : %s.", commande.ID, strings.TrimSpace(models.LibelleStatutCommande(commande.Statut))))
+	return nil
+}
+
+func (cli *CLI) annulerCommande() error {
+	cli.AfficherTitre("❌ ANNULER OU SOLDER UNE COMMANDE")
+
+	commande, err := cli.choisirCommande("ID de la commande : ")
+	if err != nil {
+		return err
+	}
+
+	question := fmt.Sprintf("Annuler la commande #%d chez %s ?", commande.ID, commande.NomFournisseur)
+	if commande.Statut == models.COMMANDE_PARTIELLE {
+		question = fmt.Sprintf("Solder la commande #%d : les livres encore attendus ne seront plus attendus ?", commande.ID)
+	}
+	if !cli.LireConfirmation(question) {
+		cli.AfficherInfo("Commande conservée.")
+		return nil
+	}
+
+	if err := cli.gestionnaireAcquisitions.AnnulerCommande(commande.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Commande #%d : %s.", commande.ID, strings.TrimSpace(models.LibelleStatutCommande(commande.Statut))))
+	return nil
+}
+
+// ========================================
+// BUDGETS ET RAPPORTS
+// ========================================
+
+// lireAnnee lit une année, l'année en cours si la saisie est vide
+func (cli *CLI) lireAnnee() (int, error) {
+	annee := time.Now().Year()
+	fmt.Fprint(cli.sortie, "Année (vide = année en cours) : ")
+	if saisie := cli.LireEntree(); saisie != "" {
+		var err error
+		if annee, err = strconv.Atoi(saisie); err != nil {
+			return 0, fmt.Errorf("'%s' n'est pas une année valide", saisie)
+		}
+	}
+	return annee, nil
+}
+
+func (cli *CLI) afficherBudgets() error {
+	cli.AfficherTitre("💰 BUDGETS")
+
+	annee, err := cli.lireAnnee()
+	if err != nil {
+		return err
+	}
+
+	bilans := cli.gestionnaireAcquisitions.BilanBudgets(annee)
+	if len(bilans) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Aucun budget défini pour %d.", annee))
+		return nil
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Fonds", Cle: "fonds", Min: 10},
+		affichage.Colonne{Titre: "Genre", Cle: "genre"},
+		affichage.Colonne{Titre: "Budget", Cle: "montant", Numerique: true},
+		affichage.Colonne{Titre: "Engagé", Cle: "engage", Numerique: true},
+		affichage.Colonne{Titre: "Dépensé", Cle: "depense", Numerique: true},
+		affichage.Colonne{Titre: "Reste", Cle: "reste", Numerique: true},
+	)
+	var total services.BilanBudget
+	for _, bilan := range bilans {
+		genre := bilan.Budget.Genre
+		if genre == "" {
+			genre = "tous genres"
+		}
+		tableau.AjouterLigne(bilan.Budget.Fonds, genre, bilan.Budget.Montant.String(), bilan.Engage.String(), bilan.Depense.String(), bilan.Reste.String())
+		total.Budget.Montant += bilan.Budget.Montant
+		total.Engage += bilan.Engage
+		total.Depense += bilan.Depense
+		total.Reste += bilan.Reste
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %s budgétés, %s engagés, %s dépensés, %s restants",
+		total.Budget.Montant, total.Engage, total.Depense, total.Reste))
+	return nil
+}
+
+func (cli *CLI) definirBudget() error {
+	cli.AfficherTitre("✏️  DÉFINIR UN BUDGET")
+
+	fonds := cli.LireEntreeObligatoire("Nom du fonds : ")
+	fmt.Fprint(cli.sortie, "Genre couvert (vide = tous les autres genres) : ")
+	genre := cli.LireEntree()
+	annee, err := cli.lireAnnee()
+	if err != nil {
+		return err
+	}
+	montant, err := models.LireMontant(cli.LireEntreeObligatoire("Montant (€) : "))
+	if err != nil {
+		return err
+	}
+
+	budget, err := cli.gestionnaireAcquisitions.DefinirBudget(fonds, genre, annee, montant)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Budget du fonds '%s' : %s.", budget.Fonds, budget.Montant))
+	return nil
+}
+
+func (cli *CLI) afficherDepensesFournisseurs() error {
+	cli.AfficherTitre("📊 DÉPENSES PAR FOURNISSEUR")
+
+	fmt.Fprintf(cli.sortie, "Période (%s, vide = année en cours) : ", statistiques.FORMATS_PERIODE)
+	saisie := cli.LireEntree()
+	if saisie == "" {
+		saisie = strconv.Itoa(time.Now().Year())
+	}
+	periode, err := statistiques.LirePeriode(saisie, time.Now())
+	if err != nil {
+		return err
+	}
+
+	depenses := cli.gestionnaireAcquisitions.DepensesParFournisseur(periode)
+	if len(depenses) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Aucun livre reçu (%s).", periode.Libelle()))
+		return nil
+	}
+
+	fmt.Fprintf(cli.sortie, "\nLivres reçus (%s) :\n", periode.Libelle())
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Fournisseur", Cle: "fournisseur", Min: 12},
+		affichage.Colonne{Titre: "Commandes", Cle: "commandes", Numerique: true},
+		affichage.Colonne{Titre: "Exemplaires", Cle: "exemplaires", Numerique: true},
+		affichage.Colonne{Titre: "Montant", Cle: "montant", Numerique: true},
+	)
+	var total models.Montant
+	for _, depense := range depenses {
+		tableau.AjouterLigne(depense.Nom, strconv.Itoa(depense.Commandes), strconv.Itoa(depense.Exemplaires), depense.Montant.String())
+		total += depense.Montant
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %s chez %d fournisseur(s)", total, len(depenses)))
+	return nil
+}
+
+func (cli *CLI) afficherCoutsParEmprunt() {
+	cli.AfficherTitre("💶 COÛT PAR EMPRUNT")
+
+	couts := cli.gestionnaireAcquisitions.CoutsParEmprunt()
+	if len(couts) == 0 {
+		cli.AfficherInfo("Aucun livre n'a encore été reçu sur une commande.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "ISBN", Cle: "isbn"},
+		affichage.Colonne{Titre: "Ex.", Cle: "exemplaires", Numerique: true},
+		affichage.Colonne{Titre: "Coût", Cle: "cout", Numerique: true},
+		affichage.Colonne{Titre: "Emprunts", Cle: "emprunts", Numerique: true},
+		affichage.Colonne{Titre: "Par emprunt", Cle: "cout_par_emprunt", Numerique: true},
+	)
+	var cout models.Montant
+	emprunts := 0
+	for _, ligne := range couts {
+		parEmprunt := "jamais emprunté"
+		if ligne.Emprunts > 0 {
+			parEmprunt = ligne.CoutParEmprunt.String()
+		}
+		tableau.AjouterLigne(ligne.Titre, ligne.ISBN, strconv.Itoa(ligne.Exemplaires), ligne.Cout.String(), strconv.Itoa(ligne.Emprunts), parEmprunt)
+		cout += ligne.Cout
+		emprunts += ligne.Emprunts
+	}
+
+	resume := fmt.Sprintf("Total : %s pour %d emprunt(s)", cout, emprunts)
+	if emprunts > 0 {
+		resume += fmt.Sprintf(", soit %s par emprunt", services.DiviserMontant(cout, emprunts))
+	}
+	cli.afficherResultats(tableau, resume)
+}
@@ -0,0 +1,279 @@
+// ==========================================
+// internal/cli/menu_codebarres.go
+// MENU DES CODES-BARRES, ÉTIQUETTES ET CARTES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/codebarres"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// SOUS-MENU CODES-BARRES
+// ========================================
+
+func (cli *CLI) menuCodesBarres() error {
+	for {
+		cli.AfficherTitre("🏷️  CODES-BARRES, ÉTIQUETTES ET CARTES")
+		fmt.Fprintf(cli.sortie, "Symbologie : %s — fichiers écrits dans '%s/'\n", cli.config.Etiquettes.Symbologie, cli.config.Etiquettes.Dossier)
+		fmt.Fprintln(cli.sortie, "1. 🖼️  Code-barres d'un livre (PNG)")
+		fmt.Fprintln(cli.sortie, "2. 📄 Planche d'étiquettes de livres (PDF)")
+		fmt.Fprintln(cli.sortie, "3. 🖼️  Code d'une carte de membre (PNG)")
+		fmt.Fprintln(cli.sortie, "4. 💳 Planche de cartes de membre (PDF)")
+		fmt.Fprintln(cli.sortie, "5. 🔗 Reprendre le code-barres existant d'un livre")
+		fmt.Fprintln(cli.sortie, "6. 🔗 Reprendre le numéro de carte existant d'un membre")
+		fmt.Fprintln(cli.sortie, "7. 🔍 Identifier un code scanné")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 7)
+
+		var err error
+		switch choix {
+		case 1:
+			err = cli.exporterCodeLivre()
+		case 2:
+			err = cli.exporterPlancheLivres()
+		case 3:
+			err = cli.exporterCodeMembre()
+		case 4:
+			err = cli.exporterPlancheCartes()
+		case 5:
+			err = cli.attribuerCodeBarres()
+		case 6:
+			err = cli.attribuerNumeroCarte()
+		case 7:
+			cli.identifierCode()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) exporterCodeLivre() error {
+	cli.AfficherTitre("🖼️  CODE-BARRES D'UN LIVRE")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(cli.LireEntreeObligatoire("ID, ISBN ou code-barres du livre : "))
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à cette saisie")
+	}
+
+	chemin, err := cli.ecrirePNG("livre-"+livre.CodeBarres+".png", livre.CodeBarres)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Code-barres de « %s » écrit dans %s", livre.Titre, chemin))
+	return nil
+}
+
+func (cli *CLI) exporterPlancheLivres() error {
+	cli.AfficherTitre("📄 PLANCHE D'ÉTIQUETTES DE LIVRES")
+
+	fmt.Fprint(cli.sortie, "Rechercher les livres à étiqueter (vide = tout le catalogue) : ")
+	terme := cli.LireEntree()
+
+	livres := cli.gestionnaireLivres.ListerLivres()
+	if terme != "" {
+		livres = cli.gestionnaireLivres.RechercherLivres(terme)
+	}
+	if len(livres) == 0 {
+		cli.AfficherInfo("Aucun livre à étiqueter.")
+		return nil
+	}
+
+	etiquettes := make([]codebarres.Etiquette, 0, len(livres))
+	for _, livre := range livres {
+		etiquettes = append(etiquettes, codebarres.Etiquette{
+			Code:  livre.CodeBarres,
+			Titre: livre.Titre,
+			Ligne: livre.Auteur,
+		})
+	}
+
+	chemin, err := cli.ecrirePlanche("etiquettes", "Étiquettes de livres", etiquettes, codebarres.PLANCHE_ETIQUETTES)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%d étiquette(s) écrite(s) dans %s", len(etiquettes), chemin))
+	return nil
+}
+
+func (cli *CLI) exporterCodeMembre() error {
+	cli.AfficherTitre("🖼️  CODE D'UNE CARTE DE MEMBRE")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette saisie")
+	}
+
+	chemin, err := cli.ecrirePNG("carte-"+membre.NumeroCarte+".png", membre.NumeroCarte)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Code de la carte de %s écrit dans %s", membre.Nom, chemin))
+	return nil
+}
+
+func (cli *CLI) exporterPlancheCartes() error {
+	cli.AfficherTitre("💳 PLANCHE DE CARTES DE MEMBRE")
+
+	fmt.Fprint(cli.sortie, "Rechercher les membres (vide = tous les membres inscrits) : ")
+	terme := cli.LireEntree()
+
+	membres := cli.gestionnaireMembres.ListerMembres()
+	if terme != "" {
+		membres = cli.gestionnaireMembres.RechercherMembres(terme)
+	}
+	if len(membres) == 0 {
+		cli.AfficherInfo("Aucune carte à imprimer.")
+		return nil
+	}
+
+	cartes := make([]codebarres.Etiquette, 0, len(membres))
+	for _, membre := range membres {
+		cartes = append(cartes, codebarres.Etiquette{
+			Code:  membre.NumeroCarte,
+			Titre: membre.Nom,
+			Ligne: "Membre depuis le " + membre.DateInscription.Format("02/01/2006"),
+		})
+	}
+
+	chemin, err := cli.ecrirePlanche("cartes", "Cartes de membre", cartes, codebarres.PLANCHE_CARTES)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%d carte(s) écrite(s) dans %s", len(cartes), chemin))
+	return nil
+}
+
+func (cli *CLI) attribuerCodeBarres() error {
+	cli.AfficherTitre("🔗 REPRENDRE UN CODE-BARRES EXISTANT")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(cli.LireEntreeObligatoire("ID ou ISBN du livre : "))
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à cette saisie")
+	}
+	fmt.Fprintf(cli.sortie, "Livre : %s (code actuel : %s)\n", livre.Titre, livre.CodeBarres)
+
+	code := cli.LireEntreeObligatoire("Scannez l'étiquette déjà collée : ")
+	if err := cli.gestionnaireLivres.AttribuerCodeBarres(livre.ID, code); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Le code %s est désormais attribué à « %s ».", models.NormaliserCode(code), livre.Titre))
+	return nil
+}
+
+func (cli *CLI) attribuerNumeroCarte() error {
+	cli.AfficherTitre("🔗 REPRENDRE UN NUMÉRO DE CARTE EXISTANT")
+
+	membreID := cli.LireEntreeEntierObligatoire("ID du membre : ")
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParID(membreID)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", membreID)
+	}
+	fmt.Fprintf(cli.sortie, "Membre : %s (carte actuelle : %s)\n", membre.Nom, membre.NumeroCarte)
+
+	numero := cli.LireEntreeObligatoire("Scannez la carte du membre : ")
+	if err := cli.gestionnaireMembres.AttribuerNumeroCarte(membre.ID, numero); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("La carte %s est désormais attribuée à %s.", models.NormaliserCode(numero), membre.Nom))
+	return nil
+}
+
+// identifierCode indique à quoi correspond un code inconnu trouvé sur un livre ou une carte
+func (cli *CLI) identifierCode() {
+	cli.AfficherTitre("🔍 IDENTIFIER UN CODE")
+
+	code := cli.LireEntreeObligatoire("Scannez ou saisissez le code : ")
+	trouve := false
+
+	if livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(code); livre != nil {
+		fmt.Fprintln(cli.sortie)
+		livre.AfficherDetails(cli.sortie)
+		if emprunt, _ := cli.gestionnaireEmprunts.TrouverEmpruntActifParLivre(livre.ID); emprunt != nil {
+			cli.AfficherInfo(fmt.Sprintf("Emprunté par %s, à rendre le %s.", emprunt.NomMembre, emprunt.DateRetourPrevu.Format("02/01/2006")))
+		}
+		trouve = true
+	}
+
+	if membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(code); membre != nil {
+		fmt.Fprintln(cli.sortie)
+		membre.AfficherDetails(cli.sortie)
+		trouve = true
+	}
+
+	if !trouve {
+		cli.AfficherAvertissement(fmt.Sprintf("Le code '%s' ne correspond à aucun livre ni aucun membre.", models.NormaliserCode(code)))
+	}
+}
+
+// ========================================
+// ÉCRITURE DES FICHIERS
+// ========================================
+
+// ecrirePNG encode le code dans la symbologie configurée et l'écrit dans le dossier des étiquettes
+func (cli *CLI) ecrirePNG(nom, code string) (string, error) {
+	symbole, err := codebarres.Encoder(code, cli.config.Etiquettes.Symbologie)
+	if err != nil {
+		return "", err
+	}
+
+	fichier, chemin, err := cli.creerFichierEtiquettes(nom)
+	if err != nil {
+		return "", err
+	}
+	defer fichier.Close()
+
+	if err := symbole.EcrirePNG(fichier, 4, 30); err != nil {
+		return "", fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+	}
+	return chemin, nil
+}
+
+// ecrirePlanche met en page les étiquettes dans un PDF horodaté
+func (cli *CLI) ecrirePlanche(prefixe, titre string, etiquettes []codebarres.Etiquette, modele codebarres.ModelePlanche) (string, error) {
+	nom := fmt.Sprintf("%s-%s.pdf", prefixe, time.Now().Format("20060102-150405"))
+	fichier, chemin, err := cli.creerFichierEtiquettes(nom)
+	if err != nil {
+		return "", err
+	}
+	defer fichier.Close()
+
+	if err := codebarres.EcrirePlanche(fichier, titre, etiquettes, cli.config.Etiquettes.Symbologie, modele); err != nil {
+		return "", fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+	}
+	return chemin, nil
+}
+
+func (cli *CLI) creerFichierEtiquettes(nom string) (*os.File, string, error) {
+	if err := os.MkdirAll(cli.config.Etiquettes.Dossier, 0755); err != nil {
+		return nil, "", fmt.Errorf("impossible de créer le dossier %s : %v", cli.config.Etiquettes.Dossier, err)
+	}
+
+	chemin := filepath.Join(cli.config.Etiquettes.Dossier, nom)
+	fichier, err := os.Create(chemin)
+	if err != nil {
+		return nil, "", fmt.Errorf("impossible de créer %s : %v", chemin, err)
+	}
+	return fichier, chemin, nil
+}
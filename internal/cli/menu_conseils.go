@@ -0,0 +1,50 @@
+// ==========================================
+// internal/cli/menu_conseils.go
+// CONSEILS D'ACHAT
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/felver-dev/bookstore/internal/conseils"
+)
+
+// conseillerAchats affiche les achats suggérés, du plus urgent au moins urgent,
+// puis propose d'enregistrer le rapport et le bon de commande CSV
+func (cli *CLI) conseillerAchats() error {
+	cli.AfficherTitre("🛒 CONSEILS D'ACHAT")
+
+	resultat := conseils.Conseiller(cli.gestionnaireLivres, cli.gestionnaireEmprunts, cli.gestionnaireReservations, cli.gestionnaireRecherches)
+	if len(resultat.Suggestions) == 0 {
+		cli.AfficherInfo("Aucun signal de demande (réservations, titres toujours sortis, rotation, recherches sans résultat) : aucun achat n'est suggéré.")
+		return nil
+	}
+
+	for _, suggestion := range resultat.Suggestions {
+		fmt.Fprintf(cli.sortie, "\n%2d. %s ×%d — %s\n", suggestion.Rang, suggestion.Titre, suggestion.Quantite, conseils.LibelleType(suggestion.Type))
+		if suggestion.Type == conseils.SUGGESTION_EXEMPLAIRE {
+			fmt.Fprintf(cli.sortie, "    %s, ISBN %s\n", suggestion.Auteur, suggestion.ISBN)
+		}
+		for _, explication := range suggestion.Explications {
+			fmt.Fprintf(cli.sortie, "    • %s\n", explication)
+		}
+	}
+	fmt.Fprintf(cli.sortie, "\nTotal : %d suggestion(s) ; rotation moyenne du fonds : %.1f emprunt(s) par livre sur 12 mois\n",
+		len(resultat.Suggestions), resultat.RotationMoyenne)
+
+	if !cli.LireConfirmation(fmt.Sprintf("\nEnregistrer le rapport et le bon de commande dans '%s/' ?", cli.config.Rapports.Dossier)) {
+		return nil
+	}
+
+	chemins, err := resultat.Enregistrer(cli.config.Rapports.Dossier)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces("Rapport et bon de commande enregistrés (prix à compléter dans le CSV).")
+	for _, chemin := range chemins {
+		fmt.Fprintf(cli.sortie, "   📄 %s\n", chemin)
+	}
+	return nil
+}
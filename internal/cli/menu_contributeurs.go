@@ -0,0 +1,343 @@
+// ==========================================
+// internal/cli/menu_contributeurs.go
+// MENU DES AUTEURS ET CONTRIBUTEURS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// SOUS-MENU CONTRIBUTEURS
+// ========================================
+
+func (cli *CLI) menuContributeurs() error {
+	for {
+		cli.AfficherTitre("✍️ AUTEURS ET CONTRIBUTEURS")
+		fmt.Fprintln(cli.sortie, "1. 📋 Lister les contributeurs")
+		fmt.Fprintln(cli.sortie, "2. 🔍 Rechercher (toutes variantes de nom)")
+		fmt.Fprintln(cli.sortie, "3. 📄 Fiche d'un auteur")
+		fmt.Fprintln(cli.sortie, "4. ➕ Ajouter un contributeur")
+		fmt.Fprintln(cli.sortie, "5. ✏️  Modifier un contributeur")
+		fmt.Fprintln(cli.sortie, "6. 🔖 Ajouter une variante de nom")
+		fmt.Fprintln(cli.sortie, "7. ✂️  Retirer une variante de nom")
+		fmt.Fprintln(cli.sortie, "8. 🗑️  Supprimer un contributeur")
+		fmt.Fprintln(cli.sortie, "9. 📎 Ajouter un contributeur à un livre")
+		fmt.Fprintln(cli.sortie, "10. ✂️  Retirer un contributeur d'un livre")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 10)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerContributeurs()
+		case 2:
+			cli.rechercherContributeurs()
+		case 3:
+			err = cli.afficherFicheContributeur()
+		case 4:
+			err = cli.ajouterContributeur()
+		case 5:
+			err = cli.modifierContributeur()
+		case 6:
+			err = cli.ajouterVarianteContributeur()
+		case 7:
+			err = cli.retirerVarianteContributeur()
+		case 8:
+			err = cli.supprimerContributeur()
+		case 9:
+			err = cli.ajouterContributionLivre()
+		case 10:
+			err = cli.retirerContributionLivre()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerContributeurs() {
+	cli.AfficherTitre("📋 LISTE DES CONTRIBUTEURS")
+
+	contributeurs := cli.gestionnaireContributeurs.ListerContributeurs()
+	if len(contributeurs) == 0 {
+		cli.AfficherInfo("Aucun contributeur enregistré.")
+		return
+	}
+
+	cli.afficherTableauContributeurs(contributeurs)
+}
+
+func (cli *CLI) rechercherContributeurs() {
+	cli.AfficherTitre("🔍 RECHERCHER DES CONTRIBUTEURS")
+
+	terme := cli.LireEntreeObligatoire("Nom ou variante : ")
+	resultats := cli.gestionnaireContributeurs.RechercherContributeurs(terme)
+
+	fmt.Fprintf(cli.sortie, "\n🎯 %d résultat(s) trouvé(s) pour '%s' :\n", len(resultats), terme)
+
+	if len(resultats) == 0 {
+		cli.AfficherInfo("Aucun contributeur correspondant.")
+		return
+	}
+
+	cli.afficherTableauContributeurs(resultats)
+}
+
+// afficherFicheContributeur affiche la page d'un auteur : ses livres, ses rôles et les emprunts
+func (cli *CLI) afficherFicheContributeur() error {
+	cli.AfficherTitre("📄 FICHE D'UN AUTEUR")
+
+	id := cli.LireEntreeEntierObligatoire("ID du contributeur : ")
+
+	contributeur, _ := cli.gestionnaireContributeurs.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	fmt.Fprintf(cli.sortie, "\n👤 %s\n", contributeur.Nom)
+	fmt.Fprintf(cli.sortie, "   Classement : %s\n", contributeur.CleTri)
+	if len(contributeur.Variantes) > 0 {
+		fmt.Fprintf(cli.sortie, "   Autres noms : %s\n", strings.Join(contributeur.Variantes, " ; "))
+	}
+
+	livres := cli.gestionnaireLivres.ListerLivresParContributeur(id)
+	if len(livres) == 0 {
+		cli.AfficherInfo("Aucun livre lié à ce contributeur.")
+		return nil
+	}
+
+	fmt.Fprintln(cli.sortie, "\n📚 Livres :")
+	totalEmprunts := 0
+	enCours := 0
+	for _, livre := range livres {
+		var roles []string
+		for _, contribution := range livre.Contributions {
+			if contribution.ContributeurID == id {
+				roles = append(roles, models.LibelleRole(contribution.Role))
+			}
+		}
+
+		emprunts := cli.gestionnaireEmprunts.ListerEmpruntsParLivre(livre.ID)
+		totalEmprunts += len(emprunts)
+
+		statut := "📗 Disponible"
+		if livre.EstRetire() {
+			statut = "🚫 Retiré"
+		} else if !livre.EstDisponible() {
+			statut = "📕 Emprunté"
+			enCours++
+		}
+
+		fmt.Fprintf(cli.sortie, "   • [%d] %s (%s) - %d emprunt(s) - %s\n",
+			livre.ID, livre.Titre, strings.Join(roles, ", "), len(emprunts), statut)
+	}
+
+	fmt.Fprintf(cli.sortie, "\nStatistiques : %d livre(s) | %d emprunt(s) au total | %d actuellement emprunté(s)\n",
+		len(livres), totalEmprunts, enCours)
+	if len(livres) > 0 {
+		fmt.Fprintf(cli.sortie, "Moyenne : %.1f emprunt(s) par livre\n", float64(totalEmprunts)/float64(len(livres)))
+	}
+
+	return nil
+}
+
+func (cli *CLI) ajouterContributeur() error {
+	cli.AfficherTitre("➕ AJOUTER UN CONTRIBUTEUR")
+
+	nom := cli.LireEntreeObligatoire("Nom d'affichage (ex : J.R.R. Tolkien) : ")
+
+	fmt.Fprintf(cli.sortie, "Clé de tri (vide pour '%s') : ", models.CleTriParDefaut(nom))
+	cleTri := cli.LireEntree()
+
+	contributeur, err := cli.gestionnaireContributeurs.AjouterContributeur(nom, cleTri)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Contributeur '%s' ajouté avec succès (ID: %d) !", contributeur.Nom, contributeur.ID))
+	return nil
+}
+
+func (cli *CLI) modifierContributeur() error {
+	cli.AfficherTitre("✏️ MODIFIER UN CONTRIBUTEUR")
+
+	id := cli.LireEntreeEntierObligatoire("ID du contributeur à modifier : ")
+
+	contributeur, _ := cli.gestionnaireContributeurs.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	cli.AfficherInfo("Laissez vide pour conserver la valeur actuelle. L'ancien nom sera gardé comme variante.")
+
+	fmt.Fprintf(cli.sortie, "Nouveau nom (%s) : ", contributeur.Nom)
+	nouveauNom := cli.LireEntree()
+
+	fmt.Fprintf(cli.sortie, "Nouvelle clé de tri (%s) : ", contributeur.CleTri)
+	nouvelleCleTri := cli.LireEntree()
+
+	if err := cli.gestionnaireLivres.ModifierContributeur(id, nouveauNom, nouvelleCleTri); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Contributeur ID %d modifié avec succès !", id))
+	return nil
+}
+
+func (cli *CLI) ajouterVarianteContributeur() error {
+	cli.AfficherTitre("🔖 AJOUTER UNE VARIANTE DE NOM")
+
+	id := cli.LireEntreeEntierObligatoire("ID du contributeur : ")
+	variante := cli.LireEntreeObligatoire("Variante (ex : John Ronald Reuel Tolkien) : ")
+
+	if err := cli.gestionnaireContributeurs.AjouterVariante(id, variante); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Variante '%s' ajoutée avec succès !", variante))
+	return nil
+}
+
+func (cli *CLI) retirerVarianteContributeur() error {
+	cli.AfficherTitre("✂️ RETIRER UNE VARIANTE DE NOM")
+
+	id := cli.LireEntreeEntierObligatoire("ID du contributeur : ")
+	variante := cli.LireEntreeObligatoire("Variante à retirer : ")
+
+	if err := cli.gestionnaireContributeurs.RetirerVariante(id, variante); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Variante '%s' retirée avec succès !", variante))
+	return nil
+}
+
+func (cli *CLI) supprimerContributeur() error {
+	cli.AfficherTitre("🗑️ SUPPRIMER UN CONTRIBUTEUR")
+
+	id := cli.LireEntreeEntierObligatoire("ID du contributeur à supprimer : ")
+
+	contributeur, _ := cli.gestionnaireContributeurs.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	if !cli.LireConfirmation(fmt.Sprintf("\n⚠️ Êtes-vous sûr de vouloir supprimer '%s' ?", contributeur.Nom)) {
+		cli.AfficherInfo("Suppression annulée.")
+		return nil
+	}
+
+	nom := contributeur.Nom
+	if err := cli.gestionnaireLivres.SupprimerContributeur(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Contributeur '%s' supprimé avec succès !", nom))
+	return nil
+}
+
+func (cli *CLI) ajouterContributionLivre() error {
+	cli.AfficherTitre("📎 AJOUTER UN CONTRIBUTEUR À UN LIVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	cli.afficherContributionsLivre(livre)
+
+	nom := cli.LireEntreeObligatoire("\nNom du contributeur (créé s'il n'existe pas) : ")
+
+	libelles := make([]string, len(models.ROLES_CONTRIBUTION))
+	for i, role := range models.ROLES_CONTRIBUTION {
+		libelles[i] = models.LibelleRole(role)
+	}
+	role := models.ROLES_CONTRIBUTION[cli.LireChoixDansListe("Rôle :", libelles)]
+
+	if err := cli.gestionnaireLivres.AjouterContribution(livreID, nom, role); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("%s ajouté comme %s de '%s' !", nom, strings.ToLower(models.LibelleRole(role)), livre.Titre))
+	return nil
+}
+
+func (cli *CLI) retirerContributionLivre() error {
+	cli.AfficherTitre("✂️ RETIRER UN CONTRIBUTEUR D'UN LIVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	if len(livre.Contributions) == 0 {
+		cli.AfficherInfo("Ce livre n'a aucun contributeur enregistré.")
+		return nil
+	}
+
+	options := make([]string, len(livre.Contributions))
+	for i, contribution := range livre.Contributions {
+		options[i] = fmt.Sprintf("%s (%s)", cli.nomContributeur(contribution.ContributeurID), models.LibelleRole(contribution.Role))
+	}
+	choix := livre.Contributions[cli.LireChoixDansListe("Contribution à retirer :", options)]
+
+	if err := cli.gestionnaireLivres.RetirerContribution(livreID, choix.ContributeurID, choix.Role); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Contribution retirée du livre '%s' !", livre.Titre))
+	return nil
+}
+
+func (cli *CLI) afficherContributionsLivre(livre *models.Livre) {
+	fmt.Fprintf(cli.sortie, "\nContributeurs de '%s' :\n", livre.Titre)
+	if len(livre.Contributions) == 0 {
+		fmt.Fprintln(cli.sortie, "  (aucun)")
+		return
+	}
+	for _, contribution := range livre.Contributions {
+		fmt.Fprintf(cli.sortie, "  • %s - %s\n", cli.nomContributeur(contribution.ContributeurID), models.LibelleRole(contribution.Role))
+	}
+}
+
+func (cli *CLI) nomContributeur(id int) string {
+	if contributeur, _ := cli.gestionnaireContributeurs.TrouverContributeurParID(id); contributeur != nil {
+		return contributeur.Nom
+	}
+	return fmt.Sprintf("contributeur #%d", id)
+}
+
+func (cli *CLI) afficherTableauContributeurs(contributeurs []models.Contributeur) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Nom", Cle: "nom", Min: 10},
+		affichage.Colonne{Titre: "Classement", Cle: "cle_tri", Min: 10},
+		affichage.Colonne{Titre: "Livres", Cle: "livres", Numerique: true},
+	)
+
+	for _, contributeur := range contributeurs {
+		livres := len(cli.gestionnaireLivres.ListerLivresParContributeur(contributeur.ID))
+		tableau.AjouterLigne(strconv.Itoa(contributeur.ID), contributeur.Nom, contributeur.CleTri, strconv.Itoa(livres))
+	}
+
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d contributeur(s)", len(contributeurs)))
+}
@@ -0,0 +1,257 @@
+// ==========================================
+// internal/cli/menu_genres.go
+// MENU DE GESTION DE LA TAXONOMIE DES GENRES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// SOUS-MENU GENRES ET SUJETS
+// ========================================
+
+func (cli *CLI) menuGenres() error {
+	for {
+		cli.AfficherTitre("🏷️ GENRES ET SUJETS")
+		fmt.Fprintln(cli.sortie, "1. 🌳 Afficher la taxonomie")
+		fmt.Fprintln(cli.sortie, "2. ➕ Ajouter un genre")
+		fmt.Fprintln(cli.sortie, "3. ✏️  Renommer / déplacer un genre")
+		fmt.Fprintln(cli.sortie, "4. 🔖 Ajouter un alias")
+		fmt.Fprintln(cli.sortie, "5. ✂️  Retirer un alias")
+		fmt.Fprintln(cli.sortie, "6. 🗑️  Supprimer un genre")
+		fmt.Fprintln(cli.sortie, "7. 📎 Ajouter un sujet à un livre")
+		fmt.Fprintln(cli.sortie, "8. 📎 Retirer un sujet d'un livre")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 8)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.afficherTaxonomie()
+		case 2:
+			err = cli.ajouterGenre()
+		case 3:
+			err = cli.modifierGenre()
+		case 4:
+			err = cli.ajouterAliasGenre()
+		case 5:
+			err = cli.retirerAliasGenre()
+		case 6:
+			err = cli.supprimerGenre()
+		case 7:
+			err = cli.ajouterSujetLivre()
+		case 8:
+			err = cli.retirerSujetLivre()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) afficherTaxonomie() {
+	cli.AfficherTitre("🌳 TAXONOMIE DES GENRES")
+
+	genres := cli.gestionnaireGenres.ListerDansLOrdre()
+	if len(genres) == 0 {
+		cli.AfficherInfo("Aucun genre enregistré.")
+		return
+	}
+
+	for _, genre := range genres {
+		indentation := strings.Repeat("   ", cli.gestionnaireGenres.Profondeur(genre.ID))
+		ligne := fmt.Sprintf("%s• [%d] %s", indentation, genre.ID, genre.Nom)
+		if len(genre.Alias) > 0 {
+			ligne += fmt.Sprintf("  (alias : %s)", strings.Join(genre.Alias, ", "))
+		}
+		fmt.Fprintln(cli.sortie, ligne)
+	}
+
+	fmt.Fprintf(cli.sortie, "\nTotal : %d genre(s)\n", len(genres))
+}
+
+// choisirGenre propose la taxonomie sous forme de liste et retourne le nom canonique choisi
+func (cli *CLI) choisirGenre(message string) string {
+	genres := cli.gestionnaireGenres.ListerDansLOrdre()
+
+	options := make([]string, len(genres))
+	for i, genre := range genres {
+		options[i] = cli.gestionnaireGenres.Chemin(genre.ID)
+	}
+
+	index := cli.LireChoixDansListe(message, options)
+	return genres[index].Nom
+}
+
+func (cli *CLI) ajouterGenre() error {
+	cli.AfficherTitre("➕ AJOUTER UN GENRE")
+
+	nom := cli.LireEntreeObligatoire("Nom du genre : ")
+
+	cli.afficherTaxonomie()
+	parentID := cli.LireEntreeEntierObligatoire("\nID du genre parent (0 pour un genre racine) : ")
+
+	genre, err := cli.gestionnaireGenres.AjouterGenre(nom, parentID)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Genre '%s' ajouté avec succès !", cli.gestionnaireGenres.Chemin(genre.ID)))
+	return nil
+}
+
+func (cli *CLI) modifierGenre() error {
+	cli.AfficherTitre("✏️ RENOMMER / DÉPLACER UN GENRE")
+
+	id := cli.LireEntreeEntierObligatoire("ID du genre à modifier : ")
+
+	genre, _ := cli.gestionnaireGenres.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	cli.AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
+
+	fmt.Fprintf(cli.sortie, "Nouveau nom (%s) : ", genre.Nom)
+	nouveauNom := cli.LireEntree()
+
+	fmt.Fprintf(cli.sortie, "Nouveau parent (ID actuel : %d, 0 pour la racine) : ", genre.ParentID)
+	nouveauParentID := -1 // -1 = conserver le parent actuel
+	if saisie := cli.LireEntree(); saisie != "" {
+		valeur, err := strconv.Atoi(saisie)
+		if err != nil || valeur < 0 {
+			return fmt.Errorf("'%s' n'est pas un ID de genre valide", saisie)
+		}
+		nouveauParentID = valeur
+	}
+
+	// Passer par le gestionnaire de livres pour mettre à jour les livres concernés
+	if err := cli.gestionnaireLivres.ModifierGenre(id, nouveauNom, nouveauParentID); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Genre modifié : %s", cli.gestionnaireGenres.Chemin(id)))
+	return nil
+}
+
+func (cli *CLI) ajouterAliasGenre() error {
+	cli.AfficherTitre("🔖 AJOUTER UN ALIAS")
+
+	id := cli.LireEntreeEntierObligatoire("ID du genre : ")
+	alias := cli.LireEntreeObligatoire("Alias (ex : 'SF' pour Science-fiction) : ")
+
+	if err := cli.gestionnaireGenres.AjouterAlias(id, alias); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Alias '%s' ajouté avec succès !", alias))
+	return nil
+}
+
+func (cli *CLI) retirerAliasGenre() error {
+	cli.AfficherTitre("✂️ RETIRER UN ALIAS")
+
+	id := cli.LireEntreeEntierObligatoire("ID du genre : ")
+	alias := cli.LireEntreeObligatoire("Alias à retirer : ")
+
+	if err := cli.gestionnaireGenres.RetirerAlias(id, alias); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Alias '%s' retiré avec succès !", alias))
+	return nil
+}
+
+func (cli *CLI) supprimerGenre() error {
+	cli.AfficherTitre("🗑️ SUPPRIMER UN GENRE")
+
+	id := cli.LireEntreeEntierObligatoire("ID du genre à supprimer : ")
+
+	genre, _ := cli.gestionnaireGenres.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	if !cli.LireConfirmation(fmt.Sprintf("\n⚠️ Êtes-vous sûr de vouloir supprimer le genre '%s' ?", genre.Nom)) {
+		cli.AfficherInfo("Suppression annulée.")
+		return nil
+	}
+
+	nom := genre.Nom
+	if err := cli.gestionnaireLivres.SupprimerGenre(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Genre '%s' supprimé avec succès !", nom))
+	return nil
+}
+
+func (cli *CLI) ajouterSujetLivre() error {
+	cli.AfficherTitre("📎 AJOUTER UN SUJET À UN LIVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	cli.afficherSujetsLivre(livreID)
+	genre := cli.choisirGenre("\nChoisissez le sujet à ajouter :")
+
+	if err := cli.gestionnaireLivres.AjouterSujet(livreID, genre); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Sujet '%s' ajouté au livre '%s' !", genre, livre.Titre))
+	return nil
+}
+
+func (cli *CLI) retirerSujetLivre() error {
+	cli.AfficherTitre("📎 RETIRER UN SUJET D'UN LIVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	cli.afficherSujetsLivre(livreID)
+	genreID := cli.LireEntreeEntierObligatoire("\nID du sujet à retirer : ")
+
+	if err := cli.gestionnaireLivres.RetirerSujet(livreID, genreID); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Sujet retiré du livre '%s' !", livre.Titre))
+	return nil
+}
+
+func (cli *CLI) afficherSujetsLivre(livreID int) {
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return
+	}
+
+	fmt.Fprintf(cli.sortie, "\nSujets de '%s' :\n", livre.Titre)
+	for i, id := range livre.Sujets {
+		marque := ""
+		if i == 0 {
+			marque = " (principal)"
+		}
+		fmt.Fprintf(cli.sortie, "  [%d] %s%s\n", id, cli.gestionnaireGenres.Chemin(id), marque)
+	}
+}
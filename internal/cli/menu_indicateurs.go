@@ -0,0 +1,194 @@
+// ==========================================
+// internal/cli/menu_indicateurs.go
+// INDICATEURS DE LA BIBLIOTHÈQUE
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/analyses"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// MOIS_INACTIVITE_PAR_DEFAUT : un livre est dormant s'il n'est pas sorti depuis un an
+const MOIS_INACTIVITE_PAR_DEFAUT = 12
+
+// ========================================
+// SOUS-MENU INDICATEURS
+// La période (12 derniers mois par défaut) et le seuil des livres dormants
+// restent choisis tant qu'on ne quitte pas le menu.
+// ========================================
+
+func (cli *CLI) menuIndicateurs() error {
+	periode := statistiques.DouzeMois(time.Now())
+	moisInactivite := MOIS_INACTIVITE_PAR_DEFAUT
+
+	for {
+		cli.AfficherTitre("📈 INDICATEURS DE LA BIBLIOTHÈQUE")
+		fmt.Fprintf(cli.sortie, "🗓️  Période : %s\n", periode.Libelle())
+		fmt.Fprintln(cli.sortie, "1. 🔄 Rotation par titre")
+		fmt.Fprintln(cli.sortie, "2. 🔄 Rotation par genre")
+		fmt.Fprintf(cli.sortie, "3. 💤 Livres dormants (sans emprunt depuis %d mois)\n", moisInactivite)
+		fmt.Fprintln(cli.sortie, "4. ⏱️  Délai de retour par genre")
+		fmt.Fprintln(cli.sortie, "5. 🎯 Retours à l'heure par cohorte d'inscription")
+		fmt.Fprintln(cli.sortie, "6. 🕒 Jours et heures d'affluence")
+		fmt.Fprintln(cli.sortie, "7. 📦 Part du fonds en circulation")
+		fmt.Fprintln(cli.sortie, "8. 💾 Exporter tous les indicateurs (CSV et JSON)")
+		fmt.Fprintln(cli.sortie, "9. 🗓️  Changer de période")
+		fmt.Fprintln(cli.sortie, "10. 💤 Changer le seuil des livres dormants")
+		fmt.Fprintln(cli.sortie, "11. 🧪 Évaluer les suggestions de lecture (précision@k)")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 11)
+		if choix == 0 {
+			return nil
+		}
+
+		var err error
+		analyse := analyses.Analyser(periode, moisInactivite, cli.gestionnaireLivres, cli.gestionnaireMembres, cli.gestionnaireEmprunts)
+		switch choix {
+		case 1:
+			cli.AfficherTitre("🔄 ROTATION PAR TITRE")
+			cli.afficherResultats(analyse.TableauRotationTitres(), fmt.Sprintf("Total : %d titre(s)", len(analyse.RotationTitres)))
+			cli.AfficherInfo("Rotation : emprunts de la période par exemplaire (livres de même ISBN).")
+		case 2:
+			cli.AfficherTitre("🔄 ROTATION PAR GENRE")
+			cli.afficherResultats(analyse.TableauRotationGenres(), fmt.Sprintf("Total : %d genre(s)", len(analyse.RotationGenres)))
+			cli.AfficherInfo("Rotation : emprunts de la période par livre du genre.")
+		case 3:
+			cli.afficherLivresDormants(analyse)
+		case 4:
+			cli.AfficherTitre("⏱️  DÉLAI DE RETOUR PAR GENRE")
+			if len(analyse.DelaisRetour) == 0 {
+				cli.AfficherInfo("Aucun retour sur la période.")
+				break
+			}
+			cli.afficherResultats(analyse.TableauDelaisRetour(), fmt.Sprintf("Total : %d genre(s)", len(analyse.DelaisRetour)))
+			cli.AfficherInfo("Retours effectués pendant la période, du genre le plus lent au plus rapide.")
+		case 5:
+			cli.AfficherTitre("🎯 RETOURS À L'HEURE PAR COHORTE D'INSCRIPTION")
+			if len(analyse.Cohortes) == 0 {
+				cli.AfficherInfo("Aucune échéance passée sur la période.")
+				break
+			}
+			cli.afficherResultats(analyse.TableauCohortes(), fmt.Sprintf("Total : %d cohorte(s)", len(analyse.Cohortes)))
+			cli.AfficherInfo("Échéances de la période déjà rendues ou dépassées, par mois d'inscription du membre.")
+		case 6:
+			cli.afficherAffluence(analyse)
+		case 7:
+			cli.AfficherTitre("📦 PART DU FONDS EN CIRCULATION")
+			cli.afficherResultats(analyse.TableauCirculation(), "")
+		case 8:
+			err = cli.exporterIndicateurs(analyse)
+		case 9:
+			periode, err = cli.choisirPeriodeIndicateurs(periode)
+		case 10:
+			moisInactivite, err = cli.choisirSeuilInactivite(moisInactivite)
+		case 11:
+			err = cli.evaluerRecommandations()
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) afficherLivresDormants(analyse analyses.Analyse) {
+	cli.AfficherTitre("💤 LIVRES DORMANTS")
+	if len(analyse.LivresDormants) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Tous les livres sont sortis au moins une fois ces %d derniers mois.", analyse.MoisInactivite))
+		return
+	}
+	cli.afficherResultats(analyse.TableauLivresDormants(), fmt.Sprintf("Total : %d livre(s) sans emprunt depuis au moins %d mois", len(analyse.LivresDormants), analyse.MoisInactivite))
+	cli.AfficherInfo("Les livres entrés au catalogue depuis moins longtemps que le seuil ne sont pas comptés.")
+}
+
+func (cli *CLI) afficherAffluence(analyse analyses.Analyse) {
+	cli.AfficherTitre("🕒 JOURS ET HEURES D'AFFLUENCE")
+	if len(analyse.Affluence.Pointes) == 0 {
+		cli.AfficherInfo("Aucun emprunt sur la période.")
+		return
+	}
+
+	fmt.Fprintln(cli.sortie, "🔝 Créneaux les plus chargés :")
+	cli.afficherResultats(analyse.TableauPointes(), "")
+
+	// Les heures sans aucun emprunt (la nuit) n'apportent rien à l'écran ;
+	// l'export les garde toutes
+	fmt.Fprintln(cli.sortie, "\n📅 Par jour de la semaine :")
+	for _, jour := range analyse.Affluence.ParJour {
+		fmt.Fprintf(cli.sortie, "   %-9s %s %d\n", jour.Libelle, barre(jour.Emprunts, analyse.Affluence.ParJour), jour.Emprunts)
+	}
+	fmt.Fprintln(cli.sortie, "\n🕒 Par heure :")
+	for _, heure := range analyse.Affluence.ParHeure {
+		if heure.Emprunts > 0 {
+			fmt.Fprintf(cli.sortie, "   %-9s %s %d\n", heure.Libelle, barre(heure.Emprunts, analyse.Affluence.ParHeure), heure.Emprunts)
+		}
+	}
+}
+
+// barre dessine une barre proportionnelle au plus grand créneau de la liste
+func barre(nombre int, creneaux []analyses.Creneau) string {
+	const longueur = 30
+	plusGrand := 0
+	for _, creneau := range creneaux {
+		plusGrand = max(plusGrand, creneau.Emprunts)
+	}
+	if plusGrand == 0 {
+		return ""
+	}
+	return strings.Repeat("█", nombre*longueur/plusGrand)
+}
+
+func (cli *CLI) exporterIndicateurs(analyse analyses.Analyse) error {
+	cli.AfficherTitre("💾 EXPORT DES INDICATEURS")
+	chemins, err := analyse.Enregistrer(cli.config.Rapports.Dossier)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Indicateurs (%s) exportés : %d fichier(s).", analyse.Periode.Libelle(), len(chemins)))
+	for _, chemin := range chemins {
+		fmt.Fprintf(cli.sortie, "   📄 %s\n", chemin)
+	}
+	return nil
+}
+
+func (cli *CLI) choisirPeriodeIndicateurs(actuelle statistiques.Periode) (statistiques.Periode, error) {
+	fmt.Fprintf(cli.sortie, "Période (%s, vide = 12 derniers mois) : ", statistiques.FORMATS_PERIODE)
+	saisie := cli.LireEntree()
+	if strings.TrimSpace(saisie) == "" {
+		return statistiques.DouzeMois(time.Now()), nil
+	}
+
+	periode, err := statistiques.LirePeriode(saisie, time.Now())
+	if err != nil {
+		return actuelle, err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Indicateurs calculés sur : %s.", periode.Libelle()))
+	return periode, nil
+}
+
+func (cli *CLI) choisirSeuilInactivite(actuel int) (int, error) {
+	fmt.Fprintf(cli.sortie, "Nombre de mois sans emprunt (vide = %d) : ", MOIS_INACTIVITE_PAR_DEFAUT)
+	saisie := strings.TrimSpace(cli.LireEntree())
+	if saisie == "" {
+		return MOIS_INACTIVITE_PAR_DEFAUT, nil
+	}
+
+	mois, err := strconv.Atoi(saisie)
+	if err != nil || mois < 1 {
+		return actuel, fmt.Errorf("'%s' n'est pas un nombre de mois valide", saisie)
+	}
+	cli.AfficherSucces(fmt.Sprintf("Un livre est dormant après %d mois sans emprunt.", mois))
+	return mois, nil
+}
@@ -0,0 +1,68 @@
+// ==========================================
+// internal/cli/menu_integrite.go
+// CONTRÔLE D'INTÉGRITÉ DES DONNÉES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+var libellesAnomalies = map[string]string{
+	services.ANOMALIE_DOUBLON:     "👯 ID en double",
+	services.ANOMALIE_ORPHELIN:    "👻 Orphelin",
+	services.ANOMALIE_NOM:         "🏷️  Nom recopié périmé",
+	services.ANOMALIE_COMPTEUR:    "🔢 Compteur",
+	services.ANOMALIE_INCOHERENCE: "⚠️  À examiner",
+}
+
+// controlerIntegrite affiche les anomalies et les corrections proposées sous
+// forme de diff, puis les applique si l'utilisateur le confirme
+func (cli *CLI) controlerIntegrite() error {
+	cli.AfficherTitre("🩺 CONTRÔLE D'INTÉGRITÉ DES DONNÉES")
+
+	rapport := cli.gestionnaireEmprunts.VerifierIntegrite()
+	AfficherRapportIntegrite(cli.sortie, rapport)
+
+	reparables := rapport.Reparables()
+	if reparables == 0 {
+		return nil
+	}
+	if !cli.LireConfirmation(fmt.Sprintf("\nAppliquer les %d correction(s) ci-dessus ?", reparables)) {
+		cli.AfficherInfo("Aucune donnée modifiée.")
+		return nil
+	}
+
+	rapport, err := cli.gestionnaireEmprunts.ReparerIntegrite()
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("%d correction(s) appliquée(s).", rapport.Reparables()))
+	return nil
+}
+
+// AfficherRapportIntegrite écrit le résultat d'un contrôle : chaque anomalie
+// avec, si elle est réparable, la valeur enregistrée (-) et la valeur
+// recalculée (+). Il sert au menu et à l'option -verifier.
+func AfficherRapportIntegrite(w io.Writer, rapport services.RapportIntegrite) {
+	fmt.Fprintf(w, "\n🔍 Contrôlés : %d livre(s), %d membre(s), %d emprunt(s), %d réservation(s)\n",
+		rapport.Livres, rapport.Membres, rapport.Emprunts, rapport.Reservations)
+
+	if len(rapport.Anomalies) == 0 {
+		fmt.Fprintln(w, "\n✅ Aucune anomalie : les compteurs correspondent à l'historique des emprunts.")
+		return
+	}
+
+	fmt.Fprintf(w, "\n⚠️  %d anomalie(s), dont %d réparable(s) :\n", len(rapport.Anomalies), rapport.Reparables())
+	for _, anomalie := range rapport.Anomalies {
+		fmt.Fprintf(w, "\n%s — %s #%d : %s\n", libellesAnomalies[anomalie.Type], anomalie.Entite, anomalie.ID, anomalie.Detail)
+		if anomalie.Reparable {
+			fmt.Fprintf(w, "  - %s : %s\n", anomalie.Champ, anomalie.Actuel)
+			fmt.Fprintf(w, "  + %s : %s\n", anomalie.Champ, anomalie.Attendu)
+		}
+	}
+}
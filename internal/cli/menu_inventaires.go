@@ -0,0 +1,350 @@
+// ==========================================
+// internal/cli/menu_inventaires.go
+// INVENTAIRE DES RAYONS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// SOUS-MENU INVENTAIRE
+// On ouvre un inventaire par rayon, on scanne les livres trouvés (en une ou
+// plusieurs fois), puis le rapport d'écarts liste ce qui manque et ce qui
+// n'est pas à sa place.
+// ========================================
+
+func (cli *CLI) menuInventaires() error {
+	for {
+		cli.AfficherTitre("🔎 INVENTAIRE DES RAYONS")
+		fmt.Fprintln(cli.sortie, "1. 📋 Lister les inventaires")
+		fmt.Fprintln(cli.sortie, "2. 🆕 Ouvrir un inventaire")
+		fmt.Fprintln(cli.sortie, "3. 📷 Relever des livres (ou reprendre un inventaire)")
+		fmt.Fprintln(cli.sortie, "4. 📊 Rapport d'écarts")
+		fmt.Fprintln(cli.sortie, "5. 🚫 Déclarer perdus les livres manquants")
+		fmt.Fprintln(cli.sortie, "6. 🔒 Clôturer un inventaire")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu des livres")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 6)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerInventaires()
+		case 2:
+			err = cli.ouvrirInventaire()
+		case 3:
+			err = cli.releverLivres()
+		case 4:
+			err = cli.afficherEcartsInventaire()
+		case 5:
+			err = cli.declarerPerdus()
+		case 6:
+			err = cli.cloturerInventaire()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerInventaires() {
+	cli.AfficherTitre("📋 INVENTAIRES")
+
+	inventaires := cli.gestionnaireInventaires.ListerInventaires()
+	if len(inventaires) == 0 {
+		cli.AfficherInfo("Aucun inventaire enregistré.")
+		return
+	}
+	cli.afficherTableauInventaires(inventaires)
+}
+
+func (cli *CLI) afficherTableauInventaires(inventaires []models.Inventaire) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Nom", Cle: "nom", Min: 12},
+		affichage.Colonne{Titre: "Rayon", Cle: "rayon"},
+		affichage.Colonne{Titre: "Ouvert le", Cle: "date_ouverture"},
+		affichage.Colonne{Titre: "Relevés", Cle: "releves", Numerique: true},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
+	for _, inventaire := range inventaires {
+		statut := "🟢 En cours"
+		if !inventaire.EstEnCours() {
+			statut = "🔒 Clôturé le " + inventaire.DateCloture.Format("02/01/2006")
+		}
+		tableau.AjouterLigne(strconv.Itoa(inventaire.ID), inventaire.Nom, inventaire.Rayon(),
+			inventaire.DateOuverture.Format("02/01/2006"), strconv.Itoa(len(inventaire.Constats)), statut)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d inventaire(s)", len(inventaires)))
+}
+
+func (cli *CLI) ouvrirInventaire() error {
+	cli.AfficherTitre("🆕 OUVRIR UN INVENTAIRE")
+
+	nom := cli.LireEntreeObligatoire("Nom de l'inventaire (ex : Romans, étagères A à C) : ")
+	fmt.Fprint(cli.sortie, "Genre du rayon, sous-genres compris (vide = tout le fonds) : ")
+	genre := cli.LireEntree()
+
+	inventaire, err := cli.gestionnaireInventaires.OuvrirInventaire(nom, genre)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Inventaire '%s' ouvert (ID : %d) sur %s.", inventaire.Nom, inventaire.ID, inventaire.Rayon()))
+	return cli.relever(inventaire.ID)
+}
+
+func (cli *CLI) releverLivres() error {
+	cli.AfficherTitre("📷 RELEVER DES LIVRES")
+
+	var enCours []models.Inventaire
+	for _, inventaire := range cli.gestionnaireInventaires.ListerInventaires() {
+		if inventaire.EstEnCours() {
+			enCours = append(enCours, inventaire)
+		}
+	}
+	if len(enCours) == 0 {
+		cli.AfficherInfo("Aucun inventaire en cours : ouvrez-en un d'abord.")
+		return nil
+	}
+	cli.afficherTableauInventaires(enCours)
+
+	return cli.relever(cli.LireEntreeEntierObligatoire("\nID de l'inventaire : "))
+}
+
+// relever lit les codes scannés jusqu'à une saisie vide. Chaque relevé est
+// enregistré aussitôt, l'inventaire peut donc être interrompu à tout moment.
+func (cli *CLI) relever(inventaireID int) error {
+	inventaire := cli.gestionnaireInventaires.TrouverInventaireParID(inventaireID)
+	if inventaire == nil {
+		return fmt.Errorf("aucun inventaire trouvé avec l'ID %d", inventaireID)
+	}
+	if !inventaire.EstEnCours() {
+		return fmt.Errorf("l'inventaire '%s' est clôturé", inventaire.Nom)
+	}
+
+	fmt.Fprintf(cli.sortie, "\nInventaire '%s' (%s) : %d livre(s) déjà relevé(s).\n", inventaire.Nom, inventaire.Rayon(), len(inventaire.Constats))
+	fmt.Fprintln(cli.sortie, "Scannez ou saisissez les livres trouvés en rayon (vide pour interrompre, « - » pour annuler le dernier).")
+
+	for {
+		fmt.Fprint(cli.sortie, "Code : ")
+		saisie := cli.LireEntree()
+		if saisie == "" {
+			break
+		}
+
+		if saisie == "-" {
+			constat, err := cli.gestionnaireInventaires.AnnulerDernierReleve(inventaireID)
+			if err != nil {
+				cli.AfficherErreur(err.Error())
+			} else {
+				cli.AfficherInfo(fmt.Sprintf("Relevé '%s' annulé.", constat.Saisie))
+			}
+			continue
+		}
+
+		releve, err := cli.gestionnaireInventaires.Relever(inventaireID, saisie)
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+			continue
+		}
+		cli.signalerReleve(releve, saisie)
+	}
+
+	inventaire = cli.gestionnaireInventaires.TrouverInventaireParID(inventaireID)
+	cli.AfficherInfo(fmt.Sprintf("%d livre(s) relevé(s) dans '%s'. Reprenez quand vous voulez (option 3), puis consultez le rapport d'écarts.",
+		len(inventaire.Constats), inventaire.Nom))
+	return nil
+}
+
+func (cli *CLI) signalerReleve(releve services.Releve, saisie string) {
+	switch releve.Type {
+	case services.RELEVE_EN_RAYON:
+		fmt.Fprintf(cli.sortie, "   ✅ « %s »\n", releve.Livre.Titre)
+	case services.RELEVE_DEJA_VU:
+		fmt.Fprintf(cli.sortie, "   ℹ️  « %s » a déjà été relevé.\n", releve.Livre.Titre)
+	case services.RELEVE_EMPRUNTE:
+		cli.AfficherAvertissement(fmt.Sprintf("« %s » est enregistré comme emprunté : son retour n'a peut-être pas été saisi.", releve.Livre.Titre))
+	case services.RELEVE_HORS:
+		cli.AfficherAvertissement(fmt.Sprintf("« %s » (%s) n'est pas de ce rayon : à reclasser.", releve.Livre.Titre, releve.Livre.Genre))
+	case services.RELEVE_RETIRE:
+		cli.AfficherAvertissement(fmt.Sprintf("« %s » est retiré du fonds (%s) : à restaurer ou à sortir du rayon.", releve.Livre.Titre, releve.Livre.Retrait))
+	case services.RELEVE_MIS_COTE:
+		cli.AfficherAvertissement(fmt.Sprintf("« %s » est réservé : il devrait attendre son lecteur à l'accueil.", releve.Livre.Titre))
+	case services.RELEVE_INCONNU:
+		cli.AfficherAvertissement(fmt.Sprintf("Code '%s' inconnu du catalogue : noté pour le rapport.", strings.TrimSpace(saisie)))
+	}
+}
+
+// choisirInventaire lit l'ID d'un inventaire et le retrouve
+func (cli *CLI) choisirInventaire() (*models.Inventaire, error) {
+	id := cli.LireEntreeEntierObligatoire("ID de l'inventaire : ")
+	inventaire := cli.gestionnaireInventaires.TrouverInventaireParID(id)
+	if inventaire == nil {
+		return nil, fmt.Errorf("aucun inventaire trouvé avec l'ID %d", id)
+	}
+	return inventaire, nil
+}
+
+// ========================================
+// RAPPORT ET ACTIONS
+// ========================================
+
+func (cli *CLI) afficherEcartsInventaire() error {
+	cli.AfficherTitre("📊 RAPPORT D'ÉCARTS")
+
+	inventaire, err := cli.choisirInventaire()
+	if err != nil {
+		return err
+	}
+	ecarts, err := cli.gestionnaireInventaires.Ecarts(inventaire.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.sortie, "\nInventaire '%s' (%s)\n", inventaire.Nom, inventaire.Rayon())
+	fmt.Fprintf(cli.sortie, "📚 Attendus en rayon : %d — relevés : %d — manquants : %d\n",
+		ecarts.Attendus, ecarts.Releves, len(ecarts.Manquants))
+
+	sections := []struct {
+		titre  string
+		livres []models.Livre
+	}{
+		{"❓ Manquants (ni empruntés ni retrouvés)", ecarts.Manquants},
+		{"📕 Trouvés en rayon mais enregistrés comme empruntés", ecarts.EmpruntesPresents},
+		{"📌 Trouvés en rayon mais réservés, à mettre de côté", ecarts.MisDeCote},
+		{"↪️  Trouvés en rayon mais d'un autre rayon", ecarts.HorsRayon},
+		{"🚫 Retirés du fonds mais trouvés en rayon", ecarts.Retrouves},
+	}
+	for _, section := range sections {
+		if len(section.livres) == 0 {
+			continue
+		}
+		fmt.Fprintf(cli.sortie, "\n%s :\n", section.titre)
+		cli.afficherTableauLivresInventaire(section.livres)
+	}
+
+	if len(ecarts.Inconnus) > 0 {
+		fmt.Fprintln(cli.sortie, "\n❔ Codes inconnus du catalogue :")
+		tableau := affichage.NouveauTableau(
+			affichage.Colonne{Titre: "Code", Cle: "code"},
+			affichage.Colonne{Titre: "Relevé le", Cle: "date"},
+		)
+		for _, constat := range ecarts.Inconnus {
+			tableau.AjouterLigne(constat.Saisie, constat.Date.Format("02/01/2006 15:04"))
+		}
+		cli.afficherResultats(tableau, fmt.Sprintf("Total : %d code(s)", len(ecarts.Inconnus)))
+	}
+
+	if len(ecarts.Manquants)+len(ecarts.EmpruntesPresents)+len(ecarts.MisDeCote)+len(ecarts.HorsRayon)+len(ecarts.Retrouves)+len(ecarts.Inconnus) == 0 {
+		cli.AfficherSucces("Aucun écart : le rayon correspond au catalogue.")
+	}
+	return nil
+}
+
+func (cli *CLI) afficherTableauLivresInventaire(livres []models.Livre) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Code-barres", Cle: "code_barres"},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "Auteur", Cle: "auteur", Min: 10},
+		affichage.Colonne{Titre: "Genre", Cle: "genre"},
+	)
+	for _, livre := range livres {
+		tableau.AjouterLigne(strconv.Itoa(livre.ID), livre.CodeBarres, livre.Titre, livre.Auteur, livre.Genre)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d livre(s)", len(livres)))
+}
+
+// declarerPerdus retire en une fois, motif « perdu », tout ou partie des livres manquants
+func (cli *CLI) declarerPerdus() error {
+	cli.AfficherTitre("🚫 DÉCLARER PERDUS LES LIVRES MANQUANTS")
+
+	inventaire, err := cli.choisirInventaire()
+	if err != nil {
+		return err
+	}
+	ecarts, err := cli.gestionnaireInventaires.Ecarts(inventaire.ID)
+	if err != nil {
+		return err
+	}
+	if len(ecarts.Manquants) == 0 {
+		cli.AfficherInfo("Aucun livre manquant dans cet inventaire.")
+		return nil
+	}
+
+	fmt.Fprintln(cli.sortie, "\nLivres manquants :")
+	cli.afficherTableauLivresInventaire(ecarts.Manquants)
+	if inventaire.EstEnCours() {
+		cli.AfficherAvertissement("L'inventaire est encore en cours : assurez-vous que tout le rayon a été relevé.")
+	}
+
+	fmt.Fprint(cli.sortie, "\nIDs à déclarer perdus (séparés par des virgules, « tous » pour tous, vide pour annuler) : ")
+	saisie := strings.TrimSpace(cli.LireEntree())
+	if saisie == "" {
+		cli.AfficherInfo("Aucun livre déclaré perdu.")
+		return nil
+	}
+
+	var ids []int
+	if strings.EqualFold(saisie, "tous") {
+		for _, livre := range ecarts.Manquants {
+			ids = append(ids, livre.ID)
+		}
+	} else {
+		for _, morceau := range strings.Split(saisie, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(morceau))
+			if err != nil {
+				return fmt.Errorf("'%s' n'est pas un ID valide", strings.TrimSpace(morceau))
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	if !cli.LireConfirmation(fmt.Sprintf("\n⚠️ Retirer du fonds %d livre(s) comme perdus ?", len(ids))) {
+		cli.AfficherInfo("Aucun livre déclaré perdu.")
+		return nil
+	}
+
+	perdus, err := cli.gestionnaireInventaires.DeclarerPerdus(inventaire.ID, ids)
+	if len(perdus) > 0 {
+		cli.AfficherSucces(fmt.Sprintf("%d livre(s) déclaré(s) perdu(s) ; ils restent consultables dans les livres retirés.", len(perdus)))
+	}
+	if ignores := len(ids) - len(perdus); err == nil && ignores > 0 {
+		cli.AfficherAvertissement(fmt.Sprintf("%d ID(s) ignoré(s) : ils ne figurent pas parmi les livres manquants.", ignores))
+	}
+	return err
+}
+
+func (cli *CLI) cloturerInventaire() error {
+	cli.AfficherTitre("🔒 CLÔTURER UN INVENTAIRE")
+
+	inventaire, err := cli.choisirInventaire()
+	if err != nil {
+		return err
+	}
+	if !cli.LireConfirmation(fmt.Sprintf("Clôturer l'inventaire '%s' ? Aucun livre ne pourra plus y être relevé", inventaire.Nom)) {
+		cli.AfficherInfo("Inventaire laissé en cours.")
+		return nil
+	}
+
+	if err := cli.gestionnaireInventaires.CloturerInventaire(inventaire.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Inventaire '%s' clôturé.", inventaire.Nom))
+	return nil
+}
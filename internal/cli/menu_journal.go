@@ -0,0 +1,174 @@
+// ==========================================
+// internal/cli/menu_journal.go
+// JOURNAL DES EMPRUNTS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// NOMBRE_EVENEMENTS_AFFICHES limite la liste des derniers événements
+const NOMBRE_EVENEMENTS_AFFICHES = 30
+
+// ========================================
+// SOUS-MENU JOURNAL
+// Chaque emprunt, retour, prolongation, annulation ou suspension est écrit
+// dans un journal qui n'est jamais réécrit. Les listes d'emprunts et les
+// compteurs en sont déduits : on peut donc les reconstruire, ou revoir
+// l'état des emprunts à une date passée.
+// ========================================
+
+func (cli *CLI) menuJournal() error {
+	for {
+		cli.AfficherTitre("🧾 JOURNAL DES EMPRUNTS")
+
+		evenements, instantane := cli.gestionnaireEmprunts.InfosJournal()
+		fmt.Fprintf(cli.sortie, "Journal ouvert le %s : %d événement(s)",
+			cli.gestionnaireEmprunts.DebutJournal().Format("02/01/2006 15:04"), evenements)
+		if instantane > 0 {
+			fmt.Fprintf(cli.sortie, ", dernier instantané après l'événement n°%d", instantane)
+		}
+		fmt.Fprintln(cli.sortie)
+		fmt.Fprintln(cli.sortie)
+
+		fmt.Fprintln(cli.sortie, "1. 📜 Derniers événements")
+		fmt.Fprintln(cli.sortie, "2. 🕰️  État des emprunts à une date")
+		fmt.Fprintln(cli.sortie, "3. 🔁 Reconstruire les emprunts depuis le journal")
+		fmt.Fprintln(cli.sortie, "4. 📸 Prendre un instantané")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu des emprunts")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 4)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerEvenements()
+		case 2:
+			err = cli.afficherEtatAu()
+		case 3:
+			err = cli.rejouerJournal()
+		case 4:
+			err = cli.prendreInstantane()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerEvenements() {
+	cli.AfficherTitre("📜 DERNIERS ÉVÉNEMENTS")
+
+	evenements := cli.gestionnaireEmprunts.ListerEvenements(NOMBRE_EVENEMENTS_AFFICHES)
+	if len(evenements) == 0 {
+		cli.AfficherInfo("Aucun événement depuis l'ouverture du journal.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "N°", Cle: "numero", Numerique: true},
+		affichage.Colonne{Titre: "Date", Cle: "date"},
+		affichage.Colonne{Titre: "Événement", Cle: "evenement", Min: 20},
+	)
+	for _, evenement := range evenements {
+		tableau.AjouterLigne(strconv.Itoa(evenement.Numero), evenement.Date.Format("02/01/2006 15:04"), evenement.Description())
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d événement(s), du plus récent au plus ancien", len(evenements)))
+}
+
+// afficherEtatAu montre les emprunts en cours et les membres suspendus tels
+// qu'ils étaient à la fin du jour saisi
+func (cli *CLI) afficherEtatAu() error {
+	cli.AfficherTitre("🕰️  ÉTAT DES EMPRUNTS À UNE DATE")
+
+	fmt.Fprint(cli.sortie, "Date (JJ/MM/AAAA, vide = maintenant) : ")
+	saisie := strings.TrimSpace(cli.LireEntree())
+	date := time.Now()
+	if saisie != "" {
+		jour, err := time.ParseInLocation("02/01/2006", saisie, time.Local)
+		if err != nil {
+			return fmt.Errorf("date '%s' invalide (format attendu : JJ/MM/AAAA)", saisie)
+		}
+		if fin := jour.AddDate(0, 0, 1).Add(-time.Second); fin.Before(date) {
+			date = fin
+		}
+	}
+
+	etat, err := cli.gestionnaireEmprunts.EtatAu(date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.sortie, "\nÉtat au %s (événements n°1 à %d pris en compte)\n", etat.Date.Format("02/01/2006 15:04"), etat.Evenements)
+
+	if len(etat.EnCours) == 0 {
+		cli.AfficherInfo("Aucun emprunt en cours à cette date.")
+	} else {
+		fmt.Fprintln(cli.sortie, "\nEmprunts en cours :")
+		tableau := affichage.NouveauTableau(
+			affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+			affichage.Colonne{Titre: "Livre", Cle: "livre", Min: 12},
+			affichage.Colonne{Titre: "Membre", Cle: "membre", Min: 10},
+			affichage.Colonne{Titre: "Emprunté", Cle: "date_emprunt"},
+			affichage.Colonne{Titre: "À rendre", Cle: "date_retour_prevu"},
+			affichage.Colonne{Titre: "Statut", Cle: "statut"},
+		)
+		for _, emprunt := range etat.EnCours {
+			statut := "📘 En cours"
+			if emprunt.Statut == models.STATUT_EN_RETARD {
+				statut = "⚠️ En retard"
+			}
+			tableau.AjouterLigne(strconv.Itoa(emprunt.ID), emprunt.TitreLivre, emprunt.NomMembre,
+				emprunt.DateEmprunt.Format("02/01/2006"), emprunt.DateRetourPrevu.Format("02/01/2006"), statut)
+		}
+		cli.afficherResultats(tableau, fmt.Sprintf("Total : %d emprunt(s) en cours", len(etat.EnCours)))
+	}
+
+	if len(etat.Suspendus) > 0 {
+		fmt.Fprintf(cli.sortie, "\n⛔ Membres suspendus : %s\n", strings.Join(etat.Suspendus, ", "))
+	}
+	return nil
+}
+
+func (cli *CLI) rejouerJournal() error {
+	cli.AfficherTitre("🔁 RECONSTRUIRE LES EMPRUNTS")
+
+	fmt.Fprintln(cli.sortie, "Les emprunts, la disponibilité des livres et les compteurs des membres vont être")
+	fmt.Fprintln(cli.sortie, "recalculés à partir de l'ouverture du journal, sans tenir compte des instantanés.")
+	fmt.Fprintln(cli.sortie, "Les corrections du contrôle d'intégrité, qui ne sont pas dans le journal, seront perdues.")
+
+	if !cli.LireConfirmation("\nReconstruire les emprunts ?") {
+		cli.AfficherInfo("Reconstruction annulée.")
+		return nil
+	}
+
+	nombre, err := cli.gestionnaireEmprunts.Rejouer()
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("%d événement(s) rejoué(s), emprunts et compteurs reconstruits.", nombre))
+	return nil
+}
+
+func (cli *CLI) prendreInstantane() error {
+	if err := cli.gestionnaireEmprunts.PrendreInstantane(); err != nil {
+		return err
+	}
+	_, instantane := cli.gestionnaireEmprunts.InfosJournal()
+	cli.AfficherSucces(fmt.Sprintf("Instantané enregistré après l'événement n°%d : le prochain démarrage repartira de là.", instantane))
+	return nil
+}
@@ -0,0 +1,355 @@
+// ==========================================
+// internal/cli/menu_peb.go
+// PRÊTS ENTRE BIBLIOTHÈQUES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// SOUS-MENU PRÊTS ENTRE BIBLIOTHÈQUES
+// Le livre demandé pour un membre entre au catalogue à sa réception : il
+// s'emprunte et se rend au comptoir comme les autres, jusqu'à la date fixée
+// par le prêteur, puis quitte le catalogue quand il est renvoyé.
+// ========================================
+
+func (cli *CLI) menuPEB() error {
+	for {
+		cli.AfficherTitre("🔁 PRÊTS ENTRE BIBLIOTHÈQUES")
+		fmt.Fprintln(cli.sortie, "1. 📋 Demandes en cours")
+		fmt.Fprintln(cli.sortie, "2. ➕ Nouvelle demande pour un membre")
+		fmt.Fprintln(cli.sortie, "3. 🚚 Noter l'expédition par le prêteur")
+		fmt.Fprintln(cli.sortie, "4. 📥 Réceptionner un livre")
+		fmt.Fprintln(cli.sortie, "5. 📤 Renvoyer un livre au prêteur")
+		fmt.Fprintln(cli.sortie, "6. 💶 Ajouter un coût")
+		fmt.Fprintln(cli.sortie, "7. 🔍 Détail d'une demande")
+		fmt.Fprintln(cli.sortie, "8. ❌ Annuler une demande")
+		fmt.Fprintln(cli.sortie, "9. 🗂️  Toutes les demandes")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 9)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerDemandesPEB(false)
+		case 2:
+			err = cli.nouvelleDemandePEB()
+		case 3:
+			err = cli.noterExpeditionPEB()
+		case 4:
+			err = cli.receptionnerPEB()
+		case 5:
+			err = cli.renvoyerPEB()
+		case 6:
+			err = cli.ajouterCoutPEB()
+		case 7:
+			err = cli.afficherDemandePEB()
+		case 8:
+			err = cli.annulerDemandePEB()
+		case 9:
+			cli.listerDemandesPEB(true)
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerDemandesPEB(toutes bool) {
+	if toutes {
+		cli.AfficherTitre("🗂️  TOUTES LES DEMANDES")
+	} else {
+		cli.AfficherTitre("📋 DEMANDES EN COURS")
+	}
+
+	demandes := cli.gestionnairePEB.ListerDemandes(toutes)
+	if len(demandes) == 0 {
+		cli.AfficherInfo("Aucune demande de prêt entre bibliothèques.")
+		return
+	}
+	cli.afficherTableauDemandesPEB(demandes)
+}
+
+func (cli *CLI) afficherTableauDemandesPEB(demandes []models.DemandePEB) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Livre", Cle: "livre", Min: 12},
+		affichage.Colonne{Titre: "Membre", Cle: "membre", Min: 10},
+		affichage.Colonne{Titre: "Prêteur", Cle: "preteur", Min: 10},
+		affichage.Colonne{Titre: "Échéance", Cle: "echeance"},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+		affichage.Colonne{Titre: "Coût", Cle: "cout", Numerique: true},
+	)
+
+	for _, demande := range demandes {
+		// Avant la réception, l'échéance est la date de besoin du membre
+		echeance := ""
+		if !demande.DateRetourPreteur.IsZero() {
+			echeance = "retour " + demande.DateRetourPreteur.Format("02/01/2006")
+		} else if !demande.DateBesoin.IsZero() {
+			echeance = "besoin " + demande.DateBesoin.Format("02/01/2006")
+		}
+		tableau.AjouterLigne(strconv.Itoa(demande.ID), demande.Titre, demande.NomMembre, demande.Preteur,
+			echeance, models.LibelleStatutPEB(demande.Statut), demande.Total().String())
+	}
+
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d demande(s)", len(demandes)))
+}
+
+func (cli *CLI) nouvelleDemandePEB() error {
+	cli.AfficherTitre("➕ NOUVELLE DEMANDE")
+	cli.AfficherInfo("Pour un livre que la bibliothèque ne possède pas : un livre du fonds se réserve.")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette carte")
+	}
+
+	titre := cli.LireEntreeObligatoire("Titre : ")
+	fmt.Fprint(cli.sortie, "Auteur (facultatif) : ")
+	auteur := cli.LireEntree()
+	fmt.Fprint(cli.sortie, "ISBN (facultatif) : ")
+	isbn := cli.LireEntree()
+	dateBesoin, err := cli.lireDatePEB("Nécessaire avant le (JJ/MM/AAAA, vide = pas de date) : ")
+	if err != nil {
+		return err
+	}
+
+	demande, err := cli.gestionnairePEB.DemanderPEB(membre.ID, titre, auteur, isbn, dateBesoin)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Demande #%d enregistrée : « %s » pour %s.", demande.ID, demande.Titre, demande.NomMembre))
+	return nil
+}
+
+func (cli *CLI) noterExpeditionPEB() error {
+	cli.AfficherTitre("🚚 EXPÉDITION PAR LE PRÊTEUR")
+
+	demande, err := cli.choisirDemandePEB(models.PEB_DEMANDEE)
+	if demande == nil || err != nil {
+		return err
+	}
+
+	preteur := cli.lirePreteur(demande)
+	if err := cli.gestionnairePEB.NoterExpedition(demande.ID, preteur); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("« %s » est en route.", demande.Titre))
+	return nil
+}
+
+func (cli *CLI) receptionnerPEB() error {
+	cli.AfficherTitre("📥 RÉCEPTIONNER UN LIVRE")
+
+	demande, err := cli.choisirDemandePEB(models.PEB_DEMANDEE, models.PEB_EXPEDIEE)
+	if demande == nil || err != nil {
+		return err
+	}
+
+	preteur := cli.lirePreteur(demande)
+	dateRetour, err := cli.lireDatePEB("Date de retour fixée par le prêteur (JJ/MM/AAAA) : ")
+	if err != nil {
+		return err
+	}
+	if dateRetour.IsZero() {
+		return fmt.Errorf("la date de retour fixée par le prêteur est obligatoire")
+	}
+
+	// Le livre peut être rendu jusqu'au soir du jour fixé
+	livre, err := cli.gestionnairePEB.Receptionner(demande.ID, preteur, dateRetour.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("« %s » est entré au catalogue sous le code %s (livre ID %d).", livre.Titre, livre.CodeBarres, livre.ID))
+	cli.AfficherInfo(fmt.Sprintf("À mettre de côté pour %s, seul membre à pouvoir l'emprunter, jusqu'au %s.",
+		demande.NomMembre, dateRetour.Format("02/01/2006")))
+	return nil
+}
+
+func (cli *CLI) renvoyerPEB() error {
+	cli.AfficherTitre("📤 RENVOYER UN LIVRE")
+
+	demande, err := cli.choisirDemandePEB(models.PEB_RECUE, models.PEB_RENDUE)
+	if demande == nil || err != nil {
+		return err
+	}
+
+	if demande.Statut == models.PEB_RECUE && !cli.LireConfirmation(fmt.Sprintf("%s n'a pas emprunté ce livre. Le renvoyer quand même ?", demande.NomMembre)) {
+		cli.AfficherInfo("Renvoi annulé.")
+		return nil
+	}
+
+	if err := cli.gestionnairePEB.Renvoyer(demande.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("« %s » est renvoyé à %s et a quitté le catalogue.", demande.Titre, demande.Preteur))
+	return nil
+}
+
+func (cli *CLI) ajouterCoutPEB() error {
+	cli.AfficherTitre("💶 AJOUTER UN COÛT")
+
+	demande, err := cli.choisirDemandePEB()
+	if demande == nil || err != nil {
+		return err
+	}
+
+	libelle := cli.LireEntreeObligatoire("Libellé (ex : frais de prêt, port) : ")
+	montant, err := models.LireMontant(cli.LireEntreeObligatoire("Montant (€) : "))
+	if err != nil {
+		return err
+	}
+
+	if err := cli.gestionnairePEB.AjouterCout(demande.ID, libelle, montant); err != nil {
+		return err
+	}
+	demande, _ = cli.gestionnairePEB.TrouverDemandeParID(demande.ID)
+	cli.AfficherSucces(fmt.Sprintf("Coût ajouté : la demande #%d revient à %s.", demande.ID, demande.Total()))
+	return nil
+}
+
+func (cli *CLI) afficherDemandePEB() error {
+	cli.AfficherTitre("🔍 DÉTAIL D'UNE DEMANDE")
+
+	demande, err := cli.choisirDemandePEB()
+	if demande == nil || err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.sortie, "\nDemande #%d — %s\n", demande.ID, models.LibelleStatutPEB(demande.Statut))
+	fmt.Fprintf(cli.sortie, "Livre : %s", demande.Titre)
+	if demande.Auteur != "" {
+		fmt.Fprintf(cli.sortie, " (%s)", demande.Auteur)
+	}
+	if demande.ISBN != "" {
+		fmt.Fprintf(cli.sortie, " — ISBN %s", demande.ISBN)
+	}
+	fmt.Fprintln(cli.sortie)
+	fmt.Fprintf(cli.sortie, "Pour : %s, demandé le %s", demande.NomMembre, demande.DateDemande.Format("02/01/2006"))
+	if !demande.DateBesoin.IsZero() {
+		fmt.Fprintf(cli.sortie, ", nécessaire avant le %s", demande.DateBesoin.Format("02/01/2006"))
+	}
+	fmt.Fprintln(cli.sortie)
+	if demande.Preteur != "" {
+		fmt.Fprintf(cli.sortie, "Prêteur : %s\n", demande.Preteur)
+	}
+	if !demande.DateRetourPreteur.IsZero() {
+		fmt.Fprintf(cli.sortie, "À lui rendre le : %s\n", demande.DateRetourPreteur.Format("02/01/2006"))
+	}
+	if livre, _ := cli.gestionnaireLivres.TrouverLivreParID(demande.LivreID); livre != nil && livre.EstPEB() {
+		fmt.Fprintf(cli.sortie, "Au catalogue : livre ID %d, code %s\n", livre.ID, livre.CodeBarres)
+	}
+
+	fmt.Fprintln(cli.sortie, "\nHistorique :")
+	historique := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Date", Cle: "date"},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+		affichage.Colonne{Titre: "Note", Cle: "note", Min: 10},
+	)
+	for _, etape := range demande.Historique {
+		historique.AjouterLigne(etape.Date.Format("02/01/2006 15:04"), models.LibelleStatutPEB(etape.Statut), etape.Note)
+	}
+	cli.afficherResultats(historique, fmt.Sprintf("%d étape(s)", len(demande.Historique)))
+
+	if len(demande.Couts) == 0 {
+		cli.AfficherInfo("Aucun coût enregistré.")
+		return nil
+	}
+	fmt.Fprintln(cli.sortie, "\nCoûts :")
+	couts := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Date", Cle: "date"},
+		affichage.Colonne{Titre: "Libellé", Cle: "libelle", Min: 10},
+		affichage.Colonne{Titre: "Montant", Cle: "montant", Numerique: true},
+	)
+	for _, cout := range demande.Couts {
+		couts.AjouterLigne(cout.Date.Format("02/01/2006"), cout.Libelle, cout.Montant.String())
+	}
+	cli.afficherResultats(couts, fmt.Sprintf("Total : %s", demande.Total()))
+	return nil
+}
+
+func (cli *CLI) annulerDemandePEB() error {
+	cli.AfficherTitre("❌ ANNULER UNE DEMANDE")
+
+	demande, err := cli.choisirDemandePEB(models.PEB_DEMANDEE)
+	if demande == nil || err != nil {
+		return err
+	}
+
+	fmt.Fprint(cli.sortie, "Raison (facultatif) : ")
+	raison := cli.LireEntree()
+	if err := cli.gestionnairePEB.Annuler(demande.ID, raison); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Demande #%d annulée.", demande.ID))
+	return nil
+}
+
+// choisirDemandePEB affiche les demandes aux statuts donnés (toutes sans
+// statut) et retourne celle choisie, nil s'il n'y en a aucune
+func (cli *CLI) choisirDemandePEB(statuts ...string) (*models.DemandePEB, error) {
+	retenus := make(map[string]bool)
+	for _, statut := range statuts {
+		retenus[statut] = true
+	}
+	var demandes []models.DemandePEB
+	for _, demande := range cli.gestionnairePEB.ListerDemandes(true) {
+		if len(statuts) == 0 || retenus[demande.Statut] {
+			demandes = append(demandes, demande)
+		}
+	}
+	if len(demandes) == 0 {
+		cli.AfficherInfo("Aucune demande concernée.")
+		return nil, nil
+	}
+	cli.afficherTableauDemandesPEB(demandes)
+
+	id := cli.LireEntreeEntierObligatoire("ID de la demande : ")
+	for _, demande := range demandes {
+		if demande.ID == id {
+			trouvee, _ := cli.gestionnairePEB.TrouverDemandeParID(id)
+			return trouvee, nil
+		}
+	}
+	return nil, fmt.Errorf("la demande ID %d n'est pas dans la liste", id)
+}
+
+// lirePreteur demande la bibliothèque prêteuse, en proposant celle déjà connue
+func (cli *CLI) lirePreteur(demande *models.DemandePEB) string {
+	if demande.Preteur == "" {
+		return cli.LireEntreeObligatoire("Bibliothèque prêteuse : ")
+	}
+	fmt.Fprintf(cli.sortie, "Bibliothèque prêteuse (vide = %s) : ", demande.Preteur)
+	return cli.LireEntree()
+}
+
+// lireDatePEB lit une date au format JJ/MM/AAAA ; une saisie vide donne la date zéro
+func (cli *CLI) lireDatePEB(message string) (time.Time, error) {
+	fmt.Fprint(cli.sortie, message)
+	saisie := strings.TrimSpace(cli.LireEntree())
+	if saisie == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("02/01/2006", saisie, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date '%s' invalide (format attendu : JJ/MM/AAAA)", saisie)
+	}
+	return date, nil
+}
@@ -0,0 +1,40 @@
+// ==========================================
+// internal/cli/menu_rapports.go
+// RAPPORTS D'ACTIVITÉ
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/rapports"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// genererRapport produit le rapport HTML et PDF d'une période choisie
+// (le mois dernier par défaut) dans le dossier des rapports
+func (cli *CLI) genererRapport() error {
+	cli.AfficherTitre("🗂️  RAPPORT D'ACTIVITÉ (HTML ET PDF)")
+	fmt.Fprintf(cli.sortie, "Fichiers écrits dans '%s/'\n", cli.config.Rapports.Dossier)
+
+	fmt.Fprintf(cli.sortie, "Période (%s, vide = mois dernier) : ", statistiques.FORMATS_PERIODE)
+	periode, err := statistiques.LirePeriode(cli.LireEntree(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	rapport := rapports.Collecter(periode, cli.gestionnaireEmprunts)
+	chemins, err := rapport.Enregistrer(cli.config.Rapports.Dossier)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Rapport (%s) : %d emprunt(s), %d retour(s), %d retard(s).",
+		periode.Libelle(), rapport.Emprunts.Total, rapport.Emprunts.Retours, len(rapport.Emprunts.Retards)))
+	for _, chemin := range chemins {
+		fmt.Fprintf(cli.sortie, "   📄 %s\n", chemin)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// ==========================================
+// internal/cli/menu_recommandations.go
+// SUGGESTIONS DE LECTURE
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/recommandations"
+)
+
+// suggererLectures propose au comptoir des livres à un membre, d'après son historique
+func (cli *CLI) suggererLectures() error {
+	cli.AfficherTitre("💡 SUGGESTIONS DE LECTURE")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette saisie")
+	}
+
+	moteur := recommandations.Construire(cli.gestionnaireLivres, cli.gestionnaireEmprunts, cli.gestionnaireReservations)
+	suggestions := moteur.Recommander(membre.ID, recommandations.K_PAR_DEFAUT)
+	if len(suggestions) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Aucune suggestion pour %s : le membre a déjà emprunté tous les titres du catalogue.", membre.Nom))
+		return nil
+	}
+
+	fmt.Fprintf(cli.sortie, "\nPour %s :\n", membre.Nom)
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 20},
+		affichage.Colonne{Titre: "Auteur", Cle: "auteur", Min: 12},
+		affichage.Colonne{Titre: "État", Cle: "etat"},
+		affichage.Colonne{Titre: "Pourquoi", Cle: "raison", Min: 20},
+	)
+	for _, suggestion := range suggestions {
+		etat := suggestion.Etat
+		if suggestion.Attente > 0 {
+			etat = fmt.Sprintf("%s (%d en attente)", etat, suggestion.Attente)
+		}
+		tableau.AjouterLigne(strconv.Itoa(suggestion.LivreID), suggestion.Titre, suggestion.Auteur, etat, suggestion.Raison)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d suggestion(s)", len(suggestions)))
+	cli.AfficherInfo("Les livres déjà empruntés par le membre ne sont jamais proposés.")
+	return nil
+}
+
+// evaluerRecommandations mesure la précision@k sur l'historique mis de côté
+func (cli *CLI) evaluerRecommandations() error {
+	cli.AfficherTitre("🧪 ÉVALUATION DES SUGGESTIONS DE LECTURE")
+
+	k := recommandations.K_PAR_DEFAUT
+	fmt.Fprintf(cli.sortie, "Nombre de suggestions par membre, k (vide = %d) : ", k)
+	if saisie := strings.TrimSpace(cli.LireEntree()); saisie != "" {
+		var err error
+		if k, err = strconv.Atoi(saisie); err != nil || k < 1 {
+			return fmt.Errorf("'%s' n'est pas un nombre de suggestions valide", saisie)
+		}
+	}
+
+	evaluation := recommandations.Evaluer(cli.gestionnaireLivres, cli.gestionnaireEmprunts, k)
+	if evaluation.Membres == 0 {
+		cli.AfficherInfo("Pas assez d'historique : il faut des membres ayant emprunté au moins deux titres.")
+		return nil
+	}
+
+	fmt.Fprintf(cli.sortie, "\n👥 Membres évalués : %d (%d titre(s) mis de côté, les %.0f %% les plus récents de chaque historique)\n",
+		evaluation.Membres, evaluation.Retenus, recommandations.PART_RETENUE*100)
+	fmt.Fprintf(cli.sortie, "🎯 Précision@%d : %.1f %%\n", evaluation.K, evaluation.Precision)
+	fmt.Fprintf(cli.sortie, "🔁 Rappel@%d : %.1f %%\n", evaluation.K, evaluation.Rappel)
+	fmt.Fprintf(cli.sortie, "📊 Précision@%d des seuls titres les plus empruntés : %.1f %%\n", evaluation.K, evaluation.PrecisionPopularite)
+	return nil
+}
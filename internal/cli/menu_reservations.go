@@ -0,0 +1,200 @@
+// ==========================================
+// internal/cli/menu_reservations.go
+// MENU DES RÉSERVATIONS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// SOUS-MENU RÉSERVATIONS
+// Les membres réservent aussi depuis le portail ; l'accueil voit ici les mêmes files d'attente.
+// ========================================
+
+func (cli *CLI) menuReservations() error {
+	for {
+		cli.AfficherTitre("📌 RÉSERVATIONS")
+		fmt.Fprintln(cli.sortie, "1. 📋 Réservations en cours")
+		fmt.Fprintln(cli.sortie, "2. ➕ Réserver un livre pour un membre")
+		fmt.Fprintln(cli.sortie, "3. ❌ Annuler une réservation")
+		fmt.Fprintln(cli.sortie, "4. ⏳ File d'attente d'un livre")
+		fmt.Fprintln(cli.sortie, "5. ⌛ Libérer les livres non retirés à temps")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu des emprunts")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 5)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerReservations()
+		case 2:
+			err = cli.reserverLivre()
+		case 3:
+			err = cli.annulerReservation()
+		case 4:
+			err = cli.afficherFileAttente()
+		case 5:
+			err = cli.expirerReservations()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerReservations() {
+	cli.AfficherTitre("📋 RÉSERVATIONS EN COURS")
+
+	reservations := cli.gestionnaireReservations.ListerReservationsActives()
+	if len(reservations) == 0 {
+		cli.AfficherInfo("Aucune réservation en cours.")
+		return
+	}
+	cli.afficherTableauReservations(reservations)
+}
+
+func (cli *CLI) reserverLivre() error {
+	cli.AfficherTitre("➕ RÉSERVER UN LIVRE")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(cli.LireEntreeObligatoire("ID ou code-barres du livre : "))
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à cette saisie")
+	}
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette carte")
+	}
+
+	reservation, err := cli.gestionnaireEmprunts.ReserverLivre(livre.ID, membre.ID)
+	if err != nil {
+		return err
+	}
+
+	if reservation.Statut == models.RESERVATION_PRETE {
+		cli.AfficherSucces(fmt.Sprintf("« %s » est en rayon : mettez-le de côté pour %s (jusqu'au %s).",
+			reservation.TitreLivre, reservation.NomMembre, reservation.DateLimite.Format("02/01/2006")))
+		return nil
+	}
+	if reservation.Statut == models.RESERVATION_EN_TRANSIT {
+		cli.AfficherSucces(fmt.Sprintf("Réservation enregistrée : un exemplaire de « %s » est acheminé vers %s pour %s.",
+			reservation.TitreLivre, cli.gestionnaireSuccursales.NomSuccursale(reservation.SuccursaleRetrait), reservation.NomMembre))
+		cli.signalerTransfert(reservation.LivreID)
+		return nil
+	}
+	cli.AfficherSucces(fmt.Sprintf("Réservation enregistrée : %s est n° %d dans la file d'attente de « %s ».",
+		reservation.NomMembre, cli.gestionnaireReservations.PositionDansFile(reservation), reservation.TitreLivre))
+	return nil
+}
+
+func (cli *CLI) annulerReservation() error {
+	cli.AfficherTitre("❌ ANNULER UNE RÉSERVATION")
+
+	reservations := cli.gestionnaireReservations.ListerReservationsActives()
+	if len(reservations) == 0 {
+		cli.AfficherInfo("Aucune réservation en cours.")
+		return nil
+	}
+	cli.afficherTableauReservations(reservations)
+
+	id := cli.LireEntreeEntierObligatoire("\nID de la réservation à annuler : ")
+	reservation, _ := cli.gestionnaireReservations.TrouverReservationParID(id)
+	if reservation == nil {
+		return fmt.Errorf("réservation ID %d introuvable", id)
+	}
+	livreID := reservation.LivreID
+
+	if err := cli.gestionnaireReservations.AnnulerReservation(id, 0); err != nil {
+		return err
+	}
+	cli.AfficherSucces("Réservation annulée.")
+	cli.signalerMiseDeCote(livreID)
+	return nil
+}
+
+func (cli *CLI) afficherFileAttente() error {
+	cli.AfficherTitre("⏳ FILE D'ATTENTE D'UN LIVRE")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(cli.LireEntreeObligatoire("ID ou code-barres du livre : "))
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à cette saisie")
+	}
+
+	var reservations []models.Reservation
+	if prete := cli.gestionnaireReservations.ReservationPrete(livre.ID); prete != nil {
+		reservations = append(reservations, *prete)
+	}
+	reservations = append(reservations, cli.gestionnaireReservations.FileAttente(livre.ID)...)
+
+	if len(reservations) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Personne n'a réservé « %s ».", livre.Titre))
+		return nil
+	}
+	fmt.Fprintf(cli.sortie, "\n« %s » :\n", livre.Titre)
+	cli.afficherTableauReservations(reservations)
+	return nil
+}
+
+func (cli *CLI) expirerReservations() error {
+	cli.AfficherTitre("⌛ LIVRES NON RETIRÉS À TEMPS")
+
+	expirees, err := cli.gestionnaireReservations.ExpirerReservations()
+	if len(expirees) == 0 && err == nil {
+		cli.AfficherInfo("Aucune réservation n'a dépassé sa date limite de retrait.")
+		return nil
+	}
+
+	for _, reservation := range expirees {
+		fmt.Fprintf(cli.sortie, "⌛ « %s » n'a pas été retiré par %s.\n", reservation.TitreLivre, reservation.NomMembre)
+		cli.signalerMiseDeCote(reservation.LivreID)
+	}
+	return err
+}
+
+// signalerMiseDeCote prévient l'accueil quand un livre doit être mis de côté
+// pour un membre plutôt que rangé en rayon
+func (cli *CLI) signalerMiseDeCote(livreID int) {
+	reservation := cli.gestionnaireEmprunts.MiseDeCote(livreID)
+	if reservation == nil {
+		cli.signalerTransfert(livreID)
+		return
+	}
+	cli.AfficherInfo(fmt.Sprintf("« %s » est réservé : à mettre de côté pour %s jusqu'au %s.",
+		reservation.TitreLivre, reservation.NomMembre, reservation.DateLimite.Format("02/01/2006")))
+}
+
+func (cli *CLI) afficherTableauReservations(reservations []models.Reservation) {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Livre", Cle: "livre", Min: 12},
+		affichage.Colonne{Titre: "Membre", Cle: "membre", Min: 10},
+		affichage.Colonne{Titre: "Réservé", Cle: "date_reservation"},
+		affichage.Colonne{Titre: "Statut", Cle: "statut"},
+	)
+
+	for _, reservation := range reservations {
+		statut := models.LibelleStatutReservation(reservation.Statut)
+		if reservation.Statut == models.RESERVATION_PRETE && reservation.DateLimite != nil {
+			statut += " → " + reservation.DateLimite.Format("02/01/2006")
+		} else if position := cli.gestionnaireReservations.PositionDansFile(reservation); position > 0 {
+			statut += fmt.Sprintf(" (n° %d)", position)
+		}
+
+		tableau.AjouterLigne(strconv.Itoa(reservation.ID), reservation.TitreLivre, reservation.NomMembre,
+			reservation.DateReservation.Format("02/01/2006"), statut)
+	}
+
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d réservation(s)", len(reservations)))
+}
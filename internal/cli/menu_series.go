@@ -0,0 +1,312 @@
+// ==========================================
+// internal/cli/menu_series.go
+// MENU DES SÉRIES, OEUVRES ET ÉDITIONS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// SOUS-MENU SÉRIES
+// ========================================
+
+func (cli *CLI) menuSeries() error {
+	for {
+		cli.AfficherTitre("📚 SÉRIES, OEUVRES ET ÉDITIONS")
+		fmt.Fprintln(cli.sortie, "1. 📋 Lister les séries")
+		fmt.Fprintln(cli.sortie, "2. 📖 Afficher une série (tomes et disponibilité)")
+		fmt.Fprintln(cli.sortie, "3. ⏭️  Quel est le tome suivant ?")
+		fmt.Fprintln(cli.sortie, "4. ➕ Créer une série")
+		fmt.Fprintln(cli.sortie, "5. ➕ Créer une oeuvre / un tome")
+		fmt.Fprintln(cli.sortie, "6. 🔗 Rattacher un livre à une oeuvre (édition)")
+		fmt.Fprintln(cli.sortie, "7. ✂️  Détacher un livre de son oeuvre")
+		fmt.Fprintln(cli.sortie, "8. ✏️  Modifier une oeuvre")
+		fmt.Fprintln(cli.sortie, "9. 🗑️  Supprimer une oeuvre")
+		fmt.Fprintln(cli.sortie, "10. 🗑️  Supprimer une série")
+		fmt.Fprintln(cli.sortie, "11. 🏆 Classement des oeuvres (toutes éditions)")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 11)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerSeries()
+		case 2:
+			err = cli.afficherSerie()
+		case 3:
+			err = cli.afficherTomeSuivant()
+		case 4:
+			err = cli.creerSerie()
+		case 5:
+			err = cli.creerOeuvre()
+		case 6:
+			err = cli.rattacherLivreOeuvre()
+		case 7:
+			err = cli.detacherLivreOeuvre()
+		case 8:
+			err = cli.modifierOeuvre()
+		case 9:
+			err = cli.supprimerOeuvre()
+		case 10:
+			err = cli.supprimerSerie()
+		case 11:
+			cli.afficherClassementOeuvres()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerSeries() {
+	cli.AfficherTitre("📋 LISTE DES SÉRIES")
+
+	series := cli.gestionnaireSeries.ListerSeries()
+	if len(series) == 0 {
+		cli.AfficherInfo("Aucune série enregistrée.")
+		return
+	}
+
+	for _, serie := range series {
+		tomes := cli.gestionnaireSeries.ListerOeuvresDeSerie(serie.ID)
+		fmt.Fprintf(cli.sortie, "• [%d] %s - %d tome(s)\n", serie.ID, serie.Titre, len(tomes))
+	}
+
+	fmt.Fprintf(cli.sortie, "\nTotal : %d série(s)\n", len(series))
+}
+
+func (cli *CLI) afficherSerie() error {
+	cli.AfficherTitre("📖 AFFICHER UNE SÉRIE")
+
+	id := cli.LireEntreeEntierObligatoire("ID de la série : ")
+
+	serie, _ := cli.gestionnaireSeries.TrouverSerieParID(id)
+	if serie == nil {
+		return fmt.Errorf("aucune série trouvée avec l'ID %d", id)
+	}
+
+	fmt.Fprintf(cli.sortie, "\n📚 %s\n", serie.Titre)
+
+	volumes := cli.gestionnaireSeries.ListerVolumes(id)
+	if len(volumes) == 0 {
+		cli.AfficherInfo("Cette série ne contient encore aucun tome.")
+		return nil
+	}
+
+	for _, volume := range volumes {
+		cli.afficherVolume(volume)
+	}
+
+	return nil
+}
+
+// afficherVolume affiche un tome avec chacune de ses éditions et leur disponibilité
+func (cli *CLI) afficherVolume(volume services.VolumeSerie) {
+	statut := "📕 aucune édition disponible"
+	if len(volume.Editions) == 0 {
+		statut = "❔ absent du catalogue"
+	} else if volume.Disponibles > 0 {
+		statut = fmt.Sprintf("📗 %d/%d édition(s) disponible(s)", volume.Disponibles, len(volume.Editions))
+	}
+
+	fmt.Fprintf(cli.sortie, "\n  Tome %d : %s [oeuvre %d] - %s\n", volume.Oeuvre.NumeroTome, volume.Oeuvre.Titre, volume.Oeuvre.ID, statut)
+	for _, livre := range volume.Editions {
+		edition := livre.Edition
+		if edition == "" {
+			edition = "édition non précisée"
+		}
+		disponibilite := "📗"
+		if !livre.EstDisponible() {
+			disponibilite = "📕"
+		}
+		fmt.Fprintf(cli.sortie, "     %s Livre %d - %s (%s)\n", disponibilite, livre.ID, livre.Titre, edition)
+	}
+}
+
+func (cli *CLI) afficherTomeSuivant() error {
+	cli.AfficherTitre("⏭️ TOME SUIVANT")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre lu : ")
+
+	volume, err := cli.gestionnaireSeries.TomeSuivant(livreID)
+	if err != nil {
+		return err
+	}
+
+	if volume == nil {
+		cli.AfficherInfo("C'est le dernier tome enregistré de la série.")
+		return nil
+	}
+
+	cli.afficherVolume(*volume)
+	return nil
+}
+
+func (cli *CLI) creerSerie() error {
+	cli.AfficherTitre("➕ CRÉER UNE SÉRIE")
+
+	titre := cli.LireEntreeObligatoire("Titre de la série : ")
+
+	serie, err := cli.gestionnaireSeries.AjouterSerie(titre)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Série '%s' créée (ID: %d) !", serie.Titre, serie.ID))
+	return nil
+}
+
+func (cli *CLI) creerOeuvre() error {
+	cli.AfficherTitre("➕ CRÉER UNE OEUVRE / UN TOME")
+
+	titre := cli.LireEntreeObligatoire("Titre de l'oeuvre : ")
+	serieID := cli.LireEntreeEntierObligatoire("ID de la série (0 si hors série) : ")
+
+	numeroTome := 0
+	if serieID != 0 {
+		numeroTome = cli.LireEntreeEntierObligatoire("Numéro du tome : ")
+	}
+
+	oeuvre, err := cli.gestionnaireSeries.AjouterOeuvre(titre, serieID, numeroTome)
+	if err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Oeuvre '%s' créée (ID: %d) !", oeuvre.Titre, oeuvre.ID))
+	return nil
+}
+
+func (cli *CLI) rattacherLivreOeuvre() error {
+	cli.AfficherTitre("🔗 RATTACHER UN LIVRE À UNE OEUVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+	oeuvreID := cli.LireEntreeEntierObligatoire("ID de l'oeuvre : ")
+
+	fmt.Fprint(cli.sortie, "Description de l'édition (ex : Folio 2012, traduction anglaise) : ")
+	edition := cli.LireEntree()
+
+	if err := cli.gestionnaireSeries.RattacherLivre(livreID, oeuvreID, edition); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces("Livre rattaché à l'oeuvre avec succès !")
+	return nil
+}
+
+func (cli *CLI) detacherLivreOeuvre() error {
+	cli.AfficherTitre("✂️ DÉTACHER UN LIVRE")
+
+	livreID := cli.LireEntreeEntierObligatoire("ID du livre : ")
+
+	if err := cli.gestionnaireSeries.DetacherLivre(livreID); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces("Livre détaché de son oeuvre.")
+	return nil
+}
+
+func (cli *CLI) modifierOeuvre() error {
+	cli.AfficherTitre("✏️ MODIFIER UNE OEUVRE")
+
+	id := cli.LireEntreeEntierObligatoire("ID de l'oeuvre : ")
+
+	oeuvre, _ := cli.gestionnaireSeries.TrouverOeuvreParID(id)
+	if oeuvre == nil {
+		return fmt.Errorf("aucune oeuvre trouvée avec l'ID %d", id)
+	}
+
+	cli.AfficherInfo("Laissez vide pour conserver la valeur actuelle.")
+
+	fmt.Fprintf(cli.sortie, "Nouveau titre (%s) : ", oeuvre.Titre)
+	nouveauTitre := cli.LireEntree()
+
+	fmt.Fprintf(cli.sortie, "Nouvelle série (ID actuel : %d, 0 pour hors série) : ", oeuvre.SerieID)
+	serieID := -1 // -1 = conserver la série et le tome actuels
+	numeroTome := 0
+	if saisie := cli.LireEntree(); saisie != "" {
+		valeur, err := strconv.Atoi(saisie)
+		if err != nil || valeur < 0 {
+			return fmt.Errorf("'%s' n'est pas un ID de série valide", saisie)
+		}
+		serieID = valeur
+		if serieID != 0 {
+			numeroTome = cli.LireEntreeEntierObligatoire("Numéro du tome : ")
+		}
+	}
+
+	if err := cli.gestionnaireSeries.ModifierOeuvre(id, nouveauTitre, serieID, numeroTome); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces(fmt.Sprintf("Oeuvre ID %d modifiée avec succès !", id))
+	return nil
+}
+
+func (cli *CLI) supprimerOeuvre() error {
+	cli.AfficherTitre("🗑️ SUPPRIMER UNE OEUVRE")
+
+	id := cli.LireEntreeEntierObligatoire("ID de l'oeuvre : ")
+
+	if !cli.LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir supprimer cette oeuvre ?") {
+		cli.AfficherInfo("Suppression annulée.")
+		return nil
+	}
+
+	if err := cli.gestionnaireSeries.SupprimerOeuvre(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces("Oeuvre supprimée avec succès !")
+	return nil
+}
+
+func (cli *CLI) supprimerSerie() error {
+	cli.AfficherTitre("🗑️ SUPPRIMER UNE SÉRIE")
+
+	id := cli.LireEntreeEntierObligatoire("ID de la série : ")
+
+	if !cli.LireConfirmation("\n⚠️ Êtes-vous sûr de vouloir supprimer cette série ?") {
+		cli.AfficherInfo("Suppression annulée.")
+		return nil
+	}
+
+	if err := cli.gestionnaireSeries.SupprimerSerie(id); err != nil {
+		return err
+	}
+
+	cli.AfficherSucces("Série supprimée avec succès !")
+	return nil
+}
+
+func (cli *CLI) afficherClassementOeuvres() {
+	cli.AfficherTitre("🏆 CLASSEMENT DES OEUVRES")
+
+	classement := cli.gestionnaireSeries.ClasserOeuvres()
+	if len(classement) == 0 {
+		cli.AfficherInfo("Aucun livre enregistré.")
+		return
+	}
+
+	for i, stat := range classement {
+		if i >= 20 {
+			break
+		}
+		fmt.Fprintf(cli.sortie, "%2d. %s - %d emprunt(s) (%d édition(s))\n", i+1, stat.Titre, stat.Emprunts, stat.Editions)
+	}
+
+	cli.AfficherInfo("Les emprunts de toutes les éditions et traductions d'une oeuvre sont cumulés.")
+}
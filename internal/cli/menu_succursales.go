@@ -0,0 +1,318 @@
+// ==========================================
+// internal/cli/menu_succursales.go
+// SUCCURSALES ET TRANSFERTS
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// SOUS-MENU SUCCURSALES
+// Les succursales partagent catalogue et membres. Un livre rendu ailleurs que
+// chez lui part en transit : la succursale d'arrivée le réceptionne en le
+// scannant, et il redevient empruntable (ou est mis de côté s'il est réservé).
+// ========================================
+
+func (cli *CLI) menuSuccursales() error {
+	for {
+		cli.AfficherTitre("🏢 SUCCURSALES ET TRANSFERTS")
+		if courante := cli.gestionnaireSuccursales.Courante(); courante != nil {
+			fmt.Fprintf(cli.sortie, "Ce poste travaille pour : %s\n\n", courante)
+		}
+		fmt.Fprintln(cli.sortie, "1. 📋 Lister les succursales")
+		fmt.Fprintln(cli.sortie, "2. ➕ Ajouter une succursale")
+		fmt.Fprintln(cli.sortie, "3. 📍 Changer la succursale de ce poste")
+		fmt.Fprintln(cli.sortie, "4. 🚚 Livres en transit")
+		fmt.Fprintln(cli.sortie, "5. 📥 Réceptionner des livres")
+		fmt.Fprintln(cli.sortie, "6. 🔀 Affecter un livre à une autre succursale")
+		fmt.Fprintln(cli.sortie, "7. 👤 Changer la succursale d'un membre")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 7)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.listerSuccursales()
+		case 2:
+			err = cli.ajouterSuccursale()
+		case 3:
+			err = cli.changerSuccursalePoste()
+		case 4:
+			cli.listerTransferts()
+		case 5:
+			err = cli.receptionnerTransferts()
+		case 6:
+			err = cli.reaffecterLivre()
+		case 7:
+			err = cli.changerSuccursaleMembre()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) listerSuccursales() {
+	cli.AfficherTitre("📋 SUCCURSALES")
+
+	succursales := cli.gestionnaireSuccursales.ListerSuccursales()
+	if len(succursales) == 0 {
+		cli.AfficherInfo("Aucune succursale déclarée : la bibliothèque fonctionne seule.")
+		return
+	}
+
+	// Fonds et présents en rayon de chaque succursale
+	fonds := make(map[int]int)
+	presents := make(map[int]int)
+	for _, livre := range cli.gestionnaireLivres.ListerLivres() {
+		fonds[livre.SuccursaleID]++
+		if !livre.EstEnTransit() {
+			presents[livre.EmplacementID]++
+		}
+	}
+
+	courante := cli.gestionnaireSuccursales.CouranteID()
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Code", Cle: "code"},
+		affichage.Colonne{Titre: "Nom", Cle: "nom", Min: 12},
+		affichage.Colonne{Titre: "Adresse", Cle: "adresse", Min: 10},
+		affichage.Colonne{Titre: "Fonds", Cle: "fonds", Numerique: true},
+		affichage.Colonne{Titre: "Sur place", Cle: "sur_place", Numerique: true},
+	)
+	for _, succursale := range succursales {
+		nom := succursale.Nom
+		if succursale.ID == courante {
+			nom += " 📍"
+		}
+		tableau.AjouterLigne(strconv.Itoa(succursale.ID), succursale.Code, nom, succursale.Adresse,
+			strconv.Itoa(fonds[succursale.ID]), strconv.Itoa(presents[succursale.ID]))
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d succursale(s) — 📍 ce poste", len(succursales)))
+}
+
+func (cli *CLI) ajouterSuccursale() error {
+	cli.AfficherTitre("➕ AJOUTER UNE SUCCURSALE")
+
+	premiere := !cli.gestionnaireSuccursales.EstEnReseau()
+	if premiere {
+		cli.AfficherInfo("La première succursale reçoit tous les livres et tous les membres existants.")
+	}
+
+	code := cli.LireEntreeObligatoire("Code (2 à 8 lettres ou chiffres, ex : CTR) : ")
+	nom := cli.LireEntreeObligatoire("Nom : ")
+	fmt.Fprint(cli.sortie, "Adresse : ")
+	adresse := cli.LireEntree()
+	latitude, err := cli.lireCoordonnee("Latitude (ex : 48.8566, vide = 0) : ")
+	if err != nil {
+		return err
+	}
+	longitude, err := cli.lireCoordonnee("Longitude (ex : 2.3522, vide = 0) : ")
+	if err != nil {
+		return err
+	}
+
+	succursale, err := cli.gestionnaireEmprunts.AjouterSuccursale(code, nom, adresse, latitude, longitude)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Succursale %s ajoutée (ID : %d).", succursale, succursale.ID))
+	if premiere {
+		cli.AfficherInfo("Ce poste travaille désormais pour cette succursale.")
+	}
+	return nil
+}
+
+// lireCoordonnee accepte la virgule comme séparateur décimal
+func (cli *CLI) lireCoordonnee(invite string) (float64, error) {
+	fmt.Fprint(cli.sortie, invite)
+	saisie := strings.ReplaceAll(cli.LireEntree(), ",", ".")
+	if saisie == "" {
+		return 0, nil
+	}
+	valeur, err := strconv.ParseFloat(saisie, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' n'est pas une coordonnée valide", saisie)
+	}
+	return valeur, nil
+}
+
+// choisirSuccursale affiche les succursales puis lit un code
+func (cli *CLI) choisirSuccursale(invite string) (*models.Succursale, error) {
+	if !cli.gestionnaireSuccursales.EstEnReseau() {
+		return nil, fmt.Errorf("aucune succursale n'est déclarée")
+	}
+	cli.listerSuccursales()
+
+	code := cli.LireEntreeObligatoire(invite)
+	succursale := cli.gestionnaireSuccursales.TrouverSuccursaleParCode(code)
+	if succursale == nil {
+		return nil, fmt.Errorf("aucune succursale ne porte le code '%s'", models.NormaliserCodeSuccursale(code))
+	}
+	return succursale, nil
+}
+
+func (cli *CLI) changerSuccursalePoste() error {
+	succursale, err := cli.choisirSuccursale("\nCode de la succursale de ce poste : ")
+	if err != nil {
+		return err
+	}
+	if err := cli.gestionnaireSuccursales.ChoisirSuccursale(succursale.Code); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Ce poste travaille désormais pour %s.", succursale))
+	cli.AfficherInfo("Pour la garder au prochain démarrage, réglez « succursale » dans la configuration ou lancez le programme avec -succursale.")
+	return nil
+}
+
+func (cli *CLI) listerTransferts() {
+	cli.AfficherTitre("🚚 LIVRES EN TRANSIT")
+
+	livres := cli.gestionnaireLivres.ListerLivresEnTransit()
+	if len(livres) == 0 {
+		cli.AfficherInfo("Aucun livre en transit.")
+		return
+	}
+	cli.afficherTableauTransferts(livres)
+}
+
+func (cli *CLI) afficherTableauTransferts(livres []models.Livre) {
+	succursales := cli.gestionnaireSuccursales
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "ID", Cle: "id", Numerique: true},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 12},
+		affichage.Colonne{Titre: "De", Cle: "de"},
+		affichage.Colonne{Titre: "Vers", Cle: "vers"},
+		affichage.Colonne{Titre: "Motif", Cle: "motif"},
+		affichage.Colonne{Titre: "Départ", Cle: "date_depart"},
+	)
+	for _, livre := range livres {
+		transfert := livre.Transfert
+		tableau.AjouterLigne(strconv.Itoa(livre.ID), livre.Titre, succursales.NomSuccursale(transfert.De),
+			succursales.NomSuccursale(transfert.Vers), models.LibelleMotifTransfert(transfert.Motif),
+			transfert.DateDepart.Format("02/01/2006"))
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d livre(s) en transit", len(livres)))
+}
+
+// receptionnerTransferts lit les codes des livres arrivés jusqu'à une saisie vide
+func (cli *CLI) receptionnerTransferts() error {
+	cli.AfficherTitre("📥 RÉCEPTIONNER DES LIVRES")
+
+	courante := cli.gestionnaireSuccursales.Courante()
+	if courante == nil {
+		return fmt.Errorf("aucune succursale n'est déclarée")
+	}
+
+	var attendus []models.Livre
+	for _, livre := range cli.gestionnaireLivres.ListerLivresEnTransit() {
+		if livre.Transfert.Vers == courante.ID {
+			attendus = append(attendus, livre)
+		}
+	}
+	if len(attendus) == 0 {
+		cli.AfficherInfo(fmt.Sprintf("Aucun livre n'est attendu à %s.", courante.Nom))
+		return nil
+	}
+	cli.afficherTableauTransferts(attendus)
+	fmt.Fprintln(cli.sortie, "\nScannez les livres arrivés, validez une ligne vide pour terminer.")
+
+	recus := 0
+	for {
+		fmt.Fprint(cli.sortie, "📷 Livre : ")
+		code := cli.LireEntree()
+		if code == "" {
+			break
+		}
+
+		livre, reservation, err := cli.gestionnaireEmprunts.ReceptionnerParCode(code)
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+			continue
+		}
+		recus++
+
+		switch {
+		case reservation != nil && reservation.Statut == models.RESERVATION_PRETE:
+			cli.AfficherSucces(fmt.Sprintf("« %s » reçu : à mettre de côté pour %s jusqu'au %s.",
+				livre.Titre, reservation.NomMembre, reservation.DateLimite.Format("02/01/2006")))
+		case livre.EstEnTransit():
+			cli.AfficherSucces(fmt.Sprintf("« %s » reçu.", livre.Titre))
+			cli.signalerTransfert(livre.ID)
+		default:
+			cli.AfficherSucces(fmt.Sprintf("« %s » reçu : à ranger en rayon.", livre.Titre))
+		}
+	}
+
+	cli.AfficherInfo(fmt.Sprintf("%d livre(s) réceptionné(s).", recus))
+	return nil
+}
+
+// signalerTransfert indique au personnel qu'un livre doit être expédié
+func (cli *CLI) signalerTransfert(livreID int) {
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil || !livre.EstEnTransit() {
+		return
+	}
+	transfert := livre.Transfert
+	cli.AfficherInfo(fmt.Sprintf("« %s » part de %s vers %s (%s).", livre.Titre,
+		cli.gestionnaireSuccursales.NomSuccursale(transfert.De), cli.gestionnaireSuccursales.NomSuccursale(transfert.Vers),
+		strings.ToLower(models.LibelleMotifTransfert(transfert.Motif))))
+}
+
+func (cli *CLI) reaffecterLivre() error {
+	cli.AfficherTitre("🔀 AFFECTER UN LIVRE À UNE AUTRE SUCCURSALE")
+
+	livre, _ := cli.gestionnaireLivres.TrouverLivreParCode(cli.LireEntreeObligatoire("ID ou code-barres du livre : "))
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à cette saisie")
+	}
+	fmt.Fprintf(cli.sortie, "« %s » fait partie du fonds de %s.\n", livre.Titre, cli.gestionnaireSuccursales.NomSuccursale(livre.SuccursaleID))
+
+	succursale, err := cli.choisirSuccursale("\nCode de la nouvelle succursale : ")
+	if err != nil {
+		return err
+	}
+	livreID := livre.ID
+	if err := cli.gestionnaireEmprunts.ReaffecterLivre(livreID, succursale.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("« %s » fait désormais partie du fonds de %s.", livre.Titre, succursale.Nom))
+	cli.signalerTransfert(livreID)
+	return nil
+}
+
+func (cli *CLI) changerSuccursaleMembre() error {
+	cli.AfficherTitre("👤 CHANGER LA SUCCURSALE D'UN MEMBRE")
+
+	membre, _ := cli.gestionnaireMembres.TrouverMembreParCarte(cli.LireEntreeObligatoire("ID ou carte du membre : "))
+	if membre == nil {
+		return fmt.Errorf("aucun membre ne correspond à cette carte")
+	}
+	fmt.Fprintf(cli.sortie, "%s retire ses réservations à %s.\n", membre.Nom, cli.gestionnaireSuccursales.NomSuccursale(membre.SuccursaleID))
+
+	succursale, err := cli.choisirSuccursale("\nCode de la nouvelle succursale : ")
+	if err != nil {
+		return err
+	}
+	if err := cli.gestionnaireMembres.ChangerSuccursale(membre.ID, succursale.ID); err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("%s est désormais rattaché(e) à %s. Les prochaines réservations y seront retirées.", membre.Nom, succursale.Nom))
+	return nil
+}
@@ -0,0 +1,218 @@
+// ==========================================
+// internal/cli/menu_synchro.go
+// SYNCHRONISATION ENTRE POSTES
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/synchro"
+)
+
+// ========================================
+// SOUS-MENU SYNCHRONISATION
+// Chaque poste prête hors ligne et échange ses événements avec ses pairs dès
+// qu'il les joint. Un livre prêté sur deux postes pendant une coupure reste au
+// premier emprunteur : le second emprunt est signalé ici pour être régularisé.
+// ========================================
+
+func (cli *CLI) menuSynchro() error {
+	for {
+		cli.AfficherTitre("🔄 SYNCHRONISATION ENTRE POSTES")
+		fmt.Fprintf(cli.sortie, "Ce poste : %s\n", cli.gestionnaireSynchro.Instance())
+		if conflits := cli.gestionnaireSynchro.ConflitsATraiter(); conflits > 0 {
+			fmt.Fprintf(cli.sortie, "⚠️  %d conflit(s) à régulariser\n", conflits)
+		}
+		fmt.Fprintln(cli.sortie)
+		fmt.Fprintln(cli.sortie, "1. 📋 État de la synchronisation")
+		fmt.Fprintln(cli.sortie, "2. 🔄 Synchroniser maintenant")
+		fmt.Fprintln(cli.sortie, "3. ➕ Ajouter un poste pair")
+		fmt.Fprintln(cli.sortie, "4. ⚠️  Conflits")
+		fmt.Fprintln(cli.sortie, "5. ✅ Marquer un conflit comme régularisé")
+		fmt.Fprintln(cli.sortie, "0. ⬅️  Retour au menu principal")
+		cli.AfficherSeparateur("-", 50)
+
+		choix := cli.LireEntreeEntierAvecLimites("Votre choix : ", 0, 5)
+
+		var err error
+		switch choix {
+		case 1:
+			cli.afficherEtatSynchro()
+		case 2:
+			err = cli.synchroniserMaintenant()
+		case 3:
+			err = cli.ajouterPair()
+		case 4:
+			cli.listerConflits()
+		case 5:
+			err = cli.regulariserConflit()
+		case 0:
+			return nil
+		}
+
+		if err != nil {
+			cli.AfficherErreur(err.Error())
+		}
+
+		cli.AttendreEntree("")
+	}
+}
+
+func (cli *CLI) afficherEtatSynchro() {
+	cli.AfficherTitre("📋 ÉTAT DE LA SYNCHRONISATION")
+
+	fmt.Fprintf(cli.sortie, "Poste : %s\n", cli.gestionnaireSynchro.Instance())
+	fmt.Fprintf(cli.sortie, "Événements dans le journal : %d\n", cli.gestionnaireSynchro.DernierEvenement())
+	if adresse := cli.config.Synchro.Adresse; adresse != "" {
+		fmt.Fprintf(cli.sortie, "Service ouvert aux autres postes : %s\n", adresse)
+	} else {
+		fmt.Fprintln(cli.sortie, "Service fermé : les autres postes ne peuvent pas joindre celui-ci")
+	}
+	if enAttente := cli.gestionnaireSynchro.EvenementsEnAttente(); enAttente > 0 {
+		fmt.Fprintf(cli.sortie, "⏳ %d événement(s) reçu(s) en attente d'un livre ou d'un membre inconnu ici\n", enAttente)
+	}
+	fmt.Fprintf(cli.sortie, "Conflits à régulariser : %d\n\n", cli.gestionnaireSynchro.ConflitsATraiter())
+
+	pairs := cli.gestionnaireSynchro.ListerPairs()
+	if len(pairs) == 0 {
+		cli.AfficherInfo("Aucun poste pair déclaré : ce poste travaille seul.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Adresse", Cle: "adresse", Min: 16},
+		affichage.Colonne{Titre: "Dernière synchro", Cle: "derniere_synchro"},
+		affichage.Colonne{Titre: "Reçus", Cle: "recus", Numerique: true},
+		affichage.Colonne{Titre: "Envoyés", Cle: "envoyes", Numerique: true},
+		affichage.Colonne{Titre: "État", Cle: "etat", Min: 10},
+	)
+	for _, pair := range pairs {
+		derniere := "jamais"
+		if !pair.DerniereSynchro.IsZero() {
+			derniere = pair.DerniereSynchro.Format("02/01/2006 15:04")
+		}
+		etat := "✅ à jour"
+		switch {
+		case pair.DerniereErreur != "":
+			etat = "❌ " + pair.DerniereErreur
+		case !pair.EstJoignable():
+			etat = "⏳ jamais joint"
+		}
+		tableau.AjouterLigne(pair.URL, derniere, strconv.Itoa(pair.Recu), strconv.Itoa(pair.Envoye), etat)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d poste(s) pair(s) — Reçus et Envoyés : derniers événements échangés", len(pairs)))
+}
+
+// synchroniserMaintenant échange les événements avec chaque pair. Le verrou
+// partagé est relâché pendant les appels réseau, comme pendant une saisie.
+func (cli *CLI) synchroniserMaintenant() error {
+	cli.AfficherTitre("🔄 SYNCHRONISER MAINTENANT")
+
+	pairs := cli.gestionnaireSynchro.ListerPairs()
+	if len(pairs) == 0 {
+		return fmt.Errorf("aucun poste pair déclaré")
+	}
+	if cli.config.Synchro.Cle == "" {
+		return fmt.Errorf("aucune clé de synchronisation configurée (synchro.cle ou LIBRAIRIE_CLE_SYNCHRO)")
+	}
+
+	for _, pair := range pairs {
+		fmt.Fprintf(cli.sortie, "%s... ", pair.URL)
+		reprendre := cli.attendre()
+		resultat, err := synchro.Synchroniser(cli.gestionnaireSynchro, pair.URL, cli.config.Synchro.Cle, cli.verrou)
+		reprendre()
+		if err != nil {
+			fmt.Fprintln(cli.sortie)
+			cli.AfficherErreur(err.Error())
+			continue
+		}
+
+		fmt.Fprintf(cli.sortie, "%d événement(s) reçu(s), %d envoyé(s)\n", resultat.Recus.Integres, resultat.Envoyes.Integres)
+		if resultat.Recus.Livres+resultat.Recus.Membres > 0 {
+			cli.AfficherInfo(fmt.Sprintf("%d livre(s) et %d membre(s) ajoutés depuis ce poste.", resultat.Recus.Livres, resultat.Recus.Membres))
+		}
+		if resultat.Recus.EnAttente > 0 {
+			cli.AfficherInfo(fmt.Sprintf("%d événement(s) en attente d'un livre ou d'un membre inconnu ici.", resultat.Recus.EnAttente))
+		}
+		for _, conflit := range resultat.Recus.Conflits {
+			cli.AfficherAvertissement(conflit.String())
+		}
+		if len(resultat.Recus.CodesEnDouble) > 0 {
+			cli.AfficherAvertissement(fmt.Sprintf("Codes reçus déjà portés ici (étiquette ou carte à refaire pour l'un des deux) : %s",
+				strings.Join(resultat.Recus.CodesEnDouble, ", ")))
+		}
+	}
+	return nil
+}
+
+func (cli *CLI) ajouterPair() error {
+	cli.AfficherTitre("➕ AJOUTER UN POSTE PAIR")
+	cli.AfficherInfo("Adresse du service de synchronisation de l'autre poste (ou du poste central).")
+
+	url := cli.LireEntreeObligatoire("Adresse (ex : http://comptoir-2:8090) : ")
+	pair, err := cli.gestionnaireSynchro.AjouterPair(url)
+	if err != nil {
+		return err
+	}
+	cli.AfficherSucces(fmt.Sprintf("Poste %s ajouté : il sera joint à la prochaine synchronisation.", pair.URL))
+	return nil
+}
+
+func (cli *CLI) listerConflits() {
+	cli.AfficherTitre("⚠️  CONFLITS DE SYNCHRONISATION")
+
+	conflits := cli.gestionnaireSynchro.ListerConflits(true)
+	if len(conflits) == 0 {
+		cli.AfficherInfo("Aucun livre n'a été prêté sur deux postes à la fois.")
+		return
+	}
+
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Livre", Cle: "livre", Min: 12},
+		affichage.Colonne{Titre: "Retenu", Cle: "retenu", Min: 10},
+		affichage.Colonne{Titre: "Le", Cle: "date_retenue"},
+		affichage.Colonne{Titre: "Écarté", Cle: "ecarte", Min: 10},
+		affichage.Colonne{Titre: "Le", Cle: "date_ecartee"},
+		affichage.Colonne{Titre: "État", Cle: "etat"},
+	)
+	for _, conflit := range conflits {
+		etat := "⚠️  à régulariser"
+		if conflit.EstTraite() {
+			etat = "✅ " + conflit.Traite.Format("02/01/2006")
+		}
+		tableau.AjouterLigne(conflit.TitreLivre, conflit.MembreRetenu, conflit.DateRetenue.Format("02/01/2006 15:04"),
+			conflit.MembreEcarte, conflit.DateEcartee.Format("02/01/2006 15:04"), etat)
+	}
+	cli.afficherResultats(tableau, fmt.Sprintf("Total : %d conflit(s) — l'emprunt le plus ancien est retenu sur tous les postes", len(conflits)))
+}
+
+func (cli *CLI) regulariserConflit() error {
+	cli.AfficherTitre("✅ RÉGULARISER UN CONFLIT")
+
+	conflits := cli.gestionnaireSynchro.ListerConflits(false)
+	if len(conflits) == 0 {
+		cli.AfficherInfo("Aucun conflit à régulariser.")
+		return nil
+	}
+
+	for i, conflit := range conflits {
+		fmt.Fprintf(cli.sortie, "%d. %s\n", i+1, conflit)
+	}
+	fmt.Fprintln(cli.sortie)
+	cli.AfficherInfo("Vérifiez qui a réellement le livre. Si c'est le membre écarté, enregistrez le retour puis un nouvel emprunt à son nom.")
+
+	choix := cli.LireEntreeEntierAvecLimites("Conflit régularisé (0 = annuler) : ", 0, len(conflits))
+	if choix == 0 {
+		return nil
+	}
+	if err := cli.gestionnaireSynchro.MarquerConflitTraite(conflits[choix-1].UID); err != nil {
+		return err
+	}
+	cli.AfficherSucces("Conflit marqué comme régularisé.")
+	return nil
+}
@@ -0,0 +1,148 @@
+// ==========================================
+// internal/cli/recu.go
+// REÇUS DU LIBRE-SERVICE (TEXTE ET PDF)
+// ==========================================
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/pdf"
+)
+
+// LARGEUR_RECU est la largeur d'un ticket en caractères (imprimante de caisse 80 mm)
+const LARGEUR_RECU = 40
+
+// operationRecu est une ligne du reçu : l'opération, le titre et un détail facultatif
+type operationRecu struct {
+	libelle string
+	titre   string
+	detail  string
+}
+
+// recu accumule les opérations d'une session au libre-service
+type recu struct {
+	date       time.Time
+	nomMembre  string
+	carte      string
+	operations []operationRecu
+	enCours    []models.Emprunt // emprunts en cours à la fin de la session
+}
+
+func nouveauRecu(membre *models.Membre) *recu {
+	return &recu{
+		date:      time.Now(),
+		nomMembre: membre.Nom,
+		carte:     membre.NumeroCarte,
+	}
+}
+
+func (r *recu) ajouter(libelle, titre, detail string) {
+	r.operations = append(r.operations, operationRecu{libelle: libelle, titre: titre, detail: detail})
+}
+
+// lignes compose le ticket, une ligne de LARGEUR_RECU caractères au plus par élément
+func (r *recu) lignes() []string {
+	double := strings.Repeat("=", LARGEUR_RECU)
+	simple := strings.Repeat("-", LARGEUR_RECU)
+
+	lignes := []string{
+		double,
+		centrer("BIBLIOTHÈQUE - LIBRE-SERVICE", LARGEUR_RECU),
+		double,
+		"Date   : " + r.date.Format("02/01/2006 15:04"),
+		affichage.Tronquer("Membre : "+r.nomMembre, LARGEUR_RECU),
+		"Carte  : " + r.carte,
+		simple,
+	}
+
+	for _, operation := range r.operations {
+		lignes = append(lignes, affichage.Tronquer(affichage.Ajuster(operation.libelle, 9)+" "+operation.titre, LARGEUR_RECU))
+		if operation.detail != "" {
+			lignes = append(lignes, affichage.Tronquer(strings.Repeat(" ", 10)+operation.detail, LARGEUR_RECU))
+		}
+	}
+
+	lignes = append(lignes, simple, fmt.Sprintf("Emprunts en cours : %d", len(r.enCours)))
+	for _, emprunt := range r.enCours {
+		date := emprunt.DateRetourPrevu.Format("02/01/2006")
+		lignes = append(lignes, affichage.Ajuster(" - "+emprunt.TitreLivre, LARGEUR_RECU-len(date)-1)+" "+date)
+	}
+
+	return append(lignes, double, centrer("Merci de votre visite !", LARGEUR_RECU))
+}
+
+func (r *recu) ecrireTexte(w io.Writer) error {
+	for _, ligne := range r.lignes() {
+		if _, err := fmt.Fprintln(w, ligne); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ecrirePDF met le ticket sur une page de 80 mm de large, aussi haute que nécessaire
+func (r *recu) ecrirePDF(w io.Writer) error {
+	const (
+		taille     = 8.0
+		interligne = 10.0
+		marge      = 4 * pdf.MM
+	)
+
+	lignes := r.lignes()
+	largeur := 80 * pdf.MM
+	hauteur := 2*marge + float64(len(lignes))*interligne
+
+	document := pdf.NouveauDocument("Reçu " + r.carte + " " + r.date.Format("02/01/2006 15:04"))
+	page := document.NouvellePage(largeur, hauteur)
+	for i, ligne := range lignes {
+		page.Texte(marge, hauteur-marge-float64(i+1)*interligne+2, pdf.POLICE_FIXE, taille, ligne)
+	}
+	return document.Ecrire(w)
+}
+
+// enregistrer écrit le reçu en texte (et en PDF si demandé) et retourne le chemin du texte
+func (r *recu) enregistrer(dossier string, avecPDF bool) (string, error) {
+	if err := os.MkdirAll(dossier, 0755); err != nil {
+		return "", fmt.Errorf("impossible de créer le dossier %s : %v", dossier, err)
+	}
+
+	base := filepath.Join(dossier, fmt.Sprintf("recu-%s-%s", r.date.Format("20060102-150405"), r.carte))
+	if err := ecrireFichier(base+".txt", r.ecrireTexte); err != nil {
+		return "", err
+	}
+	if avecPDF {
+		if err := ecrireFichier(base+".pdf", r.ecrirePDF); err != nil {
+			return "", err
+		}
+	}
+	return base + ".txt", nil
+}
+
+func ecrireFichier(chemin string, ecrire func(io.Writer) error) error {
+	fichier, err := os.Create(chemin)
+	if err != nil {
+		return fmt.Errorf("impossible de créer %s : %v", chemin, err)
+	}
+	if err := ecrire(fichier); err != nil {
+		fichier.Close()
+		return fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+	}
+	return fichier.Close()
+}
+
+func centrer(texte string, largeur int) string {
+	marge := (largeur - affichage.Largeur(texte)) / 2
+	if marge <= 0 {
+		return texte
+	}
+	return strings.Repeat(" ", marge) + texte
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// TESTS PAR TRANSCRIPTION
+// Chaque fichier testdata/sessions/<nom>.txt contient les saisies d'une session ;
+// la sortie produite est comparée à testdata/sessions/<nom>.golden.
+// Pour régénérer les fichiers de référence : go test ./internal/cli -maj
+// ========================================
+
+var majReferences = flag.Bool("maj", false, "réécrire les transcriptions de référence")
+
+// Les dates (et les horodatages des noms de fichiers) dépendent du jour d'exécution :
+// leurs chiffres sont masqués avant comparaison, ce qui conserve l'alignement des tableaux
+var (
+	motifDate    = regexp.MustCompile(`\d{2}/\d{2}/\d{4}( \d{2}:\d{2}(:\d{2})?)?|\d{8}-\d{6}`)
+	motifChiffre = regexp.MustCompile(`\d`)
+	motifUID     = regexp.MustCompile(`\b[0-9A-HJKMNP-TV-Z]{26}\b`) // identifiant du poste, tiré au hasard
+)
+
+// nouvelleCLIDeTest assemble l'application comme main.go, sur un dossier de données vide
+func nouvelleCLIDeTest(t *testing.T, dossier string) *CLI {
+	t.Helper()
+
+	cfg := config.Defaut()
+	cfg.Donnees.Dossier = dossier
+	cfg.Affichage.Largeur = 100
+	cfg.Etiquettes.Dossier = filepath.Join(dossier, "etiquettes")
+	cfg.Kiosque.DossierRecus = filepath.Join(dossier, "recus")
+	cfg.Rapports.Dossier = filepath.Join(dossier, "rapports")
+
+	validateur := validators.NouveauValidateur(cfg.Validation.AnneePublicationMin)
+	sq, err := storage.NewSequences(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Sequences), storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsu := services.NouveauGestionnaireSuccursales(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Succursales), storage.SCHEMA_SUCCURSALES), sq)
+	gg := services.NouveauGestionnaireGenres(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Genres), storage.SCHEMA_GENRES), sq, cfg.Validation.Genres)
+	gc := services.NouveauGestionnaireContributeurs(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Contributeurs), storage.SCHEMA_CONTRIBUTEURS), sq)
+	gl := services.NouveauGestionnaireLivres(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Livres), storage.SCHEMA_LIVRES), sq, validateur, gg, gc, gsu)
+	gm := services.NouveauGestionnaireMembres(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Membres), storage.SCHEMA_MEMBRES), sq, cfg.Emprunts.LimiteSimultanes, gsu)
+	gr := services.NouveauGestionnaireReservations(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Reservations), storage.SCHEMA_RESERVATIONS), sq, gl, gm, cfg.Emprunts.DelaiRetraitJours)
+	ge, err := services.NouveauGestionnaireEmprunts(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Emprunts), storage.SCHEMA_EMPRUNTS),
+		storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal)), storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Instantanes), storage.SCHEMA_INSTANTANES),
+		sq, gl, gm, gr, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := services.NouveauGestionnaireSeries(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Series), storage.SCHEMA_SERIES), sq, gl)
+	grc := services.NouveauGestionnaireRecherches(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Recherches), storage.SCHEMA_RECHERCHES))
+	ga := services.NouveauGestionnaireAcquisitions(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Acquisitions), storage.SCHEMA_ACQUISITIONS), sq, gl)
+	gi := services.NouveauGestionnaireInventaires(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Inventaires), storage.SCHEMA_INVENTAIRES), sq, gl, gr)
+	gsy := services.NouveauGestionnaireSynchro(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Synchro), storage.SCHEMA_SYNCHRO), ge)
+	gp := services.NouveauGestionnairePEB(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.PEB), storage.SCHEMA_PEB), sq, ge)
+
+	return NewCLI(cfg, gl, gm, ge, gr, gg, gc, gs, grc, ga, gi, gsu, gsy, gp)
+}
+
+func TestTranscriptions(t *testing.T) {
+	sessions, err := filepath.Glob(filepath.Join("testdata", "sessions", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) == 0 {
+		t.Fatal("aucune session trouvée dans testdata/sessions")
+	}
+
+	for _, session := range sessions {
+		nom := strings.TrimSuffix(filepath.Base(session), ".txt")
+		t.Run(nom, func(t *testing.T) {
+			entree, err := os.Open(session)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer entree.Close()
+
+			dossier := t.TempDir()
+			var sortie bytes.Buffer
+			cli := nouvelleCLIDeTest(t, dossier)
+			cli.UtiliserConsole(NouvelleConsole(entree, &sortie, true))
+
+			if err := cli.Run(); err != nil {
+				t.Fatalf("la session s'est terminée en erreur : %v", err)
+			}
+
+			verifierTranscription(t, session, dossier, sortie.String())
+		})
+	}
+}
+
+// TestTranscriptionsKiosque rejoue les sessions du libre-service (testdata/kiosque)
+// sur un fonds de deux livres et deux membres : Zoé (PIN 1234) et Marc (PIN 5678)
+func TestTranscriptionsKiosque(t *testing.T) {
+	sessions, err := filepath.Glob(filepath.Join("testdata", "kiosque", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) == 0 {
+		t.Fatal("aucune session trouvée dans testdata/kiosque")
+	}
+
+	for _, session := range sessions {
+		nom := strings.TrimSuffix(filepath.Base(session), ".txt")
+		t.Run(nom, func(t *testing.T) {
+			entree, err := os.Open(session)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer entree.Close()
+
+			dossier := t.TempDir()
+			cli := nouvelleCLIDeTest(t, dossier)
+			preparerFonds(t, cli)
+
+			var sortie bytes.Buffer
+			kiosque := NouveauKiosque(cli.config, cli.gestionnaireLivres, cli.gestionnaireMembres, cli.gestionnaireEmprunts)
+			kiosque.UtiliserConsole(NouvelleConsole(entree, &sortie, true))
+
+			if err := kiosque.Run(); err != nil {
+				t.Fatalf("la session s'est terminée en erreur : %v", err)
+			}
+
+			verifierTranscription(t, session, dossier, sortie.String())
+		})
+	}
+}
+
+func preparerFonds(t *testing.T, cli *CLI) {
+	t.Helper()
+
+	for _, livre := range [][]string{
+		{"Le Petit Prince", "Antoine de Saint-Exupéry", "9780306406157", "Roman", "06/04/1943"},
+		{"L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"},
+	} {
+		if err := cli.gestionnaireLivres.AjouterLivre(livre[0], livre[1], livre[2], livre[3], livre[4]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, membre := range [][]string{
+		{"Zoé Dupont", "zoe@example.com", "0612345678", "1234"},
+		{"Marc Durand", "marc@example.com", "0698765432", "5678"},
+	} {
+		if err := cli.gestionnaireMembres.AjouterMembre(membre[0], membre[1], membre[2]); err != nil {
+			t.Fatal(err)
+		}
+		if err := cli.gestionnaireMembres.DefinirPIN(i+1, membre[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestTranscriptionsIntegrite rejoue les sessions de testdata/integrite sur une
+// copie des données abîmées de testdata/integrite/donnees (IDs en double,
+// emprunts orphelins, compteurs faux, titres recopiés périmés)
+func TestTranscriptionsIntegrite(t *testing.T) {
+	sessions, err := filepath.Glob(filepath.Join("testdata", "integrite", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) == 0 {
+		t.Fatal("aucune session trouvée dans testdata/integrite")
+	}
+
+	for _, session := range sessions {
+		nom := strings.TrimSuffix(filepath.Base(session), ".txt")
+		t.Run(nom, func(t *testing.T) {
+			entree, err := os.Open(session)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer entree.Close()
+
+			dossier := t.TempDir()
+			copierDonnees(t, filepath.Join("testdata", "integrite", "donnees"), dossier)
+
+			var sortie bytes.Buffer
+			cli := nouvelleCLIDeTest(t, dossier)
+			cli.UtiliserConsole(NouvelleConsole(entree, &sortie, true))
+
+			if err := cli.Run(); err != nil {
+				t.Fatalf("la session s'est terminée en erreur : %v", err)
+			}
+
+			verifierTranscription(t, session, dossier, sortie.String())
+		})
+	}
+}
+
+// copierDonnees recopie les fichiers JSON d'un jeu de données dans le dossier de la session
+func copierDonnees(t *testing.T, source, dossier string) {
+	t.Helper()
+
+	fichiers, err := filepath.Glob(filepath.Join(source, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fichier := range fichiers {
+		contenu, err := os.ReadFile(fichier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dossier, filepath.Base(fichier)), contenu, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// verifierTranscription compare la sortie d'une session à sa référence (ou la réécrit avec -maj)
+func verifierTranscription(t *testing.T, session, dossier, sortie string) {
+	t.Helper()
+
+	obtenu := strings.ReplaceAll(sortie, dossier, "<donnees>")
+	obtenu = motifUID.ReplaceAllString(obtenu, "<uid>")
+	obtenu = motifDate.ReplaceAllStringFunc(obtenu, func(date string) string {
+		return motifChiffre.ReplaceAllString(date, "#")
+	})
+
+	reference := strings.TrimSuffix(session, ".txt") + ".golden"
+	if *majReferences {
+		if err := os.WriteFile(reference, []byte(obtenu), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	attendu, err := os.ReadFile(reference)
+	if err != nil {
+		t.Fatalf("transcription de référence absente (lancer avec -maj) : %v", err)
+	}
+	if obtenu != string(attendu) {
+		t.Errorf("la sortie diffère de %s :\n%s", reference, premiereDifference(string(attendu), obtenu))
+	}
+}
+
+// premiereDifference montre la première ligne divergente avec un peu de contexte
+func premiereDifference(attendu, obtenu string) string {
+	lignesAttendues := strings.Split(attendu, "\n")
+	lignesObtenues := strings.Split(obtenu, "\n")
+
+	for i := 0; i < len(lignesAttendues) || i < len(lignesObtenues); i++ {
+		var a, o string
+		if i < len(lignesAttendues) {
+			a = lignesAttendues[i]
+		}
+		if i < len(lignesObtenues) {
+			o = lignesObtenues[i]
+		}
+		if a != o {
+			return "ligne " + strconv.Itoa(i+1) + " :\n  attendu : " + a + "\n  obtenu  : " + o
+		}
+	}
+	return ""
+}
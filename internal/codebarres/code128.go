@@ -0,0 +1,60 @@
+package codebarres
+
+import "fmt"
+
+// ========================================
+// CODE 128 (JEU B)
+// Le jeu B couvre tous les caractères ASCII imprimables, ce qui suffit
+// pour les codes de la librairie (LIV000123, MEM000045, ISBN...)
+// ========================================
+
+const (
+	code128DepartB = 104
+	code128Arret   = 106
+)
+
+// motifsCode128 donne, pour chaque valeur, les largeurs alternées barre/espace en modules
+var motifsCode128 = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code128 encode le texte et retourne la suite de modules (vrai = barre), sans marge
+func Code128(texte string) ([]bool, error) {
+	if texte == "" {
+		return nil, fmt.Errorf("impossible d'encoder un code vide")
+	}
+
+	valeurs := []int{code128DepartB}
+	somme := code128DepartB
+	for i, r := range texte {
+		if r < 32 || r > 126 {
+			return nil, fmt.Errorf("le caractère '%c' ne peut pas être encodé en Code 128", r)
+		}
+		valeur := int(r - 32)
+		valeurs = append(valeurs, valeur)
+		somme += (i + 1) * valeur
+	}
+	valeurs = append(valeurs, somme%103, code128Arret)
+
+	var modules []bool
+	for _, valeur := range valeurs {
+		barre := true
+		for _, largeur := range motifsCode128[valeur] {
+			for n := 0; n < int(largeur-'0'); n++ {
+				modules = append(modules, barre)
+			}
+			barre = !barre
+		}
+	}
+	return modules, nil
+}
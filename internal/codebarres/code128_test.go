@@ -0,0 +1,104 @@
+package codebarres
+
+import (
+	"strings"
+	"testing"
+)
+
+// largeursCode128 découpe les modules en largeurs alternées barre/espace et
+// les regroupe en symboles de six largeurs, sept pour le motif d'arrêt
+func largeursCode128(t *testing.T, modules []bool) []string {
+	t.Helper()
+	var largeurs []byte
+	for i := 0; i < len(modules); {
+		debut := i
+		for i < len(modules) && modules[i] == modules[debut] {
+			i++
+		}
+		largeurs = append(largeurs, byte('0'+i-debut))
+	}
+	if len(largeurs) < 13 || (len(largeurs)-7)%6 != 0 {
+		t.Fatalf("%d largeurs, attendu des symboles de 6 puis l'arrêt", len(largeurs))
+	}
+
+	var symboles []string
+	for i := 0; i+7 < len(largeurs); i += 6 {
+		symboles = append(symboles, string(largeurs[i:i+6]))
+	}
+	return append(symboles, string(largeurs[len(largeurs)-7:]))
+}
+
+// decoderCode128 relit le texte d'un symbole en jeu B et vérifie sa clé de contrôle
+func decoderCode128(t *testing.T, modules []bool) (string, int) {
+	t.Helper()
+	valeurs := make(map[string]int, len(motifsCode128))
+	for valeur, motif := range motifsCode128 {
+		valeurs[motif] = valeur
+	}
+
+	symboles := largeursCode128(t, modules)
+	if symboles[0] != "211214" {
+		t.Fatalf("départ %s, attendu 211214 (Start B)", symboles[0])
+	}
+	if arret := symboles[len(symboles)-1]; arret != "2331112" {
+		t.Fatalf("arrêt %s, attendu 2331112", arret)
+	}
+
+	var texte strings.Builder
+	somme := 104
+	for i, motif := range symboles[1 : len(symboles)-2] {
+		valeur, ok := valeurs[motif]
+		if !ok {
+			t.Fatalf("motif %s inconnu", motif)
+		}
+		texte.WriteByte(byte(valeur + 32))
+		somme += (i + 1) * valeur
+	}
+	cle, ok := valeurs[symboles[len(symboles)-2]]
+	if !ok || cle != somme%103 {
+		t.Fatalf("clé de contrôle %d, attendu %d", cle, somme%103)
+	}
+	return texte.String(), cle
+}
+
+func TestCode128(t *testing.T) {
+	cas := []struct {
+		texte string
+		cle   int // calculée à la main : (104 + Σ position × valeur) mod 103
+	}{
+		// 104 + 44 + 2×41 + 3×54 + 4×16 + 5×16 + 6×16 + 7×16 + 8×16 + 9×17 = 1025
+		{"LIV000001", 98},
+		// 104 + 55 + 2×73 + 3×75 + 4×73 + 5×80 + 6×69 + 7×68 + 8×73 + 9×65 = 3281
+		{"Wikipedia", 88},
+		// 104 + 33 = 137
+		{"A", 34},
+		// 104 + 0 : l'espace vaut 0
+		{" ", 1},
+	}
+	for _, c := range cas {
+		modules, err := Code128(c.texte)
+		if err != nil {
+			t.Errorf("%q : %v", c.texte, err)
+			continue
+		}
+		// Départ, caractères et clé sur 11 modules, arrêt sur 13
+		if attendu := 11*(len(c.texte)+2) + 13; len(modules) != attendu {
+			t.Errorf("%q : %d modules, attendu %d", c.texte, len(modules), attendu)
+		}
+		if !modules[0] || !modules[len(modules)-1] {
+			t.Errorf("%q : le symbole doit commencer et finir par une barre", c.texte)
+		}
+		texte, cle := decoderCode128(t, modules)
+		if texte != c.texte || cle != c.cle {
+			t.Errorf("%q : relu %q avec la clé %d, attendu la clé %d", c.texte, texte, cle, c.cle)
+		}
+	}
+}
+
+func TestCode128Refuse(t *testing.T) {
+	for _, texte := range []string{"", "LIVRE\t1", "CAFÉ"} {
+		if _, err := Code128(texte); err == nil {
+			t.Errorf("%q : erreur attendue", texte)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package codebarres
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ========================================
+// SYMBOLOGIES ET RENDU PNG
+// ========================================
+
+const (
+	SYMBOLOGIE_CODE128 = "code128"
+	SYMBOLOGIE_QR      = "qr"
+)
+
+// SYMBOLOGIES liste les symbologies disponibles pour les étiquettes et cartes
+var SYMBOLOGIES = []string{SYMBOLOGIE_CODE128, SYMBOLOGIE_QR}
+
+// Symbole est un code prêt à dessiner : une grille de modules noirs ou blancs.
+// Un code-barres linéaire n'a qu'une ligne, étirée à la hauteur voulue.
+type Symbole struct {
+	Modules  [][]bool
+	Lineaire bool
+}
+
+// Encoder produit le symbole du texte dans la symbologie demandée
+func Encoder(texte, symbologie string) (*Symbole, error) {
+	switch symbologie {
+	case SYMBOLOGIE_CODE128:
+		modules, err := Code128(texte)
+		if err != nil {
+			return nil, err
+		}
+		return &Symbole{Modules: [][]bool{modules}, Lineaire: true}, nil
+	case SYMBOLOGIE_QR:
+		modules, err := QR(texte)
+		if err != nil {
+			return nil, err
+		}
+		return &Symbole{Modules: modules}, nil
+	}
+	return nil, fmt.Errorf("symbologie inconnue '%s' (disponibles : %s)", symbologie, strings.Join(SYMBOLOGIES, ", "))
+}
+
+// Largeur retourne le nombre de modules en largeur, marges non comprises
+func (s *Symbole) Largeur() int {
+	return len(s.Modules[0])
+}
+
+// Hauteur retourne le nombre de lignes de modules (1 pour un code linéaire)
+func (s *Symbole) Hauteur() int {
+	return len(s.Modules)
+}
+
+// marge retourne la zone blanche obligatoire autour du symbole, en modules
+func (s *Symbole) marge() int {
+	if s.Lineaire {
+		return 10
+	}
+	return 4
+}
+
+// EcrirePNG dessine le symbole en PNG ; echelle est la taille d'un module en pixels
+// et hauteurLineaire la hauteur des barres d'un code linéaire, en modules
+func (s *Symbole) EcrirePNG(w io.Writer, echelle, hauteurLineaire int) error {
+	marge := s.marge()
+	lignes := s.Hauteur()
+	if s.Lineaire {
+		lignes = hauteurLineaire
+	}
+
+	largeurPixels := (s.Largeur() + 2*marge) * echelle
+	hauteurPixels := (lignes + 2*marge) * echelle
+	if s.Lineaire {
+		hauteurPixels = (lignes + 2*4) * echelle // marges verticales réduites
+	}
+
+	img := image.NewGray(image.Rect(0, 0, largeurPixels, hauteurPixels))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+
+	for py := 0; py < hauteurPixels; py++ {
+		for px := 0; px < largeurPixels; px++ {
+			x := px/echelle - marge
+			var y int
+			if s.Lineaire {
+				y = 0
+				if ligne := py/echelle - 4; ligne < 0 || ligne >= lignes {
+					continue
+				}
+			} else {
+				y = py/echelle - marge
+				if y < 0 || y >= lignes {
+					continue
+				}
+			}
+			if x >= 0 && x < s.Largeur() && s.Modules[y][x] {
+				img.SetGray(px, py, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
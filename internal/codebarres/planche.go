@@ -0,0 +1,133 @@
+package codebarres
+
+import (
+	"io"
+
+	"github.com/felver-dev/bookstore/internal/pdf"
+)
+
+// ========================================
+// PLANCHES D'ÉTIQUETTES ET DE CARTES (PDF A4)
+// ========================================
+
+// Etiquette est un élément à imprimer : le code, un titre et une ligne d'information
+type Etiquette struct {
+	Code  string
+	Titre string
+	Ligne string
+}
+
+// ModelePlanche décrit la découpe d'une feuille A4 (dimensions en millimètres)
+type ModelePlanche struct {
+	Colonnes, Lignes int
+	Largeur, Hauteur float64
+	MargeGauche      float64
+	MargeHaut        float64
+	Cadres           bool // traits de coupe autour de chaque élément
+	Entete           string
+}
+
+// Étiquettes autocollantes 3 x 8 (70 x 37 mm) et cartes au format carte bancaire 2 x 5
+var (
+	PLANCHE_ETIQUETTES = ModelePlanche{Colonnes: 3, Lignes: 8, Largeur: 70, Hauteur: 37, MargeGauche: 0, MargeHaut: 0.5}
+	PLANCHE_CARTES     = ModelePlanche{Colonnes: 2, Lignes: 5, Largeur: 85.6, Hauteur: 54, MargeGauche: 19.4, MargeHaut: 13.5, Cadres: true, Entete: "Carte de membre"}
+)
+
+// EcrirePlanche met en page les étiquettes sur autant de feuilles A4 que nécessaire
+func EcrirePlanche(w io.Writer, titre string, etiquettes []Etiquette, symbologie string, modele ModelePlanche) error {
+	document := pdf.NouveauDocument(titre)
+	parPage := modele.Colonnes * modele.Lignes
+
+	var page *pdf.Page
+	for i, etiquette := range etiquettes {
+		if i%parPage == 0 {
+			page = document.NouvellePage(pdf.A4_LARGEUR, pdf.A4_HAUTEUR)
+		}
+		position := i % parPage
+		x := (modele.MargeGauche + float64(position%modele.Colonnes)*modele.Largeur) * pdf.MM
+		y := pdf.A4_HAUTEUR - (modele.MargeHaut+float64(position/modele.Colonnes+1)*modele.Hauteur)*pdf.MM
+
+		if err := dessinerEtiquette(page, x, y, etiquette, symbologie, modele); err != nil {
+			return err
+		}
+	}
+
+	if document.NombrePages() == 0 {
+		document.NouvellePage(pdf.A4_LARGEUR, pdf.A4_HAUTEUR)
+	}
+	return document.Ecrire(w)
+}
+
+// dessinerEtiquette dessine un élément dont le coin inférieur gauche est (x, y)
+func dessinerEtiquette(page *pdf.Page, x, y float64, etiquette Etiquette, symbologie string, modele ModelePlanche) error {
+	symbole, err := Encoder(etiquette.Code, symbologie)
+	if err != nil {
+		return err
+	}
+
+	largeur := modele.Largeur * pdf.MM
+	hauteur := modele.Hauteur * pdf.MM
+	marge := 3 * pdf.MM
+
+	if modele.Cadres {
+		page.Couleur(0.7, 0.7, 0.7)
+		page.Cadre(x, y, largeur, hauteur, 0.3)
+	}
+	page.Couleur(0, 0, 0)
+
+	haut := y + hauteur - marge
+	if modele.Entete != "" {
+		page.Texte(x+marge, haut-7, pdf.POLICE_NORMALE, 7, modele.Entete)
+		haut -= 10
+	}
+	page.Texte(x+marge, haut-9, pdf.POLICE_GRASSE, 9,
+		pdf.Couper(etiquette.Titre, pdf.POLICE_GRASSE, 9, largeur-2*marge))
+	if etiquette.Ligne != "" {
+		page.Texte(x+marge, haut-19, pdf.POLICE_NORMALE, 7,
+			pdf.Couper(etiquette.Ligne, pdf.POLICE_NORMALE, 7, largeur-2*marge))
+	}
+	zoneHaut := haut - 23 // le symbole occupe l'espace restant sous le texte
+	zoneBas := y + marge + 9
+
+	if symbole.Lineaire {
+		// Les barres sont centrées et le code est écrit en clair dessous
+		module := (largeur - 2*marge) / float64(symbole.Largeur()+2*symbole.marge())
+		if module > 0.5*pdf.MM {
+			module = 0.5 * pdf.MM
+		}
+		debut := x + (largeur-module*float64(symbole.Largeur()))/2
+		dessinerBarres(page, symbole.Modules[0], debut, zoneBas, module, min(zoneHaut-zoneBas, 18*pdf.MM))
+		page.TexteCentre(x+largeur/2, y+marge+1, pdf.POLICE_FIXE, 8, etiquette.Code)
+		return nil
+	}
+
+	// QR code carré aligné à droite, zone blanche comprise, le code en clair à gauche
+	cote := zoneHaut - (y + marge)
+	module := cote / float64(symbole.Largeur()+2*symbole.marge())
+	gauche := x + largeur - marge - cote + float64(symbole.marge())*module
+	hautQR := zoneHaut - float64(symbole.marge())*module
+	for ligne, modules := range symbole.Modules {
+		for colonne, noir := range modules {
+			if noir {
+				page.Rectangle(gauche+float64(colonne)*module, hautQR-float64(ligne+1)*module, module, module)
+			}
+		}
+	}
+	page.Texte(x+marge, y+marge+1, pdf.POLICE_FIXE, 9, etiquette.Code)
+	return nil
+}
+
+// dessinerBarres regroupe les modules noirs consécutifs en une seule barre
+func dessinerBarres(page *pdf.Page, modules []bool, x, y, module, hauteur float64) {
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		debut := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		page.Rectangle(x+float64(debut)*module, y, float64(i-debut)*module, hauteur)
+	}
+}
@@ -0,0 +1,418 @@
+package codebarres
+
+import "fmt"
+
+// ========================================
+// QR CODE (MODE OCTET, CORRECTION M, VERSIONS 1 À 10)
+// Jusqu'à 213 octets, bien au-delà des besoins des étiquettes et cartes
+// ========================================
+
+type versionQR struct {
+	codewords  int   // nombre total de mots de code
+	blocs      int   // nombre de blocs de correction
+	correction int   // mots de correction par bloc
+	alignement []int // centres des motifs d'alignement
+}
+
+// versionsQR décrit les versions 1 à 10 au niveau de correction M
+var versionsQR = []versionQR{
+	{26, 1, 10, nil},
+	{44, 1, 16, []int{6, 18}},
+	{70, 1, 26, []int{6, 22}},
+	{100, 2, 18, []int{6, 26}},
+	{134, 2, 24, []int{6, 30}},
+	{172, 4, 16, []int{6, 34}},
+	{196, 4, 18, []int{6, 22, 38}},
+	{242, 4, 22, []int{6, 24, 42}},
+	{292, 5, 22, []int{6, 26, 46}},
+	{346, 5, 26, []int{6, 28, 50}},
+}
+
+type matriceQR struct {
+	taille    int
+	modules   [][]bool
+	fonctions [][]bool // modules réservés (repères, synchronisation, format)
+}
+
+// QR encode le texte et retourne la matrice de modules (vrai = noir), sans marge
+func QR(texte string) ([][]bool, error) {
+	donnees := []byte(texte)
+
+	version := 0
+	for v := range versionsQR {
+		bitsCompte := 8
+		if v+1 >= 10 {
+			bitsCompte = 16
+		}
+		capacite := (versionsQR[v].codewords - versionsQR[v].blocs*versionsQR[v].correction) * 8
+		if 4+bitsCompte+8*len(donnees) <= capacite {
+			version = v + 1
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("texte trop long pour un QR code (%d octets)", len(donnees))
+	}
+	v := versionsQR[version-1]
+
+	// 1. FLUX DE BITS : mode octet, longueur, données, terminaison, remplissage
+	capaciteOctets := v.codewords - v.blocs*v.correction
+	var bits []bool
+	ajouter := func(valeur, longueur int) {
+		for i := longueur - 1; i >= 0; i-- {
+			bits = append(bits, (valeur>>i)&1 == 1)
+		}
+	}
+	ajouter(0x4, 4)
+	if version >= 10 {
+		ajouter(len(donnees), 16)
+	} else {
+		ajouter(len(donnees), 8)
+	}
+	for _, b := range donnees {
+		ajouter(int(b), 8)
+	}
+	for i := 0; i < 4 && len(bits) < capaciteOctets*8; i++ {
+		bits = append(bits, false)
+	}
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+	for remplissage := 0xEC; len(bits) < capaciteOctets*8; remplissage ^= 0xEC ^ 0x11 {
+		ajouter(remplissage, 8)
+	}
+
+	octets := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			octets[i/8] |= 1 << (7 - uint(i%8))
+		}
+	}
+
+	// 2. CORRECTION D'ERREURS ET ENTRELACEMENT
+	mots := entrelacer(octets, v)
+
+	// 3. PLACEMENT ET MASQUE
+	m := nouvelleMatriceQR(version)
+	m.placerDonnees(mots)
+
+	meilleurMasque, meilleurePenalite := 0, -1
+	for masque := 0; masque < 8; masque++ {
+		m.appliquerMasque(masque)
+		m.dessinerFormat(masque)
+		if penalite := m.penalite(); meilleurePenalite < 0 || penalite < meilleurePenalite {
+			meilleurMasque, meilleurePenalite = masque, penalite
+		}
+		m.appliquerMasque(masque) // le masque est son propre inverse
+	}
+	m.appliquerMasque(meilleurMasque)
+	m.dessinerFormat(meilleurMasque)
+
+	return m.modules, nil
+}
+
+// entrelacer découpe les données en blocs, calcule leur correction Reed-Solomon
+// puis entrelace les mots comme l'exige la norme
+func entrelacer(donnees []byte, v versionQR) []byte {
+	blocsCourts := v.blocs - v.codewords%v.blocs
+	longueurCourte := v.codewords / v.blocs // correction comprise
+	diviseur := diviseurReedSolomon(v.correction)
+
+	var blocs [][]byte
+	k := 0
+	for i := 0; i < v.blocs; i++ {
+		longueur := longueurCourte - v.correction
+		if i >= blocsCourts {
+			longueur++
+		}
+		bloc := append([]byte(nil), donnees[k:k+longueur]...)
+		k += longueur
+		correction := resteReedSolomon(bloc, diviseur)
+		if i < blocsCourts {
+			bloc = append(bloc, 0) // place réservée, ignorée à l'entrelacement
+		}
+		blocs = append(blocs, append(bloc, correction...))
+	}
+
+	var resultat []byte
+	for i := range blocs[0] {
+		for j, bloc := range blocs {
+			if i != longueurCourte-v.correction || j >= blocsCourts {
+				resultat = append(resultat, bloc[i])
+			}
+		}
+	}
+	return resultat
+}
+
+// ========================================
+// REED-SOLOMON SUR GF(256)
+// ========================================
+
+func multiplierGF(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+func diviseurReedSolomon(degre int) []byte {
+	resultat := make([]byte, degre)
+	resultat[degre-1] = 1
+	var racine byte = 1
+	for i := 0; i < degre; i++ {
+		for j := range resultat {
+			resultat[j] = multiplierGF(resultat[j], racine)
+			if j+1 < len(resultat) {
+				resultat[j] ^= resultat[j+1]
+			}
+		}
+		racine = multiplierGF(racine, 0x02)
+	}
+	return resultat
+}
+
+func resteReedSolomon(donnees, diviseur []byte) []byte {
+	resultat := make([]byte, len(diviseur))
+	for _, b := range donnees {
+		facteur := b ^ resultat[0]
+		copy(resultat, resultat[1:])
+		resultat[len(resultat)-1] = 0
+		for i := range resultat {
+			resultat[i] ^= multiplierGF(diviseur[i], facteur)
+		}
+	}
+	return resultat
+}
+
+// ========================================
+// CONSTRUCTION DE LA MATRICE
+// ========================================
+
+func nouvelleMatriceQR(version int) *matriceQR {
+	taille := version*4 + 17
+	m := &matriceQR{taille: taille}
+	m.modules = make([][]bool, taille)
+	m.fonctions = make([][]bool, taille)
+	for i := range m.modules {
+		m.modules[i] = make([]bool, taille)
+		m.fonctions[i] = make([]bool, taille)
+	}
+
+	// Motifs de synchronisation
+	for i := 0; i < taille; i++ {
+		m.fixer(6, i, i%2 == 0)
+		m.fixer(i, 6, i%2 == 0)
+	}
+
+	// Repères de position dans trois coins
+	m.dessinerRepere(3, 3)
+	m.dessinerRepere(taille-4, 3)
+	m.dessinerRepere(3, taille-4)
+
+	// Motifs d'alignement (sauf là où ils chevaucheraient les repères)
+	positions := versionsQR[version-1].alignement
+	dernier := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == dernier) || (i == dernier && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					m.fixer(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Réserver les zones de format, puis les informations de version (7 et plus)
+	m.dessinerFormat(0)
+	if version >= 7 {
+		reste := version
+		for i := 0; i < 12; i++ {
+			reste = (reste << 1) ^ ((reste >> 11) * 0x1F25)
+		}
+		bits := version<<12 | reste
+		for i := 0; i < 18; i++ {
+			bit := (bits>>uint(i))&1 == 1
+			a, b := taille-11+i%3, i/3
+			m.fixer(a, b, bit)
+			m.fixer(b, a, bit)
+		}
+	}
+
+	return m
+}
+
+// fixer place un module de fonction ; x est la colonne, y la ligne
+func (m *matriceQR) fixer(x, y int, noir bool) {
+	m.modules[y][x] = noir
+	m.fonctions[y][x] = true
+}
+
+func (m *matriceQR) dessinerRepere(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= m.taille || y >= m.taille {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			m.fixer(x, y, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// dessinerFormat écrit le niveau de correction (M) et le masque, en double exemplaire
+func (m *matriceQR) dessinerFormat(masque int) {
+	donnees := masque // niveau M = 00
+	reste := donnees
+	for i := 0; i < 10; i++ {
+		reste = (reste << 1) ^ ((reste >> 9) * 0x537)
+	}
+	bits := (donnees<<10 | reste) ^ 0x5412
+	bit := func(i int) bool { return (bits>>uint(i))&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		m.fixer(8, i, bit(i))
+	}
+	m.fixer(8, 7, bit(6))
+	m.fixer(8, 8, bit(7))
+	m.fixer(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.fixer(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.fixer(m.taille-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.fixer(8, m.taille-15+i, bit(i))
+	}
+	m.fixer(8, m.taille-8, true) // module toujours noir
+}
+
+// placerDonnees parcourt la matrice en zigzag par colonnes de deux, de droite à gauche
+func (m *matriceQR) placerDonnees(mots []byte) {
+	i := 0
+	for droite := m.taille - 1; droite >= 1; droite -= 2 {
+		if droite == 6 {
+			droite = 5
+		}
+		for vertical := 0; vertical < m.taille; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := droite - j
+				y := vertical
+				if (droite+1)&2 == 0 {
+					y = m.taille - 1 - vertical
+				}
+				if !m.fonctions[y][x] && i < len(mots)*8 {
+					m.modules[y][x] = (mots[i>>3]>>uint(7-(i&7)))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (m *matriceQR) appliquerMasque(masque int) {
+	for y := 0; y < m.taille; y++ {
+		for x := 0; x < m.taille; x++ {
+			var inverser bool
+			switch masque {
+			case 0:
+				inverser = (x+y)%2 == 0
+			case 1:
+				inverser = y%2 == 0
+			case 2:
+				inverser = x%3 == 0
+			case 3:
+				inverser = (x+y)%3 == 0
+			case 4:
+				inverser = (x/3+y/2)%2 == 0
+			case 5:
+				inverser = x*y%2+x*y%3 == 0
+			case 6:
+				inverser = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				inverser = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if inverser && !m.fonctions[y][x] {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalite évalue la lisibilité d'un masque (plus c'est bas, mieux c'est)
+func (m *matriceQR) penalite() int {
+	total := 0
+	n := m.taille
+	lire := func(ligne bool, a, b int) bool {
+		if ligne {
+			return m.modules[a][b]
+		}
+		return m.modules[b][a]
+	}
+
+	// Suites de 5 modules ou plus de même couleur, et motifs ressemblant aux repères
+	for _, ligne := range []bool{true, false} {
+		for a := 0; a < n; a++ {
+			suite := 1
+			for b := 1; b < n; b++ {
+				if lire(ligne, a, b) == lire(ligne, a, b-1) {
+					suite++
+					if suite == 5 {
+						total += 3
+					} else if suite > 5 {
+						total++
+					}
+				} else {
+					suite = 1
+				}
+			}
+			for b := 0; b+10 < n; b++ {
+				motif := [11]bool{}
+				for k := range motif {
+					motif[k] = lire(ligne, a, b+k)
+				}
+				if motif == [11]bool{true, false, true, true, true, false, true, false, false, false, false} ||
+					motif == [11]bool{false, false, false, false, true, false, true, true, true, false, true} {
+					total += 40
+				}
+			}
+		}
+	}
+
+	// Blocs 2x2 de même couleur
+	noirs := 0
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if m.modules[y][x] {
+				noirs++
+			}
+			if x+1 < n && y+1 < n {
+				c := m.modules[y][x]
+				if m.modules[y][x+1] == c && m.modules[y+1][x] == c && m.modules[y+1][x+1] == c {
+					total += 3
+				}
+			}
+		}
+	}
+
+	// Équilibre entre modules noirs et blancs
+	ecart := abs(noirs*100/(n*n) - 50)
+	total += ecart / 5 * 10
+
+	return total
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
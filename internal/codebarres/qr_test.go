@@ -0,0 +1,253 @@
+package codebarres
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// formatsQR donne les 15 bits de format du niveau de correction M pour
+// chaque masque, tels que les tabule la norme (ISO/IEC 18004, annexe C)
+var formatsQR = [8]string{
+	"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+	"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+}
+
+// lireFormat relit les bits de format autour du repère en haut à gauche,
+// du bit 14 au bit 0
+func lireFormat(modules [][]bool) string {
+	var positions [][2]int // colonne, ligne
+	for i := 0; i <= 5; i++ {
+		positions = append(positions, [2]int{8, i})
+	}
+	positions = append(positions, [2]int{8, 7}, [2]int{8, 8}, [2]int{7, 8})
+	for i := 9; i < 15; i++ {
+		positions = append(positions, [2]int{14 - i, 8})
+	}
+
+	bits := make([]byte, 15)
+	for i, p := range positions {
+		bits[14-i] = '0'
+		if modules[p[1]][p[0]] {
+			bits[14-i] = '1'
+		}
+	}
+	return string(bits)
+}
+
+// lireFormatBis relit la copie des bits de format, le long des deux autres repères
+func lireFormatBis(modules [][]bool) string {
+	taille := len(modules)
+	bits := make([]byte, 15)
+	for i := 0; i < 15; i++ {
+		x, y := taille-1-i, 8
+		if i >= 8 {
+			x, y = 8, taille-15+i
+		}
+		bits[14-i] = '0'
+		if modules[y][x] {
+			bits[14-i] = '1'
+		}
+	}
+	return string(bits)
+}
+
+func TestFormatQR(t *testing.T) {
+	for masque, attendu := range formatsQR {
+		m := nouvelleMatriceQR(1)
+		m.dessinerFormat(masque)
+		if format := lireFormat(m.modules); format != attendu {
+			t.Errorf("masque %d : format %s, attendu %s", masque, format, attendu)
+		}
+		if format := lireFormatBis(m.modules); format != attendu {
+			t.Errorf("masque %d : copie du format %s, attendu %s", masque, format, attendu)
+		}
+		if !m.modules[m.taille-8][8] {
+			t.Errorf("masque %d : le module sombre fixe manque", masque)
+		}
+	}
+}
+
+func TestVersionQR(t *testing.T) {
+	// Informations de version tabulées par la norme (annexe D), sur 18 bits
+	cas := []struct {
+		version int
+		bits    int
+	}{
+		{7, 0x07C94},
+		{8, 0x085BC},
+		{9, 0x09A99},
+		{10, 0x0A4D3},
+	}
+	for _, c := range cas {
+		m := nouvelleMatriceQR(c.version)
+		bas, droite := 0, 0
+		for i := 0; i < 18; i++ {
+			if m.modules[m.taille-11+i%3][i/3] {
+				bas |= 1 << i
+			}
+			if m.modules[i/3][m.taille-11+i%3] {
+				droite |= 1 << i
+			}
+		}
+		if bas != c.bits || droite != c.bits {
+			t.Errorf("version %d : bits %05X et %05X, attendu %05X", c.version, bas, droite, c.bits)
+		}
+	}
+
+	// Les versions 1 à 6 n'ont pas de bloc de version
+	m := nouvelleMatriceQR(6)
+	if m.fonctions[0][m.taille-11] {
+		t.Error("version 6 : bloc de version réservé")
+	}
+}
+
+func TestReedSolomonQR(t *testing.T) {
+	// « HELLO WORLD » en version 1-M (mode alphanumérique) : mots de données
+	// et de correction donnés en exemple par le tutoriel de Thonky
+	donnees := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	attendu := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if correction := resteReedSolomon(donnees, diviseurReedSolomon(10)); !bytes.Equal(correction, attendu) {
+		t.Errorf("correction %v, attendu %v", correction, attendu)
+	}
+
+	// Le polynôme générateur de degré 7 : x⁷ + α⁸⁷x⁶ + α²²⁹x⁵ + α¹⁴⁶x⁴ + α¹⁴⁹x³ + α²³⁸x² + α¹⁰²x + α²¹
+	if diviseur := diviseurReedSolomon(7); !bytes.Equal(diviseur, []byte{127, 122, 154, 164, 11, 68, 117}) {
+		t.Errorf("générateur de degré 7 : %v", diviseur)
+	}
+}
+
+// decoderQR relit un QR code en mode octet : masque tiré du format, lecture
+// en zigzag, désentrelacement, contrôle de la correction de chaque bloc
+func decoderQR(t *testing.T, modules [][]bool) string {
+	t.Helper()
+	taille := len(modules)
+	version := (taille - 17) / 4
+
+	masque := -1
+	for i, format := range formatsQR {
+		if format == lireFormat(modules) {
+			masque = i
+		}
+	}
+	if masque < 0 {
+		t.Fatalf("format %s inconnu", lireFormat(modules))
+	}
+	inverser := [8]func(x, y int) bool{
+		func(x, y int) bool { return (y+x)%2 == 0 },
+		func(x, y int) bool { return y%2 == 0 },
+		func(x, y int) bool { return x%3 == 0 },
+		func(x, y int) bool { return (y+x)%3 == 0 },
+		func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+		func(x, y int) bool { return (y*x)%2+(y*x)%3 == 0 },
+		func(x, y int) bool { return ((y*x)%2+(y*x)%3)%2 == 0 },
+		func(x, y int) bool { return ((y+x)%2+(y*x)%3)%2 == 0 },
+	}[masque]
+
+	// Zigzag par colonnes de deux, de droite à gauche, en sautant la colonne 6
+	reserve := nouvelleMatriceQR(version).fonctions
+	var bits []bool
+	for droite := taille - 1; droite >= 1; droite -= 2 {
+		if droite == 6 {
+			droite--
+		}
+		montee := (taille-1-droite)/2%2 == 0
+		if droite < 6 {
+			montee = (taille-2-droite)/2%2 == 0
+		}
+		for k := 0; k < taille; k++ {
+			y := k
+			if montee {
+				y = taille - 1 - k
+			}
+			for _, x := range []int{droite, droite - 1} {
+				if !reserve[y][x] {
+					bits = append(bits, modules[y][x] != inverser(x, y))
+				}
+			}
+		}
+	}
+	v := versionsQR[version-1]
+	mots := make([]byte, v.codewords)
+	for i := range mots {
+		for j := 0; j < 8; j++ {
+			if bits[8*i+j] {
+				mots[i] |= 1 << (7 - j)
+			}
+		}
+	}
+
+	// Désentrelacement : les blocs longs ont un mot de données de plus
+	longs := v.codewords % v.blocs
+	courts := v.blocs - longs
+	donneesCourtes := v.codewords/v.blocs - v.correction
+	blocs := make([][]byte, v.blocs)
+	k := 0
+	for i := 0; i <= donneesCourtes; i++ {
+		for b := range blocs {
+			if i < donneesCourtes || b >= courts {
+				blocs[b] = append(blocs[b], mots[k])
+				k++
+			}
+		}
+	}
+	var donnees []byte
+	diviseur := diviseurReedSolomon(v.correction)
+	for b := range blocs {
+		correction := make([]byte, v.correction)
+		for i := range correction {
+			correction[i] = mots[k+i*v.blocs+b]
+		}
+		if attendu := resteReedSolomon(blocs[b], diviseur); !bytes.Equal(correction, attendu) {
+			t.Fatalf("bloc %d : correction %v, attendu %v", b, correction, attendu)
+		}
+		donnees = append(donnees, blocs[b]...)
+	}
+
+	// Mode octet (0100), longueur sur 8 bits jusqu'à la version 9, 16 ensuite
+	if donnees[0]>>4 != 0x4 {
+		t.Fatalf("mode %04b, attendu 0100 (octet)", donnees[0]>>4)
+	}
+	flux := make([]byte, len(donnees)-1)
+	for i := range flux {
+		flux[i] = donnees[i]<<4 | donnees[i+1]>>4
+	}
+	longueur, debut := int(flux[0]), 1
+	if version >= 10 {
+		longueur, debut = int(flux[0])<<8|int(flux[1]), 2
+	}
+	return string(flux[debut : debut+longueur])
+}
+
+func TestQRRelu(t *testing.T) {
+	cas := []struct {
+		texte   string
+		version int
+	}{
+		{"LIV000001", 1},
+		{"MEMK3QF-000042", 1},
+		{"https://librairie.example/livres/LIV000042", 3},
+		// Version 8 : deux blocs de 38 mots de données, puis deux de 39
+		{strings.Repeat("Le Petit Prince ", 8), 8},
+		// Version 10 : longueur sur 16 bits
+		{strings.Repeat("L'Étranger, Albert Camus. ", 7), 10},
+	}
+	for _, c := range cas {
+		modules, err := QR(c.texte)
+		if err != nil {
+			t.Errorf("%q : %v", c.texte, err)
+			continue
+		}
+		if taille := 4*c.version + 17; len(modules) != taille {
+			t.Errorf("%q : %d modules de côté, attendu %d (version %d)", c.texte, len(modules), taille, c.version)
+			continue
+		}
+		if texte := decoderQR(t, modules); texte != c.texte {
+			t.Errorf("relu %q, attendu %q", texte, c.texte)
+		}
+	}
+
+	if _, err := QR(strings.Repeat("x", 214)); err == nil {
+		t.Error("texte de 214 octets : erreur attendue")
+	}
+}
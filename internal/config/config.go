@@ -0,0 +1,540 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/codebarres"
+)
+
+// ========================================
+// CONFIGURATION DE L'APPLICATION
+// Les valeurs sont lues dans cet ordre (la dernière gagne) :
+// valeurs par défaut -> fichier JSON -> variables d'environnement -> options de ligne de commande
+// ========================================
+
+const (
+	FICHIER_CONFIG_DEFAUT = "config.json"
+	PREFIXE_ENV           = "LIBRAIRIE_"
+
+	INTERFACE_PLEIN_ECRAN = "plein-ecran"
+	INTERFACE_MENUS       = "menus"
+	INTERFACE_KIOSQUE     = "kiosque" // libre-service verrouillé pour les membres
+	INTERFACE_PORTAIL     = "portail" // portail web des membres seul, sans écran au comptoir
+)
+
+type Config struct {
+	// Interface choisit l'écran du comptoir : plein écran ou menus numérotés
+	Interface    string             `json:"interface"`
+	Donnees      ConfigDonnees      `json:"donnees"`
+	Emprunts     ConfigEmprunts     `json:"emprunts"`
+	Validation   ConfigValidation   `json:"validation"`
+	Conservation ConfigConservation `json:"conservation"`
+	Affichage    ConfigAffichage    `json:"affichage"`
+	Etiquettes   ConfigEtiquettes   `json:"etiquettes"`
+	Kiosque      ConfigKiosque      `json:"kiosque"`
+	Portail      ConfigPortail      `json:"portail"`
+	Rapports     ConfigRapports     `json:"rapports"`
+	Synchro      ConfigSynchro      `json:"synchro"`
+
+	// Succursale est le code de la succursale pour laquelle travaille ce poste ;
+	// vide, c'est la première du réseau
+	Succursale string `json:"succursale"`
+
+	// Script rejoue les saisies d'un fichier à la place du clavier (option -script uniquement)
+	Script string `json:"-"`
+
+	// Rapport demande le rapport d'une période puis l'arrêt du programme (option -rapport uniquement)
+	Rapport string `json:"-"`
+
+	// Verifier contrôle l'intégrité des données puis arrête le programme ; avec
+	// Reparer, les anomalies réparables sont corrigées (options -verifier et -reparer uniquement)
+	Verifier bool `json:"-"`
+	Reparer  bool `json:"-"`
+
+	// Synchroniser échange les événements avec chaque pair puis arrête le programme (option -synchroniser uniquement)
+	Synchroniser bool `json:"-"`
+}
+
+type ConfigDonnees struct {
+	Dossier       string `json:"dossier"`
+	Livres        string `json:"livres"`
+	Membres       string `json:"membres"`
+	Emprunts      string `json:"emprunts"`
+	Genres        string `json:"genres"`
+	Contributeurs string `json:"contributeurs"`
+	Series        string `json:"series"`
+	Reservations  string `json:"reservations"`
+	Recherches    string `json:"recherches"`   // recherches du catalogue restées sans résultat
+	Acquisitions  string `json:"acquisitions"` // fournisseurs, commandes et budgets
+	Inventaires   string `json:"inventaires"`  // inventaires des rayons
+	Journal       string `json:"journal"`      // événements des emprunts, en ajout seul
+	Instantanes   string `json:"instantanes"`  // états des emprunts reconstruits depuis le journal
+	Sequences     string `json:"sequences"`    // derniers numéros attribués à chaque type d'enregistrement
+	Succursales   string `json:"succursales"`  // bibliothèques du réseau
+	Synchro       string `json:"synchro"`      // identifiant du poste, pairs et conflits de synchronisation
+	PEB           string `json:"peb"`          // prêts entre bibliothèques
+}
+
+type ConfigEmprunts struct {
+	DureeJours        int `json:"duree_jours"`
+	LimiteSimultanes  int `json:"limite_simultanes"`
+	ProlongationsMax  int `json:"prolongations_max"`   // prolongations permises au membre lui-même
+	DelaiRetraitJours int `json:"delai_retrait_jours"` // jours pendant lesquels un livre réservé reste mis de côté
+}
+
+type ConfigValidation struct {
+	// Genres sert à initialiser la taxonomie lors du premier démarrage
+	Genres              []string `json:"genres"`
+	AnneePublicationMin int      `json:"annee_publication_min"`
+}
+
+// ConfigConservation fixe les règles de purge des éléments retirés.
+// Une durée de 0 interdit toute purge.
+type ConfigConservation struct {
+	LivresRetiresJours int `json:"livres_retires_jours"`
+	MembresRadiesJours int `json:"membres_radies_jours"`
+}
+
+// ConfigAffichage règle la présentation des listes dans les menus.
+// Une largeur de 0 suit la largeur du terminal.
+type ConfigAffichage struct {
+	Format  string `json:"format"`
+	Largeur int    `json:"largeur"`
+}
+
+// ConfigEtiquettes règle l'impression des étiquettes de livres et des cartes de membre
+type ConfigEtiquettes struct {
+	Dossier    string `json:"dossier"`    // Dossier où sont écrits les PNG et les planches PDF
+	Symbologie string `json:"symbologie"` // code128 (douchettes 1D) ou qr (lecteurs 2D, téléphones)
+}
+
+// ConfigKiosque règle le libre-service : la session se ferme après DelaiSecondes
+// sans saisie, et chaque session produit un reçu dans DossierRecus
+type ConfigKiosque struct {
+	DelaiSecondes int    `json:"delai_secondes"`
+	DossierRecus  string `json:"dossier_recus"`
+	RecuPDF       bool   `json:"recu_pdf"` // en plus du reçu texte
+}
+
+// ConfigPortail règle le portail web des membres, servi sur le réseau local.
+// Une adresse vide désactive le portail.
+type ConfigPortail struct {
+	Adresse             string `json:"adresse"`      // adresse d'écoute, par exemple ":8080"
+	URLPublique         string `json:"url_publique"` // début des liens de connexion envoyés par courriel
+	DureeSessionMinutes int    `json:"duree_session_minutes"`
+	DureeLienMinutes    int    `json:"duree_lien_minutes"` // validité d'un lien de connexion
+	BoiteEnvoi          string `json:"boite_envoi"`        // dossier où sont déposés les courriels à envoyer
+}
+
+// ConfigRapports règle les rapports d'activité générés en HTML et en PDF
+type ConfigRapports struct {
+	Dossier string `json:"dossier"`
+}
+
+// ConfigSynchro règle la synchronisation avec les autres postes. Une adresse
+// vide n'ouvre pas le service, mais le poste peut quand même joindre ses pairs.
+type ConfigSynchro struct {
+	Adresse       string   `json:"adresse"`        // adresse d'écoute du service, par exemple ":8090"
+	Cle           string   `json:"cle"`            // clé partagée par tous les postes du réseau
+	Pairs         []string `json:"pairs"`          // services des autres postes, par exemple "http://comptoir-2:8090"
+	DelaiSecondes int      `json:"delai_secondes"` // entre deux synchronisations automatiques, 0 pour les désactiver
+}
+
+// Defaut retourne la configuration utilisée quand rien n'est précisé
+func Defaut() *Config {
+	return &Config{
+		Interface: INTERFACE_PLEIN_ECRAN,
+		Donnees: ConfigDonnees{
+			Dossier:       "data",
+			Livres:        "livres.json",
+			Membres:       "membres.json",
+			Emprunts:      "emprunts.json",
+			Genres:        "genres.json",
+			Contributeurs: "contributeurs.json",
+			Series:        "series.json",
+			Reservations:  "reservations.json",
+			Recherches:    "recherches.json",
+			Acquisitions:  "acquisitions.json",
+			Inventaires:   "inventaires.json",
+			Journal:       "journal-emprunts.jsonl",
+			Instantanes:   "instantanes-emprunts.json",
+			Sequences:     "sequences.json",
+			Succursales:   "succursales.json",
+			Synchro:       "synchro.json",
+			PEB:           "peb.json",
+		},
+		Emprunts: ConfigEmprunts{
+			DureeJours:        14,
+			LimiteSimultanes:  3,
+			ProlongationsMax:  1,
+			DelaiRetraitJours: 7,
+		},
+		Validation: ConfigValidation{
+			Genres: []string{
+				"Roman", "Science-fiction", "Fantasy", "Policier", "Thriller",
+				"Romance", "Historique", "Biographie", "Essai", "Poésie",
+				"Théâtre", "Bande dessinée", "Manga", "Jeunesse", "Documentaire",
+				"Guide pratique", "Cuisine", "Art", "Sport", "Autre",
+			},
+			AnneePublicationMin: 1440,
+		},
+		Conservation: ConfigConservation{
+			LivresRetiresJours: 0,
+			MembresRadiesJours: 0,
+		},
+		Affichage: ConfigAffichage{
+			Format:  affichage.FORMAT_TABLEAU,
+			Largeur: 0,
+		},
+		Etiquettes: ConfigEtiquettes{
+			Dossier:    "etiquettes",
+			Symbologie: codebarres.SYMBOLOGIE_CODE128,
+		},
+		Kiosque: ConfigKiosque{
+			DelaiSecondes: 60,
+			DossierRecus:  "recus",
+			RecuPDF:       true,
+		},
+		Portail: ConfigPortail{
+			DureeSessionMinutes: 30,
+			DureeLienMinutes:    15,
+			BoiteEnvoi:          "envois",
+		},
+		Rapports: ConfigRapports{
+			Dossier: "rapports",
+		},
+		Synchro: ConfigSynchro{
+			DelaiSecondes: 60,
+		},
+	}
+}
+
+// Charger construit la configuration à partir des arguments de la ligne de commande
+// (sans le nom du programme), du fichier de configuration et de l'environnement
+func Charger(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("gestion-librairie", flag.ContinueOnError)
+	fichier := fs.String("config", "", "chemin du fichier de configuration JSON")
+	dossier := fs.String("donnees", "", "dossier des fichiers de données")
+	duree := fs.Int("duree-emprunt", 0, "durée d'un emprunt en jours")
+	limite := fs.Int("limite-emprunts", 0, "nombre maximum d'emprunts simultanés par membre")
+	anneeMin := fs.Int("annee-min", 0, "année de publication minimale acceptée")
+	genres := fs.String("genres", "", "liste des genres acceptés, séparés par des virgules")
+	ecran := fs.String("interface", "", "interface : plein-ecran, menus, kiosque ou portail")
+	portail := fs.String("portail", "", "adresse d'écoute du portail des membres (ex. :8080)")
+	script := fs.String("script", "", "fichier de commandes à rejouer dans les menus")
+	rapport := fs.String("rapport", "", "génère le rapport d'une période (AAAA-MM, AAAA ou JJ/MM/AAAA-JJ/MM/AAAA, « - » pour le mois dernier) puis quitte")
+	verifier := fs.Bool("verifier", false, "contrôle l'intégrité des données (compteurs, orphelins, doublons) et affiche les corrections proposées, puis quitte")
+	reparer := fs.Bool("reparer", false, "applique les corrections proposées par -verifier, puis quitte")
+	synchro := fs.String("synchro", "", "adresse d'écoute du service de synchronisation entre postes (ex. :8090)")
+	synchroniser := fs.Bool("synchroniser", false, "échange les événements avec chaque poste pair, puis quitte")
+	succursale := fs.String("succursale", "", "code de la succursale pour laquelle travaille ce poste")
+	format := fs.String("format", "", "format des listes : "+strings.Join(affichage.FORMATS, ", "))
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Defaut()
+
+	// 1. FICHIER DE CONFIGURATION
+	chemin := *fichier
+	obligatoire := chemin != ""
+	if chemin == "" {
+		chemin = os.Getenv(PREFIXE_ENV + "CONFIG")
+		obligatoire = chemin != ""
+	}
+	if chemin == "" {
+		chemin = FICHIER_CONFIG_DEFAUT
+	}
+	if err := cfg.chargerFichier(chemin, obligatoire); err != nil {
+		return nil, err
+	}
+
+	// 2. VARIABLES D'ENVIRONNEMENT
+	if err := cfg.appliquerEnvironnement(); err != nil {
+		return nil, err
+	}
+
+	// 3. OPTIONS DE LIGNE DE COMMANDE (seulement celles explicitement fournies)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "donnees":
+			cfg.Donnees.Dossier = *dossier
+		case "duree-emprunt":
+			cfg.Emprunts.DureeJours = *duree
+		case "limite-emprunts":
+			cfg.Emprunts.LimiteSimultanes = *limite
+		case "annee-min":
+			cfg.Validation.AnneePublicationMin = *anneeMin
+		case "genres":
+			cfg.Validation.Genres = decouperListe(*genres)
+		case "interface":
+			cfg.Interface = *ecran
+		case "format":
+			cfg.Affichage.Format = *format
+		case "succursale":
+			cfg.Succursale = *succursale
+		case "portail":
+			cfg.Portail.Adresse = *portail
+		case "synchro":
+			cfg.Synchro.Adresse = *synchro
+		case "synchroniser":
+			cfg.Synchroniser = *synchroniser
+		case "script":
+			cfg.Script = *script
+		case "rapport":
+			cfg.Rapport = *rapport
+		case "verifier":
+			cfg.Verifier = *verifier
+		case "reparer":
+			cfg.Reparer = *reparer
+		}
+	})
+
+	if err := cfg.Valider(); err != nil {
+		return nil, fmt.Errorf("configuration invalide : %v", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) chargerFichier(chemin string, obligatoire bool) error {
+	contenu, err := os.ReadFile(chemin)
+	if err != nil {
+		if os.IsNotExist(err) && !obligatoire {
+			return nil
+		}
+		return fmt.Errorf("erreur lors de la lecture du fichier de configuration %s : %v", chemin, err)
+	}
+
+	if err := json.Unmarshal(contenu, c); err != nil {
+		return fmt.Errorf("erreur lors de la lecture du fichier de configuration %s : %v", chemin, err)
+	}
+	return nil
+}
+
+func (c *Config) appliquerEnvironnement() error {
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "DONNEES"); ok {
+		c.Donnees.Dossier = valeur
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "INTERFACE"); ok {
+		c.Interface = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "SUCCURSALE"); ok {
+		c.Succursale = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "FORMAT"); ok {
+		c.Affichage.Format = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "ETIQUETTES"); ok {
+		c.Etiquettes.Dossier = valeur
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "RECUS"); ok {
+		c.Kiosque.DossierRecus = valeur
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "PORTAIL"); ok {
+		c.Portail.Adresse = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "URL_PORTAIL"); ok {
+		c.Portail.URLPublique = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "BOITE_ENVOI"); ok {
+		c.Portail.BoiteEnvoi = valeur
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "SYNCHRO"); ok {
+		c.Synchro.Adresse = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "CLE_SYNCHRO"); ok {
+		c.Synchro.Cle = strings.TrimSpace(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "PAIRS"); ok {
+		c.Synchro.Pairs = decouperListe(valeur)
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "RAPPORTS"); ok {
+		c.Rapports.Dossier = valeur
+	}
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "SYMBOLOGIE"); ok {
+		c.Etiquettes.Symbologie = strings.ToLower(strings.TrimSpace(valeur))
+	}
+
+	entiers := map[string]*int{
+		"DUREE_EMPRUNT":        &c.Emprunts.DureeJours,
+		"LIMITE_EMPRUNTS":      &c.Emprunts.LimiteSimultanes,
+		"ANNEE_MIN":            &c.Validation.AnneePublicationMin,
+		"CONSERVATION_LIVRES":  &c.Conservation.LivresRetiresJours,
+		"CONSERVATION_MEMBRES": &c.Conservation.MembresRadiesJours,
+		"LARGEUR":              &c.Affichage.Largeur,
+		"PROLONGATIONS_MAX":    &c.Emprunts.ProlongationsMax,
+		"DELAI_KIOSQUE":        &c.Kiosque.DelaiSecondes,
+		"DELAI_RETRAIT":        &c.Emprunts.DelaiRetraitJours,
+		"DELAI_SYNCHRO":        &c.Synchro.DelaiSecondes,
+	}
+	for nom, cible := range entiers {
+		valeur, ok := os.LookupEnv(PREFIXE_ENV + nom)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(valeur))
+		if err != nil {
+			return fmt.Errorf("la variable %s%s doit être un nombre entier ('%s')", PREFIXE_ENV, nom, valeur)
+		}
+		*cible = n
+	}
+
+	if valeur, ok := os.LookupEnv(PREFIXE_ENV + "GENRES"); ok {
+		c.Validation.Genres = decouperListe(valeur)
+	}
+
+	return nil
+}
+
+// Valider vérifie la cohérence de la configuration au démarrage
+func (c *Config) Valider() error {
+	switch c.Interface {
+	case INTERFACE_PLEIN_ECRAN, INTERFACE_MENUS, INTERFACE_KIOSQUE, INTERFACE_PORTAIL:
+	default:
+		return fmt.Errorf("interface inconnue '%s' (valeurs possibles : %s, %s, %s, %s)", c.Interface, INTERFACE_PLEIN_ECRAN, INTERFACE_MENUS, INTERFACE_KIOSQUE, INTERFACE_PORTAIL)
+	}
+
+	if strings.TrimSpace(c.Donnees.Dossier) == "" {
+		return fmt.Errorf("le dossier des données ne peut pas être vide")
+	}
+
+	for nom, fichier := range map[string]string{
+		"livres": c.Donnees.Livres, "membres": c.Donnees.Membres, "emprunts": c.Donnees.Emprunts,
+		"genres": c.Donnees.Genres, "contributeurs": c.Donnees.Contributeurs, "séries": c.Donnees.Series,
+		"réservations": c.Donnees.Reservations, "recherches": c.Donnees.Recherches,
+		"acquisitions": c.Donnees.Acquisitions, "inventaires": c.Donnees.Inventaires,
+		"événements": c.Donnees.Journal, "instantanés": c.Donnees.Instantanes,
+		"numéros": c.Donnees.Sequences, "succursales": c.Donnees.Succursales,
+		"synchronisations": c.Donnees.Synchro, "prêts entre bibliothèques": c.Donnees.PEB,
+	} {
+		if strings.TrimSpace(fichier) == "" {
+			return fmt.Errorf("le fichier des %s ne peut pas être vide", nom)
+		}
+	}
+
+	if c.Emprunts.DureeJours < 1 || c.Emprunts.DureeJours > 365 {
+		return fmt.Errorf("la durée d'emprunt doit être entre 1 et 365 jours (actuellement %d)", c.Emprunts.DureeJours)
+	}
+
+	if c.Emprunts.LimiteSimultanes < 1 {
+		return fmt.Errorf("la limite d'emprunts simultanés doit être au moins 1 (actuellement %d)", c.Emprunts.LimiteSimultanes)
+	}
+
+	if c.Emprunts.ProlongationsMax < 0 {
+		return fmt.Errorf("le nombre de prolongations ne peut pas être négatif (actuellement %d)", c.Emprunts.ProlongationsMax)
+	}
+
+	if c.Emprunts.DelaiRetraitJours < 1 {
+		return fmt.Errorf("le délai de retrait d'un livre réservé doit être d'au moins 1 jour (actuellement %d)", c.Emprunts.DelaiRetraitJours)
+	}
+
+	if len(c.Validation.Genres) == 0 {
+		return fmt.Errorf("la liste des genres ne peut pas être vide")
+	}
+	vus := make(map[string]bool)
+	for _, genre := range c.Validation.Genres {
+		cle := strings.ToLower(strings.TrimSpace(genre))
+		if cle == "" {
+			return fmt.Errorf("la liste des genres contient une entrée vide")
+		}
+		if vus[cle] {
+			return fmt.Errorf("le genre '%s' est présent plusieurs fois", genre)
+		}
+		vus[cle] = true
+	}
+
+	if c.Validation.AnneePublicationMin < 1 {
+		return fmt.Errorf("l'année de publication minimale doit être positive (actuellement %d)", c.Validation.AnneePublicationMin)
+	}
+
+	if c.Conservation.LivresRetiresJours < 0 || c.Conservation.MembresRadiesJours < 0 {
+		return fmt.Errorf("les durées de conservation ne peuvent pas être négatives")
+	}
+
+	if _, err := affichage.NouveauFormat(c.Affichage.Format, 0); err != nil {
+		return err
+	}
+	if c.Affichage.Largeur < 0 {
+		return fmt.Errorf("la largeur d'affichage ne peut pas être négative (actuellement %d)", c.Affichage.Largeur)
+	}
+
+	if strings.TrimSpace(c.Etiquettes.Dossier) == "" {
+		return fmt.Errorf("le dossier des étiquettes ne peut pas être vide")
+	}
+	symbologieConnue := false
+	for _, symbologie := range codebarres.SYMBOLOGIES {
+		symbologieConnue = symbologieConnue || symbologie == c.Etiquettes.Symbologie
+	}
+	if !symbologieConnue {
+		return fmt.Errorf("symbologie inconnue '%s' (valeurs possibles : %s)", c.Etiquettes.Symbologie, strings.Join(codebarres.SYMBOLOGIES, ", "))
+	}
+
+	if c.Kiosque.DelaiSecondes < 10 {
+		return fmt.Errorf("le délai d'inactivité du libre-service doit être d'au moins 10 secondes (actuellement %d)", c.Kiosque.DelaiSecondes)
+	}
+	if strings.TrimSpace(c.Kiosque.DossierRecus) == "" {
+		return fmt.Errorf("le dossier des reçus ne peut pas être vide")
+	}
+
+	if c.Interface == INTERFACE_PORTAIL && strings.TrimSpace(c.Portail.Adresse) == "" {
+		return fmt.Errorf("l'interface %s demande une adresse d'écoute (portail.adresse ou -portail)", INTERFACE_PORTAIL)
+	}
+	if c.Portail.URLPublique != "" && !strings.HasPrefix(c.Portail.URLPublique, "http://") && !strings.HasPrefix(c.Portail.URLPublique, "https://") {
+		return fmt.Errorf("l'URL publique du portail doit commencer par http:// ou https:// ('%s')", c.Portail.URLPublique)
+	}
+	if c.Portail.DureeSessionMinutes < 1 || c.Portail.DureeLienMinutes < 1 {
+		return fmt.Errorf("les durées de session et de lien de connexion du portail doivent être d'au moins 1 minute")
+	}
+	if strings.TrimSpace(c.Portail.BoiteEnvoi) == "" {
+		return fmt.Errorf("la boîte d'envoi du portail ne peut pas être vide")
+	}
+
+	if strings.TrimSpace(c.Rapports.Dossier) == "" {
+		return fmt.Errorf("le dossier des rapports ne peut pas être vide")
+	}
+
+	// La clé protège les données des membres qui circulent entre les postes
+	if (c.Synchro.Adresse != "" || len(c.Synchro.Pairs) > 0) && len(c.Synchro.Cle) < 16 {
+		return fmt.Errorf("la synchronisation demande une clé partagée d'au moins 16 caractères (synchro.cle ou %sCLE_SYNCHRO)", PREFIXE_ENV)
+	}
+	for _, pair := range c.Synchro.Pairs {
+		if !strings.HasPrefix(pair, "http://") && !strings.HasPrefix(pair, "https://") {
+			return fmt.Errorf("l'adresse du poste pair doit commencer par http:// ou https:// ('%s')", pair)
+		}
+	}
+	if c.Synchro.DelaiSecondes < 0 {
+		return fmt.Errorf("le délai entre deux synchronisations ne peut pas être négatif (actuellement %d)", c.Synchro.DelaiSecondes)
+	}
+	if c.Synchroniser && len(c.Synchro.Pairs) == 0 {
+		return fmt.Errorf("-synchroniser demande au moins un poste pair (synchro.pairs ou %sPAIRS)", PREFIXE_ENV)
+	}
+
+	return nil
+}
+
+// Chemin retourne le chemin complet d'un fichier de données
+func (c *Config) Chemin(fichier string) string {
+	if filepath.IsAbs(fichier) {
+		return fichier
+	}
+	return filepath.Join(c.Donnees.Dossier, fichier)
+}
+
+func decouperListe(valeur string) []string {
+	var resultat []string
+	for _, element := range strings.Split(valeur, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			resultat = append(resultat, element)
+		}
+	}
+	return resultat
+}
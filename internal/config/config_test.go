@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ecrireFichier écrit un fichier de configuration dans un dossier temporaire
+func ecrireFichier(t *testing.T, contenu string) string {
+	t.Helper()
+
+	chemin := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(chemin, []byte(contenu), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return chemin
+}
+
+func TestOrdreDesSources(t *testing.T) {
+	// Le dossier courant ne doit pas fournir de config.json par défaut
+	t.Chdir(t.TempDir())
+
+	fichier := `{"donnees": {"dossier": "depuis-fichier"}, "emprunts": {"duree_jours": 21, "limite_simultanes": 5}, "interface": "menus"}`
+
+	cas := []struct {
+		nom     string
+		fichier string // contenu passé par -config, vide pour aucun fichier
+		env     map[string]string
+		args    []string
+
+		dossier string
+		duree   int
+		limite  int
+		ecran   string
+		genres  []string
+	}{
+		{
+			nom:     "valeurs par défaut",
+			dossier: "data", duree: 14, limite: 3, ecran: INTERFACE_PLEIN_ECRAN,
+		},
+		{
+			nom:     "le fichier remplace les valeurs par défaut qu'il contient",
+			fichier: fichier,
+			dossier: "depuis-fichier", duree: 21, limite: 5, ecran: INTERFACE_MENUS,
+		},
+		{
+			nom:     "l'environnement l'emporte sur le fichier",
+			fichier: fichier,
+			env:     map[string]string{"LIBRAIRIE_DONNEES": "depuis-env", "LIBRAIRIE_DUREE_EMPRUNT": " 28 ", "LIBRAIRIE_GENRES": "Roman, Poésie,,"},
+			dossier: "depuis-env", duree: 28, limite: 5, ecran: INTERFACE_MENUS, genres: []string{"Roman", "Poésie"},
+		},
+		{
+			nom:     "les options l'emportent sur l'environnement",
+			fichier: fichier,
+			env:     map[string]string{"LIBRAIRIE_DONNEES": "depuis-env", "LIBRAIRIE_DUREE_EMPRUNT": "28", "LIBRAIRIE_INTERFACE": "kiosque"},
+			args:    []string{"-donnees", "depuis-options", "-duree-emprunt", "7", "-genres", "Manga"},
+			dossier: "depuis-options", duree: 7, limite: 5, ecran: INTERFACE_KIOSQUE, genres: []string{"Manga"},
+		},
+		{
+			nom:     "une option non fournie ne remplace rien",
+			env:     map[string]string{"LIBRAIRIE_LIMITE_EMPRUNTS": "4"},
+			args:    []string{"-interface", "menus"},
+			dossier: "data", duree: 14, limite: 4, ecran: INTERFACE_MENUS,
+		},
+	}
+
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			for nom, valeur := range c.env {
+				t.Setenv(nom, valeur)
+			}
+			args := c.args
+			if c.fichier != "" {
+				args = append([]string{"-config", ecrireFichier(t, c.fichier)}, args...)
+			}
+
+			cfg, err := Charger(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Donnees.Dossier != c.dossier || cfg.Emprunts.DureeJours != c.duree ||
+				cfg.Emprunts.LimiteSimultanes != c.limite || cfg.Interface != c.ecran {
+				t.Errorf("dossier %q, durée %d, limite %d, interface %q ; attendu %q, %d, %d, %q",
+					cfg.Donnees.Dossier, cfg.Emprunts.DureeJours, cfg.Emprunts.LimiteSimultanes, cfg.Interface,
+					c.dossier, c.duree, c.limite, c.ecran)
+			}
+			genres := c.genres
+			if genres == nil {
+				genres = Defaut().Validation.Genres
+			}
+			if !reflect.DeepEqual(cfg.Validation.Genres, genres) {
+				t.Errorf("genres %v, attendu %v", cfg.Validation.Genres, genres)
+			}
+		})
+	}
+}
+
+func TestCheminDuFichier(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	parEnv := ecrireFichier(t, `{"emprunts": {"duree_jours": 10}}`)
+	parOption := ecrireFichier(t, `{"emprunts": {"duree_jours": 20}}`)
+	if err := os.WriteFile(FICHIER_CONFIG_DEFAUT, []byte(`{"emprunts": {"duree_jours": 30}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// -config l'emporte sur LIBRAIRIE_CONFIG, qui l'emporte sur config.json
+	etapes := []struct {
+		nom   string
+		env   string
+		args  []string
+		duree int
+	}{
+		{"config.json du dossier courant", "", nil, 30},
+		{"fichier de LIBRAIRIE_CONFIG", parEnv, nil, 10},
+		{"fichier de -config", parEnv, []string{"-config", parOption}, 20},
+	}
+	for _, e := range etapes {
+		if e.env != "" {
+			t.Setenv("LIBRAIRIE_CONFIG", e.env)
+		}
+		cfg, err := Charger(e.args)
+		if err != nil {
+			t.Fatalf("%s : %v", e.nom, err)
+		}
+		if cfg.Emprunts.DureeJours != e.duree {
+			t.Errorf("%s : durée %d, attendu %d", e.nom, cfg.Emprunts.DureeJours, e.duree)
+		}
+	}
+}
+
+func TestErreursDeChargement(t *testing.T) {
+	t.Chdir(t.TempDir())
+	absent := filepath.Join(t.TempDir(), "absent.json")
+
+	cas := []struct {
+		nom     string
+		fichier string // contenu passé par -config
+		env     map[string]string
+		args    []string
+		erreur  string
+	}{
+		{"fichier demandé par -config absent", "", nil, []string{"-config", absent}, "absent.json"},
+		{"fichier demandé par l'environnement absent", "", map[string]string{"LIBRAIRIE_CONFIG": absent}, nil, "absent.json"},
+		{"JSON invalide", "{invalide", nil, nil, "fichier de configuration"},
+		{"valeur du mauvais type", `{"emprunts": {"duree_jours": "deux"}}`, nil, nil, "fichier de configuration"},
+		{"entier invalide dans l'environnement", "", map[string]string{"LIBRAIRIE_DUREE_EMPRUNT": "deux"}, nil, "LIBRAIRIE_DUREE_EMPRUNT"},
+		{"option inconnue", "", nil, []string{"-inconnue"}, "inconnue"},
+		{"valeur refusée par la validation", "", nil, []string{"-duree-emprunt", "400"}, "configuration invalide"},
+		{"valeur du fichier refusée par la validation", `{"interface": "graphique"}`, nil, nil, "interface inconnue"},
+	}
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			for nom, valeur := range c.env {
+				t.Setenv(nom, valeur)
+			}
+			args := c.args
+			if c.fichier != "" {
+				args = append([]string{"-config", ecrireFichier(t, c.fichier)}, args...)
+			}
+
+			_, err := Charger(args)
+			if err == nil || !strings.Contains(err.Error(), c.erreur) {
+				t.Errorf("erreur %v, attendu une erreur contenant %q", err, c.erreur)
+			}
+		})
+	}
+}
+
+func TestValider(t *testing.T) {
+	cas := []struct {
+		nom      string
+		modifier func(c *Config)
+		erreur   string // vide si la configuration est valide
+	}{
+		{"valeurs par défaut", func(c *Config) {}, ""},
+		{"interface inconnue", func(c *Config) { c.Interface = "graphique" }, "interface inconnue"},
+		{"dossier vide", func(c *Config) { c.Donnees.Dossier = "  " }, "dossier des données"},
+		{"fichier de données vide", func(c *Config) { c.Donnees.Journal = "" }, "fichier des événements"},
+		{"durée nulle", func(c *Config) { c.Emprunts.DureeJours = 0 }, "durée d'emprunt"},
+		{"durée de plus d'un an", func(c *Config) { c.Emprunts.DureeJours = 366 }, "durée d'emprunt"},
+		{"durée d'un an", func(c *Config) { c.Emprunts.DureeJours = 365 }, ""},
+		{"limite nulle", func(c *Config) { c.Emprunts.LimiteSimultanes = 0 }, "limite d'emprunts"},
+		{"prolongations négatives", func(c *Config) { c.Emprunts.ProlongationsMax = -1 }, "prolongations"},
+		{"aucune prolongation", func(c *Config) { c.Emprunts.ProlongationsMax = 0 }, ""},
+		{"délai de retrait nul", func(c *Config) { c.Emprunts.DelaiRetraitJours = 0 }, "délai de retrait"},
+		{"aucun genre", func(c *Config) { c.Validation.Genres = nil }, "liste des genres"},
+		{"genre vide", func(c *Config) { c.Validation.Genres = []string{"Roman", " "} }, "entrée vide"},
+		{"genre en double à la casse près", func(c *Config) { c.Validation.Genres = []string{"Roman", "roman "} }, "plusieurs fois"},
+		{"année minimale nulle", func(c *Config) { c.Validation.AnneePublicationMin = 0 }, "année de publication"},
+		{"conservation négative", func(c *Config) { c.Conservation.MembresRadiesJours = -1 }, "conservation"},
+		{"format inconnu", func(c *Config) { c.Affichage.Format = "xml" }, "xml"},
+		{"largeur négative", func(c *Config) { c.Affichage.Largeur = -1 }, "largeur"},
+		{"symbologie inconnue", func(c *Config) { c.Etiquettes.Symbologie = "ean8" }, "symbologie"},
+		{"délai du libre-service trop court", func(c *Config) { c.Kiosque.DelaiSecondes = 9 }, "libre-service"},
+		{"portail sans adresse", func(c *Config) { c.Interface = INTERFACE_PORTAIL }, "adresse d'écoute"},
+		{"portail avec adresse", func(c *Config) { c.Interface, c.Portail.Adresse = INTERFACE_PORTAIL, ":8080" }, ""},
+		{"URL publique sans schéma", func(c *Config) { c.Portail.URLPublique = "bibliotheque.fr" }, "URL publique"},
+		{"session de durée nulle", func(c *Config) { c.Portail.DureeSessionMinutes = 0 }, "durées de session"},
+		{"synchronisation sans clé", func(c *Config) { c.Synchro.Adresse = ":8090" }, "clé partagée"},
+		{"clé trop courte", func(c *Config) { c.Synchro.Adresse, c.Synchro.Cle = ":8090", "courte" }, "clé partagée"},
+		{"pair sans schéma", func(c *Config) {
+			c.Synchro.Cle, c.Synchro.Pairs = "une clé assez longue", []string{"comptoir-2:8090"}
+		}, "poste pair"},
+		{"synchronisation complète", func(c *Config) {
+			c.Synchro.Cle, c.Synchro.Pairs = "une clé assez longue", []string{"http://comptoir-2:8090"}
+		}, ""},
+		{"délai de synchronisation négatif", func(c *Config) { c.Synchro.DelaiSecondes = -1 }, "synchronisations"},
+		{"-synchroniser sans pair", func(c *Config) { c.Synchroniser = true }, "poste pair"},
+	}
+
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			cfg := Defaut()
+			c.modifier(cfg)
+			err := cfg.Valider()
+			switch {
+			case c.erreur == "" && err != nil:
+				t.Errorf("configuration refusée : %v", err)
+			case c.erreur != "" && (err == nil || !strings.Contains(err.Error(), c.erreur)):
+				t.Errorf("erreur %v, attendu une erreur contenant %q", err, c.erreur)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// ==========================================
+// internal/conseils/bon_de_commande.go
+// RAPPORT ET BON DE COMMANDE
+// ==========================================
+
+package conseils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// LibelleType retourne le libellé français d'un type de suggestion
+func LibelleType(typeSuggestion string) string {
+	switch typeSuggestion {
+	case SUGGESTION_EXEMPLAIRE:
+		return "Exemplaire supplémentaire"
+	case SUGGESTION_AUTEUR:
+		return "Même auteur"
+	case SUGGESTION_GENRE:
+		return "Même genre"
+	case SUGGESTION_DEMANDE:
+		return "Demande non satisfaite"
+	}
+	return typeSuggestion
+}
+
+// BonDeCommande met les suggestions en tableau ; les colonnes de prix restent
+// vides, à compléter par l'acheteur avec le devis du fournisseur
+func (c Conseils) BonDeCommande() *affichage.Tableau {
+	tableau := affichage.NouveauTableau(
+		affichage.Colonne{Titre: "Rang", Cle: "rang", Numerique: true},
+		affichage.Colonne{Titre: "Type", Cle: "type"},
+		affichage.Colonne{Titre: "Titre", Cle: "titre", Min: 20},
+		affichage.Colonne{Titre: "Auteur", Cle: "auteur", Min: 12},
+		affichage.Colonne{Titre: "ISBN", Cle: "isbn"},
+		affichage.Colonne{Titre: "Genre", Cle: "genre"},
+		affichage.Colonne{Titre: "Quantité", Cle: "quantite", Numerique: true},
+		affichage.Colonne{Titre: "Prix unitaire", Cle: "prix_unitaire", Numerique: true},
+		affichage.Colonne{Titre: "Montant", Cle: "montant", Numerique: true},
+		affichage.Colonne{Titre: "Motif", Cle: "motif"},
+	)
+	for _, s := range c.Suggestions {
+		tableau.AjouterLigne(strconv.Itoa(s.Rang), LibelleType(s.Type), s.Titre, s.Auteur, s.ISBN, s.Genre,
+			strconv.Itoa(s.Quantite), "", "", strings.Join(s.Explications, " ; "))
+	}
+	return tableau
+}
+
+// EcrireRapport écrit le rapport du conseiller en Markdown
+func (c Conseils) EcrireRapport(w io.Writer) error {
+	fmt.Fprintf(w, "# Conseils d'achat\n\n")
+	fmt.Fprintf(w, "Établis le %s. Rotation moyenne du fonds : %.1f emprunt(s) par livre sur 12 mois.\n\n",
+		c.GenereLe.Format("02/01/2006 à 15:04"), c.RotationMoyenne)
+
+	if len(c.Suggestions) == 0 {
+		_, err := fmt.Fprintln(w, "Aucun signal de demande : aucun achat n'est suggéré.")
+		return err
+	}
+
+	for _, s := range c.Suggestions {
+		fmt.Fprintf(w, "## %d. %s\n\n", s.Rang, s.Titre)
+		fmt.Fprintf(w, "- **%s**, quantité suggérée : %d (score %.1f)\n", LibelleType(s.Type), s.Quantite, s.Score)
+		if s.Auteur != "" && s.Type == SUGGESTION_EXEMPLAIRE {
+			fmt.Fprintf(w, "- Auteur : %s\n", s.Auteur)
+		}
+		if s.ISBN != "" {
+			fmt.Fprintf(w, "- ISBN : %s\n", s.ISBN)
+		}
+		for _, explication := range s.Explications {
+			fmt.Fprintf(w, "- %s\n", explication)
+		}
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprintf(w, "_Le bon de commande (CSV) reprend ces lignes ; les prix sont à compléter._\n")
+	return err
+}
+
+// Enregistrer écrit conseils-achat-<horodatage>.md et le bon de commande .csv
+// dans le dossier, et retourne les chemins des fichiers créés
+func (c Conseils) Enregistrer(dossier string) ([]string, error) {
+	if err := os.MkdirAll(dossier, 0755); err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier %s : %v", dossier, err)
+	}
+
+	csv, err := affichage.NouveauFormat(affichage.FORMAT_CSV, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(dossier, "conseils-achat-"+c.GenereLe.Format("20060102-150405"))
+	ecrivains := []struct {
+		extension string
+		ecrire    func(*os.File) error
+	}{
+		{".md", func(f *os.File) error { return c.EcrireRapport(f) }},
+		{".csv", func(f *os.File) error { return csv.Rendre(f, c.BonDeCommande()) }},
+	}
+
+	var chemins []string
+	for _, ecrivain := range ecrivains {
+		chemin := base + ecrivain.extension
+		fichier, err := os.Create(chemin)
+		if err != nil {
+			return chemins, fmt.Errorf("impossible de créer %s : %v", chemin, err)
+		}
+		err = ecrivain.ecrire(fichier)
+		if errFermeture := fichier.Close(); err == nil {
+			err = errFermeture
+		}
+		if err != nil {
+			return chemins, fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+		}
+		chemins = append(chemins, chemin)
+	}
+	return chemins, nil
+}
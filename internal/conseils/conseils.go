@@ -0,0 +1,307 @@
+// ==========================================
+// internal/conseils/conseils.go
+// CONSEILLER D'ACHAT
+// ==========================================
+
+package conseils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/analyses"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// ========================================
+// SIGNAUX DE DEMANDE
+// Quatre signaux sont combinés : les files d'attente des réservations, les titres
+// qui ne sont presque jamais en rayon, la rotation des auteurs et des genres, et
+// les recherches du catalogue restées sans résultat.
+// ========================================
+
+const (
+	SUGGESTION_EXEMPLAIRE = "exemplaire" // exemplaire(s) supplémentaire(s) d'un titre du fonds
+	SUGGESTION_AUTEUR     = "auteur"     // d'autres titres d'un auteur très emprunté
+	SUGGESTION_GENRE      = "genre"      // d'autres titres d'un genre très emprunté
+	SUGGESTION_DEMANDE    = "demande"    // un livre recherché que le fonds n'a pas
+)
+
+const (
+	// JOURS_OCCUPATION est la fenêtre sur laquelle on regarde si un titre est en rayon
+	JOURS_OCCUPATION = 90
+	// SEUIL_JAMAIS_DISPONIBLE : au-delà, le titre n'est pour ainsi dire jamais en rayon
+	SEUIL_JAMAIS_DISPONIBLE = 0.9
+	// JOURS_OBSERVATION_MIN : un titre tout juste arrivé n'a pas encore d'occupation mesurable
+	JOURS_OBSERVATION_MIN = 30
+	// RESERVATIONS_PAR_EXEMPLAIRE est la file d'attente jugée acceptable pour un exemplaire
+	RESERVATIONS_PAR_EXEMPLAIRE = 2
+	// QUANTITE_MAX plafonne les exemplaires suggérés pour un même titre
+	QUANTITE_MAX = 5
+	// RATIO_ROTATION : un auteur ou un genre est signalé quand sa rotation atteint
+	// ce multiple de la rotation moyenne du fonds
+	RATIO_ROTATION = 1.5
+	// EMPRUNTS_MIN évite de signaler un auteur ou un genre sur deux ou trois emprunts
+	EMPRUNTS_MIN = 5
+	// RECHERCHES_MIN écarte les fautes de frappe isolées
+	RECHERCHES_MIN = 2
+)
+
+// Conseils est la liste classée des achats suggérés
+type Conseils struct {
+	GenereLe        time.Time    `json:"genere_le"`
+	RotationMoyenne float64      `json:"rotation_moyenne"` // emprunts par livre sur 12 mois, tout le fonds
+	Suggestions     []Suggestion `json:"suggestions"`
+}
+
+// Suggestion est une ligne du bon de commande, avec les raisons qui la justifient
+type Suggestion struct {
+	Rang         int      `json:"rang"`
+	Type         string   `json:"type"`
+	Titre        string   `json:"titre"` // titre du livre, ou description de ce qu'il faut chercher
+	Auteur       string   `json:"auteur,omitempty"`
+	ISBN         string   `json:"isbn,omitempty"`
+	Genre        string   `json:"genre,omitempty"`
+	Quantite     int      `json:"quantite"`
+	Score        float64  `json:"score"`
+	Explications []string `json:"explications"`
+}
+
+// Conseiller rassemble les signaux de demande et classe les suggestions,
+// de la plus urgente à la moins urgente
+func Conseiller(gl *services.GestionnaireLivres, ge *services.GestionnaireEmprunts, gr *services.GestionnaireReservations, grc *services.GestionnaireRecherches) Conseils {
+	maintenant := time.Now()
+	catalogue := gl.ListerLivres()
+	emprunts := ge.ListerEmprunts()
+	annee := statistiques.DouzeMois(maintenant)
+
+	// Emprunts de l'année par livre du catalogue
+	empruntsAnnee := make(map[int]int)
+	total := 0
+	for _, emprunt := range emprunts {
+		if annee.Contient(emprunt.DateEmprunt) {
+			empruntsAnnee[emprunt.LivreID]++
+			total++
+		}
+	}
+
+	conseils := Conseils{GenereLe: maintenant}
+	if len(catalogue) > 0 {
+		conseils.RotationMoyenne = float64(total) / float64(len(catalogue))
+	}
+
+	var suggestions []Suggestion
+	suggestions = append(suggestions, suggererExemplaires(maintenant, catalogue, emprunts, empruntsAnnee, gr)...)
+	suggestions = append(suggestions, suggererSimilaires(SUGGESTION_AUTEUR, catalogue, empruntsAnnee, conseils.RotationMoyenne, func(l models.Livre) string { return l.Auteur })...)
+	suggestions = append(suggestions, suggererSimilaires(SUGGESTION_GENRE, catalogue, empruntsAnnee, conseils.RotationMoyenne, func(l models.Livre) string { return l.Genre })...)
+	suggestions = append(suggestions, suggererDemandes(gl, grc)...)
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Titre < suggestions[j].Titre
+	})
+	for i := range suggestions {
+		suggestions[i].Rang = i + 1
+	}
+	conseils.Suggestions = suggestions
+	return conseils
+}
+
+// ========================================
+// EXEMPLAIRES SUPPLÉMENTAIRES
+// ========================================
+
+// titre regroupe les exemplaires d'un même titre (même ISBN)
+type titre struct {
+	livre       models.Livre // premier exemplaire, pour l'affichage
+	exemplaires int
+	file        int
+	joursPret   float64 // jours d'emprunt dans la fenêtre, tous exemplaires
+	joursOuvert float64 // jours où les exemplaires étaient au catalogue dans la fenêtre
+	emprunts    int     // sur 12 mois
+}
+
+func suggererExemplaires(maintenant time.Time, catalogue []models.Livre, emprunts []models.Emprunt, empruntsAnnee map[int]int, gr *services.GestionnaireReservations) []Suggestion {
+	debutFenetre := maintenant.AddDate(0, 0, -JOURS_OCCUPATION)
+
+	titres := make(map[string]*titre)
+	var ordre []string
+	cleDuLivre := make(map[int]string, len(catalogue))
+	for _, livre := range catalogue {
+		cle := analyses.CleTitre(livre)
+		cleDuLivre[livre.ID] = cle
+		if titres[cle] == nil {
+			titres[cle] = &titre{livre: livre}
+			ordre = append(ordre, cle)
+		}
+		t := titres[cle]
+		t.exemplaires++
+		t.file += len(gr.FileAttente(livre.ID))
+		t.emprunts += empruntsAnnee[livre.ID]
+
+		// Un exemplaire arrivé pendant la fenêtre ne compte qu'à partir de son arrivée
+		t.joursOuvert += joursEntre(plusTard(debutFenetre, livre.DateAjout), maintenant)
+	}
+
+	for _, emprunt := range emprunts {
+		cle, ok := cleDuLivre[emprunt.LivreID]
+		if !ok {
+			continue
+		}
+		retour := maintenant
+		if emprunt.DateRetourEffectif != nil {
+			retour = *emprunt.DateRetourEffectif
+		}
+		titres[cle].joursPret += joursEntre(plusTard(debutFenetre, emprunt.DateEmprunt), retour)
+	}
+
+	var suggestions []Suggestion
+	for _, cle := range ordre {
+		t := titres[cle]
+		occupation := 0.0
+		if t.joursOuvert >= JOURS_OBSERVATION_MIN {
+			occupation = min(t.joursPret/t.joursOuvert, 1)
+		}
+		jamaisDisponible := occupation >= SEUIL_JAMAIS_DISPONIBLE
+		if t.file == 0 && !jamaisDisponible {
+			continue
+		}
+
+		suggestion := Suggestion{
+			Type:     SUGGESTION_EXEMPLAIRE,
+			Titre:    t.livre.Titre,
+			Auteur:   t.livre.Auteur,
+			ISBN:     t.livre.ISBN,
+			Genre:    t.livre.Genre,
+			Quantite: min(max(1, int(math.Ceil(float64(t.file)/RESERVATIONS_PAR_EXEMPLAIRE))), QUANTITE_MAX),
+			Score:    3*float64(t.file) + 10*occupation + float64(t.emprunts)/float64(t.exemplaires),
+		}
+		if t.file > 0 {
+			suggestion.Explications = append(suggestion.Explications,
+				fmt.Sprintf("%d réservation(s) en attente pour %d exemplaire(s)", t.file, t.exemplaires))
+		}
+		if jamaisDisponible {
+			suggestion.Explications = append(suggestion.Explications,
+				fmt.Sprintf("jamais disponible : emprunté %.0f %% du temps sur les %d derniers jours", occupation*100, JOURS_OCCUPATION))
+		}
+		suggestion.Explications = append(suggestion.Explications,
+			fmt.Sprintf("%d emprunt(s) sur 12 mois, soit %.1f par exemplaire", t.emprunts, float64(t.emprunts)/float64(t.exemplaires)))
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions
+}
+
+// plusTard retourne la plus récente des deux dates
+func plusTard(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func joursEntre(debut, fin time.Time) float64 {
+	if !fin.After(debut) {
+		return 0
+	}
+	return fin.Sub(debut).Hours() / 24
+}
+
+// ========================================
+// TITRES SIMILAIRES (AUTEUR, GENRE)
+// ========================================
+
+// suggererSimilaires signale les auteurs (ou les genres) dont les livres sortent
+// nettement plus que la moyenne : d'autres titres du même auteur (ou genre)
+// trouveront probablement leurs lecteurs
+func suggererSimilaires(typeSuggestion string, catalogue []models.Livre, empruntsAnnee map[int]int, moyenne float64, critere func(models.Livre) string) []Suggestion {
+	if moyenne == 0 {
+		return nil
+	}
+
+	type groupe struct {
+		livres       int
+		emprunts     int
+		plusEmprunte models.Livre
+	}
+	groupes := make(map[string]*groupe)
+	var ordre []string
+	for _, livre := range catalogue {
+		nom := critere(livre)
+		if nom == "" {
+			continue
+		}
+		if groupes[nom] == nil {
+			groupes[nom] = &groupe{plusEmprunte: livre}
+			ordre = append(ordre, nom)
+		}
+		g := groupes[nom]
+		g.livres++
+		g.emprunts += empruntsAnnee[livre.ID]
+		if empruntsAnnee[livre.ID] > empruntsAnnee[g.plusEmprunte.ID] {
+			g.plusEmprunte = livre
+		}
+	}
+
+	var suggestions []Suggestion
+	for _, nom := range ordre {
+		g := groupes[nom]
+		rotation := float64(g.emprunts) / float64(g.livres)
+		ratio := rotation / moyenne
+		if g.emprunts < EMPRUNTS_MIN || ratio < RATIO_ROTATION {
+			continue
+		}
+
+		suggestion := Suggestion{
+			Type:     typeSuggestion,
+			Quantite: min(int(ratio), QUANTITE_MAX),
+			Score:    2*ratio + float64(g.emprunts)/10,
+			Explications: []string{
+				fmt.Sprintf("%.1f emprunts par livre sur 12 mois, %.1f fois la moyenne du fonds (%.1f)", rotation, ratio, moyenne),
+				fmt.Sprintf("%d livre(s) au catalogue, le plus emprunté : « %s »", g.livres, g.plusEmprunte.Titre),
+			},
+		}
+		if typeSuggestion == SUGGESTION_AUTEUR {
+			suggestion.Titre = "Autres titres de " + nom
+			suggestion.Auteur = nom
+		} else {
+			suggestion.Titre = "Autres titres du genre " + nom
+			suggestion.Genre = nom
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions
+}
+
+// ========================================
+// RECHERCHES SANS RÉSULTAT
+// ========================================
+
+func suggererDemandes(gl *services.GestionnaireLivres, grc *services.GestionnaireRecherches) []Suggestion {
+	var suggestions []Suggestion
+	for _, recherche := range grc.ListerRecherchesInfructueuses() {
+		if recherche.Nombre < RECHERCHES_MIN {
+			continue
+		}
+		// Le livre a pu entrer au catalogue depuis
+		if len(gl.RechercherLivres(recherche.Terme)) > 0 {
+			continue
+		}
+
+		explication := fmt.Sprintf("recherché %d fois sans résultat, du %s au %s", recherche.Nombre,
+			recherche.Premiere.Format("02/01/2006"), recherche.Derniere.Format("02/01/2006"))
+		suggestions = append(suggestions, Suggestion{
+			Type:         SUGGESTION_DEMANDE,
+			Titre:        fmt.Sprintf("Livre recherché : « %s »", recherche.Terme),
+			Quantite:     1,
+			Score:        3 * float64(recherche.Nombre),
+			Explications: []string{explication},
+		})
+	}
+	return suggestions
+}
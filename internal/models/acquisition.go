@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Montant est une somme en centimes d'euro, pour que les totaux tombent juste
+type Montant int64
+
+func (m Montant) String() string {
+	signe := ""
+	if m < 0 {
+		signe, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d,%02d €", signe, m/100, m%100)
+}
+
+// LireMontant interprète une saisie en euros : "12", "12,5", "12.50" ou "12,50 €"
+func LireMontant(saisie string) (Montant, error) {
+	nettoye := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(saisie), "€"))
+	nettoye = strings.ReplaceAll(strings.ReplaceAll(nettoye, " ", ""), ",", ".")
+
+	if _, decimales, ok := strings.Cut(nettoye, "."); ok && len(decimales) > 2 {
+		return 0, fmt.Errorf("le montant '%s' a plus de deux décimales", saisie)
+	}
+	euros, err := strconv.ParseFloat(nettoye, 64)
+	if err != nil || euros < 0 || math.IsInf(euros, 0) || math.IsNaN(euros) {
+		return 0, fmt.Errorf("le montant '%s' est invalide (exemple : 12,50)", saisie)
+	}
+	return Montant(math.Round(euros * 100)), nil
+}
+
+// Fournisseur est une librairie, un grossiste ou un éditeur auprès de qui on commande
+type Fournisseur struct {
+	ID        int    `json:"id"`
+	Nom       string `json:"nom"`
+	Email     string `json:"email"`
+	Telephone string `json:"telephone"`
+}
+
+// Budget est l'enveloppe d'un fonds pour une année. Un fonds rattaché à un genre
+// paie les livres de ce genre ; un fonds sans genre paie tous les autres.
+type Budget struct {
+	ID      int     `json:"id"`
+	Fonds   string  `json:"fonds"`
+	Genre   string  `json:"genre"` // nom canonique du genre, vide = tous genres
+	Annee   int     `json:"annee"`
+	Montant Montant `json:"montant"`
+}
+
+const (
+	COMMANDE_BROUILLON = "brouillon" // en préparation, modifiable
+	COMMANDE_PASSEE    = "passee"    // envoyée au fournisseur, rien n'est encore arrivé
+	COMMANDE_PARTIELLE = "partielle" // une partie des livres est arrivée
+	COMMANDE_RECUE     = "recue"     // tous les livres sont arrivés
+	COMMANDE_SOLDEE    = "soldee"    // close avant d'avoir tout reçu, le reliquat est abandonné
+	COMMANDE_ANNULEE   = "annulee"   // close sans rien avoir reçu
+)
+
+// Commande est un bon de commande adressé à un fournisseur
+type Commande struct {
+	ID             int             `json:"id"`
+	FournisseurID  int             `json:"fournisseur_id"`
+	NomFournisseur string          `json:"nom_fournisseur"`
+	Statut         string          `json:"statut"`
+	DateCreation   time.Time       `json:"date_creation"`
+	DatePassee     time.Time       `json:"date_passee,omitzero"`
+	DateCloture    time.Time       `json:"date_cloture,omitzero"`
+	Lignes         []LigneCommande `json:"lignes"`
+}
+
+// LigneCommande commande un titre en un ou plusieurs exemplaires. Le titre,
+// l'auteur, le genre et la date de publication servent à créer la fiche du
+// livre à la réception quand l'ISBN n'est pas encore au catalogue.
+type LigneCommande struct {
+	Numero          int       `json:"numero"`
+	ISBN            string    `json:"isbn"`
+	Titre           string    `json:"titre"`
+	Auteur          string    `json:"auteur"`
+	Genre           string    `json:"genre"`
+	DatePublication time.Time `json:"date_publication,omitzero"`
+	Quantite        int       `json:"quantite"`
+	PrixUnitaire    Montant   `json:"prix_unitaire"`
+	BudgetID        int       `json:"budget_id"` // 0 = hors budget
+
+	Receptions []Reception `json:"receptions"`
+}
+
+// Reception enregistre une livraison partielle ou complète d'une ligne
+type Reception struct {
+	Date     time.Time `json:"date"`
+	Quantite int       `json:"quantite"`
+	LivreIDs []int     `json:"livre_ids"` // exemplaires créés au catalogue
+}
+
+// Acquisition garde sur le livre la trace de sa provenance et de son prix
+type Acquisition struct {
+	CommandeID  int       `json:"commande_id"`
+	Fournisseur string    `json:"fournisseur"`
+	Prix        Montant   `json:"prix"`
+	BudgetID    int       `json:"budget_id"`
+	Date        time.Time `json:"date"`
+}
+
+// LibelleStatutCommande retourne le libellé français d'un statut de commande
+func LibelleStatutCommande(statut string) string {
+	switch statut {
+	case COMMANDE_BROUILLON:
+		return "📝 En préparation"
+	case COMMANDE_PASSEE:
+		return "✉️  Passée"
+	case COMMANDE_PARTIELLE:
+		return "📦 Reçue en partie"
+	case COMMANDE_RECUE:
+		return "✅ Reçue"
+	case COMMANDE_SOLDEE:
+		return "☑️  Soldée"
+	case COMMANDE_ANNULEE:
+		return "❌ Annulée"
+	}
+	return statut
+}
+
+// EnAttente indique si la commande attend encore des livres du fournisseur
+func (c Commande) EnAttente() bool {
+	return c.Statut == COMMANDE_PASSEE || c.Statut == COMMANDE_PARTIELLE
+}
+
+// Total est le montant de la commande, reliquat compris
+func (c Commande) Total() Montant {
+	var total Montant
+	for _, ligne := range c.Lignes {
+		total += ligne.Total()
+	}
+	return total
+}
+
+// Total est le montant de la ligne
+func (l LigneCommande) Total() Montant {
+	return Montant(l.Quantite) * l.PrixUnitaire
+}
+
+// Recus compte les exemplaires déjà livrés
+func (l LigneCommande) Recus() int {
+	recus := 0
+	for _, reception := range l.Receptions {
+		recus += reception.Quantite
+	}
+	return recus
+}
+
+// Restant compte les exemplaires encore attendus
+func (l LigneCommande) Restant() int {
+	return l.Quantite - l.Recus()
+}
+
+func (a Acquisition) String() string {
+	return fmt.Sprintf("%s, %s (commande #%d)", a.Fournisseur, a.Prix, a.CommandeID)
+}
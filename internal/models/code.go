@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Préfixes des codes imprimés sur les étiquettes et les cartes.
+// Un code généré est le préfixe suivi de l'ID sur six chiffres : LIV000042, MEM000007.
+// Sur un poste qui se synchronise, le repère du poste s'intercale : LIVK3QF-000042.
+const (
+	PREFIXE_CODE_LIVRE   = "LIV"
+	PREFIXE_CARTE_MEMBRE = "MEM"
+
+	LONGUEUR_REPERE = 4 // caractères du repère d'un poste
+)
+
+// CodeLivre retourne le code-barres attribué par défaut à un livre
+func CodeLivre(id int) string {
+	return fmt.Sprintf("%s%06d", PREFIXE_CODE_LIVRE, id)
+}
+
+// NumeroCarte retourne le numéro de carte attribué par défaut à un membre
+func NumeroCarte(id int) string {
+	return fmt.Sprintf("%s%06d", PREFIXE_CARTE_MEMBRE, id)
+}
+
+// CodeGenere retourne le code attribué par défaut sur un poste. Sans repère,
+// c'est celui d'un poste isolé (CodeLivre, NumeroCarte) ; avec le repère, deux
+// postes ne peuvent pas générer le même code pour deux livres ou membres différents.
+func CodeGenere(prefixe, repere string, id int) string {
+	if repere == "" {
+		return fmt.Sprintf("%s%06d", prefixe, id)
+	}
+	return fmt.Sprintf("%s%s-%06d", prefixe, repere, id)
+}
+
+// ReperePoste retourne le repère d'un poste : les derniers caractères de son
+// identifiant (un ULID), tirés au hasard à sa création
+func ReperePoste(instance string) string {
+	return strings.ToUpper(instance[max(len(instance)-LONGUEUR_REPERE, 0):])
+}
+
+// EstCodeAutomatique indique si le code a la forme d'un code généré : le préfixe,
+// éventuellement le repère d'un poste (quatre lettres ou chiffres) et un tiret,
+// puis des chiffres. Cette plage
+// est réservée à la numérotation automatique, pour qu'un code repris à la main
+// ne soit jamais celui d'un futur livre ou membre, ici ou sur un autre poste.
+func EstCodeAutomatique(code, prefixe string) bool {
+	reste, ok := strings.CutPrefix(code, prefixe)
+	if !ok {
+		return false
+	}
+	if repere, chiffres, avecRepere := strings.Cut(reste, "-"); avecRepere {
+		if len(repere) != LONGUEUR_REPERE || strings.IndexFunc(repere, func(r rune) bool {
+			return (r < '0' || r > '9') && (r < 'A' || r > 'Z')
+		}) >= 0 {
+			return false
+		}
+		reste = chiffres
+	}
+	if reste == "" {
+		return false
+	}
+	for _, r := range reste {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormaliserCode nettoie une saisie de douchette : les scanners en émulation clavier
+// peuvent envoyer des caractères de contrôle (tabulation, CR) et un préfixe
+// d'identification AIM (« ]C0 », « ]Q1 »...) avant le code lui-même.
+func NormaliserCode(saisie string) string {
+	code := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, saisie)
+	code = strings.TrimSpace(code)
+
+	if len(code) > 3 && code[0] == ']' {
+		code = code[3:]
+	}
+	return strings.ToUpper(strings.TrimSpace(code))
+}
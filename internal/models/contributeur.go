@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Contributeur représente une personne ayant participé à un livre
+// (auteur, traducteur, illustrateur...)
+type Contributeur struct {
+	ID        int      `json:"id"`
+	Nom       string   `json:"nom"`       // Forme d'affichage : "J.R.R. Tolkien"
+	CleTri    string   `json:"cle_tri"`   // Forme de classement : "Tolkien, J.R.R."
+	Variantes []string `json:"variantes"` // Autres graphies : "John Ronald Reuel Tolkien"
+}
+
+// Contribution relie un livre à un contributeur avec un rôle
+type Contribution struct {
+	ContributeurID int    `json:"contributeur_id"`
+	Role           string `json:"role"`
+}
+
+const (
+	ROLE_AUTEUR       = "auteur"
+	ROLE_TRADUCTEUR   = "traducteur"
+	ROLE_ILLUSTRATEUR = "illustrateur"
+	ROLE_PREFACIER    = "prefacier"
+	ROLE_DIRECTEUR    = "directeur"
+)
+
+// ROLES_CONTRIBUTION liste les rôles acceptés, dans l'ordre d'affichage
+var ROLES_CONTRIBUTION = []string{ROLE_AUTEUR, ROLE_TRADUCTEUR, ROLE_ILLUSTRATEUR, ROLE_PREFACIER, ROLE_DIRECTEUR}
+
+func (c Contributeur) String() string {
+	return fmt.Sprintf("ID: %d | %s | tri : %s", c.ID, c.Nom, c.CleTri)
+}
+
+// Correspond indique si le nom désigne ce contributeur (nom ou variante, sans tenir compte de la casse)
+func (c Contributeur) Correspond(nom string) bool {
+	nom = strings.TrimSpace(nom)
+	if strings.EqualFold(c.Nom, nom) {
+		return true
+	}
+	for _, variante := range c.Variantes {
+		if strings.EqualFold(variante, nom) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contient indique si le terme apparaît dans le nom, la clé de tri ou une variante
+func (c Contributeur) Contient(terme string) bool {
+	terme = strings.ToLower(strings.TrimSpace(terme))
+	if strings.Contains(strings.ToLower(c.Nom), terme) || strings.Contains(strings.ToLower(c.CleTri), terme) {
+		return true
+	}
+	for _, variante := range c.Variantes {
+		if strings.Contains(strings.ToLower(variante), terme) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleTriParDefaut construit une clé "Nom, Prénom" à partir d'un nom d'affichage
+func CleTriParDefaut(nom string) string {
+	mots := strings.Fields(nom)
+	if len(mots) < 2 {
+		return strings.TrimSpace(nom)
+	}
+	return mots[len(mots)-1] + ", " + strings.Join(mots[:len(mots)-1], " ")
+}
+
+// LibelleRole retourne le libellé français d'un rôle
+func LibelleRole(role string) string {
+	switch role {
+	case ROLE_AUTEUR:
+		return "Auteur"
+	case ROLE_TRADUCTEUR:
+		return "Traducteur"
+	case ROLE_ILLUSTRATEUR:
+		return "Illustrateur"
+	case ROLE_PREFACIER:
+		return "Préfacier"
+	case ROLE_DIRECTEUR:
+		return "Directeur de publication"
+	}
+	return role
+}
@@ -2,28 +2,35 @@ package models
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
 )
 
 type Emprunt struct {
 	ID                 int        `json:"id"`
+	UID                string     `json:"uid,omitempty"` // Identifiant unique (ULID), le même sur tous les postes
 	LivreID            int        `json:"livre_id"`
 	MembreID           int        `json:"membre_id"`
 	DateEmprunt        time.Time  `json:"date_emprunt"`
 	DateRetourPrevu    time.Time  `json:"date_retour_prevu"`
 	DateRetourEffectif *time.Time `json:"date_retour_effectif"`
 	Statut             string     `json:"statut"`
+	Prolongations      int        `json:"prolongations,omitempty"`
+	SuccursaleID       int        `json:"succursale_id,omitempty"`     // Succursale où le livre a été emprunté
+	SuccursaleRetour   int        `json:"succursale_retour,omitempty"` // Succursale où il a été rendu
 
 	TitreLivre string `json:"titre_livre"`
 	NomMembre  string `json:"nom_membre"`
 }
 
 const (
-	DUREE_EMPRUMT_JOURS = 14
-	STATUT_EN_COURS     = "en-cours"
-	STATUT_RENDU        = "rendu"
-	STATUT_EN_RETARD    = "en-retard"
+	STATUT_EN_COURS  = "en-cours"
+	STATUT_RENDU     = "rendu"
+	STATUT_EN_RETARD = "en-retard"
+	STATUT_PERDU     = "perdu" // clos sans retour : le livre a été déclaré perdu
 )
 
 func (e Emprunt) String() string {
@@ -37,6 +44,8 @@ func (e Emprunt) String() string {
 		statutEmoji = "📘 Rendu"
 	case STATUT_EN_RETARD:
 		statutEmoji = "⚠️ En retard"
+	case STATUT_PERDU:
+		statutEmoji = "❌ Perdu"
 	}
 
 	return fmt.Sprintf("ID: %d | %s par %s | Emprunté le %s | %s",
@@ -45,21 +54,23 @@ func (e Emprunt) String() string {
 
 }
 
-// AfficherDetails() montre toutes les informations d'un emprunt
-func (e Emprunt) AfficherDetails() {
-	fmt.Printf("┌%s┐\n", strings.Repeat("─", 70))
-	fmt.Printf("│ Emprunt #%d%s│\n", e.ID, strings.Repeat(" ", 70-len(fmt.Sprintf(" Emprunt #%d", e.ID))))
-	fmt.Printf("├%s┤\n", strings.Repeat("─", 70))
-	fmt.Printf("│ Livre         : %-50s │\n", e.TitreLivre)
-	fmt.Printf("│ Membre        : %-50s │\n", e.NomMembre)
-	fmt.Printf("│ Emprunté le   : %-50s │\n", e.DateEmprunt.Format("02/01/2006 15:04:05"))
-	fmt.Printf("│ À rendre le   : %-50s │\n", e.DateRetourPrevu.Format("02/01/2006"))
+// AfficherDetails() écrit toutes les informations d'un emprunt
+func (e Emprunt) AfficherDetails(w io.Writer) {
+	fmt.Fprintf(w, "┌%s┐\n", strings.Repeat("─", 70))
+	fmt.Fprintf(w, "│ Emprunt #%d%s│\n", e.ID, strings.Repeat(" ", 70-len(fmt.Sprintf(" Emprunt #%d", e.ID))))
+	fmt.Fprintf(w, "├%s┤\n", strings.Repeat("─", 70))
+	fmt.Fprintf(w, "│ Livre         : %s │\n", affichage.Ajuster(e.TitreLivre, 52))
+	fmt.Fprintf(w, "│ Membre        : %s │\n", affichage.Ajuster(e.NomMembre, 52))
+	fmt.Fprintf(w, "│ Emprunté le   : %s │\n", affichage.Ajuster(e.DateEmprunt.Format("02/01/2006 15:04:05"), 52))
+	fmt.Fprintf(w, "│ À rendre le   : %s │\n", affichage.Ajuster(e.DateRetourPrevu.Format("02/01/2006"), 52))
 
 	// Affichage conditionnel de la date de retour effectif
-	if e.DateRetourEffectif != nil {
-		fmt.Printf("│ Rendu le      : %-50s │\n", e.DateRetourEffectif.Format("02/01/2006 15:04:05"))
+	if e.EstPerdu() {
+		fmt.Fprintf(w, "│ Perdu le      : %s │\n", affichage.Ajuster(e.DateRetourEffectif.Format("02/01/2006 15:04:05"), 52))
+	} else if e.DateRetourEffectif != nil {
+		fmt.Fprintf(w, "│ Rendu le      : %s │\n", affichage.Ajuster(e.DateRetourEffectif.Format("02/01/2006 15:04:05"), 52))
 	} else {
-		fmt.Printf("│ Rendu le      : %-50s │\n", "Pas encore rendu")
+		fmt.Fprintf(w, "│ Rendu le      : %s │\n", affichage.Ajuster("Pas encore rendu", 52))
 	}
 
 	// Afficher le statut avec des emojis
@@ -71,16 +82,28 @@ func (e Emprunt) AfficherDetails() {
 		statutAffichage = "✅ Rendu"
 	case STATUT_EN_RETARD:
 		statutAffichage = "⚠️ En retard"
+	case STATUT_PERDU:
+		statutAffichage = "❌ Perdu"
 	}
-	fmt.Printf("│ Statut        : %-50s │\n", statutAffichage)
+	fmt.Fprintf(w, "│ Statut        : %s │\n", affichage.Ajuster(statutAffichage, 52))
 
 	// Calculer et afficher les jours de retard s'il y en a
 	if e.Statut == STATUT_EN_RETARD {
 		joursRetard := int(time.Since(e.DateRetourPrevu).Hours() / 24)
-		fmt.Printf("│ Retard        : %-50s │\n", fmt.Sprintf("%d jour(s)", joursRetard))
+		fmt.Fprintf(w, "│ Retard        : %s │\n", affichage.Ajuster(fmt.Sprintf("%d jour(s)", joursRetard), 52))
 	}
 
-	fmt.Printf("└%s┘\n", strings.Repeat("─", 70))
+	fmt.Fprintf(w, "└%s┘\n", strings.Repeat("─", 70))
+}
+
+// EstPerdu indique que l'emprunt a été clos par la perte du livre
+func (e Emprunt) EstPerdu() bool {
+	return e.Statut == STATUT_PERDU && e.DateRetourEffectif != nil
+}
+
+// EstRendu indique que le livre est revenu : un emprunt clos par une perte ne l'est pas
+func (e Emprunt) EstRendu() bool {
+	return e.DateRetourEffectif != nil && !e.EstPerdu()
 }
 
 func (e Emprunt) EstEnRetard() bool {
@@ -110,7 +133,7 @@ func (e *Emprunt) MettreAjourStatut() {
 		} else {
 			e.Statut = STATUT_EN_COURS
 		}
-	} else {
+	} else if e.Statut != STATUT_PERDU {
 		e.Statut = STATUT_RENDU
 	}
 }
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Types d'événements du journal des emprunts
+const (
+	EVENEMENT_LIVRE_EMPRUNTE     = "LivreEmprunte"
+	EVENEMENT_LIVRE_RENDU        = "LivreRendu"
+	EVENEMENT_LIVRE_PERDU        = "LivrePerdu"    // l'emprunt est clos sans retour : le livre a été retiré du fonds
+	EVENEMENT_LIVRE_RESTAURE     = "LivreRestaure" // le livre revient au catalogue, disponible s'il n'est pas prêté
+	EVENEMENT_EMPRUNT_PROLONGE   = "EmpruntProlonge"
+	EVENEMENT_EMPRUNT_ANNULE     = "EmpruntAnnule"
+	EVENEMENT_MEMBRE_SUSPENDU    = "MembreSuspendu"
+	EVENEMENT_MEMBRE_REACTIVE    = "MembreReactive"
+	EVENEMENT_HISTORIQUE_NETTOYE = "HistoriqueNettoye" // les emprunts terminés avant DateLimite quittent la liste des emprunts
+)
+
+// Evenement est une entrée du journal des emprunts. Le journal n'est jamais
+// modifié : les emprunts, la disponibilité des livres et les compteurs des
+// membres en sont déduits en rejouant les événements dans l'ordre.
+//
+// Les numéros (Numero, EmpruntID, LivreID, MembreID) sont ceux du poste qui
+// tient le journal ; les UID désignent les mêmes enregistrements d'un poste à
+// l'autre et servent à la synchronisation.
+type Evenement struct {
+	Numero   int       `json:"numero"`
+	UID      string    `json:"uid,omitempty"`      // Identifiant unique de l'événement (ULID)
+	Instance string    `json:"instance,omitempty"` // Poste où l'événement a eu lieu
+	Type     string    `json:"type"`
+	Date     time.Time `json:"date"`
+
+	EmpruntID       int       `json:"emprunt_id,omitempty"`
+	EmpruntUID      string    `json:"emprunt_uid,omitempty"`
+	LivreID         int       `json:"livre_id,omitempty"`
+	LivreUID        string    `json:"livre_uid,omitempty"`
+	MembreID        int       `json:"membre_id,omitempty"`
+	MembreUID       string    `json:"membre_uid,omitempty"`
+	DateRetourPrevu time.Time `json:"date_retour_prevu,omitzero"` // LivreEmprunte, EmpruntProlonge
+	DateLimite      time.Time `json:"date_limite,omitzero"`       // HistoriqueNettoye
+	SuccursaleID    int       `json:"succursale_id,omitempty"`    // LivreEmprunte, LivreRendu : succursale du comptoir
+
+	// Informations dénormalisées pour faciliter l'affichage
+	TitreLivre string `json:"titre_livre,omitempty"`
+	NomMembre  string `json:"nom_membre,omitempty"`
+}
+
+// Description résume l'événement en une ligne
+func (e Evenement) Description() string {
+	switch e.Type {
+	case EVENEMENT_LIVRE_EMPRUNTE:
+		return fmt.Sprintf("📚 Emprunt #%d jusqu'au %s : « %s » par %s", e.EmpruntID, e.DateRetourPrevu.Format("02/01/2006"), e.TitreLivre, e.NomMembre)
+	case EVENEMENT_LIVRE_RENDU:
+		return fmt.Sprintf("📤 Emprunt #%d rendu : « %s » par %s", e.EmpruntID, e.TitreLivre, e.NomMembre)
+	case EVENEMENT_LIVRE_PERDU:
+		return fmt.Sprintf("❓ Emprunt #%d clos, livre perdu : « %s » par %s", e.EmpruntID, e.TitreLivre, e.NomMembre)
+	case EVENEMENT_LIVRE_RESTAURE:
+		return fmt.Sprintf("♻️ « %s » remis au catalogue", e.TitreLivre)
+	case EVENEMENT_EMPRUNT_PROLONGE:
+		return fmt.Sprintf("📅 Emprunt #%d prolongé jusqu'au %s : « %s »", e.EmpruntID, e.DateRetourPrevu.Format("02/01/2006"), e.TitreLivre)
+	case EVENEMENT_EMPRUNT_ANNULE:
+		return fmt.Sprintf("❌ Emprunt #%d annulé : « %s » par %s", e.EmpruntID, e.TitreLivre, e.NomMembre)
+	case EVENEMENT_MEMBRE_SUSPENDU:
+		return fmt.Sprintf("⛔ %s suspendu", e.NomMembre)
+	case EVENEMENT_MEMBRE_REACTIVE:
+		return fmt.Sprintf("✅ %s réactivé", e.NomMembre)
+	case EVENEMENT_HISTORIQUE_NETTOYE:
+		return fmt.Sprintf("🧹 Emprunts terminés avant le %s retirés de la liste", e.DateLimite.Format("02/01/2006"))
+	}
+	return e.Type
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Genre est un noeud de la taxonomie des genres/sujets (ex : Fiction > Policier > Noir)
+type Genre struct {
+	ID       int      `json:"id"`
+	Nom      string   `json:"nom"`
+	ParentID int      `json:"parent_id"` // 0 = genre racine
+	Alias    []string `json:"alias"`
+}
+
+func (g Genre) String() string {
+	if len(g.Alias) == 0 {
+		return fmt.Sprintf("ID: %d | %s", g.ID, g.Nom)
+	}
+	return fmt.Sprintf("ID: %d | %s (alias : %s)", g.ID, g.Nom, strings.Join(g.Alias, ", "))
+}
+
+func (g Genre) EstRacine() bool {
+	return g.ParentID == 0
+}
+
+// Correspond indique si le terme désigne ce genre (nom canonique ou alias, sans tenir compte de la casse)
+func (g Genre) Correspond(terme string) bool {
+	terme = strings.TrimSpace(terme)
+	if strings.EqualFold(g.Nom, terme) {
+		return true
+	}
+	for _, alias := range g.Alias {
+		if strings.EqualFold(alias, terme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// alphabetUID est l'alphabet base32 de Crockford utilisé par les ULID :
+// ni I, ni L, ni O, ni U, pour éviter les confusions à la lecture
+const alphabetUID = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NouvelUID retourne un identifiant unique au format ULID (26 caractères).
+// Les numéros courts (ID, codes-barres, cartes) restent ceux qu'on lit et
+// qu'on saisit ; l'UID, lui, ne se répète pas d'une librairie ou d'un poste à
+// l'autre, ce qui permet de rapprocher des données venues de plusieurs endroits.
+func NouvelUID() string {
+	var aleatoire [10]byte
+	if _, err := rand.Read(aleatoire[:]); err != nil {
+		panic(err) // crypto/rand ne peut pas échouer sur les systèmes pris en charge
+	}
+	return encoderUID(time.Now(), aleatoire)
+}
+
+// UIDAncien retourne l'UID d'un enregistrement créé avant les UID. Il est
+// tiré de sa date et d'une clé qui lui est propre (type et ID) : recalculé
+// depuis un autre instantané ou sur un autre poste, il reste le même.
+func UIDAncien(date time.Time, cle string) string {
+	empreinte := sha256.Sum256([]byte(cle + "@" + date.UTC().Format(time.RFC3339Nano)))
+	var aleatoire [10]byte
+	copy(aleatoire[:], empreinte[:])
+	return encoderUID(date, aleatoire)
+}
+
+// encoderUID place les millisecondes de la date (48 bits) puis 80 bits
+// aléatoires dans 26 caractères de 5 bits
+func encoderUID(date time.Time, aleatoire [10]byte) string {
+	var octets [16]byte
+	var horodatage [8]byte
+	binary.BigEndian.PutUint64(horodatage[:], uint64(max(date.UnixMilli(), 0)))
+	copy(octets[:6], horodatage[2:])
+	copy(octets[6:], aleatoire[:])
+
+	haut := binary.BigEndian.Uint64(octets[:8])
+	bas := binary.BigEndian.Uint64(octets[8:])
+
+	// 26 × 5 = 130 bits : les 2 premiers bits du premier caractère sont nuls
+	uid := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		uid[i] = alphabetUID[bas&0x1F]
+		bas = bas>>5 | haut<<59
+		haut >>= 5
+	}
+	return string(uid)
+}
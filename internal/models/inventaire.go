@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	INVENTAIRE_EN_COURS = "en_cours" // des livres peuvent encore être relevés, même les jours suivants
+	INVENTAIRE_CLOTURE  = "cloture"  // le rapport d'écarts est figé
+)
+
+// Inventaire est le récolement d'un rayon : on relève les livres trouvés en
+// rayon pour les comparer au catalogue. Le rayon est un genre de la taxonomie,
+// sous-genres compris ; sans genre, l'inventaire couvre tout le fonds.
+type Inventaire struct {
+	ID            int       `json:"id"`
+	Nom           string    `json:"nom"`      // ex : "Romans, étagères A à C"
+	GenreID       int       `json:"genre_id"` // 0 = tout le fonds
+	Genre         string    `json:"genre"`
+	Statut        string    `json:"statut"`
+	DateOuverture time.Time `json:"date_ouverture"`
+	DateCloture   time.Time `json:"date_cloture,omitzero"`
+	Constats      []Constat `json:"constats"`
+
+	SuccursaleID int `json:"succursale_id,omitempty"` // succursale dont on inventorie les rayons (0 = pas de réseau)
+}
+
+// Constat est un livre relevé en rayon, dans l'ordre des relevés
+type Constat struct {
+	Saisie  string    `json:"saisie"`   // code scanné ou saisi
+	LivreID int       `json:"livre_id"` // 0 = aucun livre du catalogue ne correspond
+	Date    time.Time `json:"date"`
+}
+
+// EstEnCours indique si l'inventaire accepte encore des relevés
+func (i Inventaire) EstEnCours() bool {
+	return i.Statut == INVENTAIRE_EN_COURS
+}
+
+// Rayon décrit ce que couvre l'inventaire
+func (i Inventaire) Rayon() string {
+	if i.GenreID == 0 {
+		return "tout le fonds"
+	}
+	return i.Genre
+}
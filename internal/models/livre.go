@@ -2,57 +2,159 @@ package models
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
 )
 
 type Livre struct {
-	ID              int       `json:"id"`
-	Titre           string    `json:"titre"`
-	Auteur          string    `json:"auteur"`
-	ISBN            string    `json:"isbn"`
-	Genre           string    `json:"genre"`
-	DatePublication time.Time `json:"date_publication"`
-	Disponible      bool      `json:"disponible"`
-	NombreEmprunts  int       `json:"nombre_emprunts"`
-	DateAjout       time.Time `json:"date_ajout"`
+	ID               int       `json:"id"`
+	UID              string    `json:"uid,omitempty"` // Identifiant unique (ULID), le même sur tous les postes
+	Titre            string    `json:"titre"`
+	Auteur           string    `json:"auteur"` // Noms des auteurs, recalculé à partir des contributions
+	ISBN             string    `json:"isbn"`
+	CodeBarres       string    `json:"code_barres,omitempty"` // Code imprimé sur l'étiquette du livre
+	Genre            string    `json:"genre"`                 // Nom canonique du genre principal (premier sujet)
+	Sujets           []int     `json:"sujets"`                // IDs des genres/sujets, le premier est le genre principal
+	DatePublication  time.Time `json:"date_publication"`
+	Disponible       bool      `json:"disponible"`
+	NombreEmprunts   int       `json:"nombre_emprunts"`
+	EmpruntsArchives int       `json:"emprunts_archives,omitempty"` // Emprunts comptés mais supprimés de l'historique par le nettoyage
+	DateAjout        time.Time `json:"date_ajout"`
+
+	Contributions []Contribution `json:"contributions"`
+
+	OeuvreID int    `json:"oeuvre_id"` // 0 = le livre n'est rattaché à aucune oeuvre
+	Edition  string `json:"edition"`   // Ex : "Folio 2012", "Traduction anglaise"
+
+	Retrait *Retrait `json:"retrait,omitempty"` // nil tant que le livre est au catalogue
+
+	Acquisition *Acquisition `json:"acquisition,omitempty"` // nil si le livre n'a pas été reçu sur une commande
+
+	SuccursaleID  int        `json:"succursale_id,omitempty"`  // Succursale dont le livre fait partie du fonds (0 = pas de réseau)
+	EmplacementID int        `json:"emplacement_id,omitempty"` // Succursale où le livre se trouve, ou d'où il est parti
+	Transfert     *Transfert `json:"transfert,omitempty"`      // nil sauf pendant un transfert entre succursales
+
+	PEBID int `json:"peb_id,omitempty"` // Demande de prêt entre bibliothèques de l'exemplaire emprunté à une autre bibliothèque (0 = livre du fonds)
 }
 
 // Permet d'afficher un livre de manière simple
 // Elle est appelée automatiquement quanf on fait fmt.Print(livre)
 func (l Livre) String() string {
 	statut := "📗 Disponible"
-	if !l.Disponible {
+	if l.EstRetire() {
+		statut = "🚫 Retiré"
+	} else if !l.Disponible {
 		statut = "📕 Emprunté"
+	} else if l.EstEnTransit() {
+		statut = "🚚 En transit"
 	}
 
 	return fmt.Sprintf("ID: %d | %s par %s | %s | %s ", l.ID, l.Titre, l.Auteur, l.Genre, statut)
 }
 
-// AfficherDetails() montre toutes les informations d'un livre dans un tableau
-func (l Livre) AfficherDetails() {
-	fmt.Printf("┌%s┐\n", strings.Repeat("─", 60))
-	fmt.Printf("│ Livre #%d%s│\n", l.ID, strings.Repeat(" ", 60-len(fmt.Sprintf(" Livre #%d", l.ID))))
-	fmt.Printf("├%s┤\n", strings.Repeat("─", 60))
-	fmt.Printf("│ Titre         : %-40s │\n", l.Titre)
-	fmt.Printf("│ Auteur        : %-40s │\n", l.Auteur)
-	fmt.Printf("│ ISBN          : %-40s │\n", l.ISBN)
-	fmt.Printf("│ Genre         : %-40s │\n", l.Genre)
-	fmt.Printf("│ Publication   : %-40s │\n", l.DatePublication.Format("02/01/2006"))
+// AfficherDetails() écrit toutes les informations d'un livre dans un tableau
+func (l Livre) AfficherDetails(w io.Writer) {
+	fmt.Fprintf(w, "┌%s┐\n", strings.Repeat("─", 60))
+	fmt.Fprintf(w, "│ Livre #%d%s│\n", l.ID, strings.Repeat(" ", 60-len(fmt.Sprintf(" Livre #%d", l.ID))))
+	fmt.Fprintf(w, "├%s┤\n", strings.Repeat("─", 60))
+	fmt.Fprintf(w, "│ Titre         : %s │\n", affichage.Ajuster(l.Titre, 42))
+	fmt.Fprintf(w, "│ Auteur        : %s │\n", affichage.Ajuster(l.Auteur, 42))
+	fmt.Fprintf(w, "│ ISBN          : %s │\n", affichage.Ajuster(l.ISBN, 42))
+	fmt.Fprintf(w, "│ Code-barres   : %s │\n", affichage.Ajuster(l.CodeBarres, 42))
+	fmt.Fprintf(w, "│ Genre         : %s │\n", affichage.Ajuster(l.Genre, 42))
+	fmt.Fprintf(w, "│ Publication   : %s │\n", affichage.Ajuster(l.DatePublication.Format("02/01/2006"), 42))
 
 	// Afficher le statut avec des couleurs (émojis)
 	statut := "📗 Disponible"
-	if !l.Disponible {
+	if l.EstRetire() {
+		statut = "🚫 Retiré"
+	} else if !l.Disponible {
 		statut = "📕 Emprunté"
+	} else if l.EstEnTransit() {
+		statut = "🚚 En transit"
+	}
+	fmt.Fprintf(w, "│ Statut        : %s │\n", affichage.Ajuster(statut, 42))
+	if l.EstRetire() {
+		fmt.Fprintf(w, "│ Retrait       : %s │\n", affichage.Ajuster(l.Retrait.String(), 42))
+	}
+	if l.Acquisition != nil {
+		fmt.Fprintf(w, "│ Acquisition   : %s │\n", affichage.Ajuster(l.Acquisition.String(), 42))
+	}
+	if l.EstPEB() {
+		fmt.Fprintf(w, "│ Provenance    : %s │\n", affichage.Ajuster(fmt.Sprintf("prêt entre bibliothèques (demande #%d)", l.PEBID), 42))
+	}
+	fmt.Fprintf(w, "│ Emprunts      : %-42d │\n", l.NombreEmprunts)
+	fmt.Fprintf(w, "│ Ajouté le     : %s │\n", affichage.Ajuster(l.DateAjout.Format("02/01/2006 15:04:05"), 42))
+	fmt.Fprintf(w, "└%s┘\n", strings.Repeat("─", 60))
+}
+
+// ASujet indique si le livre porte le genre/sujet donné
+func (l Livre) ASujet(genreID int) bool {
+	for _, id := range l.Sujets {
+		if id == genreID {
+			return true
+		}
+	}
+	return false
+}
+
+// ASujetParmi indique si le livre porte au moins un des genres donnés
+func (l Livre) ASujetParmi(genreIDs map[int]bool) bool {
+	for _, id := range l.Sujets {
+		if genreIDs[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// ContributeursParRole retourne les IDs des contributeurs ayant le rôle donné, dans l'ordre
+func (l Livre) ContributeursParRole(role string) []int {
+	var ids []int
+	for _, contribution := range l.Contributions {
+		if contribution.Role == role {
+			ids = append(ids, contribution.ContributeurID)
+		}
 	}
-	fmt.Printf("│ Statut        : %-40s │\n", statut)
-	fmt.Printf("│ Emprunts      : %-40d │\n", l.NombreEmprunts)
-	fmt.Printf("│ Ajouté le     : %-40s │\n", l.DateAjout.Format("02/01/2006 15:04:05"))
-	fmt.Printf("└%s┘\n", strings.Repeat("─", 60))
+	return ids
+}
+
+// AContributeur indique si le contributeur a participé au livre, quel que soit son rôle
+func (l Livre) AContributeur(contributeurID int) bool {
+	for _, contribution := range l.Contributions {
+		if contribution.ContributeurID == contributeurID {
+			return true
+		}
+	}
+	return false
+}
+
+// EstPEB indique si le livre est un exemplaire prêté par une autre bibliothèque,
+// présent au catalogue le temps de la demande
+func (l Livre) EstPEB() bool {
+	return l.PEBID != 0
 }
 
 func (l Livre) EstDisponible() bool {
-	return l.Disponible
+	return l.Disponible && !l.EstRetire() && !l.EstEnTransit()
+}
+
+// EstEnTransit indique si le livre voyage entre deux succursales
+func (l Livre) EstEnTransit() bool {
+	return l.Transfert != nil
+}
+
+// EstRetire indique si le livre a été sorti du fonds (désherbé ou perdu)
+func (l Livre) EstRetire() bool {
+	return l.Retrait != nil
+}
+
+// EstPurge indique si la fiche du livre retiré a été purgée
+func (l Livre) EstPurge() bool {
+	return l.Retrait != nil && !l.Retrait.Purge.IsZero()
 }
 
 func (l *Livre) MarquerCommeEmprunte() {
@@ -2,58 +2,81 @@ package models
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
 )
 
 type Membre struct {
-	ID              int       `json:"id"`
-	Nom             string    `json:"nom"`
-	Email           string    `json:"email"`
-	Telephone       string    `json:"telephone"`
-	DateInscription time.Time `json:"date_inscription"`
-	NombreEmprunts  int       `json:"nombre_emprunts"`
-	EmpruntsActifs  int       `json:"emprunts_actifs"`
-	Actif           bool      `json:"actif"`
-}
+	ID               int       `json:"id"`
+	UID              string    `json:"uid,omitempty"` // Identifiant unique (ULID), le même sur tous les postes
+	Nom              string    `json:"nom"`
+	Email            string    `json:"email"`
+	Telephone        string    `json:"telephone"`
+	NumeroCarte      string    `json:"numero_carte,omitempty"` // Code imprimé sur la carte de membre
+	PIN              string    `json:"pin,omitempty"`          // Empreinte du code PIN du libre-service, jamais le code lui-même
+	EchecsPIN        int       `json:"echecs_pin,omitempty"`   // Essais ratés consécutifs au libre-service
+	MotDePasse       string    `json:"mot_de_passe,omitempty"` // Empreinte du mot de passe du portail des membres
+	DateInscription  time.Time `json:"date_inscription"`
+	NombreEmprunts   int       `json:"nombre_emprunts"`
+	EmpruntsArchives int       `json:"emprunts_archives,omitempty"` // Emprunts comptés mais supprimés de l'historique par le nettoyage
+	EmpruntsActifs   int       `json:"emprunts_actifs"`
+	Actif            bool      `json:"actif"`
 
-const (
-	LIMIT_EMPRUNTS_SIMULTANES = 3
-)
+	Retrait *Retrait `json:"retrait,omitempty"` // nil tant que le membre est inscrit
+
+	SuccursaleID int `json:"succursale_id,omitempty"` // Succursale d'inscription, où ses réservations l'attendent (0 = pas de réseau)
+}
 
 // Affiche un membre simplement
 func (m Membre) String() string {
 	statut := "✅ Actif"
 
-	if !m.Actif {
+	if m.EstRetire() {
+		statut = "🚫 Radié"
+	} else if !m.Actif {
 		statut = "❌ Suspendu"
 	}
 
-	return fmt.Sprintf("ID: %d | %s | %s | Emprunts: %d/%d | %s  ", m.ID, m.Nom, m.Email, m.EmpruntsActifs, LIMIT_EMPRUNTS_SIMULTANES, statut)
+	return fmt.Sprintf("ID: %d | %s | %s | Emprunts actifs: %d | %s  ", m.ID, m.Nom, m.Email, m.EmpruntsActifs, statut)
 }
 
-// AfficherDetails() montre toutes les informations d'un membre
-func (m Membre) AfficherDetails() {
-	fmt.Printf("┌%s┐\n", strings.Repeat("─", 60))
-	fmt.Printf("│ Membre #%d%s│\n", m.ID, strings.Repeat(" ", 60-len(fmt.Sprintf(" Membre #%d", m.ID))))
-	fmt.Printf("├%s┤\n", strings.Repeat("─", 60))
-	fmt.Printf("│ Nom           : %-40s │\n", m.Nom)
-	fmt.Printf("│ Email         : %-40s │\n", m.Email)
-	fmt.Printf("│ Téléphone     : %-40s │\n", m.Telephone)
-	fmt.Printf("│ Inscrit le    : %-40s │\n", m.DateInscription.Format("02/01/2006"))
+// AfficherDetails() écrit toutes les informations d'un membre
+func (m Membre) AfficherDetails(w io.Writer) {
+	fmt.Fprintf(w, "┌%s┐\n", strings.Repeat("─", 60))
+	fmt.Fprintf(w, "│ Membre #%d%s│\n", m.ID, strings.Repeat(" ", 60-len(fmt.Sprintf(" Membre #%d", m.ID))))
+	fmt.Fprintf(w, "├%s┤\n", strings.Repeat("─", 60))
+	fmt.Fprintf(w, "│ Nom           : %s │\n", affichage.Ajuster(m.Nom, 42))
+	fmt.Fprintf(w, "│ Email         : %s │\n", affichage.Ajuster(m.Email, 42))
+	fmt.Fprintf(w, "│ Téléphone     : %s │\n", affichage.Ajuster(m.Telephone, 42))
+	fmt.Fprintf(w, "│ Carte         : %s │\n", affichage.Ajuster(m.NumeroCarte, 42))
+	fmt.Fprintf(w, "│ Inscrit le    : %s │\n", affichage.Ajuster(m.DateInscription.Format("02/01/2006"), 42))
 
 	statut := "✅ Actif"
-	if !m.Actif {
+	if m.EstRetire() {
+		statut = "🚫 Radié"
+	} else if !m.Actif {
 		statut = "❌ Suspendu"
 	}
-	fmt.Printf("│ Statut        : %-40s │\n", statut)
-	fmt.Printf("│ Emprunts totaux : %-37d │\n", m.NombreEmprunts)
-	fmt.Printf("│ Emprunts actifs : %-37d │\n", m.EmpruntsActifs)
-	fmt.Printf("└%s┘\n", strings.Repeat("─", 60))
+	fmt.Fprintf(w, "│ Statut        : %s │\n", affichage.Ajuster(statut, 42))
+	if m.EstRetire() {
+		fmt.Fprintf(w, "│ Radiation     : %s │\n", affichage.Ajuster(m.Retrait.String(), 42))
+	}
+	fmt.Fprintf(w, "│ Emprunts totaux : %-40d │\n", m.NombreEmprunts)
+	fmt.Fprintf(w, "│ Emprunts actifs : %-40d │\n", m.EmpruntsActifs)
+	fmt.Fprintf(w, "└%s┘\n", strings.Repeat("─", 60))
+}
+
+// PeutEmprunter indique si le membre peut encore emprunter compte tenu de la limite configurée
+func (m Membre) PeutEmprunter(limite int) bool {
+	return m.Actif && !m.EstRetire() && m.EmpruntsActifs < limite
 }
 
-func (m Membre) PeutEmprunter() bool {
-	return m.Actif && m.EmpruntsActifs < LIMIT_EMPRUNTS_SIMULTANES
+// EstRetire indique si le membre a été radié
+func (m Membre) EstRetire() bool {
+	return m.Retrait != nil
 }
 
 func (m *Membre) AjouterEmprunt() {
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuts d'une demande de prêt entre bibliothèques (PEB). Le livre vient d'une
+// autre bibliothèque, n'est prêté qu'au membre qui l'a demandé, puis repart.
+const (
+	PEB_DEMANDEE = "demandee" // demande transmise à la bibliothèque prêteuse
+	PEB_EXPEDIEE = "expediee" // le prêteur a envoyé le livre
+	PEB_RECUE    = "recue"    // le livre est arrivé et attend le membre
+	PEB_EN_PRET  = "en_pret"  // le membre l'a emprunté
+	PEB_RENDUE   = "rendue"   // le membre l'a rendu, il reste à le renvoyer
+	PEB_RENVOYEE = "renvoyee" // renvoyé au prêteur : la demande est close
+	PEB_ANNULEE  = "annulee"  // abandonnée avant l'arrivée du livre
+)
+
+// TransitionsPEB donne, pour chaque statut, ceux qui peuvent lui succéder.
+// Un livre reçu que le membre ne vient pas chercher repart sans être prêté.
+var TransitionsPEB = map[string][]string{
+	PEB_DEMANDEE: {PEB_EXPEDIEE, PEB_RECUE, PEB_ANNULEE},
+	PEB_EXPEDIEE: {PEB_RECUE},
+	PEB_RECUE:    {PEB_EN_PRET, PEB_RENVOYEE},
+	PEB_EN_PRET:  {PEB_RENDUE},
+	PEB_RENDUE:   {PEB_RENVOYEE},
+}
+
+// DemandePEB suit un livre emprunté à une autre bibliothèque pour un membre.
+// À la réception, le livre entre temporairement au catalogue (LivreID) ; il en
+// sort quand il est renvoyé au prêteur.
+type DemandePEB struct {
+	ID          int       `json:"id"`
+	MembreID    int       `json:"membre_id"`
+	NomMembre   string    `json:"nom_membre"`
+	Titre       string    `json:"titre"`
+	Auteur      string    `json:"auteur"`
+	ISBN        string    `json:"isbn"`
+	DateBesoin  time.Time `json:"date_besoin,omitzero"` // date avant laquelle le membre en a besoin
+	DateDemande time.Time `json:"date_demande"`
+	Preteur     string    `json:"preteur"` // bibliothèque prêteuse, connue au plus tard à la réception
+	Statut      string    `json:"statut"`
+
+	DateRetourPreteur time.Time `json:"date_retour_preteur,omitzero"` // échéance fixée par le prêteur, qui devient celle de l'emprunt
+	LivreID           int       `json:"livre_id,omitempty"`           // exemplaire temporaire, de la réception au renvoi
+	EmpruntID         int       `json:"emprunt_id,omitempty"`
+
+	Historique []EtapePEB `json:"historique"`
+	Couts      []CoutPEB  `json:"couts"`
+}
+
+// EtapePEB garde la date de chaque changement de statut
+type EtapePEB struct {
+	Statut string    `json:"statut"`
+	Date   time.Time `json:"date"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// CoutPEB est une dépense liée à la demande : frais du prêteur, port...
+type CoutPEB struct {
+	Libelle string    `json:"libelle"`
+	Montant Montant   `json:"montant"`
+	Date    time.Time `json:"date"`
+}
+
+// LibelleStatutPEB retourne le libellé français d'un statut de demande
+func LibelleStatutPEB(statut string) string {
+	switch statut {
+	case PEB_DEMANDEE:
+		return "✉️  Demandée"
+	case PEB_EXPEDIEE:
+		return "🚚 Expédiée"
+	case PEB_RECUE:
+		return "📥 Reçue"
+	case PEB_EN_PRET:
+		return "📕 Prêtée au membre"
+	case PEB_RENDUE:
+		return "📤 Rendue, à renvoyer"
+	case PEB_RENVOYEE:
+		return "✅ Renvoyée"
+	case PEB_ANNULEE:
+		return "❌ Annulée"
+	}
+	return statut
+}
+
+func (d DemandePEB) String() string {
+	return fmt.Sprintf("#%d « %s » pour %s (%s)", d.ID, d.Titre, d.NomMembre, LibelleStatutPEB(d.Statut))
+}
+
+// PeutPasserA indique si la demande peut prendre le statut donné
+func (d DemandePEB) PeutPasserA(statut string) bool {
+	for _, suivant := range TransitionsPEB[d.Statut] {
+		if suivant == statut {
+			return true
+		}
+	}
+	return false
+}
+
+// EstClose indique si la demande est terminée (livre renvoyé ou demande annulée)
+func (d DemandePEB) EstClose() bool {
+	return d.Statut == PEB_RENVOYEE || d.Statut == PEB_ANNULEE
+}
+
+// Total additionne les coûts de la demande
+func (d DemandePEB) Total() Montant {
+	var total Montant
+	for _, cout := range d.Couts {
+		total += cout.Montant
+	}
+	return total
+}
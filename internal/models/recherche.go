@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Recherche compte les recherches du catalogue restées sans résultat pour un même
+// terme : c'est un signal de demande pour un livre que la librairie n'a pas.
+type Recherche struct {
+	Terme    string    `json:"terme"` // en minuscules, espaces superflus retirés
+	Nombre   int       `json:"nombre"`
+	Premiere time.Time `json:"premiere"`
+	Derniere time.Time `json:"derniere"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reservation place un membre dans la file d'attente d'un livre.
+// Quand le livre revient (ou s'il est déjà en rayon), la réservation passe
+// « prête » : le livre est mis de côté jusqu'à DateLimite.
+type Reservation struct {
+	ID              int        `json:"id"`
+	UID             string     `json:"uid,omitempty"` // Identifiant unique (ULID), le même sur tous les postes
+	LivreID         int        `json:"livre_id"`
+	MembreID        int        `json:"membre_id"`
+	DateReservation time.Time  `json:"date_reservation"`
+	Statut          string     `json:"statut"`
+	DateMiseDeCote  *time.Time `json:"date_mise_de_cote,omitempty"` // nil tant que le livre n'est pas mis de côté
+	DateLimite      *time.Time `json:"date_limite,omitempty"`       // date limite de retrait du livre mis de côté
+	DateCloture     *time.Time `json:"date_cloture,omitempty"`      // emprunt, annulation ou expiration
+
+	SuccursaleRetrait int `json:"succursale_retrait,omitempty"` // succursale où le membre retirera le livre (0 = pas de réseau)
+
+	// Informations dénormalisées pour faciliter l'affichage
+	TitreLivre string `json:"titre_livre"`
+	NomMembre  string `json:"nom_membre"`
+}
+
+const (
+	RESERVATION_EN_ATTENTE = "en-attente" // le livre est emprunté ou mis de côté pour un autre membre
+	RESERVATION_EN_TRANSIT = "en-transit" // le livre est acheminé vers la succursale de retrait
+	RESERVATION_PRETE      = "prete"      // le livre attend le membre à l'accueil
+	RESERVATION_HONOREE    = "honoree"    // le membre a emprunté le livre
+	RESERVATION_ANNULEE    = "annulee"
+	RESERVATION_EXPIREE    = "expiree" // le livre n'a pas été retiré à temps
+)
+
+// EstActive indique si la réservation est encore dans la file d'attente
+func (r Reservation) EstActive() bool {
+	return r.Statut == RESERVATION_EN_ATTENTE || r.Statut == RESERVATION_EN_TRANSIT || r.Statut == RESERVATION_PRETE
+}
+
+// LibelleStatutReservation retourne le libellé français d'un statut de réservation
+func LibelleStatutReservation(statut string) string {
+	switch statut {
+	case RESERVATION_EN_ATTENTE:
+		return "⏳ En attente"
+	case RESERVATION_EN_TRANSIT:
+		return "🚚 En transit"
+	case RESERVATION_PRETE:
+		return "📌 Prête"
+	case RESERVATION_HONOREE:
+		return "✅ Honorée"
+	case RESERVATION_ANNULEE:
+		return "❌ Annulée"
+	case RESERVATION_EXPIREE:
+		return "⌛ Expirée"
+	}
+	return statut
+}
+
+func (r Reservation) String() string {
+	texte := fmt.Sprintf("ID: %d | %s pour %s | Réservé le %s | %s",
+		r.ID, r.TitreLivre, r.NomMembre, r.DateReservation.Format("02/01/2006"), LibelleStatutReservation(r.Statut))
+	if r.Statut == RESERVATION_PRETE && r.DateLimite != nil {
+		texte += " jusqu'au " + r.DateLimite.Format("02/01/2006")
+	}
+	return texte
+}
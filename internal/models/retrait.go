@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retrait décrit la sortie d'un livre du fonds ou la radiation d'un membre.
+// L'élément est conservé pour l'historique des emprunts, mais masqué des listes.
+type Retrait struct {
+	Motif  string    `json:"motif"`
+	Date   time.Time `json:"date"`
+	Raison string    `json:"raison"`
+	Purge  time.Time `json:"purge,omitzero"` // date de la purge : la fiche ne garde que ce qui sert à l'historique
+}
+
+const (
+	MOTIF_DESHERBE = "desherbe" // Livre retiré du fonds (usé, obsolète...)
+	MOTIF_PERDU    = "perdu"    // Livre perdu ou non restitué
+	MOTIF_RADIE    = "radie"    // Membre désinscrit
+)
+
+// MOTIFS_RETRAIT_LIVRE liste les motifs acceptés pour un livre
+var MOTIFS_RETRAIT_LIVRE = []string{MOTIF_DESHERBE, MOTIF_PERDU}
+
+// LibelleMotif retourne le libellé français d'un motif de retrait
+func LibelleMotif(motif string) string {
+	switch motif {
+	case MOTIF_DESHERBE:
+		return "Désherbé"
+	case MOTIF_PERDU:
+		return "Perdu"
+	case MOTIF_RADIE:
+		return "Radié"
+	}
+	return motif
+}
+
+func (r Retrait) String() string {
+	if r.Raison == "" {
+		return fmt.Sprintf("%s le %s", LibelleMotif(r.Motif), r.Date.Format("02/01/2006"))
+	}
+	return fmt.Sprintf("%s le %s (%s)", LibelleMotif(r.Motif), r.Date.Format("02/01/2006"), r.Raison)
+}
@@ -0,0 +1,29 @@
+package models
+
+import "fmt"
+
+// Serie regroupe des oeuvres publiées en plusieurs tomes (ex : "Les Rougon-Macquart")
+type Serie struct {
+	ID    int    `json:"id"`
+	Titre string `json:"titre"`
+}
+
+// Oeuvre représente un texte indépendamment de ses éditions et traductions.
+// Plusieurs livres du catalogue peuvent être des éditions de la même oeuvre.
+type Oeuvre struct {
+	ID         int    `json:"id"`
+	Titre      string `json:"titre"`
+	SerieID    int    `json:"serie_id"`    // 0 = oeuvre hors série
+	NumeroTome int    `json:"numero_tome"` // Position dans la série (0 si hors série)
+}
+
+func (s Serie) String() string {
+	return fmt.Sprintf("ID: %d | %s", s.ID, s.Titre)
+}
+
+func (o Oeuvre) String() string {
+	if o.SerieID == 0 {
+		return fmt.Sprintf("ID: %d | %s", o.ID, o.Titre)
+	}
+	return fmt.Sprintf("ID: %d | %s (tome %d)", o.ID, o.Titre, o.NumeroTome)
+}
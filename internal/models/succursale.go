@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Succursale est une bibliothèque du réseau. Chaque livre appartient au fonds
+// d'une succursale mais peut se trouver dans une autre : un membre emprunte et
+// rend où il veut, le livre rentre ensuite chez lui par transfert.
+type Succursale struct {
+	ID        int       `json:"id"`
+	UID       string    `json:"uid,omitempty"` // Identifiant unique (ULID), le même sur tous les postes
+	Code      string    `json:"code"`          // Code court saisi au démarrage d'un poste, ex : "CTR"
+	Nom       string    `json:"nom"`
+	Adresse   string    `json:"adresse"`
+	Latitude  float64   `json:"latitude"` // Position, pour trouver la succursale la plus proche
+	Longitude float64   `json:"longitude"`
+	DateAjout time.Time `json:"date_ajout"`
+}
+
+// RAYON_TERRE_KM sert au calcul des distances à vol d'oiseau
+const RAYON_TERRE_KM = 6371.0
+
+func (s Succursale) String() string {
+	return fmt.Sprintf("%s (%s)", s.Nom, s.Code)
+}
+
+// DistanceKm retourne la distance à vol d'oiseau entre deux succursales
+func (s Succursale) DistanceKm(autre Succursale) float64 {
+	radians := func(degres float64) float64 { return degres * math.Pi / 180 }
+	dLat := radians(autre.Latitude - s.Latitude)
+	dLon := radians(autre.Longitude - s.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(s.Latitude))*math.Cos(radians(autre.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * RAYON_TERRE_KM * math.Asin(math.Sqrt(a))
+}
+
+// NormaliserCodeSuccursale met un code de succursale sous sa forme enregistrée
+func NormaliserCodeSuccursale(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ========================================
+// TRANSFERTS
+// ========================================
+
+const (
+	TRANSFERT_RETOUR      = "retour"      // rendu ailleurs, le livre rentre dans sa succursale
+	TRANSFERT_RESERVATION = "reservation" // le livre part vers la succursale où un membre le retirera
+	TRANSFERT_REAFFECTE   = "reaffecte"   // le livre change de succursale
+)
+
+// Transfert est l'acheminement d'un livre d'une succursale à une autre.
+// Tant qu'il n'est pas réceptionné, le livre ne peut pas être emprunté.
+type Transfert struct {
+	De            int       `json:"de"`
+	Vers          int       `json:"vers"`
+	Motif         string    `json:"motif"`
+	ReservationID int       `json:"reservation_id,omitempty"` // TRANSFERT_RESERVATION
+	DateDepart    time.Time `json:"date_depart"`
+}
+
+// LibelleMotifTransfert retourne le libellé français d'un motif de transfert
+func LibelleMotifTransfert(motif string) string {
+	switch motif {
+	case TRANSFERT_RETOUR:
+		return "Retour dans sa succursale"
+	case TRANSFERT_RESERVATION:
+		return "Réservation à retirer"
+	case TRANSFERT_REAFFECTE:
+		return "Changement de succursale"
+	}
+	return motif
+}
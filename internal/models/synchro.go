@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PairSynchro est un autre poste (ou le poste central) avec lequel ce poste
+// échange ses événements. Les curseurs sont des numéros du journal : Recu
+// chez le pair, Envoye chez nous.
+type PairSynchro struct {
+	URL             string    `json:"url"`
+	Instance        string    `json:"instance,omitempty"` // Identifiant du pair, appris à la première synchronisation
+	Recu            int       `json:"recu"`               // dernier événement du pair déjà reçu
+	Envoye          int       `json:"envoye"`             // dernier de nos événements déjà envoyé au pair
+	DerniereSynchro time.Time `json:"derniere_synchro,omitzero"`
+	DerniereErreur  string    `json:"derniere_erreur,omitempty"` // vide si la dernière tentative a réussi
+}
+
+// EstJoignable indique si la dernière tentative de synchronisation a réussi
+func (p PairSynchro) EstJoignable() bool {
+	return !p.DerniereSynchro.IsZero() && p.DerniereErreur == ""
+}
+
+// ConflitSynchro signale un livre emprunté sur deux postes pendant qu'ils ne
+// se voyaient pas. Tous les postes retiennent le même emprunt, le plus ancien ;
+// l'autre est écarté et doit être régularisé au comptoir.
+type ConflitSynchro struct {
+	UID        string `json:"uid"` // UID de l'événement écarté
+	LivreID    int    `json:"livre_id"`
+	TitreLivre string `json:"titre_livre"`
+
+	MembreRetenu   string    `json:"membre_retenu"`
+	DateRetenue    time.Time `json:"date_retenue"`
+	InstanceRetenu string    `json:"instance_retenu,omitempty"`
+
+	MembreEcarte   string    `json:"membre_ecarte"`
+	DateEcartee    time.Time `json:"date_ecartee"`
+	InstanceEcarte string    `json:"instance_ecarte,omitempty"`
+
+	DateDetection time.Time  `json:"date_detection"`
+	Traite        *time.Time `json:"traite,omitempty"` // nil tant que personne ne l'a régularisé
+}
+
+func (c ConflitSynchro) String() string {
+	return fmt.Sprintf("« %s » emprunté par %s le %s et par %s le %s : l'emprunt de %s est écarté",
+		c.TitreLivre, c.MembreRetenu, c.DateRetenue.Format("02/01/2006 15:04"),
+		c.MembreEcarte, c.DateEcartee.Format("02/01/2006 15:04"), c.MembreEcarte)
+}
+
+// EstTraite indique si le conflit a été régularisé
+func (c ConflitSynchro) EstTraite() bool {
+	return c.Traite != nil
+}
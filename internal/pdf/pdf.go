@@ -0,0 +1,232 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ========================================
+// GÉNÉRATION DE DOCUMENTS PDF
+// Juste ce qu'il faut pour les étiquettes, reçus et rapports :
+// rectangles, traits, texte dans les polices standard (aucune police à embarquer)
+// ========================================
+
+// Dimensions en points (1 point = 1/72 de pouce)
+const (
+	A4_LARGEUR = 595.28
+	A4_HAUTEUR = 841.89
+	MM         = 72 / 25.4
+)
+
+// Polices standard disponibles dans tous les lecteurs PDF
+const (
+	POLICE_NORMALE = "F1" // Helvetica
+	POLICE_GRASSE  = "F2" // Helvetica-Bold
+	POLICE_FIXE    = "F3" // Courier
+)
+
+type Document struct {
+	titre string
+	pages []*Page
+}
+
+// Page reçoit les instructions de dessin ; l'origine est en bas à gauche
+type Page struct {
+	Largeur, Hauteur float64
+	contenu          bytes.Buffer
+}
+
+// NouveauDocument crée un document vide
+func NouveauDocument(titre string) *Document {
+	return &Document{titre: titre}
+}
+
+// NouvellePage ajoute une page aux dimensions données (en points)
+func (d *Document) NouvellePage(largeur, hauteur float64) *Page {
+	page := &Page{Largeur: largeur, Hauteur: hauteur}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// NombrePages retourne le nombre de pages du document
+func (d *Document) NombrePages() int {
+	return len(d.pages)
+}
+
+// Couleur règle la couleur de remplissage et de trait (composantes entre 0 et 1)
+func (p *Page) Couleur(r, v, b float64) {
+	fmt.Fprintf(&p.contenu, "%.3f %.3f %.3f rg %.3f %.3f %.3f RG\n", r, v, b, r, v, b)
+}
+
+// Rectangle dessine un rectangle plein
+func (p *Page) Rectangle(x, y, largeur, hauteur float64) {
+	fmt.Fprintf(&p.contenu, "%.2f %.2f %.2f %.2f re f\n", x, y, largeur, hauteur)
+}
+
+// Cadre dessine le contour d'un rectangle
+func (p *Page) Cadre(x, y, largeur, hauteur, epaisseur float64) {
+	fmt.Fprintf(&p.contenu, "%.2f w %.2f %.2f %.2f %.2f re S\n", epaisseur, x, y, largeur, hauteur)
+}
+
+// Trait dessine un segment
+func (p *Page) Trait(x1, y1, x2, y2, epaisseur float64) {
+	fmt.Fprintf(&p.contenu, "%.2f w %.2f %.2f m %.2f %.2f l S\n", epaisseur, x1, y1, x2, y2)
+}
+
+// Texte écrit une ligne de texte dont la ligne de base commence en (x, y)
+func (p *Page) Texte(x, y float64, police string, taille float64, texte string) {
+	fmt.Fprintf(&p.contenu, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", police, taille, x, y, encoderTexte(texte))
+}
+
+// TexteCentre écrit une ligne de texte centrée sur x
+func (p *Page) TexteCentre(x, y float64, police string, taille float64, texte string) {
+	p.Texte(x-LargeurTexte(texte, police, taille)/2, y, police, taille, texte)
+}
+
+// Ecrire produit le fichier PDF
+func (d *Document) Ecrire(w io.Writer) error {
+	var sortie bytes.Buffer
+	var positions []int
+
+	objet := func(contenu string) {
+		positions = append(positions, sortie.Len())
+		fmt.Fprintf(&sortie, "%d 0 obj\n%s\nendobj\n", len(positions), contenu)
+	}
+
+	sortie.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objets 1 à 5 : catalogue, arbre des pages, polices, informations
+	premierePage := 6
+	var enfants []string
+	for i := range d.pages {
+		enfants = append(enfants, fmt.Sprintf("%d 0 R", premierePage+2*i))
+	}
+	objet("<< /Type /Catalog /Pages 2 0 R >>")
+	objet(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(enfants, " "), len(d.pages)))
+	objet("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objet("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	objet(fmt.Sprintf("<< /Title (%s) /Producer (gestion-librairie) >>", encoderTexte(d.titre)))
+
+	// Chaque page est suivie de son flux de contenu
+	for i, page := range d.pages {
+		objet(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 %d 0 R >> >> /Contents %d 0 R >>",
+			page.Largeur, page.Hauteur, premierePage+2*len(d.pages), premierePage+2*i+1))
+		objet(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.contenu.Len(), page.contenu.String()))
+	}
+	objet("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	debutXref := sortie.Len()
+	fmt.Fprintf(&sortie, "xref\n0 %d\n0000000000 65535 f \n", len(positions)+1)
+	for _, position := range positions {
+		fmt.Fprintf(&sortie, "%010d 00000 n \n", position)
+	}
+	fmt.Fprintf(&sortie, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(positions)+1, debutXref)
+
+	_, err := w.Write(sortie.Bytes())
+	return err
+}
+
+// ========================================
+// TEXTE : ENCODAGE WINANSI ET MÉTRIQUES
+// ========================================
+
+// winAnsiSpeciaux complète Latin-1 avec les caractères typographiques courants
+var winAnsiSpeciaux = map[rune]byte{
+	'€': 0x80, '…': 0x85, 'Œ': 0x8C, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, 'œ': 0x9C, 'Ÿ': 0x9F,
+}
+
+// encoderTexte convertit en WinAnsi (les caractères absents deviennent « ? »)
+// et protège les caractères spéciaux des chaînes PDF
+func encoderTexte(texte string) string {
+	var resultat strings.Builder
+	for _, r := range texte {
+		var b byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			resultat.WriteByte('\\')
+			b = byte(r)
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			b = byte(r)
+		default:
+			var ok bool
+			if b, ok = winAnsiSpeciaux[r]; !ok {
+				b = '?'
+			}
+		}
+		resultat.WriteByte(b)
+	}
+	return resultat.String()
+}
+
+// largeursHelvetica donne la chasse des caractères ASCII 32 à 126, en millièmes de corps
+var largeursHelvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// LargeurTexte estime la largeur d'un texte en points. Le gras est compté
+// 5 % plus large ; les lettres accentuées prennent la chasse de leur lettre de base.
+func LargeurTexte(texte, police string, taille float64) float64 {
+	if police == POLICE_FIXE {
+		return float64(len([]rune(texte))) * 600 * taille / 1000
+	}
+
+	total := 0
+	for _, r := range texte {
+		r = lettreDeBase(r)
+		if r >= 32 && r <= 126 {
+			total += largeursHelvetica[r-32]
+		} else {
+			total += 556
+		}
+	}
+	largeur := float64(total) * taille / 1000
+	if police == POLICE_GRASSE {
+		largeur *= 1.05
+	}
+	return largeur
+}
+
+func lettreDeBase(r rune) rune {
+	switch {
+	case strings.ContainsRune("àáâãäå", r):
+		return 'a'
+	case strings.ContainsRune("ÀÁÂÃÄÅ", r):
+		return 'A'
+	case strings.ContainsRune("èéêë", r):
+		return 'e'
+	case strings.ContainsRune("ÈÉÊË", r):
+		return 'E'
+	case strings.ContainsRune("ìíîï", r):
+		return 'i'
+	case strings.ContainsRune("òóôõö", r):
+		return 'o'
+	case strings.ContainsRune("ùúûü", r):
+		return 'u'
+	case r == 'ç':
+		return 'c'
+	case r == 'Ç':
+		return 'C'
+	}
+	return r
+}
+
+// Couper tronque un texte pour qu'il tienne dans la largeur donnée
+func Couper(texte, police string, taille, largeurMax float64) string {
+	if LargeurTexte(texte, police, taille) <= largeurMax {
+		return texte
+	}
+	runes := []rune(texte)
+	for len(runes) > 0 && LargeurTexte(string(runes)+"…", police, taille) > largeurMax {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,58 @@
+// ==========================================
+// internal/portail/envoi.go
+// ENVOI DES COURRIELS DU PORTAIL
+// ==========================================
+
+package portail
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Expediteur achemine les courriels du portail (liens de connexion)
+type Expediteur interface {
+	Envoyer(destinataire, sujet, corps string) error
+}
+
+// BoiteEnvoi dépose chaque courriel dans un fichier .eml du dossier donné.
+// Le portail fonctionne ainsi sans serveur de messagerie ni accès à Internet :
+// les messages sont relayés par l'outil de messagerie de la bibliothèque,
+// ou lus par l'accueil qui transmet le lien au membre.
+type BoiteEnvoi struct {
+	Dossier string
+}
+
+func (b BoiteEnvoi) Envoyer(destinataire, sujet, corps string) error {
+	if err := os.MkdirAll(b.Dossier, 0700); err != nil {
+		return fmt.Errorf("impossible de créer la boîte d'envoi %s : %v", b.Dossier, err)
+	}
+
+	suffixe := make([]byte, 4)
+	if _, err := rand.Read(suffixe); err != nil {
+		return fmt.Errorf("impossible de nommer le courriel : %v", err)
+	}
+	maintenant := time.Now()
+	chemin := filepath.Join(b.Dossier, fmt.Sprintf("%s-%s.eml", maintenant.Format("20060102-150405"), hex.EncodeToString(suffixe)))
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "To: %s\r\n", destinataire)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", sujet))
+	fmt.Fprintf(&message, "Date: %s\r\n", maintenant.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(corps, "\n", "\r\n"))
+
+	// Le lien donne accès au compte : le fichier n'est lisible que par l'application
+	if err := os.WriteFile(chemin, []byte(message.String()), 0600); err != nil {
+		return fmt.Errorf("impossible d'écrire le courriel %s : %v", chemin, err)
+	}
+	return nil
+}
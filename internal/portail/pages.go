@@ -0,0 +1,421 @@
+// ==========================================
+// internal/portail/pages.go
+// PAGES DU PORTAIL : CONNEXION, COMPTE, CATALOGUE, PROFIL
+// ==========================================
+
+package portail
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/recommandations"
+)
+
+// RESULTATS_MAX limite le nombre de livres affichés par recherche dans le catalogue
+const RESULTATS_MAX = 50
+
+func (s *Serveur) accueil(w http.ResponseWriter, r *http.Request) {
+	if _, sess := s.sessionCourante(r); sess != nil {
+		http.Redirect(w, r, "/compte", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/connexion", http.StatusSeeOther)
+}
+
+// ========================================
+// CONNEXION
+// ========================================
+
+func (s *Serveur) pageConnexion(w http.ResponseWriter, r *http.Request) {
+	s.afficher(w, http.StatusOK, "connexion", "Connexion", nil, nil)
+}
+
+// connexion vérifie l'email et le mot de passe, dans la limite de ESSAIS_MAX essais
+func (s *Serveur) connexion(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.PostFormValue("email"))
+	cles := clesEssais(r, email)
+
+	if s.essaisEpuises(cles...) {
+		s.afficher(w, http.StatusTooManyRequests, "connexion", "Connexion", nil, map[string]any{
+			"Email":  email,
+			"Erreur": "Trop d'essais ratés, réessayez dans quelques minutes ou demandez un lien de connexion.",
+		})
+		return
+	}
+
+	membre, err := s.gestionnaireMembres.AuthentifierParEmail(email, r.PostFormValue("mot_de_passe"))
+	if err != nil {
+		s.compterEssai(cles...)
+		s.afficher(w, http.StatusUnauthorized, "connexion", "Connexion", nil, map[string]any{
+			"Email":  email,
+			"Erreur": err.Error(),
+		})
+		return
+	}
+
+	s.ouvrirSession(w, r, membre.ID)
+	http.Redirect(w, r, "/compte", http.StatusSeeOther)
+}
+
+// demanderLien envoie un lien de connexion à usage unique. La réponse est la même
+// que l'adresse soit connue ou non, pour ne pas révéler qui est inscrit.
+func (s *Serveur) demanderLien(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.PostFormValue("email"))
+	cles := clesEssais(r, email)
+
+	if s.essaisEpuises(cles...) {
+		s.afficher(w, http.StatusTooManyRequests, "connexion", "Connexion", nil, map[string]any{
+			"Email":  email,
+			"Erreur": "Trop de demandes, réessayez dans quelques minutes.",
+		})
+		return
+	}
+	s.compterEssai(cles...)
+
+	if membre, _ := s.gestionnaireMembres.TrouverMembreParEmail(email); membre != nil && !membre.EstRetire() {
+		lien := s.urlPublique() + "/lien/" + s.creerLien(membre.ID)
+		corps := fmt.Sprintf("Bonjour %s,\n\nPour vous connecter au portail de la bibliothèque, ouvrez ce lien :\n\n%s\n\n"+
+			"Il est valable %d minutes et ne sert qu'une fois.\nSi vous n'avez rien demandé, ignorez ce message.\n",
+			membre.Nom, lien, s.config.Portail.DureeLienMinutes)
+
+		if err := s.expediteur.Envoyer(membre.Email, "Votre lien de connexion", corps); err != nil {
+			s.afficher(w, http.StatusServiceUnavailable, "connexion", "Connexion", nil, map[string]any{
+				"Email":  email,
+				"Erreur": "Le courriel n'a pas pu être envoyé, adressez-vous à l'accueil.",
+			})
+			return
+		}
+	}
+
+	s.afficher(w, http.StatusOK, "connexion", "Connexion", nil, map[string]any{
+		"Info": fmt.Sprintf("Si cette adresse est connue, un lien de connexion vient d'y être envoyé (valable %d minutes).", s.config.Portail.DureeLienMinutes),
+	})
+}
+
+// pageLien demande de confirmer avant de consommer le lien : les logiciels qui
+// ouvrent les liens des courriels pour les analyser ne l'invalident pas
+func (s *Serveur) pageLien(w http.ResponseWriter, r *http.Request) {
+	jeton := r.PathValue("jeton")
+	if !s.lienValide(jeton) {
+		s.afficher(w, http.StatusGone, "lien", "Lien de connexion", nil, map[string]any{"Invalide": true})
+		return
+	}
+	s.afficher(w, http.StatusOK, "lien", "Lien de connexion", nil, map[string]any{"Jeton": jeton})
+}
+
+func (s *Serveur) utiliserLien(w http.ResponseWriter, r *http.Request) {
+	membreID, ok := s.consommerLien(r.PathValue("jeton"))
+	membre, _ := s.gestionnaireMembres.TrouverMembreParID(membreID)
+	if !ok || membre == nil || membre.EstRetire() {
+		s.afficher(w, http.StatusGone, "lien", "Lien de connexion", nil, map[string]any{"Invalide": true})
+		return
+	}
+
+	sess := s.ouvrirSession(w, r, membre.ID)
+	if membre.MotDePasse == "" {
+		sess.message = "Bienvenue ! Choisissez un mot de passe dans « Mon profil » pour vous connecter sans lien la prochaine fois."
+	}
+	http.Redirect(w, r, "/compte", http.StatusSeeOther)
+}
+
+func (s *Serveur) deconnexion(req *requete) {
+	s.fermerSession(req.w, req.jeton)
+	http.Redirect(req.w, req.r, "/connexion", http.StatusSeeOther)
+}
+
+// urlPublique retourne le début des liens envoyés par courriel. À défaut d'URL
+// configurée, le nom de la machine est utilisé plutôt que l'en-tête Host de la
+// requête, qu'un tiers pourrait falsifier pour détourner le lien.
+func (s *Serveur) urlPublique() string {
+	if s.config.Portail.URLPublique != "" {
+		return strings.TrimSuffix(s.config.Portail.URLPublique, "/")
+	}
+
+	hote, port, err := net.SplitHostPort(s.config.Portail.Adresse)
+	if err != nil {
+		hote, port = "", "80"
+	}
+	if hote == "" || hote == "0.0.0.0" || hote == "::" {
+		if nom, err := os.Hostname(); err == nil {
+			hote = nom
+		} else {
+			hote = "localhost"
+		}
+	}
+	return "http://" + net.JoinHostPort(hote, port)
+}
+
+// ========================================
+// COMPTE : EMPRUNTS, RÉSERVATIONS, HISTORIQUE
+// ========================================
+
+type ligneEmprunt struct {
+	models.Emprunt
+	Prolongeable bool
+	Raison       string // pourquoi l'emprunt ne peut pas être prolongé
+}
+
+type ligneReservation struct {
+	models.Reservation
+	Position int // rang dans la file d'attente (0 si le livre est mis de côté)
+}
+
+type ligneSuggestion struct {
+	recommandations.Recommandation
+	DejaReserve bool
+}
+
+func (s *Serveur) compte(req *requete) {
+	// Les livres non retirés à temps passent au membre suivant
+	s.gestionnaireReservations.ExpirerReservations()
+
+	var enCours []ligneEmprunt
+	var historique []models.Emprunt
+	for _, emprunt := range s.gestionnaireEmprunts.ListerEmpruntsParMembre(req.membre.ID) {
+		if emprunt.DateRetourEffectif != nil {
+			historique = append(historique, emprunt)
+			continue
+		}
+		emprunt.MettreAjourStatut()
+		ligne := ligneEmprunt{Emprunt: emprunt, Prolongeable: true}
+		if err := s.gestionnaireEmprunts.VerifierProlongation(emprunt); err != nil {
+			ligne.Prolongeable = false
+			ligne.Raison = err.Error()
+		}
+		enCours = append(enCours, ligne)
+	}
+	sort.Slice(historique, func(i, j int) bool {
+		return historique[i].DateRetourEffectif.After(*historique[j].DateRetourEffectif)
+	})
+
+	var reservations []ligneReservation
+	reserves := make(map[int]bool)
+	for _, reservation := range s.gestionnaireReservations.ListerReservationsParMembre(req.membre.ID) {
+		if reservation.EstActive() {
+			reservations = append(reservations, ligneReservation{
+				Reservation: reservation,
+				Position:    s.gestionnaireReservations.PositionDansFile(reservation),
+			})
+			reserves[reservation.LivreID] = true
+		}
+	}
+
+	// Les livres déjà empruntés n'y figurent jamais ; ceux déjà réservés restent
+	// affichés, sans bouton
+	var suggestions []ligneSuggestion
+	moteur := recommandations.Construire(s.gestionnaireLivres, s.gestionnaireEmprunts, s.gestionnaireReservations)
+	for _, suggestion := range moteur.Recommander(req.membre.ID, recommandations.K_PAR_DEFAUT) {
+		suggestions = append(suggestions, ligneSuggestion{Recommandation: suggestion, DejaReserve: reserves[suggestion.LivreID]})
+	}
+
+	s.afficher(req.w, http.StatusOK, "compte", "Mon compte", req, map[string]any{
+		"EnCours":      enCours,
+		"Reservations": reservations,
+		"Suggestions":  suggestions,
+		"Historique":   historique,
+	})
+}
+
+func (s *Serveur) prolonger(req *requete) {
+	id, _ := strconv.Atoi(req.r.PostFormValue("emprunt"))
+	emprunt, err := s.gestionnaireEmprunts.ProlongerEmpruntMembre(id, req.membre.ID)
+	if err != nil {
+		req.rediriger("/compte", err.Error(), true)
+		return
+	}
+	req.rediriger("/compte", fmt.Sprintf("« %s » est à rendre le %s.", emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006")), false)
+}
+
+// ========================================
+// CATALOGUE ET RÉSERVATIONS
+// ========================================
+
+type ligneCatalogue struct {
+	models.Livre
+	Etat        string // Disponible, Emprunté, Mis de côté
+	Attente     int    // membres dans la file d'attente
+	Reservable  bool
+	DejaReserve bool
+}
+
+func (s *Serveur) catalogue(req *requete) {
+	terme := strings.TrimSpace(req.r.URL.Query().Get("q"))
+
+	var resultats []ligneCatalogue
+	total := 0
+	if terme != "" {
+		reserves := make(map[int]bool)
+		for _, reservation := range s.gestionnaireReservations.ListerReservationsParMembre(req.membre.ID) {
+			reserves[reservation.LivreID] = reserves[reservation.LivreID] || reservation.EstActive()
+		}
+		empruntes := make(map[int]bool)
+		for _, emprunt := range s.gestionnaireEmprunts.ListerEmpruntsParMembre(req.membre.ID) {
+			empruntes[emprunt.LivreID] = empruntes[emprunt.LivreID] || emprunt.DateRetourEffectif == nil
+		}
+
+		livres := s.gestionnaireLivres.RechercherLivres(terme)
+		total = len(livres)
+		// Le membre n'a pas à savoir si la recherche a été notée pour les achats
+		_ = s.gestionnaireRecherches.NoterRecherche(terme, total)
+		for _, livre := range livres[:min(len(livres), RESULTATS_MAX)] {
+			ligne := ligneCatalogue{
+				Livre:       livre,
+				Etat:        "Disponible",
+				Attente:     len(s.gestionnaireReservations.FileAttente(livre.ID)),
+				DejaReserve: reserves[livre.ID],
+			}
+			if !livre.EstDisponible() {
+				ligne.Etat = "Emprunté"
+			} else if s.gestionnaireReservations.ReservationPrete(livre.ID) != nil {
+				ligne.Etat = "Mis de côté"
+			}
+			ligne.Reservable = !ligne.DejaReserve && !empruntes[livre.ID]
+			resultats = append(resultats, ligne)
+		}
+	}
+
+	s.afficher(req.w, http.StatusOK, "catalogue", "Catalogue", req, map[string]any{
+		"Terme":     terme,
+		"Resultats": resultats,
+		"Total":     total,
+		"Tronque":   total > RESULTATS_MAX,
+		"Max":       RESULTATS_MAX,
+	})
+}
+
+func (s *Serveur) reserver(req *requete) {
+	id, _ := strconv.Atoi(req.r.PostFormValue("livre"))
+	reservation, err := s.gestionnaireEmprunts.ReserverLivre(id, req.membre.ID)
+	if err != nil {
+		req.rediriger("/catalogue?q="+url.QueryEscape(req.r.PostFormValue("q")), err.Error(), true)
+		return
+	}
+
+	if reservation.Statut == models.RESERVATION_PRETE {
+		req.rediriger("/compte", fmt.Sprintf("« %s » est mis de côté pour vous à l'accueil jusqu'au %s.",
+			reservation.TitreLivre, reservation.DateLimite.Format("02/01/2006")), false)
+		return
+	}
+	req.rediriger("/compte", fmt.Sprintf("Réservation enregistrée : vous êtes n° %d dans la file d'attente de « %s ».",
+		s.gestionnaireReservations.PositionDansFile(reservation), reservation.TitreLivre), false)
+}
+
+func (s *Serveur) annulerReservation(req *requete) {
+	id, _ := strconv.Atoi(req.r.PostFormValue("reservation"))
+	if err := s.gestionnaireReservations.AnnulerReservation(id, req.membre.ID); err != nil {
+		req.rediriger("/compte", err.Error(), true)
+		return
+	}
+	req.rediriger("/compte", "Réservation annulée.", false)
+}
+
+// ========================================
+// PROFIL ET DONNÉES PERSONNELLES
+// ========================================
+
+func (s *Serveur) profil(req *requete) {
+	s.afficher(req.w, http.StatusOK, "profil", "Mon profil", req, nil)
+}
+
+// modifierProfil passe par les mêmes validations que l'accueil (ModifierMembre)
+func (s *Serveur) modifierProfil(req *requete) {
+	email := strings.TrimSpace(req.r.PostFormValue("email"))
+	telephone := strings.TrimSpace(req.r.PostFormValue("telephone"))
+	if email == "" || telephone == "" {
+		req.rediriger("/profil", "L'email et le téléphone sont obligatoires.", true)
+		return
+	}
+
+	if err := s.gestionnaireMembres.ModifierMembre(req.membre.ID, "", email, telephone); err != nil {
+		req.rediriger("/profil", err.Error(), true)
+		return
+	}
+	req.rediriger("/profil", "Vos coordonnées ont été mises à jour.", false)
+}
+
+func (s *Serveur) changerMotDePasse(req *requete) {
+	// Le mot de passe actuel est demandé s'il y en a un : une session laissée
+	// ouverte ne suffit pas à s'approprier le compte
+	if req.membre.MotDePasse != "" {
+		cles := clesEssais(req.r, req.membre.Email)
+		if s.essaisEpuises(cles...) {
+			req.rediriger("/profil", "Trop d'essais ratés, réessayez dans quelques minutes.", true)
+			return
+		}
+		if _, err := s.gestionnaireMembres.AuthentifierParEmail(req.membre.Email, req.r.PostFormValue("actuel")); err != nil {
+			s.compterEssai(cles...)
+			req.rediriger("/profil", "Le mot de passe actuel est incorrect.", true)
+			return
+		}
+	}
+
+	nouveau := req.r.PostFormValue("nouveau")
+	if nouveau != req.r.PostFormValue("confirmation") {
+		req.rediriger("/profil", "Les deux saisies du nouveau mot de passe ne correspondent pas.", true)
+		return
+	}
+	if err := s.gestionnaireMembres.DefinirMotDePasse(req.membre.ID, nouveau); err != nil {
+		req.rediriger("/profil", err.Error(), true)
+		return
+	}
+	req.rediriger("/profil", "Mot de passe enregistré.", false)
+}
+
+// exportMembre reprend la fiche du membre sans les empreintes du code PIN et du mot de passe
+type exportMembre struct {
+	ID               int       `json:"id"`
+	Nom              string    `json:"nom"`
+	Email            string    `json:"email"`
+	Telephone        string    `json:"telephone"`
+	NumeroCarte      string    `json:"numero_carte"`
+	DateInscription  time.Time `json:"date_inscription"`
+	NombreEmprunts   int       `json:"nombre_emprunts"`
+	EmpruntsActifs   int       `json:"emprunts_actifs"`
+	Actif            bool      `json:"actif"`
+	CodePINDefini    bool      `json:"code_pin_defini"`
+	MotDePasseDefini bool      `json:"mot_de_passe_defini"`
+}
+
+type exportDonnees struct {
+	DateExport   time.Time            `json:"date_export"`
+	Membre       exportMembre         `json:"membre"`
+	Emprunts     []models.Emprunt     `json:"emprunts"`
+	Reservations []models.Reservation `json:"reservations"`
+}
+
+// telechargerDonnees fournit au membre toutes les données le concernant, en JSON
+func (s *Serveur) telechargerDonnees(req *requete) {
+	m := req.membre
+	donnees := exportDonnees{
+		DateExport: time.Now(),
+		Membre: exportMembre{
+			ID: m.ID, Nom: m.Nom, Email: m.Email, Telephone: m.Telephone, NumeroCarte: m.NumeroCarte,
+			DateInscription: m.DateInscription, NombreEmprunts: m.NombreEmprunts, EmpruntsActifs: m.EmpruntsActifs,
+			Actif: m.Actif, CodePINDefini: m.PIN != "", MotDePasseDefini: m.MotDePasse != "",
+		},
+		Emprunts:     s.gestionnaireEmprunts.ListerEmpruntsParMembre(m.ID),
+		Reservations: s.gestionnaireReservations.ListerReservationsParMembre(m.ID),
+	}
+	if donnees.Emprunts == nil {
+		donnees.Emprunts = []models.Emprunt{}
+	}
+	if donnees.Reservations == nil {
+		donnees.Reservations = []models.Reservation{}
+	}
+
+	req.w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	req.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="mes-donnees-%s.json"`, m.NumeroCarte))
+	encodeur := json.NewEncoder(req.w)
+	encodeur.SetIndent("", "  ")
+	encodeur.Encode(donnees)
+}
@@ -0,0 +1,232 @@
+// ==========================================
+// internal/portail/serveur.go
+// PORTAIL WEB DES MEMBRES
+// ==========================================
+
+package portail
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// SERVEUR
+// Les pages sont rendues côté serveur, sans script ni ressource externe :
+// le portail fonctionne sur le réseau local de la bibliothèque, hors ligne.
+// Toutes les requêtes sont servies sous le verrou partagé avec l'écran du
+// comptoir, ce qui protège à la fois les données et l'état du portail
+// (sessions, liens, compteurs d'essais).
+// ========================================
+
+//go:embed gabarits/*.html
+var fichiersGabarits embed.FS
+
+type Serveur struct {
+	config                   *config.Config
+	gestionnaireLivres       *services.GestionnaireLivres
+	gestionnaireMembres      *services.GestionnaireMembres
+	gestionnaireEmprunts     *services.GestionnaireEmprunts
+	gestionnaireReservations *services.GestionnaireReservations
+	gestionnaireRecherches   *services.GestionnaireRecherches
+	expediteur               Expediteur
+
+	verrou   sync.Locker
+	gabarits map[string]*template.Template
+
+	sessions map[string]*session
+	liens    map[string]lienConnexion
+	essais   map[string]*compteurEssais
+}
+
+// NouveauServeur prépare le portail ; les courriels sont déposés dans la boîte
+// d'envoi de la configuration. verrou est celui que tient l'écran du comptoir.
+func NouveauServeur(cfg *config.Config, gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts, gr *services.GestionnaireReservations, grc *services.GestionnaireRecherches, verrou sync.Locker) *Serveur {
+	return &Serveur{
+		config:                   cfg,
+		gestionnaireLivres:       gl,
+		gestionnaireMembres:      gm,
+		gestionnaireEmprunts:     ge,
+		gestionnaireReservations: gr,
+		gestionnaireRecherches:   grc,
+		expediteur:               BoiteEnvoi{Dossier: cfg.Portail.BoiteEnvoi},
+
+		verrou:   verrou,
+		gabarits: chargerGabarits(),
+
+		sessions: make(map[string]*session),
+		liens:    make(map[string]lienConnexion),
+		essais:   make(map[string]*compteurEssais),
+	}
+}
+
+// UtiliserExpediteur remplace la boîte d'envoi (serveur de messagerie, tests)
+func (s *Serveur) UtiliserExpediteur(expediteur Expediteur) {
+	s.expediteur = expediteur
+}
+
+// Handler retourne les routes du portail
+func (s *Serveur) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", s.servir(s.accueil))
+	mux.HandleFunc("GET /connexion", s.servir(s.pageConnexion))
+	mux.HandleFunc("POST /connexion", s.servir(s.connexion))
+	mux.HandleFunc("POST /lien", s.servir(s.demanderLien))
+	mux.HandleFunc("GET /lien/{jeton}", s.servir(s.pageLien))
+	mux.HandleFunc("POST /lien/{jeton}", s.servir(s.utiliserLien))
+
+	mux.HandleFunc("GET /compte", s.servir(s.membre(s.compte)))
+	mux.HandleFunc("POST /prolonger", s.servir(s.membre(s.formulaire(s.prolonger))))
+	mux.HandleFunc("GET /catalogue", s.servir(s.membre(s.catalogue)))
+	mux.HandleFunc("POST /reserver", s.servir(s.membre(s.formulaire(s.reserver))))
+	mux.HandleFunc("POST /reservations/annuler", s.servir(s.membre(s.formulaire(s.annulerReservation))))
+	mux.HandleFunc("GET /profil", s.servir(s.membre(s.profil)))
+	mux.HandleFunc("POST /profil", s.servir(s.membre(s.formulaire(s.modifierProfil))))
+	mux.HandleFunc("POST /mot-de-passe", s.servir(s.membre(s.formulaire(s.changerMotDePasse))))
+	mux.HandleFunc("GET /donnees", s.servir(s.membre(s.telechargerDonnees)))
+	mux.HandleFunc("POST /deconnexion", s.servir(s.membre(s.formulaire(s.deconnexion))))
+
+	return mux
+}
+
+// ========================================
+// INTERMÉDIAIRES
+// ========================================
+
+// requete regroupe ce que les pages réservées aux membres connaissent de l'appel
+type requete struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	jeton   string
+	session *session
+	membre  *models.Membre
+}
+
+// servir prend le verrou partagé et pose les en-têtes de sécurité
+func (s *Serveur) servir(page func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.verrou.Lock()
+		defer s.verrou.Unlock()
+
+		s.nettoyer()
+
+		entetes := w.Header()
+		entetes.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'")
+		entetes.Set("X-Content-Type-Options", "nosniff")
+		entetes.Set("Referrer-Policy", "no-referrer") // les liens de connexion contiennent un jeton
+		entetes.Set("Cache-Control", "no-store")
+
+		page(w, r)
+	}
+}
+
+// membre réserve une page aux membres connectés ; les autres vont à la connexion
+func (s *Serveur) membre(page func(*requete)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jeton, sess := s.sessionCourante(r)
+		if sess == nil {
+			http.Redirect(w, r, "/connexion", http.StatusSeeOther)
+			return
+		}
+
+		// Un membre radié entre-temps perd l'accès au portail
+		membre, _ := s.gestionnaireMembres.TrouverMembreParID(sess.membreID)
+		if membre == nil || membre.EstRetire() {
+			s.fermerSession(w, jeton)
+			http.Redirect(w, r, "/connexion", http.StatusSeeOther)
+			return
+		}
+
+		page(&requete{w: w, r: r, jeton: jeton, session: sess, membre: membre})
+	}
+}
+
+// formulaire vérifie le jeton CSRF de chaque envoi de formulaire
+func (s *Serveur) formulaire(action func(*requete)) func(*requete) {
+	return func(req *requete) {
+		if req.r.PostFormValue("csrf") != req.session.jetonCSRF {
+			http.Error(req.w, "Formulaire expiré, rechargez la page.", http.StatusForbidden)
+			return
+		}
+		action(req)
+	}
+}
+
+// ========================================
+// RENDU
+// ========================================
+
+var fonctionsGabarits = template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("02/01/2006") },
+	"datePtr": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("02/01/2006")
+	},
+	"statutReservation": models.LibelleStatutReservation,
+}
+
+// chargerGabarits associe chaque page au gabarit commun
+func chargerGabarits() map[string]*template.Template {
+	pages, err := fichiersGabarits.ReadDir("gabarits")
+	if err != nil {
+		panic(err)
+	}
+
+	gabarits := make(map[string]*template.Template)
+	for _, page := range pages {
+		nom := page.Name()
+		if nom == "base.html" {
+			continue
+		}
+		gabarits[strings.TrimSuffix(nom, ".html")] = template.Must(
+			template.New("base.html").Funcs(fonctionsGabarits).ParseFS(fichiersGabarits, "gabarits/base.html", "gabarits/"+nom))
+	}
+	return gabarits
+}
+
+// afficher rend une page ; les données communes (membre, message, jeton CSRF) sont ajoutées
+func (s *Serveur) afficher(w http.ResponseWriter, statut int, page, titre string, req *requete, donnees map[string]any) {
+	if donnees == nil {
+		donnees = make(map[string]any)
+	}
+	donnees["Titre"] = titre
+
+	if req != nil {
+		donnees["Membre"] = req.membre
+		donnees["CSRF"] = req.session.jetonCSRF
+		if req.session.message != "" {
+			donnees["Message"] = req.session.message
+			donnees["MessageErreur"] = req.session.messageErreur
+			req.session.message = ""
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statut)
+	if err := s.gabarits[page].Execute(w, donnees); err != nil {
+		fmt.Fprintf(w, "<p>Erreur d'affichage : %s</p>", template.HTMLEscapeString(err.Error()))
+	}
+}
+
+// rediriger affiche message sur la page suivante (après une action POST)
+func (req *requete) rediriger(chemin, message string, erreur bool) {
+	req.session.message = message
+	req.session.messageErreur = erreur
+	http.Redirect(req.w, req.r, chemin, http.StatusSeeOther)
+}
+
+func (s *Serveur) dureeSession() time.Duration {
+	return time.Duration(s.config.Portail.DureeSessionMinutes) * time.Minute
+}
@@ -0,0 +1,434 @@
+package portail
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+const (
+	emailZoe      = "zoe@example.com"
+	motDePasseZoe = "un mot de passe"
+)
+
+var motifLien = regexp.MustCompile(`/lien/([0-9a-f]{64})`)
+
+// boiteDeTest garde les courriels au lieu de les déposer dans la boîte d'envoi
+type boiteDeTest struct {
+	corps []string
+}
+
+func (b *boiteDeTest) Envoyer(destinataire, sujet, corps string) error {
+	b.corps = append(b.corps, corps)
+	return nil
+}
+
+type portailDeTest struct {
+	serveur *Serveur
+	handler http.Handler
+	boite   *boiteDeTest
+	membres *services.GestionnaireMembres
+}
+
+// nouveauPortailDeTest assemble le portail comme main.go, avec deux membres :
+// Zoé (ID 1, qui a un mot de passe) et Marc (ID 2, qui a un livre emprunté)
+func nouveauPortailDeTest(t *testing.T) *portailDeTest {
+	t.Helper()
+
+	cfg := config.Defaut()
+	cfg.Donnees.Dossier = t.TempDir()
+	cfg.Portail.URLPublique = "https://portail.example.com"
+	chemin := func(fichier, schema string) storage.Storage {
+		return storage.NewJSONStorage(cfg.Chemin(fichier), schema)
+	}
+
+	sq, err := storage.NewSequences(chemin(cfg.Donnees.Sequences, storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsu := services.NouveauGestionnaireSuccursales(chemin(cfg.Donnees.Succursales, storage.SCHEMA_SUCCURSALES), sq)
+	gg := services.NouveauGestionnaireGenres(chemin(cfg.Donnees.Genres, storage.SCHEMA_GENRES), sq, cfg.Validation.Genres)
+	gc := services.NouveauGestionnaireContributeurs(chemin(cfg.Donnees.Contributeurs, storage.SCHEMA_CONTRIBUTEURS), sq)
+	gl := services.NouveauGestionnaireLivres(chemin(cfg.Donnees.Livres, storage.SCHEMA_LIVRES), sq,
+		validators.NouveauValidateur(cfg.Validation.AnneePublicationMin), gg, gc, gsu)
+	gm := services.NouveauGestionnaireMembres(chemin(cfg.Donnees.Membres, storage.SCHEMA_MEMBRES), sq, cfg.Emprunts.LimiteSimultanes, gsu)
+	gr := services.NouveauGestionnaireReservations(chemin(cfg.Donnees.Reservations, storage.SCHEMA_RESERVATIONS), sq, gl, gm, cfg.Emprunts.DelaiRetraitJours)
+	ge, err := services.NouveauGestionnaireEmprunts(chemin(cfg.Donnees.Emprunts, storage.SCHEMA_EMPRUNTS),
+		storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal)), chemin(cfg.Donnees.Instantanes, storage.SCHEMA_INSTANTANES),
+		sq, gl, gm, gr, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grc := services.NouveauGestionnaireRecherches(chemin(cfg.Donnees.Recherches, storage.SCHEMA_RECHERCHES))
+
+	for _, err := range []error{
+		gl.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"),
+		gm.AjouterMembre("Zoé Dupont", emailZoe, "0601020304"),
+		gm.AjouterMembre("Marc Petit", "marc@example.com", "0605060708"),
+		gm.DefinirMotDePasse(1, motDePasseZoe),
+		ge.EmprunterLivre(1, 2),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NouveauServeur(cfg, gl, gm, ge, gr, grc, &sync.Mutex{})
+	boite := &boiteDeTest{}
+	s.UtiliserExpediteur(boite)
+	return &portailDeTest{serveur: s, handler: s.Handler(), boite: boite, membres: gm}
+}
+
+// appeler envoie une requête depuis le poste donné, avec le cookie de session s'il y en a un
+func (p *portailDeTest) appeler(methode, chemin string, valeurs url.Values, cookie *http.Cookie, poste string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(methode, chemin, strings.NewReader(valeurs.Encode()))
+	if valeurs != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	if poste != "" {
+		r.RemoteAddr = poste
+	}
+
+	w := httptest.NewRecorder()
+	p.handler.ServeHTTP(w, r)
+	return w
+}
+
+// connecter ouvre une session pour Zoé et retourne son cookie et son jeton CSRF
+func (p *portailDeTest) connecter(t *testing.T) (*http.Cookie, string) {
+	t.Helper()
+
+	w := p.appeler("POST", "/connexion", url.Values{"email": {emailZoe}, "mot_de_passe": {motDePasseZoe}}, nil, "")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("connexion : statut %d, attendu %d", w.Code, http.StatusSeeOther)
+	}
+	cookie := cookieSession(w)
+	if cookie == nil {
+		t.Fatal("connexion : aucun cookie de session")
+	}
+	return cookie, p.serveur.sessions[cookie.Value].jetonCSRF
+}
+
+func cookieSession(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == COOKIE_SESSION {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// verifierRedirection vérifie que la page renvoie à la connexion
+func verifierRedirection(t *testing.T, w *httptest.ResponseRecorder, quoi string) {
+	t.Helper()
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/connexion" {
+		t.Errorf("%s : statut %d vers %q, attendu %d vers /connexion", quoi, w.Code, w.Header().Get("Location"), http.StatusSeeOther)
+	}
+}
+
+// ========================================
+// CSRF
+// ========================================
+
+func TestFormulairesSansJetonCSRF(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+	cookie, csrf := p.connecter(t)
+
+	formulaires := []struct {
+		chemin  string
+		valeurs url.Values
+	}{
+		{"/profil", url.Values{"email": {"pirate@example.com"}, "telephone": {"0600000000"}}},
+		{"/mot-de-passe", url.Values{"actuel": {motDePasseZoe}, "nouveau": {"piraté piraté"}, "confirmation": {"piraté piraté"}}},
+		{"/reserver", url.Values{"livre": {"1"}}},
+		{"/prolonger", url.Values{"emprunt": {"1"}}},
+		{"/reservations/annuler", url.Values{"reservation": {"1"}}},
+		{"/deconnexion", url.Values{}},
+	}
+	for _, f := range formulaires {
+		for nom, jeton := range map[string]string{"sans jeton": "", "jeton d'une autre session": jetonAleatoire()} {
+			valeurs := url.Values{"csrf": {jeton}}
+			for cle, v := range f.valeurs {
+				valeurs[cle] = v
+			}
+			if w := p.appeler("POST", f.chemin, valeurs, cookie, ""); w.Code != http.StatusForbidden {
+				t.Errorf("%s %s : statut %d, attendu %d", f.chemin, nom, w.Code, http.StatusForbidden)
+			}
+		}
+	}
+
+	// Aucun formulaire refusé n'a eu d'effet : la session et le compte sont intacts
+	zoe, _ := p.membres.TrouverMembreParID(1)
+	if zoe.Email != emailZoe {
+		t.Errorf("email modifié malgré le refus : %s", zoe.Email)
+	}
+	if _, err := p.membres.AuthentifierParEmail(emailZoe, motDePasseZoe); err != nil {
+		t.Errorf("mot de passe modifié malgré le refus : %v", err)
+	}
+	if _, ok := p.serveur.sessions[cookie.Value]; !ok {
+		t.Fatal("session fermée par une déconnexion sans jeton")
+	}
+
+	// Avec le bon jeton, le formulaire passe
+	w := p.appeler("POST", "/deconnexion", url.Values{"csrf": {csrf}}, cookie, "")
+	verifierRedirection(t, w, "déconnexion")
+	verifierRedirection(t, p.appeler("GET", "/compte", nil, cookie, ""), "compte après déconnexion")
+}
+
+// ========================================
+// LIENS DE CONNEXION
+// ========================================
+
+// demanderLien demande un lien pour Zoé et retourne le jeton reçu par courriel
+func (p *portailDeTest) demanderLien(t *testing.T) string {
+	t.Helper()
+
+	if w := p.appeler("POST", "/lien", url.Values{"email": {emailZoe}}, nil, ""); w.Code != http.StatusOK {
+		t.Fatalf("demande de lien : statut %d", w.Code)
+	}
+	if len(p.boite.corps) == 0 {
+		t.Fatal("aucun courriel envoyé")
+	}
+	trouve := motifLien.FindStringSubmatch(p.boite.corps[len(p.boite.corps)-1])
+	if trouve == nil {
+		t.Fatalf("pas de lien dans le courriel : %s", p.boite.corps[len(p.boite.corps)-1])
+	}
+	return trouve[1]
+}
+
+func TestLienAUsageUnique(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+	jeton := p.demanderLien(t)
+
+	// Seule l'empreinte du jeton est gardée en mémoire
+	if _, ok := p.serveur.liens[jeton]; ok {
+		t.Error("le jeton est gardé tel quel")
+	}
+	if _, ok := p.serveur.liens[empreinteJeton(jeton)]; !ok {
+		t.Error("l'empreinte du jeton est introuvable")
+	}
+
+	// Ouvrir le lien (ce que font les analyseurs de courriels) ne le consomme pas
+	for i := 0; i < 2; i++ {
+		if w := p.appeler("GET", "/lien/"+jeton, nil, nil, ""); w.Code != http.StatusOK {
+			t.Fatalf("ouverture %d du lien : statut %d", i+1, w.Code)
+		}
+	}
+
+	w := p.appeler("POST", "/lien/"+jeton, nil, nil, "")
+	if w.Code != http.StatusSeeOther || cookieSession(w) == nil {
+		t.Fatalf("utilisation du lien : statut %d, cookie %v", w.Code, cookieSession(w))
+	}
+	if w := p.appeler("GET", "/compte", nil, cookieSession(w), ""); w.Code != http.StatusOK {
+		t.Errorf("compte après le lien : statut %d", w.Code)
+	}
+
+	// Une seconde utilisation est refusée
+	if w := p.appeler("POST", "/lien/"+jeton, nil, nil, ""); w.Code != http.StatusGone || cookieSession(w) != nil {
+		t.Errorf("seconde utilisation : statut %d, attendu %d sans session", w.Code, http.StatusGone)
+	}
+	if w := p.appeler("GET", "/lien/"+jeton, nil, nil, ""); w.Code != http.StatusGone {
+		t.Errorf("ouverture après utilisation : statut %d, attendu %d", w.Code, http.StatusGone)
+	}
+
+	// L'empreinte elle-même ne sert pas de jeton
+	autre := p.demanderLien(t)
+	if w := p.appeler("POST", "/lien/"+empreinteJeton(autre), nil, nil, ""); w.Code != http.StatusGone {
+		t.Errorf("empreinte utilisée comme jeton : statut %d, attendu %d", w.Code, http.StatusGone)
+	}
+}
+
+func TestLienRefuse(t *testing.T) {
+	cas := []struct {
+		nom      string
+		preparer func(t *testing.T, p *portailDeTest, jeton string)
+	}{
+		{"lien expiré", func(t *testing.T, p *portailDeTest, jeton string) {
+			lien := p.serveur.liens[empreinteJeton(jeton)]
+			lien.expire = time.Now().Add(-time.Second)
+			p.serveur.liens[empreinteJeton(jeton)] = lien
+		}},
+		{"membre radié après l'envoi", func(t *testing.T, p *portailDeTest, jeton string) {
+			if err := p.membres.RadierMembre(1, "test"); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"jeton inconnu", func(t *testing.T, p *portailDeTest, jeton string) {
+			delete(p.serveur.liens, empreinteJeton(jeton))
+		}},
+	}
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			p := nouveauPortailDeTest(t)
+			jeton := p.demanderLien(t)
+			c.preparer(t, p, jeton)
+
+			w := p.appeler("POST", "/lien/"+jeton, nil, nil, "")
+			if w.Code != http.StatusGone || cookieSession(w) != nil {
+				t.Errorf("statut %d, attendu %d sans session", w.Code, http.StatusGone)
+			}
+			if len(p.serveur.sessions) != 0 {
+				t.Errorf("%d session(s) ouverte(s)", len(p.serveur.sessions))
+			}
+		})
+	}
+}
+
+func TestDemandeDeLienSansIndice(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+
+	// Adresse connue ou non, la réponse est la même : seul le membre reçoit un courriel
+	connue := p.appeler("POST", "/lien", url.Values{"email": {emailZoe}}, nil, "")
+	inconnue := p.appeler("POST", "/lien", url.Values{"email": {"inconnu@example.com"}}, nil, "")
+	if connue.Code != inconnue.Code || connue.Body.String() != inconnue.Body.String() {
+		t.Errorf("réponses différentes : %d et %d", connue.Code, inconnue.Code)
+	}
+	if len(p.boite.corps) != 1 {
+		t.Errorf("%d courriel(s) envoyé(s), attendu 1", len(p.boite.corps))
+	}
+	if len(p.serveur.liens) != 1 {
+		t.Errorf("%d lien(s) créé(s), attendu 1", len(p.serveur.liens))
+	}
+}
+
+// ========================================
+// SESSIONS
+// ========================================
+
+func TestSessionExpiree(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+	cookie, _ := p.connecter(t)
+
+	p.serveur.sessions[cookie.Value].expire = time.Now().Add(-time.Second)
+	verifierRedirection(t, p.appeler("GET", "/compte", nil, cookie, ""), "session expirée")
+	if _, ok := p.serveur.sessions[cookie.Value]; ok {
+		t.Error("la session expirée est toujours en mémoire")
+	}
+
+	// Un cookie forgé ne vaut pas mieux qu'une session expirée
+	verifierRedirection(t, p.appeler("GET", "/compte", nil, &http.Cookie{Name: COOKIE_SESSION, Value: jetonAleatoire()}, ""), "cookie inconnu")
+}
+
+// ========================================
+// LIMITATION DES ESSAIS
+// ========================================
+
+func TestEssaisDeConnexionLimites(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+	const poste = "203.0.113.7:4000"
+
+	for i := 1; i <= ESSAIS_MAX; i++ {
+		valeurs := url.Values{"email": {emailZoe}, "mot_de_passe": {"mauvais mot de passe"}}
+		if w := p.appeler("POST", "/connexion", valeurs, nil, poste); w.Code != http.StatusUnauthorized {
+			t.Fatalf("essai %d : statut %d, attendu %d", i, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	bon := url.Values{"email": {emailZoe}, "mot_de_passe": {motDePasseZoe}}
+	cas := []struct {
+		nom     string
+		valeurs url.Values
+		poste   string
+		statut  int
+	}{
+		{"bon mot de passe après les essais ratés", bon, poste, http.StatusTooManyRequests},
+		// La limite porte sur l'adresse visée, quel que soit le poste
+		{"même adresse depuis un autre poste", bon, "198.51.100.1:5000", http.StatusTooManyRequests},
+		// ... et sur le poste, quelle que soit l'adresse visée
+		{"autre adresse depuis le même poste", url.Values{"email": {"marc@example.com"}, "mot_de_passe": {"x"}}, "203.0.113.7:4001", http.StatusTooManyRequests},
+		{"autre adresse depuis un autre poste", url.Values{"email": {"marc@example.com"}, "mot_de_passe": {"x"}}, "198.51.100.1:5000", http.StatusUnauthorized},
+	}
+	for _, c := range cas {
+		w := p.appeler("POST", "/connexion", c.valeurs, nil, c.poste)
+		if w.Code != c.statut {
+			t.Errorf("%s : statut %d, attendu %d", c.nom, w.Code, c.statut)
+		}
+		if cookieSession(w) != nil {
+			t.Errorf("%s : session ouverte", c.nom)
+		}
+	}
+
+	// Passée la fenêtre, le membre peut de nouveau se connecter
+	for _, compteur := range p.serveur.essais {
+		compteur.debut = time.Now().Add(-FENETRE_ESSAIS)
+	}
+	if w := p.appeler("POST", "/connexion", bon, nil, poste); w.Code != http.StatusSeeOther {
+		t.Errorf("après la fenêtre : statut %d, attendu %d", w.Code, http.StatusSeeOther)
+	}
+}
+
+func TestDemandesDeLienLimitees(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+
+	for i := 1; i <= ESSAIS_MAX; i++ {
+		if w := p.appeler("POST", "/lien", url.Values{"email": {emailZoe}}, nil, ""); w.Code != http.StatusOK {
+			t.Fatalf("demande %d : statut %d", i, w.Code)
+		}
+	}
+	if w := p.appeler("POST", "/lien", url.Values{"email": {emailZoe}}, nil, ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("demande de trop : statut %d, attendu %d", w.Code, http.StatusTooManyRequests)
+	}
+	if len(p.boite.corps) != ESSAIS_MAX {
+		t.Errorf("%d courriels envoyés, attendu %d", len(p.boite.corps), ESSAIS_MAX)
+	}
+}
+
+// ========================================
+// TÉLÉCHARGEMENT DES DONNÉES
+// ========================================
+
+func TestTelechargementDesDonnees(t *testing.T) {
+	p := nouveauPortailDeTest(t)
+
+	verifierRedirection(t, p.appeler("GET", "/donnees", nil, nil, ""), "sans session")
+
+	cookie, _ := p.connecter(t)
+	w := p.appeler("GET", "/donnees", nil, cookie, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("statut %d", w.Code)
+	}
+
+	// Zoé ne reçoit que ses données, sans les empreintes de ses secrets
+	var donnees struct {
+		Membre   map[string]any   `json:"membre"`
+		Emprunts []map[string]any `json:"emprunts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &donnees); err != nil {
+		t.Fatal(err)
+	}
+	if donnees.Membre["email"] != emailZoe {
+		t.Errorf("données de %v, attendu %s", donnees.Membre["email"], emailZoe)
+	}
+	if len(donnees.Emprunts) != 0 {
+		t.Errorf("%d emprunt(s) exporté(s), attendu aucun : l'emprunt en cours est celui de Marc", len(donnees.Emprunts))
+	}
+	if donnees.Membre["mot_de_passe_defini"] != true {
+		t.Errorf("mot_de_passe_defini = %v", donnees.Membre["mot_de_passe_defini"])
+	}
+	if strings.Contains(w.Body.String(), "pbkdf2") {
+		t.Error("l'export contient une empreinte de secret")
+	}
+
+	// Un membre radié perd l'accès et sa session est fermée
+	if err := p.membres.RadierMembre(1, "test"); err != nil {
+		t.Fatal(err)
+	}
+	verifierRedirection(t, p.appeler("GET", "/donnees", nil, cookie, ""), "membre radié")
+	if _, ok := p.serveur.sessions[cookie.Value]; ok {
+		t.Error("la session du membre radié est toujours ouverte")
+	}
+}
@@ -0,0 +1,192 @@
+// ==========================================
+// internal/portail/sessions.go
+// SESSIONS, LIENS DE CONNEXION ET LIMITATION DES ESSAIS
+// ==========================================
+
+package portail
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	COOKIE_SESSION = "session_portail"
+
+	// ESSAIS_MAX tentatives ratées (ou demandes de lien) par adresse email
+	// et par poste sont permises sur FENETRE_ESSAIS
+	ESSAIS_MAX     = 5
+	FENETRE_ESSAIS = 15 * time.Minute
+)
+
+// session est ouverte après une connexion réussie ; jetonCSRF accompagne chaque formulaire
+type session struct {
+	membreID  int
+	expire    time.Time
+	jetonCSRF string
+
+	// message est affiché une seule fois, sur la page suivante
+	message       string
+	messageErreur bool
+}
+
+// lienConnexion est un lien à usage unique envoyé par courriel
+type lienConnexion struct {
+	membreID int
+	expire   time.Time
+}
+
+type compteurEssais struct {
+	nombre int
+	debut  time.Time
+}
+
+// jetonAleatoire retourne 32 octets aléatoires en hexadécimal
+func jetonAleatoire() string {
+	octets := make([]byte, 32)
+	if _, err := rand.Read(octets); err != nil {
+		// Sans source aléatoire, aucun jeton ne serait sûr : inutile de continuer
+		panic("portail : source aléatoire indisponible : " + err.Error())
+	}
+	return hex.EncodeToString(octets)
+}
+
+// empreinteJeton évite de garder en mémoire les liens envoyés tels quels
+func empreinteJeton(jeton string) string {
+	somme := sha256.Sum256([]byte(jeton))
+	return hex.EncodeToString(somme[:])
+}
+
+// ouvrirSession crée la session du membre et pose le cookie
+func (s *Serveur) ouvrirSession(w http.ResponseWriter, r *http.Request, membreID int) *session {
+	jeton := jetonAleatoire()
+	sess := &session{
+		membreID:  membreID,
+		expire:    time.Now().Add(s.dureeSession()),
+		jetonCSRF: jetonAleatoire(),
+	}
+	s.sessions[jeton] = sess
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     COOKIE_SESSION,
+		Value:    jeton,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return sess
+}
+
+// sessionCourante retourne la session du cookie, prolongée à chaque requête
+func (s *Serveur) sessionCourante(r *http.Request) (string, *session) {
+	cookie, err := r.Cookie(COOKIE_SESSION)
+	if err != nil {
+		return "", nil
+	}
+
+	sess, ok := s.sessions[cookie.Value]
+	if !ok {
+		return "", nil
+	}
+	if time.Now().After(sess.expire) {
+		delete(s.sessions, cookie.Value)
+		return "", nil
+	}
+
+	sess.expire = time.Now().Add(s.dureeSession())
+	return cookie.Value, sess
+}
+
+func (s *Serveur) fermerSession(w http.ResponseWriter, jeton string) {
+	delete(s.sessions, jeton)
+	http.SetCookie(w, &http.Cookie{
+		Name:     COOKIE_SESSION,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// creerLien enregistre un lien de connexion et retourne le jeton à mettre dans l'URL
+func (s *Serveur) creerLien(membreID int) string {
+	jeton := jetonAleatoire()
+	s.liens[empreinteJeton(jeton)] = lienConnexion{
+		membreID: membreID,
+		expire:   time.Now().Add(time.Duration(s.config.Portail.DureeLienMinutes) * time.Minute),
+	}
+	return jeton
+}
+
+// lienValide indique si le jeton correspond à un lien encore utilisable, sans le consommer
+func (s *Serveur) lienValide(jeton string) bool {
+	lien, ok := s.liens[empreinteJeton(jeton)]
+	return ok && time.Now().Before(lien.expire)
+}
+
+// consommerLien retourne le membre du lien et l'invalide : un lien ne sert qu'une fois
+func (s *Serveur) consommerLien(jeton string) (int, bool) {
+	cle := empreinteJeton(jeton)
+	lien, ok := s.liens[cle]
+	delete(s.liens, cle)
+	if !ok || time.Now().After(lien.expire) {
+		return 0, false
+	}
+	return lien.membreID, true
+}
+
+// essaisEpuises indique si l'une des clés (email, poste) a atteint ESSAIS_MAX sur la fenêtre
+func (s *Serveur) essaisEpuises(cles ...string) bool {
+	for _, cle := range cles {
+		if compteur, ok := s.essais[cle]; ok && time.Since(compteur.debut) < FENETRE_ESSAIS && compteur.nombre >= ESSAIS_MAX {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Serveur) compterEssai(cles ...string) {
+	for _, cle := range cles {
+		compteur, ok := s.essais[cle]
+		if !ok || time.Since(compteur.debut) >= FENETRE_ESSAIS {
+			compteur = &compteurEssais{debut: time.Now()}
+			s.essais[cle] = compteur
+		}
+		compteur.nombre++
+	}
+}
+
+// nettoyer oublie les sessions, liens et compteurs périmés
+func (s *Serveur) nettoyer() {
+	maintenant := time.Now()
+	for jeton, sess := range s.sessions {
+		if maintenant.After(sess.expire) {
+			delete(s.sessions, jeton)
+		}
+	}
+	for cle, lien := range s.liens {
+		if maintenant.After(lien.expire) {
+			delete(s.liens, cle)
+		}
+	}
+	for cle, compteur := range s.essais {
+		if maintenant.Sub(compteur.debut) >= FENETRE_ESSAIS {
+			delete(s.essais, cle)
+		}
+	}
+}
+
+// clesEssais identifie une tentative par l'adresse email visée et par le poste d'origine
+func clesEssais(r *http.Request, email string) []string {
+	poste := r.RemoteAddr
+	if i := strings.LastIndex(poste, ":"); i > 0 {
+		poste = poste[:i]
+	}
+	return []string{"email:" + strings.ToLower(strings.TrimSpace(email)), "poste:" + poste}
+}
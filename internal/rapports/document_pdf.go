@@ -0,0 +1,212 @@
+// ==========================================
+// internal/rapports/document_pdf.go
+// MISE EN PAGE PDF (A4)
+// ==========================================
+
+package rapports
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/felver-dev/bookstore/internal/pdf"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+const (
+	margePDF      = 18 * pdf.MM
+	interlignePDF = 14.0
+)
+
+// redaction suit la position d'écriture et ouvre une nouvelle page quand la place manque
+type redaction struct {
+	document *pdf.Document
+	page     *pdf.Page
+	y        float64 // ligne de base courante, depuis le bas de la page
+	pied     string
+}
+
+func (red *redaction) nouvellePage() {
+	red.page = red.document.NouvellePage(pdf.A4_LARGEUR, pdf.A4_HAUTEUR)
+	red.y = pdf.A4_HAUTEUR - margePDF
+
+	couleur(red.page, COULEUR_AXE)
+	red.page.Texte(margePDF, margePDF/2, pdf.POLICE_NORMALE, 8,
+		fmt.Sprintf("%s — page %d", red.pied, red.document.NombrePages()))
+	couleur(red.page, COULEUR_TEXTE)
+}
+
+// reserver garantit qu'il reste hauteur points sur la page courante
+func (red *redaction) reserver(hauteur float64) {
+	if red.y-hauteur < margePDF {
+		red.nouvellePage()
+	}
+}
+
+func (red *redaction) largeurUtile() float64 {
+	return pdf.A4_LARGEUR - 2*margePDF
+}
+
+func (red *redaction) ligne(police string, taille float64, texte string) {
+	red.reserver(taille + 4)
+	red.y -= taille + 4
+	red.page.Texte(margePDF, red.y, police, taille, pdf.Couper(texte, police, taille, red.largeurUtile()))
+}
+
+// section ouvre une partie du rapport ; elle ne reste jamais seule en bas de page
+func (red *redaction) section(titre string) {
+	red.reserver(80)
+	red.y -= 14
+	red.ligne(pdf.POLICE_GRASSE, 13, titre)
+	red.y -= 4
+	couleur(red.page, COULEUR_AXE)
+	red.page.Trait(margePDF, red.y, pdf.A4_LARGEUR-margePDF, red.y, 0.5)
+	couleur(red.page, COULEUR_TEXTE)
+	red.y -= 4
+}
+
+// tableau écrit des lignes en colonnes ; largeurs en proportion de la largeur utile,
+// la dernière colonne (un nombre) est alignée à droite
+func (red *redaction) tableau(largeurs []float64, entetes []string, lignes [][]string) {
+	ecrireLigne := func(police string, cellules []string) {
+		red.reserver(interlignePDF)
+		red.y -= interlignePDF
+		x := margePDF
+		for i, cellule := range cellules {
+			largeur := largeurs[i] * red.largeurUtile()
+			texte := pdf.Couper(cellule, police, 9, largeur-6)
+			if i == len(cellules)-1 {
+				red.page.Texte(x+largeur-pdf.LargeurTexte(texte, police, 9), red.y, police, 9, texte)
+			} else {
+				red.page.Texte(x, red.y, police, 9, texte)
+			}
+			x += largeur
+		}
+	}
+
+	ecrireLigne(pdf.POLICE_GRASSE, entetes)
+	for _, ligne := range lignes {
+		ecrireLigne(pdf.POLICE_NORMALE, ligne)
+	}
+}
+
+// graphique reprend la disposition du SVG, mise à l'échelle de la largeur utile
+func (red *redaction) graphique(g Graphique) {
+	if len(g.Barres) == 0 {
+		red.ligne(pdf.POLICE_NORMALE, 10, "Aucune donnée sur la période")
+		return
+	}
+
+	echelle := red.largeurUtile() / largeurGraphique
+	hauteur := g.Hauteur() * echelle
+	red.reserver(hauteur + 6)
+	haut := red.y - 6
+	taille := 11 * echelle
+
+	// Le SVG compte y depuis le haut, le PDF depuis le bas
+	x := func(v float64) float64 { return margePDF + v*echelle }
+	y := func(v float64) float64 { return haut - v*echelle }
+
+	for _, barre := range g.disposer() {
+		couleur(red.page, COULEUR_BARRE)
+		red.page.Rectangle(x(barre.x), y(barre.y+barre.hauteur), barre.largeur*echelle, barre.hauteur*echelle)
+		couleur(red.page, COULEUR_TEXTE)
+
+		nombre := strconv.Itoa(barre.nombre)
+		if g.Colonnes {
+			centre := x(barre.x + barre.largeur/2)
+			red.page.TexteCentre(centre, y(barre.y-4), pdf.POLICE_NORMALE, taille, nombre)
+			red.page.TexteCentre(centre, y(margeGraphique+hauteurColonnes+16), pdf.POLICE_NORMALE, taille, barre.libelle)
+			continue
+		}
+
+		milieu := y(barre.y + barre.hauteur/2 + 4)
+		libelle := pdf.Couper(barre.libelle, pdf.POLICE_NORMALE, taille, (largeurLibelles-12)*echelle)
+		red.page.Texte(x(largeurLibelles-8)-pdf.LargeurTexte(libelle, pdf.POLICE_NORMALE, taille), milieu, pdf.POLICE_NORMALE, taille, libelle)
+		red.page.Texte(x(barre.x+barre.largeur+6), milieu, pdf.POLICE_NORMALE, taille, nombre)
+	}
+
+	couleur(red.page, COULEUR_AXE)
+	if g.Colonnes {
+		red.page.Trait(x(margeGraphique), y(margeGraphique+hauteurColonnes), x(largeurGraphique-margeGraphique), y(margeGraphique+hauteurColonnes), 0.5)
+	} else {
+		red.page.Trait(x(largeurLibelles), y(0), x(largeurLibelles), y(float64(len(g.Barres))*hauteurBarre), 0.5)
+	}
+	couleur(red.page, COULEUR_TEXTE)
+
+	red.y = haut - hauteur
+}
+
+// EcrirePDF produit le rapport au format A4, avec les mêmes graphiques que la page HTML
+func (r Rapport) EcrirePDF(w io.Writer) error {
+	titre := "Rapport d'activité — " + r.Periode.Libelle()
+	red := &redaction{document: pdf.NouveauDocument(titre), pied: titre}
+	red.nouvellePage()
+
+	red.ligne(pdf.POLICE_GRASSE, 18, titre)
+	red.ligne(pdf.POLICE_NORMALE, 9, fmt.Sprintf("Du %s au %s · généré le %s",
+		r.Periode.Debut.Format("02/01/2006"), r.Periode.Dernier().Format("02/01/2006"),
+		r.GenereLe.Format("02/01/2006 à 15:04")))
+
+	red.section("Activité")
+	activite := r.Emprunts
+	red.tableau([]float64{0.8, 0.2}, []string{"Indicateur", "Valeur"}, [][]string{
+		{"Emprunts", strconv.Itoa(activite.Total)},
+		{"Retours", strconv.Itoa(activite.Retours)},
+		{"Retours en retard", strconv.Itoa(activite.RetoursEnRetard)},
+		{"Membres emprunteurs", strconv.Itoa(activite.MembresEmprunteurs)},
+		{"Livres différents", strconv.Itoa(activite.LivresDifferents)},
+		{"Durée moyenne d'emprunt (jours)", fmt.Sprintf("%.1f", activite.DureeMoyenneJours)},
+	})
+
+	red.section("Tendance sur 12 mois")
+	red.graphique(r.GraphiqueTendance())
+
+	red.section("Emprunts par genre")
+	red.graphique(r.GraphiqueGenres())
+
+	red.section("Livres les plus empruntés")
+	red.classement(r.Emprunts.TopLivres)
+
+	red.section("Membres les plus actifs")
+	red.classement(r.Emprunts.TopMembres)
+
+	red.section(fmt.Sprintf("Retards (%d)", len(r.Emprunts.Retards)))
+	if len(r.Emprunts.Retards) == 0 {
+		red.ligne(pdf.POLICE_NORMALE, 10, "Aucun retard à la fin de la période.")
+	} else {
+		var lignes [][]string
+		for _, retard := range r.Emprunts.Retards {
+			jours := strconv.Itoa(retard.Jours)
+			if retard.RenduDepuis {
+				jours += " (rendu depuis)"
+			}
+			lignes = append(lignes, []string{retard.Titre, retard.Membre, retard.DateRetourPrevu.Format("02/01/2006"), jours})
+		}
+		red.tableau([]float64{0.4, 0.25, 0.15, 0.2}, []string{"Livre", "Membre", "À rendre le", "Jours de retard"}, lignes)
+	}
+
+	return red.document.Ecrire(w)
+}
+
+func (red *redaction) classement(valeurs []statistiques.Classement) {
+	if len(valeurs) == 0 {
+		red.ligne(pdf.POLICE_NORMALE, 10, "Aucun emprunt sur la période.")
+		return
+	}
+	var lignes [][]string
+	for i, valeur := range valeurs {
+		lignes = append(lignes, []string{strconv.Itoa(i + 1), valeur.Nom, strconv.Itoa(valeur.Emprunts)})
+	}
+	red.tableau([]float64{0.08, 0.72, 0.2}, []string{"#", "Nom", "Emprunts"}, lignes)
+}
+
+// couleur applique une couleur #rrggbb à la page
+func couleur(page *pdf.Page, hexa string) {
+	composante := func(i int) float64 {
+		valeur, _ := strconv.ParseUint(hexa[i:i+2], 16, 8)
+		return float64(valeur) / 255
+	}
+	page.Couleur(composante(1), composante(3), composante(5))
+}
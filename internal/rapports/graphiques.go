@@ -0,0 +1,148 @@
+// ==========================================
+// internal/rapports/graphiques.go
+// GRAPHIQUES EN BARRES (SVG)
+// ==========================================
+
+package rapports
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Couleurs communes au HTML et au PDF
+const (
+	COULEUR_BARRE = "#2f6f9f"
+	COULEUR_AXE   = "#999999"
+	COULEUR_TEXTE = "#333333"
+)
+
+// Graphique est un diagramme en barres horizontales, ou en colonnes pour les séries
+// temporelles. Le même graphique est dessiné en SVG (HTML) et en traits PDF.
+type Graphique struct {
+	Titre    string
+	Barres   []Valeur
+	Colonnes bool
+}
+
+// Dimensions des graphiques, en unités SVG (pixels) ; le PDF les met à l'échelle
+const (
+	largeurGraphique = 640.0
+	largeurLibelles  = 200.0 // barres horizontales : colonne des libellés
+	hauteurBarre     = 22.0
+	hauteurColonnes  = 220.0 // colonnes : zone des barres, hors libellés
+	margeGraphique   = 24.0
+)
+
+// maximum retourne la plus grande valeur, au moins 1 pour éviter une division par zéro
+func (g Graphique) maximum() int {
+	maximum := 1
+	for _, barre := range g.Barres {
+		maximum = max(maximum, barre.Nombre)
+	}
+	return maximum
+}
+
+// Hauteur retourne la hauteur du graphique en unités SVG
+func (g Graphique) Hauteur() float64 {
+	if g.Colonnes {
+		return hauteurColonnes + 2*margeGraphique
+	}
+	return float64(len(g.Barres))*hauteurBarre + margeGraphique
+}
+
+// rect est une barre du graphique, dans un repère dont l'origine est en haut à gauche
+type rect struct {
+	x, y, largeur, hauteur float64
+	libelle                string
+	nombre                 int
+}
+
+// disposer calcule la position de chaque barre, de ses libellés et de sa valeur
+func (g Graphique) disposer() []rect {
+	maximum := float64(g.maximum())
+	barres := make([]rect, 0, len(g.Barres))
+
+	if g.Colonnes {
+		pas := (largeurGraphique - 2*margeGraphique) / float64(max(len(g.Barres), 1))
+		for i, barre := range g.Barres {
+			hauteur := float64(barre.Nombre) / maximum * hauteurColonnes
+			barres = append(barres, rect{
+				x:       margeGraphique + float64(i)*pas + pas*0.15,
+				y:       margeGraphique + hauteurColonnes - hauteur,
+				largeur: pas * 0.7,
+				hauteur: hauteur,
+				libelle: barre.Libelle,
+				nombre:  barre.Nombre,
+			})
+		}
+		return barres
+	}
+
+	largeurUtile := largeurGraphique - largeurLibelles - 2*margeGraphique
+	for i, barre := range g.Barres {
+		barres = append(barres, rect{
+			x:       largeurLibelles,
+			y:       float64(i)*hauteurBarre + 3,
+			largeur: float64(barre.Nombre) / maximum * largeurUtile,
+			hauteur: hauteurBarre - 6,
+			libelle: barre.Libelle,
+			nombre:  barre.Nombre,
+		})
+	}
+	return barres
+}
+
+// SVG retourne le graphique sous forme d'élément <svg> autonome, à insérer tel quel dans une page
+func (g Graphique) SVG() string {
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" width="%.0f" height="%.0f" role="img" aria-label="%s" font-family="sans-serif" font-size="11">`,
+		largeurGraphique, g.Hauteur(), largeurGraphique, g.Hauteur(), html.EscapeString(g.Titre))
+
+	if len(g.Barres) == 0 {
+		fmt.Fprintf(&svg, `<text x="0" y="16" fill="%s">Aucune donnée sur la période</text></svg>`, COULEUR_TEXTE)
+		return svg.String()
+	}
+
+	for _, barre := range g.disposer() {
+		libelle := html.EscapeString(barre.libelle)
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s : %d</title></rect>`,
+			barre.x, barre.y, barre.largeur, barre.hauteur, COULEUR_BARRE, libelle, barre.nombre)
+
+		if g.Colonnes {
+			centre := barre.x + barre.largeur/2
+			fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%d</text>`,
+				centre, barre.y-4, COULEUR_TEXTE, barre.nombre)
+			fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%s</text>`,
+				centre, margeGraphique+hauteurColonnes+16, COULEUR_TEXTE, libelle)
+			continue
+		}
+
+		milieu := barre.y + barre.hauteur/2 + 4
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="end" fill="%s">%s</text>`,
+			largeurLibelles-8, milieu, COULEUR_TEXTE, html.EscapeString(couperLibelle(barre.libelle, 30)))
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" fill="%s">%d</text>`,
+			barre.x+barre.largeur+6, milieu, COULEUR_TEXTE, barre.nombre)
+	}
+
+	if g.Colonnes {
+		fmt.Fprintf(&svg, `<line x1="%.0f" y1="%.0f" x2="%.0f" y2="%.0f" stroke="%s"/>`,
+			margeGraphique, margeGraphique+hauteurColonnes, largeurGraphique-margeGraphique, margeGraphique+hauteurColonnes, COULEUR_AXE)
+	} else {
+		fmt.Fprintf(&svg, `<line x1="%.0f" y1="0" x2="%.0f" y2="%.0f" stroke="%s"/>`,
+			largeurLibelles, largeurLibelles, float64(len(g.Barres))*hauteurBarre, COULEUR_AXE)
+	}
+
+	svg.WriteString(`</svg>`)
+	return svg.String()
+}
+
+// couperLibelle raccourcit un libellé trop long pour la colonne des libellés
+func couperLibelle(libelle string, longueurMax int) string {
+	runes := []rune(libelle)
+	if len(runes) <= longueurMax {
+		return libelle
+	}
+	return string(runes[:longueurMax-1]) + "…"
+}
@@ -0,0 +1,31 @@
+// ==========================================
+// internal/rapports/html.go
+// MISE EN PAGE HTML
+// ==========================================
+
+package rapports
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"time"
+)
+
+// Le rapport est un fichier HTML autonome : styles et graphiques SVG sont
+// inclus dans la page, qui s'ouvre sans connexion et s'imprime telle quelle
+//
+//go:embed gabarits/rapport.html
+var fichiersGabarits embed.FS
+
+var gabaritHTML = template.Must(template.New("rapport.html").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("02/01/2006") },
+	"rang": func(i int) int { return i + 1 },
+	// Le SVG est construit par Graphique.SVG, qui échappe lui-même les libellés
+	"svg": func(g Graphique) template.HTML { return template.HTML(g.SVG()) },
+}).ParseFS(fichiersGabarits, "gabarits/rapport.html"))
+
+// EcrireHTML produit la page HTML du rapport
+func (r Rapport) EcrireHTML(w io.Writer) error {
+	return gabaritHTML.Execute(w, r)
+}
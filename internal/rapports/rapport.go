@@ -0,0 +1,103 @@
+// ==========================================
+// internal/rapports/rapport.go
+// RAPPORTS D'ACTIVITÉ (HTML ET PDF)
+// ==========================================
+
+package rapports
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// Rapport rassemble les chiffres d'une période ; il est calculé une fois
+// puis mis en page en HTML et en PDF
+type Rapport struct {
+	Periode  statistiques.Periode
+	GenereLe time.Time
+
+	Emprunts statistiques.Emprunts
+	Tendance []statistiques.Point // 12 mois qui finissent avec la période
+}
+
+// Valeur est une barre de graphique
+type Valeur struct {
+	Libelle string
+	Nombre  int
+}
+
+// Collecter calcule le rapport de la période à partir des emprunts enregistrés
+func Collecter(periode statistiques.Periode, ge *services.GestionnaireEmprunts) Rapport {
+	return Rapport{
+		Periode:  periode,
+		GenereLe: time.Now(),
+		Emprunts: ge.ObtenirStatistiques(periode, 0),
+		Tendance: ge.EmpruntsParMois(statistiques.DouzeMois(periode.Dernier())),
+	}
+}
+
+// ========================================
+// GRAPHIQUES DU RAPPORT
+// ========================================
+
+// GraphiqueGenres répartit les emprunts de la période par genre
+func (r Rapport) GraphiqueGenres() Graphique {
+	graphique := Graphique{Titre: "Emprunts par genre"}
+	for _, genre := range r.Emprunts.ParGenre {
+		graphique.Barres = append(graphique.Barres, Valeur{Libelle: genre.Libelle, Nombre: genre.Nombre})
+	}
+	return graphique
+}
+
+// GraphiqueTendance montre les emprunts des 12 derniers mois, en colonnes
+func (r Rapport) GraphiqueTendance() Graphique {
+	graphique := Graphique{Titre: "Emprunts sur 12 mois", Colonnes: true}
+	for _, mois := range r.Tendance {
+		graphique.Barres = append(graphique.Barres, Valeur{Libelle: mois.Debut.Format("01/06"), Nombre: mois.Valeur})
+	}
+	return graphique
+}
+
+// ========================================
+// ENREGISTREMENT
+// ========================================
+
+// Enregistrer écrit rapport-<période>.html et rapport-<période>.pdf dans le dossier
+// et retourne les chemins des fichiers créés
+func (r Rapport) Enregistrer(dossier string) ([]string, error) {
+	if err := os.MkdirAll(dossier, 0755); err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier %s : %v", dossier, err)
+	}
+
+	base := filepath.Join(dossier, "rapport-"+r.Periode.Nom())
+	ecrivains := []struct {
+		extension string
+		ecrire    func(*os.File) error
+	}{
+		{".html", func(f *os.File) error { return r.EcrireHTML(f) }},
+		{".pdf", func(f *os.File) error { return r.EcrirePDF(f) }},
+	}
+
+	var chemins []string
+	for _, ecrivain := range ecrivains {
+		chemin := base + ecrivain.extension
+		fichier, err := os.Create(chemin)
+		if err != nil {
+			return chemins, fmt.Errorf("impossible de créer %s : %v", chemin, err)
+		}
+		err = ecrivain.ecrire(fichier)
+		if errFermeture := fichier.Close(); err == nil {
+			err = errFermeture
+		}
+		if err != nil {
+			return chemins, fmt.Errorf("erreur lors de l'écriture de %s : %v", chemin, err)
+		}
+		chemins = append(chemins, chemin)
+	}
+	return chemins, nil
+}
@@ -0,0 +1,132 @@
+// ==========================================
+// internal/recommandations/evaluation.go
+// ÉVALUATION HORS LIGNE (PRÉCISION@K)
+// ==========================================
+
+package recommandations
+
+import (
+	"sort"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// ÉVALUATION
+// Pour chaque membre qui a emprunté au moins deux titres, ses derniers titres
+// (PART_RETENUE de son historique, au moins un) sont mis de côté. Le moteur est
+// construit sans eux, puis on compte combien des k suggestions faites au membre
+// figurent parmi les titres mis de côté.
+// ========================================
+
+// PART_RETENUE est la part la plus récente de l'historique de chaque membre mise de côté
+const PART_RETENUE = 0.2
+
+// Evaluation mesure la qualité des suggestions sur l'historique mis de côté
+type Evaluation struct {
+	K       int `json:"k"`
+	Membres int `json:"membres"` // membres évalués
+	Retenus int `json:"retenus"` // titres mis de côté, tous membres
+
+	Precision float64 `json:"precision"` // précision@k moyenne, en pourcentage
+	Rappel    float64 `json:"rappel"`    // part des titres mis de côté retrouvés, en pourcentage
+
+	// Même mesure pour les seuls titres les plus empruntés, comme point de comparaison
+	PrecisionPopularite float64 `json:"precision_popularite"`
+}
+
+// Evaluer mesure la précision@k du moteur sur l'historique des emprunts
+func Evaluer(gl *services.GestionnaireLivres, ge *services.GestionnaireEmprunts, k int) Evaluation {
+	livres := append(gl.ListerLivres(), gl.ListerLivresRetires()...)
+	return evaluer(livres, ge.ListerEmprunts(), k)
+}
+
+func evaluer(livres []models.Livre, emprunts []models.Emprunt, k int) Evaluation {
+	evaluation := Evaluation{K: k}
+	if k < 1 {
+		return evaluation
+	}
+
+	cleDuLivre := make(map[int]string, len(livres))
+	for _, livre := range livres {
+		cleDuLivre[livre.ID] = cle(livre)
+	}
+
+	// Titres de chaque membre dans l'ordre de leur premier emprunt
+	chronologie := make([]models.Emprunt, len(emprunts))
+	copy(chronologie, emprunts)
+	sort.SliceStable(chronologie, func(i, j int) bool {
+		return chronologie[i].DateEmprunt.Before(chronologie[j].DateEmprunt)
+	})
+	historiques := make(map[int][]string)
+	vus := make(map[int]map[string]bool)
+	for _, emprunt := range chronologie {
+		c, ok := cleDuLivre[emprunt.LivreID]
+		if !ok {
+			continue
+		}
+		if vus[emprunt.MembreID] == nil {
+			vus[emprunt.MembreID] = make(map[string]bool)
+		}
+		if !vus[emprunt.MembreID][c] {
+			vus[emprunt.MembreID][c] = true
+			historiques[emprunt.MembreID] = append(historiques[emprunt.MembreID], c)
+		}
+	}
+
+	// Mettre de côté la fin de chaque historique
+	retenus := make(map[int]map[string]bool)
+	for membreID, titres := range historiques {
+		if len(titres) < 2 {
+			continue
+		}
+		nombre := max(1, int(float64(len(titres))*PART_RETENUE))
+		retenus[membreID] = make(map[string]bool)
+		for _, c := range titres[len(titres)-nombre:] {
+			retenus[membreID][c] = true
+		}
+	}
+	if len(retenus) == 0 {
+		return evaluation
+	}
+
+	var apprentissage []models.Emprunt
+	for _, emprunt := range emprunts {
+		if !retenus[emprunt.MembreID][cleDuLivre[emprunt.LivreID]] {
+			apprentissage = append(apprentissage, emprunt)
+		}
+	}
+
+	// Tous les titres connus peuvent être proposés : un titre retiré depuis
+	// a pu être emprunté pendant la période mise de côté
+	moteur := nouveauMoteur(livres, apprentissage, func(*titre) bool { return true })
+
+	var precision, rappel, precisionPopularite float64
+	for membreID, attendus := range retenus {
+		trouves := compterTrouves(moteur.suggerer(membreID, k), attendus)
+		precision += float64(trouves) / float64(k)
+		rappel += float64(trouves) / float64(len(attendus))
+
+		populaires := moteur.parPopularite(moteur.lectures[membreID])
+		precisionPopularite += float64(compterTrouves(populaires[:min(k, len(populaires))], attendus)) / float64(k)
+
+		evaluation.Retenus += len(attendus)
+	}
+
+	evaluation.Membres = len(retenus)
+	evaluation.Precision = precision / float64(evaluation.Membres) * 100
+	evaluation.Rappel = rappel / float64(evaluation.Membres) * 100
+	evaluation.PrecisionPopularite = precisionPopularite / float64(evaluation.Membres) * 100
+	return evaluation
+}
+
+func compterTrouves(suggestions []suggestion, attendus map[string]bool) int {
+	trouves := 0
+	for _, s := range suggestions {
+		if attendus[s.titre.cle] {
+			trouves++
+		}
+	}
+	return trouves
+}
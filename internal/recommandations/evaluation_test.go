@@ -0,0 +1,100 @@
+package recommandations
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// fondsEvaluation : quatre titres, dont Alpha en deux exemplaires
+func fondsEvaluation() []models.Livre {
+	return []models.Livre{
+		{ID: 1, Titre: "Alpha", Auteur: "Auteur X", Genre: "Roman", ISBN: "isbn-a"},
+		{ID: 2, Titre: "Bravo", Auteur: "Auteur Y", Genre: "Roman", ISBN: "isbn-b"},
+		{ID: 3, Titre: "Charlie", Auteur: "Auteur Z", Genre: "Policier", ISBN: "isbn-c"},
+		{ID: 4, Titre: "Delta", Auteur: "Auteur W", Genre: "Science-fiction", ISBN: "isbn-d"},
+		{ID: 5, Titre: "Alpha", Auteur: "Auteur X", Genre: "Roman", ISBN: "isbn-a"},
+	}
+}
+
+func TestEvaluerPrecision(t *testing.T) {
+	jour := func(n int) time.Time { return time.Date(2026, 3, n, 10, 0, 0, 0, time.UTC) }
+	emprunts := []models.Emprunt{
+		// Membre 1 : Alpha, Bravo puis Charlie, mis de côté (max(1, 3 × 20 %) = 1 titre)
+		{ID: 1, LivreID: 1, MembreID: 1, DateEmprunt: jour(1)},
+		{ID: 2, LivreID: 2, MembreID: 1, DateEmprunt: jour(2)},
+		{ID: 3, LivreID: 3, MembreID: 1, DateEmprunt: jour(3)},
+		// Membre 2 : Alpha (l'autre exemplaire, puis le même titre de nouveau), puis Charlie, mis de côté
+		{ID: 4, LivreID: 5, MembreID: 2, DateEmprunt: jour(1)},
+		{ID: 5, LivreID: 1, MembreID: 2, DateEmprunt: jour(2)},
+		{ID: 6, LivreID: 3, MembreID: 2, DateEmprunt: jour(4)},
+		// Membre 3 : un seul titre, il n'est pas évalué mais compte à l'apprentissage
+		{ID: 7, LivreID: 2, MembreID: 3, DateEmprunt: jour(2)},
+		// Membre 4 : seulement un livre inconnu du catalogue, donc aucun historique
+		{ID: 8, LivreID: 99, MembreID: 4, DateEmprunt: jour(1)},
+		{ID: 9, LivreID: 99, MembreID: 4, DateEmprunt: jour(2)},
+	}
+
+	// Apprentissage : Alpha lu par les membres 1 et 2, Bravo par les membres 1 et 3,
+	// Charlie et Delta par personne.
+	// Membre 1 (a lu Alpha et Bravo) : ni voisin ni auteur ou genre commun à
+	// proposer, la popularité départage Charlie et Delta (0 lecteur) par titre.
+	// Membre 2 (a lu Alpha) : Bravo en voisin, puis Charlie et Delta.
+	cas := []struct {
+		k                   int
+		precision, rappel   float64
+		precisionPopularite float64
+	}{
+		// Membre 1 : [Charlie] → 1/1 ; membre 2 : [Bravo] → 0/1
+		{1, 50, 50, 50},
+		// Membre 1 : [Charlie, Delta] → 1/2 ; membre 2 : [Bravo, Charlie] → 1/2
+		{2, 50, 100, 50},
+		// k au-delà des candidats : la précision reste rapportée à k.
+		// Membre 1 : [Charlie, Delta] → 1/5 ; membre 2 : [Bravo, Charlie, Delta] → 1/5
+		{5, 20, 100, 20},
+	}
+	for _, c := range cas {
+		evaluation := evaluer(fondsEvaluation(), emprunts, c.k)
+		if evaluation.K != c.k || evaluation.Membres != 2 || evaluation.Retenus != 2 {
+			t.Errorf("k = %d : %d membre(s) et %d titre(s) mis de côté, attendu 2 et 2", c.k, evaluation.Membres, evaluation.Retenus)
+		}
+		for _, mesure := range []struct {
+			nom             string
+			obtenu, attendu float64
+		}{
+			{"précision", evaluation.Precision, c.precision},
+			{"rappel", evaluation.Rappel, c.rappel},
+			{"précision de la popularité", evaluation.PrecisionPopularite, c.precisionPopularite},
+		} {
+			if math.Abs(mesure.obtenu-mesure.attendu) > 1e-9 {
+				t.Errorf("k = %d : %s %.2f %%, attendu %.2f %%", c.k, mesure.nom, mesure.obtenu, mesure.attendu)
+			}
+		}
+	}
+}
+
+func TestEvaluerSansMembreEvaluable(t *testing.T) {
+	emprunts := []models.Emprunt{
+		{ID: 1, LivreID: 1, MembreID: 1, DateEmprunt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: 2, LivreID: 5, MembreID: 1, DateEmprunt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: 3, LivreID: 99, MembreID: 2, DateEmprunt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	cas := []struct {
+		nom      string
+		emprunts []models.Emprunt
+		k        int
+	}{
+		{"aucun emprunt", nil, 5},
+		// Deux exemplaires du même titre ne font qu'un titre ; le livre 99 est inconnu
+		{"un seul titre par membre", emprunts, 5},
+		{"k nul", emprunts, 0},
+	}
+	for _, c := range cas {
+		evaluation := evaluer(fondsEvaluation(), c.emprunts, c.k)
+		if evaluation != (Evaluation{K: c.k}) {
+			t.Errorf("%s : %+v, attendu une évaluation vide", c.nom, evaluation)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// ==========================================
+// internal/recommandations/recommandations.go
+// SUGGESTIONS DE LECTURE
+// ==========================================
+
+package recommandations
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/felver-dev/bookstore/internal/analyses"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// MOTEUR DE RECOMMANDATION
+// Filtrage collaboratif article-article sur l'historique des emprunts : deux
+// titres sont proches quand les mêmes membres les ont empruntés (similarité
+// cosinus). Pour un membre sans voisins utiles (nouvel inscrit, lectures rares),
+// le moteur se rabat sur ses auteurs et genres, puis sur les titres les plus
+// empruntés. Les titres déjà empruntés par le membre ne sont jamais proposés.
+// ========================================
+
+// K_PAR_DEFAUT est le nombre de suggestions affichées
+const K_PAR_DEFAUT = 5
+
+const (
+	METHODE_COLLABORATIVE = "collaborative" // lu par les lecteurs des mêmes livres
+	METHODE_CONTENU       = "contenu"       // même auteur ou même genre
+	METHODE_POPULARITE    = "popularite"    // parmi les plus empruntés
+)
+
+// Recommandation est un titre suggéré à un membre, avec sa disponibilité
+type Recommandation struct {
+	LivreID int    `json:"livre_id"` // l'exemplaire à proposer (de préférence un exemplaire en rayon)
+	Titre   string `json:"titre"`
+	Auteur  string `json:"auteur"`
+	Genre   string `json:"genre"`
+
+	Score   float64 `json:"score"`
+	Methode string  `json:"methode"`
+	Raison  string  `json:"raison"`
+
+	Disponible bool   `json:"disponible"`
+	Etat       string `json:"etat"` // Disponible, Emprunté, Mis de côté
+	Attente    int    `json:"attente"`
+}
+
+// titre regroupe les éditions et exemplaires d'une même oeuvre (ou d'un même ISBN)
+type titre struct {
+	cle         string
+	livre       models.Livre   // première édition rencontrée, pour l'affichage
+	exemplaires []models.Livre // exemplaires au catalogue, vide si le titre n'y est plus
+}
+
+// Moteur est construit une fois à partir de l'historique ; il n'est pas mis à jour
+// par les emprunts qui suivent (il suffit d'en construire un nouveau)
+type Moteur struct {
+	titres     map[string]*titre
+	cleDuLivre map[int]string
+
+	lectures      map[int]map[string]bool   // membre → titres empruntés
+	lecteurs      map[string]int            // titre → nombre de membres l'ayant emprunté
+	cooccurrences map[string]map[string]int // titre → titre → membres ayant emprunté les deux
+	candidats     func(*titre) bool         // titres qui peuvent être proposés
+
+	gestionnaireReservations *services.GestionnaireReservations
+}
+
+// Construire prépare le moteur sur tout l'historique des emprunts ; seuls les
+// titres encore au catalogue sont proposés
+func Construire(gl *services.GestionnaireLivres, ge *services.GestionnaireEmprunts, gr *services.GestionnaireReservations) *Moteur {
+	livres := append(gl.ListerLivres(), gl.ListerLivresRetires()...)
+	moteur := nouveauMoteur(livres, ge.ListerEmprunts(), func(t *titre) bool { return len(t.exemplaires) > 0 })
+	moteur.gestionnaireReservations = gr
+	return moteur
+}
+
+// cle identifie un titre : l'oeuvre si le livre y est rattaché (toutes éditions
+// confondues), sinon l'ISBN
+func cle(livre models.Livre) string {
+	if livre.OeuvreID != 0 {
+		return fmt.Sprintf("oeuvre:%d", livre.OeuvreID)
+	}
+	return analyses.CleTitre(livre)
+}
+
+func nouveauMoteur(livres []models.Livre, emprunts []models.Emprunt, candidats func(*titre) bool) *Moteur {
+	m := &Moteur{
+		titres:        make(map[string]*titre),
+		cleDuLivre:    make(map[int]string, len(livres)),
+		lectures:      make(map[int]map[string]bool),
+		lecteurs:      make(map[string]int),
+		cooccurrences: make(map[string]map[string]int),
+		candidats:     candidats,
+	}
+
+	for _, livre := range livres {
+		c := cle(livre)
+		m.cleDuLivre[livre.ID] = c
+		if m.titres[c] == nil {
+			m.titres[c] = &titre{cle: c, livre: livre}
+		}
+		if !livre.EstRetire() {
+			m.titres[c].exemplaires = append(m.titres[c].exemplaires, livre)
+		}
+	}
+
+	for _, emprunt := range emprunts {
+		c, ok := m.cleDuLivre[emprunt.LivreID]
+		if !ok {
+			continue
+		}
+		if m.lectures[emprunt.MembreID] == nil {
+			m.lectures[emprunt.MembreID] = make(map[string]bool)
+		}
+		m.lectures[emprunt.MembreID][c] = true
+	}
+
+	// Un membre compte une fois par titre, quel que soit le nombre d'emprunts
+	for _, lus := range m.lectures {
+		for a := range lus {
+			m.lecteurs[a]++
+			if m.cooccurrences[a] == nil {
+				m.cooccurrences[a] = make(map[string]int)
+			}
+			for b := range lus {
+				if a != b {
+					m.cooccurrences[a][b]++
+				}
+			}
+		}
+	}
+	return m
+}
+
+// Recommander propose au plus k titres au membre, les meilleurs d'abord
+func (m *Moteur) Recommander(membreID, k int) []Recommandation {
+	var recommandations []Recommandation
+	for _, s := range m.suggerer(membreID, k) {
+		recommandations = append(recommandations, m.completer(s))
+	}
+	return recommandations
+}
+
+// suggestion est un titre retenu, avant la recherche d'un exemplaire à proposer
+type suggestion struct {
+	titre   *titre
+	score   float64
+	methode string
+	raison  string
+}
+
+func (m *Moteur) suggerer(membreID, k int) []suggestion {
+	lus := m.lectures[membreID]
+	dejaPropose := make(map[string]bool)
+	var suggestions []suggestion
+
+	ajouter := func(candidates []suggestion) {
+		for _, s := range candidates {
+			if len(suggestions) >= k {
+				return
+			}
+			if !dejaPropose[s.titre.cle] {
+				dejaPropose[s.titre.cle] = true
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+
+	ajouter(m.collaboratif(lus))
+	ajouter(m.parContenu(lus))
+	ajouter(m.parPopularite(lus))
+	return suggestions
+}
+
+// proposable indique si le titre peut être suggéré à un membre qui a lu lus
+func (m *Moteur) proposable(t *titre, lus map[string]bool) bool {
+	return !lus[t.cle] && m.candidats(t)
+}
+
+// classer trie par score décroissant, puis par titre pour un ordre stable
+func classer(suggestions []suggestion) []suggestion {
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].score != suggestions[j].score {
+			return suggestions[i].score > suggestions[j].score
+		}
+		return suggestions[i].titre.livre.Titre < suggestions[j].titre.livre.Titre
+	})
+	return suggestions
+}
+
+// collaboratif additionne, pour chaque titre non lu, sa similarité cosinus avec
+// chacun des titres lus ; la raison cite le titre lu qui y contribue le plus
+func (m *Moteur) collaboratif(lus map[string]bool) []suggestion {
+	scores := make(map[string]float64)
+	meilleurVoisin := make(map[string]string)
+	meilleureSimilarite := make(map[string]float64)
+
+	for lu := range lus {
+		for voisin, communs := range m.cooccurrences[lu] {
+			t := m.titres[voisin]
+			if !m.proposable(t, lus) {
+				continue
+			}
+			similarite := float64(communs) / math.Sqrt(float64(m.lecteurs[lu]*m.lecteurs[voisin]))
+			scores[voisin] += similarite
+			if similarite > meilleureSimilarite[voisin] || (similarite == meilleureSimilarite[voisin] && m.titres[lu].livre.Titre < m.titres[meilleurVoisin[voisin]].livre.Titre) {
+				meilleureSimilarite[voisin] = similarite
+				meilleurVoisin[voisin] = lu
+			}
+		}
+	}
+
+	suggestions := make([]suggestion, 0, len(scores))
+	for c, score := range scores {
+		suggestions = append(suggestions, suggestion{
+			titre:   m.titres[c],
+			score:   score,
+			methode: METHODE_COLLABORATIVE,
+			raison:  fmt.Sprintf("emprunté par les lecteurs de « %s »", m.titres[meilleurVoisin[c]].livre.Titre),
+		})
+	}
+	return classer(suggestions)
+}
+
+// parContenu favorise les auteurs (deux points par livre lu) puis les genres
+// (un point) que le membre a déjà empruntés
+func (m *Moteur) parContenu(lus map[string]bool) []suggestion {
+	auteurs := make(map[string]int)
+	genres := make(map[string]int)
+	exemple := make(map[string]string) // auteur → un titre lu de cet auteur
+	for c := range lus {
+		livre := m.titres[c].livre
+		if livre.Auteur != "" {
+			auteurs[livre.Auteur]++
+			if exemple[livre.Auteur] == "" || livre.Titre < exemple[livre.Auteur] {
+				exemple[livre.Auteur] = livre.Titre
+			}
+		}
+		if livre.Genre != "" {
+			genres[livre.Genre]++
+		}
+	}
+
+	var suggestions []suggestion
+	for _, t := range m.titres {
+		if !m.proposable(t, lus) {
+			continue
+		}
+		livre := t.livre
+		score := float64(2*auteurs[livre.Auteur] + genres[livre.Genre])
+		if score == 0 {
+			continue
+		}
+		raison := fmt.Sprintf("dans le genre %s, que vous lisez", livre.Genre)
+		if auteurs[livre.Auteur] > 0 {
+			raison = fmt.Sprintf("du même auteur que « %s »", exemple[livre.Auteur])
+		}
+		// À score égal, le plus emprunté passe devant
+		score += float64(m.lecteurs[t.cle]) / 1000
+		suggestions = append(suggestions, suggestion{titre: t, score: score, methode: METHODE_CONTENU, raison: raison})
+	}
+	return classer(suggestions)
+}
+
+// parPopularite propose les titres empruntés par le plus de membres
+func (m *Moteur) parPopularite(lus map[string]bool) []suggestion {
+	var suggestions []suggestion
+	for _, t := range m.titres {
+		if !m.proposable(t, lus) {
+			continue
+		}
+		raison := fmt.Sprintf("parmi les plus empruntés (%d lecteur(s))", m.lecteurs[t.cle])
+		if m.lecteurs[t.cle] == 0 {
+			raison = "pas encore emprunté, à découvrir"
+		}
+		suggestions = append(suggestions, suggestion{
+			titre:   t,
+			score:   float64(m.lecteurs[t.cle]),
+			methode: METHODE_POPULARITE,
+			raison:  raison,
+		})
+	}
+	return classer(suggestions)
+}
+
+// completer choisit l'exemplaire à proposer : un exemplaire en rayon et libre
+// s'il y en a un, sinon celui dont la file d'attente est la plus courte
+func (m *Moteur) completer(s suggestion) Recommandation {
+	r := Recommandation{
+		Titre:   s.titre.livre.Titre,
+		Auteur:  s.titre.livre.Auteur,
+		Genre:   s.titre.livre.Genre,
+		Score:   s.score,
+		Methode: s.methode,
+		Raison:  s.raison,
+		Etat:    "Emprunté",
+		Attente: -1,
+	}
+
+	for _, exemplaire := range s.titre.exemplaires {
+		attente := len(m.gestionnaireReservations.FileAttente(exemplaire.ID))
+		misDeCote := m.gestionnaireReservations.ReservationPrete(exemplaire.ID) != nil
+
+		if exemplaire.EstDisponible() && !misDeCote {
+			r.LivreID, r.Disponible, r.Etat, r.Attente = exemplaire.ID, true, "Disponible", attente
+			break
+		}
+		if r.Attente < 0 || attente < r.Attente {
+			r.LivreID, r.Attente = exemplaire.ID, attente
+			if misDeCote {
+				r.Etat = "Mis de côté"
+			} else {
+				r.Etat = "Emprunté"
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+const euro = models.Montant(100)
+
+// ligneDeTest commande quantite exemplaires d'un ISBN au prix unitaire donné
+type ligneDeTest struct {
+	isbn, titre, genre string
+	quantite           int
+	prix               models.Montant
+}
+
+// commander crée une commande chez le fournisseur 1 (créé au besoin)
+func commander(t *testing.T, l librairie, lignes ...ligneDeTest) *models.Commande {
+	t.Helper()
+
+	if l.acquisitions.TrouverFournisseurParID(1) == nil {
+		if _, err := l.acquisitions.AjouterFournisseur("Librairie du Centre", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commande, err := l.acquisitions.CreerCommande(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ligne := range lignes {
+		if _, err := l.acquisitions.AjouterLigne(commande.ID, ligne.isbn, ligne.titre, "Auteur", ligne.genre, "01/01/1950", ligne.quantite, ligne.prix); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l.acquisitions.TrouverCommandeParID(commande.ID)
+}
+
+// bilan retourne l'engagé, le dépensé et le reste du budget de l'année courante
+func bilan(t *testing.T, l librairie, fonds string) (engage, depense, reste models.Montant) {
+	t.Helper()
+	for _, b := range l.acquisitions.BilanBudgets(time.Now().Year()) {
+		if b.Budget.Fonds == fonds {
+			return b.Engage, b.Depense, b.Reste
+		}
+	}
+	t.Fatalf("pas de bilan pour le fonds %s", fonds)
+	return 0, 0, 0
+}
+
+func verifierBilan(t *testing.T, l librairie, fonds string, engage, depense, reste models.Montant) {
+	t.Helper()
+	e, d, r := bilan(t, l, fonds)
+	if e != engage || d != depense || r != reste {
+		t.Errorf("fonds %s : engagé %s, dépensé %s, reste %s ; attendu %s, %s, %s", fonds, e, d, r, engage, depense, reste)
+	}
+}
+
+func TestBudgetDuGenreParent(t *testing.T) {
+	l, _ := taxonomie(t) // Fiction > Policier > Noir
+	annee := time.Now().Year()
+
+	budgets := []struct{ fonds, genre string }{
+		{"Fiction", "Fiction"},
+		{"Polars", "Polar"}, // alias de Policier : le budget garde le nom canonique
+		{"Général", ""},
+	}
+	ids := make(map[string]int)
+	if err := l.genres.AjouterAlias(l.genres.Resoudre("Policier").ID, "Polar"); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range budgets {
+		budget, err := l.acquisitions.DefinirBudget(b.fonds, b.genre, annee, 500*euro)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[b.fonds] = budget.ID
+	}
+	// Le budget de Noir de l'an dernier ne paie pas les commandes de cette année
+	if _, err := l.acquisitions.DefinirBudget("Noir", "Noir", annee-1, 500*euro); err != nil {
+		t.Fatal(err)
+	}
+
+	cas := []struct {
+		genre string
+		annee int
+		fonds string // vide : hors budget
+	}{
+		{"Noir", annee, "Polars"}, // le plus proche parent, pas Fiction
+		{"Policier", annee, "Polars"},
+		{"polar", annee, "Polars"},
+		{"Fiction", annee, "Fiction"},
+		{"Roman", annee, "Général"},   // genre sans fonds ni parent
+		{"Inconnu", annee, "Général"}, // genre hors taxonomie
+		{"Noir", annee - 1, "Noir"},
+		{"Roman", annee - 1, ""}, // pas de fonds sans genre l'an dernier
+	}
+	for _, c := range cas {
+		id := l.acquisitions.budgetPour(c.genre, c.annee)
+		fonds := ""
+		if budget := l.acquisitions.TrouverBudgetParID(id); budget != nil {
+			fonds = budget.Fonds
+		}
+		if fonds != c.fonds {
+			t.Errorf("genre %s en %d : fonds %q, attendu %q", c.genre, c.annee, fonds, c.fonds)
+		}
+	}
+
+	// Les lignes de commande sont imputées de la même façon, y compris pour un
+	// livre déjà au catalogue, imputé au fonds de son genre
+	if err := l.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	commande := commander(t, l,
+		ligneDeTest{"9780306406157", "Le Dahlia noir", "Noir", 1, 10 * euro},
+		ligneDeTest{"9782070612758", "", "", 1, 10 * euro},
+	)
+	if b := commande.Lignes[0].BudgetID; b != ids["Polars"] {
+		t.Errorf("ligne Noir imputée au budget %d, attendu %d", b, ids["Polars"])
+	}
+	if ligne := commande.Lignes[1]; ligne.BudgetID != ids["Général"] || ligne.Titre != "Le Petit Prince" {
+		t.Errorf("ligne du catalogue : %+v", ligne)
+	}
+
+	// Un même genre ne peut avoir qu'un fonds par an ; redéfinir un fonds change son montant
+	if _, err := l.acquisitions.DefinirBudget("Policier bis", "Policier", annee, euro); err == nil || !strings.Contains(err.Error(), "couvre déjà") {
+		t.Errorf("second fonds du même genre : %v", err)
+	}
+	if budget, err := l.acquisitions.DefinirBudget("polars", "", annee, 800*euro); err != nil || budget.ID != ids["Polars"] || budget.Montant != 800*euro || budget.Genre != "Policier" {
+		t.Errorf("nouveau montant : %+v, %v", budget, err)
+	}
+}
+
+func TestPasserCommandeDansLeBudget(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	if _, err := l.acquisitions.DefinirBudget("Général", "", time.Now().Year(), 100*euro); err != nil {
+		t.Fatal(err)
+	}
+
+	premiere := commander(t, l, ligneDeTest{"9780306406157", "Fondation", "Science-fiction", 3, 20 * euro})
+	if err := l.acquisitions.PasserCommande(premiere.ID); err != nil {
+		t.Fatal(err)
+	}
+	verifierBilan(t, l, "Général", 60*euro, 0, 40*euro)
+
+	// Deux lignes qui tiennent chacune dans le reste mais pas ensemble
+	trop := commander(t, l,
+		ligneDeTest{"9782070360024", "L'Étranger", "Roman", 1, 30 * euro},
+		ligneDeTest{"9782070368228", "La Peste", "Roman", 1, 15 * euro},
+	)
+	err := l.acquisitions.PasserCommande(trop.ID)
+	if err == nil || !strings.Contains(err.Error(), "dépasse le budget du fonds 'Général' : 45,00 € demandés, 40,00 € disponibles") {
+		t.Errorf("commande hors budget : %v", err)
+	}
+	if statut := l.acquisitions.TrouverCommandeParID(trop.ID).Statut; statut != models.COMMANDE_BROUILLON {
+		t.Errorf("commande refusée au statut %s, attendu brouillon", statut)
+	}
+
+	// Le reste exact passe
+	juste := commander(t, l, ligneDeTest{"9782070612758", "Le Petit Prince", "Roman", 2, 20 * euro})
+	if err := l.acquisitions.PasserCommande(juste.ID); err != nil {
+		t.Errorf("commande du reste exact : %v", err)
+	}
+	verifierBilan(t, l, "Général", 100*euro, 0, 0)
+
+	// Annuler une commande passée libère son montant
+	if err := l.acquisitions.AnnulerCommande(premiere.ID); err != nil {
+		t.Fatal(err)
+	}
+	verifierBilan(t, l, "Général", 40*euro, 0, 60*euro)
+	if err := l.acquisitions.PasserCommande(trop.ID); err != nil {
+		t.Errorf("commande après annulation : %v", err)
+	}
+
+	refus := []struct {
+		nom    string
+		id     int
+		erreur string
+	}{
+		{"commande déjà passée", juste.ID, "déjà été passée"},
+		{"commande annulée", premiere.ID, "déjà été passée"},
+		{"commande vide", commander(t, l).ID, "est vide"},
+		{"commande inconnue", 99, "aucune commande"},
+	}
+	for _, r := range refus {
+		if err := l.acquisitions.PasserCommande(r.id); err == nil || !strings.Contains(err.Error(), r.erreur) {
+			t.Errorf("%s : erreur %v, attendu une erreur contenant %q", r.nom, err, r.erreur)
+		}
+	}
+}
+
+func TestReceptionsPartielles(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	if _, err := l.acquisitions.DefinirBudget("Général", "", time.Now().Year(), 200*euro); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	commande := commander(t, l,
+		ligneDeTest{"9780306406157", "Fondation", "Science-fiction", 3, 12 * euro},
+		ligneDeTest{"9782070612758", "", "", 2, 10 * euro},
+	)
+	if err := l.acquisitions.PasserCommande(commande.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	etapes := []struct {
+		nom      string
+		ligne    int
+		quantite int
+		statut   string
+		engage   models.Montant
+		depense  models.Montant
+	}{
+		{"deux exemplaires d'un titre nouveau", 1, 2, models.COMMANDE_PARTIELLE, 32 * euro, 24 * euro},
+		{"la ligne du catalogue en entier", 2, 2, models.COMMANDE_PARTIELLE, 12 * euro, 44 * euro},
+		{"le dernier exemplaire", 1, 1, models.COMMANDE_RECUE, 0, 56 * euro},
+	}
+	for _, e := range etapes {
+		recus, err := l.acquisitions.Recevoir(commande.ID, e.ligne, e.quantite)
+		if err != nil {
+			t.Fatalf("%s : %v", e.nom, err)
+		}
+		if len(recus) != e.quantite {
+			t.Errorf("%s : %d exemplaire(s) créé(s), attendu %d", e.nom, len(recus), e.quantite)
+		}
+		for _, livre := range recus {
+			if a := livre.Acquisition; a == nil || a.CommandeID != commande.ID || a.Fournisseur != "Librairie du Centre" {
+				t.Errorf("%s : provenance du livre %d : %+v", e.nom, livre.ID, a)
+			}
+		}
+		if statut := l.acquisitions.TrouverCommandeParID(commande.ID).Statut; statut != e.statut {
+			t.Errorf("%s : statut %s, attendu %s", e.nom, statut, e.statut)
+		}
+		verifierBilan(t, l, "Général", e.engage, e.depense, 200*euro-e.engage-e.depense)
+	}
+
+	// Trois exemplaires de Fondation, trois du Petit Prince (dont celui d'avant la commande)
+	for isbn, nombre := range map[string]int{"9780306406157": 3, "9782070612758": 3} {
+		exemplaires := 0
+		for _, livre := range l.livres.ListerLivres() {
+			if livre.ISBN == isbn {
+				exemplaires++
+			}
+		}
+		if exemplaires != nombre {
+			t.Errorf("ISBN %s : %d exemplaire(s) au catalogue, attendu %d", isbn, exemplaires, nombre)
+		}
+	}
+	if commande := l.acquisitions.TrouverCommandeParID(commande.ID); commande.DateCloture.IsZero() {
+		t.Error("commande reçue sans date de clôture")
+	}
+
+	// Une commande reçue n'attend plus rien et ne peut plus être annulée
+	if _, err := l.acquisitions.Recevoir(commande.ID, 1, 1); err == nil || !strings.Contains(err.Error(), "n'attend pas de livraison") {
+		t.Errorf("réception après la dernière : %v", err)
+	}
+	if err := l.acquisitions.AnnulerCommande(commande.ID); err == nil || !strings.Contains(err.Error(), "déjà close") {
+		t.Errorf("annulation d'une commande reçue : %v", err)
+	}
+}
+
+func TestReceptionRefusee(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	commande := commander(t, l, ligneDeTest{"9780306406157", "Fondation", "Science-fiction", 2, 12 * euro})
+
+	if _, err := l.acquisitions.Recevoir(commande.ID, 1, 1); err == nil || !strings.Contains(err.Error(), "n'attend pas de livraison") {
+		t.Errorf("réception d'un brouillon : %v", err)
+	}
+	if err := l.acquisitions.PasserCommande(commande.ID); err != nil {
+		t.Fatal(err) // hors budget : aucun fonds n'est défini
+	}
+
+	cas := []struct {
+		nom      string
+		ligne    int
+		quantite int
+		erreur   string
+	}{
+		{"plus que le restant", 1, 3, "2 exemplaire(s) de « Fondation » sont attendus, pas 3"},
+		{"quantité nulle", 1, 0, "pas 0"},
+		{"ligne inconnue", 2, 1, "pas de ligne 2"},
+	}
+	for _, c := range cas {
+		if _, err := l.acquisitions.Recevoir(commande.ID, c.ligne, c.quantite); err == nil || !strings.Contains(err.Error(), c.erreur) {
+			t.Errorf("%s : erreur %v, attendu une erreur contenant %q", c.nom, err, c.erreur)
+		}
+	}
+	if n := len(l.livres.ListerLivres()); n != 0 {
+		t.Errorf("%d livre(s) créé(s) par des réceptions refusées", n)
+	}
+}
+
+func TestAnnulerLibereLeReliquat(t *testing.T) {
+	dossier := t.TempDir()
+	l := ouvrirLibrairie(t, dossier)
+	if _, err := l.acquisitions.DefinirBudget("Général", "", time.Now().Year(), 100*euro); err != nil {
+		t.Fatal(err)
+	}
+
+	commande := commander(t, l, ligneDeTest{"9780306406157", "Fondation", "Science-fiction", 5, 20 * euro})
+	if err := l.acquisitions.PasserCommande(commande.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.acquisitions.Recevoir(commande.ID, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	verifierBilan(t, l, "Général", 60*euro, 40*euro, 0)
+
+	suivante := commander(t, l, ligneDeTest{"9782070360024", "L'Étranger", "Roman", 1, 10 * euro})
+	if err := l.acquisitions.PasserCommande(suivante.ID); err == nil {
+		t.Fatal("commande passée sur un budget entièrement engagé")
+	}
+
+	// Annuler une commande en partie reçue la solde : les livres reçus restent
+	// une dépense, le reliquat n'est plus engagé
+	if err := l.acquisitions.AnnulerCommande(commande.ID); err != nil {
+		t.Fatal(err)
+	}
+	if statut := l.acquisitions.TrouverCommandeParID(commande.ID).Statut; statut != models.COMMANDE_SOLDEE {
+		t.Errorf("statut %s, attendu %s", statut, models.COMMANDE_SOLDEE)
+	}
+	verifierBilan(t, l, "Général", 0, 40*euro, 60*euro)
+	if _, err := l.acquisitions.Recevoir(commande.ID, 1, 1); err == nil {
+		t.Error("réception sur une commande soldée")
+	}
+	if err := l.acquisitions.PasserCommande(suivante.ID); err != nil {
+		t.Errorf("commande après le solde : %v", err)
+	}
+
+	// Un brouillon est simplement annulé ; une commande close ne l'est pas deux fois
+	brouillon := commander(t, l, ligneDeTest{"9782070368228", "La Peste", "Roman", 1, 10 * euro})
+	if err := l.acquisitions.AnnulerCommande(brouillon.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{brouillon.ID, commande.ID} {
+		if err := l.acquisitions.AnnulerCommande(id); err == nil || !strings.Contains(err.Error(), "déjà close") {
+			t.Errorf("commande #%d annulée deux fois : %v", id, err)
+		}
+	}
+
+	// Le solde et le bilan survivent à un redémarrage
+	l = ouvrirLibrairie(t, dossier)
+	if statut := l.acquisitions.TrouverCommandeParID(commande.ID).Statut; statut != models.COMMANDE_SOLDEE {
+		t.Errorf("statut après redémarrage %s", statut)
+	}
+	verifierBilan(t, l, "Général", 10*euro, 40*euro, 50*euro)
+}
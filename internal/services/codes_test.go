@@ -0,0 +1,109 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCodesReprisALaMain(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	for _, livre := range [][2]string{{"Le Petit Prince", "9782070612758"}, {"L'Étranger", "9782070360024"}} {
+		if err := l.livres.AjouterLivre(livre[0], "Auteur Test", livre[1], "Roman", "01/01/1950"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range [][2]string{{"Zoé Dupont", "zoe@example.com"}, {"Marc Durand", "marc@example.com"}} {
+		if err := l.membres.AjouterMembre(m[0], m[1], "0601020304"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	livres := []struct {
+		nom    string
+		id     int
+		code   string
+		refuse bool
+	}{
+		{"code généré d'un futur livre", 1, "LIV000005", true},
+		{"code généré d'un autre livre", 1, "LIV000002", true},
+		{"code généré sur moins de six chiffres", 1, "liv5", true},
+		{"code d'origine du livre", 1, "LIV000001", false},
+		{"ancienne étiquette", 1, "anc-0042", false},
+		{"ancienne étiquette déjà portée", 2, "ANC-0042", true},
+		{"préfixe suivi d'autre chose que des chiffres", 2, "LIVRE-12", false},
+		{"code généré sur un autre poste", 2, "LIVK3QF-000002", true},
+	}
+	for _, c := range livres {
+		err := l.livres.AttribuerCodeBarres(c.id, c.code)
+		if c.refuse != (err != nil) {
+			t.Errorf("livre %d, %s (%s) : refus attendu %v, erreur %v", c.id, c.nom, c.code, c.refuse, err)
+		}
+	}
+
+	cartes := []struct {
+		nom    string
+		id     int
+		numero string
+		refuse bool
+	}{
+		{"carte générée d'un futur membre", 1, "MEM000009", true},
+		{"carte d'origine du membre", 2, "MEM000002", false},
+		{"ancienne carte", 1, "C-1234", false},
+		{"ancienne carte déjà portée", 2, "c-1234", true},
+	}
+	for _, c := range cartes {
+		err := l.membres.AttribuerNumeroCarte(c.id, c.numero)
+		if c.refuse != (err != nil) {
+			t.Errorf("membre %d, %s (%s) : refus attendu %v, erreur %v", c.id, c.nom, c.numero, c.refuse, err)
+		}
+	}
+
+	// Les livres et membres créés ensuite reçoivent le code réservé à leur numéro
+	if err := l.livres.AjouterLivre("Madame Bovary", "Gustave Flaubert", "9782070368228", "Roman", "01/01/1857"); err != nil {
+		t.Fatal(err)
+	}
+	if livre, _ := l.livres.TrouverLivreParCode("LIV000003"); livre == nil || livre.Titre != "Madame Bovary" {
+		t.Errorf("LIV000003 désigne %+v", livre)
+	}
+}
+
+func TestOrdreDeLectureDesCodes(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	for _, livre := range [][2]string{{"Le Petit Prince", "9782070612758"}, {"L'Étranger", "9782070360024"}, {"Madame Bovary", "9782070368228"}} {
+		if err := l.livres.AjouterLivre(livre[0], "Auteur Test", livre[1], "Roman", "01/01/1950"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Le Petit Prince reprend une étiquette qui est aussi l'ISBN de L'Étranger,
+	// et Madame Bovary une étiquette qui se lit comme l'ID 2
+	if err := l.livres.AttribuerCodeBarres(1, "9782070360024"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.livres.AttribuerCodeBarres(3, "002"); err != nil {
+		t.Fatal(err)
+	}
+	// Un livre dont l'ID se lit comme l'ISBN de Madame Bovary
+	isbn, _ := strconv.Atoi("9782070368228")
+	l.livres.livres[1].ID = isbn
+
+	cas := []struct {
+		saisie string
+		titre  string
+	}{
+		{"9782070360024", "Le Petit Prince"}, // code-barres avant ISBN
+		{"002", "Madame Bovary"},             // code-barres avant ID
+		{"9782070368228", "Madame Bovary"},   // ISBN avant ID
+		{"\t]C0liv000002\r", "L'Étranger"},   // saisie de douchette normalisée
+		{"1", "Le Petit Prince"},             // ID en dernier recours
+	}
+	for _, c := range cas {
+		livre, _ := l.livres.TrouverLivreParCode(c.saisie)
+		if livre == nil || livre.Titre != c.titre {
+			t.Errorf("saisie %q : %+v, attendu « %s »", c.saisie, livre, c.titre)
+		}
+	}
+	if livre, _ := l.livres.TrouverLivreParCode("LIV000099"); livre != nil {
+		t.Errorf("code inconnu : %+v", livre)
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// idsDesLivres retourne les ID des livres, triés
+func idsDesLivres(livres []models.Livre) []int {
+	var ids []int
+	for _, livre := range livres {
+		ids = append(ids, livre.ID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestVariantesDeNom(t *testing.T) {
+	dossier := t.TempDir()
+	l := ouvrirLibrairie(t, dossier)
+
+	if err := l.livres.AjouterLivre("Fondation", "Isaac Asimov", "9780306406157", "Science-fiction", "01/05/1951"); err != nil {
+		t.Fatal(err)
+	}
+	asimov := l.contributeurs.Resoudre("Isaac Asimov")
+	if asimov == nil || asimov.CleTri != "Asimov, Isaac" {
+		t.Fatalf("fiche créée avec le livre : %+v", asimov)
+	}
+	if err := l.contributeurs.AjouterVariante(asimov.ID, "I. Asimov"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un livre saisi sous une variante, à la casse près, rejoint la même fiche
+	// et affiche le nom canonique
+	if err := l.livres.AjouterLivre("Les Robots", "i. asimov", "9782070360024", "Science-fiction", "01/12/1950"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(l.contributeurs.ListerContributeurs()); n != 1 {
+		t.Errorf("%d fiches, attendu 1", n)
+	}
+	if robots := livre(t, l, 2); robots.Auteur != "Isaac Asimov" || !robots.AContributeur(asimov.ID) {
+		t.Errorf("auteur %q, contributions %+v", robots.Auteur, robots.Contributions)
+	}
+
+	// La recherche par variante trouve tous les livres de la fiche
+	for _, terme := range []string{"I. Asimov", "asimov, isaac"} {
+		if ids := idsDesLivres(l.livres.RechercherLivres(terme)); !reflect.DeepEqual(ids, []int{1, 2}) {
+			t.Errorf("recherche %q : livres %v, attendu [1 2]", terme, ids)
+		}
+	}
+
+	// Un nom ou une variante ne désigne qu'une seule fiche
+	gary, err := l.contributeurs.AjouterContributeur("Romain Gary", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.contributeurs.AjouterVariante(gary.ID, "Émile Ajar"); err != nil {
+		t.Fatal(err)
+	}
+	refus := []struct {
+		nom    string
+		action func() error
+	}{
+		{"variante déjà prise par une autre fiche", func() error { return l.contributeurs.AjouterVariante(asimov.ID, "émile ajar") }},
+		{"variante égale au nom d'une autre fiche", func() error { return l.contributeurs.AjouterVariante(asimov.ID, "Romain Gary") }},
+		{"nouvelle fiche sous une variante", func() error { _, err := l.contributeurs.AjouterContributeur("I. Asimov", ""); return err }},
+		{"renommage vers une variante d'une autre fiche", func() error { return l.livres.ModifierContributeur(gary.ID, "I. Asimov", "") }},
+	}
+	for _, r := range refus {
+		if err := r.action(); err == nil || !strings.Contains(err.Error(), "déjà") {
+			t.Errorf("%s : erreur %v", r.nom, err)
+		}
+	}
+}
+
+func TestRenommerGardeLAncienNom(t *testing.T) {
+	dossier := t.TempDir()
+	l := ouvrirLibrairie(t, dossier)
+
+	if err := l.livres.AjouterLivre("La Promesse de l'aube", "Romain Gary", "9782070368228", "Roman", "01/01/1960"); err != nil {
+		t.Fatal(err)
+	}
+	gary := l.contributeurs.Resoudre("Romain Gary")
+	if err := l.contributeurs.AjouterVariante(gary.ID, "Émile Ajar"); err != nil {
+		t.Fatal(err)
+	}
+
+	// L'ancien nom devient une variante ; les livres affichent le nouveau
+	if err := l.livres.ModifierContributeur(gary.ID, "Roman Kacew", "Kacew, Roman"); err != nil {
+		t.Fatal(err)
+	}
+	if auteur := livre(t, l, 1).Auteur; auteur != "Roman Kacew" {
+		t.Errorf("auteur affiché %q, attendu Roman Kacew", auteur)
+	}
+	if variantes := l.contributeurs.Resoudre("Roman Kacew").Variantes; !reflect.DeepEqual(variantes, []string{"Émile Ajar", "Romain Gary"}) {
+		t.Errorf("variantes %v", variantes)
+	}
+
+	// Reprendre une variante comme nom la retire des variantes, sans doublon
+	if err := l.livres.ModifierContributeur(gary.ID, "Émile Ajar", ""); err != nil {
+		t.Fatal(err)
+	}
+	kacew := l.contributeurs.Resoudre("Roman Kacew")
+	if kacew == nil || kacew.Nom != "Émile Ajar" || !reflect.DeepEqual(kacew.Variantes, []string{"Romain Gary", "Roman Kacew"}) {
+		t.Fatalf("après le second renommage : %+v", kacew)
+	}
+	if kacew.CleTri != "Kacew, Roman" {
+		t.Errorf("clé de tri %q, attendu qu'elle soit conservée", kacew.CleTri)
+	}
+
+	// Les variantes survivent à un redémarrage
+	l = ouvrirLibrairie(t, dossier)
+	for _, nom := range []string{"romain gary", "ROMAN KACEW", "émile ajar"} {
+		if c := l.contributeurs.Resoudre(nom); c == nil || c.ID != gary.ID {
+			t.Errorf("%q après redémarrage : %+v", nom, c)
+		}
+	}
+	if auteur := livre(t, l, 1).Auteur; auteur != "Émile Ajar" {
+		t.Errorf("auteur affiché après redémarrage %q", auteur)
+	}
+}
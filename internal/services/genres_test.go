@@ -0,0 +1,148 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// taxonomie ouvre une librairie où Policier (genre par défaut) est rangé sous
+// Fiction et a pour sous-genre Noir ; elle retourne les ID de ces trois genres
+func taxonomie(t *testing.T) (librairie, map[string]int) {
+	t.Helper()
+
+	l := ouvrirLibrairie(t, t.TempDir())
+	fiction, err := l.genres.AjouterGenre("Fiction", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policier := l.genres.Resoudre("Policier")
+	if err := l.livres.ModifierGenre(policier.ID, "", fiction.ID); err != nil {
+		t.Fatal(err)
+	}
+	noir, err := l.genres.AjouterGenre("Noir", policier.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, map[string]int{"Fiction": fiction.ID, "Policier": policier.ID, "Noir": noir.ID}
+}
+
+func TestDeplacementDeGenreSansCycle(t *testing.T) {
+	l, ids := taxonomie(t)
+
+	cas := []struct {
+		nom    string
+		genre  string
+		parent int
+		erreur string // vide si le déplacement est permis
+	}{
+		{"sous lui-même", "Fiction", ids["Fiction"], "sous lui-même"},
+		{"sous son enfant", "Fiction", ids["Policier"], "sous lui-même"},
+		{"sous son petit-enfant", "Fiction", ids["Noir"], "sous lui-même"},
+		{"sous son enfant, depuis le milieu de l'arbre", "Policier", ids["Noir"], "sous lui-même"},
+		{"sous un parent inconnu", "Noir", 999, "aucun genre parent"},
+		{"parent négatif : parent conservé", "Noir", -1, ""},
+	}
+	for _, c := range cas {
+		err := l.livres.ModifierGenre(ids[c.genre], "", c.parent)
+		switch {
+		case c.erreur == "" && err != nil:
+			t.Errorf("%s : déplacement refusé : %v", c.nom, err)
+		case c.erreur != "" && (err == nil || !strings.Contains(err.Error(), c.erreur)):
+			t.Errorf("%s : erreur %v, attendu une erreur contenant %q", c.nom, err, c.erreur)
+		}
+	}
+
+	// Aucun déplacement refusé n'a modifié l'arbre
+	if chemin := l.genres.Chemin(ids["Noir"]); chemin != "Fiction > Policier > Noir" {
+		t.Errorf("chemin de Noir : %q", chemin)
+	}
+
+	// Déplacer un genre entraîne ses sous-genres
+	if err := l.livres.ModifierGenre(ids["Policier"], "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if chemin := l.genres.Chemin(ids["Noir"]); chemin != "Policier > Noir" {
+		t.Errorf("chemin de Noir après déplacement : %q", chemin)
+	}
+	if profondeur := l.genres.Profondeur(ids["Noir"]); profondeur != 1 {
+		t.Errorf("profondeur de Noir : %d, attendu 1", profondeur)
+	}
+
+	// Noir a suivi Policier : la racine ne peut toujours pas passer sous lui
+	if err := l.livres.ModifierGenre(ids["Policier"], "", ids["Noir"]); err == nil {
+		t.Error("Policier déplacé sous Noir, son sous-genre")
+	}
+}
+
+func TestAncetresDUnFichierCyclique(t *testing.T) {
+	l, ids := taxonomie(t)
+
+	// Un fichier modifié à la main peut contenir une boucle : Fiction sous Noir
+	fiction, _ := l.genres.TrouverGenreParID(ids["Fiction"])
+	fiction.ParentID = ids["Noir"]
+
+	ancetres := l.genres.Ancetres(ids["Noir"])
+	if len(ancetres) != 3 {
+		t.Fatalf("%d ancêtres, attendu 3 (chaque genre une seule fois)", len(ancetres))
+	}
+	if chemin := l.genres.Chemin(ids["Noir"]); chemin != "Fiction > Policier > Noir" {
+		t.Errorf("chemin de Noir : %q", chemin)
+	}
+	if descendants := l.genres.Descendants(ids["Fiction"]); len(descendants) != 3 {
+		t.Errorf("descendants de Fiction : %v, attendu les trois genres", descendants)
+	}
+}
+
+func TestGenresCumules(t *testing.T) {
+	l, ids := taxonomie(t)
+
+	livres := []struct{ titre, isbn, genre string }{
+		{"Le Dahlia noir", "9780306406157", "Noir"},
+		{"Maigret tend un piège", "9782070360024", "Policier"},
+		{"Le Facteur sonne toujours deux fois", "9782070368228", "Noir"},
+		{"Le Petit Prince", "9782070612758", "Roman"},
+	}
+	for _, livre := range livres {
+		if err := l.livres.AjouterLivre(livre.titre, "Auteur", livre.isbn, livre.genre, "01/01/1950"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Un livre classé à la fois en Noir et en Policier ne compte qu'une fois pour Policier
+	if err := l.livres.AjouterSujet(3, "Policier"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := l.livres.ObtenirStatistiques(statistiques.Periode{}, 0)
+	nombres := make(map[string]int)
+	var ordre []string
+	for _, r := range stats.ParGenreCumule {
+		nombres[r.Libelle] = r.Nombre
+		ordre = append(ordre, r.Libelle)
+	}
+	if attendu := map[string]int{"Fiction": 3, "Policier": 3, "Noir": 2, "Roman": 1}; !reflect.DeepEqual(nombres, attendu) {
+		t.Errorf("répartition cumulée %v, attendu %v", nombres, attendu)
+	}
+	// Dans l'ordre de l'arbre : chaque genre précède ses sous-genres
+	if !reflect.DeepEqual(ordre[:3], []string{"Fiction", "Policier", "Noir"}) {
+		t.Errorf("ordre %v, attendu Fiction, Policier puis Noir", ordre)
+	}
+
+	// Chercher un genre, par son nom ou un alias, trouve aussi les livres de ses sous-genres
+	if err := l.genres.AjouterAlias(ids["Policier"], "Polar"); err != nil {
+		t.Fatal(err)
+	}
+	for _, terme := range []string{"policier", "POLAR", "fiction"} {
+		var trouves []int
+		for _, livre := range l.livres.RechercherLivres(terme) {
+			trouves = append(trouves, livre.ID)
+		}
+		sort.Ints(trouves)
+		if !reflect.DeepEqual(trouves, []int{1, 2, 3}) {
+			t.Errorf("recherche %q : livres %v, attendu [1 2 3]", terme, trouves)
+		}
+	}
+}
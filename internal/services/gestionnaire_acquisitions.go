@@ -0,0 +1,651 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// ACQUISITIONS
+// Fournisseurs, bons de commande et budgets. Une commande passée engage le
+// budget de ses lignes ; chaque exemplaire reçu entre au catalogue avec son
+// prix et sa provenance, et devient une dépense.
+// ========================================
+
+type GestionnaireAcquisitions struct {
+	fournisseurs []models.Fournisseur
+	commandes    []models.Commande
+	budgets      []models.Budget
+
+	sequenceFournisseurs *storage.Sequence
+	sequenceCommandes    *storage.Sequence
+	sequenceBudgets      *storage.Sequence
+
+	stockage           storage.Storage
+	gestionnaireLivres *GestionnaireLivres
+}
+
+// donneesAcquisitions est le format du fichier de sauvegarde
+type donneesAcquisitions struct {
+	Fournisseurs []models.Fournisseur `json:"fournisseurs"`
+	Commandes    []models.Commande    `json:"commandes"`
+	Budgets      []models.Budget      `json:"budgets"`
+}
+
+// BilanBudget fait le point sur un budget : le montant engagé est celui des
+// livres commandés et pas encore reçus, le montant dépensé celui des livres reçus
+type BilanBudget struct {
+	Budget  models.Budget
+	Engage  models.Montant
+	Depense models.Montant
+	Reste   models.Montant
+}
+
+// DepenseFournisseur totalise les livres reçus d'un fournisseur sur une période
+type DepenseFournisseur struct {
+	FournisseurID int
+	Nom           string
+	Commandes     int
+	Exemplaires   int
+	Montant       models.Montant
+}
+
+// CoutEmprunt rapporte le prix payé pour un titre au nombre de ses emprunts
+type CoutEmprunt struct {
+	Titre          string
+	ISBN           string
+	Exemplaires    int
+	Cout           models.Montant
+	Emprunts       int
+	CoutParEmprunt models.Montant // 0 tant que le titre n'a pas été emprunté
+}
+
+func (ga *GestionnaireAcquisitions) sauvegarderAcquisitions() error {
+	return ga.stockage.Sauvegarder(donneesAcquisitions{Fournisseurs: ga.fournisseurs, Commandes: ga.commandes, Budgets: ga.budgets})
+}
+
+func (ga *GestionnaireAcquisitions) ChargerAcquisitions() error {
+	var donnees donneesAcquisitions
+	if err := ga.stockage.Charger(&donnees); err != nil {
+		return err
+	}
+
+	if donnees.Fournisseurs != nil {
+		ga.fournisseurs = donnees.Fournisseurs
+	}
+	if donnees.Commandes != nil {
+		ga.commandes = donnees.Commandes
+	}
+	if donnees.Budgets != nil {
+		ga.budgets = donnees.Budgets
+	}
+
+	for _, fournisseur := range ga.fournisseurs {
+		ga.sequenceFournisseurs.Observer(fournisseur.ID)
+	}
+	for _, commande := range ga.commandes {
+		ga.sequenceCommandes.Observer(commande.ID)
+	}
+	for _, budget := range ga.budgets {
+		ga.sequenceBudgets.Observer(budget.ID)
+	}
+
+	return nil
+}
+
+func NouveauGestionnaireAcquisitions(stockage storage.Storage, sequences *storage.Sequences, gl *GestionnaireLivres) *GestionnaireAcquisitions {
+	ga := &GestionnaireAcquisitions{
+		fournisseurs:         make([]models.Fournisseur, 0),
+		commandes:            make([]models.Commande, 0),
+		budgets:              make([]models.Budget, 0),
+		sequenceFournisseurs: sequences.Sequence("fournisseurs"),
+		sequenceCommandes:    sequences.Sequence("commandes"),
+		sequenceBudgets:      sequences.Sequence("budgets"),
+		stockage:             stockage,
+		gestionnaireLivres:   gl,
+	}
+
+	ga.ChargerAcquisitions()
+	return ga
+}
+
+// ========================================
+// FOURNISSEURS
+// ========================================
+
+func (ga *GestionnaireAcquisitions) AjouterFournisseur(nom, email, telephone string) (*models.Fournisseur, error) {
+	nom = strings.TrimSpace(nom)
+	if !validators.ValiderTitre(nom) {
+		return nil, fmt.Errorf("le nom du fournisseur est invalide")
+	}
+	email = strings.TrimSpace(email)
+	if email != "" && !validators.ValiderEmail(email) {
+		return nil, fmt.Errorf("l'adresse email est invalide")
+	}
+	telephone = strings.TrimSpace(telephone)
+	if telephone != "" && !validators.ValiderTelephone(telephone) {
+		return nil, fmt.Errorf("le numéro de téléphone est invalide")
+	}
+
+	for _, fournisseur := range ga.fournisseurs {
+		if strings.EqualFold(fournisseur.Nom, nom) {
+			return nil, fmt.Errorf("le fournisseur '%s' existe déjà (ID : %d)", fournisseur.Nom, fournisseur.ID)
+		}
+	}
+
+	id, err := ga.sequenceFournisseurs.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	ga.fournisseurs = append(ga.fournisseurs, models.Fournisseur{ID: id, Nom: nom, Email: email, Telephone: telephone})
+
+	if err := ga.sauvegarderAcquisitions(); err != nil {
+		return nil, err
+	}
+	return &ga.fournisseurs[len(ga.fournisseurs)-1], nil
+}
+
+func (ga *GestionnaireAcquisitions) ListerFournisseurs() []models.Fournisseur {
+	return ga.fournisseurs
+}
+
+func (ga *GestionnaireAcquisitions) TrouverFournisseurParID(id int) *models.Fournisseur {
+	for i, fournisseur := range ga.fournisseurs {
+		if fournisseur.ID == id {
+			return &ga.fournisseurs[i]
+		}
+	}
+	return nil
+}
+
+// ========================================
+// BUDGETS
+// ========================================
+
+// DefinirBudget crée le budget d'un fonds pour l'année, ou change son montant
+// s'il existe déjà. Le genre n'est lu qu'à la création.
+func (ga *GestionnaireAcquisitions) DefinirBudget(fonds, genre string, annee int, montant models.Montant) (*models.Budget, error) {
+	fonds = strings.TrimSpace(fonds)
+	if !validators.ValiderTitre(fonds) {
+		return nil, fmt.Errorf("le nom du fonds est invalide")
+	}
+	if annee < 2000 || annee > 9999 {
+		return nil, fmt.Errorf("l'année %d est invalide", annee)
+	}
+	if montant < 0 {
+		return nil, fmt.Errorf("le montant du budget ne peut pas être négatif")
+	}
+
+	for i, budget := range ga.budgets {
+		if budget.Annee == annee && strings.EqualFold(budget.Fonds, fonds) {
+			ga.budgets[i].Montant = montant
+			return &ga.budgets[i], ga.sauvegarderAcquisitions()
+		}
+	}
+
+	nomGenre := ""
+	if strings.TrimSpace(genre) != "" {
+		resolu, err := ga.gestionnaireLivres.resoudreGenre(genre)
+		if err != nil {
+			return nil, err
+		}
+		nomGenre = resolu.Nom
+	}
+	for _, budget := range ga.budgets {
+		if budget.Annee == annee && budget.Genre == nomGenre {
+			if nomGenre == "" {
+				return nil, fmt.Errorf("le fonds '%s' couvre déjà tous les genres en %d", budget.Fonds, annee)
+			}
+			return nil, fmt.Errorf("le fonds '%s' couvre déjà le genre %s en %d", budget.Fonds, nomGenre, annee)
+		}
+	}
+
+	id, err := ga.sequenceBudgets.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	ga.budgets = append(ga.budgets, models.Budget{ID: id, Fonds: fonds, Genre: nomGenre, Annee: annee, Montant: montant})
+
+	if err := ga.sauvegarderAcquisitions(); err != nil {
+		return nil, err
+	}
+	return &ga.budgets[len(ga.budgets)-1], nil
+}
+
+func (ga *GestionnaireAcquisitions) TrouverBudgetParID(id int) *models.Budget {
+	for i, budget := range ga.budgets {
+		if budget.ID == id {
+			return &ga.budgets[i]
+		}
+	}
+	return nil
+}
+
+// budgetPour choisit le budget qui paie un livre du genre donné : le fonds du
+// genre ou, à défaut, de son plus proche genre parent, sinon le fonds sans genre
+// de l'année (0 = hors budget)
+func (ga *GestionnaireAcquisitions) budgetPour(genre string, annee int) int {
+	parGenre := make(map[string]int)
+	for _, budget := range ga.budgets {
+		if budget.Annee == annee {
+			parGenre[budget.Genre] = budget.ID
+		}
+	}
+
+	gg := ga.gestionnaireLivres.gestionnaireGenres
+	if resolu := gg.Resoudre(genre); resolu != nil {
+		for _, ancetre := range gg.Ancetres(resolu.ID) {
+			if id, ok := parGenre[ancetre.Nom]; ok {
+				return id
+			}
+		}
+	}
+	return parGenre[""]
+}
+
+// BilanBudgets fait le point sur les budgets de l'année, par fonds
+func (ga *GestionnaireAcquisitions) BilanBudgets(annee int) []BilanBudget {
+	var bilans []BilanBudget
+	for _, budget := range ga.budgets {
+		if budget.Annee != annee {
+			continue
+		}
+		engage, depense := ga.consommation(budget.ID)
+		bilans = append(bilans, BilanBudget{Budget: budget, Engage: engage, Depense: depense, Reste: budget.Montant - engage - depense})
+	}
+
+	sort.Slice(bilans, func(i, j int) bool {
+		return strings.ToLower(bilans[i].Budget.Fonds) < strings.ToLower(bilans[j].Budget.Fonds)
+	})
+	return bilans
+}
+
+// consommation retourne ce qui est engagé (commandé, pas encore reçu) et
+// dépensé (reçu) sur un budget
+func (ga *GestionnaireAcquisitions) consommation(budgetID int) (engage, depense models.Montant) {
+	for _, commande := range ga.commandes {
+		for _, ligne := range commande.Lignes {
+			if ligne.BudgetID != budgetID {
+				continue
+			}
+			depense += models.Montant(ligne.Recus()) * ligne.PrixUnitaire
+			if commande.EnAttente() {
+				engage += models.Montant(ligne.Restant()) * ligne.PrixUnitaire
+			}
+		}
+	}
+	return engage, depense
+}
+
+// ========================================
+// COMMANDES
+// ========================================
+
+func (ga *GestionnaireAcquisitions) CreerCommande(fournisseurID int) (*models.Commande, error) {
+	fournisseur := ga.TrouverFournisseurParID(fournisseurID)
+	if fournisseur == nil {
+		return nil, fmt.Errorf("aucun fournisseur trouvé avec l'ID %d", fournisseurID)
+	}
+
+	id, err := ga.sequenceCommandes.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	ga.commandes = append(ga.commandes, models.Commande{
+		ID:             id,
+		FournisseurID:  fournisseur.ID,
+		NomFournisseur: fournisseur.Nom,
+		Statut:         models.COMMANDE_BROUILLON,
+		DateCreation:   time.Now(),
+		Lignes:         make([]models.LigneCommande, 0),
+	})
+
+	if err := ga.sauvegarderAcquisitions(); err != nil {
+		return nil, err
+	}
+	return &ga.commandes[len(ga.commandes)-1], nil
+}
+
+// ListerCommandes retourne les commandes, les plus récentes d'abord
+func (ga *GestionnaireAcquisitions) ListerCommandes() []models.Commande {
+	commandes := make([]models.Commande, len(ga.commandes))
+	copy(commandes, ga.commandes)
+	sort.SliceStable(commandes, func(i, j int) bool { return commandes[i].ID > commandes[j].ID })
+	return commandes
+}
+
+// ListerCommandesEnAttente retourne les commandes dont des livres sont attendus
+func (ga *GestionnaireAcquisitions) ListerCommandesEnAttente() []models.Commande {
+	var commandes []models.Commande
+	for _, commande := range ga.commandes {
+		if commande.EnAttente() {
+			commandes = append(commandes, commande)
+		}
+	}
+	return commandes
+}
+
+func (ga *GestionnaireAcquisitions) TrouverCommandeParID(id int) *models.Commande {
+	for i, commande := range ga.commandes {
+		if commande.ID == id {
+			return &ga.commandes[i]
+		}
+	}
+	return nil
+}
+
+// AjouterLigne ajoute un titre à une commande en préparation. Pour un ISBN déjà
+// au catalogue, la fiche existante complète le titre, l'auteur et le genre ;
+// sinon ils sont obligatoires, avec la date de publication. La ligne est
+// imputée au budget du genre de l'année de la commande.
+func (ga *GestionnaireAcquisitions) AjouterLigne(commandeID int, isbn, titre, auteur, genre, datePublicationStr string, quantite int, prix models.Montant) (*models.LigneCommande, error) {
+	commande := ga.TrouverCommandeParID(commandeID)
+	if commande == nil {
+		return nil, fmt.Errorf("aucune commande trouvée avec l'ID %d", commandeID)
+	}
+	if commande.Statut != models.COMMANDE_BROUILLON {
+		return nil, fmt.Errorf("la commande #%d a déjà été passée, elle ne peut plus être modifiée", commande.ID)
+	}
+
+	if !validators.ValiderISBN(isbn) {
+		return nil, fmt.Errorf("l'ISBN est invalide (doit faire 10 ou 13 caractères)")
+	}
+	isbn = strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", "")
+	for _, ligne := range commande.Lignes {
+		if strings.EqualFold(ligne.ISBN, isbn) {
+			return nil, fmt.Errorf("l'ISBN %s est déjà commandé sur la ligne %d", isbn, ligne.Numero)
+		}
+	}
+	if quantite < 1 {
+		return nil, fmt.Errorf("la quantité doit être d'au moins un exemplaire")
+	}
+	if prix < 0 {
+		return nil, fmt.Errorf("le prix ne peut pas être négatif")
+	}
+
+	ligne := models.LigneCommande{
+		ISBN:         isbn,
+		Quantite:     quantite,
+		PrixUnitaire: prix,
+		Receptions:   make([]models.Reception, 0),
+	}
+
+	if livre, _ := ga.gestionnaireLivres.TrouverLivreParISBN(isbn); livre != nil {
+		ligne.Titre, ligne.Auteur, ligne.Genre = livre.Titre, livre.Auteur, livre.Genre
+	} else {
+		// Valider dès maintenant ce qui servira à créer la fiche à la réception
+		saisie, err := ga.gestionnaireLivres.validerSaisieLivre(titre, auteur, isbn, genre, datePublicationStr)
+		if err != nil {
+			return nil, err
+		}
+		ligne.Titre, ligne.Auteur, ligne.Genre, ligne.DatePublication = saisie.titre, strings.Join(saisie.auteurs, "; "), saisie.genre.Nom, saisie.datePublication
+	}
+	ligne.BudgetID = ga.budgetPour(ligne.Genre, commande.DateCreation.Year())
+
+	ligne.Numero = 1
+	if n := len(commande.Lignes); n > 0 {
+		ligne.Numero = commande.Lignes[n-1].Numero + 1
+	}
+	commande.Lignes = append(commande.Lignes, ligne)
+
+	if err := ga.sauvegarderAcquisitions(); err != nil {
+		return nil, err
+	}
+	return &commande.Lignes[len(commande.Lignes)-1], nil
+}
+
+func (ga *GestionnaireAcquisitions) SupprimerLigne(commandeID, numero int) error {
+	commande := ga.TrouverCommandeParID(commandeID)
+	if commande == nil {
+		return fmt.Errorf("aucune commande trouvée avec l'ID %d", commandeID)
+	}
+	if commande.Statut != models.COMMANDE_BROUILLON {
+		return fmt.Errorf("la commande #%d a déjà été passée, elle ne peut plus être modifiée", commande.ID)
+	}
+
+	for i, ligne := range commande.Lignes {
+		if ligne.Numero == numero {
+			commande.Lignes = append(commande.Lignes[:i], commande.Lignes[i+1:]...)
+			return ga.sauvegarderAcquisitions()
+		}
+	}
+	return fmt.Errorf("la commande #%d n'a pas de ligne %d", commande.ID, numero)
+}
+
+// PasserCommande envoie la commande au fournisseur.
+// RÈGLE MÉTIER : une commande ne peut pas dépasser ce qui reste sur ses budgets.
+func (ga *GestionnaireAcquisitions) PasserCommande(id int) error {
+	commande := ga.TrouverCommandeParID(id)
+	if commande == nil {
+		return fmt.Errorf("aucune commande trouvée avec l'ID %d", id)
+	}
+	if commande.Statut != models.COMMANDE_BROUILLON {
+		return fmt.Errorf("la commande #%d a déjà été passée", commande.ID)
+	}
+	if len(commande.Lignes) == 0 {
+		return fmt.Errorf("la commande #%d est vide", commande.ID)
+	}
+
+	parBudget := make(map[int]models.Montant)
+	for _, ligne := range commande.Lignes {
+		if ligne.BudgetID != 0 {
+			parBudget[ligne.BudgetID] += ligne.Total()
+		}
+	}
+	for budgetID, montant := range parBudget {
+		budget := ga.TrouverBudgetParID(budgetID)
+		if budget == nil {
+			continue
+		}
+		engage, depense := ga.consommation(budgetID)
+		if reste := budget.Montant - engage - depense; montant > reste {
+			return fmt.Errorf("la commande dépasse le budget du fonds '%s' : %s demandés, %s disponibles", budget.Fonds, montant, reste)
+		}
+	}
+
+	commande.Statut = models.COMMANDE_PASSEE
+	commande.DatePassee = time.Now()
+	return ga.sauvegarderAcquisitions()
+}
+
+// Recevoir enregistre l'arrivée de quantite exemplaires d'une ligne : chacun
+// entre au catalogue avec son prix et sa provenance. Si la création d'une fiche
+// échoue en cours de route, les exemplaires déjà créés restent reçus.
+func (ga *GestionnaireAcquisitions) Recevoir(commandeID, numero, quantite int) ([]models.Livre, error) {
+	commande := ga.TrouverCommandeParID(commandeID)
+	if commande == nil {
+		return nil, fmt.Errorf("aucune commande trouvée avec l'ID %d", commandeID)
+	}
+	if !commande.EnAttente() {
+		return nil, fmt.Errorf("la commande #%d n'attend pas de livraison (%s)", commande.ID, models.LibelleStatutCommande(commande.Statut))
+	}
+
+	var ligne *models.LigneCommande
+	for i := range commande.Lignes {
+		if commande.Lignes[i].Numero == numero {
+			ligne = &commande.Lignes[i]
+		}
+	}
+	if ligne == nil {
+		return nil, fmt.Errorf("la commande #%d n'a pas de ligne %d", commande.ID, numero)
+	}
+	if quantite < 1 || quantite > ligne.Restant() {
+		return nil, fmt.Errorf("%d exemplaire(s) de « %s » sont attendus, pas %d", ligne.Restant(), ligne.Titre, quantite)
+	}
+
+	maintenant := time.Now()
+	acquisition := models.Acquisition{
+		CommandeID:  commande.ID,
+		Fournisseur: commande.NomFournisseur,
+		Prix:        ligne.PrixUnitaire,
+		BudgetID:    ligne.BudgetID,
+		Date:        maintenant,
+	}
+	datePublication := ""
+	if !ligne.DatePublication.IsZero() {
+		datePublication = ligne.DatePublication.Format("02/01/2006")
+	}
+
+	var recus []models.Livre
+	var erreur error
+	for range quantite {
+		livre, err := ga.gestionnaireLivres.AjouterExemplaireAcquis(ligne.ISBN, ligne.Titre, ligne.Auteur, ligne.Genre, datePublication, acquisition)
+		if err != nil {
+			erreur = fmt.Errorf("« %s » : %v", ligne.Titre, err)
+			break
+		}
+		recus = append(recus, livre)
+	}
+	if len(recus) == 0 {
+		return nil, erreur
+	}
+
+	reception := models.Reception{Date: maintenant, Quantite: len(recus)}
+	for _, livre := range recus {
+		reception.LivreIDs = append(reception.LivreIDs, livre.ID)
+	}
+	ligne.Receptions = append(ligne.Receptions, reception)
+
+	commande.Statut = models.COMMANDE_RECUE
+	for _, l := range commande.Lignes {
+		if l.Restant() > 0 {
+			commande.Statut = models.COMMANDE_PARTIELLE
+		}
+	}
+	if commande.Statut == models.COMMANDE_RECUE {
+		commande.DateCloture = maintenant
+	}
+
+	if err := ga.sauvegarderAcquisitions(); err != nil {
+		return recus, err
+	}
+	return recus, erreur
+}
+
+// AnnulerCommande abandonne une commande. Si des livres sont déjà arrivés, elle
+// est soldée : le reliquat n'est plus attendu et libère le budget.
+func (ga *GestionnaireAcquisitions) AnnulerCommande(id int) error {
+	commande := ga.TrouverCommandeParID(id)
+	if commande == nil {
+		return fmt.Errorf("aucune commande trouvée avec l'ID %d", id)
+	}
+
+	switch commande.Statut {
+	case models.COMMANDE_BROUILLON, models.COMMANDE_PASSEE:
+		commande.Statut = models.COMMANDE_ANNULEE
+	case models.COMMANDE_PARTIELLE:
+		commande.Statut = models.COMMANDE_SOLDEE
+	default:
+		return fmt.Errorf("la commande #%d est déjà close (%s)", commande.ID, models.LibelleStatutCommande(commande.Statut))
+	}
+
+	commande.DateCloture = time.Now()
+	return ga.sauvegarderAcquisitions()
+}
+
+// ========================================
+// RAPPORTS
+// ========================================
+
+// DepensesParFournisseur totalise les livres reçus sur la période, par fournisseur,
+// du plus gros montant au plus petit
+func (ga *GestionnaireAcquisitions) DepensesParFournisseur(periode statistiques.Periode) []DepenseFournisseur {
+	parFournisseur := make(map[int]*DepenseFournisseur)
+	for _, commande := range ga.commandes {
+		compte := false
+		for _, ligne := range commande.Lignes {
+			for _, reception := range ligne.Receptions {
+				if !periode.Contient(reception.Date) {
+					continue
+				}
+				depense := parFournisseur[commande.FournisseurID]
+				if depense == nil {
+					depense = &DepenseFournisseur{FournisseurID: commande.FournisseurID, Nom: commande.NomFournisseur}
+					if fournisseur := ga.TrouverFournisseurParID(commande.FournisseurID); fournisseur != nil {
+						depense.Nom = fournisseur.Nom
+					}
+					parFournisseur[commande.FournisseurID] = depense
+				}
+				if !compte {
+					depense.Commandes++
+					compte = true
+				}
+				depense.Exemplaires += reception.Quantite
+				depense.Montant += models.Montant(reception.Quantite) * ligne.PrixUnitaire
+			}
+		}
+	}
+
+	depenses := make([]DepenseFournisseur, 0, len(parFournisseur))
+	for _, depense := range parFournisseur {
+		depenses = append(depenses, *depense)
+	}
+	sort.Slice(depenses, func(i, j int) bool {
+		if depenses[i].Montant != depenses[j].Montant {
+			return depenses[i].Montant > depenses[j].Montant
+		}
+		return depenses[i].Nom < depenses[j].Nom
+	})
+	return depenses
+}
+
+// CoutsParEmprunt rapporte, pour chaque titre acheté sur commande, le prix de
+// ses exemplaires (retirés compris) à leurs emprunts. Les titres les plus chers
+// à l'emprunt viennent en premier, ceux jamais empruntés en tête.
+func (ga *GestionnaireAcquisitions) CoutsParEmprunt() []CoutEmprunt {
+	livres := append(ga.gestionnaireLivres.ListerLivres(), ga.gestionnaireLivres.ListerLivresRetires()...)
+
+	parTitre := make(map[string]*CoutEmprunt)
+	var ordre []string
+	for _, livre := range livres {
+		if livre.Acquisition == nil {
+			continue
+		}
+		cle := livre.ISBN
+		cout := parTitre[cle]
+		if cout == nil {
+			cout = &CoutEmprunt{Titre: livre.Titre, ISBN: livre.ISBN}
+			parTitre[cle] = cout
+			ordre = append(ordre, cle)
+		}
+		cout.Exemplaires++
+		cout.Cout += livre.Acquisition.Prix
+		cout.Emprunts += livre.NombreEmprunts
+	}
+
+	couts := make([]CoutEmprunt, 0, len(ordre))
+	for _, cle := range ordre {
+		cout := parTitre[cle]
+		cout.CoutParEmprunt = DiviserMontant(cout.Cout, cout.Emprunts)
+		couts = append(couts, *cout)
+	}
+	sort.SliceStable(couts, func(i, j int) bool {
+		if (couts[i].Emprunts == 0) != (couts[j].Emprunts == 0) {
+			return couts[i].Emprunts == 0
+		}
+		if couts[i].CoutParEmprunt != couts[j].CoutParEmprunt {
+			return couts[i].CoutParEmprunt > couts[j].CoutParEmprunt
+		}
+		return couts[i].Titre < couts[j].Titre
+	})
+	return couts
+}
+
+// DiviserMontant partage un montant en parts égales, arrondi au centime (0 si aucune part)
+func DiviserMontant(montant models.Montant, parts int) models.Montant {
+	if parts <= 0 {
+		return 0
+	}
+	return models.Montant(math.Round(float64(montant) / float64(parts)))
+}
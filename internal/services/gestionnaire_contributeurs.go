@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// AUTEURS ET CONTRIBUTEURS
+// Fiches de personnes avec variantes de nom et clé de tri
+// ========================================
+
+type GestionnaireContributeurs struct {
+	contributeurs []models.Contributeur
+	sequence      *storage.Sequence
+	stockage      storage.Storage
+}
+
+func (gc *GestionnaireContributeurs) sauvegarderContributeurs() error {
+	return gc.stockage.Sauvegarder(gc.contributeurs)
+}
+
+func (gc *GestionnaireContributeurs) ChargerContributeurs() error {
+	err := gc.stockage.Charger(&gc.contributeurs)
+	if err != nil {
+		return err
+	}
+
+	for _, contributeur := range gc.contributeurs {
+		gc.sequence.Observer(contributeur.ID)
+	}
+
+	return nil
+}
+
+func NouveauGestionnaireContributeurs(stockage storage.Storage, sequences *storage.Sequences) *GestionnaireContributeurs {
+	gc := &GestionnaireContributeurs{
+		contributeurs: make([]models.Contributeur, 0),
+		sequence:      sequences.Sequence("contributeurs"),
+		stockage:      stockage,
+	}
+
+	gc.ChargerContributeurs()
+	return gc
+}
+
+// AjouterContributeur crée une fiche. Si la clé de tri est vide, elle est déduite du nom.
+func (gc *GestionnaireContributeurs) AjouterContributeur(nom, cleTri string) (*models.Contributeur, error) {
+	nom = strings.TrimSpace(nom)
+	if !validators.ValiderNom(nom) {
+		return nil, fmt.Errorf("le nom du contributeur '%s' est invalide", nom)
+	}
+
+	if existant := gc.Resoudre(nom); existant != nil {
+		return nil, fmt.Errorf("le nom '%s' est déjà utilisé par le contributeur ID %d (%s)", nom, existant.ID, existant.Nom)
+	}
+
+	cleTri = strings.TrimSpace(cleTri)
+	if cleTri == "" {
+		cleTri = models.CleTriParDefaut(nom)
+	}
+
+	id, err := gc.sequence.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gc.contributeurs = append(gc.contributeurs, models.Contributeur{
+		ID:     id,
+		Nom:    nom,
+		CleTri: cleTri,
+	})
+
+	if err := gc.sauvegarderContributeurs(); err != nil {
+		return nil, err
+	}
+	return &gc.contributeurs[len(gc.contributeurs)-1], nil
+}
+
+// ObtenirOuCreer retrouve un contributeur par son nom ou une variante, ou le crée
+func (gc *GestionnaireContributeurs) ObtenirOuCreer(nom string) (*models.Contributeur, error) {
+	if existant := gc.Resoudre(nom); existant != nil {
+		return existant, nil
+	}
+	return gc.AjouterContributeur(nom, "")
+}
+
+// ListerContributeurs retourne les contributeurs triés par clé de tri
+func (gc *GestionnaireContributeurs) ListerContributeurs() []models.Contributeur {
+	tries := make([]models.Contributeur, len(gc.contributeurs))
+	copy(tries, gc.contributeurs)
+	sort.Slice(tries, func(i, j int) bool {
+		return strings.ToLower(tries[i].CleTri) < strings.ToLower(tries[j].CleTri)
+	})
+	return tries
+}
+
+func (gc *GestionnaireContributeurs) TrouverContributeurParID(id int) (*models.Contributeur, int) {
+	for i, contributeur := range gc.contributeurs {
+		if contributeur.ID == id {
+			return &gc.contributeurs[i], i
+		}
+	}
+	return nil, -1
+}
+
+// Resoudre retrouve un contributeur par son nom exact ou l'une de ses variantes
+func (gc *GestionnaireContributeurs) Resoudre(nom string) *models.Contributeur {
+	for i, contributeur := range gc.contributeurs {
+		if contributeur.Correspond(nom) {
+			return &gc.contributeurs[i]
+		}
+	}
+	return nil
+}
+
+// RechercherContributeurs cherche le terme dans les noms, clés de tri et variantes
+func (gc *GestionnaireContributeurs) RechercherContributeurs(terme string) []models.Contributeur {
+	var resultats []models.Contributeur
+	for _, contributeur := range gc.ListerContributeurs() {
+		if contributeur.Contient(terme) {
+			resultats = append(resultats, contributeur)
+		}
+	}
+	return resultats
+}
+
+func (gc *GestionnaireContributeurs) ModifierContributeur(id int, nouveauNom, nouvelleCleTri string) error {
+	contributeur, index := gc.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	if nouveauNom != "" {
+		nouveauNom = strings.TrimSpace(nouveauNom)
+		if !validators.ValiderNom(nouveauNom) {
+			return fmt.Errorf("le nouveau nom est invalide")
+		}
+		if existant := gc.Resoudre(nouveauNom); existant != nil && existant.ID != id {
+			return fmt.Errorf("le nom '%s' est déjà utilisé par le contributeur ID %d", nouveauNom, existant.ID)
+		}
+		// L'ancien nom reste connu comme variante pour la recherche
+		if !strings.EqualFold(contributeur.Nom, nouveauNom) {
+			contributeur.Variantes = append(contributeur.Variantes, contributeur.Nom)
+		}
+		contributeur.Variantes = retirerChaine(contributeur.Variantes, nouveauNom)
+		contributeur.Nom = nouveauNom
+	}
+
+	if nouvelleCleTri != "" {
+		contributeur.CleTri = strings.TrimSpace(nouvelleCleTri)
+	}
+
+	gc.contributeurs[index] = *contributeur
+	return gc.sauvegarderContributeurs()
+}
+
+func (gc *GestionnaireContributeurs) AjouterVariante(id int, variante string) error {
+	contributeur, index := gc.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	variante = strings.TrimSpace(variante)
+	if !validators.ValiderNom(variante) {
+		return fmt.Errorf("la variante '%s' est invalide", variante)
+	}
+
+	if existant := gc.Resoudre(variante); existant != nil {
+		return fmt.Errorf("'%s' désigne déjà le contributeur ID %d (%s)", variante, existant.ID, existant.Nom)
+	}
+
+	contributeur.Variantes = append(contributeur.Variantes, variante)
+	gc.contributeurs[index] = *contributeur
+	return gc.sauvegarderContributeurs()
+}
+
+func (gc *GestionnaireContributeurs) RetirerVariante(id int, variante string) error {
+	contributeur, index := gc.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	restantes := retirerChaine(contributeur.Variantes, variante)
+	if len(restantes) == len(contributeur.Variantes) {
+		return fmt.Errorf("le contributeur '%s' n'a pas de variante '%s'", contributeur.Nom, variante)
+	}
+
+	contributeur.Variantes = restantes
+	gc.contributeurs[index] = *contributeur
+	return gc.sauvegarderContributeurs()
+}
+
+// SupprimerContributeur supprime une fiche. La vérification des livres liés
+// est faite par GestionnaireLivres.SupprimerContributeur.
+func (gc *GestionnaireContributeurs) SupprimerContributeur(id int) error {
+	contributeur, index := gc.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	gc.contributeurs = append(gc.contributeurs[:index], gc.contributeurs[index+1:]...)
+	return gc.sauvegarderContributeurs()
+}
+
+// retirerChaine retourne la liste sans les éléments égaux à valeur (sans tenir compte de la casse)
+func retirerChaine(liste []string, valeur string) []string {
+	var resultat []string
+	for _, element := range liste {
+		if !strings.EqualFold(element, strings.TrimSpace(valeur)) {
+			resultat = append(resultat, element)
+		}
+	}
+	return resultat
+}
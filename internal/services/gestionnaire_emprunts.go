@@ -2,28 +2,32 @@ package services
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
 	"github.com/felver-dev/bookstore/internal/storage"
 )
 
 type GestionnaireEmprunts struct {
-	emprunts            []models.Emprunt
-	prochainID          int
-	stockage            storage.Storage
-	gestionnaireLivres  *GestionnaireLivres
-	gestionnaireMembres *GestionnaireMembres
-}
-
-type statMembre struct {
-	nom   string
-	count int
-}
-
-type statLivre struct {
-	titre string
-	count int
+	emprunts                 []models.Emprunt
+	sequence                 *storage.Sequence
+	stockage                 storage.Storage
+	journal                  *storage.Journal
+	instantanes              storage.Storage
+	evenements               []models.Evenement
+	dernierNumero            int
+	instance                 string // identifiant de ce poste, écrit dans ses événements
+	origine                  instantaneEmprunts
+	dernierInstantane        *instantaneEmprunts
+	projection               *projectionEmprunts
+	gestionnaireLivres       *GestionnaireLivres
+	gestionnaireMembres      *GestionnaireMembres
+	gestionnaireReservations *GestionnaireReservations
+	gestionnairePEB          *GestionnairePEB // nil si les prêts entre bibliothèques ne sont pas suivis
+	dureeEmpruntJours        int
+	prolongationsMax         int // prolongations permises à un membre en libre-service
 }
 
 func (ge *GestionnaireEmprunts) sauvegarderEmprunts() error {
@@ -36,28 +40,39 @@ func (ge *GestionnaireEmprunts) ChargerEmprunts() error {
 		return err
 	}
 
-	// Calculer le prochain ID
 	for _, emprunt := range ge.emprunts {
-		if emprunt.ID >= ge.prochainID {
-			ge.prochainID = emprunt.ID + 1
-		}
+		ge.sequence.Observer(emprunt.ID)
 	}
 
 	return nil
 }
 
-func NouveauGestionnaireEmprunts(stockage storage.Storage, gl *GestionnaireLivres, gm *GestionnaireMembres) *GestionnaireEmprunts {
+// NouveauGestionnaireEmprunts reconstruit les emprunts depuis le journal. Une
+// erreur de lecture est retournée : continuer sans projection écrirait dans le
+// journal des événements qui ne seraient jamais appliqués.
+func NouveauGestionnaireEmprunts(stockage storage.Storage, journal *storage.Journal, instantanes storage.Storage, sequences *storage.Sequences, gl *GestionnaireLivres, gm *GestionnaireMembres, gr *GestionnaireReservations, dureeEmpruntJours, prolongationsMax int) (*GestionnaireEmprunts, error) {
 	ge := &GestionnaireEmprunts{
-		emprunts:            make([]models.Emprunt, 0),
-		prochainID:          1,
-		stockage:            stockage,
-		gestionnaireLivres:  gl,
-		gestionnaireMembres: gm,
+		emprunts:                 make([]models.Emprunt, 0),
+		sequence:                 sequences.Sequence("emprunts"),
+		stockage:                 stockage,
+		journal:                  journal,
+		instantanes:              instantanes,
+		gestionnaireLivres:       gl,
+		gestionnaireMembres:      gm,
+		gestionnaireReservations: gr,
+		dureeEmpruntJours:        dureeEmpruntJours,
+		prolongationsMax:         prolongationsMax,
 	}
 
-	ge.ChargerEmprunts()
+	if err := ge.ChargerEmprunts(); err != nil {
+		return nil, err
+	}
+	// Les emprunts et les compteurs sont reconstruits depuis le journal
+	if err := ge.chargerJournal(); err != nil {
+		return nil, err
+	}
 	ge.mettreAJourStatutsEmprunts() // Vérifier les retards au démarrage
-	return ge
+	return ge, nil
 }
 
 func (ge *GestionnaireEmprunts) EmprunterLivre(livreID, membreID int) error {
@@ -69,6 +84,19 @@ func (ge *GestionnaireEmprunts) EmprunterLivre(livreID, membreID int) error {
 		return fmt.Errorf("livre ID %d introuvable", livreID)
 	}
 
+	if livre.EstRetire() {
+		return fmt.Errorf("le livre '%s' a été retiré du fonds (%s)", livre.Titre, livre.Retrait.String())
+	}
+
+	// En réseau, on n'emprunte que les livres présents dans la succursale du comptoir
+	succursales := ge.gestionnaireLivres.gestionnaireSuccursales
+	if livre.Disponible && livre.EstEnTransit() {
+		return fmt.Errorf("le livre '%s' est en transit vers %s", livre.Titre, succursales.NomSuccursale(livre.Transfert.Vers))
+	}
+	if ici := succursales.CouranteID(); ici != 0 && livre.Disponible && livre.EmplacementID != ici {
+		return fmt.Errorf("le livre '%s' se trouve à %s, pas dans cette succursale", livre.Titre, succursales.NomSuccursale(livre.EmplacementID))
+	}
+
 	if !livre.EstDisponible() {
 		return fmt.Errorf("le livre '%s' n'est pas disponible (actuellement emprunté)", livre.Titre)
 	}
@@ -79,12 +107,17 @@ func (ge *GestionnaireEmprunts) EmprunterLivre(livreID, membreID int) error {
 		return fmt.Errorf("membre ID %d introuvable", membreID)
 	}
 
-	if !membre.PeutEmprunter() {
+	if membre.EstRetire() {
+		return fmt.Errorf("le membre %s a été radié et ne peut pas emprunter", membre.Nom)
+	}
+
+	limite := ge.gestionnaireMembres.LimiteEmprunts()
+	if !membre.PeutEmprunter(limite) {
 		if !membre.Actif {
 			return fmt.Errorf("le membre %s est suspendu et ne peut pas emprunter", membre.Nom)
 		}
 		return fmt.Errorf("le membre %s a atteint la limite de %d emprunts simultanés",
-			membre.Nom, models.LIMIT_EMPRUNTS_SIMULTANES)
+			membre.Nom, limite)
 	}
 
 	// Vérifier que ce membre n'a pas déjà emprunté ce livre et ne l'a pas encore rendu
@@ -94,47 +127,59 @@ func (ge *GestionnaireEmprunts) EmprunterLivre(livreID, membreID int) error {
 		}
 	}
 
-	// 2. CRÉER L'EMPRUNT
+	// RÈGLE MÉTIER : un livre mis de côté ne peut être emprunté que par le membre qui l'a réservé
+	if reservation := ge.gestionnaireReservations.ReservationPrete(livreID); reservation != nil && reservation.MembreID != membreID {
+		return fmt.Errorf("le livre '%s' est mis de côté pour un autre membre jusqu'au %s",
+			livre.Titre, reservation.DateLimite.Format("02/01/2006"))
+	}
+
+	// RÈGLE MÉTIER : un livre prêté par une autre bibliothèque n'est prêté qu'au
+	// membre qui l'a demandé, et doit être rendu à la date fixée par le prêteur
 	maintenant := time.Now()
-	dateRetourPrevu := maintenant.AddDate(0, 0, models.DUREE_EMPRUMT_JOURS) // Ajouter 14 jours
-
-	nouvelEmprunt := models.Emprunt{
-		ID:                 ge.prochainID,
-		LivreID:            livreID,
-		MembreID:           membreID,
-		DateEmprunt:        maintenant,
-		DateRetourPrevu:    dateRetourPrevu,
-		DateRetourEffectif: nil, // Pas encore rendu
-		Statut:             models.STATUT_EN_COURS,
-
-		// Informations dénormalisées pour faciliter l'affichage
-		TitreLivre: livre.Titre,
-		NomMembre:  membre.Nom,
+	dateRetourPrevu := maintenant.AddDate(0, 0, ge.dureeEmpruntJours)
+	demandePEB := ge.demandePEB(livreID)
+	if demandePEB != nil {
+		echeance, err := ge.gestionnairePEB.echeanceEmprunt(demandePEB, membreID)
+		if err != nil {
+			return err
+		}
+		dateRetourPrevu = echeance
 	}
 
-	// 3. METTRE À JOUR LES ÉTATS
-	// Marquer le livre comme emprunté
-	if err := ge.gestionnaireLivres.MarquerCommeEmprunte(livreID); err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du livre : %v", err)
+	// 2. ENREGISTRER L'EMPRUNT DANS LE JOURNAL
+	// Le livre, le membre et la liste des emprunts sont mis à jour à partir de l'événement
+	id, err := ge.sequence.Attribuer()
+	if err != nil {
+		return err
 	}
 
-	// Mettre à jour les compteurs du membre
-	if err := ge.gestionnaireMembres.AjouterEmpruntAuMembre(membreID); err != nil {
-		// Si erreur, annuler la modification du livre
-		ge.gestionnaireLivres.MarquerCommeDisponible(livreID)
-		return fmt.Errorf("erreur lors de la mise à jour du membre : %v", err)
+	err = ge.enregistrer(models.Evenement{
+		Type:            models.EVENEMENT_LIVRE_EMPRUNTE,
+		Date:            maintenant,
+		EmpruntID:       id,
+		EmpruntUID:      models.NouvelUID(),
+		LivreID:         livreID,
+		MembreID:        membreID,
+		DateRetourPrevu: dateRetourPrevu,
+		SuccursaleID:    succursales.CouranteID(),
+		TitreLivre:      livre.Titre,
+		NomMembre:       membre.Nom,
+	})
+	if err != nil {
+		return err
 	}
 
-	// 4. ENREGISTRER L'EMPRUNT
-	ge.emprunts = append(ge.emprunts, nouvelEmprunt)
-	ge.prochainID++
+	if demandePEB != nil {
+		return ge.gestionnairePEB.livrePrete(demandePEB, id)
+	}
 
-	return ge.sauvegarderEmprunts()
+	// La réservation du membre pour ce livre, s'il en avait une, est honorée
+	return ge.gestionnaireReservations.honorer(livreID, membreID)
 }
 
 func (ge *GestionnaireEmprunts) RetournerLivre(empruntID int) error {
 	// 1. TROUVER L'EMPRUNT
-	emprunt, index := ge.TrouverEmpruntParID(empruntID)
+	emprunt, _ := ge.TrouverEmpruntParID(empruntID)
 	if emprunt == nil {
 		return fmt.Errorf("emprunt ID %d introuvable", empruntID)
 	}
@@ -145,23 +190,106 @@ func (ge *GestionnaireEmprunts) RetournerLivre(empruntID int) error {
 			emprunt.DateRetourEffectif.Format("02/01/2006"))
 	}
 
-	// 2. METTRE À JOUR L'EMPRUNT
-	emprunt.MarquerCommeRendu()
+	// 2. ENREGISTRER LE RETOUR DANS LE JOURNAL
+	ici := ge.gestionnaireLivres.gestionnaireSuccursales.CouranteID()
+	err := ge.enregistrer(models.Evenement{
+		Type:         models.EVENEMENT_LIVRE_RENDU,
+		EmpruntID:    emprunt.ID,
+		LivreID:      emprunt.LivreID,
+		MembreID:     emprunt.MembreID,
+		SuccursaleID: ici,
+		TitreLivre:   emprunt.TitreLivre,
+		NomMembre:    emprunt.NomMembre,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3. LE LIVRE EST DÉSORMAIS DANS LA SUCCURSALE OÙ IL A ÉTÉ RENDU
+	if livre, index := ge.gestionnaireLivres.TrouverLivreParID(emprunt.LivreID); livre != nil && ici != 0 {
+		ge.gestionnaireLivres.livres[index].EmplacementID = ici
+		if err := ge.gestionnaireLivres.sauvegarderLivres(); err != nil {
+			return err
+		}
+	}
+
+	// Un livre prêté par une autre bibliothèque attend d'être renvoyé
+	if demande := ge.demandePEB(emprunt.LivreID); demande != nil {
+		return ge.gestionnairePEB.livreRendu(demande)
+	}
+
+	// 4. METTRE LE LIVRE DE CÔTÉ POUR LE PREMIER MEMBRE QUI L'A RÉSERVÉ,
+	// OU L'ACHEMINER VERS LA SUCCURSALE OÙ IL EST ATTENDU
+	_, err = ge.gestionnaireReservations.livreDisponible(emprunt.LivreID)
+	return err
+}
+
+// ========================================
+// PRÊT ET RETOUR PAR SCAN
+// ========================================
+
+// EmprunterParCode enregistre un emprunt à partir des codes scannés au comptoir
+// (code-barres ou ID du livre, carte ou ID du membre) et retourne l'emprunt créé
+func (ge *GestionnaireEmprunts) EmprunterParCode(codeLivre, carteMembre string) (models.Emprunt, error) {
+	livre, _ := ge.gestionnaireLivres.TrouverLivreParCode(codeLivre)
+	if livre == nil {
+		return models.Emprunt{}, fmt.Errorf("aucun livre ne correspond au code '%s'", models.NormaliserCode(codeLivre))
+	}
+
+	membre, _ := ge.gestionnaireMembres.TrouverMembreParCarte(carteMembre)
+	if membre == nil {
+		return models.Emprunt{}, fmt.Errorf("aucun membre ne correspond à la carte '%s'", models.NormaliserCode(carteMembre))
+	}
+
+	if err := ge.EmprunterLivre(livre.ID, membre.ID); err != nil {
+		return models.Emprunt{}, err
+	}
+
+	emprunt, _ := ge.TrouverEmpruntActifParLivre(livre.ID)
+	return *emprunt, nil
+}
+
+// RetournerParCode enregistre le retour du livre scanné, sans avoir à chercher l'emprunt
+func (ge *GestionnaireEmprunts) RetournerParCode(codeLivre string) (models.Emprunt, error) {
+	livre, _ := ge.gestionnaireLivres.TrouverLivreParCode(codeLivre)
+	if livre == nil {
+		return models.Emprunt{}, fmt.Errorf("aucun livre ne correspond au code '%s'", models.NormaliserCode(codeLivre))
+	}
 
-	// 3. METTRE À JOUR LES ÉTATS
-	// Marquer le livre comme disponible
-	if err := ge.gestionnaireLivres.MarquerCommeDisponible(emprunt.LivreID); err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du livre : %v", err)
+	emprunt, _ := ge.TrouverEmpruntActifParLivre(livre.ID)
+	if emprunt == nil {
+		return models.Emprunt{}, fmt.Errorf("le livre '%s' n'est pas emprunté", livre.Titre)
 	}
 
-	// Mettre à jour les compteurs du membre
-	if err := ge.gestionnaireMembres.RetirerEmpruntDuMembre(emprunt.MembreID); err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du membre : %v", err)
+	empruntID := emprunt.ID
+	if err := ge.RetournerLivre(empruntID); err != nil {
+		return models.Emprunt{}, err
+	}
+
+	emprunt, _ = ge.TrouverEmpruntParID(empruntID)
+	return *emprunt, nil
+}
+
+// ========================================
+// RÉSERVATIONS
+// ========================================
+
+// ReserverLivre place le membre dans la file d'attente d'un livre.
+// Réserver un livre que le membre a déjà entre les mains n'a pas de sens.
+func (ge *GestionnaireEmprunts) ReserverLivre(livreID, membreID int) (models.Reservation, error) {
+	if livre, _ := ge.gestionnaireLivres.TrouverLivreParID(livreID); livre != nil && livre.EstPEB() {
+		return models.Reservation{}, fmt.Errorf("'%s' est prêté par une autre bibliothèque pour un membre, il ne peut pas être réservé", livre.Titre)
+	}
+	if emprunt, _ := ge.TrouverEmpruntActifParLivre(livreID); emprunt != nil && emprunt.MembreID == membreID {
+		return models.Reservation{}, fmt.Errorf("%s a déjà emprunté '%s'", emprunt.NomMembre, emprunt.TitreLivre)
 	}
+	return ge.gestionnaireReservations.reserver(livreID, membreID)
+}
 
-	// 4. SAUVEGARDER
-	ge.emprunts[index] = *emprunt
-	return ge.sauvegarderEmprunts()
+// MiseDeCote retourne la réservation pour laquelle un livre rendu doit être mis
+// de côté à l'accueil (nil si personne ne l'attend)
+func (ge *GestionnaireEmprunts) MiseDeCote(livreID int) *models.Reservation {
+	return ge.gestionnaireReservations.ReservationPrete(livreID)
 }
 
 func (ge *GestionnaireEmprunts) ListerEmprunts() []models.Emprunt {
@@ -275,7 +403,7 @@ func (ge *GestionnaireEmprunts) CalculerDureeEmpruntsTermines() float64 {
 	var count int
 
 	for _, emprunt := range ge.emprunts {
-		if emprunt.DateRetourEffectif != nil { // Emprunt terminé
+		if emprunt.EstRendu() { // Emprunt terminé par un retour
 			duree := emprunt.DateRetourEffectif.Sub(emprunt.DateEmprunt)
 			totalJours += int(duree.Hours() / 24)
 			count++
@@ -290,7 +418,7 @@ func (ge *GestionnaireEmprunts) CalculerDureeEmpruntsTermines() float64 {
 }
 
 func (ge *GestionnaireEmprunts) PrologerEmprunt(empruntID int, joursSupplementaires int) error {
-	emprunt, index := ge.TrouverEmpruntParID(empruntID)
+	emprunt, _ := ge.TrouverEmpruntParID(empruntID)
 	if emprunt == nil {
 		return fmt.Errorf("emprunt ID %d introuvable", empruntID)
 	}
@@ -300,18 +428,83 @@ func (ge *GestionnaireEmprunts) PrologerEmprunt(empruntID int, joursSupplementai
 		return fmt.Errorf("impossible de prolonger un emprunt déjà terminé")
 	}
 
+	// RÈGLE MÉTIER : un livre prêté par une autre bibliothèque lui est rendu à la date qu'elle a fixée
+	dateRetourPrevu := emprunt.DateRetourPrevu.AddDate(0, 0, joursSupplementaires)
+	if demande := ge.demandePEB(emprunt.LivreID); demande != nil && dateRetourPrevu.After(demande.DateRetourPreteur) {
+		return fmt.Errorf("'%s' doit être renvoyé à %s le %s : l'emprunt ne peut pas aller au-delà",
+			emprunt.TitreLivre, demande.Preteur, demande.DateRetourPreteur.Format("02/01/2006"))
+	}
+
 	// Prolonger la date de retour
-	emprunt.DateRetourPrevu = emprunt.DateRetourPrevu.AddDate(0, 0, joursSupplementaires)
+	err := ge.enregistrer(models.Evenement{
+		Type:            models.EVENEMENT_EMPRUNT_PROLONGE,
+		EmpruntID:       emprunt.ID,
+		LivreID:         emprunt.LivreID,
+		MembreID:        emprunt.MembreID,
+		DateRetourPrevu: dateRetourPrevu,
+		TitreLivre:      emprunt.TitreLivre,
+		NomMembre:       emprunt.NomMembre,
+	})
+	if err != nil {
+		return err
+	}
 
 	// Mettre à jour le statut si nécessaire
-	emprunt.MettreAjourStatut()
+	ge.mettreAJourStatutsEmprunts()
+	return nil
+}
+
+// VerifierProlongation indique pourquoi un membre ne peut pas prolonger lui-même
+// un emprunt (nil s'il le peut). Le personnel n'est pas soumis à ces règles.
+func (ge *GestionnaireEmprunts) VerifierProlongation(emprunt models.Emprunt) error {
+	if emprunt.DateRetourEffectif != nil {
+		return fmt.Errorf("cet emprunt est déjà terminé")
+	}
+
+	// RÈGLE MÉTIER : la prolongation d'un prêt entre bibliothèques se négocie avec le prêteur
+	if ge.demandePEB(emprunt.LivreID) != nil {
+		return fmt.Errorf("'%s' est prêté par une autre bibliothèque, adressez-vous à l'accueil pour le prolonger", emprunt.TitreLivre)
+	}
+
+	// RÈGLE MÉTIER : un emprunt en retard doit être régularisé à l'accueil
+	if emprunt.EstEnRetard() {
+		return fmt.Errorf("l'emprunt de '%s' est en retard, adressez-vous à l'accueil", emprunt.TitreLivre)
+	}
+
+	// RÈGLE MÉTIER : on ne prolonge pas un livre que d'autres membres attendent
+	if ge.gestionnaireReservations.MembresEnAttente(emprunt.LivreID, emprunt.MembreID) > 0 {
+		return fmt.Errorf("d'autres membres ont réservé '%s', il ne peut pas être prolongé", emprunt.TitreLivre)
+	}
+
+	if emprunt.Prolongations >= ge.prolongationsMax {
+		return fmt.Errorf("l'emprunt de '%s' a déjà été prolongé %d fois (maximum %d)", emprunt.TitreLivre, emprunt.Prolongations, ge.prolongationsMax)
+	}
+
+	return nil
+}
+
+// ProlongerEmpruntMembre prolonge, à la demande du membre lui-même, un de ses emprunts
+// d'une durée d'emprunt complète, dans la limite des prolongations permises
+func (ge *GestionnaireEmprunts) ProlongerEmpruntMembre(empruntID, membreID int) (models.Emprunt, error) {
+	emprunt, _ := ge.TrouverEmpruntParID(empruntID)
+	if emprunt == nil || emprunt.MembreID != membreID {
+		return models.Emprunt{}, fmt.Errorf("emprunt ID %d introuvable", empruntID)
+	}
 
-	ge.emprunts[index] = *emprunt
-	return ge.sauvegarderEmprunts()
+	if err := ge.VerifierProlongation(*emprunt); err != nil {
+		return models.Emprunt{}, err
+	}
+
+	if err := ge.PrologerEmprunt(empruntID, ge.dureeEmpruntJours); err != nil {
+		return models.Emprunt{}, err
+	}
+
+	emprunt, _ = ge.TrouverEmpruntParID(empruntID)
+	return *emprunt, nil
 }
 
 func (ge *GestionnaireEmprunts) AnnulerEmprunt(empruntID int) error {
-	emprunt, index := ge.TrouverEmpruntParID(empruntID)
+	emprunt, _ := ge.TrouverEmpruntParID(empruntID)
 	if emprunt == nil {
 		return fmt.Errorf("emprunt ID %d introuvable", empruntID)
 	}
@@ -321,119 +514,274 @@ func (ge *GestionnaireEmprunts) AnnulerEmprunt(empruntID int) error {
 		return fmt.Errorf("impossible d'annuler un emprunt déjà terminé")
 	}
 
-	// Remettre le livre disponible
-	if err := ge.gestionnaireLivres.MarquerCommeDisponible(emprunt.LivreID); err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du livre : %v", err)
+	// L'emprunt quitte la liste et ne compte plus dans l'historique du livre ni
+	// du membre ; le journal, lui, garde la trace de l'emprunt et de son annulation
+	err := ge.enregistrer(models.Evenement{
+		Type:       models.EVENEMENT_EMPRUNT_ANNULE,
+		EmpruntID:  emprunt.ID,
+		LivreID:    emprunt.LivreID,
+		MembreID:   emprunt.MembreID,
+		TitreLivre: emprunt.TitreLivre,
+		NomMembre:  emprunt.NomMembre,
+	})
+	if err != nil {
+		return err
+	}
+
+	if demande := ge.demandePEB(emprunt.LivreID); demande != nil {
+		return ge.gestionnairePEB.empruntAnnule(demande)
 	}
 
-	// Retirer l'emprunt du membre
-	if err := ge.gestionnaireMembres.RetirerEmpruntDuMembre(emprunt.MembreID); err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du membre : %v", err)
+	_, err = ge.gestionnaireReservations.livreDisponible(emprunt.LivreID)
+	return err
+}
+
+// RetirerLivre sort un livre du fonds. Un livre emprunté ne peut qu'être
+// déclaré perdu : son emprunt est alors clos sans retour.
+func (ge *GestionnaireEmprunts) RetirerLivre(id int, motif, raison string) error {
+	emprunt, _ := ge.TrouverEmpruntActifParLivre(id)
+	if emprunt == nil || motif != models.MOTIF_PERDU {
+		return ge.gestionnaireLivres.RetirerLivre(id, motif, raison)
+	}
+
+	if livre, _ := ge.gestionnaireLivres.TrouverLivreParID(id); livre != nil && livre.EstPEB() {
+		return fmt.Errorf("le livre '%s' appartient à une autre bibliothèque : sa perte se règle avec le prêteur", livre.Titre)
+	}
+	if err := ge.gestionnaireLivres.retirer(id, motif, raison, true); err != nil {
+		return err
+	}
+	return ge.cloreEmpruntPerdu(*emprunt)
+}
+
+// RestaurerLivre remet au catalogue un livre retiré. La restauration est écrite
+// dans le journal : un livre perdu pendant un emprunt y redevient disponible.
+func (ge *GestionnaireEmprunts) RestaurerLivre(id int) error {
+	if err := ge.gestionnaireLivres.verifierRestauration(id); err != nil {
+		return err
+	}
+
+	livre, _ := ge.gestionnaireLivres.TrouverLivreParID(id)
+	err := ge.enregistrer(models.Evenement{
+		Type:       models.EVENEMENT_LIVRE_RESTAURE,
+		LivreID:    livre.ID,
+		TitreLivre: livre.Titre,
+	})
+	if err != nil {
+		return err
+	}
+	return ge.gestionnaireLivres.restaurer(id)
+}
+
+// cloreEmpruntPerdu termine l'emprunt d'un livre déclaré perdu. Le livre ne
+// revient pas : il n'est ni remis en rayon ni proposé aux réservations.
+func (ge *GestionnaireEmprunts) cloreEmpruntPerdu(emprunt models.Emprunt) error {
+	return ge.enregistrer(models.Evenement{
+		Type:       models.EVENEMENT_LIVRE_PERDU,
+		EmpruntID:  emprunt.ID,
+		LivreID:    emprunt.LivreID,
+		MembreID:   emprunt.MembreID,
+		TitreLivre: emprunt.TitreLivre,
+		NomMembre:  emprunt.NomMembre,
+	})
+}
+
+// demandePEB retourne la demande de prêt entre bibliothèques du livre, s'il
+// vient d'une autre bibliothèque et que ces prêts sont suivis (nil sinon)
+func (ge *GestionnaireEmprunts) demandePEB(livreID int) *models.DemandePEB {
+	livre, _ := ge.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil || !livre.EstPEB() || ge.gestionnairePEB == nil {
+		return nil
+	}
+	demande, _ := ge.gestionnairePEB.TrouverDemandeParID(livre.PEBID)
+	return demande
+}
+
+// SuspendreMembre bloque les emprunts d'un membre. La suspension passe par le
+// journal des emprunts, qui fait foi pour l'état actif ou suspendu des membres.
+func (ge *GestionnaireEmprunts) SuspendreMembre(id int) error {
+	membre, _ := ge.gestionnaireMembres.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	// Supprimer l'emprunt de la liste
-	ge.emprunts = append(ge.emprunts[:index], ge.emprunts[index+1:]...)
+	if !membre.Actif {
+		return fmt.Errorf("le membre %s est déjà suspendu", membre.Nom)
+	}
+
+	return ge.enregistrer(models.Evenement{
+		Type:      models.EVENEMENT_MEMBRE_SUSPENDU,
+		MembreID:  membre.ID,
+		NomMembre: membre.Nom,
+	})
+}
+
+func (ge *GestionnaireEmprunts) ReactiverMembre(id int) error {
+	membre, _ := ge.gestionnaireMembres.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
+	}
+
+	if membre.Actif {
+		return fmt.Errorf("le membre %s est déjà actif", membre.Nom)
+	}
 
-	return ge.sauvegarderEmprunts()
+	return ge.enregistrer(models.Evenement{
+		Type:      models.EVENEMENT_MEMBRE_REACTIVE,
+		MembreID:  membre.ID,
+		NomMembre: membre.Nom,
+	})
 }
 
-func (ge *GestionnaireEmprunts) ObtenirStatistiques() map[string]interface{} {
-	stats := make(map[string]interface{})
+// TOP_EMPRUNTS limite les palmarès de livres et de membres des statistiques
+const TOP_EMPRUNTS = 10
 
+// ObtenirStatistiques décrit l'activité de la période : emprunts commencés, retours,
+// retards à la fin de la période, palmarès et répartition par mois et par genre.
+// Avec une succursale (ID non nul), seuls comptent les emprunts faits à son
+// comptoir, et les retours qu'elle a reçus.
+func (ge *GestionnaireEmprunts) ObtenirStatistiques(periode statistiques.Periode, succursaleID int) statistiques.Emprunts {
 	// Mettre à jour les statuts avant de calculer les stats
 	ge.mettreAJourStatutsEmprunts()
 
-	total := len(ge.emprunts)
-	stats["total"] = total
+	stats := statistiques.Emprunts{Periode: periode, SuccursaleID: succursaleID, Retards: []statistiques.Retard{}}
 
-	if total == 0 {
-		return stats
+	// Les retards s'apprécient à la fin de la période, sans dépasser aujourd'hui
+	arrete := time.Now()
+	if !periode.Fin.IsZero() && periode.Fin.Before(arrete) {
+		arrete = periode.Fin
 	}
 
-	// Compter par statut
-	enCours := 0
-	rendus := 0
-	enRetard := 0
+	parGenre := make(map[string]int)
+	parLivre := make(map[int]statistiques.Classement)
+	parMembre := make(map[int]statistiques.Classement)
+	var dates []time.Time
+	totalJours := 0
 
 	for _, emprunt := range ge.emprunts {
-		switch emprunt.Statut {
-		case models.STATUT_EN_COURS:
-			enCours++
-		case models.STATUT_RENDU:
-			rendus++
-		case models.STATUT_EN_RETARD:
-			enRetard++
-		}
-	}
+		empruntIci := succursaleID == 0 || emprunt.SuccursaleID == succursaleID
+		renduIci := succursaleID == 0 || emprunt.SuccursaleRetour == succursaleID
+
+		if empruntIci && periode.Contient(emprunt.DateEmprunt) {
+			stats.Total++
+			switch emprunt.Statut {
+			case models.STATUT_EN_COURS:
+				stats.EnCours++
+			case models.STATUT_RENDU:
+				stats.Rendus++
+			case models.STATUT_EN_RETARD:
+				stats.EnRetard++
+			case models.STATUT_PERDU:
+				stats.Perdus++
+			}
+			dates = append(dates, emprunt.DateEmprunt)
 
-	stats["en_cours"] = enCours
-	stats["rendus"] = rendus
-	stats["en_retard"] = enRetard
+			genre := "Non classé"
+			if livre, _ := ge.gestionnaireLivres.TrouverLivreParID(emprunt.LivreID); livre != nil && livre.Genre != "" {
+				genre = livre.Genre
+			}
+			parGenre[genre]++
 
-	// Durée moyenne des emprunts terminés
-	dureeM := ge.CalculerDureeEmpruntsTermines()
-	stats["duree_moyenne_jours"] = dureeM
+			livre := parLivre[emprunt.LivreID]
+			parLivre[emprunt.LivreID] = statistiques.Classement{ID: emprunt.LivreID, Nom: emprunt.TitreLivre, Emprunts: livre.Emprunts + 1}
+			membre := parMembre[emprunt.MembreID]
+			parMembre[emprunt.MembreID] = statistiques.Classement{ID: emprunt.MembreID, Nom: emprunt.NomMembre, Emprunts: membre.Emprunts + 1}
+		}
 
-	// Emprunts par mois (12 derniers mois)
-	empruntsParMois := ge.calculerEmpruntsParMois()
-	stats["emprunts_par_mois"] = empruntsParMois
+		// Durée des emprunts terminés pendant la période ; un livre perdu n'est pas un retour
+		if retour := emprunt.DateRetourEffectif; emprunt.EstRendu() && renduIci && periode.Contient(*retour) {
+			stats.Retours++
+			if retour.After(emprunt.DateRetourPrevu) {
+				stats.RetoursEnRetard++
+			}
+			totalJours += int(retour.Sub(emprunt.DateEmprunt).Hours() / 24)
+		}
 
-	// Membre le plus actif (le plus d'emprunts)
-	membrePlusActif := ge.trouverMembrePlusActif()
-	if membrePlusActif != nil {
-		stats["membre_plus_actif"] = map[string]interface{}{
-			"nom":      membrePlusActif.nom,
-			"emprunts": membrePlusActif.count,
+		rendu := emprunt.DateRetourEffectif != nil && emprunt.DateRetourEffectif.Before(arrete)
+		if empruntIci && !rendu && emprunt.DateEmprunt.Before(arrete) && emprunt.DateRetourPrevu.Before(arrete) {
+			stats.Retards = append(stats.Retards, statistiques.Retard{
+				EmpruntID:       emprunt.ID,
+				Titre:           emprunt.TitreLivre,
+				Membre:          emprunt.NomMembre,
+				DateRetourPrevu: emprunt.DateRetourPrevu,
+				Jours:           int(arrete.Sub(emprunt.DateRetourPrevu).Hours() / 24),
+				RenduDepuis:     emprunt.DateRetourEffectif != nil,
+			})
 		}
 	}
 
-	// Livre le plus emprunté
-	livrePlusEmprunte := ge.trouverLivrePlusEmprunte()
-	if livrePlusEmprunte != nil {
-		stats["livre_plus_emprunte"] = map[string]interface{}{
-			"titre":    livrePlusEmprunte.titre,
-			"emprunts": livrePlusEmprunte.count,
-		}
+	if stats.Retours > 0 {
+		stats.DureeMoyenneJours = float64(totalJours) / float64(stats.Retours)
 	}
+	stats.MembresEmprunteurs = len(parMembre)
+	stats.LivresDifferents = len(parLivre)
+
+	// Les plus gros retards d'abord
+	sort.SliceStable(stats.Retards, func(i, j int) bool {
+		return stats.Retards[i].Jours > stats.Retards[j].Jours
+	})
+
+	stats.ParMois = statistiques.SerieMensuelle(periode, dates)
+	stats.ParGenre = statistiques.Repartir(parGenre)
+	stats.TopLivres = statistiques.Classer(parLivre, TOP_EMPRUNTS)
+	stats.TopMembres = statistiques.Classer(parMembre, TOP_EMPRUNTS)
 
 	return stats
 }
+
+// EmpruntsParMois compte les emprunts commencés chaque mois de la période, dans l'ordre chronologique
+func (ge *GestionnaireEmprunts) EmpruntsParMois(periode statistiques.Periode) []statistiques.Point {
+	dates := make([]time.Time, 0, len(ge.emprunts))
+	for _, emprunt := range ge.emprunts {
+		dates = append(dates, emprunt.DateEmprunt)
+	}
+	return statistiques.SerieMensuelle(periode, dates)
+}
+
+// NettoierEmpruntsAnciens retire de la liste les emprunts terminés depuis plus
+// de ageMaxAnnees ; ils restent dans le journal et dans les compteurs
 func (ge *GestionnaireEmprunts) NettoierEmpruntsAnciens(ageMaxAnnees int) error {
 	dateLimit := time.Now().AddDate(-ageMaxAnnees, 0, 0)
-	var empruntsAGarder []models.Emprunt
-	supprimesCount := 0
 
 	for _, emprunt := range ge.emprunts {
-		// Garder l'emprunt s'il est récent OU s'il n'est pas encore terminé
-		if emprunt.DateEmprunt.After(dateLimit) || emprunt.DateRetourEffectif == nil {
-			empruntsAGarder = append(empruntsAGarder, emprunt)
-		} else {
-			supprimesCount++
+		// Un emprunt est retiré s'il est ancien ET terminé
+		if !emprunt.DateEmprunt.After(dateLimit) && emprunt.DateRetourEffectif != nil {
+			err := ge.enregistrer(models.Evenement{Type: models.EVENEMENT_HISTORIQUE_NETTOYE, DateLimite: dateLimit})
+			if err != nil {
+				return fmt.Errorf("erreur lors de la sauvegarde après nettoyage : %v", err)
+			}
+			return nil
 		}
 	}
 
-	if supprimesCount > 0 {
-		ge.emprunts = empruntsAGarder
-		err := ge.sauvegarderEmprunts()
-		if err != nil {
-			return fmt.Errorf("erreur lors de la sauvegarde après nettoyage : %v", err)
+	return nil
+}
+
+// DernierNettoyage retourne la date limite du dernier nettoyage de l'historique,
+// zéro si l'historique n'a jamais été nettoyé
+func (ge *GestionnaireEmprunts) DernierNettoyage() time.Time {
+	var limite time.Time
+	for _, evenement := range ge.evenements {
+		if evenement.Type == models.EVENEMENT_HISTORIQUE_NETTOYE && evenement.DateLimite.After(limite) {
+			limite = evenement.DateLimite
 		}
 	}
-
-	return nil
+	return limite
 }
 
 func (ge *GestionnaireEmprunts) ExporterRapportEmprunts() string {
-	stats := ge.ObtenirStatistiques()
+	stats := ge.ObtenirStatistiques(statistiques.Periode{}, 0)
 	rapport := "=== RAPPORT DES EMPRUNTS ===\n\n"
 
-	rapport += fmt.Sprintf("Total des emprunts : %d\n", stats["total"])
-	rapport += fmt.Sprintf("En cours : %d\n", stats["en_cours"])
-	rapport += fmt.Sprintf("Rendus : %d\n", stats["rendus"])
-	rapport += fmt.Sprintf("En retard : %d\n", stats["en_retard"])
+	rapport += fmt.Sprintf("Total des emprunts : %d\n", stats.Total)
+	rapport += fmt.Sprintf("En cours : %d\n", stats.EnCours)
+	rapport += fmt.Sprintf("Rendus : %d\n", stats.Rendus)
+	rapport += fmt.Sprintf("En retard : %d\n", stats.EnRetard)
+	if stats.Perdus > 0 {
+		rapport += fmt.Sprintf("Perdus : %d\n", stats.Perdus)
+	}
 
-	if duree, ok := stats["duree_moyenne_jours"].(float64); ok && duree > 0 {
-		rapport += fmt.Sprintf("Durée moyenne : %.1f jours\n", duree)
+	if stats.DureeMoyenneJours > 0 {
+		rapport += fmt.Sprintf("Durée moyenne : %.1f jours\n", stats.DureeMoyenneJours)
 	}
 
 	rapport += "\n=== EMPRUNTS EN RETARD ===\n"
@@ -451,81 +799,6 @@ func (ge *GestionnaireEmprunts) ExporterRapportEmprunts() string {
 	return rapport
 }
 
-func (ge *GestionnaireEmprunts) calculerEmpruntsParMois() map[string]int {
-	empruntsParMois := make(map[string]int)
-	maintenant := time.Now()
-
-	// Initialiser les 12 derniers mois à 0
-	for i := 11; i >= 0; i-- {
-		mois := maintenant.AddDate(0, -i, 0).Format("2006-01")
-		empruntsParMois[mois] = 0
-	}
-
-	// Compter les emprunts par mois
-	for _, emprunt := range ge.emprunts {
-		mois := emprunt.DateEmprunt.Format("2006-01")
-		if _, existe := empruntsParMois[mois]; existe {
-			empruntsParMois[mois]++
-		}
-	}
-
-	return empruntsParMois
-}
-
-func (ge *GestionnaireEmprunts) trouverMembrePlusActif() *statMembre {
-	compteurMembres := make(map[int]int)
-	nomsMembres := make(map[int]string)
-
-	// Compter les emprunts par membre
-	for _, emprunt := range ge.emprunts {
-		compteurMembres[emprunt.MembreID]++
-		nomsMembres[emprunt.MembreID] = emprunt.NomMembre
-	}
-
-	// Trouver le maximum
-	var membrePlusActif *statMembre
-	maxEmprunts := 0
-
-	for membreID, count := range compteurMembres {
-		if count > maxEmprunts {
-			maxEmprunts = count
-			membrePlusActif = &statMembre{
-				nom:   nomsMembres[membreID],
-				count: count,
-			}
-		}
-	}
-
-	return membrePlusActif
-}
-
-func (ge *GestionnaireEmprunts) trouverLivrePlusEmprunte() *statLivre {
-	compteurLivres := make(map[int]int)
-	titresLivres := make(map[int]string)
-
-	// Compter les emprunts par livre
-	for _, emprunt := range ge.emprunts {
-		compteurLivres[emprunt.LivreID]++
-		titresLivres[emprunt.LivreID] = emprunt.TitreLivre
-	}
-
-	// Trouver le maximum
-	var livrePlusEmprunte *statLivre
-	maxEmprunts := 0
-
-	for livreID, count := range compteurLivres {
-		if count > maxEmprunts {
-			maxEmprunts = count
-			livrePlusEmprunte = &statLivre{
-				titre: titresLivres[livreID],
-				count: count,
-			}
-		}
-	}
-
-	return livrePlusEmprunte
-}
-
 func (ge *GestionnaireEmprunts) mettreAJourStatutsEmprunts() {
 	modifie := false
 
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// TAXONOMIE DES GENRES
+// Hiérarchie persistée de genres avec noms canoniques et alias
+// ========================================
+
+type GestionnaireGenres struct {
+	genres   []models.Genre
+	sequence *storage.Sequence
+	stockage storage.Storage
+}
+
+func (gg *GestionnaireGenres) sauvegarderGenres() error {
+	return gg.stockage.Sauvegarder(gg.genres)
+}
+
+func (gg *GestionnaireGenres) ChargerGenres() error {
+	err := gg.stockage.Charger(&gg.genres)
+	if err != nil {
+		return err
+	}
+
+	for _, genre := range gg.genres {
+		gg.sequence.Observer(genre.ID)
+	}
+
+	return nil
+}
+
+// NouveauGestionnaireGenres charge la taxonomie. Si elle est vide, elle est
+// initialisée avec les genres de la configuration (tous à la racine).
+func NouveauGestionnaireGenres(stockage storage.Storage, sequences *storage.Sequences, genresInitiaux []string) *GestionnaireGenres {
+	gg := &GestionnaireGenres{
+		genres:   make([]models.Genre, 0),
+		sequence: sequences.Sequence("genres"),
+		stockage: stockage,
+	}
+
+	gg.ChargerGenres()
+
+	if len(gg.genres) == 0 && len(genresInitiaux) > 0 {
+		for _, nom := range genresInitiaux {
+			id := gg.sequence.Prochain()
+			gg.sequence.Observer(id)
+			gg.genres = append(gg.genres, models.Genre{ID: id, Nom: strings.TrimSpace(nom)})
+		}
+		sequences.Sauvegarder()
+		gg.sauvegarderGenres()
+	}
+
+	return gg
+}
+
+func (gg *GestionnaireGenres) AjouterGenre(nom string, parentID int) (*models.Genre, error) {
+	nom = strings.TrimSpace(nom)
+	if !validators.ValiderTitre(nom) {
+		return nil, fmt.Errorf("le nom du genre est invalide")
+	}
+
+	if parentID != 0 {
+		if parent, _ := gg.TrouverGenreParID(parentID); parent == nil {
+			return nil, fmt.Errorf("aucun genre parent trouvé avec l'ID %d", parentID)
+		}
+	}
+
+	if existant := gg.Resoudre(nom); existant != nil {
+		return nil, fmt.Errorf("le nom '%s' est déjà utilisé par le genre ID %d (%s)", nom, existant.ID, existant.Nom)
+	}
+
+	id, err := gg.sequence.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gg.genres = append(gg.genres, models.Genre{
+		ID:       id,
+		Nom:      nom,
+		ParentID: parentID,
+	})
+
+	if err := gg.sauvegarderGenres(); err != nil {
+		return nil, err
+	}
+	return &gg.genres[len(gg.genres)-1], nil
+}
+
+func (gg *GestionnaireGenres) ListerGenres() []models.Genre {
+	return gg.genres
+}
+
+func (gg *GestionnaireGenres) TrouverGenreParID(id int) (*models.Genre, int) {
+	for i, genre := range gg.genres {
+		if genre.ID == id {
+			return &gg.genres[i], i
+		}
+	}
+	return nil, -1
+}
+
+// Resoudre retrouve un genre à partir de son nom canonique ou d'un de ses alias
+func (gg *GestionnaireGenres) Resoudre(terme string) *models.Genre {
+	for i, genre := range gg.genres {
+		if genre.Correspond(terme) {
+			return &gg.genres[i]
+		}
+	}
+	return nil
+}
+
+// ModifierGenre renomme et/ou déplace un genre. Un parentID négatif conserve le parent actuel.
+func (gg *GestionnaireGenres) ModifierGenre(id int, nouveauNom string, nouveauParentID int) error {
+	genre, index := gg.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	if nouveauNom != "" {
+		nouveauNom = strings.TrimSpace(nouveauNom)
+		if !validators.ValiderTitre(nouveauNom) {
+			return fmt.Errorf("le nouveau nom du genre est invalide")
+		}
+		if existant := gg.Resoudre(nouveauNom); existant != nil && existant.ID != id {
+			return fmt.Errorf("le nom '%s' est déjà utilisé par le genre ID %d (%s)", nouveauNom, existant.ID, existant.Nom)
+		}
+		genre.Nom = nouveauNom
+	}
+
+	if nouveauParentID >= 0 {
+		if nouveauParentID != 0 {
+			if parent, _ := gg.TrouverGenreParID(nouveauParentID); parent == nil {
+				return fmt.Errorf("aucun genre parent trouvé avec l'ID %d", nouveauParentID)
+			}
+			// RÈGLE MÉTIER : un genre ne peut pas devenir son propre descendant
+			for _, ancetre := range gg.Ancetres(nouveauParentID) {
+				if ancetre.ID == id {
+					return fmt.Errorf("impossible de déplacer '%s' sous lui-même ou l'un de ses sous-genres", genre.Nom)
+				}
+			}
+		}
+		genre.ParentID = nouveauParentID
+	}
+
+	gg.genres[index] = *genre
+	return gg.sauvegarderGenres()
+}
+
+func (gg *GestionnaireGenres) AjouterAlias(id int, alias string) error {
+	genre, index := gg.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	alias = strings.TrimSpace(alias)
+	if !validators.ValiderTitre(alias) {
+		return fmt.Errorf("l'alias est invalide")
+	}
+
+	if existant := gg.Resoudre(alias); existant != nil {
+		return fmt.Errorf("'%s' désigne déjà le genre ID %d (%s)", alias, existant.ID, existant.Nom)
+	}
+
+	genre.Alias = append(genre.Alias, alias)
+	gg.genres[index] = *genre
+	return gg.sauvegarderGenres()
+}
+
+func (gg *GestionnaireGenres) RetirerAlias(id int, alias string) error {
+	genre, index := gg.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	for i, a := range genre.Alias {
+		if strings.EqualFold(a, strings.TrimSpace(alias)) {
+			genre.Alias = append(genre.Alias[:i], genre.Alias[i+1:]...)
+			gg.genres[index] = *genre
+			return gg.sauvegarderGenres()
+		}
+	}
+
+	return fmt.Errorf("le genre '%s' n'a pas d'alias '%s'", genre.Nom, alias)
+}
+
+// SupprimerGenre supprime un genre sans sous-genre. La vérification des livres
+// qui l'utilisent est faite par GestionnaireLivres.SupprimerGenre.
+func (gg *GestionnaireGenres) SupprimerGenre(id int) error {
+	genre, index := gg.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	// RÈGLE MÉTIER : on ne peut pas supprimer un genre qui a des sous-genres
+	if enfants := gg.ListerEnfants(id); len(enfants) > 0 {
+		return fmt.Errorf("impossible de supprimer '%s' car il a %d sous-genre(s)", genre.Nom, len(enfants))
+	}
+
+	gg.genres = append(gg.genres[:index], gg.genres[index+1:]...)
+	return gg.sauvegarderGenres()
+}
+
+// ListerEnfants retourne les sous-genres directs, triés par nom (0 = genres racines)
+func (gg *GestionnaireGenres) ListerEnfants(parentID int) []models.Genre {
+	var enfants []models.Genre
+	for _, genre := range gg.genres {
+		if genre.ParentID == parentID {
+			enfants = append(enfants, genre)
+		}
+	}
+	sort.Slice(enfants, func(i, j int) bool {
+		return strings.ToLower(enfants[i].Nom) < strings.ToLower(enfants[j].Nom)
+	})
+	return enfants
+}
+
+// Ancetres retourne le genre et tous ses ancêtres, du genre lui-même jusqu'à la racine
+func (gg *GestionnaireGenres) Ancetres(id int) []models.Genre {
+	var ancetres []models.Genre
+	vus := make(map[int]bool)
+
+	for id != 0 && !vus[id] {
+		vus[id] = true
+		genre, _ := gg.TrouverGenreParID(id)
+		if genre == nil {
+			break
+		}
+		ancetres = append(ancetres, *genre)
+		id = genre.ParentID
+	}
+
+	return ancetres
+}
+
+// Descendants retourne les IDs du genre et de tous ses sous-genres
+func (gg *GestionnaireGenres) Descendants(id int) map[int]bool {
+	resultat := map[int]bool{id: true}
+	aVisiter := []int{id}
+
+	for len(aVisiter) > 0 {
+		courant := aVisiter[0]
+		aVisiter = aVisiter[1:]
+		for _, genre := range gg.genres {
+			if genre.ParentID == courant && !resultat[genre.ID] {
+				resultat[genre.ID] = true
+				aVisiter = append(aVisiter, genre.ID)
+			}
+		}
+	}
+
+	return resultat
+}
+
+// Chemin retourne le chemin complet d'un genre (ex : "Fiction > Policier > Noir")
+func (gg *GestionnaireGenres) Chemin(id int) string {
+	ancetres := gg.Ancetres(id)
+	noms := make([]string, len(ancetres))
+	for i, genre := range ancetres {
+		noms[len(ancetres)-1-i] = genre.Nom
+	}
+	return strings.Join(noms, " > ")
+}
+
+// ListerDansLOrdre retourne tous les genres dans l'ordre de l'arbre (parcours en profondeur)
+func (gg *GestionnaireGenres) ListerDansLOrdre() []models.Genre {
+	var resultat []models.Genre
+	var parcourir func(parentID int)
+	parcourir = func(parentID int) {
+		for _, enfant := range gg.ListerEnfants(parentID) {
+			resultat = append(resultat, enfant)
+			parcourir(enfant.ID)
+		}
+	}
+	parcourir(0)
+	return resultat
+}
+
+// Profondeur retourne le niveau d'un genre dans l'arbre (0 pour une racine)
+func (gg *GestionnaireGenres) Profondeur(id int) int {
+	return len(gg.Ancetres(id)) - 1
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// INVENTAIRE DES RAYONS
+// Chaque relevé est enregistré aussitôt : une session interrompue reprend le
+// lendemain là où elle s'était arrêtée. Le rapport d'écarts compare les livres
+// relevés à ce que le catalogue attend en rayon.
+// ========================================
+
+type GestionnaireInventaires struct {
+	inventaires []models.Inventaire
+	sequence    *storage.Sequence
+	stockage    storage.Storage
+
+	gestionnaireLivres       *GestionnaireLivres
+	gestionnaireReservations *GestionnaireReservations
+}
+
+const (
+	RELEVE_EN_RAYON = "en_rayon" // livre du rayon, attendu en rayon
+	RELEVE_EMPRUNTE = "emprunte" // le catalogue le croit emprunté
+	RELEVE_HORS     = "hors"     // livre d'un autre rayon
+	RELEVE_RETIRE   = "retire"   // livre retiré du fonds (perdu, désherbé) retrouvé
+	RELEVE_INCONNU  = "inconnu"  // aucun livre du catalogue ne correspond
+	RELEVE_DEJA_VU  = "deja_vu"  // ce livre a déjà été relevé dans la session
+	RELEVE_MIS_COTE = "mis_cote" // livre mis de côté pour un membre, attendu à l'accueil
+)
+
+// Releve est le résultat d'un relevé, pour prévenir tout de suite la personne
+// qui scanne qu'un livre mérite attention
+type Releve struct {
+	Type  string
+	Livre *models.Livre
+}
+
+// EcartsInventaire est le rapport d'une session
+type EcartsInventaire struct {
+	Attendus int // livres que le catalogue attend en rayon
+	Releves  int // livres relevés (inconnus compris)
+
+	Manquants         []models.Livre // attendus en rayon, ni empruntés ni retrouvés
+	EmpruntesPresents []models.Livre // trouvés en rayon alors que le catalogue les croit empruntés
+	HorsRayon         []models.Livre // trouvés dans ce rayon, rangés d'ordinaire ailleurs
+	Retrouves         []models.Livre // retirés du fonds (perdus, désherbés) mais trouvés en rayon
+	MisDeCote         []models.Livre // trouvés en rayon alors qu'ils devraient attendre un membre à l'accueil
+	Inconnus          []models.Constat
+}
+
+func (gi *GestionnaireInventaires) sauvegarderInventaires() error {
+	return gi.stockage.Sauvegarder(gi.inventaires)
+}
+
+func (gi *GestionnaireInventaires) ChargerInventaires() error {
+	if err := gi.stockage.Charger(&gi.inventaires); err != nil {
+		return err
+	}
+
+	for _, inventaire := range gi.inventaires {
+		gi.sequence.Observer(inventaire.ID)
+	}
+	return nil
+}
+
+func NouveauGestionnaireInventaires(stockage storage.Storage, sequences *storage.Sequences, gl *GestionnaireLivres, gr *GestionnaireReservations) *GestionnaireInventaires {
+	gi := &GestionnaireInventaires{
+		inventaires:              make([]models.Inventaire, 0),
+		sequence:                 sequences.Sequence("inventaires"),
+		stockage:                 stockage,
+		gestionnaireLivres:       gl,
+		gestionnaireReservations: gr,
+	}
+
+	gi.ChargerInventaires()
+	return gi
+}
+
+// ========================================
+// SESSIONS
+// ========================================
+
+// OuvrirInventaire démarre l'inventaire d'un rayon (un genre, ou tout le fonds si genre est vide)
+func (gi *GestionnaireInventaires) OuvrirInventaire(nom, genre string) (*models.Inventaire, error) {
+	nom = strings.TrimSpace(nom)
+	if !validators.ValiderTitre(nom) {
+		return nil, fmt.Errorf("le nom de l'inventaire est invalide")
+	}
+
+	inventaire := models.Inventaire{
+		Nom:           nom,
+		Statut:        models.INVENTAIRE_EN_COURS,
+		DateOuverture: time.Now(),
+		Constats:      make([]models.Constat, 0),
+		SuccursaleID:  gi.gestionnaireLivres.gestionnaireSuccursales.CouranteID(),
+	}
+	if strings.TrimSpace(genre) != "" {
+		resolu, err := gi.gestionnaireLivres.resoudreGenre(genre)
+		if err != nil {
+			return nil, err
+		}
+		inventaire.GenreID, inventaire.Genre = resolu.ID, resolu.Nom
+	}
+
+	// RÈGLE MÉTIER : un rayon ne s'inventorie qu'une fois à la fois
+	for _, existant := range gi.inventaires {
+		if existant.EstEnCours() && existant.GenreID == inventaire.GenreID && existant.SuccursaleID == inventaire.SuccursaleID {
+			return nil, fmt.Errorf("l'inventaire '%s' (ID : %d) couvre déjà %s : reprenez-le ou clôturez-le", existant.Nom, existant.ID, existant.Rayon())
+		}
+	}
+
+	id, err := gi.sequence.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+	inventaire.ID = id
+	gi.inventaires = append(gi.inventaires, inventaire)
+
+	if err := gi.sauvegarderInventaires(); err != nil {
+		return nil, err
+	}
+	return &gi.inventaires[len(gi.inventaires)-1], nil
+}
+
+// ListerInventaires retourne les sessions, les plus récentes d'abord
+func (gi *GestionnaireInventaires) ListerInventaires() []models.Inventaire {
+	inventaires := make([]models.Inventaire, len(gi.inventaires))
+	copy(inventaires, gi.inventaires)
+	sort.SliceStable(inventaires, func(i, j int) bool { return inventaires[i].ID > inventaires[j].ID })
+	return inventaires
+}
+
+func (gi *GestionnaireInventaires) TrouverInventaireParID(id int) *models.Inventaire {
+	for i, inventaire := range gi.inventaires {
+		if inventaire.ID == id {
+			return &gi.inventaires[i]
+		}
+	}
+	return nil
+}
+
+func (gi *GestionnaireInventaires) inventaireEnCours(id int) (*models.Inventaire, error) {
+	inventaire := gi.TrouverInventaireParID(id)
+	if inventaire == nil {
+		return nil, fmt.Errorf("aucun inventaire trouvé avec l'ID %d", id)
+	}
+	if !inventaire.EstEnCours() {
+		return nil, fmt.Errorf("l'inventaire '%s' est clôturé", inventaire.Nom)
+	}
+	return inventaire, nil
+}
+
+func (gi *GestionnaireInventaires) CloturerInventaire(id int) error {
+	inventaire, err := gi.inventaireEnCours(id)
+	if err != nil {
+		return err
+	}
+
+	inventaire.Statut = models.INVENTAIRE_CLOTURE
+	inventaire.DateCloture = time.Now()
+	return gi.sauvegarderInventaires()
+}
+
+// ========================================
+// RELEVÉS
+// ========================================
+
+// Relever enregistre un livre trouvé en rayon. Un code inconnu est noté tel
+// quel pour le rapport ; un livre déjà relevé n'est pas compté deux fois.
+func (gi *GestionnaireInventaires) Relever(inventaireID int, saisie string) (Releve, error) {
+	inventaire, err := gi.inventaireEnCours(inventaireID)
+	if err != nil {
+		return Releve{}, err
+	}
+
+	code := models.NormaliserCode(saisie)
+	if code == "" {
+		return Releve{}, fmt.Errorf("aucun code saisi")
+	}
+
+	releves := gi.livresReleves(inventaire)
+	livre := gi.identifier(code, releves)
+	if livre != nil && releves[livre.ID] {
+		return Releve{Type: RELEVE_DEJA_VU, Livre: livre}, nil
+	}
+
+	constat := models.Constat{Saisie: code, Date: time.Now()}
+	if livre != nil {
+		constat.LivreID = livre.ID
+	}
+	inventaire.Constats = append(inventaire.Constats, constat)
+	if err := gi.sauvegarderInventaires(); err != nil {
+		return Releve{}, err
+	}
+
+	if livre == nil {
+		return Releve{Type: RELEVE_INCONNU}, nil
+	}
+	return Releve{Type: gi.classer(*livre, gi.dansLeRayon(inventaire)), Livre: livre}, nil
+}
+
+// AnnulerDernierReleve retire le dernier relevé de la session (erreur de scan)
+func (gi *GestionnaireInventaires) AnnulerDernierReleve(inventaireID int) (models.Constat, error) {
+	inventaire, err := gi.inventaireEnCours(inventaireID)
+	if err != nil {
+		return models.Constat{}, err
+	}
+	if len(inventaire.Constats) == 0 {
+		return models.Constat{}, fmt.Errorf("aucun livre n'a encore été relevé")
+	}
+
+	dernier := inventaire.Constats[len(inventaire.Constats)-1]
+	inventaire.Constats = inventaire.Constats[:len(inventaire.Constats)-1]
+	return dernier, gi.sauvegarderInventaires()
+}
+
+// identifier retrouve le livre d'un code : son code-barres, sinon un exemplaire
+// de cet ISBN pas encore relevé, sinon son ID
+func (gi *GestionnaireInventaires) identifier(code string, releves map[int]bool) *models.Livre {
+	gl := gi.gestionnaireLivres
+	for i, livre := range gl.livres {
+		if livre.CodeBarres == code {
+			return &gl.livres[i]
+		}
+	}
+
+	var premier *models.Livre
+	for i, livre := range gl.livres {
+		if livre.ISBN != "" && strings.EqualFold(livre.ISBN, code) {
+			if !releves[livre.ID] {
+				return &gl.livres[i]
+			}
+			if premier == nil {
+				premier = &gl.livres[i]
+			}
+		}
+	}
+	if premier != nil {
+		return premier
+	}
+
+	livre, _ := gl.TrouverLivreParCode(code)
+	return livre
+}
+
+func (gi *GestionnaireInventaires) livresReleves(inventaire *models.Inventaire) map[int]bool {
+	releves := make(map[int]bool, len(inventaire.Constats))
+	for _, constat := range inventaire.Constats {
+		if constat.LivreID != 0 {
+			releves[constat.LivreID] = true
+		}
+	}
+	return releves
+}
+
+// dansLeRayon retourne le test d'appartenance au rayon de l'inventaire
+func (gi *GestionnaireInventaires) dansLeRayon(inventaire *models.Inventaire) func(models.Livre) bool {
+	// En réseau, seuls les livres présents dans la succursale inventoriée sont attendus ;
+	// un livre prêté par une autre bibliothèque n'est jamais rangé en rayon
+	ici := func(livre models.Livre) bool {
+		if livre.EstPEB() {
+			return false
+		}
+		return inventaire.SuccursaleID == 0 || (livre.EmplacementID == inventaire.SuccursaleID && !livre.EstEnTransit())
+	}
+	if inventaire.GenreID == 0 {
+		return ici
+	}
+	genres := gi.gestionnaireLivres.gestionnaireGenres.Descendants(inventaire.GenreID)
+	return func(livre models.Livre) bool {
+		return ici(livre) && len(livre.Sujets) > 0 && genres[livre.Sujets[0]]
+	}
+}
+
+// classer dit ce que signifie la présence du livre en rayon
+func (gi *GestionnaireInventaires) classer(livre models.Livre, dansLeRayon func(models.Livre) bool) string {
+	switch {
+	case livre.EstRetire():
+		return RELEVE_RETIRE
+	case !livre.Disponible:
+		return RELEVE_EMPRUNTE
+	case gi.gestionnaireReservations.ReservationPrete(livre.ID) != nil:
+		return RELEVE_MIS_COTE
+	case !dansLeRayon(livre):
+		return RELEVE_HORS
+	}
+	return RELEVE_EN_RAYON
+}
+
+// ========================================
+// RAPPORT D'ÉCARTS
+// ========================================
+
+// Ecarts compare les relevés au catalogue. Les livres empruntés et ceux mis
+// de côté à l'accueil ne sont pas attendus en rayon.
+func (gi *GestionnaireInventaires) Ecarts(inventaireID int) (EcartsInventaire, error) {
+	inventaire := gi.TrouverInventaireParID(inventaireID)
+	if inventaire == nil {
+		return EcartsInventaire{}, fmt.Errorf("aucun inventaire trouvé avec l'ID %d", inventaireID)
+	}
+
+	dansLeRayon := gi.dansLeRayon(inventaire)
+	releves := gi.livresReleves(inventaire)
+	ecarts := EcartsInventaire{Releves: len(inventaire.Constats)}
+
+	for _, livre := range gi.gestionnaireLivres.livresAuCatalogue() {
+		if !dansLeRayon(livre) || !livre.Disponible || gi.gestionnaireReservations.ReservationPrete(livre.ID) != nil {
+			continue
+		}
+		ecarts.Attendus++
+		if !releves[livre.ID] {
+			ecarts.Manquants = append(ecarts.Manquants, livre)
+		}
+	}
+
+	for _, constat := range inventaire.Constats {
+		if constat.LivreID == 0 {
+			ecarts.Inconnus = append(ecarts.Inconnus, constat)
+			continue
+		}
+		livre, _ := gi.gestionnaireLivres.TrouverLivreParID(constat.LivreID)
+		if livre == nil {
+			// Le livre a été purgé depuis le relevé
+			ecarts.Inconnus = append(ecarts.Inconnus, constat)
+			continue
+		}
+		switch gi.classer(*livre, dansLeRayon) {
+		case RELEVE_EMPRUNTE:
+			ecarts.EmpruntesPresents = append(ecarts.EmpruntesPresents, *livre)
+		case RELEVE_HORS:
+			ecarts.HorsRayon = append(ecarts.HorsRayon, *livre)
+		case RELEVE_RETIRE:
+			ecarts.Retrouves = append(ecarts.Retrouves, *livre)
+		case RELEVE_MIS_COTE:
+			ecarts.MisDeCote = append(ecarts.MisDeCote, *livre)
+		}
+	}
+	return ecarts, nil
+}
+
+// DeclarerPerdus retire du fonds, motif « perdu », les livres manquants de
+// l'inventaire. Seuls les livres encore manquants au moment de l'appel sont
+// retirés : un livre rendu ou relevé entre-temps est laissé au catalogue.
+func (gi *GestionnaireInventaires) DeclarerPerdus(inventaireID int, livreIDs []int) ([]models.Livre, error) {
+	ecarts, err := gi.Ecarts(inventaireID)
+	if err != nil {
+		return nil, err
+	}
+	inventaire := gi.TrouverInventaireParID(inventaireID)
+
+	manquants := make(map[int]bool, len(ecarts.Manquants))
+	for _, livre := range ecarts.Manquants {
+		manquants[livre.ID] = true
+	}
+
+	var perdus []models.Livre
+	raison := fmt.Sprintf("introuvable à l'inventaire '%s'", inventaire.Nom)
+	for _, id := range livreIDs {
+		if !manquants[id] {
+			continue
+		}
+		if err := gi.gestionnaireLivres.RetirerLivre(id, models.MOTIF_PERDU, raison); err != nil {
+			return perdus, err
+		}
+		livre, _ := gi.gestionnaireLivres.TrouverLivreParID(id)
+		perdus = append(perdus, *livre)
+	}
+	return perdus, nil
+}
@@ -2,18 +2,27 @@ package services
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
 	"github.com/felver-dev/bookstore/internal/storage"
 	"github.com/felver-dev/bookstore/internal/validators"
 )
 
 type GestionnaireLivres struct {
 	livres     []models.Livre
-	prochainID int
+	sequence   *storage.Sequence
 	stockage   storage.Storage
+	validateur *validators.Validateur
+	repere     string // repère du poste dans les codes générés, vide tant qu'il ne se synchronise pas
+
+	gestionnaireGenres        *GestionnaireGenres
+	gestionnaireContributeurs *GestionnaireContributeurs
+	gestionnaireSuccursales   *GestionnaireSuccursales
 }
 
 func (gl *GestionnaireLivres) ChargerLivres() error {
@@ -23,9 +32,7 @@ func (gl *GestionnaireLivres) ChargerLivres() error {
 	}
 
 	for _, livre := range gl.livres {
-		if livre.ID >= gl.prochainID {
-			gl.prochainID = livre.ID
-		}
+		gl.sequence.Observer(livre.ID)
 	}
 
 	return nil
@@ -35,66 +42,233 @@ func (gl *GestionnaireLivres) sauvegarderLivres() error {
 	return gl.stockage.Sauvegarder(gl.livres)
 }
 
-func NouveauGestionnaireLivres(stockage storage.Storage) *GestionnaireLivres {
+func NouveauGestionnaireLivres(stockage storage.Storage, sequences *storage.Sequences, validateur *validators.Validateur, gg *GestionnaireGenres, gc *GestionnaireContributeurs, gsu *GestionnaireSuccursales) *GestionnaireLivres {
 	gl := &GestionnaireLivres{
-		livres:     make([]models.Livre, 0),
-		prochainID: 1,
-		stockage:   stockage,
+		livres:                    make([]models.Livre, 0),
+		sequence:                  sequences.Sequence("livres"),
+		stockage:                  stockage,
+		validateur:                validateur,
+		gestionnaireGenres:        gg,
+		gestionnaireContributeurs: gc,
+		gestionnaireSuccursales:   gsu,
 	}
 
 	gl.ChargerLivres()
+	gl.normaliserGenres()        // Rattacher les anciens genres texte à la taxonomie
+	gl.normaliserContributeurs() // Rattacher les anciens auteurs texte aux fiches contributeurs
+	gl.normaliserIdentifiants()  // Attribuer un code-barres et un UID aux livres qui n'en ont pas
+	if gsu.EstEnReseau() {
+		gl.rattacherSuccursale(gsu.ListerSuccursales()[0].ID) // Les livres entrés hors réseau vont à la première succursale
+	}
 	return gl
 }
 
+// normaliserGenres rattache chaque livre à la taxonomie et remet à jour le nom
+// canonique de son genre principal. Les genres inconnus sont créés à la racine.
+func (gl *GestionnaireLivres) normaliserGenres() error {
+	modifie := false
+
+	for i := range gl.livres {
+		livre := &gl.livres[i]
+
+		// Ne garder que les sujets qui existent encore
+		var sujets []int
+		for _, id := range livre.Sujets {
+			if genre, _ := gl.gestionnaireGenres.TrouverGenreParID(id); genre != nil {
+				sujets = append(sujets, id)
+			}
+		}
+
+		if len(sujets) == 0 && strings.TrimSpace(livre.Genre) != "" {
+			genre := gl.gestionnaireGenres.Resoudre(livre.Genre)
+			if genre == nil {
+				var err error
+				genre, err = gl.gestionnaireGenres.AjouterGenre(livre.Genre, 0)
+				if err != nil {
+					return err
+				}
+			}
+			sujets = []int{genre.ID}
+		}
+
+		if len(sujets) != len(livre.Sujets) {
+			livre.Sujets = sujets
+			modifie = true
+		}
+
+		if len(livre.Sujets) > 0 {
+			principal, _ := gl.gestionnaireGenres.TrouverGenreParID(livre.Sujets[0])
+			if livre.Genre != principal.Nom {
+				livre.Genre = principal.Nom
+				modifie = true
+			}
+		}
+	}
+
+	if modifie {
+		return gl.sauvegarderLivres()
+	}
+	return nil
+}
+
+// resoudreGenre retrouve un genre de la taxonomie par son nom canonique ou un alias
+func (gl *GestionnaireLivres) resoudreGenre(terme string) (*models.Genre, error) {
+	genre := gl.gestionnaireGenres.Resoudre(terme)
+	if genre == nil {
+		return nil, fmt.Errorf("le genre '%s' n'est pas reconnu", terme)
+	}
+	return genre, nil
+}
+
 // Methodes publiques
 
 func (gl *GestionnaireLivres) AjouterLivre(titre, auteur, isbn, genre, datePublicationStr string) error {
+	nouveauLivre, err := gl.nouveauLivre(titre, auteur, isbn, genre, datePublicationStr)
+	if err != nil {
+		return err
+	}
+
+	gl.livres = append(gl.livres, nouveauLivre)
+	return gl.sauvegarderLivres()
+}
+
+// saisieLivre est une saisie de livre validée
+type saisieLivre struct {
+	titre           string
+	auteurs         []string
+	isbn            string
+	genre           *models.Genre
+	datePublication time.Time
+}
+
+// validerSaisieLivre vérifie la saisie d'un nouveau livre sans rien enregistrer
+func (gl *GestionnaireLivres) validerSaisieLivre(titre, auteur, isbn, genre, datePublicationStr string) (saisieLivre, error) {
 	if !validators.ValiderTitre(titre) {
-		return fmt.Errorf("le titre du livre est invalide")
+		return saisieLivre{}, fmt.Errorf("le titre du livre est invalide")
 	}
 
-	if !validators.ValiderNom(auteur) {
-		return fmt.Errorf("le nom de l'auteur est invalide")
+	nomsAuteurs := decouperNoms(auteur)
+	if len(nomsAuteurs) == 0 {
+		return saisieLivre{}, fmt.Errorf("le nom de l'auteur est invalide")
+	}
+	for _, nom := range nomsAuteurs {
+		if !validators.ValiderNom(nom) {
+			return saisieLivre{}, fmt.Errorf("le nom de l'auteur '%s' est invalide", nom)
+		}
 	}
 
 	if !validators.ValiderISBN(isbn) {
-		return fmt.Errorf("l'ISBN est invalide (doit faire 10 ou 13 caractères)")
+		return saisieLivre{}, fmt.Errorf("l'ISBN est invalide (doit faire 10 ou 13 caractères)")
 	}
 
-	if !validators.ValiderGenre(genre) {
-		return fmt.Errorf("le genre '%s' n'est pas reconnu", genre)
+	genreResolu, err := gl.resoudreGenre(genre)
+	if err != nil {
+		return saisieLivre{}, err
 	}
 
-	datePublication, err := validators.ValiderDatePublication(datePublicationStr)
+	datePublication, err := gl.validateur.ValiderDatePublication(datePublicationStr)
 	if err != nil {
-		return fmt.Errorf("date de publication invalide : %v", err)
+		return saisieLivre{}, fmt.Errorf("date de publication invalide : %v", err)
 	}
 
 	for _, livre := range gl.livres {
-		if strings.EqualFold(livre.ISBN, isbn) {
-			return fmt.Errorf("un livre avec l'ISBN %s existe déjà (ID : %d - %s)", isbn, livre.ID, livre.Titre)
+		if strings.EqualFold(livre.ISBN, isbn) && !livre.EstPEB() {
+			if livre.EstRetire() {
+				return saisieLivre{}, fmt.Errorf("un livre retiré a déjà l'ISBN %s (ID : %d - %s), restaurez-le plutôt", isbn, livre.ID, livre.Titre)
+			}
+			return saisieLivre{}, fmt.Errorf("un livre avec l'ISBN %s existe déjà (ID : %d - %s)", isbn, livre.ID, livre.Titre)
 		}
 	}
 
+	return saisieLivre{
+		titre:           strings.TrimSpace(titre),
+		auteurs:         nomsAuteurs,
+		isbn:            strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", ""),
+		genre:           genreResolu,
+		datePublication: datePublication,
+	}, nil
+}
+
+// nouveauLivre valide la saisie et prépare la fiche du prochain livre, sans l'enregistrer
+func (gl *GestionnaireLivres) nouveauLivre(titre, auteur, isbn, genre, datePublicationStr string) (models.Livre, error) {
+	saisie, err := gl.validerSaisieLivre(titre, auteur, isbn, genre, datePublicationStr)
+	if err != nil {
+		return models.Livre{}, err
+	}
+
+	contributions, err := gl.contributionsAuteurs(saisie.auteurs)
+	if err != nil {
+		return models.Livre{}, err
+	}
+
+	id, err := gl.sequence.Attribuer()
+	if err != nil {
+		return models.Livre{}, err
+	}
+
 	maintenant := time.Now()
 	nouveauLivre := models.Livre{
-		ID:              gl.prochainID,
-		Titre:           strings.TrimSpace(titre),
-		Auteur:          strings.TrimSpace(auteur),
-		ISBN:            strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", ""),
-		Genre:           genre,
-		DatePublication: datePublication,
+		ID:              id,
+		UID:             models.NouvelUID(),
+		Titre:           saisie.titre,
+		Contributions:   contributions,
+		ISBN:            saisie.isbn,
+		CodeBarres:      gl.codeGenere(id),
+		Genre:           saisie.genre.Nom,
+		Sujets:          []int{saisie.genre.ID},
+		DatePublication: saisie.datePublication,
 		Disponible:      true,
 		NombreEmprunts:  0,
 		DateAjout:       maintenant,
+		SuccursaleID:    gl.gestionnaireSuccursales.CouranteID(),
+		EmplacementID:   gl.gestionnaireSuccursales.CouranteID(),
 	}
+	gl.rafraichirAuteurs(&nouveauLivre)
+	return nouveauLivre, nil
+}
 
-	gl.livres = append(gl.livres, nouveauLivre)
-	gl.prochainID++
-	return gl.sauvegarderLivres()
+// AjouterExemplaireAcquis entre au catalogue un livre reçu sur une commande.
+// Si l'ISBN y est déjà (même retiré), la fiche existante est reprise pour un
+// nouvel exemplaire avec son propre code-barres ; sinon la fiche est créée à
+// partir des informations de la commande.
+func (gl *GestionnaireLivres) AjouterExemplaireAcquis(isbn, titre, auteur, genre, datePublicationStr string, acquisition models.Acquisition) (models.Livre, error) {
+	var exemplaire models.Livre
+	if modele, _ := gl.TrouverLivreParISBN(isbn); modele != nil && !modele.EstPEB() {
+		id, err := gl.sequence.Attribuer()
+		if err != nil {
+			return models.Livre{}, err
+		}
+		exemplaire = *modele
+		exemplaire.Sujets = append([]int(nil), modele.Sujets...)
+		exemplaire.Contributions = append([]models.Contribution(nil), modele.Contributions...)
+		exemplaire.ID = id
+		exemplaire.UID = models.NouvelUID()
+		exemplaire.CodeBarres = gl.codeGenere(id)
+		exemplaire.Disponible = true
+		exemplaire.NombreEmprunts = 0
+		exemplaire.DateAjout = time.Now()
+		exemplaire.Retrait = nil
+		exemplaire.SuccursaleID = gl.gestionnaireSuccursales.CouranteID()
+		exemplaire.EmplacementID = exemplaire.SuccursaleID
+		exemplaire.Transfert = nil
+	} else {
+		var err error
+		if exemplaire, err = gl.nouveauLivre(titre, auteur, isbn, genre, datePublicationStr); err != nil {
+			return models.Livre{}, err
+		}
+	}
+	exemplaire.Acquisition = &acquisition
+
+	gl.livres = append(gl.livres, exemplaire)
+	return exemplaire, gl.sauvegarderLivres()
 }
 
+// ListerLivres retourne les livres au catalogue (les livres retirés sont masqués)
 func (gl *GestionnaireLivres) ListerLivres() []models.Livre {
+	return gl.livresAuCatalogue()
+}
+
+func (gl *GestionnaireLivres) ListerLivresDisponibles() []models.Livre {
 	var disponibles []models.Livre
 
 	for _, livre := range gl.livres {
@@ -108,10 +282,22 @@ func (gl *GestionnaireLivres) ListerLivres() []models.Livre {
 
 func (gl *GestionnaireLivres) RechercherLivres(terme string) []models.Livre {
 	var resultats []models.Livre
-	terme = strings.TrimSpace(terme)
+	terme = strings.ToLower(strings.TrimSpace(terme))
 
-	for _, livre := range gl.livres {
-		if strings.Contains(strings.ToLower(livre.Titre), terme) || strings.Contains(strings.ToLower(livre.Auteur), terme) || strings.Contains(strings.ToLower(livre.Genre), terme) {
+	// Si le terme désigne un genre (nom ou alias), inclure tous ses sous-genres
+	genresCibles := make(map[int]bool)
+	if genre := gl.gestionnaireGenres.Resoudre(terme); genre != nil {
+		genresCibles = gl.gestionnaireGenres.Descendants(genre.ID)
+	}
+
+	// Inclure les livres des contributeurs dont une variante de nom correspond
+	contributeursCibles := make(map[int]bool)
+	for _, contributeur := range gl.gestionnaireContributeurs.RechercherContributeurs(terme) {
+		contributeursCibles[contributeur.ID] = true
+	}
+
+	for _, livre := range gl.livresAuCatalogue() {
+		if strings.Contains(strings.ToLower(livre.Titre), terme) || strings.Contains(strings.ToLower(livre.Auteur), terme) || strings.Contains(strings.ToLower(livre.Genre), terme) || livre.ASujetParmi(genresCibles) || aContributeurParmi(livre, contributeursCibles) {
 
 			resultats = append(resultats, livre)
 		}
@@ -130,6 +316,19 @@ func (gl *GestionnaireLivres) TrouverLivreParID(id int) (*models.Livre, int) {
 	return nil, -1
 }
 
+// trouverLivreParUID retrouve un livre par son UID, le même sur tous les postes
+func (gl *GestionnaireLivres) trouverLivreParUID(uid string) *models.Livre {
+	if uid == "" {
+		return nil
+	}
+	for i, livre := range gl.livres {
+		if livre.UID == uid {
+			return &gl.livres[i]
+		}
+	}
+	return nil
+}
+
 func (gl *GestionnaireLivres) TrouverLivreParISBN(isbn string) (*models.Livre, int) {
 	isbnNettoye := strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", "")
 
@@ -156,10 +355,24 @@ func (gl *GestionnaireLivres) ModifierLivre(id int, nouveauTitre, nouvelAuteur,
 	}
 
 	if nouvelAuteur != "" {
-		if !validators.ValiderNom(nouvelAuteur) {
-			return fmt.Errorf("le nouveau nom d'auteur est invalide")
+		noms := decouperNoms(nouvelAuteur)
+		for _, nom := range noms {
+			if !validators.ValiderNom(nom) {
+				return fmt.Errorf("le nouveau nom d'auteur '%s' est invalide", nom)
+			}
 		}
-		livre.Auteur = strings.TrimSpace(nouvelAuteur)
+		auteurs, err := gl.contributionsAuteurs(noms)
+		if err != nil {
+			return err
+		}
+		// Remplacer les auteurs en conservant les autres rôles (traducteur, illustrateur...)
+		for _, contribution := range livre.Contributions {
+			if contribution.Role != models.ROLE_AUTEUR {
+				auteurs = append(auteurs, contribution)
+			}
+		}
+		livre.Contributions = auteurs
+		gl.rafraichirAuteurs(livre)
 	}
 
 	if nouvelISBN != "" {
@@ -178,14 +391,23 @@ func (gl *GestionnaireLivres) ModifierLivre(id int, nouveauTitre, nouvelAuteur,
 	}
 
 	if nouveauGenre != "" {
-		if !validators.ValiderGenre(nouveauGenre) {
-			return fmt.Errorf("le nouveau genre '%s' n'est pas reconnu", nouveauGenre)
+		genre, err := gl.resoudreGenre(nouveauGenre)
+		if err != nil {
+			return err
 		}
-		livre.Genre = nouveauGenre
+		// Le nouveau genre devient le genre principal, les autres sujets sont conservés
+		sujets := []int{genre.ID}
+		for _, id := range livre.Sujets {
+			if id != genre.ID {
+				sujets = append(sujets, id)
+			}
+		}
+		livre.Sujets = sujets
+		livre.Genre = genre.Nom
 	}
 
 	if nouvelleDateStr != "" {
-		nouvelleDate, err := validators.ValiderDatePublication(nouvelleDateStr)
+		nouvelleDate, err := gl.validateur.ValiderDatePublication(nouvelleDateStr)
 		if err != nil {
 			return fmt.Errorf("nouvelle date de publication invalide : %v", err)
 		}
@@ -197,23 +419,147 @@ func (gl *GestionnaireLivres) ModifierLivre(id int, nouveauTitre, nouvelAuteur,
 
 }
 
-func (gl *GestionnaireLivres) SupprimerLivre(id int) error {
+// RetirerLivre sort un livre du fonds (désherbage ou perte) sans l'effacer :
+// l'historique des emprunts continue de pointer vers lui
+func (gl *GestionnaireLivres) RetirerLivre(id int, motif, raison string) error {
+	return gl.retirer(id, motif, raison, false)
+}
+
+// retirer sort le livre du fonds ; un livre emprunté n'est accepté que si
+// l'appelant clôt lui-même son emprunt (GestionnaireEmprunts.RetirerLivre)
+func (gl *GestionnaireLivres) retirer(id int, motif, raison string, empruntClos bool) error {
 	livre, index := gl.TrouverLivreParID(id)
 	if livre == nil {
 		return fmt.Errorf("aucun livre trouvé avec l'ID %d", id)
 	}
 
-	// RÈGLE MÉTIER : On ne peut pas supprimer un livre actuellement emprunté
-	if !livre.EstDisponible() {
-		return fmt.Errorf("impossible de supprimer le livre '%s' car il est actuellement emprunté", livre.Titre)
+	if livre.EstRetire() {
+		return fmt.Errorf("le livre '%s' est déjà retiré (%s)", livre.Titre, livre.Retrait.String())
+	}
+
+	if !validerMotifLivre(motif) {
+		return fmt.Errorf("le motif de retrait '%s' n'est pas reconnu", motif)
 	}
 
-	// Supprimer le livre de la liste
-	gl.livres = append(gl.livres[:index], gl.livres[index+1:]...)
+	// RÈGLE MÉTIER : On ne peut pas retirer un livre actuellement emprunté
+	if !livre.Disponible && !empruntClos {
+		return fmt.Errorf("impossible de retirer le livre '%s' car il est actuellement emprunté", livre.Titre)
+	}
+
+	if livre.EstEnTransit() {
+		return fmt.Errorf("le livre '%s' est en transit vers %s : réceptionnez-le avant de le retirer",
+			livre.Titre, gl.gestionnaireSuccursales.NomSuccursale(livre.Transfert.Vers))
+	}
+
+	livre.Retrait = &models.Retrait{
+		Motif:  motif,
+		Date:   time.Now(),
+		Raison: strings.TrimSpace(raison),
+	}
+	gl.livres[index] = *livre
 
 	return gl.sauvegarderLivres()
 }
 
+// verifierRestauration dit pourquoi le livre ne peut pas revenir au catalogue (nil s'il le peut)
+func (gl *GestionnaireLivres) verifierRestauration(id int) error {
+	livre, _ := gl.TrouverLivreParID(id)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", id)
+	}
+	if !livre.EstRetire() {
+		return fmt.Errorf("le livre '%s' n'est pas retiré", livre.Titre)
+	}
+	if livre.EstPurge() {
+		return fmt.Errorf("le livre '%s' a été purgé le %s : sa fiche ne peut plus être restaurée", livre.Titre, livre.Retrait.Purge.Format("02/01/2006"))
+	}
+	return nil
+}
+
+// restaurer efface le retrait du livre. Sa disponibilité vient du journal
+// des emprunts : c'est GestionnaireEmprunts.RestaurerLivre qui l'appelle.
+func (gl *GestionnaireLivres) restaurer(id int) error {
+	if err := gl.verifierRestauration(id); err != nil {
+		return err
+	}
+	_, index := gl.TrouverLivreParID(id)
+	gl.livres[index].Retrait = nil
+	return gl.sauvegarderLivres()
+}
+
+// ListerLivresRetires retourne les livres sortis du fonds, sauf ceux dont la fiche a été purgée
+func (gl *GestionnaireLivres) ListerLivresRetires() []models.Livre {
+	var retires []models.Livre
+	for _, livre := range gl.livres {
+		if livre.EstRetire() && !livre.EstPurge() {
+			retires = append(retires, livre)
+		}
+	}
+	return retires
+}
+
+// PurgerLivresRetires efface la fiche des livres retirés depuis plus de
+// conservationJours jours. Sans règle de conservation (0), rien n'est purgé.
+// Chaque livre purgé reste comme pierre tombale, avec son numéro, son UID,
+// son titre et ses compteurs : l'historique des emprunts pointe encore vers lui.
+func (gl *GestionnaireLivres) PurgerLivresRetires(conservationJours int) ([]models.Livre, error) {
+	if conservationJours <= 0 {
+		return nil, fmt.Errorf("aucune règle de conservation des livres retirés n'est définie")
+	}
+
+	maintenant := time.Now()
+	dateLimite := maintenant.AddDate(0, 0, -conservationJours)
+	var purges []models.Livre
+
+	for i, livre := range gl.livres {
+		if !livre.EstRetire() || livre.EstPurge() || !livre.Retrait.Date.Before(dateLimite) {
+			continue
+		}
+		purges = append(purges, livre)
+
+		retrait := *livre.Retrait
+		retrait.Purge = maintenant
+		gl.livres[i] = models.Livre{
+			ID:               livre.ID,
+			UID:              livre.UID,
+			Titre:            livre.Titre,
+			Sujets:           []int{},
+			Contributions:    []models.Contribution{},
+			Disponible:       livre.Disponible,
+			NombreEmprunts:   livre.NombreEmprunts,
+			EmpruntsArchives: livre.EmpruntsArchives,
+			DateAjout:        livre.DateAjout,
+			SuccursaleID:     livre.SuccursaleID,
+			Retrait:          &retrait,
+		}
+	}
+
+	if len(purges) == 0 {
+		return nil, nil
+	}
+	return purges, gl.sauvegarderLivres()
+}
+
+func validerMotifLivre(motif string) bool {
+	for _, m := range models.MOTIFS_RETRAIT_LIVRE {
+		if m == motif {
+			return true
+		}
+	}
+	return false
+}
+
+// livresAuCatalogue retourne les livres qui n'ont pas été retirés
+func (gl *GestionnaireLivres) livresAuCatalogue() []models.Livre {
+	var catalogue []models.Livre
+	for _, livre := range gl.livres {
+		if !livre.EstRetire() {
+			catalogue = append(catalogue, livre)
+		}
+	}
+	return catalogue
+}
+
 func (gl *GestionnaireLivres) MarquerCommeEmprunte(id int) error {
 	livre, index := gl.TrouverLivreParID(id)
 	if livre == nil {
@@ -243,48 +589,573 @@ func (gl *GestionnaireLivres) MarquerCommeDisponible(id int) error {
 	return gl.sauvegarderLivres()
 }
 
-func (gl *GestionnaireLivres) ObtenirStatistiques() map[string]interface{} {
-	stats := make(map[string]interface{})
+// ObtenirStatistiques décrit le fonds actuel ; la période ne sert qu'à compter les entrées au catalogue.
+// Avec une succursale (ID non nul), seul son fonds est compté, où que se trouvent ses livres.
+func (gl *GestionnaireLivres) ObtenirStatistiques(periode statistiques.Periode, succursaleID int) statistiques.Livres {
+	var catalogue []models.Livre
+	retires := 0
+	for _, livre := range gl.livres {
+		if (succursaleID != 0 && livre.SuccursaleID != succursaleID) || livre.EstPurge() {
+			continue
+		}
+		if livre.EstRetire() {
+			retires++
+		} else {
+			catalogue = append(catalogue, livre)
+		}
+	}
+	stats := statistiques.Livres{
+		Periode:      periode,
+		SuccursaleID: succursaleID,
+		Total:        len(catalogue),
+		Retires:      retires,
+	}
+
+	genresCount := make(map[string]int)
+	for _, livre := range catalogue {
+		// Compter les livres disponibles et empruntés
+		if livre.EstDisponible() {
+			stats.Disponibles++
+		} else if livre.EstEnTransit() {
+			stats.EnTransit++
+		} else {
+			stats.Empruntes++
+		}
+
+		if periode.Contient(livre.DateAjout) {
+			stats.Ajoutes++
+		}
+
+		// Compter par genre principal (nom canonique)
+		genresCount[livre.Genre]++
+
+		// Livre le plus emprunté
+		if livre.NombreEmprunts > 0 && (stats.PlusEmprunte == nil || livre.NombreEmprunts > stats.PlusEmprunte.Emprunts) {
+			stats.PlusEmprunte = &statistiques.Classement{ID: livre.ID, Nom: livre.Titre, Emprunts: livre.NombreEmprunts}
+		}
+	}
+	stats.ParGenre = statistiques.Repartir(genresCount)
+
+	if stats.Total > 0 {
+		stats.TauxOccupation = float64(stats.Empruntes) / float64(stats.Total) * 100
+	}
+
+	// Compter par genre en remontant la hiérarchie : un livre classé en
+	// "Noir" compte aussi pour "Policier" et "Fiction" (une seule fois par genre)
+	parGenreCumule := gl.compterParGenreCumule(catalogue)
+	stats.ParGenreCumule = []statistiques.Repartition{}
+	for _, genre := range gl.gestionnaireGenres.ListerDansLOrdre() {
+		if nombre := parGenreCumule[genre.ID]; nombre > 0 {
+			stats.ParGenreCumule = append(stats.ParGenreCumule, statistiques.Repartition{ID: genre.ID, Libelle: genre.Nom, Nombre: nombre})
+		}
+	}
+
+	return stats
+}
+
+// compterParGenreCumule retourne le nombre de livres par ID de genre, sous-genres inclus
+func (gl *GestionnaireLivres) compterParGenreCumule(catalogue []models.Livre) map[int]int {
+	compteur := make(map[int]int)
 
-	total := len(gl.livres)
-	stats["total"] = total
+	for _, livre := range catalogue {
+		genresDuLivre := make(map[int]bool)
+		for _, sujetID := range livre.Sujets {
+			for _, ancetre := range gl.gestionnaireGenres.Ancetres(sujetID) {
+				genresDuLivre[ancetre.ID] = true
+			}
+		}
+		for id := range genresDuLivre {
+			compteur[id]++
+		}
+	}
+
+	return compteur
+}
+
+// ========================================
+// SUJETS ET TAXONOMIE
+// ========================================
+
+// AjouterSujet associe un genre/sujet supplémentaire à un livre
+func (gl *GestionnaireLivres) AjouterSujet(livreID int, terme string) error {
+	livre, index := gl.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	genre, err := gl.resoudreGenre(terme)
+	if err != nil {
+		return err
+	}
+
+	if livre.ASujet(genre.ID) {
+		return fmt.Errorf("le livre '%s' a déjà le sujet '%s'", livre.Titre, genre.Nom)
+	}
 
-	if total == 0 {
-		return stats
+	livre.Sujets = append(livre.Sujets, genre.ID)
+	if len(livre.Sujets) == 1 {
+		livre.Genre = genre.Nom
 	}
 
-	// Compter les livres disponibles et empruntés
-	disponibles := 0
-	empruntes := 0
+	gl.livres[index] = *livre
+	return gl.sauvegarderLivres()
+}
+
+// RetirerSujet retire un sujet d'un livre. Le livre doit garder au moins un sujet.
+func (gl *GestionnaireLivres) RetirerSujet(livreID, genreID int) error {
+	livre, index := gl.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	if !livre.ASujet(genreID) {
+		return fmt.Errorf("le livre '%s' n'a pas le sujet ID %d", livre.Titre, genreID)
+	}
+
+	if len(livre.Sujets) == 1 {
+		return fmt.Errorf("impossible de retirer le dernier sujet du livre '%s'", livre.Titre)
+	}
+
+	var sujets []int
+	for _, id := range livre.Sujets {
+		if id != genreID {
+			sujets = append(sujets, id)
+		}
+	}
+	livre.Sujets = sujets
+
+	// Le genre principal est toujours le premier sujet
+	principal, _ := gl.gestionnaireGenres.TrouverGenreParID(sujets[0])
+	if principal != nil {
+		livre.Genre = principal.Nom
+	}
+
+	gl.livres[index] = *livre
+	return gl.sauvegarderLivres()
+}
+
+// ModifierGenre renomme ou déplace un genre et met à jour les livres concernés
+func (gl *GestionnaireLivres) ModifierGenre(id int, nouveauNom string, nouveauParentID int) error {
+	if err := gl.gestionnaireGenres.ModifierGenre(id, nouveauNom, nouveauParentID); err != nil {
+		return err
+	}
+	return gl.normaliserGenres()
+}
+
+// SupprimerGenre supprime un genre de la taxonomie s'il n'est utilisé par aucun livre
+func (gl *GestionnaireLivres) SupprimerGenre(id int) error {
+	genre, _ := gl.gestionnaireGenres.TrouverGenreParID(id)
+	if genre == nil {
+		return fmt.Errorf("aucun genre trouvé avec l'ID %d", id)
+	}
+
+	// RÈGLE MÉTIER : on ne peut pas supprimer un genre encore utilisé par des livres
+	utilisations := 0
 	for _, livre := range gl.livres {
-		if livre.EstDisponible() {
-			disponibles++
-		} else {
-			empruntes++
+		if livre.ASujet(id) {
+			utilisations++
 		}
 	}
-	stats["disponibles"] = disponibles
-	stats["empruntes"] = empruntes
+	if utilisations > 0 {
+		return fmt.Errorf("impossible de supprimer le genre '%s' car il est utilisé par %d livre(s)", genre.Nom, utilisations)
+	}
 
-	// Compter par genre
-	genresCount := make(map[string]int)
+	return gl.gestionnaireGenres.SupprimerGenre(id)
+}
+
+// ========================================
+// CODES-BARRES
+// ========================================
+
+// normaliserIdentifiants attribue le code par défaut aux livres enregistrés avant
+// l'introduction des étiquettes, et un UID à ceux enregistrés avant les UID
+func (gl *GestionnaireLivres) normaliserIdentifiants() error {
+	modifie := false
+	for i := range gl.livres {
+		livre := &gl.livres[i]
+		if livre.CodeBarres == "" {
+			livre.CodeBarres = models.CodeLivre(livre.ID)
+			modifie = true
+		}
+		if livre.UID == "" {
+			livre.UID = models.UIDAncien(livre.DateAjout, fmt.Sprintf("livre:%d:%s", livre.ID, livre.ISBN))
+			modifie = true
+		}
+	}
+
+	if modifie {
+		return gl.sauvegarderLivres()
+	}
+	return nil
+}
+
+// TrouverLivreParCode retrouve un livre à partir d'une saisie de comptoir :
+// code-barres scanné, ISBN (le code EAN imprimé au dos) ou ID tapé au clavier
+func (gl *GestionnaireLivres) TrouverLivreParCode(saisie string) (*models.Livre, int) {
+	code := models.NormaliserCode(saisie)
+	if code == "" {
+		return nil, -1
+	}
+
+	// Un code reçu d'un autre poste peut être aussi celui d'un livre d'ici :
+	// tous les postes retiennent le livre dont l'UID est le plus ancien
+	trouve := -1
+	for i, livre := range gl.livres {
+		if livre.CodeBarres == code && (trouve < 0 || livre.UID < gl.livres[trouve].UID) {
+			trouve = i
+		}
+	}
+	if trouve >= 0 {
+		return &gl.livres[trouve], trouve
+	}
+
+	if livre, index := gl.TrouverLivreParISBN(code); livre != nil {
+		return livre, index
+	}
+
+	if id, err := strconv.Atoi(code); err == nil {
+		return gl.TrouverLivreParID(id)
+	}
+	return nil, -1
+}
+
+// codePorte indique si un livre du catalogue porte déjà ce code-barres
+func (gl *GestionnaireLivres) codePorte(code string) bool {
 	for _, livre := range gl.livres {
-		genresCount[livre.Genre]++
+		if livre.CodeBarres == code {
+			return true
+		}
+	}
+	return false
+}
+
+// codeGenere retourne le code attribué par défaut à un livre créé sur ce poste
+func (gl *GestionnaireLivres) codeGenere(id int) string {
+	return models.CodeGenere(models.PREFIXE_CODE_LIVRE, gl.repere, id)
+}
+
+// AttribuerCodeBarres remplace le code d'un livre, par exemple pour reprendre
+// une étiquette déjà collée par un ancien système
+func (gl *GestionnaireLivres) AttribuerCodeBarres(id int, code string) error {
+	livre, index := gl.TrouverLivreParID(id)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", id)
+	}
+
+	code = models.NormaliserCode(code)
+	if !validators.ValiderCodeBarres(code) {
+		return fmt.Errorf("le code-barres '%s' est invalide (3 à 40 caractères ASCII, sans espace)", code)
+	}
+
+	// RÈGLE MÉTIER : les codes LIV suivis de chiffres sont réservés à la
+	// numérotation automatique ; seul le code d'origine du livre peut être repris
+	if models.EstCodeAutomatique(code, models.PREFIXE_CODE_LIVRE) && code != livre.CodeBarres &&
+		code != models.CodeLivre(id) && code != gl.codeGenere(id) {
+		return fmt.Errorf("le code-barres %s est réservé aux codes attribués automatiquement (%s suivi de chiffres)", code, models.PREFIXE_CODE_LIVRE)
+	}
+
+	// RÈGLE MÉTIER : un code-barres désigne un seul exemplaire
+	for _, autre := range gl.livres {
+		if autre.ID != id && autre.CodeBarres == code {
+			return fmt.Errorf("le code-barres %s est déjà attribué au livre '%s' (ID : %d)", code, autre.Titre, autre.ID)
+		}
+	}
+
+	livre.CodeBarres = code
+	gl.livres[index] = *livre
+
+	return gl.sauvegarderLivres()
+}
+
+// ========================================
+// CONTRIBUTEURS
+// ========================================
+
+// decouperNoms sépare une saisie "Auteur 1 ; Auteur 2" en noms individuels
+func decouperNoms(saisie string) []string {
+	var noms []string
+	for _, nom := range strings.Split(saisie, ";") {
+		if nom = strings.TrimSpace(nom); nom != "" {
+			noms = append(noms, nom)
+		}
+	}
+	return noms
+}
+
+func aContributeurParmi(livre models.Livre, contributeurIDs map[int]bool) bool {
+	for _, contribution := range livre.Contributions {
+		if contributeurIDs[contribution.ContributeurID] {
+			return true
+		}
+	}
+	return false
+}
+
+// contributionsAuteurs retrouve ou crée les fiches des auteurs donnés
+func (gl *GestionnaireLivres) contributionsAuteurs(noms []string) ([]models.Contribution, error) {
+	var contributions []models.Contribution
+	for _, nom := range noms {
+		contributeur, err := gl.gestionnaireContributeurs.ObtenirOuCreer(nom)
+		if err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, models.Contribution{
+			ContributeurID: contributeur.ID,
+			Role:           models.ROLE_AUTEUR,
+		})
+	}
+	return contributions, nil
+}
+
+// rafraichirAuteurs recalcule le champ Auteur à partir des contributions
+func (gl *GestionnaireLivres) rafraichirAuteurs(livre *models.Livre) {
+	var noms []string
+	for _, id := range livre.ContributeursParRole(models.ROLE_AUTEUR) {
+		if contributeur, _ := gl.gestionnaireContributeurs.TrouverContributeurParID(id); contributeur != nil {
+			noms = append(noms, contributeur.Nom)
+		}
+	}
+	livre.Auteur = strings.Join(noms, ", ")
+}
+
+// normaliserContributeurs crée les fiches manquantes pour les livres enregistrés
+// avant l'introduction des contributeurs et remet à jour les noms affichés
+func (gl *GestionnaireLivres) normaliserContributeurs() error {
+	modifie := false
+
+	for i := range gl.livres {
+		livre := &gl.livres[i]
+
+		// L'auteur d'un livre prêté par une autre bibliothèque reste en texte libre
+		if len(livre.Contributions) == 0 && !livre.EstPEB() && strings.TrimSpace(livre.Auteur) != "" {
+			for _, nom := range decouperNoms(livre.Auteur) {
+				contributeur := gl.gestionnaireContributeurs.Resoudre(nom)
+				if contributeur == nil {
+					// Un ancien nom refusé par le validateur reste en texte libre dans Auteur
+					var err error
+					contributeur, err = gl.gestionnaireContributeurs.AjouterContributeur(nom, "")
+					if err != nil {
+						continue
+					}
+				}
+				livre.Contributions = append(livre.Contributions, models.Contribution{
+					ContributeurID: contributeur.ID,
+					Role:           models.ROLE_AUTEUR,
+				})
+				modifie = true
+			}
+		}
+
+		if len(livre.Contributions) > 0 {
+			ancien := livre.Auteur
+			gl.rafraichirAuteurs(livre)
+			if livre.Auteur != ancien {
+				modifie = true
+			}
+		}
+	}
+
+	if modifie {
+		return gl.sauvegarderLivres()
 	}
-	stats["par_genre"] = genresCount
+	return nil
+}
 
-	// Livre le plus emprunté
-	var plusEmprunte models.Livre
-	maxEmprunts := -1
+// ListerLivresParContributeur retourne les livres auxquels le contributeur a participé,
+// y compris les livres retirés (ils restent liés à leurs contributeurs)
+func (gl *GestionnaireLivres) ListerLivresParContributeur(contributeurID int) []models.Livre {
+	var resultats []models.Livre
 	for _, livre := range gl.livres {
-		if livre.NombreEmprunts > maxEmprunts {
-			maxEmprunts = livre.NombreEmprunts
-			plusEmprunte = livre
+		if livre.AContributeur(contributeurID) {
+			resultats = append(resultats, livre)
 		}
 	}
-	if maxEmprunts > 0 {
-		stats["plus_emprunte"] = plusEmprunte
+	return resultats
+}
+
+// AjouterContribution associe un contributeur (créé si besoin) à un livre avec un rôle
+func (gl *GestionnaireLivres) AjouterContribution(livreID int, nom, role string) error {
+	livre, index := gl.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
 	}
 
-	return stats
+	if !validerRole(role) {
+		return fmt.Errorf("le rôle '%s' n'est pas reconnu", role)
+	}
+
+	contributeur, err := gl.gestionnaireContributeurs.ObtenirOuCreer(nom)
+	if err != nil {
+		return err
+	}
+
+	for _, contribution := range livre.Contributions {
+		if contribution.ContributeurID == contributeur.ID && contribution.Role == role {
+			return fmt.Errorf("%s est déjà %s de '%s'", contributeur.Nom, strings.ToLower(models.LibelleRole(role)), livre.Titre)
+		}
+	}
+
+	livre.Contributions = append(livre.Contributions, models.Contribution{
+		ContributeurID: contributeur.ID,
+		Role:           role,
+	})
+	gl.rafraichirAuteurs(livre)
+
+	gl.livres[index] = *livre
+	return gl.sauvegarderLivres()
+}
+
+// RetirerContribution retire un rôle d'un contributeur sur un livre. Un livre garde au moins un auteur.
+func (gl *GestionnaireLivres) RetirerContribution(livreID, contributeurID int, role string) error {
+	livre, index := gl.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	var restantes []models.Contribution
+	for _, contribution := range livre.Contributions {
+		if contribution.ContributeurID != contributeurID || contribution.Role != role {
+			restantes = append(restantes, contribution)
+		}
+	}
+
+	if len(restantes) == len(livre.Contributions) {
+		return fmt.Errorf("ce contributeur n'a pas le rôle '%s' sur le livre '%s'", role, livre.Titre)
+	}
+
+	if role == models.ROLE_AUTEUR && len(livre.ContributeursParRole(models.ROLE_AUTEUR)) == 1 {
+		return fmt.Errorf("impossible de retirer le dernier auteur du livre '%s'", livre.Titre)
+	}
+
+	livre.Contributions = restantes
+	gl.rafraichirAuteurs(livre)
+
+	gl.livres[index] = *livre
+	return gl.sauvegarderLivres()
+}
+
+// ModifierContributeur renomme une fiche et met à jour les livres concernés
+func (gl *GestionnaireLivres) ModifierContributeur(id int, nouveauNom, nouvelleCleTri string) error {
+	if err := gl.gestionnaireContributeurs.ModifierContributeur(id, nouveauNom, nouvelleCleTri); err != nil {
+		return err
+	}
+	return gl.normaliserContributeurs()
+}
+
+// SupprimerContributeur supprime une fiche qui n'est liée à aucun livre
+func (gl *GestionnaireLivres) SupprimerContributeur(id int) error {
+	contributeur, _ := gl.gestionnaireContributeurs.TrouverContributeurParID(id)
+	if contributeur == nil {
+		return fmt.Errorf("aucun contributeur trouvé avec l'ID %d", id)
+	}
+
+	// RÈGLE MÉTIER : on ne peut pas supprimer un contributeur encore lié à des livres
+	if livres := gl.ListerLivresParContributeur(id); len(livres) > 0 {
+		return fmt.Errorf("impossible de supprimer '%s' car il est lié à %d livre(s)", contributeur.Nom, len(livres))
+	}
+
+	return gl.gestionnaireContributeurs.SupprimerContributeur(id)
+}
+
+func validerRole(role string) bool {
+	for _, r := range models.ROLES_CONTRIBUTION {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// OEUVRES ET ÉDITIONS
+// ========================================
+
+// ListerLivresParOeuvre retourne toutes les éditions d'une oeuvre, y compris les livres retirés
+func (gl *GestionnaireLivres) ListerLivresParOeuvre(oeuvreID int) []models.Livre {
+	var editions []models.Livre
+	for _, livre := range gl.livres {
+		if oeuvreID != 0 && livre.OeuvreID == oeuvreID {
+			editions = append(editions, livre)
+		}
+	}
+	return editions
+}
+
+// DefinirOeuvre rattache un livre à une oeuvre (0 pour le détacher).
+// L'existence de l'oeuvre est vérifiée par GestionnaireSeries.
+func (gl *GestionnaireLivres) DefinirOeuvre(livreID, oeuvreID int, edition string) error {
+	livre, index := gl.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	livre.OeuvreID = oeuvreID
+	livre.Edition = strings.TrimSpace(edition)
+
+	gl.livres[index] = *livre
+	return gl.sauvegarderLivres()
+}
+
+// ========================================
+// SUCCURSALES ET TRANSFERTS
+// ========================================
+
+// rattacherSuccursale place dans le fonds de la succursale les livres qui
+// n'appartiennent encore à aucune, et retourne leur nombre
+func (gl *GestionnaireLivres) rattacherSuccursale(succursaleID int) (int, error) {
+	rattaches := 0
+	for i := range gl.livres {
+		livre := &gl.livres[i]
+		if livre.SuccursaleID != 0 {
+			continue
+		}
+		livre.SuccursaleID = succursaleID
+		if livre.EmplacementID == 0 {
+			livre.EmplacementID = succursaleID
+		}
+		rattaches++
+	}
+
+	if rattaches == 0 {
+		return 0, nil
+	}
+	return rattaches, gl.sauvegarderLivres()
+}
+
+// expedier met le livre en transit depuis la succursale où il se trouve
+func (gl *GestionnaireLivres) expedier(livre *models.Livre, vers int, motif string, reservationID int) {
+	livre.Transfert = &models.Transfert{
+		De:            livre.EmplacementID,
+		Vers:          vers,
+		Motif:         motif,
+		ReservationID: reservationID,
+		DateDepart:    time.Now(),
+	}
+}
+
+// ListerLivresEnTransit retourne les livres acheminés entre deux succursales,
+// les plus anciens départs d'abord
+func (gl *GestionnaireLivres) ListerLivresEnTransit() []models.Livre {
+	var enTransit []models.Livre
+	for _, livre := range gl.livresAuCatalogue() {
+		if livre.EstEnTransit() {
+			enTransit = append(enTransit, livre)
+		}
+	}
+	sort.SliceStable(enTransit, func(i, j int) bool {
+		return enTransit[i].Transfert.DateDepart.Before(enTransit[j].Transfert.DateDepart)
+	})
+	return enTransit
+}
+
+// exemplaires retourne les livres du catalogue qui portent le même ISBN que
+// celui donné, lui compris : ce sont des exemplaires d'un même titre
+func (gl *GestionnaireLivres) exemplaires(livre models.Livre) []models.Livre {
+	var exemplaires []models.Livre
+	for _, autre := range gl.livresAuCatalogue() {
+		if autre.ID == livre.ID || (livre.ISBN != "" && strings.EqualFold(autre.ISBN, livre.ISBN)) {
+			exemplaires = append(exemplaires, autre)
+		}
+	}
+	return exemplaires
 }
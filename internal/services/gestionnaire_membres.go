@@ -1,19 +1,30 @@
 package services
 
 import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
 	"github.com/felver-dev/bookstore/internal/storage"
 	"github.com/felver-dev/bookstore/internal/validators"
 )
 
 type GestionnaireMembres struct {
-	membres    []models.Membre
-	prochainID int
-	stockage   storage.Storage
+	membres        []models.Membre
+	sequence       *storage.Sequence
+	stockage       storage.Storage
+	limiteEmprunts int
+	repere         string // repère du poste dans les cartes générées, vide tant qu'il ne se synchronise pas
+
+	gestionnaireSuccursales *GestionnaireSuccursales
 }
 
 func (gm *GestionnaireMembres) SauvegarderMembres() error {
@@ -27,25 +38,35 @@ func (gm *GestionnaireMembres) ChargerMembres() error {
 	}
 
 	for _, membre := range gm.membres {
-		if membre.ID >= gm.prochainID {
-			gm.prochainID = membre.ID
-		}
+		gm.sequence.Observer(membre.ID)
 	}
 
 	return nil
 }
 
-func NouveauGestionnaireMembres(stokage storage.Storage) *GestionnaireMembres {
+func NouveauGestionnaireMembres(stokage storage.Storage, sequences *storage.Sequences, limiteEmprunts int, gsu *GestionnaireSuccursales) *GestionnaireMembres {
 	gm := &GestionnaireMembres{
-		membres:    make([]models.Membre, 0),
-		prochainID: 1,
-		stockage:   stokage,
+		membres:        make([]models.Membre, 0),
+		sequence:       sequences.Sequence("membres"),
+		stockage:       stokage,
+		limiteEmprunts: limiteEmprunts,
+
+		gestionnaireSuccursales: gsu,
 	}
 
 	gm.ChargerMembres()
+	gm.normaliserIdentifiants() // Attribuer une carte et un UID aux membres qui n'en ont pas
+	if gsu.EstEnReseau() {
+		gm.rattacherSuccursale(gsu.ListerSuccursales()[0].ID) // Les membres inscrits hors réseau vont à la première succursale
+	}
 	return gm
 }
 
+// LimiteEmprunts retourne le nombre maximum d'emprunts simultanés par membre
+func (gm *GestionnaireMembres) LimiteEmprunts() int {
+	return gm.limiteEmprunts
+}
+
 func (gm *GestionnaireMembres) AjouterMembre(nom, email, telephone string) error {
 	if !validators.ValiderNom(nom) {
 		return fmt.Errorf("le nom du membre est invalide")
@@ -61,37 +82,48 @@ func (gm *GestionnaireMembres) AjouterMembre(nom, email, telephone string) error
 
 	for _, membre := range gm.membres {
 		if strings.EqualFold(membre.Email, email) {
+			if membre.EstRetire() {
+				return fmt.Errorf("un membre radié utilise déjà l'email %s (ID: %d %s), restaurez-le plutôt", email, membre.ID, membre.Nom)
+			}
 			return fmt.Errorf("un membre avec l'email %s existe déjà (ID: %d %s)", email, membre.ID, membre.Nom)
 		}
 	}
 
+	id, err := gm.sequence.Attribuer()
+	if err != nil {
+		return err
+	}
+
 	maintenant := time.Now()
 	nouveauMembre := models.Membre{
-		ID:              gm.prochainID,
+		ID:              id,
+		UID:             models.NouvelUID(),
 		Nom:             strings.TrimSpace(nom),
 		Email:           strings.ToLower(strings.TrimSpace(email)), // Email en minuscules
 		Telephone:       strings.TrimSpace(telephone),
+		NumeroCarte:     gm.numeroGenere(id),
 		DateInscription: maintenant,
 		NombreEmprunts:  0,    // Aucun emprunt au début
 		EmpruntsActifs:  0,    // Aucun emprunt actif au début
 		Actif:           true, // Membre actif par défaut
+		SuccursaleID:    gm.gestionnaireSuccursales.CouranteID(),
 	}
 
 	gm.membres = append(gm.membres, nouveauMembre)
-	gm.prochainID++
 
 	return gm.SauvegarderMembres()
 
 }
 
+// ListerMembres retourne les membres inscrits (les membres radiés sont masqués)
 func (gm *GestionnaireMembres) ListerMembres() []models.Membre {
-	return gm.membres
+	return gm.membresInscrits()
 }
 
 func (gm *GestionnaireMembres) ListerMembresActifs() []models.Membre {
 	var actifs []models.Membre
 
-	for _, membre := range gm.membres {
+	for _, membre := range gm.membresInscrits() {
 		if membre.Actif {
 			actifs = append(actifs, membre)
 		}
@@ -104,7 +136,7 @@ func (gm *GestionnaireMembres) RechercherMembres(terme string) []models.Membre {
 	var resultats []models.Membre
 	terme = strings.ToLower(terme)
 
-	for _, membre := range gm.membres {
+	for _, membre := range gm.membresInscrits() {
 		if strings.Contains(strings.ToLower(membre.Nom), terme) ||
 			strings.Contains(strings.ToLower(membre.Email), terme) {
 			resultats = append(resultats, membre)
@@ -123,6 +155,19 @@ func (gm *GestionnaireMembres) TrouverMembreParID(id int) (*models.Membre, int)
 	return nil, -1
 }
 
+// trouverMembreParUID retrouve un membre par son UID, le même sur tous les postes
+func (gm *GestionnaireMembres) trouverMembreParUID(uid string) *models.Membre {
+	if uid == "" {
+		return nil
+	}
+	for i, membre := range gm.membres {
+		if membre.UID == uid {
+			return &gm.membres[i]
+		}
+	}
+	return nil
+}
+
 func (gm *GestionnaireMembres) TrouverMembreParEmail(email string) (*models.Membre, int) {
 	emailNettoye := strings.ToLower(strings.TrimSpace(email))
 
@@ -176,54 +221,96 @@ func (gm *GestionnaireMembres) ModifierMembre(id int, nouveauNom, nouvelEmail, n
 	return gm.SauvegarderMembres()
 }
 
-func (gm *GestionnaireMembres) SuspendirMembre(id int) error {
+// RadierMembre désinscrit un membre sans l'effacer : l'historique de ses emprunts est conservé
+func (gm *GestionnaireMembres) RadierMembre(id int, raison string) error {
 	membre, index := gm.TrouverMembreParID(id)
 	if membre == nil {
 		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	if !membre.Actif {
-		return fmt.Errorf("le membre %s est déjà suspendu", membre.Nom)
+	if membre.EstRetire() {
+		return fmt.Errorf("le membre %s est déjà radié (%s)", membre.Nom, membre.Retrait.String())
+	}
+
+	// RÈGLE MÉTIER : On ne peut pas radier un membre qui a des emprunts en cours
+	if membre.EmpruntsActifs > 0 {
+		return fmt.Errorf("impossible de radier le membre '%s' car il a %d emprunt(s) en cours",
+			membre.Nom, membre.EmpruntsActifs)
 	}
 
-	membre.Suspendre()
+	membre.Retrait = &models.Retrait{
+		Motif:  models.MOTIF_RADIE,
+		Date:   time.Now(),
+		Raison: strings.TrimSpace(raison),
+	}
 	gm.membres[index] = *membre
 
 	return gm.SauvegarderMembres()
 }
 
-func (gm *GestionnaireMembres) ReactiverMembre(id int) error {
+// RestaurerMembre réinscrit un membre radié
+func (gm *GestionnaireMembres) RestaurerMembre(id int) error {
 	membre, index := gm.TrouverMembreParID(id)
 	if membre == nil {
 		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
 	}
 
-	if membre.Actif {
-		return fmt.Errorf("le membre %s est déjà actif", membre.Nom)
+	if !membre.EstRetire() {
+		return fmt.Errorf("le membre %s n'est pas radié", membre.Nom)
 	}
 
-	membre.Reactiver()
+	membre.Retrait = nil
 	gm.membres[index] = *membre
 
 	return gm.SauvegarderMembres()
 }
 
-func (gm *GestionnaireMembres) SupprimerMembre(id int) error {
-	membre, index := gm.TrouverMembreParID(id)
-	if membre == nil {
-		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
+// ListerMembresRadies retourne les membres désinscrits
+func (gm *GestionnaireMembres) ListerMembresRadies() []models.Membre {
+	var radies []models.Membre
+	for _, membre := range gm.membres {
+		if membre.EstRetire() {
+			radies = append(radies, membre)
+		}
 	}
+	return radies
+}
 
-	// RÈGLE MÉTIER : On ne peut pas supprimer un membre qui a des emprunts en cours
-	if membre.EmpruntsActifs > 0 {
-		return fmt.Errorf("impossible de supprimer le membre '%s' car il a %d emprunt(s) en cours",
-			membre.Nom, membre.EmpruntsActifs)
+// PurgerMembresRadies supprime définitivement les membres radiés depuis plus de
+// conservationJours jours. Sans règle de conservation (0), rien n'est purgé.
+func (gm *GestionnaireMembres) PurgerMembresRadies(conservationJours int) ([]models.Membre, error) {
+	if conservationJours <= 0 {
+		return nil, fmt.Errorf("aucune règle de conservation des membres radiés n'est définie")
 	}
 
-	// Supprimer le membre de la liste
-	gm.membres = append(gm.membres[:index], gm.membres[index+1:]...)
+	dateLimite := time.Now().AddDate(0, 0, -conservationJours)
+	var aGarder, purges []models.Membre
 
-	return gm.SauvegarderMembres()
+	for _, membre := range gm.membres {
+		if membre.EstRetire() && membre.Retrait.Date.Before(dateLimite) {
+			purges = append(purges, membre)
+		} else {
+			aGarder = append(aGarder, membre)
+		}
+	}
+
+	if len(purges) == 0 {
+		return nil, nil
+	}
+
+	gm.membres = aGarder
+	return purges, gm.SauvegarderMembres()
+}
+
+// membresInscrits retourne les membres qui n'ont pas été radiés
+func (gm *GestionnaireMembres) membresInscrits() []models.Membre {
+	var inscrits []models.Membre
+	for _, membre := range gm.membres {
+		if !membre.EstRetire() {
+			inscrits = append(inscrits, membre)
+		}
+	}
+	return inscrits
 }
 
 func (gm *GestionnaireMembres) AjouterEmpruntAuMembre(id int) error {
@@ -232,12 +319,12 @@ func (gm *GestionnaireMembres) AjouterEmpruntAuMembre(id int) error {
 		return fmt.Errorf("membre ID %d introuvable", id)
 	}
 
-	if !membre.PeutEmprunter() {
+	if !membre.PeutEmprunter(gm.limiteEmprunts) {
 		if !membre.Actif {
 			return fmt.Errorf("le membre %s est suspendu", membre.Nom)
 		}
 		return fmt.Errorf("le membre %s a atteint la limite de %d emprunts simultanés",
-			membre.Nom, models.LIMIT_EMPRUNTS_SIMULTANES)
+			membre.Nom, gm.limiteEmprunts)
 	}
 
 	membre.AjouterEmprunt()
@@ -258,41 +345,344 @@ func (gm *GestionnaireMembres) RetirerEmpruntDuMembre(id int) error {
 	return gm.SauvegarderMembres()
 }
 
-func (gm *GestionnaireMembres) ObtenirStatistiques() map[string]interface{} {
-	stats := make(map[string]interface{})
-
-	total := len(gm.membres)
-	stats["total"] = total
-
-	if total == 0 {
-		return stats
+// ObtenirStatistiques décrit les inscrits actuels ; la période ne sert qu'à compter les inscriptions.
+// Avec une succursale (ID non nul), seuls ses inscrits sont comptés.
+func (gm *GestionnaireMembres) ObtenirStatistiques(periode statistiques.Periode, succursaleID int) statistiques.Membres {
+	var inscrits []models.Membre
+	radies := 0
+	for _, membre := range gm.membres {
+		if succursaleID != 0 && membre.SuccursaleID != succursaleID {
+			continue
+		}
+		if membre.EstRetire() {
+			radies++
+		} else {
+			inscrits = append(inscrits, membre)
+		}
+	}
+	stats := statistiques.Membres{
+		Periode:      periode,
+		SuccursaleID: succursaleID,
+		Total:        len(inscrits),
+		Radies:       radies,
 	}
 
-	// Compter les membres actifs et suspendus
-	actifs := 0
-	suspendus := 0
-	for _, membre := range gm.membres {
+	for _, membre := range inscrits {
+		// Compter les membres actifs et suspendus
 		if membre.Actif {
-			actifs++
+			stats.Actifs++
 		} else {
-			suspendus++
+			stats.Suspendus++
+		}
+
+		if periode.Contient(membre.DateInscription) {
+			stats.Inscrits++
+		}
+
+		// Membre le plus actif (celui qui a emprunté le plus de livres)
+		if membre.NombreEmprunts > 0 && (stats.PlusActif == nil || membre.NombreEmprunts > stats.PlusActif.Emprunts) {
+			stats.PlusActif = &statistiques.Classement{ID: membre.ID, Nom: membre.Nom, Emprunts: membre.NombreEmprunts}
+		}
+	}
+
+	return stats
+}
+
+// ========================================
+// SUCCURSALES
+// ========================================
+
+// rattacherSuccursale inscrit à la succursale les membres qui ne le sont
+// encore à aucune, et retourne leur nombre
+func (gm *GestionnaireMembres) rattacherSuccursale(succursaleID int) (int, error) {
+	rattaches := 0
+	for i := range gm.membres {
+		if gm.membres[i].SuccursaleID == 0 {
+			gm.membres[i].SuccursaleID = succursaleID
+			rattaches++
 		}
 	}
-	stats["actifs"] = actifs
-	stats["suspendus"] = suspendus
 
-	// Membre le plus actif (celui qui a emprunté le plus de livres)
-	var plusActif models.Membre
-	maxEmprunts := -1
+	if rattaches == 0 {
+		return 0, nil
+	}
+	return rattaches, gm.SauvegarderMembres()
+}
+
+// ChangerSuccursale inscrit le membre dans une autre succursale : c'est là
+// que ses prochaines réservations l'attendront
+func (gm *GestionnaireMembres) ChangerSuccursale(id, succursaleID int) error {
+	membre, index := gm.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("membre ID %d introuvable", id)
+	}
+	succursale := gm.gestionnaireSuccursales.TrouverSuccursaleParID(succursaleID)
+	if succursale == nil {
+		return fmt.Errorf("succursale ID %d introuvable", succursaleID)
+	}
+	if membre.SuccursaleID == succursaleID {
+		return fmt.Errorf("%s est déjà inscrit(e) à %s", membre.Nom, succursale.Nom)
+	}
+
+	gm.membres[index].SuccursaleID = succursaleID
+	return gm.SauvegarderMembres()
+}
+
+// ========================================
+// CARTES DE MEMBRE
+// ========================================
+
+// normaliserIdentifiants attribue le numéro par défaut aux membres inscrits
+// avant l'introduction des cartes, et un UID à ceux inscrits avant les UID
+func (gm *GestionnaireMembres) normaliserIdentifiants() error {
+	modifie := false
+	for i := range gm.membres {
+		membre := &gm.membres[i]
+		if membre.NumeroCarte == "" {
+			membre.NumeroCarte = models.NumeroCarte(membre.ID)
+			modifie = true
+		}
+		if membre.UID == "" {
+			membre.UID = models.UIDAncien(membre.DateInscription, fmt.Sprintf("membre:%d:%s", membre.ID, membre.Email))
+			modifie = true
+		}
+	}
+
+	if modifie {
+		return gm.SauvegarderMembres()
+	}
+	return nil
+}
+
+// TrouverMembreParCarte retrouve un membre par sa carte scannée ou son ID tapé au clavier
+func (gm *GestionnaireMembres) TrouverMembreParCarte(saisie string) (*models.Membre, int) {
+	numero := models.NormaliserCode(saisie)
+	if numero == "" {
+		return nil, -1
+	}
+
+	// Une carte reçue d'un autre poste peut être aussi celle d'un membre d'ici :
+	// tous les postes retiennent le membre dont l'UID est le plus ancien
+	trouve := -1
+	for i, membre := range gm.membres {
+		if membre.NumeroCarte == numero && (trouve < 0 || membre.UID < gm.membres[trouve].UID) {
+			trouve = i
+		}
+	}
+	if trouve >= 0 {
+		return &gm.membres[trouve], trouve
+	}
+
+	if id, err := strconv.Atoi(numero); err == nil {
+		return gm.TrouverMembreParID(id)
+	}
+	return nil, -1
+}
+
+// cartePortee indique si un membre porte déjà ce numéro de carte
+func (gm *GestionnaireMembres) cartePortee(numero string) bool {
 	for _, membre := range gm.membres {
-		if membre.NombreEmprunts > maxEmprunts {
-			maxEmprunts = membre.NombreEmprunts
-			plusActif = membre
+		if membre.NumeroCarte == numero {
+			return true
 		}
 	}
-	if maxEmprunts > 0 {
-		stats["plus_actif"] = plusActif
+	return false
+}
+
+// numeroGenere retourne la carte attribuée par défaut à un membre inscrit sur ce poste
+func (gm *GestionnaireMembres) numeroGenere(id int) string {
+	return models.CodeGenere(models.PREFIXE_CARTE_MEMBRE, gm.repere, id)
+}
+
+// AttribuerNumeroCarte remplace le numéro de carte d'un membre (carte perdue, ancienne carte...)
+func (gm *GestionnaireMembres) AttribuerNumeroCarte(id int, numero string) error {
+	membre, index := gm.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
+	}
+
+	numero = models.NormaliserCode(numero)
+	if !validators.ValiderCodeBarres(numero) {
+		return fmt.Errorf("le numéro de carte '%s' est invalide (3 à 40 caractères ASCII, sans espace)", numero)
 	}
 
-	return stats
+	// RÈGLE MÉTIER : les numéros MEM suivis de chiffres sont réservés à la
+	// numérotation automatique ; seul le numéro d'origine du membre peut être repris
+	if models.EstCodeAutomatique(numero, models.PREFIXE_CARTE_MEMBRE) && numero != membre.NumeroCarte &&
+		numero != models.NumeroCarte(id) && numero != gm.numeroGenere(id) {
+		return fmt.Errorf("le numéro de carte %s est réservé aux cartes attribuées automatiquement (%s suivi de chiffres)", numero, models.PREFIXE_CARTE_MEMBRE)
+	}
+
+	// RÈGLE MÉTIER : une carte identifie un seul membre
+	for _, autre := range gm.membres {
+		if autre.ID != id && autre.NumeroCarte == numero {
+			return fmt.Errorf("la carte %s est déjà attribuée à %s (ID : %d)", numero, autre.Nom, autre.ID)
+		}
+	}
+
+	membre.NumeroCarte = numero
+	gm.membres[index] = *membre
+
+	return gm.SauvegarderMembres()
+}
+
+// ========================================
+// CODE PIN DU LIBRE-SERVICE
+// ========================================
+
+const (
+	// ESSAIS_PIN_MAX essais ratés consécutifs bloquent la carte jusqu'à ce que
+	// l'accueil définisse un nouveau code
+	ESSAIS_PIN_MAX = 5
+
+	iterationsPIN = 100000
+)
+
+// DefinirPIN enregistre l'empreinte d'un nouveau code PIN et débloque la carte
+func (gm *GestionnaireMembres) DefinirPIN(id int, pin string) error {
+	membre, index := gm.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
+	}
+
+	if !validators.ValiderPIN(pin) {
+		return fmt.Errorf("le code PIN doit contenir de 4 à 8 chiffres")
+	}
+
+	empreinte, err := hacherSecret(pin)
+	if err != nil {
+		return err
+	}
+
+	membre.PIN = empreinte
+	membre.EchecsPIN = 0
+	gm.membres[index] = *membre
+
+	return gm.SauvegarderMembres()
+}
+
+// AuthentifierMembre vérifie la carte et le code PIN saisis au libre-service.
+// Le message d'erreur ne dit pas si c'est la carte ou le code qui est faux.
+func (gm *GestionnaireMembres) AuthentifierMembre(carte, pin string) (*models.Membre, error) {
+	membre, index := gm.TrouverMembreParCarte(carte)
+	if membre == nil || membre.EstRetire() {
+		return nil, fmt.Errorf("carte ou code PIN incorrect")
+	}
+
+	if membre.PIN == "" {
+		return nil, fmt.Errorf("aucun code PIN n'est défini pour cette carte, adressez-vous à l'accueil")
+	}
+
+	// RÈGLE MÉTIER : la carte est bloquée après trop d'essais ratés
+	if membre.EchecsPIN >= ESSAIS_PIN_MAX {
+		return nil, fmt.Errorf("carte bloquée après %d essais ratés, adressez-vous à l'accueil", ESSAIS_PIN_MAX)
+	}
+
+	if !verifierSecret(membre.PIN, pin) {
+		membre.EchecsPIN++
+		gm.membres[index] = *membre
+		if err := gm.SauvegarderMembres(); err != nil {
+			return nil, err
+		}
+
+		if restants := ESSAIS_PIN_MAX - membre.EchecsPIN; restants > 0 {
+			return nil, fmt.Errorf("carte ou code PIN incorrect (%d essai(s) restant(s))", restants)
+		}
+		return nil, fmt.Errorf("carte bloquée après %d essais ratés, adressez-vous à l'accueil", ESSAIS_PIN_MAX)
+	}
+
+	if membre.EchecsPIN > 0 {
+		membre.EchecsPIN = 0
+		gm.membres[index] = *membre
+		if err := gm.SauvegarderMembres(); err != nil {
+			return nil, err
+		}
+	}
+
+	return membre, nil
+}
+
+// ========================================
+// MOT DE PASSE DU PORTAIL
+// ========================================
+
+// DefinirMotDePasse enregistre l'empreinte du mot de passe du portail des membres
+func (gm *GestionnaireMembres) DefinirMotDePasse(id int, motDePasse string) error {
+	membre, index := gm.TrouverMembreParID(id)
+	if membre == nil {
+		return fmt.Errorf("aucun membre trouvé avec l'ID %d", id)
+	}
+
+	if !validators.ValiderMotDePasse(motDePasse) {
+		return fmt.Errorf("le mot de passe doit contenir de 8 à 128 caractères")
+	}
+
+	empreinte, err := hacherSecret(motDePasse)
+	if err != nil {
+		return err
+	}
+
+	membre.MotDePasse = empreinte
+	gm.membres[index] = *membre
+
+	return gm.SauvegarderMembres()
+}
+
+// AuthentifierParEmail vérifie l'email et le mot de passe saisis sur le portail.
+// Comme au libre-service, l'erreur ne dit pas lequel des deux est faux ;
+// la limitation des essais est faite par le portail.
+func (gm *GestionnaireMembres) AuthentifierParEmail(email, motDePasse string) (*models.Membre, error) {
+	membre, _ := gm.TrouverMembreParEmail(email)
+	if membre == nil || membre.EstRetire() || membre.MotDePasse == "" || !verifierSecret(membre.MotDePasse, motDePasse) {
+		return nil, fmt.Errorf("email ou mot de passe incorrect")
+	}
+	return membre, nil
+}
+
+// ========================================
+// EMPREINTES DES SECRETS (PIN ET MOT DE PASSE)
+// ========================================
+
+// hacherSecret calcule l'empreinte "pbkdf2-sha256$iterations$sel$empreinte" d'un secret avec un sel aléatoire
+func hacherSecret(secret string) (string, error) {
+	sel := make([]byte, 16)
+	if _, err := rand.Read(sel); err != nil {
+		return "", fmt.Errorf("impossible de générer le sel : %v", err)
+	}
+	empreinte, err := empreinteSecret(secret, sel, iterationsPIN)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", iterationsPIN, hex.EncodeToString(sel), empreinte), nil
+}
+
+func empreinteSecret(secret string, sel []byte, iterations int) (string, error) {
+	cle, err := pbkdf2.Key(sha256.New, secret, sel, iterations, 32)
+	if err != nil {
+		return "", fmt.Errorf("impossible de calculer l'empreinte : %v", err)
+	}
+	return hex.EncodeToString(cle), nil
+}
+
+// verifierSecret compare un secret saisi à une empreinte "pbkdf2-sha256$iterations$sel$empreinte"
+func verifierSecret(enregistre, secret string) bool {
+	parties := strings.Split(enregistre, "$")
+	if len(parties) != 4 || parties[0] != "pbkdf2-sha256" {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parties[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	sel, err := hex.DecodeString(parties[2])
+	if err != nil {
+		return false
+	}
+
+	empreinte, err := empreinteSecret(secret, sel, iterations)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(empreinte), []byte(parties[3])) == 1
 }
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// PRÊTS ENTRE BIBLIOTHÈQUES (PEB)
+// Un membre demande un livre que la bibliothèque ne possède pas ; une autre
+// bibliothèque le prête. À la réception, l'exemplaire entre au catalogue avec
+// son propre code-barres pour passer par le comptoir comme les autres : il
+// n'est prêté qu'au membre qui l'a demandé, jusqu'à la date fixée par le
+// prêteur. Il quitte le catalogue quand il est renvoyé.
+// ========================================
+
+type GestionnairePEB struct {
+	demandes             []models.DemandePEB
+	sequence             *storage.Sequence
+	stockage             storage.Storage
+	gestionnaireEmprunts *GestionnaireEmprunts
+}
+
+func (gp *GestionnairePEB) sauvegarderDemandes() error {
+	return gp.stockage.Sauvegarder(gp.demandes)
+}
+
+func (gp *GestionnairePEB) ChargerDemandes() error {
+	err := gp.stockage.Charger(&gp.demandes)
+	if err != nil {
+		return err
+	}
+
+	for _, demande := range gp.demandes {
+		gp.sequence.Observer(demande.ID)
+	}
+
+	return nil
+}
+
+// NouveauGestionnairePEB charge les demandes et se branche sur les emprunts :
+// un exemplaire prêté par une autre bibliothèque suit ses propres règles
+func NouveauGestionnairePEB(stockage storage.Storage, sequences *storage.Sequences, ge *GestionnaireEmprunts) *GestionnairePEB {
+	gp := &GestionnairePEB{
+		demandes:             make([]models.DemandePEB, 0),
+		sequence:             sequences.Sequence("peb"),
+		stockage:             stockage,
+		gestionnaireEmprunts: ge,
+	}
+
+	gp.ChargerDemandes()
+	ge.gestionnairePEB = gp
+	return gp
+}
+
+// ========================================
+// SUIVI DES DEMANDES
+// ========================================
+
+// DemanderPEB enregistre la demande d'un membre. L'ISBN et l'auteur sont
+// facultatifs ; dateBesoin (zéro si le membre n'est pas pressé) ne peut pas
+// être passée.
+func (gp *GestionnairePEB) DemanderPEB(membreID int, titre, auteur, isbn string, dateBesoin time.Time) (*models.DemandePEB, error) {
+	ge := gp.gestionnaireEmprunts
+	membre, _ := ge.gestionnaireMembres.TrouverMembreParID(membreID)
+	if membre == nil {
+		return nil, fmt.Errorf("membre ID %d introuvable", membreID)
+	}
+	if membre.EstRetire() {
+		return nil, fmt.Errorf("le membre %s a été radié", membre.Nom)
+	}
+
+	titre = strings.TrimSpace(titre)
+	if !validators.ValiderTitre(titre) {
+		return nil, fmt.Errorf("le titre du livre est invalide")
+	}
+
+	isbn = strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(isbn), "-", ""), " ", "")
+	if isbn != "" {
+		if !validators.ValiderISBN(isbn) {
+			return nil, fmt.Errorf("l'ISBN est invalide (doit faire 10 ou 13 caractères)")
+		}
+		// RÈGLE MÉTIER : un livre du fonds se réserve, il ne se demande pas ailleurs
+		for _, livre := range ge.gestionnaireLivres.livresAuCatalogue() {
+			if !livre.EstPEB() && strings.EqualFold(livre.ISBN, isbn) {
+				return nil, fmt.Errorf("la bibliothèque possède déjà « %s » (ID : %d), réservez-le plutôt", livre.Titre, livre.ID)
+			}
+		}
+	}
+
+	maintenant := time.Now()
+	if !dateBesoin.IsZero() && dateBesoin.Before(debutDuJour(maintenant)) {
+		return nil, fmt.Errorf("la date de besoin %s est déjà passée", dateBesoin.Format("02/01/2006"))
+	}
+
+	id, err := gp.sequence.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gp.demandes = append(gp.demandes, models.DemandePEB{
+		ID:          id,
+		MembreID:    membre.ID,
+		NomMembre:   membre.Nom,
+		Titre:       titre,
+		Auteur:      strings.TrimSpace(auteur),
+		ISBN:        isbn,
+		DateBesoin:  dateBesoin,
+		DateDemande: maintenant,
+		Statut:      models.PEB_DEMANDEE,
+		Historique:  []models.EtapePEB{{Statut: models.PEB_DEMANDEE, Date: maintenant}},
+		Couts:       []models.CoutPEB{},
+	})
+	if err := gp.sauvegarderDemandes(); err != nil {
+		return nil, err
+	}
+
+	demande, _ := gp.TrouverDemandeParID(id)
+	return demande, nil
+}
+
+// NoterExpedition indique que le prêteur a envoyé le livre. Le prêteur peut
+// être précisé à cette étape s'il ne l'a pas été avant.
+func (gp *GestionnairePEB) NoterExpedition(id int, preteur string) error {
+	demande, err := gp.demandeAPasser(id, models.PEB_EXPEDIEE)
+	if err != nil {
+		return err
+	}
+	if err := gp.definirPreteur(demande, preteur); err != nil {
+		return err
+	}
+
+	gp.changerStatut(demande, models.PEB_EXPEDIEE, "")
+	return gp.sauvegarderDemandes()
+}
+
+// Receptionner enregistre l'arrivée du livre et l'entre temporairement au
+// catalogue. dateRetourPreteur est la date à laquelle le prêteur veut le
+// récupérer : elle devient l'échéance de l'emprunt du membre.
+func (gp *GestionnairePEB) Receptionner(id int, preteur string, dateRetourPreteur time.Time) (models.Livre, error) {
+	demande, err := gp.demandeAPasser(id, models.PEB_RECUE)
+	if err != nil {
+		return models.Livre{}, err
+	}
+	if err := gp.definirPreteur(demande, preteur); err != nil {
+		return models.Livre{}, err
+	}
+	if !dateRetourPreteur.After(time.Now()) {
+		return models.Livre{}, fmt.Errorf("la date de retour fixée par le prêteur doit être dans le futur")
+	}
+
+	livre, err := gp.gestionnaireEmprunts.gestionnaireLivres.ajouterLivrePEB(*demande)
+	if err != nil {
+		return models.Livre{}, err
+	}
+
+	demande.DateRetourPreteur = dateRetourPreteur
+	demande.LivreID = livre.ID
+	gp.changerStatut(demande, models.PEB_RECUE, fmt.Sprintf("à rendre à %s le %s", demande.Preteur, dateRetourPreteur.Format("02/01/2006")))
+	return livre, gp.sauvegarderDemandes()
+}
+
+// Renvoyer clôt la demande : le livre repart chez le prêteur et quitte le
+// catalogue. Ses emprunts restent dans l'historique.
+func (gp *GestionnairePEB) Renvoyer(id int) error {
+	demande, err := gp.demandeAPasser(id, models.PEB_RENVOYEE)
+	if err != nil {
+		return err
+	}
+
+	if err := gp.gestionnaireEmprunts.gestionnaireLivres.supprimerLivrePEB(demande.LivreID); err != nil {
+		return err
+	}
+
+	gp.changerStatut(demande, models.PEB_RENVOYEE, "à "+demande.Preteur)
+	return gp.sauvegarderDemandes()
+}
+
+// Annuler abandonne une demande dont le livre n'est pas encore arrivé
+func (gp *GestionnairePEB) Annuler(id int, raison string) error {
+	demande, err := gp.demandeAPasser(id, models.PEB_ANNULEE)
+	if err != nil {
+		return err
+	}
+
+	gp.changerStatut(demande, models.PEB_ANNULEE, strings.TrimSpace(raison))
+	return gp.sauvegarderDemandes()
+}
+
+// AjouterCout enregistre une dépense de la demande. Les factures arrivant
+// souvent après le renvoi, une demande close accepte encore des coûts.
+func (gp *GestionnairePEB) AjouterCout(id int, libelle string, montant models.Montant) error {
+	demande, _ := gp.TrouverDemandeParID(id)
+	if demande == nil {
+		return fmt.Errorf("demande de prêt entre bibliothèques ID %d introuvable", id)
+	}
+
+	libelle = strings.TrimSpace(libelle)
+	if libelle == "" {
+		return fmt.Errorf("le libellé du coût ne peut pas être vide")
+	}
+	if montant <= 0 {
+		return fmt.Errorf("le montant doit être positif")
+	}
+
+	demande.Couts = append(demande.Couts, models.CoutPEB{Libelle: libelle, Montant: montant, Date: time.Now()})
+	return gp.sauvegarderDemandes()
+}
+
+// ListerDemandes retourne les demandes en cours, ou toutes, des plus anciennes aux plus récentes
+func (gp *GestionnairePEB) ListerDemandes(toutes bool) []models.DemandePEB {
+	var demandes []models.DemandePEB
+	for _, demande := range gp.demandes {
+		if toutes || !demande.EstClose() {
+			demandes = append(demandes, demande)
+		}
+	}
+	return demandes
+}
+
+func (gp *GestionnairePEB) TrouverDemandeParID(id int) (*models.DemandePEB, int) {
+	for i, demande := range gp.demandes {
+		if demande.ID == id {
+			return &gp.demandes[i], i
+		}
+	}
+	return nil, -1
+}
+
+// demandeAPasser retrouve la demande et vérifie qu'elle peut prendre le statut donné
+func (gp *GestionnairePEB) demandeAPasser(id int, statut string) (*models.DemandePEB, error) {
+	demande, _ := gp.TrouverDemandeParID(id)
+	if demande == nil {
+		return nil, fmt.Errorf("demande de prêt entre bibliothèques ID %d introuvable", id)
+	}
+	if !demande.PeutPasserA(statut) {
+		return nil, fmt.Errorf("la demande #%d est au statut « %s » : elle ne peut pas passer à « %s »",
+			demande.ID, models.LibelleStatutPEB(demande.Statut), models.LibelleStatutPEB(statut))
+	}
+	return demande, nil
+}
+
+// definirPreteur remplace le prêteur s'il est saisi ; il doit être connu au plus tard à l'envoi
+func (gp *GestionnairePEB) definirPreteur(demande *models.DemandePEB, preteur string) error {
+	if preteur = strings.TrimSpace(preteur); preteur != "" {
+		demande.Preteur = preteur
+	}
+	if demande.Preteur == "" {
+		return fmt.Errorf("la bibliothèque prêteuse doit être indiquée")
+	}
+	return nil
+}
+
+func (gp *GestionnairePEB) changerStatut(demande *models.DemandePEB, statut, note string) {
+	demande.Statut = statut
+	demande.Historique = append(demande.Historique, models.EtapePEB{Statut: statut, Date: time.Now(), Note: note})
+}
+
+// debutDuJour retourne minuit le jour de la date donnée
+func debutDuJour(date time.Time) time.Time {
+	annee, mois, jour := date.Date()
+	return time.Date(annee, mois, jour, 0, 0, 0, 0, date.Location())
+}
+
+// ========================================
+// LIEN AVEC LES EMPRUNTS
+// Appelés par le gestionnaire des emprunts avec la demande de l'exemplaire
+// (voir demandePEB).
+// ========================================
+
+// echeanceEmprunt vérifie que le membre peut emprunter l'exemplaire de la
+// demande et retourne la date à laquelle il devra le rendre : celle fixée par le prêteur
+func (gp *GestionnairePEB) echeanceEmprunt(demande *models.DemandePEB, membreID int) (time.Time, error) {
+	// RÈGLE MÉTIER : le livre est prêté par l'autre bibliothèque pour un membre précis
+	if demande.MembreID != membreID {
+		return time.Time{}, fmt.Errorf("le livre '%s' a été demandé à %s pour %s : seul ce membre peut l'emprunter", demande.Titre, demande.Preteur, demande.NomMembre)
+	}
+	if demande.Statut != models.PEB_RECUE {
+		return time.Time{}, fmt.Errorf("le livre '%s' doit être renvoyé à %s", demande.Titre, demande.Preteur)
+	}
+	if !demande.DateRetourPreteur.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%s attend le retour de '%s' depuis le %s : il doit lui être renvoyé",
+			demande.Preteur, demande.Titre, demande.DateRetourPreteur.Format("02/01/2006"))
+	}
+	return demande.DateRetourPreteur, nil
+}
+
+// livrePrete fait passer la demande au statut « prêtée au membre »
+func (gp *GestionnairePEB) livrePrete(demande *models.DemandePEB, empruntID int) error {
+	demande.EmpruntID = empruntID
+	gp.changerStatut(demande, models.PEB_EN_PRET, fmt.Sprintf("emprunt #%d", empruntID))
+	return gp.sauvegarderDemandes()
+}
+
+// livreRendu fait passer la demande au statut « rendue, à renvoyer »
+func (gp *GestionnairePEB) livreRendu(demande *models.DemandePEB) error {
+	if demande.Statut != models.PEB_EN_PRET {
+		return nil
+	}
+	gp.changerStatut(demande, models.PEB_RENDUE, "")
+	return gp.sauvegarderDemandes()
+}
+
+// empruntAnnule remet la demande en attente du membre : l'emprunt saisi par
+// erreur n'a jamais eu lieu
+func (gp *GestionnairePEB) empruntAnnule(demande *models.DemandePEB) error {
+	if demande.Statut != models.PEB_EN_PRET {
+		return nil
+	}
+	demande.EmpruntID = 0
+	gp.changerStatut(demande, models.PEB_RECUE, "emprunt annulé")
+	return gp.sauvegarderDemandes()
+}
+
+// livresPEB retourne les IDs des exemplaires temporaires, présents ou déjà renvoyés
+func (gp *GestionnairePEB) livresPEB() map[int]bool {
+	livres := make(map[int]bool)
+	for _, demande := range gp.demandes {
+		if demande.LivreID != 0 {
+			livres[demande.LivreID] = true
+		}
+	}
+	return livres
+}
+
+// ========================================
+// EXEMPLAIRES TEMPORAIRES
+// ========================================
+
+// ajouterLivrePEB entre au catalogue le livre prêté par une autre bibliothèque.
+// Il n'est rattaché à aucun genre ni contributeur : ces fiches décrivent le fonds.
+func (gl *GestionnaireLivres) ajouterLivrePEB(demande models.DemandePEB) (models.Livre, error) {
+	id, err := gl.sequence.Attribuer()
+	if err != nil {
+		return models.Livre{}, err
+	}
+
+	livre := models.Livre{
+		ID:            id,
+		UID:           models.NouvelUID(),
+		Titre:         demande.Titre,
+		Auteur:        demande.Auteur,
+		ISBN:          demande.ISBN,
+		CodeBarres:    gl.codeGenere(id),
+		Sujets:        []int{},
+		Disponible:    true,
+		DateAjout:     time.Now(),
+		SuccursaleID:  gl.gestionnaireSuccursales.CouranteID(),
+		EmplacementID: gl.gestionnaireSuccursales.CouranteID(),
+		PEBID:         demande.ID,
+	}
+
+	gl.livres = append(gl.livres, livre)
+	return livre, gl.sauvegarderLivres()
+}
+
+// supprimerLivrePEB retire définitivement du catalogue un exemplaire renvoyé
+func (gl *GestionnaireLivres) supprimerLivrePEB(id int) error {
+	livre, index := gl.TrouverLivreParID(id)
+	if livre == nil {
+		return nil
+	}
+	if !livre.EstPEB() {
+		return fmt.Errorf("le livre ID %d appartient au fonds", id)
+	}
+	if !livre.Disponible {
+		return fmt.Errorf("le livre '%s' est encore emprunté", livre.Titre)
+	}
+
+	gl.livres = append(gl.livres[:index], gl.livres[index+1:]...)
+	return gl.sauvegarderLivres()
+}
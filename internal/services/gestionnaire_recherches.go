@@ -0,0 +1,84 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+)
+
+// ========================================
+// RECHERCHES SANS RÉSULTAT
+// Les recherches du comptoir et du portail qui ne trouvent rien sont notées,
+// regroupées par terme, pour le conseiller d'achat.
+// ========================================
+
+type GestionnaireRecherches struct {
+	recherches []models.Recherche
+	stockage   storage.Storage
+}
+
+func (grc *GestionnaireRecherches) sauvegarderRecherches() error {
+	return grc.stockage.Sauvegarder(grc.recherches)
+}
+
+func (grc *GestionnaireRecherches) ChargerRecherches() error {
+	return grc.stockage.Charger(&grc.recherches)
+}
+
+func NouveauGestionnaireRecherches(stockage storage.Storage) *GestionnaireRecherches {
+	grc := &GestionnaireRecherches{
+		recherches: make([]models.Recherche, 0),
+		stockage:   stockage,
+	}
+
+	grc.ChargerRecherches()
+	return grc
+}
+
+// normaliserTerme rend comparables « Le  Nom de la Rose » et « le nom de la rose »
+func normaliserTerme(terme string) string {
+	return strings.Join(strings.Fields(strings.ToLower(terme)), " ")
+}
+
+// NoterRecherche enregistre une recherche du catalogue ; seules celles qui
+// n'ont rien trouvé sont conservées
+func (grc *GestionnaireRecherches) NoterRecherche(terme string, resultats int) error {
+	terme = normaliserTerme(terme)
+	if terme == "" || resultats > 0 {
+		return nil
+	}
+
+	maintenant := time.Now()
+	for i := range grc.recherches {
+		if grc.recherches[i].Terme == terme {
+			grc.recherches[i].Nombre++
+			grc.recherches[i].Derniere = maintenant
+			return grc.sauvegarderRecherches()
+		}
+	}
+
+	grc.recherches = append(grc.recherches, models.Recherche{
+		Terme:    terme,
+		Nombre:   1,
+		Premiere: maintenant,
+		Derniere: maintenant,
+	})
+	return grc.sauvegarderRecherches()
+}
+
+// ListerRecherchesInfructueuses retourne les termes notés, les plus demandés d'abord
+func (grc *GestionnaireRecherches) ListerRecherchesInfructueuses() []models.Recherche {
+	recherches := make([]models.Recherche, len(grc.recherches))
+	copy(recherches, grc.recherches)
+
+	sort.Slice(recherches, func(i, j int) bool {
+		if recherches[i].Nombre != recherches[j].Nombre {
+			return recherches[i].Nombre > recherches[j].Nombre
+		}
+		return recherches[i].Derniere.After(recherches[j].Derniere)
+	})
+	return recherches
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+)
+
+// ========================================
+// RÉSERVATIONS
+// Un membre réserve un livre emprunté (ou déjà en rayon) ; au retour du livre,
+// la première réservation de la file passe « prête » et le livre est mis de côté
+// pendant delaiRetraitJours. Les vérifications liées aux emprunts du membre sont
+// faites par GestionnaireEmprunts.ReserverLivre.
+// En réseau, le livre est retiré dans la succursale du membre : la réservation
+// porte sur l'exemplaire le plus proche, qui est acheminé s'il est ailleurs.
+// ========================================
+
+type GestionnaireReservations struct {
+	reservations        []models.Reservation
+	sequence            *storage.Sequence
+	stockage            storage.Storage
+	gestionnaireLivres  *GestionnaireLivres
+	gestionnaireMembres *GestionnaireMembres
+	delaiRetraitJours   int
+}
+
+func (gr *GestionnaireReservations) sauvegarderReservations() error {
+	return gr.stockage.Sauvegarder(gr.reservations)
+}
+
+func (gr *GestionnaireReservations) ChargerReservations() error {
+	err := gr.stockage.Charger(&gr.reservations)
+	if err != nil {
+		return err
+	}
+
+	for i := range gr.reservations {
+		reservation := &gr.reservations[i]
+		gr.sequence.Observer(reservation.ID)
+
+		// Les réservations d'avant les UID en reçoivent un, toujours le même
+		if reservation.UID == "" {
+			reservation.UID = models.UIDAncien(reservation.DateReservation,
+				fmt.Sprintf("reservation:%d:%d:%d", reservation.ID, reservation.LivreID, reservation.MembreID))
+		}
+	}
+
+	return nil
+}
+
+func NouveauGestionnaireReservations(stockage storage.Storage, sequences *storage.Sequences, gl *GestionnaireLivres, gm *GestionnaireMembres, delaiRetraitJours int) *GestionnaireReservations {
+	gr := &GestionnaireReservations{
+		reservations:        make([]models.Reservation, 0),
+		sequence:            sequences.Sequence("reservations"),
+		stockage:            stockage,
+		gestionnaireLivres:  gl,
+		gestionnaireMembres: gm,
+		delaiRetraitJours:   delaiRetraitJours,
+	}
+
+	gr.ChargerReservations()
+	gr.ExpirerReservations() // Libérer les livres non retirés à temps
+	return gr
+}
+
+// DelaiRetraitJours retourne le nombre de jours pendant lesquels un livre reste mis de côté
+func (gr *GestionnaireReservations) DelaiRetraitJours() int {
+	return gr.delaiRetraitJours
+}
+
+// reserver ajoute le membre à la file d'attente du livre ; si le livre est en rayon
+// et que personne n'attend, il est mis de côté immédiatement
+func (gr *GestionnaireReservations) reserver(livreID, membreID int) (models.Reservation, error) {
+	livre, _ := gr.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return models.Reservation{}, fmt.Errorf("livre ID %d introuvable", livreID)
+	}
+	if livre.EstRetire() {
+		return models.Reservation{}, fmt.Errorf("le livre '%s' a été retiré du fonds (%s)", livre.Titre, livre.Retrait.String())
+	}
+
+	membre, _ := gr.gestionnaireMembres.TrouverMembreParID(membreID)
+	if membre == nil {
+		return models.Reservation{}, fmt.Errorf("membre ID %d introuvable", membreID)
+	}
+	if membre.EstRetire() {
+		return models.Reservation{}, fmt.Errorf("le membre %s a été radié et ne peut pas réserver", membre.Nom)
+	}
+	if !membre.Actif {
+		return models.Reservation{}, fmt.Errorf("le membre %s est suspendu et ne peut pas réserver", membre.Nom)
+	}
+
+	// En réseau, n'importe quel exemplaire du titre fait l'affaire : on prend le plus proche
+	retrait := membre.SuccursaleID
+	if retrait == 0 {
+		retrait = gr.gestionnaireLivres.gestionnaireSuccursales.CouranteID()
+	}
+	exemplaires := []models.Livre{*livre}
+	if retrait != 0 {
+		exemplaires = gr.gestionnaireLivres.exemplaires(*livre)
+		livre = gr.exemplairePlusProche(exemplaires, retrait)
+		livreID = livre.ID
+	}
+
+	actives := 0
+	for _, reservation := range gr.reservations {
+		if !reservation.EstActive() || reservation.MembreID != membreID {
+			continue
+		}
+		for _, exemplaire := range exemplaires {
+			if reservation.LivreID == exemplaire.ID {
+				return models.Reservation{}, fmt.Errorf("%s a déjà réservé '%s'", membre.Nom, livre.Titre)
+			}
+		}
+		actives++
+	}
+
+	// RÈGLE MÉTIER : pas plus de réservations en cours que d'emprunts simultanés
+	if limite := gr.gestionnaireMembres.LimiteEmprunts(); actives >= limite {
+		return models.Reservation{}, fmt.Errorf("le membre %s a atteint la limite de %d réservations en cours", membre.Nom, limite)
+	}
+
+	id, err := gr.sequence.Attribuer()
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	reservation := models.Reservation{
+		ID:              id,
+		UID:             models.NouvelUID(),
+		LivreID:         livreID,
+		MembreID:        membreID,
+		DateReservation: time.Now(),
+		Statut:          models.RESERVATION_EN_ATTENTE,
+
+		SuccursaleRetrait: retrait,
+
+		TitreLivre: livre.Titre,
+		NomMembre:  membre.Nom,
+	}
+	gr.reservations = append(gr.reservations, reservation)
+
+	// Un livre libre est aussitôt mis de côté, ou acheminé vers la succursale de retrait
+	if gr.estLibre(*livre) && len(gr.FileAttente(livreID)) == 1 {
+		if _, err := gr.livreDisponible(livreID); err != nil {
+			return reservation, err
+		}
+		reservation, _ := gr.TrouverReservationParID(id)
+		return *reservation, nil
+	}
+
+	return reservation, gr.sauvegarderReservations()
+}
+
+// estLibre indique si le livre est en rayon sans être promis à personne
+func (gr *GestionnaireReservations) estLibre(livre models.Livre) bool {
+	return livre.EstDisponible() && gr.ReservationPrete(livre.ID) == nil && gr.ReservationAcheminee(livre.ID) == nil
+}
+
+// exemplairePlusProche choisit l'exemplaire à réserver pour un retrait dans la
+// succursale donnée : le plus proche des exemplaires libres, sinon celui dont
+// la file d'attente est la plus courte, puis le plus proche de sa succursale
+func (gr *GestionnaireReservations) exemplairePlusProche(exemplaires []models.Livre, retrait int) *models.Livre {
+	succursales := gr.gestionnaireLivres.gestionnaireSuccursales
+	var choisi *models.Livre
+	var choisiLibre bool
+	var choisiAttente int
+	var choisiDistance float64
+
+	for i := range exemplaires {
+		exemplaire := &exemplaires[i]
+		libre := gr.estLibre(*exemplaire)
+		attente := len(gr.FileAttente(exemplaire.ID))
+		ou := exemplaire.SuccursaleID
+		if libre {
+			ou = exemplaire.EmplacementID
+		}
+		distance := succursales.DistanceKm(ou, retrait)
+
+		meilleur := choisi == nil ||
+			(libre && !choisiLibre) ||
+			(libre == choisiLibre && attente < choisiAttente) ||
+			(libre == choisiLibre && attente == choisiAttente && distance < choisiDistance)
+		if meilleur {
+			choisi, choisiLibre, choisiAttente, choisiDistance = exemplaire, libre, attente, distance
+		}
+	}
+
+	livre, _ := gr.gestionnaireLivres.TrouverLivreParID(choisi.ID)
+	return livre
+}
+
+func (gr *GestionnaireReservations) mettreDeCote(reservation *models.Reservation) {
+	maintenant := time.Now()
+	limite := maintenant.AddDate(0, 0, gr.delaiRetraitJours)
+	reservation.Statut = models.RESERVATION_PRETE
+	reservation.DateMiseDeCote = &maintenant
+	reservation.DateLimite = &limite
+}
+
+func (gr *GestionnaireReservations) cloturer(index int, statut string) {
+	maintenant := time.Now()
+	gr.reservations[index].Statut = statut
+	gr.reservations[index].DateCloture = &maintenant
+}
+
+// livreDisponible est appelé quand un livre revient en rayon : la première
+// réservation en attente passe « prête ». Retourne la réservation mise de côté.
+// En réseau, le livre attend dans la succursale de retrait : s'il est ailleurs,
+// il y est acheminé et la réservation passe « en transit ». Sans réservation,
+// un livre qui n'est pas dans sa succursale y retourne.
+func (gr *GestionnaireReservations) livreDisponible(livreID int) (*models.Reservation, error) {
+	if prete := gr.ReservationPrete(livreID); prete != nil {
+		return prete, nil
+	}
+
+	livre, _ := gr.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil || livre.EstEnTransit() {
+		return nil, nil
+	}
+
+	// Le livre arrive dans la succursale où un membre l'attend
+	reservation, index := gr.ReservationAcheminee(livreID), -1
+	if reservation == nil {
+		file := gr.FileAttente(livreID)
+		if len(file) == 0 && livre.SuccursaleID != 0 {
+			file = gr.fileAttenteTitre(*livre)
+		}
+		if len(file) == 0 {
+			return nil, gr.renvoyer(livre)
+		}
+		reservation = &file[0]
+	}
+	reservation, index = gr.TrouverReservationParID(reservation.ID)
+
+	// Une réservation en attente sur un autre exemplaire du titre est servie par celui-ci
+	reservation.LivreID = livreID
+
+	if reservation.SuccursaleRetrait == 0 || reservation.SuccursaleRetrait == livre.EmplacementID {
+		gr.mettreDeCote(reservation)
+	} else {
+		reservation.Statut = models.RESERVATION_EN_TRANSIT
+		if err := gr.acheminer(livre, reservation.SuccursaleRetrait, models.TRANSFERT_RESERVATION, reservation.ID); err != nil {
+			return nil, err
+		}
+	}
+	gr.reservations[index] = *reservation
+
+	return reservation, gr.sauvegarderReservations()
+}
+
+// fileAttenteTitre retourne les réservations en attente sur les autres
+// exemplaires du même titre, dans l'ordre de passage
+func (gr *GestionnaireReservations) fileAttenteTitre(livre models.Livre) []models.Reservation {
+	var file []models.Reservation
+	for _, exemplaire := range gr.gestionnaireLivres.exemplaires(livre) {
+		if exemplaire.ID != livre.ID {
+			file = append(file, gr.FileAttente(exemplaire.ID)...)
+		}
+	}
+	trierReservations(file)
+	return file
+}
+
+// acheminer met le livre en transit et l'enregistre
+func (gr *GestionnaireReservations) acheminer(livre *models.Livre, vers int, motif string, reservationID int) error {
+	livre, index := gr.gestionnaireLivres.TrouverLivreParID(livre.ID)
+	gr.gestionnaireLivres.expedier(livre, vers, motif, reservationID)
+	gr.gestionnaireLivres.livres[index] = *livre
+	return gr.gestionnaireLivres.sauvegarderLivres()
+}
+
+// renvoyer fait rentrer dans sa succursale un livre que personne n'attend ailleurs
+func (gr *GestionnaireReservations) renvoyer(livre *models.Livre) error {
+	if livre.SuccursaleID == 0 || livre.EmplacementID == livre.SuccursaleID {
+		return nil
+	}
+	return gr.acheminer(livre, livre.SuccursaleID, models.TRANSFERT_RETOUR, 0)
+}
+
+// honorer clôt la réservation du membre pour ce livre quand il l'emprunte
+func (gr *GestionnaireReservations) honorer(livreID, membreID int) error {
+	for i, reservation := range gr.reservations {
+		if reservation.EstActive() && reservation.LivreID == livreID && reservation.MembreID == membreID {
+			gr.cloturer(i, models.RESERVATION_HONOREE)
+			return gr.sauvegarderReservations()
+		}
+	}
+	return nil
+}
+
+// AnnulerReservation annule une réservation active. Un membreID non nul limite
+// l'annulation aux réservations de ce membre (portail) ; l'accueil passe 0.
+func (gr *GestionnaireReservations) AnnulerReservation(id, membreID int) error {
+	reservation, index := gr.TrouverReservationParID(id)
+	if reservation == nil || (membreID != 0 && reservation.MembreID != membreID) {
+		return fmt.Errorf("réservation ID %d introuvable", id)
+	}
+	if !reservation.EstActive() {
+		return fmt.Errorf("la réservation ID %d est déjà close (%s)", id, models.LibelleStatutReservation(reservation.Statut))
+	}
+
+	etaitPrete := reservation.Statut == models.RESERVATION_PRETE
+	gr.cloturer(index, models.RESERVATION_ANNULEE)
+	if err := gr.sauvegarderReservations(); err != nil {
+		return err
+	}
+
+	// Le livre mis de côté passe au membre suivant
+	if etaitPrete {
+		if _, err := gr.livreDisponible(reservation.LivreID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpirerReservations clôt les réservations prêtes dont la date limite de retrait
+// est dépassée, et met le livre de côté pour le membre suivant
+func (gr *GestionnaireReservations) ExpirerReservations() ([]models.Reservation, error) {
+	maintenant := time.Now()
+	var expirees []models.Reservation
+
+	for i, reservation := range gr.reservations {
+		if reservation.Statut == models.RESERVATION_PRETE && reservation.DateLimite != nil && maintenant.After(*reservation.DateLimite) {
+			gr.cloturer(i, models.RESERVATION_EXPIREE)
+			expirees = append(expirees, gr.reservations[i])
+		}
+	}
+	if len(expirees) == 0 {
+		return nil, nil
+	}
+	if err := gr.sauvegarderReservations(); err != nil {
+		return expirees, err
+	}
+
+	for _, reservation := range expirees {
+		if _, err := gr.livreDisponible(reservation.LivreID); err != nil {
+			return expirees, err
+		}
+	}
+	return expirees, nil
+}
+
+func (gr *GestionnaireReservations) ListerReservations() []models.Reservation {
+	return gr.reservations
+}
+
+// ListerReservationsActives retourne les réservations en attente ou prêtes, les plus anciennes d'abord
+func (gr *GestionnaireReservations) ListerReservationsActives() []models.Reservation {
+	var actives []models.Reservation
+	for _, reservation := range gr.reservations {
+		if reservation.EstActive() {
+			actives = append(actives, reservation)
+		}
+	}
+	trierReservations(actives)
+	return actives
+}
+
+func (gr *GestionnaireReservations) ListerReservationsParMembre(membreID int) []models.Reservation {
+	var reservationsMembre []models.Reservation
+	for _, reservation := range gr.reservations {
+		if reservation.MembreID == membreID {
+			reservationsMembre = append(reservationsMembre, reservation)
+		}
+	}
+	return reservationsMembre
+}
+
+// FileAttente retourne les réservations en attente d'un livre, dans l'ordre de passage
+func (gr *GestionnaireReservations) FileAttente(livreID int) []models.Reservation {
+	var file []models.Reservation
+	for _, reservation := range gr.reservations {
+		if reservation.LivreID == livreID && reservation.Statut == models.RESERVATION_EN_ATTENTE {
+			file = append(file, reservation)
+		}
+	}
+	trierReservations(file)
+	return file
+}
+
+// PositionDansFile retourne le rang (à partir de 1) d'une réservation en attente, 0 sinon
+func (gr *GestionnaireReservations) PositionDansFile(reservation models.Reservation) int {
+	for i, r := range gr.FileAttente(reservation.LivreID) {
+		if r.ID == reservation.ID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ReservationPrete retourne la réservation pour laquelle le livre est mis de côté, s'il l'est
+func (gr *GestionnaireReservations) ReservationPrete(livreID int) *models.Reservation {
+	for i, reservation := range gr.reservations {
+		if reservation.LivreID == livreID && reservation.Statut == models.RESERVATION_PRETE {
+			return &gr.reservations[i]
+		}
+	}
+	return nil
+}
+
+// ReservationAcheminee retourne la réservation pour laquelle le livre est
+// acheminé vers une autre succursale, s'il l'est
+func (gr *GestionnaireReservations) ReservationAcheminee(livreID int) *models.Reservation {
+	for i, reservation := range gr.reservations {
+		if reservation.LivreID == livreID && reservation.Statut == models.RESERVATION_EN_TRANSIT {
+			return &gr.reservations[i]
+		}
+	}
+	return nil
+}
+
+// MembresEnAttente compte les réservations actives d'autres membres que membreID sur ce livre
+func (gr *GestionnaireReservations) MembresEnAttente(livreID, membreID int) int {
+	n := 0
+	for _, reservation := range gr.reservations {
+		if reservation.LivreID == livreID && reservation.MembreID != membreID && reservation.EstActive() {
+			n++
+		}
+	}
+	return n
+}
+
+func (gr *GestionnaireReservations) TrouverReservationParID(id int) (*models.Reservation, int) {
+	for i, reservation := range gr.reservations {
+		if reservation.ID == id {
+			return &gr.reservations[i], i
+		}
+	}
+	return nil, -1
+}
+
+func trierReservations(reservations []models.Reservation) {
+	sort.SliceStable(reservations, func(i, j int) bool {
+		if !reservations[i].DateReservation.Equal(reservations[j].DateReservation) {
+			return reservations[i].DateReservation.Before(reservations[j].DateReservation)
+		}
+		return reservations[i].ID < reservations[j].ID
+	})
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// SÉRIES, OEUVRES ET ÉDITIONS
+// Une série contient des oeuvres numérotées (tomes),
+// une oeuvre regroupe les livres qui en sont des éditions
+// ========================================
+
+type GestionnaireSeries struct {
+	series             []models.Serie
+	oeuvres            []models.Oeuvre
+	sequenceSeries     *storage.Sequence
+	sequenceOeuvres    *storage.Sequence
+	stockage           storage.Storage
+	gestionnaireLivres *GestionnaireLivres
+}
+
+// donneesSeries est le format du fichier de sauvegarde
+type donneesSeries struct {
+	Series  []models.Serie  `json:"series"`
+	Oeuvres []models.Oeuvre `json:"oeuvres"`
+}
+
+// VolumeSerie décrit un tome d'une série avec ses éditions au catalogue
+type VolumeSerie struct {
+	Oeuvre      models.Oeuvre
+	Editions    []models.Livre
+	Disponibles int
+}
+
+func (gs *GestionnaireSeries) sauvegarderSeries() error {
+	return gs.stockage.Sauvegarder(donneesSeries{Series: gs.series, Oeuvres: gs.oeuvres})
+}
+
+func (gs *GestionnaireSeries) ChargerSeries() error {
+	var donnees donneesSeries
+	if err := gs.stockage.Charger(&donnees); err != nil {
+		return err
+	}
+
+	if donnees.Series != nil {
+		gs.series = donnees.Series
+	}
+	if donnees.Oeuvres != nil {
+		gs.oeuvres = donnees.Oeuvres
+	}
+
+	for _, serie := range gs.series {
+		gs.sequenceSeries.Observer(serie.ID)
+	}
+	for _, oeuvre := range gs.oeuvres {
+		gs.sequenceOeuvres.Observer(oeuvre.ID)
+	}
+
+	return nil
+}
+
+func NouveauGestionnaireSeries(stockage storage.Storage, sequences *storage.Sequences, gl *GestionnaireLivres) *GestionnaireSeries {
+	gs := &GestionnaireSeries{
+		series:             make([]models.Serie, 0),
+		oeuvres:            make([]models.Oeuvre, 0),
+		sequenceSeries:     sequences.Sequence("series"),
+		sequenceOeuvres:    sequences.Sequence("oeuvres"),
+		stockage:           stockage,
+		gestionnaireLivres: gl,
+	}
+
+	gs.ChargerSeries()
+	return gs
+}
+
+// ========================================
+// SÉRIES
+// ========================================
+
+func (gs *GestionnaireSeries) AjouterSerie(titre string) (*models.Serie, error) {
+	titre = strings.TrimSpace(titre)
+	if !validators.ValiderTitre(titre) {
+		return nil, fmt.Errorf("le titre de la série est invalide")
+	}
+
+	for _, serie := range gs.series {
+		if strings.EqualFold(serie.Titre, titre) {
+			return nil, fmt.Errorf("une série '%s' existe déjà (ID : %d)", serie.Titre, serie.ID)
+		}
+	}
+
+	id, err := gs.sequenceSeries.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gs.series = append(gs.series, models.Serie{ID: id, Titre: titre})
+
+	if err := gs.sauvegarderSeries(); err != nil {
+		return nil, err
+	}
+	return &gs.series[len(gs.series)-1], nil
+}
+
+func (gs *GestionnaireSeries) ListerSeries() []models.Serie {
+	return gs.series
+}
+
+func (gs *GestionnaireSeries) TrouverSerieParID(id int) (*models.Serie, int) {
+	for i, serie := range gs.series {
+		if serie.ID == id {
+			return &gs.series[i], i
+		}
+	}
+	return nil, -1
+}
+
+func (gs *GestionnaireSeries) RenommerSerie(id int, nouveauTitre string) error {
+	serie, index := gs.TrouverSerieParID(id)
+	if serie == nil {
+		return fmt.Errorf("aucune série trouvée avec l'ID %d", id)
+	}
+
+	if !validators.ValiderTitre(nouveauTitre) {
+		return fmt.Errorf("le nouveau titre est invalide")
+	}
+
+	serie.Titre = strings.TrimSpace(nouveauTitre)
+	gs.series[index] = *serie
+	return gs.sauvegarderSeries()
+}
+
+func (gs *GestionnaireSeries) SupprimerSerie(id int) error {
+	serie, index := gs.TrouverSerieParID(id)
+	if serie == nil {
+		return fmt.Errorf("aucune série trouvée avec l'ID %d", id)
+	}
+
+	// RÈGLE MÉTIER : on ne peut pas supprimer une série qui contient des tomes
+	if tomes := gs.ListerOeuvresDeSerie(id); len(tomes) > 0 {
+		return fmt.Errorf("impossible de supprimer la série '%s' car elle contient %d tome(s)", serie.Titre, len(tomes))
+	}
+
+	gs.series = append(gs.series[:index], gs.series[index+1:]...)
+	return gs.sauvegarderSeries()
+}
+
+// ========================================
+// OEUVRES
+// ========================================
+
+// AjouterOeuvre crée une oeuvre, éventuellement comme tome d'une série (serieID 0 = hors série)
+func (gs *GestionnaireSeries) AjouterOeuvre(titre string, serieID, numeroTome int) (*models.Oeuvre, error) {
+	titre = strings.TrimSpace(titre)
+	if !validators.ValiderTitre(titre) {
+		return nil, fmt.Errorf("le titre de l'oeuvre est invalide")
+	}
+
+	if err := gs.verifierTome(0, serieID, numeroTome); err != nil {
+		return nil, err
+	}
+
+	if serieID == 0 {
+		numeroTome = 0
+	}
+
+	id, err := gs.sequenceOeuvres.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gs.oeuvres = append(gs.oeuvres, models.Oeuvre{
+		ID:         id,
+		Titre:      titre,
+		SerieID:    serieID,
+		NumeroTome: numeroTome,
+	})
+
+	if err := gs.sauvegarderSeries(); err != nil {
+		return nil, err
+	}
+	return &gs.oeuvres[len(gs.oeuvres)-1], nil
+}
+
+// verifierTome contrôle la série et l'unicité du numéro de tome (oeuvreID = oeuvre modifiée, 0 à la création)
+func (gs *GestionnaireSeries) verifierTome(oeuvreID, serieID, numeroTome int) error {
+	if serieID == 0 {
+		return nil
+	}
+
+	serie, _ := gs.TrouverSerieParID(serieID)
+	if serie == nil {
+		return fmt.Errorf("aucune série trouvée avec l'ID %d", serieID)
+	}
+
+	if numeroTome < 1 {
+		return fmt.Errorf("le numéro de tome doit être au moins 1")
+	}
+
+	for _, oeuvre := range gs.oeuvres {
+		if oeuvre.ID != oeuvreID && oeuvre.SerieID == serieID && oeuvre.NumeroTome == numeroTome {
+			return fmt.Errorf("le tome %d de '%s' existe déjà (%s)", numeroTome, serie.Titre, oeuvre.Titre)
+		}
+	}
+
+	return nil
+}
+
+func (gs *GestionnaireSeries) ListerOeuvres() []models.Oeuvre {
+	return gs.oeuvres
+}
+
+func (gs *GestionnaireSeries) TrouverOeuvreParID(id int) (*models.Oeuvre, int) {
+	for i, oeuvre := range gs.oeuvres {
+		if oeuvre.ID == id {
+			return &gs.oeuvres[i], i
+		}
+	}
+	return nil, -1
+}
+
+// ListerOeuvresDeSerie retourne les tomes d'une série dans l'ordre
+func (gs *GestionnaireSeries) ListerOeuvresDeSerie(serieID int) []models.Oeuvre {
+	var tomes []models.Oeuvre
+	for _, oeuvre := range gs.oeuvres {
+		if oeuvre.SerieID == serieID {
+			tomes = append(tomes, oeuvre)
+		}
+	}
+	sort.Slice(tomes, func(i, j int) bool {
+		return tomes[i].NumeroTome < tomes[j].NumeroTome
+	})
+	return tomes
+}
+
+// ModifierOeuvre change le titre et/ou la place d'une oeuvre dans une série.
+// Un serieID négatif conserve la série et le tome actuels.
+func (gs *GestionnaireSeries) ModifierOeuvre(id int, nouveauTitre string, serieID, numeroTome int) error {
+	oeuvre, index := gs.TrouverOeuvreParID(id)
+	if oeuvre == nil {
+		return fmt.Errorf("aucune oeuvre trouvée avec l'ID %d", id)
+	}
+
+	if nouveauTitre != "" {
+		if !validators.ValiderTitre(nouveauTitre) {
+			return fmt.Errorf("le nouveau titre est invalide")
+		}
+		oeuvre.Titre = strings.TrimSpace(nouveauTitre)
+	}
+
+	if serieID >= 0 {
+		if err := gs.verifierTome(id, serieID, numeroTome); err != nil {
+			return err
+		}
+		if serieID == 0 {
+			numeroTome = 0
+		}
+		oeuvre.SerieID = serieID
+		oeuvre.NumeroTome = numeroTome
+	}
+
+	gs.oeuvres[index] = *oeuvre
+	return gs.sauvegarderSeries()
+}
+
+func (gs *GestionnaireSeries) SupprimerOeuvre(id int) error {
+	oeuvre, index := gs.TrouverOeuvreParID(id)
+	if oeuvre == nil {
+		return fmt.Errorf("aucune oeuvre trouvée avec l'ID %d", id)
+	}
+
+	// RÈGLE MÉTIER : on ne peut pas supprimer une oeuvre qui a encore des éditions au catalogue
+	if editions := gs.gestionnaireLivres.ListerLivresParOeuvre(id); len(editions) > 0 {
+		return fmt.Errorf("impossible de supprimer '%s' car %d livre(s) y sont rattachés", oeuvre.Titre, len(editions))
+	}
+
+	gs.oeuvres = append(gs.oeuvres[:index], gs.oeuvres[index+1:]...)
+	return gs.sauvegarderSeries()
+}
+
+// RattacherLivre déclare un livre comme édition d'une oeuvre
+func (gs *GestionnaireSeries) RattacherLivre(livreID, oeuvreID int, edition string) error {
+	if oeuvre, _ := gs.TrouverOeuvreParID(oeuvreID); oeuvre == nil {
+		return fmt.Errorf("aucune oeuvre trouvée avec l'ID %d", oeuvreID)
+	}
+	return gs.gestionnaireLivres.DefinirOeuvre(livreID, oeuvreID, edition)
+}
+
+// DetacherLivre retire un livre de son oeuvre
+func (gs *GestionnaireSeries) DetacherLivre(livreID int) error {
+	return gs.gestionnaireLivres.DefinirOeuvre(livreID, 0, "")
+}
+
+// ========================================
+// VUES
+// ========================================
+
+// ListerVolumes retourne les tomes d'une série dans l'ordre avec la disponibilité de leurs éditions
+func (gs *GestionnaireSeries) ListerVolumes(serieID int) []VolumeSerie {
+	var volumes []VolumeSerie
+	for _, oeuvre := range gs.ListerOeuvresDeSerie(serieID) {
+		volume := VolumeSerie{Oeuvre: oeuvre}
+		for _, edition := range gs.gestionnaireLivres.ListerLivresParOeuvre(oeuvre.ID) {
+			if edition.EstRetire() {
+				continue
+			}
+			volume.Editions = append(volume.Editions, edition)
+			if edition.EstDisponible() {
+				volume.Disponibles++
+			}
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
+
+// TomeSuivant retourne le tome qui suit celui du livre donné, ou nil s'il n'y en a pas
+func (gs *GestionnaireSeries) TomeSuivant(livreID int) (*VolumeSerie, error) {
+	livre, _ := gs.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return nil, fmt.Errorf("aucun livre trouvé avec l'ID %d", livreID)
+	}
+
+	oeuvre, _ := gs.TrouverOeuvreParID(livre.OeuvreID)
+	if oeuvre == nil || oeuvre.SerieID == 0 {
+		return nil, fmt.Errorf("le livre '%s' ne fait partie d'aucune série", livre.Titre)
+	}
+
+	for _, volume := range gs.ListerVolumes(oeuvre.SerieID) {
+		if volume.Oeuvre.NumeroTome > oeuvre.NumeroTome {
+			return &volume, nil
+		}
+	}
+	return nil, nil
+}
+
+// ClasserOeuvres cumule les emprunts par oeuvre, toutes éditions confondues.
+// Un livre rattaché à aucune oeuvre est compté comme une oeuvre à lui seul.
+func (gs *GestionnaireSeries) ClasserOeuvres() []statistiques.Oeuvre {
+	parOeuvre := make(map[int]*statistiques.Oeuvre)
+	var classement []statistiques.Oeuvre
+
+	for _, livre := range gs.gestionnaireLivres.ListerLivres() {
+		oeuvre, _ := gs.TrouverOeuvreParID(livre.OeuvreID)
+		if oeuvre == nil {
+			classement = append(classement, statistiques.Oeuvre{Titre: livre.Titre, Editions: 1, Emprunts: livre.NombreEmprunts})
+			continue
+		}
+
+		stat, existe := parOeuvre[oeuvre.ID]
+		if !existe {
+			stat = &statistiques.Oeuvre{Titre: oeuvre.Titre}
+			parOeuvre[oeuvre.ID] = stat
+		}
+		stat.Editions++
+		stat.Emprunts += livre.NombreEmprunts
+	}
+
+	for _, stat := range parOeuvre {
+		classement = append(classement, *stat)
+	}
+
+	sort.Slice(classement, func(i, j int) bool {
+		if classement[i].Emprunts != classement[j].Emprunts {
+			return classement[i].Emprunts > classement[j].Emprunts
+		}
+		return classement[i].Titre < classement[j].Titre
+	})
+
+	return classement
+}
+
+func (gs *GestionnaireSeries) ObtenirStatistiques() statistiques.Series {
+	stats := statistiques.Series{
+		Series:            len(gs.series),
+		Oeuvres:           len(gs.oeuvres),
+		ClassementOeuvres: gs.ClasserOeuvres(),
+	}
+
+	if len(stats.ClassementOeuvres) > 0 && stats.ClassementOeuvres[0].Emprunts > 0 {
+		stats.OeuvrePlusEmpruntee = &stats.ClassementOeuvres[0]
+	}
+
+	return stats
+}
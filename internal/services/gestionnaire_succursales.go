@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// SUCCURSALES
+// Les bibliothèques du réseau partagent le même catalogue et les mêmes
+// membres. Chaque poste travaille pour une succursale, la « courante » :
+// c'est là que sont enregistrés les emprunts, les retours et les nouveaux
+// livres. Tant qu'aucune succursale n'est déclarée, l'application se
+// comporte comme une bibliothèque unique.
+// ========================================
+
+type GestionnaireSuccursales struct {
+	succursales []models.Succursale
+	sequence    *storage.Sequence
+	stockage    storage.Storage
+	courante    int // succursale de ce poste, 0 tant que le réseau n'en a pas
+}
+
+func (gsu *GestionnaireSuccursales) sauvegarderSuccursales() error {
+	return gsu.stockage.Sauvegarder(gsu.succursales)
+}
+
+func (gsu *GestionnaireSuccursales) ChargerSuccursales() error {
+	err := gsu.stockage.Charger(&gsu.succursales)
+	if err != nil {
+		return err
+	}
+
+	for _, succursale := range gsu.succursales {
+		gsu.sequence.Observer(succursale.ID)
+	}
+
+	return nil
+}
+
+func NouveauGestionnaireSuccursales(stockage storage.Storage, sequences *storage.Sequences) *GestionnaireSuccursales {
+	gsu := &GestionnaireSuccursales{
+		succursales: make([]models.Succursale, 0),
+		sequence:    sequences.Sequence("succursales"),
+		stockage:    stockage,
+	}
+
+	gsu.ChargerSuccursales()
+
+	// Sans succursale désignée, le poste travaille pour la première du réseau
+	if len(gsu.succursales) > 0 {
+		gsu.courante = gsu.succursales[0].ID
+	}
+	return gsu
+}
+
+// ajouterSuccursale déclare une succursale ; la première devient celle du poste.
+// Les livres et membres existants y sont rattachés par GestionnaireEmprunts.AjouterSuccursale.
+func (gsu *GestionnaireSuccursales) ajouterSuccursale(code, nom, adresse string, latitude, longitude float64) (*models.Succursale, error) {
+	code = models.NormaliserCodeSuccursale(code)
+	if !validators.ValiderCodeSuccursale(code) {
+		return nil, fmt.Errorf("le code de succursale '%s' est invalide (2 à 8 lettres ou chiffres)", code)
+	}
+	if existante := gsu.TrouverSuccursaleParCode(code); existante != nil {
+		return nil, fmt.Errorf("le code %s est déjà celui de la succursale %s", code, existante.Nom)
+	}
+
+	nom = strings.TrimSpace(nom)
+	if !validators.ValiderTitre(nom) {
+		return nil, fmt.Errorf("le nom de la succursale ne peut pas être vide")
+	}
+
+	if !validators.ValiderCoordonnees(latitude, longitude) {
+		return nil, fmt.Errorf("position invalide (latitude entre -90 et 90, longitude entre -180 et 180)")
+	}
+
+	id, err := gsu.sequence.Attribuer()
+	if err != nil {
+		return nil, err
+	}
+
+	gsu.succursales = append(gsu.succursales, models.Succursale{
+		ID:        id,
+		UID:       models.NouvelUID(),
+		Code:      code,
+		Nom:       nom,
+		Adresse:   strings.TrimSpace(adresse),
+		Latitude:  latitude,
+		Longitude: longitude,
+		DateAjout: time.Now(),
+	})
+	if gsu.courante == 0 {
+		gsu.courante = id
+	}
+
+	if err := gsu.sauvegarderSuccursales(); err != nil {
+		return nil, err
+	}
+	return &gsu.succursales[len(gsu.succursales)-1], nil
+}
+
+// ListerSuccursales retourne les succursales dans l'ordre de leur création
+func (gsu *GestionnaireSuccursales) ListerSuccursales() []models.Succursale {
+	return gsu.succursales
+}
+
+// EstEnReseau indique si au moins une succursale a été déclarée
+func (gsu *GestionnaireSuccursales) EstEnReseau() bool {
+	return len(gsu.succursales) > 0
+}
+
+func (gsu *GestionnaireSuccursales) TrouverSuccursaleParID(id int) *models.Succursale {
+	for i, succursale := range gsu.succursales {
+		if succursale.ID == id {
+			return &gsu.succursales[i]
+		}
+	}
+	return nil
+}
+
+// TrouverSuccursaleParCode retrouve une succursale par son code, sans tenir compte de la casse
+func (gsu *GestionnaireSuccursales) TrouverSuccursaleParCode(code string) *models.Succursale {
+	code = models.NormaliserCodeSuccursale(code)
+	for i, succursale := range gsu.succursales {
+		if succursale.Code == code {
+			return &gsu.succursales[i]
+		}
+	}
+	return nil
+}
+
+// ChoisirSuccursale désigne la succursale pour laquelle travaille ce poste
+func (gsu *GestionnaireSuccursales) ChoisirSuccursale(code string) error {
+	succursale := gsu.TrouverSuccursaleParCode(code)
+	if succursale == nil {
+		if !gsu.EstEnReseau() {
+			return fmt.Errorf("succursale '%s' inconnue : aucune succursale n'est encore déclarée", code)
+		}
+		codes := make([]string, 0, len(gsu.succursales))
+		for _, s := range gsu.succursales {
+			codes = append(codes, s.Code)
+		}
+		return fmt.Errorf("succursale '%s' inconnue (codes déclarés : %s)", code, strings.Join(codes, ", "))
+	}
+	gsu.courante = succursale.ID
+	return nil
+}
+
+// Courante retourne la succursale de ce poste, nil hors réseau
+func (gsu *GestionnaireSuccursales) Courante() *models.Succursale {
+	return gsu.TrouverSuccursaleParID(gsu.courante)
+}
+
+// CouranteID retourne l'ID de la succursale de ce poste, 0 hors réseau
+func (gsu *GestionnaireSuccursales) CouranteID() int {
+	return gsu.courante
+}
+
+// NomSuccursale retourne le nom affiché d'une succursale, ou un tiret si elle n'est pas connue
+func (gsu *GestionnaireSuccursales) NomSuccursale(id int) string {
+	if succursale := gsu.TrouverSuccursaleParID(id); succursale != nil {
+		return succursale.Nom
+	}
+	return "—"
+}
+
+// DistanceKm retourne la distance entre deux succursales. Une succursale
+// inconnue est considérée comme infiniment loin, sauf d'elle-même.
+func (gsu *GestionnaireSuccursales) DistanceKm(de, vers int) float64 {
+	if de == vers {
+		return 0
+	}
+	a, b := gsu.TrouverSuccursaleParID(de), gsu.TrouverSuccursaleParID(vers)
+	if a == nil || b == nil {
+		return DISTANCE_INCONNUE
+	}
+	return a.DistanceKm(*b)
+}
+
+// DISTANCE_INCONNUE classe en dernier les livres dont on ne sait pas où ils sont
+const DISTANCE_INCONNUE = 1e9
+
+// ParProximite retourne les succursales de la plus proche à la plus éloignée de celle donnée
+func (gsu *GestionnaireSuccursales) ParProximite(id int) []models.Succursale {
+	triees := append([]models.Succursale(nil), gsu.succursales...)
+	sort.SliceStable(triees, func(i, j int) bool {
+		return gsu.DistanceKm(id, triees[i].ID) < gsu.DistanceKm(id, triees[j].ID)
+	})
+	return triees
+}
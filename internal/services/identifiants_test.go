@@ -0,0 +1,227 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// librairie regroupe les services utiles aux tests
+type librairie struct {
+	livres        *GestionnaireLivres
+	genres        *GestionnaireGenres
+	contributeurs *GestionnaireContributeurs
+	series        *GestionnaireSeries
+	acquisitions  *GestionnaireAcquisitions
+	membres       *GestionnaireMembres
+	emprunts      *GestionnaireEmprunts
+	reservations  *GestionnaireReservations
+	succursales   *GestionnaireSuccursales
+	peb           *GestionnairePEB
+}
+
+// ouvrirLibrairie assemble les services comme main.go sur le dossier donné :
+// l'appeler deux fois sur le même dossier simule un redémarrage
+func ouvrirLibrairie(t *testing.T, dossier string) librairie {
+	t.Helper()
+
+	cfg := config.Defaut()
+	cfg.Donnees.Dossier = dossier
+	chemin := func(fichier, schema string) storage.Storage {
+		return storage.NewJSONStorage(cfg.Chemin(fichier), schema)
+	}
+
+	sq, err := storage.NewSequences(chemin(cfg.Donnees.Sequences, storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsu := NouveauGestionnaireSuccursales(chemin(cfg.Donnees.Succursales, storage.SCHEMA_SUCCURSALES), sq)
+	gg := NouveauGestionnaireGenres(chemin(cfg.Donnees.Genres, storage.SCHEMA_GENRES), sq, cfg.Validation.Genres)
+	gc := NouveauGestionnaireContributeurs(chemin(cfg.Donnees.Contributeurs, storage.SCHEMA_CONTRIBUTEURS), sq)
+	gl := NouveauGestionnaireLivres(chemin(cfg.Donnees.Livres, storage.SCHEMA_LIVRES), sq,
+		validators.NouveauValidateur(cfg.Validation.AnneePublicationMin), gg, gc, gsu)
+	gm := NouveauGestionnaireMembres(chemin(cfg.Donnees.Membres, storage.SCHEMA_MEMBRES), sq, cfg.Emprunts.LimiteSimultanes, gsu)
+	gr := NouveauGestionnaireReservations(chemin(cfg.Donnees.Reservations, storage.SCHEMA_RESERVATIONS), sq, gl, gm, cfg.Emprunts.DelaiRetraitJours)
+	ge, err := NouveauGestionnaireEmprunts(chemin(cfg.Donnees.Emprunts, storage.SCHEMA_EMPRUNTS),
+		storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal)), chemin(cfg.Donnees.Instantanes, storage.SCHEMA_INSTANTANES),
+		sq, gl, gm, gr, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := NouveauGestionnaireSeries(chemin(cfg.Donnees.Series, storage.SCHEMA_SERIES), sq, gl)
+	ga := NouveauGestionnaireAcquisitions(chemin(cfg.Donnees.Acquisitions, storage.SCHEMA_ACQUISITIONS), sq, gl)
+	gp := NouveauGestionnairePEB(chemin(cfg.Donnees.PEB, storage.SCHEMA_PEB), sq, ge)
+
+	return librairie{livres: gl, genres: gg, contributeurs: gc, series: gs, acquisitions: ga, membres: gm, emprunts: ge, reservations: gr, succursales: gsu, peb: gp}
+}
+
+func TestNumerosApresRedemarrage(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Après un redémarrage, les numéros reprennent après le plus grand
+	// attribué, sans réutiliser celui du dernier livre chargé
+	l = ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("Madame Bovary", "Gustave Flaubert", "9782070368228", "Roman", "01/01/1857"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Marc Durand", "marc@example.com", "0605060708"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	verifierIDs(t, "livres", idsLivres(l.livres), 1, 2, 3)
+	verifierIDs(t, "membres", idsMembres(l.membres), 1, 2)
+	var emprunts []int
+	for _, emprunt := range l.emprunts.ListerEmprunts() {
+		emprunts = append(emprunts, emprunt.ID)
+	}
+	verifierIDs(t, "emprunts", emprunts, 1, 2)
+
+	if rapport := l.emprunts.VerifierIntegrite(); rapport.Doublons() != 0 {
+		t.Errorf("aucun doublon attendu, trouvé %+v", rapport.Anomalies)
+	}
+}
+
+func TestNumeroPurgeNonRedonne(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	for _, m := range []struct{ nom, email string }{{"Zoé Dupont", "zoe@example.com"}, {"Marc Durand", "marc@example.com"}} {
+		if err := l.membres.AjouterMembre(m.nom, m.email, "0601020304"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Le dernier membre inscrit est radié puis purgé : son numéro et sa
+	// carte ne doivent désigner personne d'autre
+	if err := l.membres.RadierMembre(2, "déménagement"); err != nil {
+		t.Fatal(err)
+	}
+	l.membres.membres[1].Retrait.Date = time.Now().AddDate(-2, 0, 0)
+	if purges, err := l.membres.PurgerMembresRadies(365); err != nil || len(purges) != 1 {
+		t.Fatalf("purge : %d membre(s), erreur %v", len(purges), err)
+	}
+
+	l = ouvrirLibrairie(t, dossier)
+	if err := l.membres.AjouterMembre("Léa Martin", "lea@example.com", "0605060708"); err != nil {
+		t.Fatal(err)
+	}
+	verifierIDs(t, "membres", idsMembres(l.membres), 1, 3)
+	if membre, _ := l.membres.TrouverMembreParID(3); membre == nil || membre.NumeroCarte != "MEM000003" {
+		t.Errorf("numéro de carte inattendu : %+v", membre)
+	}
+}
+
+func TestUIDStables(t *testing.T) {
+	dossier := t.TempDir()
+
+	// Fichier écrit avant les UID et les séquences
+	ancien := `[{"id": 4, "titre": "Le Petit Prince", "auteur": "Antoine de Saint-Exupéry", "isbn": "9782070612758",
+		"genre": "Roman", "date_publication": "1943-04-06T00:00:00Z", "disponible": true, "date_ajout": "2023-05-01T10:00:00Z"}]`
+	if err := os.WriteFile(filepath.Join(dossier, "livres.json"), []byte(ancien), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("Madame Bovary", "Gustave Flaubert", "9782070368228", "Roman", "01/01/1857"); err != nil {
+		t.Fatal(err)
+	}
+	avant := l.livres.ListerLivres()
+	if len(avant) != 2 || avant[0].UID == "" || avant[1].UID == "" || avant[0].UID == avant[1].UID {
+		t.Fatalf("UID manquants ou identiques : %+v", avant)
+	}
+	if avant[1].ID != 5 {
+		t.Errorf("nouveau livre numéroté %d, attendu 5", avant[1].ID)
+	}
+
+	l = ouvrirLibrairie(t, dossier)
+	apres := l.livres.ListerLivres()
+	for i := range avant {
+		if apres[i].UID != avant[i].UID {
+			t.Errorf("l'UID du livre %d a changé au redémarrage : %s puis %s", avant[i].ID, avant[i].UID, apres[i].UID)
+		}
+	}
+}
+
+func TestDoublonsDetectesAuChargement(t *testing.T) {
+	dossier := t.TempDir()
+
+	// Deux fichiers fusionnés à la main : les deux livres portent l'ID 1
+	fusion := `[{"id": 1, "titre": "Le Petit Prince", "auteur": "Antoine de Saint-Exupéry", "isbn": "9782070612758",
+		"genre": "Roman", "date_publication": "1943-04-06T00:00:00Z", "disponible": true, "date_ajout": "2023-05-01T10:00:00Z"},
+		{"id": 1, "titre": "Madame Bovary", "auteur": "Gustave Flaubert", "isbn": "9782070368228",
+		"genre": "Roman", "date_publication": "1857-01-01T00:00:00Z", "disponible": true, "date_ajout": "2023-06-01T10:00:00Z"}]`
+	if err := os.WriteFile(filepath.Join(dossier, "livres.json"), []byte(fusion), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := ouvrirLibrairie(t, dossier)
+	if doublons := l.emprunts.VerifierIntegrite().Doublons(); doublons != 1 {
+		t.Fatalf("%d doublon(s) signalé(s), attendu 1", doublons)
+	}
+	if _, err := l.emprunts.ReparerIntegrite(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le livre renuméroté garde son numéro après redémarrage, et le
+	// suivant n'en hérite pas
+	l = ouvrirLibrairie(t, dossier)
+	if doublons := l.emprunts.VerifierIntegrite().Doublons(); doublons != 0 {
+		t.Errorf("%d doublon(s) après réparation", doublons)
+	}
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	verifierIDs(t, "livres", idsLivres(l.livres), 1, 2, 3)
+}
+
+func idsLivres(gl *GestionnaireLivres) []int {
+	var ids []int
+	for _, livre := range gl.ListerLivres() {
+		ids = append(ids, livre.ID)
+	}
+	return ids
+}
+
+func idsMembres(gm *GestionnaireMembres) []int {
+	var ids []int
+	for _, membre := range gm.ListerMembres() {
+		ids = append(ids, membre.ID)
+	}
+	return ids
+}
+
+func verifierIDs(t *testing.T, quoi string, obtenus []int, attendus ...int) {
+	t.Helper()
+	if len(obtenus) != len(attendus) {
+		t.Errorf("%s : IDs %v, attendu %v", quoi, obtenus, attendus)
+		return
+	}
+	for i := range attendus {
+		if obtenus[i] != attendus[i] {
+			t.Errorf("%s : IDs %v, attendu %v", quoi, obtenus, attendus)
+			return
+		}
+	}
+}
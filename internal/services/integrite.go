@@ -0,0 +1,564 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// CONTRÔLE D'INTÉGRITÉ
+// La disponibilité des livres, les compteurs d'emprunts des livres et des
+// membres, et les titres et noms recopiés dans les emprunts et les
+// réservations se déduisent tous de l'historique des emprunts. Le contrôle
+// les recalcule et signale chaque écart ; la réparation applique les
+// valeurs recalculées.
+// ========================================
+
+const (
+	ANOMALIE_DOUBLON     = "doublon"     // deux enregistrements partagent le même ID ou le même UID
+	ANOMALIE_ORPHELIN    = "orphelin"    // un emprunt ou une réservation désigne un livre ou un membre disparu
+	ANOMALIE_NOM         = "nom"         // titre ou nom recopié qui ne correspond plus à la fiche
+	ANOMALIE_COMPTEUR    = "compteur"    // disponibilité ou compteur qui contredit l'historique
+	ANOMALIE_INCOHERENCE = "incoherence" // situation impossible, à examiner à la main
+)
+
+// Anomalie est un écart entre les données enregistrées et ce que l'historique
+// des emprunts permet d'en déduire. Champ, Actuel et Attendu décrivent la
+// correction proposée ; une anomalie non réparable est seulement signalée.
+type Anomalie struct {
+	Type      string
+	Entite    string // "livre", "membre", "emprunt" ou "réservation"
+	ID        int
+	Detail    string
+	Champ     string
+	Actuel    string
+	Attendu   string
+	Reparable bool
+}
+
+// RapportIntegrite rassemble les anomalies trouvées par un contrôle
+type RapportIntegrite struct {
+	Livres       int // enregistrements contrôlés
+	Membres      int
+	Emprunts     int
+	Reservations int
+	Anomalies    []Anomalie
+}
+
+// Reparables compte les anomalies que la réparation corrige
+func (r RapportIntegrite) Reparables() int {
+	n := 0
+	for _, anomalie := range r.Anomalies {
+		if anomalie.Reparable {
+			n++
+		}
+	}
+	return n
+}
+
+// Doublons compte les identifiants en double, qui rendent les numéros
+// ambigus tant qu'ils ne sont pas réparés
+func (r RapportIntegrite) Doublons() int {
+	n := 0
+	for _, anomalie := range r.Anomalies {
+		if anomalie.Type == ANOMALIE_DOUBLON {
+			n++
+		}
+	}
+	return n
+}
+
+// etatIntegrite est une copie corrigée des données contrôlées
+type etatIntegrite struct {
+	livres       []models.Livre
+	membres      []models.Membre
+	emprunts     []models.Emprunt
+	reservations []models.Reservation
+	livresPEB    map[int]bool // exemplaires prêtés par d'autres bibliothèques, présents ou renvoyés
+
+	// Premiers numéros libres pour renuméroter les doublons
+	prochainLivre, prochainMembre, prochainEmprunt, prochainReservation int
+}
+
+// VerifierIntegrite recalcule les champs dérivés sans rien modifier
+func (ge *GestionnaireEmprunts) VerifierIntegrite() RapportIntegrite {
+	_, rapport := ge.controlerIntegrite(time.Now())
+	return rapport
+}
+
+// ReparerIntegrite applique les corrections réparables puis enregistre les
+// fichiers concernés. Le rapport retourné décrit ce qui a été corrigé.
+func (ge *GestionnaireEmprunts) ReparerIntegrite() (RapportIntegrite, error) {
+	etat, rapport := ge.controlerIntegrite(time.Now())
+	if rapport.Reparables() == 0 {
+		return rapport, nil
+	}
+
+	gl, gm, gr := ge.gestionnaireLivres, ge.gestionnaireMembres, ge.gestionnaireReservations
+	gl.livres, gm.membres, ge.emprunts, gr.reservations = etat.livres, etat.membres, etat.emprunts, etat.reservations
+
+	// Les IDs attribués aux doublons ne doivent pas être redonnés
+	for _, livre := range gl.livres {
+		gl.sequence.Observer(livre.ID)
+	}
+	for _, membre := range gm.membres {
+		gm.sequence.Observer(membre.ID)
+	}
+	for _, emprunt := range ge.emprunts {
+		ge.sequence.Observer(emprunt.ID)
+	}
+	for _, reservation := range gr.reservations {
+		gr.sequence.Observer(reservation.ID)
+	}
+	if err := gl.sequence.Sauvegarder(); err != nil {
+		return rapport, err
+	}
+
+	if err := gl.sauvegarderLivres(); err != nil {
+		return rapport, err
+	}
+	if err := gm.SauvegarderMembres(); err != nil {
+		return rapport, err
+	}
+	if err := ge.sauvegarderEmprunts(); err != nil {
+		return rapport, err
+	}
+	if err := gr.sauvegarderReservations(); err != nil {
+		return rapport, err
+	}
+
+	// Sans instantané, le prochain démarrage reconstruirait les compteurs
+	// d'avant la réparation à partir du journal
+	return rapport, ge.PrendreInstantane()
+}
+
+func (ge *GestionnaireEmprunts) controlerIntegrite(maintenant time.Time) (etatIntegrite, RapportIntegrite) {
+	gl, gm, gr := ge.gestionnaireLivres, ge.gestionnaireMembres, ge.gestionnaireReservations
+	etat := etatIntegrite{
+		livres:       append([]models.Livre(nil), gl.livres...),
+		membres:      append([]models.Membre(nil), gm.membres...),
+		emprunts:     append([]models.Emprunt(nil), ge.emprunts...),
+		reservations: append([]models.Reservation(nil), gr.reservations...),
+
+		prochainLivre:       gl.sequence.Prochain(),
+		prochainMembre:      gm.sequence.Prochain(),
+		prochainEmprunt:     ge.sequence.Prochain(),
+		prochainReservation: gr.sequence.Prochain(),
+	}
+	if ge.gestionnairePEB != nil {
+		etat.livresPEB = ge.gestionnairePEB.livresPEB()
+	}
+	rapport := RapportIntegrite{
+		Livres:       len(etat.livres),
+		Membres:      len(etat.membres),
+		Emprunts:     len(etat.emprunts),
+		Reservations: len(etat.reservations),
+	}
+	signaler := func(anomalie Anomalie) {
+		rapport.Anomalies = append(rapport.Anomalies, anomalie)
+	}
+
+	etat.renumeroterDoublons(signaler)
+	etat.separerUIDs(signaler)
+	etat.controlerReferences(maintenant, signaler)
+	etat.recalculerCompteurs(signaler)
+
+	return etat, rapport
+}
+
+// ========================================
+// IDENTIFIANTS EN DOUBLE
+// ========================================
+
+// renumeroterDoublons laisse son ID au premier enregistrement et en donne un
+// nouveau aux suivants. Les emprunts et réservations d'un livre ou d'un membre
+// renuméroté le suivent quand le titre ou le nom recopié permet de les départager.
+func (etat *etatIntegrite) renumeroterDoublons(signaler func(Anomalie)) {
+	prochain := etat.prochainLivre
+	for _, livre := range etat.livres {
+		prochain = max(prochain, livre.ID+1)
+	}
+	premiers := make(map[int]int)
+	for i := range etat.livres {
+		livre := &etat.livres[i]
+		j, vu := premiers[livre.ID]
+		if !vu {
+			premiers[livre.ID] = i
+			continue
+		}
+		premier := etat.livres[j]
+		ancien := livre.ID
+		livre.ID = prochain
+		prochain++
+		if livre.CodeBarres == models.CodeLivre(ancien) {
+			livre.CodeBarres = models.CodeLivre(livre.ID)
+		}
+
+		deplaces := 0
+		if livre.Titre != premier.Titre {
+			for k := range etat.emprunts {
+				if etat.emprunts[k].LivreID == ancien && etat.emprunts[k].TitreLivre == livre.Titre {
+					etat.emprunts[k].LivreID = livre.ID
+					deplaces++
+				}
+			}
+			for k := range etat.reservations {
+				if etat.reservations[k].LivreID == ancien && etat.reservations[k].TitreLivre == livre.Titre {
+					etat.reservations[k].LivreID = livre.ID
+					deplaces++
+				}
+			}
+		}
+
+		signaler(Anomalie{
+			Type:   ANOMALIE_DOUBLON,
+			Entite: "livre",
+			ID:     ancien,
+			Detail: fmt.Sprintf("« %s » a le même ID que « %s »%s", livre.Titre, premier.Titre, suiviDe(deplaces)),
+			Champ:  "id", Actuel: strconv.Itoa(ancien), Attendu: strconv.Itoa(livre.ID),
+			Reparable: true,
+		})
+	}
+
+	prochain = etat.prochainMembre
+	for _, membre := range etat.membres {
+		prochain = max(prochain, membre.ID+1)
+	}
+	premiers = make(map[int]int)
+	for i := range etat.membres {
+		membre := &etat.membres[i]
+		j, vu := premiers[membre.ID]
+		if !vu {
+			premiers[membre.ID] = i
+			continue
+		}
+		premier := etat.membres[j]
+		ancien := membre.ID
+		membre.ID = prochain
+		prochain++
+		if membre.NumeroCarte == models.NumeroCarte(ancien) {
+			membre.NumeroCarte = models.NumeroCarte(membre.ID)
+		}
+
+		deplaces := 0
+		if membre.Nom != premier.Nom {
+			for k := range etat.emprunts {
+				if etat.emprunts[k].MembreID == ancien && etat.emprunts[k].NomMembre == membre.Nom {
+					etat.emprunts[k].MembreID = membre.ID
+					deplaces++
+				}
+			}
+			for k := range etat.reservations {
+				if etat.reservations[k].MembreID == ancien && etat.reservations[k].NomMembre == membre.Nom {
+					etat.reservations[k].MembreID = membre.ID
+					deplaces++
+				}
+			}
+		}
+
+		signaler(Anomalie{
+			Type:   ANOMALIE_DOUBLON,
+			Entite: "membre",
+			ID:     ancien,
+			Detail: fmt.Sprintf("%s a le même ID que %s%s", membre.Nom, premier.Nom, suiviDe(deplaces)),
+			Champ:  "id", Actuel: strconv.Itoa(ancien), Attendu: strconv.Itoa(membre.ID),
+			Reparable: true,
+		})
+	}
+
+	prochain = etat.prochainEmprunt
+	for _, emprunt := range etat.emprunts {
+		prochain = max(prochain, emprunt.ID+1)
+	}
+	vus := make(map[int]bool)
+	for i := range etat.emprunts {
+		emprunt := &etat.emprunts[i]
+		if !vus[emprunt.ID] {
+			vus[emprunt.ID] = true
+			continue
+		}
+		signaler(Anomalie{
+			Type:   ANOMALIE_DOUBLON,
+			Entite: "emprunt",
+			ID:     emprunt.ID,
+			Detail: fmt.Sprintf("l'emprunt de « %s » par %s a le même ID qu'un autre emprunt", emprunt.TitreLivre, emprunt.NomMembre),
+			Champ:  "id", Actuel: strconv.Itoa(emprunt.ID), Attendu: strconv.Itoa(prochain),
+			Reparable: true,
+		})
+		emprunt.ID = prochain
+		prochain++
+	}
+
+	prochain = etat.prochainReservation
+	for _, reservation := range etat.reservations {
+		prochain = max(prochain, reservation.ID+1)
+	}
+	vus = make(map[int]bool)
+	for i := range etat.reservations {
+		reservation := &etat.reservations[i]
+		if !vus[reservation.ID] {
+			vus[reservation.ID] = true
+			continue
+		}
+		signaler(Anomalie{
+			Type:   ANOMALIE_DOUBLON,
+			Entite: "réservation",
+			ID:     reservation.ID,
+			Detail: fmt.Sprintf("la réservation de « %s » par %s a le même ID qu'une autre réservation", reservation.TitreLivre, reservation.NomMembre),
+			Champ:  "id", Actuel: strconv.Itoa(reservation.ID), Attendu: strconv.Itoa(prochain),
+			Reparable: true,
+		})
+		reservation.ID = prochain
+		prochain++
+	}
+}
+
+// separerUIDs donne un nouvel UID au second de deux enregistrements qui
+// partagent le même : c'est une copie, qui doit pouvoir être distinguée de
+// l'original une fois les données rapprochées d'un autre poste
+func (etat *etatIntegrite) separerUIDs(signaler func(Anomalie)) {
+	separer := func(uid *string, vus map[string]bool, anomalie Anomalie) {
+		if *uid == "" {
+			return
+		}
+		if !vus[*uid] {
+			vus[*uid] = true
+			return
+		}
+		anomalie.Type = ANOMALIE_DOUBLON
+		anomalie.Champ, anomalie.Actuel = "uid", *uid
+		anomalie.Attendu = models.NouvelUID()
+		anomalie.Reparable = true
+		signaler(anomalie)
+		*uid = anomalie.Attendu
+	}
+
+	vus := make(map[string]bool)
+	for i := range etat.livres {
+		livre := &etat.livres[i]
+		separer(&livre.UID, vus, Anomalie{Entite: "livre", ID: livre.ID,
+			Detail: fmt.Sprintf("« %s » a le même UID qu'un autre livre", livre.Titre)})
+	}
+	vus = make(map[string]bool)
+	for i := range etat.membres {
+		membre := &etat.membres[i]
+		separer(&membre.UID, vus, Anomalie{Entite: "membre", ID: membre.ID,
+			Detail: fmt.Sprintf("%s a le même UID qu'un autre membre", membre.Nom)})
+	}
+	vus = make(map[string]bool)
+	for i := range etat.emprunts {
+		emprunt := &etat.emprunts[i]
+		separer(&emprunt.UID, vus, Anomalie{Entite: "emprunt", ID: emprunt.ID,
+			Detail: fmt.Sprintf("l'emprunt de « %s » par %s a le même UID qu'un autre emprunt", emprunt.TitreLivre, emprunt.NomMembre)})
+	}
+	vus = make(map[string]bool)
+	for i := range etat.reservations {
+		reservation := &etat.reservations[i]
+		separer(&reservation.UID, vus, Anomalie{Entite: "réservation", ID: reservation.ID,
+			Detail: fmt.Sprintf("la réservation de « %s » par %s a le même UID qu'une autre réservation", reservation.TitreLivre, reservation.NomMembre)})
+	}
+}
+
+func suiviDe(deplaces int) string {
+	if deplaces == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d emprunt(s) ou réservation(s) le suivent)", deplaces)
+}
+
+// ========================================
+// RÉFÉRENCES ET NOMS RECOPIÉS
+// ========================================
+
+// controlerReferences signale les emprunts et réservations dont le livre ou le
+// membre n'existe plus, et remet à jour les titres et noms recopiés. Un emprunt
+// orphelin encore en cours est clos : plus personne ne pourrait le rendre. Les
+// emprunts orphelins terminés restent dans l'historique ; ceux d'un livre prêté
+// par une autre bibliothèque, puis renvoyé, ne sont pas signalés.
+func (etat *etatIntegrite) controlerReferences(maintenant time.Time, signaler func(Anomalie)) {
+	livres := make(map[int]*models.Livre)
+	for i := range etat.livres {
+		livres[etat.livres[i].ID] = &etat.livres[i]
+	}
+	membres := make(map[int]*models.Membre)
+	for i := range etat.membres {
+		membres[etat.membres[i].ID] = &etat.membres[i]
+	}
+
+	for i := range etat.emprunts {
+		emprunt := &etat.emprunts[i]
+		livre, membre := livres[emprunt.LivreID], membres[emprunt.MembreID]
+
+		// Le livre d'un prêt entre bibliothèques quitte le catalogue une fois renvoyé
+		renvoye := livre == nil && membre != nil && emprunt.DateRetourEffectif != nil && etat.livresPEB[emprunt.LivreID]
+
+		if (livre == nil || membre == nil) && !renvoye {
+			manquant := fmt.Sprintf("le livre #%d, qui n'existe plus", emprunt.LivreID)
+			if livre != nil {
+				manquant = fmt.Sprintf("le membre #%d, qui n'existe plus", emprunt.MembreID)
+			} else if membre == nil {
+				manquant = fmt.Sprintf("le livre #%d et le membre #%d, qui n'existent plus", emprunt.LivreID, emprunt.MembreID)
+			}
+			anomalie := Anomalie{
+				Type:   ANOMALIE_ORPHELIN,
+				Entite: "emprunt",
+				ID:     emprunt.ID,
+				Detail: fmt.Sprintf("l'emprunt de « %s » par %s désigne %s", emprunt.TitreLivre, emprunt.NomMembre, manquant),
+			}
+			if emprunt.DateRetourEffectif == nil {
+				anomalie.Detail += " : il est clos"
+				anomalie.Champ, anomalie.Actuel, anomalie.Attendu = "statut", emprunt.Statut, models.STATUT_RENDU
+				anomalie.Reparable = true
+				retour := maintenant
+				emprunt.DateRetourEffectif = &retour
+				emprunt.Statut = models.STATUT_RENDU
+			} else {
+				anomalie.Detail += " : l'emprunt reste dans l'historique"
+			}
+			signaler(anomalie)
+		}
+
+		if livre != nil && emprunt.TitreLivre != livre.Titre {
+			signaler(Anomalie{
+				Type: ANOMALIE_NOM, Entite: "emprunt", ID: emprunt.ID,
+				Detail: fmt.Sprintf("le titre recopié ne correspond plus au livre #%d", livre.ID),
+				Champ:  "titre_livre", Actuel: emprunt.TitreLivre, Attendu: livre.Titre,
+				Reparable: true,
+			})
+			emprunt.TitreLivre = livre.Titre
+		}
+		if membre != nil && emprunt.NomMembre != membre.Nom {
+			signaler(Anomalie{
+				Type: ANOMALIE_NOM, Entite: "emprunt", ID: emprunt.ID,
+				Detail: fmt.Sprintf("le nom recopié ne correspond plus au membre #%d", membre.ID),
+				Champ:  "nom_membre", Actuel: emprunt.NomMembre, Attendu: membre.Nom,
+				Reparable: true,
+			})
+			emprunt.NomMembre = membre.Nom
+		}
+	}
+
+	for i := range etat.reservations {
+		reservation := &etat.reservations[i]
+		livre, membre := livres[reservation.LivreID], membres[reservation.MembreID]
+
+		// Seules les réservations encore dans la file d'attente comptent
+		if (livre == nil || membre == nil) && reservation.EstActive() {
+			signaler(Anomalie{
+				Type: ANOMALIE_ORPHELIN, Entite: "réservation", ID: reservation.ID,
+				Detail: fmt.Sprintf("la réservation de « %s » par %s désigne un livre ou un membre qui n'existe plus : elle est annulée", reservation.TitreLivre, reservation.NomMembre),
+				Champ:  "statut", Actuel: reservation.Statut, Attendu: models.RESERVATION_ANNULEE,
+				Reparable: true,
+			})
+			cloture := maintenant
+			reservation.Statut = models.RESERVATION_ANNULEE
+			reservation.DateCloture = &cloture
+		}
+
+		if livre != nil && reservation.TitreLivre != livre.Titre {
+			signaler(Anomalie{
+				Type: ANOMALIE_NOM, Entite: "réservation", ID: reservation.ID,
+				Detail: fmt.Sprintf("le titre recopié ne correspond plus au livre #%d", livre.ID),
+				Champ:  "titre_livre", Actuel: reservation.TitreLivre, Attendu: livre.Titre,
+				Reparable: true,
+			})
+			reservation.TitreLivre = livre.Titre
+		}
+		if membre != nil && reservation.NomMembre != membre.Nom {
+			signaler(Anomalie{
+				Type: ANOMALIE_NOM, Entite: "réservation", ID: reservation.ID,
+				Detail: fmt.Sprintf("le nom recopié ne correspond plus au membre #%d", membre.ID),
+				Champ:  "nom_membre", Actuel: reservation.NomMembre, Attendu: membre.Nom,
+				Reparable: true,
+			})
+			reservation.NomMembre = membre.Nom
+		}
+	}
+}
+
+// ========================================
+// COMPTEURS
+// ========================================
+
+// recalculerCompteurs déduit de l'historique la disponibilité des livres et les
+// compteurs d'emprunts. Les emprunts supprimés par le nettoyage sont comptés
+// dans EmpruntsArchives. Un livre perdu pendant son dernier emprunt reste
+// indisponible tant qu'il n'est pas restauré.
+func (etat *etatIntegrite) recalculerCompteurs(signaler func(Anomalie)) {
+	enCoursLivre, totalLivre := make(map[int]int), make(map[int]int)
+	enCoursMembre, totalMembre := make(map[int]int), make(map[int]int)
+	dernierClos := make(map[int]models.Emprunt)
+	for _, emprunt := range etat.emprunts {
+		totalLivre[emprunt.LivreID]++
+		totalMembre[emprunt.MembreID]++
+		if emprunt.DateRetourEffectif == nil {
+			enCoursLivre[emprunt.LivreID]++
+			enCoursMembre[emprunt.MembreID]++
+		} else if dernier, ok := dernierClos[emprunt.LivreID]; !ok || emprunt.DateRetourEffectif.After(*dernier.DateRetourEffectif) {
+			dernierClos[emprunt.LivreID] = emprunt
+		}
+	}
+
+	for i := range etat.livres {
+		livre := &etat.livres[i]
+		enCours := enCoursLivre[livre.ID]
+
+		if enCours > 1 {
+			signaler(Anomalie{
+				Type: ANOMALIE_INCOHERENCE, Entite: "livre", ID: livre.ID,
+				Detail: fmt.Sprintf("« %s » a %d emprunts en cours en même temps : rendez ou annulez ceux qui sont en trop", livre.Titre, enCours),
+			})
+		}
+		if enCours > 0 && livre.EstRetire() {
+			signaler(Anomalie{
+				Type: ANOMALIE_INCOHERENCE, Entite: "livre", ID: livre.ID,
+				Detail: fmt.Sprintf("« %s » est retiré du fonds mais encore emprunté : enregistrez le retour ou restaurez-le", livre.Titre),
+			})
+		}
+
+		perdu := livre.EstRetire() && dernierClos[livre.ID].EstPerdu()
+		if disponible := enCours == 0 && !perdu; livre.Disponible != disponible {
+			signaler(Anomalie{
+				Type: ANOMALIE_COMPTEUR, Entite: "livre", ID: livre.ID,
+				Detail: fmt.Sprintf("la disponibilité de « %s » contredit ses emprunts en cours", livre.Titre),
+				Champ:  "disponible", Actuel: strconv.FormatBool(livre.Disponible), Attendu: strconv.FormatBool(disponible),
+				Reparable: true,
+			})
+			livre.Disponible = disponible
+		}
+		if total := totalLivre[livre.ID] + livre.EmpruntsArchives; livre.NombreEmprunts != total {
+			signaler(Anomalie{
+				Type: ANOMALIE_COMPTEUR, Entite: "livre", ID: livre.ID,
+				Detail: fmt.Sprintf("le nombre d'emprunts de « %s » ne correspond pas à l'historique", livre.Titre),
+				Champ:  "nombre_emprunts", Actuel: strconv.Itoa(livre.NombreEmprunts), Attendu: strconv.Itoa(total),
+				Reparable: true,
+			})
+			livre.NombreEmprunts = total
+		}
+	}
+
+	for i := range etat.membres {
+		membre := &etat.membres[i]
+		if enCours := enCoursMembre[membre.ID]; membre.EmpruntsActifs != enCours {
+			signaler(Anomalie{
+				Type: ANOMALIE_COMPTEUR, Entite: "membre", ID: membre.ID,
+				Detail: fmt.Sprintf("les emprunts actifs de %s ne correspondent pas à ses emprunts en cours", membre.Nom),
+				Champ:  "emprunts_actifs", Actuel: strconv.Itoa(membre.EmpruntsActifs), Attendu: strconv.Itoa(enCours),
+				Reparable: true,
+			})
+			membre.EmpruntsActifs = enCours
+		}
+		if total := totalMembre[membre.ID] + membre.EmpruntsArchives; membre.NombreEmprunts != total {
+			signaler(Anomalie{
+				Type: ANOMALIE_COMPTEUR, Entite: "membre", ID: membre.ID,
+				Detail: fmt.Sprintf("le nombre d'emprunts de %s ne correspond pas à l'historique", membre.Nom),
+				Champ:  "nombre_emprunts", Actuel: strconv.Itoa(membre.NombreEmprunts), Attendu: strconv.Itoa(total),
+				Reparable: true,
+			})
+			membre.NombreEmprunts = total
+		}
+	}
+}
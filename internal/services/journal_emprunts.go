@@ -0,0 +1,618 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// JOURNAL DES EMPRUNTS
+// Chaque emprunt, retour, prolongation, annulation et chaque suspension de
+// membre est d'abord écrit dans un journal en ajout seul. Les emprunts, la
+// disponibilité des livres et les compteurs des membres sont des projections
+// de ce journal : emprunts.json, livres.json et membres.json n'en gardent que
+// la dernière image. Un instantané régulier évite de tout relire au démarrage.
+// ========================================
+
+// INTERVALLE_INSTANTANES est le nombre d'événements entre deux instantanés
+const INTERVALLE_INSTANTANES = 200
+
+// compteursLivre regroupe les champs d'un livre déduits du journal
+type compteursLivre struct {
+	ID               int  `json:"id"`
+	Disponible       bool `json:"disponible"`
+	NombreEmprunts   int  `json:"nombre_emprunts"`
+	EmpruntsArchives int  `json:"emprunts_archives,omitempty"`
+}
+
+// compteursMembre regroupe les champs d'un membre déduits du journal
+type compteursMembre struct {
+	ID               int  `json:"id"`
+	Actif            bool `json:"actif"`
+	EmpruntsActifs   int  `json:"emprunts_actifs"`
+	NombreEmprunts   int  `json:"nombre_emprunts"`
+	EmpruntsArchives int  `json:"emprunts_archives,omitempty"`
+}
+
+// instantaneEmprunts fige les projections juste après l'événement Numero
+type instantaneEmprunts struct {
+	Numero   int               `json:"numero"`
+	Date     time.Time         `json:"date"`
+	Emprunts []models.Emprunt  `json:"emprunts"`
+	Livres   []compteursLivre  `json:"livres"`
+	Membres  []compteursMembre `json:"membres"`
+}
+
+// donneesInstantanes est le contenu du fichier des instantanés. L'origine est
+// l'état à l'ouverture du journal (vide pour une nouvelle installation, les
+// données existantes sinon) : elle n'est jamais remplacée, c'est d'elle que
+// part une relecture complète.
+type donneesInstantanes struct {
+	Origine instantaneEmprunts  `json:"origine"`
+	Dernier *instantaneEmprunts `json:"dernier,omitempty"`
+}
+
+// EtatEmprunts décrit les emprunts à une date passée, reconstitués depuis le journal
+type EtatEmprunts struct {
+	Date       time.Time
+	Evenements int              // numéro du dernier événement pris en compte
+	EnCours    []models.Emprunt // emprunts en cours à cette date, statut calculé à cette date
+	Suspendus  []string         // membres suspendus à cette date
+}
+
+// ========================================
+// PROJECTION
+// ========================================
+
+type projectionEmprunts struct {
+	emprunts []models.Emprunt
+	livres   map[int]*compteursLivre
+	membres  map[int]*compteursMembre
+	ecartes  []empruntEcarte
+}
+
+// empruntEcarte est un emprunt refusé à la relecture : le livre était déjà
+// prêté par un emprunt antérieur, enregistré sur un autre poste
+type empruntEcarte struct {
+	Evenement models.Evenement
+	Retenu    models.Emprunt
+}
+
+func projectionDepuis(instantane instantaneEmprunts) *projectionEmprunts {
+	p := &projectionEmprunts{
+		emprunts: append([]models.Emprunt{}, instantane.Emprunts...),
+		livres:   make(map[int]*compteursLivre),
+		membres:  make(map[int]*compteursMembre),
+	}
+	for i := range p.emprunts {
+		p.emprunts[i].UID = uidEmprunt(p.emprunts[i])
+	}
+	for _, compteurs := range instantane.Livres {
+		compteurs := compteurs
+		p.livres[compteurs.ID] = &compteurs
+	}
+	for _, compteurs := range instantane.Membres {
+		compteurs := compteurs
+		p.membres[compteurs.ID] = &compteurs
+	}
+	return p
+}
+
+// uidEmprunt retourne l'UID de l'emprunt ; les emprunts enregistrés avant les
+// UID en reçoivent un tiré de leur ID et de leur date, identique à chaque relecture
+func uidEmprunt(emprunt models.Emprunt) string {
+	if emprunt.UID != "" {
+		return emprunt.UID
+	}
+	return models.UIDAncien(emprunt.DateEmprunt, fmt.Sprintf("emprunt:%d:%d:%d", emprunt.ID, emprunt.LivreID, emprunt.MembreID))
+}
+
+// livre retourne les compteurs d'un livre ; un livre encore jamais emprunté est disponible
+func (p *projectionEmprunts) livre(id int) *compteursLivre {
+	if p.livres[id] == nil {
+		p.livres[id] = &compteursLivre{ID: id, Disponible: true}
+	}
+	return p.livres[id]
+}
+
+// membre retourne les compteurs d'un membre ; un membre encore jamais suspendu est actif
+func (p *projectionEmprunts) membre(id int) *compteursMembre {
+	if p.membres[id] == nil {
+		p.membres[id] = &compteursMembre{ID: id, Actif: true}
+	}
+	return p.membres[id]
+}
+
+func (p *projectionEmprunts) emprunt(id int) *models.Emprunt {
+	for i := range p.emprunts {
+		if p.emprunts[i].ID == id {
+			return &p.emprunts[i]
+		}
+	}
+	return nil
+}
+
+// empruntEnCours retourne l'emprunt en cours du livre, s'il y en a un
+func (p *projectionEmprunts) empruntEnCours(livreID int) *models.Emprunt {
+	for i := range p.emprunts {
+		if p.emprunts[i].LivreID == livreID && p.emprunts[i].DateRetourEffectif == nil {
+			return &p.emprunts[i]
+		}
+	}
+	return nil
+}
+
+// appliquer fait évoluer la projection d'un événement. C'est le seul endroit
+// où les emprunts et les compteurs changent, en direct comme en relecture.
+func (p *projectionEmprunts) appliquer(evenement models.Evenement) {
+	switch evenement.Type {
+	case models.EVENEMENT_LIVRE_EMPRUNTE:
+		// Deux postes hors ligne ont prêté le même livre : le premier emprunt
+		// est retenu, le second écarté. Les événements antérieurs à la
+		// synchronisation (sans UID) sont appliqués comme avant.
+		if enCours := p.empruntEnCours(evenement.LivreID); enCours != nil && evenement.UID != "" {
+			p.ecartes = append(p.ecartes, empruntEcarte{Evenement: evenement, Retenu: *enCours})
+			return
+		}
+		emprunt := models.Emprunt{
+			ID:              evenement.EmpruntID,
+			UID:             evenement.EmpruntUID,
+			LivreID:         evenement.LivreID,
+			MembreID:        evenement.MembreID,
+			DateEmprunt:     evenement.Date,
+			DateRetourPrevu: evenement.DateRetourPrevu,
+			Statut:          models.STATUT_EN_COURS,
+			SuccursaleID:    evenement.SuccursaleID,
+			TitreLivre:      evenement.TitreLivre,
+			NomMembre:       evenement.NomMembre,
+		}
+		emprunt.UID = uidEmprunt(emprunt)
+		p.emprunts = append(p.emprunts, emprunt)
+		livre := p.livre(evenement.LivreID)
+		livre.Disponible = false
+		livre.NombreEmprunts++
+		membre := p.membre(evenement.MembreID)
+		membre.EmpruntsActifs++
+		membre.NombreEmprunts++
+
+	case models.EVENEMENT_LIVRE_RENDU:
+		emprunt := p.emprunt(evenement.EmpruntID)
+		if emprunt == nil || emprunt.DateRetourEffectif != nil {
+			return
+		}
+		retour := evenement.Date
+		emprunt.DateRetourEffectif = &retour
+		emprunt.Statut = models.STATUT_RENDU
+		emprunt.SuccursaleRetour = evenement.SuccursaleID
+		p.livre(emprunt.LivreID).Disponible = true
+		if membre := p.membre(emprunt.MembreID); membre.EmpruntsActifs > 0 {
+			membre.EmpruntsActifs--
+		}
+
+	case models.EVENEMENT_LIVRE_PERDU:
+		// Un livre perdu clôt l'emprunt sans retour : le membre n'en répond
+		// plus, mais le livre ne revient pas en rayon et reste indisponible
+		emprunt := p.emprunt(evenement.EmpruntID)
+		if emprunt == nil || emprunt.DateRetourEffectif != nil {
+			return
+		}
+		perte := evenement.Date
+		emprunt.DateRetourEffectif = &perte
+		emprunt.Statut = models.STATUT_PERDU
+		p.livre(emprunt.LivreID).Disponible = false
+		if membre := p.membre(emprunt.MembreID); membre.EmpruntsActifs > 0 {
+			membre.EmpruntsActifs--
+		}
+
+	case models.EVENEMENT_LIVRE_RESTAURE:
+		// Un livre retrouvé revient en rayon, sauf s'il est encore prêté
+		p.livre(evenement.LivreID).Disponible = p.empruntEnCours(evenement.LivreID) == nil
+
+	case models.EVENEMENT_EMPRUNT_PROLONGE:
+		if emprunt := p.emprunt(evenement.EmpruntID); emprunt != nil {
+			emprunt.DateRetourPrevu = evenement.DateRetourPrevu
+			emprunt.Prolongations++
+		}
+
+	case models.EVENEMENT_EMPRUNT_ANNULE:
+		// Un emprunt annulé n'a jamais eu lieu : il ne compte plus nulle part
+		for i, emprunt := range p.emprunts {
+			if emprunt.ID != evenement.EmpruntID {
+				continue
+			}
+			p.emprunts = append(p.emprunts[:i:i], p.emprunts[i+1:]...)
+			livre, membre := p.livre(emprunt.LivreID), p.membre(emprunt.MembreID)
+			if emprunt.DateRetourEffectif == nil {
+				livre.Disponible = true
+				membre.EmpruntsActifs = max(membre.EmpruntsActifs-1, 0)
+			}
+			livre.NombreEmprunts = max(livre.NombreEmprunts-1, 0)
+			membre.NombreEmprunts = max(membre.NombreEmprunts-1, 0)
+			break
+		}
+
+	case models.EVENEMENT_MEMBRE_SUSPENDU:
+		p.membre(evenement.MembreID).Actif = false
+
+	case models.EVENEMENT_MEMBRE_REACTIVE:
+		p.membre(evenement.MembreID).Actif = true
+
+	case models.EVENEMENT_HISTORIQUE_NETTOYE:
+		// Les emprunts retirés de la liste restent comptés dans EmpruntsArchives
+		garder := []models.Emprunt{}
+		for _, emprunt := range p.emprunts {
+			if emprunt.DateRetourEffectif == nil || emprunt.DateEmprunt.After(evenement.DateLimite) {
+				garder = append(garder, emprunt)
+				continue
+			}
+			p.livre(emprunt.LivreID).EmpruntsArchives++
+			p.membre(emprunt.MembreID).EmpruntsArchives++
+		}
+		p.emprunts = garder
+	}
+}
+
+// ========================================
+// JOURNAL ET INSTANTANÉS
+// ========================================
+
+// chargerJournal relit le journal et reconstruit les projections à partir du
+// dernier instantané. À la première ouverture, les données existantes
+// deviennent l'origine du journal.
+func (ge *GestionnaireEmprunts) chargerJournal() error {
+	var donnees donneesInstantanes
+	if err := ge.instantanes.Charger(&donnees); err != nil {
+		return err
+	}
+
+	ge.evenements = nil
+	err := ge.journal.Relire(func(ligne []byte) error {
+		var evenement models.Evenement
+		if err := json.Unmarshal(ligne, &evenement); err != nil {
+			return err
+		}
+		ge.evenements = append(ge.evenements, evenement)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Les IDs des emprunts annulés ou nettoyés ne doivent pas être redonnés
+	for _, evenement := range ge.evenements {
+		ge.dernierNumero = max(ge.dernierNumero, evenement.Numero)
+		ge.sequence.Observer(evenement.EmpruntID)
+	}
+
+	if donnees.Origine.Date.IsZero() {
+		donnees.Origine = ge.instantane()
+		donnees.Origine.Numero = 0
+		if err := ge.instantanes.Sauvegarder(donnees); err != nil {
+			return err
+		}
+	}
+	ge.origine = donnees.Origine
+	ge.dernierInstantane = donnees.Dernier
+
+	base := ge.origine
+	if ge.dernierInstantane != nil {
+		base = *ge.dernierInstantane
+	}
+	// Un événement reçu d'un autre poste peut être antérieur à l'instantané :
+	// tout est alors relu depuis l'origine
+	for _, evenement := range ge.evenements {
+		if evenement.Numero > base.Numero && evenement.Date.Before(base.Date) {
+			base = ge.origine
+			break
+		}
+	}
+
+	ge.projection = projectionDepuis(base)
+	for _, evenement := range ordreCanonique(ge.evenements) {
+		if evenement.Numero > base.Numero {
+			ge.projection.appliquer(evenement)
+		}
+	}
+	ge.adopter(ge.projection)
+	return nil
+}
+
+// ordreCanonique retourne les événements dans l'ordre où ils sont rejoués :
+// par date, puis par UID. Deux postes qui ont les mêmes événements, reçus
+// dans un ordre différent, en déduisent ainsi les mêmes emprunts.
+func ordreCanonique(evenements []models.Evenement) []models.Evenement {
+	ordonnes := append([]models.Evenement(nil), evenements...)
+	sort.SliceStable(ordonnes, func(i, j int) bool {
+		if !ordonnes[i].Date.Equal(ordonnes[j].Date) {
+			return ordonnes[i].Date.Before(ordonnes[j].Date)
+		}
+		return ordonnes[i].UID < ordonnes[j].UID
+	})
+	return ordonnes
+}
+
+// uidEvenement retourne l'UID de l'événement ; ceux écrits avant les UID en
+// reçoivent un tiré de leur numéro et de leur date, le même sur deux postes
+// qui partagent ce début de journal
+func uidEvenement(evenement models.Evenement) string {
+	if evenement.UID != "" {
+		return evenement.UID
+	}
+	return models.UIDAncien(evenement.Date, fmt.Sprintf("evenement:%d:%s:%d", evenement.Numero, evenement.Type, evenement.EmpruntID))
+}
+
+// completer ajoute à l'événement les UID du livre, du membre et de l'emprunt
+// qu'il désigne, pour qu'un autre poste les retrouve sous ses propres numéros
+func (ge *GestionnaireEmprunts) completer(evenement *models.Evenement) {
+	if evenement.LivreUID == "" && evenement.LivreID != 0 {
+		if livre, _ := ge.gestionnaireLivres.TrouverLivreParID(evenement.LivreID); livre != nil {
+			evenement.LivreUID = livre.UID
+		}
+	}
+	if evenement.MembreUID == "" && evenement.MembreID != 0 {
+		if membre, _ := ge.gestionnaireMembres.TrouverMembreParID(evenement.MembreID); membre != nil {
+			evenement.MembreUID = membre.UID
+		}
+	}
+	if evenement.EmpruntUID == "" && evenement.EmpruntID != 0 {
+		evenement.EmpruntUID = ge.uidsEmprunts()[evenement.EmpruntID]
+	}
+}
+
+// uidsEmprunts associe le numéro de chaque emprunt connu du journal à son UID,
+// y compris les emprunts annulés, nettoyés ou écartés
+func (ge *GestionnaireEmprunts) uidsEmprunts() map[int]string {
+	uids := make(map[int]string)
+	for _, emprunt := range ge.origine.Emprunts {
+		uids[emprunt.ID] = uidEmprunt(emprunt)
+	}
+	for _, emprunt := range ge.emprunts {
+		uids[emprunt.ID] = emprunt.UID
+	}
+	for _, evenement := range ge.evenements {
+		if evenement.Type == models.EVENEMENT_LIVRE_EMPRUNTE && evenement.EmpruntUID != "" {
+			uids[evenement.EmpruntID] = evenement.EmpruntUID
+		}
+	}
+	return uids
+}
+
+// enregistrer écrit l'événement dans le journal, l'applique aux projections
+// puis enregistre leur image. Rien ne change si l'écriture dans le journal échoue.
+func (ge *GestionnaireEmprunts) enregistrer(evenement models.Evenement) error {
+	evenement.Numero = ge.dernierNumero + 1
+	if evenement.Date.IsZero() {
+		evenement.Date = time.Now()
+	}
+	if evenement.UID == "" {
+		evenement.UID = models.NouvelUID()
+		evenement.Instance = ge.instance
+	}
+	ge.completer(&evenement)
+	if err := ge.journal.Ajouter(evenement); err != nil {
+		return err
+	}
+	ge.dernierNumero = evenement.Numero
+	ge.evenements = append(ge.evenements, evenement)
+
+	ge.projection.appliquer(evenement)
+	ge.adopter(ge.projection)
+	if err := ge.sauvegarderProjections(); err != nil {
+		return err
+	}
+
+	depuis := ge.origine.Numero
+	if ge.dernierInstantane != nil {
+		depuis = ge.dernierInstantane.Numero
+	}
+	if ge.dernierNumero-depuis >= INTERVALLE_INSTANTANES {
+		return ge.PrendreInstantane()
+	}
+	return nil
+}
+
+// ajouterRecu ajoute au journal un événement reçu d'un autre poste, déjà
+// traduit dans les numéros de ce poste. Il garde sa date, son UID et son
+// poste d'origine ; la projection est recalculée par Rejouer une fois le lot
+// intégré, car l'événement peut être antérieur aux derniers enregistrés ici.
+func (ge *GestionnaireEmprunts) ajouterRecu(evenement models.Evenement) error {
+	evenement.Numero = ge.dernierNumero + 1
+	if err := ge.journal.Ajouter(evenement); err != nil {
+		return err
+	}
+	ge.dernierNumero = evenement.Numero
+	ge.evenements = append(ge.evenements, evenement)
+	return nil
+}
+
+// adopter reporte la projection dans les emprunts, les livres et les membres.
+// Seul le premier d'un ID en double est mis à jour : les doublons relèvent du
+// contrôle d'intégrité.
+func (ge *GestionnaireEmprunts) adopter(p *projectionEmprunts) {
+	ge.emprunts = p.emprunts
+
+	vus := make(map[int]bool)
+	livres := ge.gestionnaireLivres.livres
+	for i := range livres {
+		if vus[livres[i].ID] {
+			continue
+		}
+		vus[livres[i].ID] = true
+		compteurs := p.livre(livres[i].ID)
+		livres[i].Disponible = compteurs.Disponible
+		livres[i].NombreEmprunts = compteurs.NombreEmprunts
+		livres[i].EmpruntsArchives = compteurs.EmpruntsArchives
+	}
+
+	vus = make(map[int]bool)
+	membres := ge.gestionnaireMembres.membres
+	for i := range membres {
+		if vus[membres[i].ID] {
+			continue
+		}
+		vus[membres[i].ID] = true
+		compteurs := p.membre(membres[i].ID)
+		membres[i].Actif = compteurs.Actif
+		membres[i].EmpruntsActifs = compteurs.EmpruntsActifs
+		membres[i].NombreEmprunts = compteurs.NombreEmprunts
+		membres[i].EmpruntsArchives = compteurs.EmpruntsArchives
+	}
+}
+
+func (ge *GestionnaireEmprunts) sauvegarderProjections() error {
+	if err := ge.gestionnaireLivres.sauvegarderLivres(); err != nil {
+		return err
+	}
+	if err := ge.gestionnaireMembres.SauvegarderMembres(); err != nil {
+		return err
+	}
+	return ge.sauvegarderEmprunts()
+}
+
+// instantane fige l'état courant des emprunts, des livres et des membres
+func (ge *GestionnaireEmprunts) instantane() instantaneEmprunts {
+	instantane := instantaneEmprunts{
+		Numero:   ge.dernierNumero,
+		Date:     time.Now(),
+		Emprunts: append([]models.Emprunt{}, ge.emprunts...),
+		Livres:   []compteursLivre{},
+		Membres:  []compteursMembre{},
+	}
+
+	vus := make(map[int]bool)
+	for _, livre := range ge.gestionnaireLivres.livres {
+		if !vus[livre.ID] {
+			vus[livre.ID] = true
+			instantane.Livres = append(instantane.Livres, compteursLivre{
+				ID: livre.ID, Disponible: livre.Disponible, NombreEmprunts: livre.NombreEmprunts, EmpruntsArchives: livre.EmpruntsArchives,
+			})
+		}
+	}
+	vus = make(map[int]bool)
+	for _, membre := range ge.gestionnaireMembres.membres {
+		if !vus[membre.ID] {
+			vus[membre.ID] = true
+			instantane.Membres = append(instantane.Membres, compteursMembre{
+				ID: membre.ID, Actif: membre.Actif, EmpruntsActifs: membre.EmpruntsActifs, NombreEmprunts: membre.NombreEmprunts, EmpruntsArchives: membre.EmpruntsArchives,
+			})
+		}
+	}
+	return instantane
+}
+
+// PrendreInstantane enregistre l'état courant comme point de départ du
+// prochain démarrage : seuls les événements suivants seront relus
+func (ge *GestionnaireEmprunts) PrendreInstantane() error {
+	instantane := ge.instantane()
+	if err := ge.instantanes.Sauvegarder(donneesInstantanes{Origine: ge.origine, Dernier: &instantane}); err != nil {
+		return err
+	}
+	ge.dernierInstantane = &instantane
+
+	// Les emprunts écartés au dernier rejeu restent connus de la synchronisation
+	ecartes := ge.projection.ecartes
+	ge.projection = projectionDepuis(instantane)
+	ge.projection.ecartes = ecartes
+	ge.adopter(ge.projection)
+	return nil
+}
+
+// Rejouer reconstruit les emprunts et les compteurs à partir de l'origine et
+// de tout le journal, sans tenir compte des instantanés, puis en prend un
+// nouveau. Il retourne le nombre d'événements relus.
+func (ge *GestionnaireEmprunts) Rejouer() (int, error) {
+	p := projectionDepuis(ge.origine)
+	for _, evenement := range ordreCanonique(ge.evenements) {
+		p.appliquer(evenement)
+	}
+	ge.projection = p
+	ge.adopter(p)
+	ge.mettreAJourStatutsEmprunts()
+
+	if err := ge.sauvegarderProjections(); err != nil {
+		return 0, err
+	}
+	return len(ge.evenements), ge.PrendreInstantane()
+}
+
+// ========================================
+// CONSULTATION DU JOURNAL
+// ========================================
+
+// ListerEvenements retourne les derniers événements du journal, du plus récent au plus ancien
+func (ge *GestionnaireEmprunts) ListerEvenements(nombre int) []models.Evenement {
+	evenements := make([]models.Evenement, 0, min(nombre, len(ge.evenements)))
+	for i := len(ge.evenements) - 1; i >= 0 && len(evenements) < nombre; i-- {
+		evenements = append(evenements, ge.evenements[i])
+	}
+	return evenements
+}
+
+// DebutJournal retourne la date à partir de laquelle le journal connaît les emprunts
+func (ge *GestionnaireEmprunts) DebutJournal() time.Time {
+	return ge.origine.Date
+}
+
+// InfosJournal retourne le nombre d'événements et le numéro du dernier instantané
+func (ge *GestionnaireEmprunts) InfosJournal() (evenements, instantane int) {
+	if ge.dernierInstantane != nil {
+		instantane = ge.dernierInstantane.Numero
+	}
+	return len(ge.evenements), instantane
+}
+
+// EtatAu reconstitue les emprunts en cours et les membres suspendus à une date
+// passée, en rejouant le journal jusqu'à cette date
+func (ge *GestionnaireEmprunts) EtatAu(date time.Time) (EtatEmprunts, error) {
+	if date.Before(ge.origine.Date) {
+		return EtatEmprunts{}, fmt.Errorf("le journal commence le %s : l'état antérieur n'est pas connu",
+			ge.origine.Date.Format("02/01/2006 15:04"))
+	}
+
+	// Le dernier instantané sert de point de départ s'il est antérieur à la date
+	base := ge.origine
+	if ge.dernierInstantane != nil && !ge.dernierInstantane.Date.After(date) {
+		base = *ge.dernierInstantane
+	}
+	p := projectionDepuis(base)
+	etat := EtatEmprunts{Date: date, Evenements: base.Numero}
+	for _, evenement := range ordreCanonique(ge.evenements) {
+		if evenement.Numero <= base.Numero {
+			continue
+		}
+		if evenement.Date.After(date) {
+			break
+		}
+		p.appliquer(evenement)
+		etat.Evenements = max(etat.Evenements, evenement.Numero)
+	}
+
+	for _, emprunt := range p.emprunts {
+		if emprunt.DateRetourEffectif != nil {
+			continue
+		}
+		emprunt.Statut = models.STATUT_EN_COURS
+		if date.After(emprunt.DateRetourPrevu) {
+			emprunt.Statut = models.STATUT_EN_RETARD
+		}
+		etat.EnCours = append(etat.EnCours, emprunt)
+	}
+
+	for _, compteurs := range p.membres {
+		if compteurs.Actif {
+			continue
+		}
+		nom := fmt.Sprintf("membre #%d", compteurs.ID)
+		if membre, _ := ge.gestionnaireMembres.TrouverMembreParID(compteurs.ID); membre != nil {
+			nom = membre.Nom
+		}
+		etat.Suspendus = append(etat.Suspendus, nom)
+	}
+	sort.Strings(etat.Suspendus)
+
+	return etat, nil
+}
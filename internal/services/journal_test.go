@@ -0,0 +1,311 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+)
+
+// pretsDeTest ouvre une librairie avec trois livres, Zoé (membre 1) et Marc (membre 2)
+func pretsDeTest(t *testing.T, dossier string) librairie {
+	t.Helper()
+
+	l := ouvrirLibrairie(t, dossier)
+	livres := []struct{ titre, isbn string }{
+		{"Fondation", "9780306406157"},
+		{"L'Étranger", "9782070360024"},
+		{"Le Petit Prince", "9782070612758"},
+	}
+	for _, livre := range livres {
+		if err := l.livres.AjouterLivre(livre.titre, "Auteur", livre.isbn, "Roman", "01/01/1950"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range [][3]string{{"Zoé Dupont", "zoe@example.com", "0601020304"}, {"Marc Durand", "marc@example.com", "0605060708"}} {
+		if err := l.membres.AjouterMembre(m[0], m[1], m[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+// activite écrit un événement de plus dans le journal ; les étapes se
+// succèdent en boucle pour mêler emprunts, prolongations, suspensions et retours
+func activite(t *testing.T, l librairie, etape int) {
+	t.Helper()
+
+	enCours := func(livreID int) int {
+		emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(livreID)
+		if emprunt == nil {
+			t.Fatalf("étape %d : le livre %d n'est pas prêté", etape, livreID)
+		}
+		return emprunt.ID
+	}
+	var err error
+	switch etape % 7 {
+	case 0:
+		err = l.emprunts.EmprunterLivre(1, 1)
+	case 1:
+		err = l.emprunts.EmprunterLivre(2, 2)
+	case 2:
+		err = l.emprunts.PrologerEmprunt(enCours(1), 7)
+	case 3:
+		err = l.emprunts.SuspendreMembre(2)
+	case 4:
+		err = l.emprunts.RetournerLivre(enCours(1))
+	case 5:
+		err = l.emprunts.ReactiverMembre(2)
+	case 6:
+		err = l.emprunts.RetournerLivre(enCours(2))
+	}
+	if err != nil {
+		t.Fatalf("étape %d : %v", etape, err)
+	}
+}
+
+// etatDesPrets résume ce que le journal projette dans les emprunts, les livres
+// et les membres ; les dates sont ramenées en UTC, comme après une relecture du disque
+func etatDesPrets(l librairie) []any {
+	var etat []any
+	for _, emprunt := range l.emprunts.ListerEmprunts() {
+		emprunt.DateEmprunt = emprunt.DateEmprunt.UTC()
+		emprunt.DateRetourPrevu = emprunt.DateRetourPrevu.UTC()
+		if emprunt.DateRetourEffectif != nil {
+			retour := emprunt.DateRetourEffectif.UTC()
+			emprunt.DateRetourEffectif = &retour
+		}
+		etat = append(etat, emprunt)
+	}
+	for _, livre := range l.livres.ListerLivres() {
+		etat = append(etat, [3]any{livre.ID, livre.Disponible, livre.NombreEmprunts})
+	}
+	for _, membre := range l.membres.ListerMembres() {
+		etat = append(etat, [4]any{membre.ID, membre.Actif, membre.EmpruntsActifs, membre.NombreEmprunts})
+	}
+	return etat
+}
+
+func TestInstantaneTousLesIntervalles(t *testing.T) {
+	dossier := t.TempDir()
+	l := pretsDeTest(t, dossier)
+
+	// Un instantané est pris dès que INTERVALLE_INSTANTANES événements le séparent du précédent
+	for n := 1; n <= 2*INTERVALLE_INSTANTANES+50; n++ {
+		activite(t, l, n-1)
+		evenements, instantane := l.emprunts.InfosJournal()
+		if attendu := n / INTERVALLE_INSTANTANES * INTERVALLE_INSTANTANES; evenements != n || instantane != attendu {
+			t.Fatalf("après %d événements : %d événements, instantané %d, attendu %d", n, evenements, instantane, attendu)
+		}
+	}
+	avant := etatDesPrets(l)
+
+	// Au redémarrage, l'instantané puis les 50 événements suivants redonnent le même état
+	l = ouvrirLibrairie(t, dossier)
+	if evenements, instantane := l.emprunts.InfosJournal(); evenements != 450 || instantane != 400 {
+		t.Errorf("après redémarrage : %d événements, instantané %d", evenements, instantane)
+	}
+	if apres := etatDesPrets(l); !reflect.DeepEqual(apres, avant) {
+		t.Errorf("état relu depuis l'instantané\n%+v\nattendu\n%+v", apres, avant)
+	}
+
+	// La relecture de tout le journal depuis l'origine aboutit au même état
+	ge := l.emprunts
+	depuisInstantane := projectionDepuis(*ge.dernierInstantane)
+	complete := projectionDepuis(ge.origine)
+	for _, evenement := range ordreCanonique(ge.evenements) {
+		if evenement.Numero > ge.dernierInstantane.Numero {
+			depuisInstantane.appliquer(evenement)
+		}
+		complete.appliquer(evenement)
+	}
+	if !reflect.DeepEqual(depuisInstantane.emprunts, complete.emprunts) {
+		t.Errorf("emprunts depuis l'instantané\n%+v\nattendu\n%+v", depuisInstantane.emprunts, complete.emprunts)
+	}
+	for id := 1; id <= 3; id++ {
+		if a, b := *depuisInstantane.livre(id), *complete.livre(id); a != b {
+			t.Errorf("livre %d : %+v depuis l'instantané, %+v depuis l'origine", id, a, b)
+		}
+	}
+	for id := 1; id <= 2; id++ {
+		if a, b := *depuisInstantane.membre(id), *complete.membre(id); a != b {
+			t.Errorf("membre %d : %+v depuis l'instantané, %+v depuis l'origine", id, a, b)
+		}
+	}
+
+	if relus, err := ge.Rejouer(); err != nil || relus != 450 {
+		t.Fatalf("Rejouer : %d événements, %v", relus, err)
+	}
+	if apres := etatDesPrets(l); !reflect.DeepEqual(apres, avant) {
+		t.Errorf("état après Rejouer\n%+v\nattendu\n%+v", apres, avant)
+	}
+	if _, instantane := ge.InfosJournal(); instantane != 450 {
+		t.Errorf("instantané %d après Rejouer, attendu 450", instantane)
+	}
+}
+
+func TestOrdreDeRelecture(t *testing.T) {
+	debut := time.Now()
+	evenements := []models.Evenement{
+		{Numero: 1, UID: "C", Date: debut.Add(2 * time.Minute)},
+		{Numero: 2, UID: "Z", Date: debut.Add(time.Minute)},
+		{Numero: 3, UID: "A", Date: debut.Add(2 * time.Minute)},
+	}
+	// Par date, puis par UID, quel que soit l'ordre d'écriture
+	var uids []string
+	for _, evenement := range ordreCanonique(evenements) {
+		uids = append(uids, evenement.UID)
+	}
+	if !reflect.DeepEqual(uids, []string{"Z", "A", "C"}) {
+		t.Errorf("ordre %v, attendu [Z A C]", uids)
+	}
+	if evenements[0].UID != "C" {
+		t.Error("ordreCanonique a modifié le journal")
+	}
+
+	dossier := t.TempDir()
+	l := pretsDeTest(t, dossier)
+	avantLePret := time.Now()
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un autre poste avait prêté le même livre à Marc un peu plus tôt ;
+	// son événement arrive après celui de Zoé dans le journal
+	recu := models.Evenement{
+		UID:             models.NouvelUID(),
+		Instance:        "autre-poste",
+		Type:            models.EVENEMENT_LIVRE_EMPRUNTE,
+		Date:            avantLePret,
+		EmpruntID:       2,
+		EmpruntUID:      models.NouvelUID(),
+		LivreID:         1,
+		MembreID:        2,
+		DateRetourPrevu: avantLePret.AddDate(0, 0, 14),
+	}
+	if err := l.emprunts.ajouterRecu(recu); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.emprunts.Rejouer(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rejoué dans l'ordre des dates, le prêt à Marc est retenu et celui de Zoé écarté
+	for _, l := range []librairie{l, ouvrirLibrairie(t, dossier)} {
+		emprunts := l.emprunts.ListerEmprunts()
+		if len(emprunts) != 1 || emprunts[0].MembreID != 2 {
+			t.Errorf("emprunts %+v, attendu le seul prêt à Marc", emprunts)
+		}
+		for id, actifs := range map[int]int{1: 0, 2: 1} {
+			if membre, _ := l.membres.TrouverMembreParID(id); membre.EmpruntsActifs != actifs {
+				t.Errorf("membre %d : %d emprunts actifs, attendu %d", id, membre.EmpruntsActifs, actifs)
+			}
+		}
+	}
+	if ecartes := l.emprunts.projection.ecartes; len(ecartes) != 1 || ecartes[0].Evenement.MembreID != 1 {
+		t.Errorf("emprunts écartés %+v, attendu celui de Zoé", ecartes)
+	}
+}
+
+func TestEtatAu(t *testing.T) {
+	l := pretsDeTest(t, t.TempDir())
+	ge := l.emprunts
+
+	// Les événements sont datés à la main, après l'ouverture du journal
+	t1 := ge.DebutJournal().Add(time.Hour)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+	t4 := t1.AddDate(0, 0, 2)
+	t5 := t4.Add(time.Hour)
+	evenements := []models.Evenement{
+		{Type: models.EVENEMENT_LIVRE_EMPRUNTE, Date: t1, EmpruntID: 1, LivreID: 1, MembreID: 1, DateRetourPrevu: t1.AddDate(0, 0, 14)},
+		{Type: models.EVENEMENT_LIVRE_EMPRUNTE, Date: t2, EmpruntID: 2, LivreID: 2, MembreID: 2, DateRetourPrevu: t2.AddDate(0, 0, 1)},
+		{Type: models.EVENEMENT_MEMBRE_SUSPENDU, Date: t3, MembreID: 2},
+		{Type: models.EVENEMENT_LIVRE_RENDU, Date: t4, EmpruntID: 1, LivreID: 1, MembreID: 1},
+		{Type: models.EVENEMENT_MEMBRE_REACTIVE, Date: t5, MembreID: 2},
+	}
+	for _, evenement := range evenements {
+		if err := ge.enregistrer(evenement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ge.EtatAu(ge.DebutJournal().Add(-time.Minute)); err == nil || !strings.Contains(err.Error(), "le journal commence") {
+		t.Errorf("avant l'origine : erreur %v", err)
+	}
+
+	cas := []struct {
+		nom        string
+		date       time.Time
+		evenements int
+		enCours    []string // emprunt:statut
+		suspendus  []string
+	}{
+		{"à l'ouverture du journal", ge.DebutJournal(), 0, nil, nil},
+		{"à l'instant du premier prêt", t1, 1, []string{"1:" + models.STATUT_EN_COURS}, nil},
+		{"après la suspension", t3, 3, []string{"1:" + models.STATUT_EN_COURS, "2:" + models.STATUT_EN_COURS}, []string{"Marc Durand"}},
+		{"juste avant le retour", t4.Add(-time.Minute), 3, []string{"1:" + models.STATUT_EN_COURS, "2:" + models.STATUT_EN_RETARD}, []string{"Marc Durand"}},
+		{"au retour", t4, 4, []string{"2:" + models.STATUT_EN_RETARD}, []string{"Marc Durand"}},
+		{"après la réactivation", t5, 5, []string{"2:" + models.STATUT_EN_RETARD}, nil},
+	}
+	for _, c := range cas {
+		etat, err := ge.EtatAu(c.date)
+		if err != nil {
+			t.Errorf("%s : %v", c.nom, err)
+			continue
+		}
+		var enCours []string
+		for _, emprunt := range etat.EnCours {
+			enCours = append(enCours, fmt.Sprintf("%d:%s", emprunt.ID, emprunt.Statut))
+		}
+		if etat.Evenements != c.evenements || !reflect.DeepEqual(enCours, c.enCours) || !reflect.DeepEqual(etat.Suspendus, c.suspendus) {
+			t.Errorf("%s : %d événements, en cours %v, suspendus %v ; attendu %d, %v, %v",
+				c.nom, etat.Evenements, enCours, etat.Suspendus, c.evenements, c.enCours, c.suspendus)
+		}
+	}
+
+	// Remonter le temps ne change pas l'état courant
+	if emprunts := ge.ListerEmpruntsEnCours(); len(emprunts) != 1 || emprunts[0].ID != 2 {
+		t.Errorf("emprunts en cours %+v, attendu le seul emprunt 2", emprunts)
+	}
+}
+
+func TestInstantanesIllisiblesRefuses(t *testing.T) {
+	dossier := t.TempDir()
+	l := pretsDeTest(t, dossier)
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un fichier d'instantanés écrit par une version plus récente du programme
+	cfg := config.Defaut()
+	cfg.Donnees.Dossier = dossier
+	futur := `{"version": 999, "schema": "instantanes", "donnees": {}}`
+	if err := os.WriteFile(cfg.Chemin(cfg.Donnees.Instantanes), []byte(futur), 0644); err != nil {
+		t.Fatal(err)
+	}
+	journal, err := os.ReadFile(cfg.Chemin(cfg.Donnees.Journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sq, err := storage.NewSequences(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Sequences), storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ge, err := NouveauGestionnaireEmprunts(storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Emprunts), storage.SCHEMA_EMPRUNTS),
+		storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal)), storage.NewJSONStorage(cfg.Chemin(cfg.Donnees.Instantanes), storage.SCHEMA_INSTANTANES),
+		sq, l.livres, l.membres, l.reservations, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err == nil || ge != nil {
+		t.Fatalf("ouverture acceptée malgré des instantanés illisibles : %v", err)
+	}
+	if apres, _ := os.ReadFile(cfg.Chemin(cfg.Donnees.Journal)); string(apres) != string(journal) {
+		t.Error("le journal a été modifié")
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// demandeRecue ouvre une librairie où Zoé (membre 1) a demandé un livre que
+// Marc (membre 2) ne peut pas emprunter, reçu de la bibliothèque de Lyon
+func demandeRecue(t *testing.T, dossier string, retour time.Time) (librairie, *models.DemandePEB, models.Livre) {
+	t.Helper()
+
+	l := ouvrirLibrairie(t, dossier)
+	for _, m := range [][3]string{{"Zoé Dupont", "zoe@example.com", "0601020304"}, {"Marc Durand", "marc@example.com", "0605060708"}} {
+		if err := l.membres.AjouterMembre(m[0], m[1], m[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	demande, err := l.peb.DemanderPEB(1, "Les Essais", "Montaigne", "978-2-07-041168-5", time.Now().AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.peb.NoterExpedition(demande.ID, "Bibliothèque municipale de Lyon"); err != nil {
+		t.Fatal(err)
+	}
+	exemplaire, err := l.peb.Receptionner(demande.ID, "", retour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, demande, exemplaire
+}
+
+func TestPEBSuitLaDateDuPreteur(t *testing.T) {
+	retour := time.Now().AddDate(0, 0, 30).Truncate(time.Second)
+	l, demande, exemplaire := demandeRecue(t, t.TempDir(), retour)
+
+	if !exemplaire.EstPEB() || exemplaire.CodeBarres == "" {
+		t.Fatalf("exemplaire temporaire attendu avec un code-barres : %+v", exemplaire)
+	}
+
+	// Seul le membre qui l'a demandé peut l'emprunter, et personne ne peut le réserver
+	if err := l.emprunts.EmprunterLivre(exemplaire.ID, 2); err == nil || !strings.Contains(err.Error(), "Zoé Dupont") {
+		t.Errorf("emprunt par un autre membre : erreur attendue, obtenu %v", err)
+	}
+	if _, err := l.emprunts.ReserverLivre(exemplaire.ID, 2); err == nil {
+		t.Error("la réservation d'un livre prêté par une autre bibliothèque doit être refusée")
+	}
+
+	if err := l.emprunts.EmprunterLivre(exemplaire.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(exemplaire.ID)
+	if !emprunt.DateRetourPrevu.Equal(retour) {
+		t.Errorf("échéance %v, attendu la date du prêteur %v", emprunt.DateRetourPrevu, retour)
+	}
+	if demande.Statut != models.PEB_EN_PRET || demande.EmpruntID != emprunt.ID {
+		t.Errorf("demande %s (emprunt %d), attendu en prêt (emprunt %d)", demande.Statut, demande.EmpruntID, emprunt.ID)
+	}
+
+	// La prolongation ne dépasse pas la date du prêteur
+	if err := l.emprunts.VerifierProlongation(*emprunt); err == nil {
+		t.Error("le membre ne doit pas pouvoir prolonger lui-même un prêt entre bibliothèques")
+	}
+	if err := l.emprunts.PrologerEmprunt(emprunt.ID, 7); err == nil {
+		t.Error("prolongation au-delà de la date du prêteur acceptée")
+	}
+
+	// Un livre prêté ne peut pas repartir
+	if err := l.peb.Renvoyer(demande.ID); err == nil {
+		t.Error("renvoi d'un livre encore prêté au membre accepté")
+	}
+}
+
+func TestPEBRetireDuCatalogueAuRenvoi(t *testing.T) {
+	dossier := t.TempDir()
+	l, demande, exemplaire := demandeRecue(t, dossier, time.Now().AddDate(0, 0, 30))
+	if err := l.emprunts.EmprunterLivre(exemplaire.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(exemplaire.ID)
+	if err := l.emprunts.RetournerLivre(emprunt.ID); err != nil {
+		t.Fatal(err)
+	}
+	if demande.Statut != models.PEB_RENDUE {
+		t.Fatalf("demande %s après le retour, attendu %s", demande.Statut, models.PEB_RENDUE)
+	}
+
+	for _, cout := range []struct {
+		libelle string
+		montant models.Montant
+	}{{"Frais de prêt", 500}, {"Port retour", 790}} {
+		if err := l.peb.AjouterCout(demande.ID, cout.libelle, cout.montant); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.peb.Renvoyer(demande.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Après un redémarrage, l'exemplaire a quitté le catalogue, l'emprunt est
+	// resté dans l'historique et l'intégrité ne signale rien
+	l = ouvrirLibrairie(t, dossier)
+	if livre, _ := l.livres.TrouverLivreParID(exemplaire.ID); livre != nil {
+		t.Errorf("l'exemplaire renvoyé est toujours au catalogue : %+v", livre)
+	}
+	if emprunts := l.emprunts.ListerEmpruntsParMembre(1); len(emprunts) != 1 || emprunts[0].DateRetourEffectif == nil {
+		t.Errorf("historique de Zoé : %+v", emprunts)
+	}
+	if rapport := l.emprunts.VerifierIntegrite(); len(rapport.Anomalies) != 0 {
+		t.Errorf("aucune anomalie attendue, trouvé %+v", rapport.Anomalies)
+	}
+
+	demande, _ = l.peb.TrouverDemandeParID(demande.ID)
+	if demande.Statut != models.PEB_RENVOYEE || demande.Total() != 1290 || len(demande.Historique) != 6 {
+		t.Errorf("demande %s, total %s, %d étape(s)", demande.Statut, demande.Total(), len(demande.Historique))
+	}
+	if len(l.peb.ListerDemandes(false)) != 0 {
+		t.Error("une demande renvoyée n'est plus en cours")
+	}
+}
+
+func TestPEBRefuseUnLivreDuFonds(t *testing.T) {
+	l := ouvrirLibrairie(t, t.TempDir())
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.peb.DemanderPEB(1, "L'Étranger", "", "9782070360024", time.Time{}); err == nil {
+		t.Error("demande d'un livre du fonds acceptée")
+	}
+
+	// Une demande sans ISBN peut être annulée tant que le livre n'est pas arrivé
+	demande, err := l.peb.DemanderPEB(1, "Un livre introuvable", "", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.peb.Receptionner(demande.ID, "", time.Now().AddDate(0, 0, 30)); err == nil {
+		t.Error("réception sans bibliothèque prêteuse acceptée")
+	}
+	if err := l.peb.Annuler(demande.ID, "introuvable dans le réseau"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.peb.NoterExpedition(demande.ID, "Lyon"); err == nil {
+		t.Error("expédition d'une demande annulée acceptée")
+	}
+}
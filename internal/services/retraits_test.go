@@ -0,0 +1,212 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+func TestPerteDUnLivreEmprunte(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un livre emprunté ne peut pas être désherbé...
+	if err := l.emprunts.RetirerLivre(1, models.MOTIF_DESHERBE, "abîmé"); err == nil {
+		t.Error("désherbage d'un livre emprunté accepté")
+	}
+
+	// ... mais il peut être déclaré perdu : son emprunt est clos sans retour
+	if err := l.emprunts.RetirerLivre(1, models.MOTIF_PERDU, "jamais rendu"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Après un redémarrage, le journal garde la perte et rien n'est attendu en retour
+	l = ouvrirLibrairie(t, dossier)
+	if emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(1); emprunt != nil {
+		t.Errorf("l'emprunt du livre perdu est toujours en cours : %+v", emprunt)
+	}
+	if membre, _ := l.membres.TrouverMembreParID(1); membre.EmpruntsActifs != 0 {
+		t.Errorf("%d emprunt(s) actif(s) pour Zoé, attendu 0", membre.EmpruntsActifs)
+	}
+	livre, _ := l.livres.TrouverLivreParID(1)
+	if !livre.EstRetire() || livre.Retrait.Motif != models.MOTIF_PERDU {
+		t.Errorf("livre non retiré comme perdu : %+v", livre.Retrait)
+	}
+	if dernier := l.emprunts.ListerEvenements(1)[0]; dernier.Type != models.EVENEMENT_LIVRE_PERDU || dernier.EmpruntID != 1 {
+		t.Errorf("dernier événement %s (emprunt %d), attendu la perte de l'emprunt 1", dernier.Type, dernier.EmpruntID)
+	}
+	if rapport := l.emprunts.VerifierIntegrite(); len(rapport.Anomalies) != 0 {
+		t.Errorf("aucune anomalie attendue, trouvé %+v", rapport.Anomalies)
+	}
+
+	// Retrouvé, le livre revient au catalogue disponible
+	if err := l.emprunts.RestaurerLivre(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Errorf("emprunt du livre retrouvé : %v", err)
+	}
+}
+
+func TestPerteRejoueeSansRetour(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.RetirerLivre(1, models.MOTIF_PERDU, "jamais rendu"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Au redémarrage puis après une relecture complète du journal, l'emprunt
+	// est clos comme perdu et le livre reste indisponible
+	l = ouvrirLibrairie(t, dossier)
+	for _, etape := range []string{"redémarrage", "relecture"} {
+		if etape == "relecture" {
+			if _, err := l.emprunts.Rejouer(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		emprunt, _ := l.emprunts.TrouverEmpruntParID(1)
+		if emprunt == nil || emprunt.Statut != models.STATUT_PERDU || !emprunt.EstPerdu() || emprunt.EstRendu() {
+			t.Errorf("%s : emprunt %+v, attendu clos comme perdu", etape, emprunt)
+		}
+		if livre, _ := l.livres.TrouverLivreParID(1); livre.Disponible {
+			t.Errorf("%s : le livre perdu est disponible", etape)
+		}
+
+		// Une perte n'est ni un rendu ni un retour dans les statistiques
+		stats := l.emprunts.ObtenirStatistiques(statistiques.Periode{}, 0)
+		if stats.Perdus != 1 || stats.Rendus != 0 || stats.Retours != 0 || stats.RetoursEnRetard != 0 {
+			t.Errorf("%s : perdus %d, rendus %d, retours %d (%d en retard), attendu une seule perte",
+				etape, stats.Perdus, stats.Rendus, stats.Retours, stats.RetoursEnRetard)
+		}
+		if rapport := l.emprunts.VerifierIntegrite(); len(rapport.Anomalies) != 0 {
+			t.Errorf("%s : aucune anomalie attendue, trouvé %+v", etape, rapport.Anomalies)
+		}
+	}
+}
+
+func TestRestaurationRejouee(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.RetirerLivre(1, models.MOTIF_PERDU, "jamais rendu"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le livre retrouvé redevient disponible par un événement du journal
+	if err := l.emprunts.RestaurerLivre(1); err != nil {
+		t.Fatal(err)
+	}
+	if dernier := l.emprunts.ListerEvenements(1)[0]; dernier.Type != models.EVENEMENT_LIVRE_RESTAURE || dernier.LivreID != 1 {
+		t.Errorf("dernier événement %s (livre %d), attendu la restauration du livre 1", dernier.Type, dernier.LivreID)
+	}
+	if err := l.emprunts.RestaurerLivre(1); err == nil {
+		t.Error("restauration d'un livre qui n'est pas retiré acceptée")
+	}
+
+	// Après un redémarrage puis une relecture complète, il l'est toujours
+	l = ouvrirLibrairie(t, dossier)
+	for _, etape := range []string{"redémarrage", "relecture"} {
+		if etape == "relecture" {
+			if _, err := l.emprunts.Rejouer(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if livre, _ := l.livres.TrouverLivreParID(1); livre.EstRetire() || !livre.EstDisponible() {
+			t.Errorf("%s : livre %+v, attendu au catalogue et disponible", etape, livre)
+		}
+		if rapport := l.emprunts.VerifierIntegrite(); len(rapport.Anomalies) != 0 {
+			t.Errorf("%s : aucune anomalie attendue, trouvé %+v", etape, rapport.Anomalies)
+		}
+	}
+}
+
+func TestPurgeGardeUnePierreTombale(t *testing.T) {
+	dossier := t.TempDir()
+
+	l := ouvrirLibrairie(t, dossier)
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(1)
+	if err := l.emprunts.RetournerLivre(emprunt.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.RetirerLivre(1, models.MOTIF_DESHERBE, "abîmé"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un retrait trop récent n'est pas purgé
+	if purges, err := l.livres.PurgerLivresRetires(30); err != nil || len(purges) != 0 {
+		t.Fatalf("purge d'un retrait récent : %d livre(s), %v", len(purges), err)
+	}
+	l.livres.livres[0].Retrait.Date = time.Now().AddDate(0, 0, -31)
+	purges, err := l.livres.PurgerLivresRetires(30)
+	if err != nil || len(purges) != 1 || purges[0].ISBN != "9782070360024" {
+		t.Fatalf("purge : %+v, %v", purges, err)
+	}
+
+	// La fiche reste, réduite à ce que l'historique des emprunts utilise
+	l = ouvrirLibrairie(t, dossier)
+	livre, _ := l.livres.TrouverLivreParID(1)
+	if livre == nil || !livre.EstPurge() || livre.Titre != "L'Étranger" || livre.ISBN != "" || livre.NombreEmprunts != 1 {
+		t.Fatalf("pierre tombale %+v", livre)
+	}
+	if retires := l.livres.ListerLivresRetires(); len(retires) != 0 {
+		t.Errorf("livres retirés %+v, attendu aucun après la purge", retires)
+	}
+	if stats := l.livres.ObtenirStatistiques(statistiques.Periode{}, 0); stats.Retires != 0 {
+		t.Errorf("%d livre(s) retiré(s) dans les statistiques, attendu 0", stats.Retires)
+	}
+	if err := l.emprunts.RestaurerLivre(1); err == nil || !strings.Contains(err.Error(), "purgé") {
+		t.Errorf("restauration d'un livre purgé : erreur %v", err)
+	}
+	if purges, _ := l.livres.PurgerLivresRetires(30); len(purges) != 0 {
+		t.Errorf("livre purgé une seconde fois : %+v", purges)
+	}
+
+	// L'historique ne pointe vers aucun livre disparu, et l'ISBN peut resservir
+	if rapport := l.emprunts.VerifierIntegrite(); len(rapport.Anomalies) != 0 {
+		t.Errorf("aucune anomalie attendue, trouvé %+v", rapport.Anomalies)
+	}
+	if err := l.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Errorf("nouvel exemplaire après la purge : %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// cycleFondation ouvre une librairie où la série Fondation a trois tomes,
+// créés dans le désordre ; les livres 1 et 2 sont deux éditions du tome 1,
+// les livres 3 et 4 les tomes 2 et 3, le livre 5 une oeuvre hors série et
+// le livre 6 n'est rattaché à aucune oeuvre
+func cycleFondation(t *testing.T) (librairie, *models.Serie, map[int]int) {
+	t.Helper()
+
+	l := ouvrirLibrairie(t, t.TempDir())
+	serie, err := l.series.AjouterSerie("Fondation")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tomes := make(map[int]int) // numéro de tome → ID de l'oeuvre
+	for _, tome := range []struct {
+		titre  string
+		numero int
+	}{{"Seconde Fondation", 3}, {"Fondation", 1}, {"Fondation et Empire", 2}} {
+		oeuvre, err := l.series.AjouterOeuvre(tome.titre, serie.ID, tome.numero)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tomes[tome.numero] = oeuvre.ID
+	}
+	robots, err := l.series.AjouterOeuvre("Les Robots", 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	livres := []struct {
+		titre, isbn, edition string
+		oeuvreID             int
+	}{
+		{"Fondation", "9780306406157", "Denoël", tomes[1]},
+		{"Fondation", "0306406152", "Folio", tomes[1]},
+		{"Fondation et Empire", "9782070360024", "", tomes[2]},
+		{"Seconde Fondation", "9782070368228", "", tomes[3]},
+		{"Les Robots", "9782070612758", "", robots.ID},
+		{"Le Petit Prince", "2070612759", "", 0},
+	}
+	for i, livre := range livres {
+		if err := l.livres.AjouterLivre(livre.titre, "Isaac Asimov", livre.isbn, "Science-fiction", "01/01/1951"); err != nil {
+			t.Fatal(err)
+		}
+		if livre.oeuvreID != 0 {
+			if err := l.series.RattacherLivre(i+1, livre.oeuvreID, livre.edition); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return l, serie, tomes
+}
+
+// titresDesTomes retourne le numéro et le titre de chaque tome, dans l'ordre
+func titresDesTomes(oeuvres []models.Oeuvre) []string {
+	var titres []string
+	for _, oeuvre := range oeuvres {
+		titres = append(titres, fmt.Sprintf("%d. %s", oeuvre.NumeroTome, oeuvre.Titre))
+	}
+	return titres
+}
+
+func TestOrdreDesTomes(t *testing.T) {
+	l, serie, tomes := cycleFondation(t)
+
+	// Les tomes sont rangés par numéro, quel que soit l'ordre de création
+	attendu := []string{"1. Fondation", "2. Fondation et Empire", "3. Seconde Fondation"}
+	if titres := titresDesTomes(l.series.ListerOeuvresDeSerie(serie.ID)); !reflect.DeepEqual(titres, attendu) {
+		t.Errorf("tomes %v, attendu %v", titres, attendu)
+	}
+
+	// Une oeuvre hors série n'a pas de numéro de tome
+	if robots := l.series.ListerOeuvresDeSerie(0); len(robots) != 1 || robots[0].NumeroTome != 0 {
+		t.Errorf("oeuvres hors série : %+v", robots)
+	}
+
+	refus := []struct {
+		nom    string
+		action func() error
+		erreur string
+	}{
+		{"numéro déjà pris", func() error { _, err := l.series.AjouterOeuvre("Prélude à Fondation", serie.ID, 1); return err }, "existe déjà"},
+		{"numéro nul", func() error { _, err := l.series.AjouterOeuvre("Prélude à Fondation", serie.ID, 0); return err }, "au moins 1"},
+		{"série inconnue", func() error { _, err := l.series.AjouterOeuvre("Prélude à Fondation", 99, 1); return err }, "aucune série"},
+		{"déplacement sur un tome existant", func() error { return l.series.ModifierOeuvre(tomes[2], "", serie.ID, 3) }, "existe déjà"},
+		{"série qui contient des tomes", func() error { return l.series.SupprimerSerie(serie.ID) }, "contient 3 tome(s)"},
+		{"oeuvre qui a des éditions", func() error { return l.series.SupprimerOeuvre(tomes[1]) }, "2 livre(s)"},
+	}
+	for _, r := range refus {
+		if err := r.action(); err == nil || !strings.Contains(err.Error(), r.erreur) {
+			t.Errorf("%s : erreur %v, attendu une erreur contenant %q", r.nom, err, r.erreur)
+		}
+	}
+
+	// Garder son propre numéro n'est pas un conflit
+	if err := l.series.ModifierOeuvre(tomes[1], "Fondation (Cycle de Trantor)", serie.ID, 1); err != nil {
+		t.Errorf("renommage d'un tome : %v", err)
+	}
+
+	// Un tome renuméroté ou ajouté après coup prend sa place dans l'ordre
+	if err := l.series.ModifierOeuvre(tomes[2], "", serie.ID, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.series.AjouterOeuvre("Fondation foudroyée", serie.ID, 4); err != nil {
+		t.Fatal(err)
+	}
+	attendu = []string{"1. Fondation (Cycle de Trantor)", "3. Seconde Fondation", "4. Fondation foudroyée", "7. Fondation et Empire"}
+	if titres := titresDesTomes(l.series.ListerOeuvresDeSerie(serie.ID)); !reflect.DeepEqual(titres, attendu) {
+		t.Errorf("tomes après renumérotation %v, attendu %v", titres, attendu)
+	}
+}
+
+func TestVolumesEtTomeSuivant(t *testing.T) {
+	l, serie, _ := cycleFondation(t)
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.livres.RetirerLivre(4, models.MOTIF_DESHERBE, "abîmé"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Les éditions retirées ne comptent plus ; un tome sans édition reste listé
+	volumes := l.series.ListerVolumes(serie.ID)
+	var resume []string
+	for _, volume := range volumes {
+		var editions []string
+		for _, edition := range volume.Editions {
+			editions = append(editions, edition.Edition)
+		}
+		resume = append(resume, fmt.Sprintf("%s/%d", strings.Join(editions, "+"), volume.Disponibles))
+	}
+	if attendu := []string{"Denoël+Folio/1", "/1", "/0"}; !reflect.DeepEqual(resume, attendu) {
+		t.Errorf("éditions/disponibles par tome %v, attendu %v", resume, attendu)
+	}
+
+	cas := []struct {
+		nom     string
+		livreID int
+		suivant string // vide s'il n'y a pas de tome suivant
+		erreur  string
+	}{
+		{"depuis une des éditions du tome 1", 2, "Fondation et Empire", ""},
+		{"depuis le tome 2", 3, "Seconde Fondation", ""},
+		{"depuis le dernier tome", 4, "", ""},
+		{"oeuvre hors série", 5, "", "aucune série"},
+		{"livre sans oeuvre", 6, "", "aucune série"},
+		{"livre inconnu", 99, "", "aucun livre"},
+	}
+	for _, c := range cas {
+		volume, err := l.series.TomeSuivant(c.livreID)
+		switch {
+		case c.erreur != "":
+			if err == nil || !strings.Contains(err.Error(), c.erreur) {
+				t.Errorf("%s : erreur %v, attendu une erreur contenant %q", c.nom, err, c.erreur)
+			}
+		case err != nil:
+			t.Errorf("%s : %v", c.nom, err)
+		case c.suivant == "" && volume != nil:
+			t.Errorf("%s : tome suivant %q, attendu aucun", c.nom, volume.Oeuvre.Titre)
+		case c.suivant != "" && (volume == nil || volume.Oeuvre.Titre != c.suivant):
+			t.Errorf("%s : tome suivant %+v, attendu %q", c.nom, volume, c.suivant)
+		}
+	}
+}
+
+func TestClassementDesOeuvres(t *testing.T) {
+	l, _, _ := cycleFondation(t)
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un emprunt pour chaque édition de Fondation, deux pour Les Robots
+	for _, livreID := range []int{1, 2, 5, 5} {
+		if err := l.emprunts.EmprunterLivre(livreID, 1); err != nil {
+			t.Fatal(err)
+		}
+		emprunt, _ := l.emprunts.TrouverEmpruntActifParLivre(livreID)
+		if err := l.emprunts.RetournerLivre(emprunt.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Les éditions d'une oeuvre sont cumulées ; à égalité, l'ordre est alphabétique
+	classement := l.series.ClasserOeuvres()
+	attendu := []statistiques.Oeuvre{
+		{Titre: "Fondation", Editions: 2, Emprunts: 2},
+		{Titre: "Les Robots", Editions: 1, Emprunts: 2},
+		{Titre: "Fondation et Empire", Editions: 1},
+		{Titre: "Le Petit Prince", Editions: 1},
+		{Titre: "Seconde Fondation", Editions: 1},
+	}
+	if !reflect.DeepEqual(classement, attendu) {
+		t.Errorf("classement %+v, attendu %+v", classement, attendu)
+	}
+
+	stats := l.series.ObtenirStatistiques()
+	if stats.Series != 1 || stats.Oeuvres != 4 || stats.OeuvrePlusEmpruntee == nil || stats.OeuvrePlusEmpruntee.Titre != "Fondation" {
+		t.Errorf("statistiques %+v", stats)
+	}
+}
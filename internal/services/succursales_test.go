@@ -0,0 +1,177 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/statistiques"
+)
+
+// reseau ouvre une librairie à trois succursales : le livre 1 et le membre 1,
+// enregistrés avant le réseau, sont rattachés au centre
+func reseau(t *testing.T) (librairie, map[string]int) {
+	t.Helper()
+
+	l := ouvrirLibrairie(t, t.TempDir())
+	if err := l.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]int)
+	for _, s := range []struct {
+		code, nom string
+		lat, lon  float64
+	}{{"CTR", "Centre", 48.8566, 2.3522}, {"NRD", "Nord", 50.6292, 3.0573}, {"SUD", "Sud", 43.2965, 5.3698}} {
+		succursale, err := l.emprunts.AjouterSuccursale(s.code, s.nom, "", s.lat, s.lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[s.code] = succursale.ID
+	}
+	return l, ids
+}
+
+// au place le poste dans la succursale donnée
+func au(t *testing.T, l librairie, code string) {
+	t.Helper()
+	if err := l.succursales.ChoisirSuccursale(code); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func livre(t *testing.T, l librairie, id int) models.Livre {
+	t.Helper()
+	livre, _ := l.livres.TrouverLivreParID(id)
+	if livre == nil {
+		t.Fatalf("livre %d introuvable", id)
+	}
+	return *livre
+}
+
+func TestPremiereSuccursaleRattacheLeFonds(t *testing.T) {
+	l, ids := reseau(t)
+
+	if l1 := livre(t, l, 1); l1.SuccursaleID != ids["CTR"] || l1.EmplacementID != ids["CTR"] {
+		t.Errorf("livre 1 : succursale %d, emplacement %d, attendu %d", l1.SuccursaleID, l1.EmplacementID, ids["CTR"])
+	}
+	if membre, _ := l.membres.TrouverMembreParID(1); membre.SuccursaleID != ids["CTR"] {
+		t.Errorf("membre 1 rattaché à %d, attendu %d", membre.SuccursaleID, ids["CTR"])
+	}
+	if courante := l.succursales.CouranteID(); courante != ids["CTR"] {
+		t.Errorf("poste à %d, attendu la première succursale %d", courante, ids["CTR"])
+	}
+}
+
+func TestRetourAilleursPuisReception(t *testing.T) {
+	l, ids := reseau(t)
+	if err := l.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rendu au nord, le livre repart vers le centre et n'est pas empruntable en route
+	au(t, l, "NRD")
+	if err := l.emprunts.RetournerLivre(1); err != nil {
+		t.Fatal(err)
+	}
+	l1 := livre(t, l, 1)
+	if !l1.EstEnTransit() || l1.Transfert.De != ids["NRD"] || l1.Transfert.Vers != ids["CTR"] || l1.Transfert.Motif != models.TRANSFERT_RETOUR {
+		t.Fatalf("transfert inattendu : %+v", l1.Transfert)
+	}
+	if l1.EstDisponible() {
+		t.Error("un livre en transit ne doit pas être disponible")
+	}
+	if err := l.emprunts.EmprunterLivre(1, 1); err == nil {
+		t.Error("l'emprunt d'un livre en transit doit être refusé")
+	}
+	if emprunt, _ := l.emprunts.TrouverEmpruntParID(1); emprunt.SuccursaleID != ids["CTR"] || emprunt.SuccursaleRetour != ids["NRD"] {
+		t.Errorf("emprunt : pris à %d, rendu à %d", emprunt.SuccursaleID, emprunt.SuccursaleRetour)
+	}
+
+	// Seule la succursale d'arrivée peut le réceptionner
+	if _, err := l.emprunts.ReceptionnerTransfert(1); err == nil {
+		t.Error("la réception au nord doit être refusée")
+	}
+	au(t, l, "CTR")
+	if _, err := l.emprunts.ReceptionnerTransfert(1); err != nil {
+		t.Fatal(err)
+	}
+	if l1 := livre(t, l, 1); l1.EstEnTransit() || l1.EmplacementID != ids["CTR"] || !l1.EstDisponible() {
+		t.Errorf("après réception : %+v", l1)
+	}
+}
+
+func TestReservationServieParLExemplaireLePlusProche(t *testing.T) {
+	l, ids := reseau(t)
+
+	// Deux autres exemplaires, au sud et au nord ; celui du centre est prêté
+	for _, code := range []string{"SUD", "NRD"} {
+		au(t, l, code)
+		if _, err := l.livres.AjouterExemplaireAcquis("9782070612758", "", "", "", "", models.Acquisition{Date: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	au(t, l, "CTR")
+	if err := l.membres.AjouterMembre("Marc Durand", "marc@example.com", "0605060708"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lille est plus proche de Paris que Marseille : l'exemplaire du nord est acheminé
+	reservation, err := l.emprunts.ReserverLivre(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reservation.Statut != models.RESERVATION_EN_TRANSIT || reservation.LivreID != 3 {
+		t.Fatalf("réservation : statut %s sur le livre %d, attendu en transit sur le livre 3", reservation.Statut, reservation.LivreID)
+	}
+	if l3 := livre(t, l, 3); !l3.EstEnTransit() || l3.Transfert.Vers != ids["CTR"] || l3.Transfert.ReservationID != reservation.ID {
+		t.Fatalf("transfert inattendu : %+v", l3.Transfert)
+	}
+	if _, err := l.emprunts.ReserverLivre(2, 1); err == nil {
+		t.Error("un membre ne doit pas réserver deux fois le même titre")
+	}
+
+	// À l'arrivée, le livre est mis de côté pour le membre
+	prete, err := l.emprunts.ReceptionnerTransfert(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prete == nil || prete.ID != reservation.ID || prete.Statut != models.RESERVATION_PRETE {
+		t.Fatalf("réservation après réception : %+v", prete)
+	}
+	if err := l.emprunts.EmprunterLivre(3, 2); err == nil {
+		t.Error("un livre mis de côté ne doit pas être prêté à un autre membre")
+	}
+	if err := l.emprunts.EmprunterLivre(3, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatistiquesParSuccursale(t *testing.T) {
+	l, ids := reseau(t)
+	au(t, l, "SUD")
+	if _, err := l.livres.AjouterExemplaireAcquis("9782070612758", "", "", "", "", models.Acquisition{Date: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.emprunts.EmprunterLivre(2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cas := range []struct {
+		code                        string
+		livres, empruntes, emprunts int
+	}{{"", 2, 1, 1}, {"CTR", 1, 0, 0}, {"SUD", 1, 1, 1}, {"NRD", 0, 0, 0}} {
+		livres := l.livres.ObtenirStatistiques(statistiques.Periode{}, ids[cas.code])
+		emprunts := l.emprunts.ObtenirStatistiques(statistiques.Periode{}, ids[cas.code])
+		if livres.Total != cas.livres || livres.Empruntes != cas.empruntes || emprunts.Total != cas.emprunts {
+			t.Errorf("%q : %d livre(s), %d emprunté(s), %d emprunt(s) ; attendu %d, %d, %d", cas.code,
+				livres.Total, livres.Empruntes, emprunts.Total, cas.livres, cas.empruntes, cas.emprunts)
+		}
+	}
+}
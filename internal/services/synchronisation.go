@@ -0,0 +1,553 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/storage"
+)
+
+// ========================================
+// SYNCHRONISATION ENTRE POSTES
+// Chaque poste tient son propre journal des emprunts et continue de prêter
+// quand le réseau tombe. À la synchronisation, deux postes s'échangent les
+// événements que l'autre n'a pas encore, avec les livres et les membres
+// créés entre-temps. Les événements reçus sont ajoutés au journal, puis tout
+// est rejoué dans l'ordre canonique (date, puis UID) : les postes qui ont les
+// mêmes événements en déduisent les mêmes emprunts, quel que soit l'ordre dans
+// lequel ils les ont reçus. Un livre prêté sur deux postes à la fois reste à
+// l'emprunt le plus ancien ; l'autre est écarté et signalé comme conflit.
+//
+// Un code-barres ou une carte garde partout le code imprimé sur le poste qui
+// l'a créé. Dès sa première synchronisation, un poste intercale son repère
+// dans les codes qu'il génère, pour ne jamais reprendre ceux d'un autre poste.
+//
+// Les modifications d'un livre ou d'un membre déjà connu des deux postes
+// (titre corrigé, retrait, changement d'adresse...) ne sont pas échangées.
+// ========================================
+
+// etatSynchro est le contenu du fichier de synchronisation
+type etatSynchro struct {
+	Instance  string                  `json:"instance"`
+	Repere    string                  `json:"repere,omitempty"` // repère des codes générés, pris à la première synchronisation
+	Pairs     []models.PairSynchro    `json:"pairs"`
+	Conflits  []models.ConflitSynchro `json:"conflits"`
+	EnAttente []models.Evenement      `json:"en_attente"` // reçus, mais le livre ou le membre n'est pas encore connu ici
+
+	// Fiches associe l'UID de chaque livre et membre au numéro du journal
+	// quand ce poste l'a vu pour la première fois, créé ici ou reçu
+	Fiches map[string]int `json:"fiches,omitempty"`
+}
+
+// LotSynchro est ce qu'un poste transmet à un autre : ses événements depuis un
+// numéro de son journal, et les livres et membres apparus ici depuis
+type LotSynchro struct {
+	Instance   string             `json:"instance"`
+	Dernier    int                `json:"dernier"` // numéro du dernier événement du journal de l'émetteur
+	Evenements []models.Evenement `json:"evenements"`
+	Livres     []models.Livre     `json:"livres"`
+	Membres    []models.Membre    `json:"membres"`
+}
+
+// BilanSynchro résume l'intégration d'un lot
+type BilanSynchro struct {
+	Recus     int                     `json:"recus"`      // événements du lot
+	Integres  int                     `json:"integres"`   // événements nouveaux pour ce poste
+	EnAttente int                     `json:"en_attente"` // événements mis de côté faute de livre ou de membre
+	Livres    int                     `json:"livres"`     // livres ajoutés au catalogue
+	Membres   int                     `json:"membres"`    // membres ajoutés
+	Conflits  []models.ConflitSynchro `json:"conflits"`   // conflits apparus avec ce lot
+
+	// Codes reçus déjà portés ici par un autre livre ou membre : au scan, tous
+	// les postes retiennent celui dont l'UID est le plus ancien, et l'étiquette
+	// ou la carte de l'autre est à refaire
+	CodesEnDouble []string `json:"codes_en_double,omitempty"`
+}
+
+type GestionnaireSynchro struct {
+	etat                 etatSynchro
+	stockage             storage.Storage
+	gestionnaireEmprunts *GestionnaireEmprunts
+}
+
+func (gsy *GestionnaireSynchro) sauvegarderEtat() error {
+	return gsy.stockage.Sauvegarder(gsy.etat)
+}
+
+func (gsy *GestionnaireSynchro) ChargerEtat() error {
+	return gsy.stockage.Charger(&gsy.etat)
+}
+
+// NouveauGestionnaireSynchro charge l'état de la synchronisation. Un poste
+// reçoit son identifiant au premier démarrage ; ses événements le portent.
+func NouveauGestionnaireSynchro(stockage storage.Storage, ge *GestionnaireEmprunts) *GestionnaireSynchro {
+	gsy := &GestionnaireSynchro{
+		etat:                 etatSynchro{Pairs: []models.PairSynchro{}, Conflits: []models.ConflitSynchro{}},
+		stockage:             stockage,
+		gestionnaireEmprunts: ge,
+	}
+
+	gsy.ChargerEtat()
+	if gsy.etat.Instance == "" {
+		gsy.etat.Instance = models.NouvelUID()
+		gsy.sauvegarderEtat()
+	}
+	ge.instance = gsy.etat.Instance
+	gsy.appliquerRepere()
+	return gsy
+}
+
+// rejoindreReseau donne son repère à ce poste lors de sa première
+// synchronisation ; les codes générés jusque-là ne changent pas
+func (gsy *GestionnaireSynchro) rejoindreReseau() {
+	if gsy.etat.Repere != "" {
+		return
+	}
+	gsy.etat.Repere = models.ReperePoste(gsy.etat.Instance)
+	gsy.appliquerRepere()
+	gsy.sauvegarderEtat()
+}
+
+func (gsy *GestionnaireSynchro) appliquerRepere() {
+	ge := gsy.gestionnaireEmprunts
+	ge.gestionnaireLivres.repere = gsy.etat.Repere
+	ge.gestionnaireMembres.repere = gsy.etat.Repere
+}
+
+// Repere retourne le repère de ce poste dans ses codes générés, vide tant
+// qu'il ne s'est jamais synchronisé
+func (gsy *GestionnaireSynchro) Repere() string {
+	return gsy.etat.Repere
+}
+
+// Instance retourne l'identifiant de ce poste
+func (gsy *GestionnaireSynchro) Instance() string {
+	return gsy.etat.Instance
+}
+
+// DernierEvenement retourne le numéro du dernier événement du journal de ce poste
+func (gsy *GestionnaireSynchro) DernierEvenement() int {
+	return gsy.gestionnaireEmprunts.dernierNumero
+}
+
+// ========================================
+// PAIRS
+// ========================================
+
+// ListerPairs retourne les postes avec lesquels celui-ci se synchronise
+func (gsy *GestionnaireSynchro) ListerPairs() []models.PairSynchro {
+	return gsy.etat.Pairs
+}
+
+func (gsy *GestionnaireSynchro) TrouverPair(url string) *models.PairSynchro {
+	url = normaliserURL(url)
+	for i, pair := range gsy.etat.Pairs {
+		if pair.URL == url {
+			return &gsy.etat.Pairs[i]
+		}
+	}
+	return nil
+}
+
+// AjouterPair déclare un poste à synchroniser, par l'adresse de son service de synchronisation
+func (gsy *GestionnaireSynchro) AjouterPair(url string) (*models.PairSynchro, error) {
+	url = normaliserURL(url)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("l'adresse '%s' doit commencer par http:// ou https://", url)
+	}
+	if gsy.TrouverPair(url) != nil {
+		return nil, fmt.Errorf("le poste %s est déjà déclaré", url)
+	}
+
+	gsy.etat.Pairs = append(gsy.etat.Pairs, models.PairSynchro{URL: url})
+	gsy.rejoindreReseau()
+	if err := gsy.sauvegarderEtat(); err != nil {
+		return nil, err
+	}
+	return &gsy.etat.Pairs[len(gsy.etat.Pairs)-1], nil
+}
+
+func normaliserURL(url string) string {
+	return strings.TrimRight(strings.TrimSpace(url), "/")
+}
+
+// NoterEchange enregistre une synchronisation réussie et avance les curseurs du pair
+func (gsy *GestionnaireSynchro) NoterEchange(url, instance string, recu, envoye int) error {
+	pair := gsy.TrouverPair(url)
+	if pair == nil {
+		return fmt.Errorf("le poste %s n'est pas déclaré", url)
+	}
+	pair.Instance = instance
+	pair.Recu = recu
+	pair.Envoye = envoye
+	pair.DerniereSynchro = time.Now()
+	pair.DerniereErreur = ""
+	return gsy.sauvegarderEtat()
+}
+
+// NoterEchec enregistre l'erreur de la dernière tentative ; les curseurs ne bougent pas
+func (gsy *GestionnaireSynchro) NoterEchec(url string, echec error) error {
+	pair := gsy.TrouverPair(url)
+	if pair == nil {
+		return fmt.Errorf("le poste %s n'est pas déclaré", url)
+	}
+	pair.DerniereErreur = echec.Error()
+	return gsy.sauvegarderEtat()
+}
+
+// ========================================
+// ÉCHANGE DES ÉVÉNEMENTS
+// ========================================
+
+// Changements prépare le lot des événements de ce poste postérieurs au numéro
+// donné, avec les livres et les membres apparus ici depuis ce numéro
+func (gsy *GestionnaireSynchro) Changements(depuis int) LotSynchro {
+	gsy.rejoindreReseau()
+	gsy.noterFiches()
+	ge := gsy.gestionnaireEmprunts
+	lot := LotSynchro{
+		Instance:   gsy.etat.Instance,
+		Dernier:    ge.dernierNumero,
+		Evenements: []models.Evenement{},
+		Livres:     []models.Livre{},
+		Membres:    []models.Membre{},
+	}
+
+	// Une fiche vue au numéro depuis a pu apparaître après l'envoi de cet
+	// événement : elle repart, l'autre poste ignorera un UID déjà connu.
+	// RÈGLE MÉTIER : les empreintes du PIN et du mot de passe ne quittent pas
+	// le poste ; un membre reçu choisit les siens au comptoir de l'autre poste.
+	for _, membre := range ge.gestionnaireMembres.membres {
+		if gsy.etat.Fiches[membre.UID] >= depuis {
+			membre.PIN, membre.EchecsPIN, membre.MotDePasse = "", 0, ""
+			lot.Membres = append(lot.Membres, membre)
+		}
+	}
+
+	// Un livre prêté par une autre bibliothèque ne quitte pas le poste qui l'a
+	// reçu : ni lui ni ses emprunts ne sont envoyés
+	var livresPEB map[int]bool
+	if ge.gestionnairePEB != nil {
+		livresPEB = ge.gestionnairePEB.livresPEB()
+	}
+	for _, livre := range ge.gestionnaireLivres.livres {
+		if !livre.EstPEB() && gsy.etat.Fiches[livre.UID] >= depuis {
+			lot.Livres = append(lot.Livres, livre)
+		}
+	}
+
+	for _, evenement := range ge.evenements {
+		if evenement.Numero <= depuis || livresPEB[evenement.LivreID] {
+			continue
+		}
+		evenement.UID = uidEvenement(evenement)
+		ge.completer(&evenement)
+		lot.Evenements = append(lot.Evenements, evenement)
+	}
+	return lot
+}
+
+// noterFiches retient le numéro du journal auquel ce poste voit pour la
+// première fois chacun de ses livres et membres
+func (gsy *GestionnaireSynchro) noterFiches() {
+	ge := gsy.gestionnaireEmprunts
+	if gsy.etat.Fiches == nil {
+		gsy.etat.Fiches = make(map[string]int)
+	}
+	nouvelles := false
+	noter := func(uid string) {
+		if _, vue := gsy.etat.Fiches[uid]; !vue && uid != "" {
+			gsy.etat.Fiches[uid] = ge.dernierNumero
+			nouvelles = true
+		}
+	}
+	for _, livre := range ge.gestionnaireLivres.livres {
+		noter(livre.UID)
+	}
+	for _, membre := range ge.gestionnaireMembres.membres {
+		noter(membre.UID)
+	}
+	if nouvelles {
+		gsy.sauvegarderEtat()
+	}
+}
+
+// Integrer ajoute à ce poste les livres, les membres et les événements du lot
+// qu'il ne connaît pas encore, puis rejoue le journal. Les événements qui
+// désignent un livre ou un membre inconnu attendent la prochaine synchronisation.
+func (gsy *GestionnaireSynchro) Integrer(lot LotSynchro) (BilanSynchro, error) {
+	if lot.Instance == gsy.etat.Instance {
+		return BilanSynchro{}, fmt.Errorf("ce lot vient de ce poste lui-même")
+	}
+
+	gsy.rejoindreReseau()
+	ge := gsy.gestionnaireEmprunts
+	bilan := BilanSynchro{Recus: len(lot.Evenements)}
+
+	var err error
+	if bilan.Livres, err = gsy.importerLivres(lot.Livres, &bilan); err != nil {
+		return bilan, err
+	}
+	if bilan.Membres, err = gsy.importerMembres(lot.Membres, &bilan); err != nil {
+		return bilan, err
+	}
+
+	connus := make(map[string]bool, len(ge.evenements))
+	for _, evenement := range ge.evenements {
+		connus[uidEvenement(evenement)] = true
+	}
+	emprunts := make(map[string]int)
+	for id, uid := range ge.uidsEmprunts() {
+		emprunts[uid] = id
+	}
+
+	// Les événements mis de côté passent avant ceux du lot : un retour ne
+	// doit pas précéder l'emprunt qu'il clôt
+	aTraiter := append(gsy.etat.EnAttente, lot.Evenements...)
+	gsy.etat.EnAttente = nil
+	for _, evenement := range aTraiter {
+		evenement.UID = uidEvenement(evenement)
+		if connus[evenement.UID] {
+			continue
+		}
+
+		traduit, ok, err := gsy.traduire(evenement, emprunts)
+		if err != nil {
+			return bilan, err
+		}
+		if !ok {
+			gsy.etat.EnAttente = append(gsy.etat.EnAttente, evenement)
+			continue
+		}
+		if err := ge.ajouterRecu(traduit); err != nil {
+			return bilan, err
+		}
+		connus[evenement.UID] = true
+		bilan.Integres++
+	}
+	bilan.EnAttente = len(gsy.etat.EnAttente)
+
+	if bilan.Integres > 0 || bilan.Livres > 0 || bilan.Membres > 0 {
+		if _, err := ge.Rejouer(); err != nil {
+			return bilan, err
+		}
+		bilan.Conflits = gsy.releverConflits()
+	}
+
+	return bilan, gsy.sauvegarderEtat()
+}
+
+// traduire remplace dans un événement reçu les numéros de l'autre poste par
+// ceux de ce poste, retrouvés par les UID. Il retourne false si le livre, le
+// membre ou l'emprunt désigné n'est pas (encore) connu ici.
+func (gsy *GestionnaireSynchro) traduire(evenement models.Evenement, emprunts map[string]int) (models.Evenement, bool, error) {
+	ge := gsy.gestionnaireEmprunts
+
+	if evenement.LivreID != 0 || evenement.LivreUID != "" {
+		livre := ge.gestionnaireLivres.trouverLivreParUID(evenement.LivreUID)
+		if livre == nil {
+			return evenement, false, nil
+		}
+		evenement.LivreID = livre.ID
+	}
+	if evenement.MembreID != 0 || evenement.MembreUID != "" {
+		membre := ge.gestionnaireMembres.trouverMembreParUID(evenement.MembreUID)
+		if membre == nil {
+			return evenement, false, nil
+		}
+		evenement.MembreID = membre.ID
+	}
+
+	if evenement.EmpruntID != 0 || evenement.EmpruntUID != "" {
+		id, connu := emprunts[evenement.EmpruntUID]
+		switch {
+		case evenement.EmpruntUID == "":
+			return evenement, false, nil
+		case connu:
+			evenement.EmpruntID = id
+		case evenement.Type == models.EVENEMENT_LIVRE_EMPRUNTE:
+			// L'emprunt reçoit un numéro de ce poste
+			nouveau, err := ge.sequence.Attribuer()
+			if err != nil {
+				return evenement, false, err
+			}
+			emprunts[evenement.EmpruntUID] = nouveau
+			evenement.EmpruntID = nouveau
+		default:
+			return evenement, false, nil
+		}
+	}
+
+	// Les succursales ne sont pas échangées : un numéro inconnu ici est oublié
+	if ge.gestionnaireLivres.gestionnaireSuccursales.TrouverSuccursaleParID(evenement.SuccursaleID) == nil {
+		evenement.SuccursaleID = 0
+	}
+	return evenement, true, nil
+}
+
+// importerLivres ajoute au catalogue les livres du lot dont l'UID est inconnu
+// ici. Ils reçoivent un numéro de ce poste ; genre et auteurs sont retrouvés
+// par leur nom. Ils gardent le code-barres collé sur l'exemplaire : un code
+// déjà porté par un livre d'ici est relevé dans le bilan, sans être remplacé.
+func (gsy *GestionnaireSynchro) importerLivres(livres []models.Livre, bilan *BilanSynchro) (int, error) {
+	gl := gsy.gestionnaireEmprunts.gestionnaireLivres
+	ajoutes := 0
+	for _, livre := range livres {
+		if livre.UID == "" || gl.trouverLivreParUID(livre.UID) != nil {
+			continue
+		}
+
+		id, err := gl.sequence.Attribuer()
+		if err != nil {
+			return ajoutes, err
+		}
+		if livre.CodeBarres == "" {
+			livre.CodeBarres = gl.codeGenere(id)
+		} else if gl.codePorte(livre.CodeBarres) {
+			bilan.CodesEnDouble = append(bilan.CodesEnDouble, livre.CodeBarres)
+		}
+		livre.ID = id
+		livre.Sujets = nil
+		if genre := gl.gestionnaireGenres.Resoudre(livre.Genre); genre != nil {
+			livre.Genre, livre.Sujets = genre.Nom, []int{genre.ID}
+		}
+		if livre.Contributions, err = gl.contributionsAuteurs(strings.Split(livre.Auteur, ", ")); err != nil {
+			return ajoutes, err
+		}
+		gl.rafraichirAuteurs(&livre)
+		livre.OeuvreID, livre.Acquisition, livre.Transfert = 0, nil, nil
+		if gl.gestionnaireSuccursales.TrouverSuccursaleParID(livre.SuccursaleID) == nil {
+			livre.SuccursaleID = 0
+		}
+		if gl.gestionnaireSuccursales.TrouverSuccursaleParID(livre.EmplacementID) == nil {
+			livre.EmplacementID = livre.SuccursaleID
+		}
+
+		// Disponibilité et compteurs sont déduits du journal au rejeu
+		livre.Disponible, livre.NombreEmprunts, livre.EmpruntsArchives = true, 0, 0
+		gl.livres = append(gl.livres, livre)
+		ajoutes++
+	}
+
+	if ajoutes == 0 {
+		return 0, nil
+	}
+	return ajoutes, gl.sauvegarderLivres()
+}
+
+// importerMembres ajoute les membres du lot dont l'UID est inconnu ici ; ils
+// gardent leur carte, relevée dans le bilan si un membre d'ici la porte déjà
+func (gsy *GestionnaireSynchro) importerMembres(membres []models.Membre, bilan *BilanSynchro) (int, error) {
+	gm := gsy.gestionnaireEmprunts.gestionnaireMembres
+	ajoutes := 0
+	for _, membre := range membres {
+		if membre.UID == "" || gm.trouverMembreParUID(membre.UID) != nil {
+			continue
+		}
+
+		id, err := gm.sequence.Attribuer()
+		if err != nil {
+			return ajoutes, err
+		}
+		if membre.NumeroCarte == "" {
+			membre.NumeroCarte = gm.numeroGenere(id)
+		} else if gm.cartePortee(membre.NumeroCarte) {
+			bilan.CodesEnDouble = append(bilan.CodesEnDouble, membre.NumeroCarte)
+		}
+		membre.ID = id
+		if gm.gestionnaireSuccursales.TrouverSuccursaleParID(membre.SuccursaleID) == nil {
+			membre.SuccursaleID = 0
+		}
+
+		membre.Actif, membre.EmpruntsActifs, membre.NombreEmprunts, membre.EmpruntsArchives = true, 0, 0, 0
+		gm.membres = append(gm.membres, membre)
+		ajoutes++
+	}
+
+	if ajoutes == 0 {
+		return 0, nil
+	}
+	return ajoutes, gm.SauvegarderMembres()
+}
+
+// ========================================
+// CONFLITS
+// ========================================
+
+// releverConflits enregistre les emprunts écartés au dernier rejeu qui ne
+// l'étaient pas encore, et les retourne
+func (gsy *GestionnaireSynchro) releverConflits() []models.ConflitSynchro {
+	ge := gsy.gestionnaireEmprunts
+	connus := make(map[string]bool)
+	for _, conflit := range gsy.etat.Conflits {
+		connus[conflit.UID] = true
+	}
+
+	instances := make(map[string]string)
+	for _, evenement := range ge.evenements {
+		if evenement.Type == models.EVENEMENT_LIVRE_EMPRUNTE {
+			instances[evenement.EmpruntUID] = evenement.Instance
+		}
+	}
+
+	var nouveaux []models.ConflitSynchro
+	for _, ecarte := range ge.projection.ecartes {
+		if connus[ecarte.Evenement.UID] {
+			continue
+		}
+		nouveaux = append(nouveaux, models.ConflitSynchro{
+			UID:            ecarte.Evenement.UID,
+			LivreID:        ecarte.Evenement.LivreID,
+			TitreLivre:     ecarte.Evenement.TitreLivre,
+			MembreRetenu:   ecarte.Retenu.NomMembre,
+			DateRetenue:    ecarte.Retenu.DateEmprunt,
+			InstanceRetenu: instances[ecarte.Retenu.UID],
+			MembreEcarte:   ecarte.Evenement.NomMembre,
+			DateEcartee:    ecarte.Evenement.Date,
+			InstanceEcarte: ecarte.Evenement.Instance,
+			DateDetection:  time.Now(),
+		})
+	}
+	gsy.etat.Conflits = append(gsy.etat.Conflits, nouveaux...)
+	return nouveaux
+}
+
+// ListerConflits retourne les conflits détectés, les plus récents d'abord ;
+// seulement ceux qui restent à régulariser si tous est faux
+func (gsy *GestionnaireSynchro) ListerConflits(tous bool) []models.ConflitSynchro {
+	var conflits []models.ConflitSynchro
+	for i := len(gsy.etat.Conflits) - 1; i >= 0; i-- {
+		if tous || !gsy.etat.Conflits[i].EstTraite() {
+			conflits = append(conflits, gsy.etat.Conflits[i])
+		}
+	}
+	return conflits
+}
+
+// ConflitsATraiter compte les conflits qui restent à régulariser
+func (gsy *GestionnaireSynchro) ConflitsATraiter() int {
+	return len(gsy.ListerConflits(false))
+}
+
+// MarquerConflitTraite note que le conflit a été régularisé au comptoir
+func (gsy *GestionnaireSynchro) MarquerConflitTraite(uid string) error {
+	for i, conflit := range gsy.etat.Conflits {
+		if conflit.UID != uid {
+			continue
+		}
+		if conflit.EstTraite() {
+			return fmt.Errorf("ce conflit est déjà régularisé")
+		}
+		maintenant := time.Now()
+		gsy.etat.Conflits[i].Traite = &maintenant
+		return gsy.sauvegarderEtat()
+	}
+	return fmt.Errorf("conflit %s introuvable", uid)
+}
+
+// EvenementsEnAttente compte les événements reçus qui attendent un livre ou un membre inconnu ici
+func (gsy *GestionnaireSynchro) EvenementsEnAttente() int {
+	return len(gsy.etat.EnAttente)
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// RÉSEAU DE SUCCURSALES
+// Un membre emprunte et rend dans n'importe quelle succursale. Un livre rendu
+// ailleurs que chez lui, ou réservé par un membre d'une autre succursale,
+// part en transit ; il redevient empruntable quand la succursale d'arrivée
+// le réceptionne.
+// ========================================
+
+// AjouterSuccursale déclare une succursale. À la création de la première,
+// les livres et les membres existants lui sont rattachés : la bibliothèque
+// unique devient la première succursale du réseau.
+func (ge *GestionnaireEmprunts) AjouterSuccursale(code, nom, adresse string, latitude, longitude float64) (*models.Succursale, error) {
+	succursales := ge.gestionnaireLivres.gestionnaireSuccursales
+	premiere := !succursales.EstEnReseau()
+
+	succursale, err := succursales.ajouterSuccursale(code, nom, adresse, latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	if !premiere {
+		return succursale, nil
+	}
+
+	if _, err := ge.gestionnaireLivres.rattacherSuccursale(succursale.ID); err != nil {
+		return succursale, err
+	}
+	if _, err := ge.gestionnaireMembres.rattacherSuccursale(succursale.ID); err != nil {
+		return succursale, err
+	}
+	return succursale, nil
+}
+
+// ReceptionnerTransfert enregistre l'arrivée d'un livre dans la succursale du
+// comptoir. Retourne la réservation pour laquelle il est mis de côté, s'il l'est.
+func (ge *GestionnaireEmprunts) ReceptionnerTransfert(livreID int) (*models.Reservation, error) {
+	livre, index := ge.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return nil, fmt.Errorf("livre ID %d introuvable", livreID)
+	}
+	if !livre.EstEnTransit() {
+		return nil, fmt.Errorf("le livre '%s' n'est pas en transit", livre.Titre)
+	}
+
+	succursales := ge.gestionnaireLivres.gestionnaireSuccursales
+	if ici := succursales.CouranteID(); ici != livre.Transfert.Vers {
+		return nil, fmt.Errorf("le livre '%s' est attendu à %s, pas dans cette succursale",
+			livre.Titre, succursales.NomSuccursale(livre.Transfert.Vers))
+	}
+
+	livre.EmplacementID = livre.Transfert.Vers
+	livre.Transfert = nil
+	ge.gestionnaireLivres.livres[index] = *livre
+	if err := ge.gestionnaireLivres.sauvegarderLivres(); err != nil {
+		return nil, err
+	}
+
+	// Le livre est mis de côté pour le membre qui l'attend ici, ou repart
+	return ge.gestionnaireReservations.livreDisponible(livreID)
+}
+
+// ReceptionnerParCode réceptionne le livre scanné à son arrivée
+func (ge *GestionnaireEmprunts) ReceptionnerParCode(codeLivre string) (models.Livre, *models.Reservation, error) {
+	livre, _ := ge.gestionnaireLivres.TrouverLivreParCode(codeLivre)
+	if livre == nil {
+		return models.Livre{}, nil, fmt.Errorf("aucun livre ne correspond au code '%s'", models.NormaliserCode(codeLivre))
+	}
+
+	livreID := livre.ID
+	reservation, err := ge.ReceptionnerTransfert(livreID)
+	if err != nil {
+		return models.Livre{}, nil, err
+	}
+
+	livre, _ = ge.gestionnaireLivres.TrouverLivreParID(livreID)
+	return *livre, reservation, nil
+}
+
+// ReaffecterLivre fait passer un livre dans le fonds d'une autre succursale.
+// S'il est en rayon ailleurs et que personne ne l'attend, il y est acheminé ;
+// sinon il la rejoindra à son prochain retour.
+func (ge *GestionnaireEmprunts) ReaffecterLivre(livreID, succursaleID int) error {
+	livre, index := ge.gestionnaireLivres.TrouverLivreParID(livreID)
+	if livre == nil {
+		return fmt.Errorf("livre ID %d introuvable", livreID)
+	}
+	if livre.EstRetire() {
+		return fmt.Errorf("le livre '%s' a été retiré du fonds (%s)", livre.Titre, livre.Retrait.String())
+	}
+
+	succursale := ge.gestionnaireLivres.gestionnaireSuccursales.TrouverSuccursaleParID(succursaleID)
+	if succursale == nil {
+		return fmt.Errorf("succursale ID %d introuvable", succursaleID)
+	}
+	if livre.SuccursaleID == succursaleID {
+		return fmt.Errorf("le livre '%s' fait déjà partie du fonds de %s", livre.Titre, succursale.Nom)
+	}
+
+	livre.SuccursaleID = succursaleID
+	gr := ge.gestionnaireReservations
+	switch {
+	case livre.EstEnTransit() && livre.Transfert.Motif == models.TRANSFERT_RETOUR:
+		// Le livre rentrait dans son ancienne succursale : il change de destination
+		livre.Transfert.Vers = succursaleID
+		livre.Transfert.Motif = models.TRANSFERT_REAFFECTE
+	case gr.estLibre(*livre) && len(gr.FileAttente(livre.ID)) == 0 && livre.EmplacementID != succursaleID:
+		ge.gestionnaireLivres.expedier(livre, succursaleID, models.TRANSFERT_REAFFECTE, 0)
+	}
+	ge.gestionnaireLivres.livres[index] = *livre
+
+	return ge.gestionnaireLivres.sauvegarderLivres()
+}
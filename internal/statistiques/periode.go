@@ -0,0 +1,125 @@
+// ==========================================
+// internal/statistiques/periode.go
+// PÉRIODE COUVERTE PAR DES STATISTIQUES
+// ==========================================
+
+package statistiques
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FORMATS_PERIODE résume les saisies acceptées par LirePeriode
+const FORMATS_PERIODE = "AAAA-MM, AAAA ou JJ/MM/AAAA-JJ/MM/AAAA"
+
+var nomsMois = [12]string{
+	"janvier", "février", "mars", "avril", "mai", "juin",
+	"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+}
+
+// Periode couvre les instants de Debut (inclus) à Fin (exclue).
+// Une borne nulle n'est pas limitée : Periode{} couvre toute l'activité.
+type Periode struct {
+	Debut time.Time `json:"debut,omitzero"`
+	Fin   time.Time `json:"fin,omitzero"`
+}
+
+// Mois retourne la période du mois civil contenant t
+func Mois(t time.Time) Periode {
+	debut := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Periode{Debut: debut, Fin: debut.AddDate(0, 1, 0)}
+}
+
+// DouzeMois retourne les 12 mois civils qui finissent avec le mois de reference
+func DouzeMois(reference time.Time) Periode {
+	dernier := Mois(reference)
+	return Periode{Debut: dernier.Debut.AddDate(0, -11, 0), Fin: dernier.Fin}
+}
+
+// LirePeriode interprète un mois (2026-09), une année (2026) ou un intervalle
+// de dates (01/09/2026-15/10/2026, bornes incluses). Une saisie vide désigne
+// le mois précédant maintenant : c'est le rapport mensuel habituel.
+func LirePeriode(saisie string, maintenant time.Time) (Periode, error) {
+	saisie = strings.TrimSpace(saisie)
+
+	if saisie == "" {
+		return Mois(Mois(maintenant).Debut.AddDate(0, -1, 0)), nil
+	}
+
+	if debut, err := time.ParseInLocation("2006-01", saisie, time.Local); err == nil {
+		return Mois(debut), nil
+	}
+
+	if debut, err := time.ParseInLocation("2006", saisie, time.Local); err == nil {
+		return Periode{Debut: debut, Fin: debut.AddDate(1, 0, 0)}, nil
+	}
+
+	if bornes := strings.Split(saisie, "-"); len(bornes) == 2 {
+		debut, errDebut := time.ParseInLocation("02/01/2006", strings.TrimSpace(bornes[0]), time.Local)
+		fin, errFin := time.ParseInLocation("02/01/2006", strings.TrimSpace(bornes[1]), time.Local)
+		if errDebut == nil && errFin == nil {
+			if fin.Before(debut) {
+				return Periode{}, fmt.Errorf("la période se termine avant de commencer (%s)", saisie)
+			}
+			return Periode{Debut: debut, Fin: fin.AddDate(0, 0, 1)}, nil
+		}
+	}
+
+	return Periode{}, fmt.Errorf("période '%s' non reconnue (formats acceptés : %s)", saisie, FORMATS_PERIODE)
+}
+
+// Contient indique si l'instant tombe dans la période
+func (p Periode) Contient(t time.Time) bool {
+	return (p.Debut.IsZero() || !t.Before(p.Debut)) && (p.Fin.IsZero() || t.Before(p.Fin))
+}
+
+// EstBornee indique si la période a un début et une fin
+func (p Periode) EstBornee() bool {
+	return !p.Debut.IsZero() && !p.Fin.IsZero()
+}
+
+// estMois et estAnnee reconnaissent les périodes civiles, pour les nommer simplement
+func (p Periode) estMois() bool {
+	return p.EstBornee() && p.Debut.Day() == 1 && p.Debut.Hour() == 0 && p.Fin.Equal(p.Debut.AddDate(0, 1, 0))
+}
+
+func (p Periode) estAnnee() bool {
+	return p.EstBornee() && p.Debut.YearDay() == 1 && p.Debut.Hour() == 0 && p.Fin.Equal(p.Debut.AddDate(1, 0, 0))
+}
+
+// Dernier retourne le dernier jour inclus d'une période bornée
+func (p Periode) Dernier() time.Time {
+	return p.Fin.AddDate(0, 0, -1)
+}
+
+// Libelle décrit la période en toutes lettres, par exemple « septembre 2026 »
+func (p Periode) Libelle() string {
+	switch {
+	case p.estMois():
+		return fmt.Sprintf("%s %d", nomsMois[p.Debut.Month()-1], p.Debut.Year())
+	case p.estAnnee():
+		return fmt.Sprintf("année %d", p.Debut.Year())
+	case p.EstBornee():
+		return fmt.Sprintf("du %s au %s", p.Debut.Format("02/01/2006"), p.Dernier().Format("02/01/2006"))
+	case !p.Debut.IsZero():
+		return "depuis le " + p.Debut.Format("02/01/2006")
+	case !p.Fin.IsZero():
+		return "jusqu'au " + p.Dernier().Format("02/01/2006")
+	}
+	return "toute l'activité"
+}
+
+// Nom sert à nommer des fichiers, par exemple « 2026-09 »
+func (p Periode) Nom() string {
+	switch {
+	case p.estMois():
+		return p.Debut.Format("2006-01")
+	case p.estAnnee():
+		return p.Debut.Format("2006")
+	case p.EstBornee():
+		return p.Debut.Format("20060102") + "-" + p.Dernier().Format("20060102")
+	}
+	return "tout"
+}
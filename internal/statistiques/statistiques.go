@@ -0,0 +1,207 @@
+// ==========================================
+// internal/statistiques/statistiques.go
+// STATISTIQUES TYPÉES DE LA LIBRAIRIE
+// ==========================================
+
+package statistiques
+
+import (
+	"sort"
+	"time"
+)
+
+// ========================================
+// STATISTIQUES PAR DOMAINE
+// Calculées par les gestionnaires (ObtenirStatistiques), pour une période au choix.
+// Toutes se sérialisent en JSON telles quelles.
+// ========================================
+
+// Livres décrit le fonds au moment du calcul ; seule Ajoutes dépend de la période
+type Livres struct {
+	Periode        Periode       `json:"periode"`
+	SuccursaleID   int           `json:"succursale_id,omitempty"` // 0 = tout le réseau
+	Total          int           `json:"total"`                   // livres au catalogue, retirés exclus
+	Retires        int           `json:"retires"`
+	Disponibles    int           `json:"disponibles"`
+	Empruntes      int           `json:"empruntes"`
+	EnTransit      int           `json:"en_transit,omitempty"` // acheminés entre deux succursales
+	TauxOccupation float64       `json:"taux_occupation"`      // pourcentage du fonds actuellement emprunté
+	Ajoutes        int           `json:"ajoutes"`              // entrés au catalogue pendant la période
+	ParGenre       []Repartition `json:"par_genre"`            // genre principal, du plus fourni au moins fourni
+	ParGenreCumule []Repartition `json:"par_genre_cumule"`     // sous-genres inclus, dans l'ordre de la taxonomie
+	PlusEmprunte   *Classement   `json:"plus_emprunte,omitempty"`
+}
+
+// Membres décrit les inscrits au moment du calcul ; seule Inscrits dépend de la période
+type Membres struct {
+	Periode      Periode     `json:"periode"`
+	SuccursaleID int         `json:"succursale_id,omitempty"` // 0 = tout le réseau
+	Total        int         `json:"total"`                   // membres inscrits, radiés exclus
+	Radies       int         `json:"radies"`
+	Actifs       int         `json:"actifs"`
+	Suspendus    int         `json:"suspendus"`
+	Inscrits     int         `json:"inscrits"` // inscriptions pendant la période
+	PlusActif    *Classement `json:"plus_actif,omitempty"`
+}
+
+// Emprunts décrit l'activité de la période
+type Emprunts struct {
+	Periode      Periode `json:"periode"`
+	SuccursaleID int     `json:"succursale_id,omitempty"` // 0 = tout le réseau
+
+	// Emprunts commencés pendant la période, selon leur statut actuel
+	Total    int `json:"total"`
+	EnCours  int `json:"en_cours"`
+	Rendus   int `json:"rendus"`
+	EnRetard int `json:"en_retard"`
+	Perdus   int `json:"perdus,omitempty"` // clos par la perte du livre
+
+	// Retours effectués pendant la période
+	Retours           int     `json:"retours"`
+	RetoursEnRetard   int     `json:"retours_en_retard"`
+	DureeMoyenneJours float64 `json:"duree_moyenne_jours"`
+
+	MembresEmprunteurs int `json:"membres_emprunteurs"`
+	LivresDifferents   int `json:"livres_differents"`
+
+	ParMois    []Point       `json:"par_mois"` // mois de la période (les 12 derniers si elle n'est pas bornée)
+	ParGenre   []Repartition `json:"par_genre"`
+	TopLivres  []Classement  `json:"top_livres"`
+	TopMembres []Classement  `json:"top_membres"`
+	Retards    []Retard      `json:"retards"` // à la fin de la période, les plus gros d'abord
+}
+
+// Series décrit les séries et le classement des oeuvres, toutes éditions confondues
+type Series struct {
+	Series              int      `json:"series"`
+	Oeuvres             int      `json:"oeuvres"`
+	ClassementOeuvres   []Oeuvre `json:"classement_oeuvres"`
+	OeuvrePlusEmpruntee *Oeuvre  `json:"oeuvre_plus_empruntee,omitempty"`
+}
+
+// ========================================
+// ÉLÉMENTS COMMUNS
+// ========================================
+
+// Classement est une ligne d'un palmarès de livres ou de membres
+type Classement struct {
+	ID       int    `json:"id"`
+	Nom      string `json:"nom"`
+	Emprunts int    `json:"emprunts"`
+}
+
+// Repartition compte des éléments par catégorie (genre, le plus souvent)
+type Repartition struct {
+	ID      int    `json:"id,omitempty"`
+	Libelle string `json:"libelle"`
+	Nombre  int    `json:"nombre"`
+}
+
+// Oeuvre cumule les emprunts de toutes les éditions d'une oeuvre
+type Oeuvre struct {
+	Titre    string `json:"titre"`
+	Editions int    `json:"editions"`
+	Emprunts int    `json:"emprunts"`
+}
+
+// Retard est un emprunt qui n'était pas rendu à la fin de la période (ou aujourd'hui,
+// si la période n'est pas terminée) alors que sa date de retour était passée
+type Retard struct {
+	EmpruntID       int       `json:"emprunt_id"`
+	Titre           string    `json:"titre"`
+	Membre          string    `json:"membre"`
+	DateRetourPrevu time.Time `json:"date_retour_prevu"`
+	Jours           int       `json:"jours"`
+	RenduDepuis     bool      `json:"rendu_depuis"` // rendu après la fin de la période
+}
+
+// Point est un intervalle d'une série temporelle ; les points se suivent dans l'ordre chronologique
+type Point struct {
+	Debut   time.Time `json:"debut"`
+	Libelle string    `json:"libelle"` // AAAA-MM
+	Valeur  int       `json:"valeur"`
+}
+
+// ========================================
+// CALCULS COMMUNS
+// ========================================
+
+// SerieMensuelle compte les dates par mois civil sur la période. Une période
+// sans début couvre les 12 mois qui finissent avec sa fin (ou avec le mois en cours).
+func SerieMensuelle(periode Periode, dates []time.Time) []Point {
+	if periode.Debut.IsZero() {
+		reference := time.Now()
+		if !periode.Fin.IsZero() {
+			reference = periode.Dernier()
+		}
+		periode = DouzeMois(reference)
+	} else if periode.Fin.IsZero() {
+		periode.Fin = Mois(time.Now()).Fin
+	}
+
+	var points []Point
+	index := make(map[string]int)
+	for mois := Mois(periode.Debut).Debut; mois.Before(periode.Fin); mois = mois.AddDate(0, 1, 0) {
+		libelle := mois.Format("2006-01")
+		index[libelle] = len(points)
+		points = append(points, Point{Debut: mois, Libelle: libelle})
+	}
+
+	for _, date := range dates {
+		if !periode.Contient(date) {
+			continue
+		}
+		if i, ok := index[date.In(periode.Debut.Location()).Format("2006-01")]; ok {
+			points[i].Valeur++
+		}
+	}
+	return points
+}
+
+// Classer trie par emprunts décroissants (puis par nom, et par ID entre deux
+// homonymes, pour un ordre stable) et garde les limite premiers ; une limite
+// de 0 garde tout
+func Classer(compteurs map[int]Classement, limite int) []Classement {
+	classement := make([]Classement, 0, len(compteurs))
+	for _, ligne := range compteurs {
+		classement = append(classement, ligne)
+	}
+	sort.Slice(classement, func(i, j int) bool {
+		if classement[i].Emprunts != classement[j].Emprunts {
+			return classement[i].Emprunts > classement[j].Emprunts
+		}
+		if classement[i].Nom != classement[j].Nom {
+			return classement[i].Nom < classement[j].Nom
+		}
+		return classement[i].ID < classement[j].ID
+	})
+	if limite > 0 && len(classement) > limite {
+		classement = classement[:limite]
+	}
+	return classement
+}
+
+// Repartir transforme un comptage par libellé en répartition, de la plus grande à la plus petite
+func Repartir(compteurs map[string]int) []Repartition {
+	repartition := make([]Repartition, 0, len(compteurs))
+	for libelle, nombre := range compteurs {
+		repartition = append(repartition, Repartition{Libelle: libelle, Nombre: nombre})
+	}
+	sort.Slice(repartition, func(i, j int) bool {
+		if repartition[i].Nombre != repartition[j].Nombre {
+			return repartition[i].Nombre > repartition[j].Nombre
+		}
+		return repartition[i].Libelle < repartition[j].Libelle
+	})
+	return repartition
+}
+
+// Nombre retourne le nombre associé à un ID dans une répartition (0 s'il est absent)
+func Nombre(repartition []Repartition, id int) int {
+	for _, ligne := range repartition {
+		if ligne.ID == id {
+			return ligne.Nombre
+		}
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package statistiques
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fuseau est décalé d'UTC pour que les changements de mois diffèrent selon le fuseau
+var fuseau = time.FixedZone("UTC+1", 3600)
+
+func le(annee int, mois time.Month, jour, heure, minute int) time.Time {
+	return time.Date(annee, mois, jour, heure, minute, 0, 0, fuseau)
+}
+
+// valeurs associe le libellé de chaque mois à sa valeur
+func valeurs(points []Point) map[string]int {
+	resume := make(map[string]int)
+	for _, point := range points {
+		resume[point.Libelle] = point.Valeur
+	}
+	return resume
+}
+
+func TestSerieMensuelleAuxBornes(t *testing.T) {
+	// Du 15 novembre (inclus) au 1er février (exclu)
+	periode := Periode{Debut: le(2025, time.November, 15, 0, 0), Fin: le(2026, time.February, 1, 0, 0)}
+	dates := []time.Time{
+		le(2025, time.November, 10, 12, 0), // avant le début de la période, dans son premier mois
+		le(2025, time.November, 15, 0, 0),  // début inclus
+		le(2025, time.November, 30, 23, 59).Add(59*time.Second + 999*time.Millisecond),
+		le(2025, time.December, 1, 0, 0),
+		le(2025, time.December, 31, 23, 30),
+		// 00:30 le 1er janvier dans le fuseau de la période, encore en décembre en UTC
+		time.Date(2025, time.December, 31, 23, 30, 0, 0, time.UTC),
+		le(2026, time.January, 31, 23, 59),
+		le(2026, time.February, 1, 0, 0),  // fin exclue
+		le(2024, time.December, 15, 0, 0), // même mois, autre année
+	}
+
+	points := SerieMensuelle(periode, dates)
+
+	var libelles []string
+	for _, point := range points {
+		libelles = append(libelles, point.Libelle)
+		if point.Debut.Day() != 1 || point.Debut.Location() != fuseau {
+			t.Errorf("point %s commence le %v", point.Libelle, point.Debut)
+		}
+	}
+	if attendu := []string{"2025-11", "2025-12", "2026-01"}; !reflect.DeepEqual(libelles, attendu) {
+		t.Fatalf("mois %v, attendu %v", libelles, attendu)
+	}
+	if attendu := map[string]int{"2025-11": 2, "2025-12": 2, "2026-01": 2}; !reflect.DeepEqual(valeurs(points), attendu) {
+		t.Errorf("valeurs %v, attendu %v", valeurs(points), attendu)
+	}
+}
+
+func TestSerieMensuellePeriodeOuverte(t *testing.T) {
+	maintenant := time.Now()
+	moisCourant := Mois(maintenant).Debut.Format("2006-01")
+
+	cas := []struct {
+		nom     string
+		periode Periode
+		nombre  int
+		premier string
+		dernier string
+	}{
+		{"sans bornes : les douze derniers mois", Periode{}, 12,
+			Mois(maintenant).Debut.AddDate(0, -11, 0).Format("2006-01"), moisCourant},
+		{"sans début : les douze mois avant la fin", Periode{Fin: le(2026, time.March, 1, 0, 0)}, 12, "2025-03", "2026-02"},
+		{"sans fin : jusqu'au mois courant", Periode{Debut: Mois(maintenant).Debut.AddDate(0, -2, 0)}, 3,
+			Mois(maintenant).Debut.AddDate(0, -2, 0).Format("2006-01"), moisCourant},
+		{"un seul mois", Mois(le(2026, time.February, 10, 0, 0)), 1, "2026-02", "2026-02"},
+	}
+	for _, c := range cas {
+		points := SerieMensuelle(c.periode, nil)
+		if len(points) != c.nombre {
+			t.Errorf("%s : %d points, attendu %d", c.nom, len(points), c.nombre)
+			continue
+		}
+		if points[0].Libelle != c.premier || points[len(points)-1].Libelle != c.dernier {
+			t.Errorf("%s : de %s à %s, attendu de %s à %s", c.nom, points[0].Libelle, points[len(points)-1].Libelle, c.premier, c.dernier)
+		}
+	}
+}
+
+func TestClasser(t *testing.T) {
+	compteurs := map[int]Classement{
+		1: {ID: 1, Nom: "Zoé Dupont", Emprunts: 4},
+		2: {ID: 2, Nom: "Anne Martin", Emprunts: 4},
+		3: {ID: 3, Nom: "Marc Petit", Emprunts: 9},
+		4: {ID: 4, Nom: "Léa Bernard", Emprunts: 1},
+		5: {ID: 5, Nom: "Bruno Durand", Emprunts: 4},
+		// Deux homonymes ex æquo : le plus petit ID passe devant
+		8: {ID: 8, Nom: "Anne Martin", Emprunts: 4},
+		6: {ID: 6, Nom: "Anne Martin", Emprunts: 4},
+	}
+
+	cas := []struct {
+		nom    string
+		limite int
+		ids    []int
+	}{
+		// À égalité d'emprunts, l'ordre suit le nom puis l'ID, quel que soit l'ordre de la map
+		{"sans limite", 0, []int{3, 2, 6, 8, 5, 1, 4}},
+		{"limite au milieu des ex æquo", 2, []int{3, 2}},
+		{"limite entre deux homonymes", 3, []int{3, 2, 6}},
+		{"limite après les ex æquo", 6, []int{3, 2, 6, 8, 5, 1}},
+		{"limite supérieure au nombre de lignes", 10, []int{3, 2, 6, 8, 5, 1, 4}},
+	}
+	for _, c := range cas {
+		// Le parcours d'une map change d'un appel à l'autre : l'ordre doit rester le même
+		for essai := 0; essai < 5; essai++ {
+			var ids []int
+			for _, ligne := range Classer(compteurs, c.limite) {
+				ids = append(ids, ligne.ID)
+			}
+			if !reflect.DeepEqual(ids, c.ids) {
+				t.Errorf("%s : %v, attendu %v", c.nom, ids, c.ids)
+				break
+			}
+		}
+	}
+
+	if classement := Classer(nil, 3); classement == nil || len(classement) != 0 {
+		t.Errorf("sans compteur : %#v, attendu un classement vide", classement)
+	}
+}
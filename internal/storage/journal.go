@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Journal est un fichier en ajout seul : chaque enregistrement est une ligne
+// JSON écrite à la fin du fichier, les lignes déjà écrites ne sont jamais réécrites.
+type Journal struct {
+	filename string
+}
+
+func NewJournal(filename string) *Journal {
+	return &Journal{filename: filename}
+}
+
+// Ajouter écrit un enregistrement à la fin du journal et attend qu'il soit sur le disque
+func (j *Journal) Ajouter(data any) error {
+	dir := filepath.Dir(j.filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("impossible de créer le dossier %s : %v", dir, err)
+	}
+
+	ligne, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("erreur lors de la conversion en JSON : %v", err)
+	}
+
+	fichier, err := os.OpenFile(j.filename, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("impossible d'ouvrir le journal %s : %v", j.filename, err)
+	}
+	defer fichier.Close()
+
+	// Un arrêt brutal peut laisser une dernière ligne sans fin de ligne :
+	// complète, elle est terminée ; coupée, elle est retirée. Seule la
+	// dernière ligne du journal peut ainsi être illisible.
+	debut, reste, err := finSansRetour(fichier)
+	if err != nil {
+		return fmt.Errorf("erreur lors de la lecture du journal %s : %v", j.filename, err)
+	}
+	if len(reste) > 0 {
+		if json.Valid(bytes.TrimSpace(reste)) {
+			ligne = append([]byte{'\n'}, ligne...)
+		} else if err := fichier.Truncate(debut); err != nil {
+			return fmt.Errorf("impossible de retirer la ligne incomplète du journal %s : %v", j.filename, err)
+		}
+	}
+
+	if _, err := fichier.Write(append(ligne, '\n')); err != nil {
+		return fmt.Errorf("erreur lors de l'écriture du journal %s : %v", j.filename, err)
+	}
+	return fichier.Sync()
+}
+
+// finSansRetour retourne la position et le contenu de ce qui suit le dernier
+// retour à la ligne du fichier : vide si le fichier se termine proprement
+func finSansRetour(fichier *os.File) (int64, []byte, error) {
+	info, err := fichier.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	var reste []byte
+	bloc := make([]byte, 4096)
+	for fin := info.Size(); fin > 0; {
+		debut := max(fin-int64(len(bloc)), 0)
+		lus, err := fichier.ReadAt(bloc[:fin-debut], debut)
+		if err != nil && lus < int(fin-debut) {
+			return 0, nil, err
+		}
+		if i := bytes.LastIndexByte(bloc[:lus], '\n'); i >= 0 {
+			return debut + int64(i) + 1, append(append([]byte(nil), bloc[i+1:lus]...), reste...), nil
+		}
+		reste = append(append([]byte(nil), bloc[:lus]...), reste...)
+		fin = debut
+	}
+	return 0, reste, nil
+}
+
+// Relire passe chaque enregistrement du journal, dans l'ordre d'écriture, à la
+// fonction lire. Seule la dernière ligne peut être incomplète (arrêt pendant
+// une écriture) : elle est alors ignorée. Une ligne illisible avant elle est
+// une erreur, car l'enregistrement qu'elle portait serait perdu sans bruit.
+func (j *Journal) Relire(lire func(ligne []byte) error) error {
+	fichier, err := os.Open(j.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erreur lors de la lecture du journal %s : %v", j.filename, err)
+	}
+	defer fichier.Close()
+
+	var lignes [][]byte
+	lecteur := bufio.NewScanner(fichier)
+	lecteur.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lecteur.Scan() {
+		lignes = append(lignes, append([]byte(nil), lecteur.Bytes()...))
+	}
+	if err := lecteur.Err(); err != nil {
+		return fmt.Errorf("erreur lors de la lecture du journal %s : %v", j.filename, err)
+	}
+
+	derniere := -1
+	for i, ligne := range lignes {
+		if len(bytes.TrimSpace(ligne)) > 0 {
+			derniere = i
+		}
+	}
+
+	for i, ligne := range lignes {
+		ligne = bytes.TrimSpace(ligne)
+		if len(ligne) == 0 {
+			continue
+		}
+		if !json.Valid(ligne) {
+			if i == derniere {
+				break
+			}
+			return fmt.Errorf("journal %s, ligne %d : enregistrement illisible", j.filename, i+1)
+		}
+		if err := lire(ligne); err != nil {
+			return fmt.Errorf("journal %s, ligne %d : %v", j.filename, i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type enregistrement struct {
+	Numero int `json:"numero"`
+}
+
+// relireNumeros retourne les numéros lus dans le journal
+func relireNumeros(j *Journal) ([]int, error) {
+	var numeros []int
+	err := j.Relire(func(ligne []byte) error {
+		var e enregistrement
+		if err := json.Unmarshal(ligne, &e); err != nil {
+			return err
+		}
+		numeros = append(numeros, e.Numero)
+		return nil
+	})
+	return numeros, err
+}
+
+func TestRelireLignesIllisibles(t *testing.T) {
+	cas := []struct {
+		nom     string
+		contenu string
+		numeros []int
+		erreur  string // vide si la relecture réussit
+	}{
+		{"journal intact", "{\"numero\":1}\n{\"numero\":2}\n", []int{1, 2}, ""},
+		{"dernière ligne coupée", "{\"numero\":1}\n{\"numero\":2}\n{\"num", []int{1, 2}, ""},
+		{"dernière ligne coupée, suivie de lignes vides", "{\"numero\":1}\n{\"num\n\n", []int{1}, ""},
+		{"dernière ligne complète sans fin de ligne", "{\"numero\":1}\n{\"numero\":2}", []int{1, 2}, ""},
+		{"ligne illisible au milieu", "{\"numero\":1}\n{\"num\n{\"numero\":3}\n", nil, "ligne 2 : enregistrement illisible"},
+		{"première ligne illisible", "pas du json\n{\"numero\":2}\n", nil, "ligne 1 : enregistrement illisible"},
+	}
+	for _, c := range cas {
+		chemin := filepath.Join(t.TempDir(), "journal.jsonl")
+		if err := os.WriteFile(chemin, []byte(c.contenu), 0644); err != nil {
+			t.Fatal(err)
+		}
+		numeros, err := relireNumeros(NewJournal(chemin))
+		switch {
+		case c.erreur == "" && err != nil:
+			t.Errorf("%s : %v", c.nom, err)
+		case c.erreur != "" && (err == nil || !strings.Contains(err.Error(), c.erreur)):
+			t.Errorf("%s : erreur %v, attendu une erreur contenant %q", c.nom, err, c.erreur)
+		case c.erreur == "" && !reflect.DeepEqual(numeros, c.numeros):
+			t.Errorf("%s : numéros %v, attendu %v", c.nom, numeros, c.numeros)
+		}
+	}
+}
+
+func TestAjouterApresUnArretBrutal(t *testing.T) {
+	cas := []struct {
+		nom     string
+		contenu string
+		numeros []int
+	}{
+		// La ligne coupée est retirée : elle ne reste pas au milieu du journal
+		{"ligne coupée", "{\"numero\":1}\n{\"num", []int{1, 3}},
+		// Une ligne complète à laquelle seule manque la fin de ligne est gardée
+		{"ligne complète sans fin de ligne", "{\"numero\":1}\n{\"numero\":2}", []int{1, 2, 3}},
+		{"journal réduit à une ligne coupée", "{\"nu", []int{3}},
+		{"ligne coupée plus longue qu'un bloc de lecture", "{\"numero\":1}\n{\"titre\":\"" + strings.Repeat("a", 10000), []int{1, 3}},
+	}
+	for _, c := range cas {
+		chemin := filepath.Join(t.TempDir(), "journal.jsonl")
+		if err := os.WriteFile(chemin, []byte(c.contenu), 0644); err != nil {
+			t.Fatal(err)
+		}
+		j := NewJournal(chemin)
+		if err := j.Ajouter(enregistrement{Numero: 3}); err != nil {
+			t.Fatal(err)
+		}
+		if numeros, err := relireNumeros(j); err != nil || !reflect.DeepEqual(numeros, c.numeros) {
+			t.Errorf("%s : numéros %v, erreur %v, attendu %v", c.nom, numeros, err, c.numeros)
+		}
+	}
+}
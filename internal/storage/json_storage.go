@@ -1,10 +1,12 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type Storage interface {
@@ -14,19 +16,45 @@ type Storage interface {
 
 type JSONStorage struct {
 	filename string
+	schema   *Schema
+
+	// refus est l'erreur qui a empêché de lire le fichier (schéma inconnu,
+	// version plus récente que le programme...). Tant qu'elle est là, le
+	// fichier n'est pas réécrit : on ne remplace pas des données qu'on n'a pas pu lire.
+	refus error
+}
+
+// enveloppe entoure les données de chaque fichier avec la version de leur format
+type enveloppe struct {
+	Version int             `json:"version"`
+	Schema  string          `json:"schema"`
+	Donnees json.RawMessage `json:"donnees"`
 }
 
-func NewJSONStorage(filename string) *JSONStorage {
-	return &JSONStorage{filename: filename}
+func NewJSONStorage(filename, nomSchema string) *JSONStorage {
+	schema, err := TrouverSchema(nomSchema)
+	return &JSONStorage{filename: filename, schema: schema, refus: err}
 }
 
 func (js *JSONStorage) Sauvegarder(data any) error {
+	if js.refus != nil {
+		return js.refus
+	}
+
+	donnees, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("erreur lors de la conversion en JSON : %v", err)
+	}
+	return js.ecrire(donnees)
+}
+
+func (js *JSONStorage) ecrire(donnees json.RawMessage) error {
 	dir := filepath.Dir(js.filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("imppossible de créer le dossier %s : %v", dir, err)
 	}
 
-	jsonData, err := json.MarshalIndent(data, "", " ")
+	jsonData, err := json.MarshalIndent(enveloppe{Version: js.schema.Version(), Schema: js.schema.Nom, Donnees: donnees}, "", " ")
 	if err != nil {
 		return fmt.Errorf("erreur lors de la conversion en JSON : %v", err)
 	}
@@ -39,7 +67,14 @@ func (js *JSONStorage) Sauvegarder(data any) error {
 	return nil
 }
 
+// Charger lit le fichier et le convertit au format actuel s'il a été écrit
+// par une version précédente. Le fichier d'origine est d'abord copié à côté
+// (livres.json.v1-20260101-120000.bak), puis réécrit au nouveau format.
 func (js *JSONStorage) Charger(data any) error {
+	if js.refus != nil {
+		return js.refus
+	}
+
 	if _, err := os.Stat(js.filename); os.IsNotExist(err) {
 		return nil
 	}
@@ -49,17 +84,68 @@ func (js *JSONStorage) Charger(data any) error {
 		return fmt.Errorf("erreur lors de la lecture du fichier %s : %v", js.filename, err)
 	}
 
-	if len(fileData) == 0 {
+	if len(bytes.TrimSpace(fileData)) == 0 {
 		return nil
 	}
 
-	err = json.Unmarshal(fileData, data)
+	version, donnees, err := js.ouvrirEnveloppe(fileData)
+	if err != nil {
+		js.refus = err
+		return err
+	}
+
+	if version < js.schema.Version() {
+		if err := js.copierSauvegarde(fileData, version); err != nil {
+			return err
+		}
+		if donnees, err = js.schema.Migrer(donnees, version); err != nil {
+			js.refus = fmt.Errorf("fichier %s : %v", js.filename, err)
+			return js.refus
+		}
+		if err := js.ecrire(donnees); err != nil {
+			return err
+		}
+	}
+
+	err = json.Unmarshal(donnees, data)
 	if err != nil {
 		return fmt.Errorf("erreur lors de la conversion depuis JSON : %v", err)
 	}
 	return nil
 }
 
+// ouvrirEnveloppe retourne la version du fichier et ses données. Un fichier
+// sans enveloppe est de la version initiale.
+func (js *JSONStorage) ouvrirEnveloppe(fileData []byte) (int, json.RawMessage, error) {
+	var env enveloppe
+	if bytes.HasPrefix(bytes.TrimSpace(fileData), []byte("{")) {
+		if err := json.Unmarshal(fileData, &env); err != nil {
+			return 0, nil, fmt.Errorf("erreur lors de la conversion depuis JSON : %v", err)
+		}
+	}
+	if env.Version == 0 || len(env.Donnees) == 0 {
+		return VERSION_INITIALE, fileData, nil
+	}
+
+	if env.Schema != "" && env.Schema != js.schema.Nom {
+		return 0, nil, fmt.Errorf("le fichier %s contient des données « %s » et non « %s »", js.filename, env.Schema, js.schema.Nom)
+	}
+	if env.Version > js.schema.Version() {
+		return 0, nil, fmt.Errorf("le fichier %s est au format %d, plus récent que celui de ce programme (%d) : mettez le programme à jour",
+			js.filename, env.Version, js.schema.Version())
+	}
+	return env.Version, env.Donnees, nil
+}
+
+// copierSauvegarde garde le fichier tel qu'il était avant sa migration
+func (js *JSONStorage) copierSauvegarde(fileData []byte, version int) error {
+	copie := fmt.Sprintf("%s.v%d-%s.bak", js.filename, version, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(copie, fileData, 0644); err != nil {
+		return fmt.Errorf("impossible de sauvegarder %s avant sa migration : %v", js.filename, err)
+	}
+	return nil
+}
+
 func (js *JSONStorage) Existe() bool {
 
 	_, err := os.Stat(js.filename)
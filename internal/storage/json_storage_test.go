@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// Chaque dossier de testdata contient les fichiers tels qu'une version du
+// programme les écrivait : v1 sans enveloppe, v2 avec. Tous doivent se
+// charger à l'identique avec le programme actuel.
+var versionsHistoriques = []struct {
+	dossier string
+	version int
+}{
+	{"v1", 1},
+	{"v2", 2},
+}
+
+// copierFixture recopie un fichier de testdata dans un dossier temporaire
+func copierFixture(t *testing.T, version, fichier string) (string, []byte) {
+	t.Helper()
+
+	contenu, err := os.ReadFile(filepath.Join("testdata", version, fichier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chemin := filepath.Join(t.TempDir(), fichier)
+	if err := os.WriteFile(chemin, contenu, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return chemin, contenu
+}
+
+// verifierMigration contrôle qu'après chargement le fichier est au format
+// actuel et que l'original a été sauvegardé s'il a fallu le migrer
+func verifierMigration(t *testing.T, chemin string, original []byte, version int, nomSchema string) {
+	t.Helper()
+
+	schema, _ := TrouverSchema(nomSchema)
+	copies, _ := filepath.Glob(chemin + ".v*.bak")
+
+	if version == schema.Version() {
+		if len(copies) != 0 {
+			t.Errorf("aucune sauvegarde attendue pour un fichier déjà à jour, trouvé %v", copies)
+		}
+		return
+	}
+
+	if len(copies) != 1 {
+		t.Fatalf("une sauvegarde attendue avant la migration, trouvé %v", copies)
+	}
+	if !strings.Contains(filepath.Base(copies[0]), ".v1-") {
+		t.Errorf("la sauvegarde %s ne porte pas la version d'origine", copies[0])
+	}
+	sauvegarde, err := os.ReadFile(copies[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sauvegarde, original) {
+		t.Error("la sauvegarde ne reproduit pas le fichier d'origine")
+	}
+
+	contenu, err := os.ReadFile(chemin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env enveloppe
+	if err := json.Unmarshal(contenu, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Version != schema.Version() || env.Schema != nomSchema {
+		t.Errorf("fichier réécrit en version %d du schéma %q, attendu %d du schéma %q", env.Version, env.Schema, schema.Version(), nomSchema)
+	}
+}
+
+func TestChargerLivresHistoriques(t *testing.T) {
+	for _, v := range versionsHistoriques {
+		t.Run(v.dossier, func(t *testing.T) {
+			chemin, original := copierFixture(t, v.dossier, "livres.json")
+
+			var livres []models.Livre
+			if err := NewJSONStorage(chemin, SCHEMA_LIVRES).Charger(&livres); err != nil {
+				t.Fatal(err)
+			}
+			if len(livres) != 2 {
+				t.Fatalf("%d livre(s) chargé(s), attendu 2", len(livres))
+			}
+			if livres[0].Titre != "Le Petit Prince" || livres[0].Auteur != "Antoine de Saint-Exupéry" || livres[0].Genre != "Roman" {
+				t.Errorf("livre 1 mal chargé : %+v", livres[0])
+			}
+			if livres[0].Disponible || livres[0].NombreEmprunts != 2 || livres[0].DatePublication.Year() != 1943 {
+				t.Errorf("état du livre 1 mal chargé : %+v", livres[0])
+			}
+			if !livres[1].Disponible || livres[1].ISBN != "9782070368228" {
+				t.Errorf("livre 2 mal chargé : %+v", livres[1])
+			}
+			if v.version >= 2 && (livres[0].CodeBarres != "LIV000001" || len(livres[0].Contributions) != 1 || !livres[1].EstRetire()) {
+				t.Errorf("champs de la version %d perdus : %+v", v.version, livres)
+			}
+
+			verifierMigration(t, chemin, original, v.version, SCHEMA_LIVRES)
+
+			// Le fichier réécrit se recharge sans nouvelle migration
+			var relus []models.Livre
+			if err := NewJSONStorage(chemin, SCHEMA_LIVRES).Charger(&relus); err != nil {
+				t.Fatal(err)
+			}
+			if len(relus) != len(livres) || relus[0].Titre != livres[0].Titre {
+				t.Errorf("le fichier migré ne se relit pas à l'identique : %+v", relus)
+			}
+			verifierMigration(t, chemin, original, v.version, SCHEMA_LIVRES)
+		})
+	}
+}
+
+func TestChargerMembresHistoriques(t *testing.T) {
+	for _, v := range versionsHistoriques {
+		t.Run(v.dossier, func(t *testing.T) {
+			chemin, original := copierFixture(t, v.dossier, "membres.json")
+
+			var membres []models.Membre
+			if err := NewJSONStorage(chemin, SCHEMA_MEMBRES).Charger(&membres); err != nil {
+				t.Fatal(err)
+			}
+			if len(membres) != 1 {
+				t.Fatalf("%d membre(s) chargé(s), attendu 1", len(membres))
+			}
+			m := membres[0]
+			if m.Nom != "Zoé Dupont" || m.Email != "zoe@example.com" || !m.Actif || m.NombreEmprunts != 2 || m.EmpruntsActifs != 1 {
+				t.Errorf("membre mal chargé : %+v", m)
+			}
+			if v.version >= 2 && m.NumeroCarte != "MEM000001" {
+				t.Errorf("numéro de carte perdu : %+v", m)
+			}
+
+			verifierMigration(t, chemin, original, v.version, SCHEMA_MEMBRES)
+		})
+	}
+}
+
+func TestChargerEmpruntsHistoriques(t *testing.T) {
+	for _, v := range versionsHistoriques {
+		t.Run(v.dossier, func(t *testing.T) {
+			chemin, original := copierFixture(t, v.dossier, "emprunts.json")
+
+			var emprunts []models.Emprunt
+			if err := NewJSONStorage(chemin, SCHEMA_EMPRUNTS).Charger(&emprunts); err != nil {
+				t.Fatal(err)
+			}
+			if len(emprunts) != 2 {
+				t.Fatalf("%d emprunt(s) chargé(s), attendu 2", len(emprunts))
+			}
+			// Les statuts font partie du format : ils doivent rester reconnus
+			if emprunts[0].Statut != models.STATUT_RENDU || emprunts[0].DateRetourEffectif == nil {
+				t.Errorf("emprunt rendu mal chargé : %+v", emprunts[0])
+			}
+			if emprunts[1].Statut != models.STATUT_EN_RETARD || emprunts[1].DateRetourEffectif != nil {
+				t.Errorf("emprunt en retard mal chargé : %+v", emprunts[1])
+			}
+
+			verifierMigration(t, chemin, original, v.version, SCHEMA_EMPRUNTS)
+		})
+	}
+}
+
+func TestMigrationsEnChaine(t *testing.T) {
+	// Schéma fictif : la version 2 renomme « auteur » en « auteurs » et en
+	// fait une liste, la version 3 passe les statuts en majuscules
+	schemas["essai"] = &Schema{Nom: "essai", Migrations: []Migration{
+		envelopper,
+		func(donnees json.RawMessage) (json.RawMessage, error) {
+			var elements []map[string]any
+			if err := json.Unmarshal(donnees, &elements); err != nil {
+				return nil, err
+			}
+			for _, e := range elements {
+				e["auteurs"] = []any{e["auteur"]}
+				delete(e, "auteur")
+			}
+			return json.Marshal(elements)
+		},
+		func(donnees json.RawMessage) (json.RawMessage, error) {
+			return bytes.ReplaceAll(donnees, []byte(`"en-cours"`), []byte(`"EN_COURS"`)), nil
+		},
+	}}
+	defer delete(schemas, "essai")
+
+	chemin := filepath.Join(t.TempDir(), "essai.json")
+	if err := os.WriteFile(chemin, []byte(`[{"auteur": "Homère", "statut": "en-cours"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var elements []struct {
+		Auteurs []string `json:"auteurs"`
+		Statut  string   `json:"statut"`
+	}
+	if err := NewJSONStorage(chemin, "essai").Charger(&elements); err != nil {
+		t.Fatal(err)
+	}
+	if len(elements) != 1 || len(elements[0].Auteurs) != 1 || elements[0].Auteurs[0] != "Homère" || elements[0].Statut != "EN_COURS" {
+		t.Errorf("migrations mal appliquées : %+v", elements)
+	}
+
+	contenu, _ := os.ReadFile(chemin)
+	var env enveloppe
+	if err := json.Unmarshal(contenu, &env); err != nil || env.Version != 4 {
+		t.Errorf("fichier réécrit en version %d, attendu 4 (%v)", env.Version, err)
+	}
+}
+
+func TestFichierPlusRecent(t *testing.T) {
+	chemin := filepath.Join(t.TempDir(), "livres.json")
+	original := []byte(`{"version": 99, "schema": "livres", "donnees": [{"id": 1, "titre_v99": "?"}]}`)
+	if err := os.WriteFile(chemin, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stockage := NewJSONStorage(chemin, SCHEMA_LIVRES)
+	var livres []models.Livre
+	if err := stockage.Charger(&livres); err == nil || !strings.Contains(err.Error(), "plus récent") {
+		t.Fatalf("erreur attendue pour un format plus récent, obtenu %v", err)
+	}
+
+	// Le programme ne doit pas écraser des données qu'il n'a pas su lire
+	if err := stockage.Sauvegarder([]models.Livre{}); err == nil {
+		t.Fatal("la sauvegarde aurait dû être refusée")
+	}
+	contenu, _ := os.ReadFile(chemin)
+	if !bytes.Equal(contenu, original) {
+		t.Error("le fichier plus récent a été modifié")
+	}
+}
+
+func TestFichierDUnAutreSchema(t *testing.T) {
+	chemin, _ := copierFixture(t, "v2", "membres.json")
+
+	var livres []models.Livre
+	err := NewJSONStorage(chemin, SCHEMA_LIVRES).Charger(&livres)
+	if err == nil || !strings.Contains(err.Error(), "« membres »") {
+		t.Fatalf("erreur attendue pour un fichier de membres chargé comme livres, obtenu %v", err)
+	}
+}
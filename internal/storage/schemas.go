@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Noms des schémas, un par fichier de données. Le nom est écrit dans
+// l'enveloppe : un fichier ne peut pas être chargé à la place d'un autre.
+const (
+	SCHEMA_LIVRES        = "livres"
+	SCHEMA_MEMBRES       = "membres"
+	SCHEMA_EMPRUNTS      = "emprunts"
+	SCHEMA_GENRES        = "genres"
+	SCHEMA_CONTRIBUTEURS = "contributeurs"
+	SCHEMA_SERIES        = "series"
+	SCHEMA_RESERVATIONS  = "reservations"
+	SCHEMA_RECHERCHES    = "recherches"
+	SCHEMA_ACQUISITIONS  = "acquisitions"
+	SCHEMA_INVENTAIRES   = "inventaires"
+	SCHEMA_INSTANTANES   = "instantanes"
+	SCHEMA_SEQUENCES     = "sequences"
+	SCHEMA_SUCCURSALES   = "succursales"
+	SCHEMA_SYNCHRO       = "synchro"
+	SCHEMA_PEB           = "peb"
+)
+
+// VERSION_INITIALE est la version des fichiers écrits avant l'enveloppe :
+// un tableau ou un objet JSON nu, sans numéro de version
+const VERSION_INITIALE = 1
+
+// Migration fait passer les données d'un fichier d'une version à la suivante.
+// Elle travaille sur le JSON brut : les structures Go d'une ancienne version
+// n'existent plus dans le code.
+type Migration func(donnees json.RawMessage) (json.RawMessage, error)
+
+// Schema décrit l'historique du format d'un fichier. Migrations[i] fait
+// passer les données de la version i+1 à la version i+2.
+type Schema struct {
+	Nom        string
+	Migrations []Migration
+}
+
+// Version est la version écrite par ce programme
+func (s *Schema) Version() int {
+	return VERSION_INITIALE + len(s.Migrations)
+}
+
+// Migrer applique, dans l'ordre, les migrations depuis la version donnée
+func (s *Schema) Migrer(donnees json.RawMessage, version int) (json.RawMessage, error) {
+	for v := version; v < s.Version(); v++ {
+		var err error
+		donnees, err = s.Migrations[v-VERSION_INITIALE](donnees)
+		if err != nil {
+			return nil, fmt.Errorf("migration du schéma %s de la version %d à %d : %v", s.Nom, v, v+1, err)
+		}
+	}
+	return donnees, nil
+}
+
+// ========================================
+// REGISTRE DES MIGRATIONS
+// Pour changer le format d'un fichier (champ renommé, valeur de statut
+// modifiée...), ajouter une migration à la fin de la liste de son schéma :
+// les fichiers existants seront convertis au prochain chargement, après
+// une copie de sauvegarde. Ne jamais modifier une migration déjà publiée.
+// ========================================
+
+var schemas = map[string]*Schema{
+	SCHEMA_LIVRES:        {Nom: SCHEMA_LIVRES, Migrations: []Migration{envelopper}},
+	SCHEMA_MEMBRES:       {Nom: SCHEMA_MEMBRES, Migrations: []Migration{envelopper}},
+	SCHEMA_EMPRUNTS:      {Nom: SCHEMA_EMPRUNTS, Migrations: []Migration{envelopper}},
+	SCHEMA_GENRES:        {Nom: SCHEMA_GENRES, Migrations: []Migration{envelopper}},
+	SCHEMA_CONTRIBUTEURS: {Nom: SCHEMA_CONTRIBUTEURS, Migrations: []Migration{envelopper}},
+	SCHEMA_SERIES:        {Nom: SCHEMA_SERIES, Migrations: []Migration{envelopper}},
+	SCHEMA_RESERVATIONS:  {Nom: SCHEMA_RESERVATIONS, Migrations: []Migration{envelopper}},
+	SCHEMA_RECHERCHES:    {Nom: SCHEMA_RECHERCHES, Migrations: []Migration{envelopper}},
+	SCHEMA_ACQUISITIONS:  {Nom: SCHEMA_ACQUISITIONS, Migrations: []Migration{envelopper}},
+	SCHEMA_INVENTAIRES:   {Nom: SCHEMA_INVENTAIRES, Migrations: []Migration{envelopper}},
+	SCHEMA_INSTANTANES:   {Nom: SCHEMA_INSTANTANES, Migrations: []Migration{envelopper}},
+	SCHEMA_SEQUENCES:     {Nom: SCHEMA_SEQUENCES, Migrations: []Migration{envelopper}},
+	SCHEMA_SUCCURSALES:   {Nom: SCHEMA_SUCCURSALES, Migrations: []Migration{envelopper}},
+	SCHEMA_SYNCHRO:       {Nom: SCHEMA_SYNCHRO, Migrations: []Migration{envelopper}},
+	SCHEMA_PEB:           {Nom: SCHEMA_PEB, Migrations: []Migration{envelopper}},
+}
+
+// TrouverSchema retourne le schéma enregistré sous ce nom
+func TrouverSchema(nom string) (*Schema, error) {
+	schema, ok := schemas[nom]
+	if !ok {
+		return nil, fmt.Errorf("schéma de données '%s' inconnu", nom)
+	}
+	return schema, nil
+}
+
+// envelopper (version 1 → 2) : les données ne changent pas, elles sont
+// seulement placées dans l'enveloppe versionnée au moment de l'écriture.
+// Les champs ajoutés depuis les premières versions (sujets, contributions,
+// retraits...) sont complétés au chargement par les services.
+func envelopper(donnees json.RawMessage) (json.RawMessage, error) {
+	return donnees, nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+// Sequences garde, pour chaque type d'enregistrement, le dernier numéro
+// attribué. Un numéro n'est jamais redonné, même si l'enregistrement qui le
+// portait a été purgé depuis : un ancien code-barres ou une ancienne carte
+// ne peut pas désigner quelqu'un ou quelque chose d'autre.
+type Sequences struct {
+	stockage Storage
+	derniers map[string]int
+}
+
+// NewSequences charge les derniers numéros attribués. Un fichier illisible
+// est une erreur : repartir de zéro redonnerait des numéros déjà imprimés sur
+// des codes-barres ou des cartes.
+func NewSequences(stockage Storage) (*Sequences, error) {
+	s := &Sequences{stockage: stockage, derniers: make(map[string]int)}
+	if err := s.Charger(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Charger relit les derniers numéros attribués
+func (s *Sequences) Charger() error {
+	return s.stockage.Charger(&s.derniers)
+}
+
+// Sequence retourne la séquence des numéros d'un type d'enregistrement
+func (s *Sequences) Sequence(nom string) *Sequence {
+	return &Sequence{nom: nom, sequences: s}
+}
+
+// Sequence distribue les numéros d'un type d'enregistrement
+type Sequence struct {
+	nom       string
+	sequences *Sequences
+}
+
+// Prochain retourne le numéro que recevra le prochain enregistrement, sans le réserver
+func (sq *Sequence) Prochain() int {
+	return sq.sequences.derniers[sq.nom] + 1
+}
+
+// Observer signale un numéro présent dans les données : la séquence ne
+// redonnera ni ce numéro ni les précédents
+func (sq *Sequence) Observer(id int) {
+	if id > sq.sequences.derniers[sq.nom] {
+		sq.sequences.derniers[sq.nom] = id
+	}
+}
+
+// Attribuer réserve le prochain numéro et l'enregistre aussitôt
+func (sq *Sequence) Attribuer() (int, error) {
+	id := sq.Prochain()
+	sq.sequences.derniers[sq.nom] = id
+	return id, sq.sequences.Sauvegarder()
+}
+
+// Sauvegarder enregistre les derniers numéros attribués, y compris ceux
+// que des Observer ont fait avancer
+func (s *Sequences) Sauvegarder() error {
+	return s.stockage.Sauvegarder(s.derniers)
+}
+
+// Sauvegarder enregistre toutes les séquences du fichier, celle-ci comprise
+func (sq *Sequence) Sauvegarder() error {
+	return sq.sequences.Sauvegarder()
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSequencesApresRedemarrage(t *testing.T) {
+	chemin := filepath.Join(t.TempDir(), "sequences.json")
+
+	s, err := NewSequences(NewJSONStorage(chemin, SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	livres := s.Sequence("livres")
+	livres.Observer(41)
+	if id, err := livres.Attribuer(); err != nil || id != 42 {
+		t.Fatalf("numéro %d, erreur %v, attendu 42", id, err)
+	}
+
+	s, err = NewSequences(NewJSONStorage(chemin, SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prochain := s.Sequence("livres").Prochain(); prochain != 43 {
+		t.Errorf("prochain numéro %d après redémarrage, attendu 43", prochain)
+	}
+	if prochain := s.Sequence("membres").Prochain(); prochain != 1 {
+		t.Errorf("prochain numéro de membre %d, attendu 1", prochain)
+	}
+}
+
+func TestSequencesFichierIllisible(t *testing.T) {
+	for nom, contenu := range map[string]string{
+		"JSON tronqué":         `{"version": 2, "donnees": {"livres": 4`,
+		"mauvais type":         `{"version": 2, "donnees": ["livres"]}`,
+		"version trop récente": `{"version": 99, "donnees": {"livres": 42}}`,
+	} {
+		t.Run(nom, func(t *testing.T) {
+			chemin := filepath.Join(t.TempDir(), "sequences.json")
+			if err := os.WriteFile(chemin, []byte(contenu), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// La numérotation ne doit pas repartir de zéro en silence
+			if s, err := NewSequences(NewJSONStorage(chemin, SCHEMA_SEQUENCES)); err == nil {
+				t.Errorf("erreur attendue, prochain numéro %d", s.Sequence("livres").Prochain())
+			}
+			if relu, _ := os.ReadFile(chemin); string(relu) != contenu {
+				t.Errorf("le fichier illisible a été modifié : %s", relu)
+			}
+		})
+	}
+}
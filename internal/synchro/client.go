@@ -0,0 +1,155 @@
+// ==========================================
+// internal/synchro/client.go
+// SYNCHRONISATION AVEC UN PAIR
+// ==========================================
+
+package synchro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// CLIENT
+// Une synchronisation va chercher les événements du pair, les intègre, puis
+// lui envoie ceux de ce poste. Les curseurs ne sont avancés qu'une fois
+// l'échange complet : une synchronisation interrompue est reprise à
+// l'identique, les événements déjà connus étant ignorés par leur UID.
+// Le verrou partagé n'est tenu que pendant le travail sur les données, pas
+// pendant les appels réseau : deux postes peuvent se synchroniser l'un avec
+// l'autre en même temps.
+// ========================================
+
+// DELAI_APPEL borne chaque appel au pair
+const DELAI_APPEL = 30 * time.Second
+
+// Resultat décrit une synchronisation réussie
+type Resultat struct {
+	Instance string                // identifiant du pair
+	Recus    services.BilanSynchro // intégration chez nous des événements du pair
+	Envoyes  services.BilanSynchro // intégration chez le pair de nos événements
+}
+
+// Synchroniser échange les événements de ce poste avec le pair d'adresse url,
+// déjà déclaré. verrou (nil s'il n'est pas partagé) est pris le temps de lire
+// et de modifier les données ; l'appelant ne doit pas le tenir.
+func Synchroniser(gsy *services.GestionnaireSynchro, url, cle string, verrou sync.Locker) (Resultat, error) {
+	if verrou == nil {
+		verrou = &sync.Mutex{}
+	}
+
+	verrou.Lock()
+	pair := gsy.TrouverPair(url)
+	if pair == nil {
+		verrou.Unlock()
+		return Resultat{}, fmt.Errorf("le poste %s n'est pas déclaré", url)
+	}
+	copie, instance := *pair, gsy.Instance()
+	verrou.Unlock()
+
+	url = copie.URL
+	resultat, err := echanger(gsy, url, cle, instance, copie.Instance, copie.Recu, copie.Envoye, verrou)
+
+	verrou.Lock()
+	defer verrou.Unlock()
+	if err != nil {
+		gsy.NoterEchec(url, err)
+		return resultat.Resultat, err
+	}
+	return resultat.Resultat, gsy.NoterEchange(url, resultat.Instance, resultat.dernierRecu, resultat.dernierEnvoye)
+}
+
+// resultatEchange ajoute au résultat les curseurs à enregistrer
+type resultatEchange struct {
+	Resultat
+	dernierRecu, dernierEnvoye int
+}
+
+func echanger(gsy *services.GestionnaireSynchro, url, cle, instance, connue string, recu, envoye int, verrou sync.Locker) (resultatEchange, error) {
+	client := &http.Client{Timeout: DELAI_APPEL}
+	var resultat resultatEchange
+
+	var etat Etat
+	if err := appeler(client, http.MethodGet, url+"/synchro/etat", cle, nil, &etat); err != nil {
+		return resultat, err
+	}
+	if etat.Instance == instance {
+		return resultat, fmt.Errorf("l'adresse %s est celle de ce poste", url)
+	}
+
+	// Un pair réinstallé (autre identifiant) ou dont le journal a reculé
+	// (restauration d'une sauvegarde) est repris depuis le début
+	if (connue != "" && connue != etat.Instance) || etat.Dernier < recu {
+		recu, envoye = 0, 0
+	}
+	resultat.Instance = etat.Instance
+
+	var lot services.LotSynchro
+	if err := appeler(client, http.MethodGet, fmt.Sprintf("%s/synchro/changements?depuis=%d", url, recu), cle, nil, &lot); err != nil {
+		return resultat, err
+	}
+	if lot.Instance != etat.Instance {
+		return resultat, fmt.Errorf("le poste %s a changé d'identifiant pendant la synchronisation", url)
+	}
+
+	verrou.Lock()
+	bilan, err := gsy.Integrer(lot)
+	notre := gsy.Changements(envoye)
+	verrou.Unlock()
+	if err != nil {
+		return resultat, err
+	}
+	resultat.Recus = bilan
+	resultat.dernierRecu = lot.Dernier
+
+	if err := appeler(client, http.MethodPost, url+"/synchro/changements", cle, notre, &resultat.Envoyes); err != nil {
+		return resultat, err
+	}
+	resultat.dernierEnvoye = notre.Dernier
+	return resultat, nil
+}
+
+// appeler envoie une requête au pair et décode sa réponse JSON
+func appeler(client *http.Client, methode, url, cle string, corps, reponse any) error {
+	var contenu io.Reader
+	if corps != nil {
+		donnees, err := json.Marshal(corps)
+		if err != nil {
+			return err
+		}
+		contenu = bytes.NewReader(donnees)
+	}
+
+	requete, err := http.NewRequest(methode, url, contenu)
+	if err != nil {
+		return fmt.Errorf("adresse invalide %s : %v", url, err)
+	}
+	requete.Header.Set("Authorization", "Bearer "+cle)
+	if corps != nil {
+		requete.Header.Set("Content-Type", "application/json")
+	}
+
+	r, err := client.Do(requete)
+	if err != nil {
+		return fmt.Errorf("poste injoignable : %v", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(r.Body, 1024))
+		return fmt.Errorf("le poste a répondu %d : %s", r.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(r.Body).Decode(reponse); err != nil {
+		return fmt.Errorf("réponse illisible du poste : %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// ==========================================
+// internal/synchro/serveur.go
+// SERVICE DE SYNCHRONISATION ENTRE POSTES
+// ==========================================
+
+package synchro
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/felver-dev/bookstore/internal/services"
+)
+
+// ========================================
+// SERVEUR
+// Chaque poste peut ouvrir ce service pour que les autres viennent chercher
+// ses événements et déposer les leurs. Un poste central n'est qu'un poste
+// sans comptoir que tous les autres déclarent comme pair. Les appels doivent
+// porter la clé partagée du réseau ; ils sont servis sous le verrou partagé
+// avec l'écran du comptoir.
+// ========================================
+
+// TAILLE_MAX_LOT borne le corps d'un envoi (catalogue et membres compris)
+const TAILLE_MAX_LOT = 64 << 20
+
+// Etat est la réponse de /synchro/etat : qui est le poste, où en est son journal
+type Etat struct {
+	Instance string `json:"instance"`
+	Dernier  int    `json:"dernier"`
+}
+
+type Serveur struct {
+	gestionnaireSynchro *services.GestionnaireSynchro
+	cle                 string
+	verrou              sync.Locker
+}
+
+// NouveauServeur prépare le service ; verrou est celui que tient l'écran du comptoir
+func NouveauServeur(gsy *services.GestionnaireSynchro, cle string, verrou sync.Locker) *Serveur {
+	return &Serveur{gestionnaireSynchro: gsy, cle: cle, verrou: verrou}
+}
+
+// Handler retourne les routes du service
+func (s *Serveur) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /synchro/etat", s.servir(s.etat))
+	mux.HandleFunc("GET /synchro/changements", s.servir(s.changements))
+	mux.HandleFunc("POST /synchro/changements", s.servir(s.recevoir))
+	return mux
+}
+
+// servir vérifie la clé puis prend le verrou partagé
+func (s *Serveur) servir(action func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cle, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.cle == "" || subtle.ConstantTimeCompare([]byte(cle), []byte(s.cle)) != 1 {
+			http.Error(w, "clé de synchronisation refusée", http.StatusUnauthorized)
+			return
+		}
+
+		s.verrou.Lock()
+		defer s.verrou.Unlock()
+		action(w, r)
+	}
+}
+
+func (s *Serveur) etat(w http.ResponseWriter, r *http.Request) {
+	repondre(w, Etat{Instance: s.gestionnaireSynchro.Instance(), Dernier: s.gestionnaireSynchro.DernierEvenement()})
+}
+
+// changements envoie les événements postérieurs au numéro depuis
+func (s *Serveur) changements(w http.ResponseWriter, r *http.Request) {
+	depuis := 0
+	if valeur := r.URL.Query().Get("depuis"); valeur != "" {
+		n, err := strconv.Atoi(valeur)
+		if err != nil || n < 0 {
+			http.Error(w, "paramètre depuis invalide", http.StatusBadRequest)
+			return
+		}
+		depuis = n
+	}
+	repondre(w, s.gestionnaireSynchro.Changements(depuis))
+}
+
+// recevoir intègre le lot envoyé par un autre poste
+func (s *Serveur) recevoir(w http.ResponseWriter, r *http.Request) {
+	var lot services.LotSynchro
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, TAILLE_MAX_LOT)).Decode(&lot); err != nil {
+		http.Error(w, "lot illisible : "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bilan, err := s.gestionnaireSynchro.Integrer(lot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	repondre(w, bilan)
+}
+
+func repondre(w http.ResponseWriter, valeur any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(valeur)
+}
@@ -0,0 +1,324 @@
+package synchro
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/felver-dev/bookstore/internal/config"
+	"github.com/felver-dev/bookstore/internal/models"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/storage"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+const cleTest = "cle-de-test-0123456789"
+
+// poste regroupe les services d'un poste de prêt et son service de synchronisation
+type poste struct {
+	livres   *services.GestionnaireLivres
+	membres  *services.GestionnaireMembres
+	emprunts *services.GestionnaireEmprunts
+	synchro  *services.GestionnaireSynchro
+	verrou   sync.Locker
+	url      string
+}
+
+// ouvrirPoste assemble les services comme main.go dans un dossier à part et
+// ouvre le service de synchronisation sur un port local
+func ouvrirPoste(t *testing.T) *poste {
+	t.Helper()
+
+	cfg := config.Defaut()
+	cfg.Donnees.Dossier = t.TempDir()
+	chemin := func(fichier, schema string) storage.Storage {
+		return storage.NewJSONStorage(cfg.Chemin(fichier), schema)
+	}
+
+	sq, err := storage.NewSequences(chemin(cfg.Donnees.Sequences, storage.SCHEMA_SEQUENCES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsu := services.NouveauGestionnaireSuccursales(chemin(cfg.Donnees.Succursales, storage.SCHEMA_SUCCURSALES), sq)
+	gg := services.NouveauGestionnaireGenres(chemin(cfg.Donnees.Genres, storage.SCHEMA_GENRES), sq, cfg.Validation.Genres)
+	gc := services.NouveauGestionnaireContributeurs(chemin(cfg.Donnees.Contributeurs, storage.SCHEMA_CONTRIBUTEURS), sq)
+	gl := services.NouveauGestionnaireLivres(chemin(cfg.Donnees.Livres, storage.SCHEMA_LIVRES), sq,
+		validators.NouveauValidateur(cfg.Validation.AnneePublicationMin), gg, gc, gsu)
+	gm := services.NouveauGestionnaireMembres(chemin(cfg.Donnees.Membres, storage.SCHEMA_MEMBRES), sq, cfg.Emprunts.LimiteSimultanes, gsu)
+	gr := services.NouveauGestionnaireReservations(chemin(cfg.Donnees.Reservations, storage.SCHEMA_RESERVATIONS), sq, gl, gm, cfg.Emprunts.DelaiRetraitJours)
+	ge, err := services.NouveauGestionnaireEmprunts(chemin(cfg.Donnees.Emprunts, storage.SCHEMA_EMPRUNTS),
+		storage.NewJournal(cfg.Chemin(cfg.Donnees.Journal)), chemin(cfg.Donnees.Instantanes, storage.SCHEMA_INSTANTANES),
+		sq, gl, gm, gr, cfg.Emprunts.DureeJours, cfg.Emprunts.ProlongationsMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsy := services.NouveauGestionnaireSynchro(chemin(cfg.Donnees.Synchro, storage.SCHEMA_SYNCHRO), ge)
+
+	p := &poste{livres: gl, membres: gm, emprunts: ge, synchro: gsy, verrou: &sync.Mutex{}}
+	serveur := httptest.NewServer(NouveauServeur(gsy, cleTest, p.verrou).Handler())
+	t.Cleanup(serveur.Close)
+	p.url = serveur.URL
+	return p
+}
+
+// synchroniser fait synchroniser le poste avec l'autre, déclaré au besoin
+func (p *poste) synchroniser(t *testing.T, autre *poste) Resultat {
+	t.Helper()
+	if p.synchro.TrouverPair(autre.url) == nil {
+		if _, err := p.synchro.AjouterPair(autre.url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resultat, err := Synchroniser(p.synchro, autre.url, cleTest, p.verrou)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resultat
+}
+
+// empruntEnCours retourne le nom du membre qui a le livre d'UID donné
+func (p *poste) empruntEnCours(t *testing.T, uid string) string {
+	t.Helper()
+	for _, livre := range p.livres.ListerLivres() {
+		if livre.UID != uid {
+			continue
+		}
+		for _, emprunt := range p.emprunts.ListerEmpruntsEnCours() {
+			if emprunt.LivreID == livre.ID {
+				return emprunt.NomMembre
+			}
+		}
+		return ""
+	}
+	t.Fatalf("livre %s inconnu du poste", uid)
+	return ""
+}
+
+func TestLivreEmprunteSurDeuxPostes(t *testing.T) {
+	a, b := ouvrirPoste(t), ouvrirPoste(t)
+	if err := a.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range [][3]string{{"Zoé Dupont", "zoe@example.com", "0601020304"}, {"Marc Durand", "marc@example.com", "0605060708"}} {
+		if err := a.membres.AjouterMembre(m[0], m[1], m[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Le poste B, vide, reçoit le catalogue et les membres de A
+	if resultat := b.synchroniser(t, a); resultat.Recus.Livres != 1 || resultat.Recus.Membres != 2 {
+		t.Fatalf("première synchronisation : %+v", resultat.Recus)
+	}
+	livreA, _ := a.livres.TrouverLivreParID(1)
+	uid := livreA.UID
+	zoe, _ := a.membres.TrouverMembreParEmail("zoe@example.com")
+	marc, _ := b.membres.TrouverMembreParEmail("marc@example.com")
+	if marc == nil {
+		t.Fatal("Marc n'a pas été reçu par le poste B")
+	}
+	var livreB *models.Livre
+	for _, livre := range b.livres.ListerLivres() {
+		if livre.UID == uid {
+			livreB = &livre
+		}
+	}
+	if livreB == nil {
+		t.Fatal("le livre n'a pas été reçu par le poste B")
+	}
+
+	// Hors ligne, chacun prête le même livre : Zoé à A d'abord, Marc à B ensuite
+	if err := a.emprunts.EmprunterLivre(1, zoe.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.emprunts.EmprunterLivre(livreB.ID, marc.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	resultat := b.synchroniser(t, a)
+	if len(resultat.Recus.Conflits) != 1 || len(resultat.Envoyes.Conflits) != 1 {
+		t.Fatalf("conflits : %d reçu(s), %d envoyé(s), attendu 1 de chaque côté", len(resultat.Recus.Conflits), len(resultat.Envoyes.Conflits))
+	}
+
+	// Les deux postes retiennent le même emprunt, le plus ancien, et signalent l'autre
+	for nom, p := range map[string]*poste{"A": a, "B": b} {
+		if membre := p.empruntEnCours(t, uid); membre != "Zoé Dupont" {
+			t.Errorf("poste %s : livre chez %q, attendu Zoé Dupont", nom, membre)
+		}
+		conflits := p.synchro.ListerConflits(false)
+		if len(conflits) != 1 || conflits[0].MembreEcarte != "Marc Durand" || conflits[0].MembreRetenu != "Zoé Dupont" {
+			t.Errorf("poste %s : conflits %+v", nom, conflits)
+		}
+	}
+
+	// Une nouvelle synchronisation n'apporte rien ni ne signale le conflit une seconde fois
+	resultat = a.synchroniser(t, b)
+	if resultat.Recus.Integres != 0 || resultat.Envoyes.Integres != 0 || len(resultat.Recus.Conflits)+len(resultat.Envoyes.Conflits) != 0 {
+		t.Errorf("synchronisation sans changement : reçus %+v, envoyés %+v", resultat.Recus, resultat.Envoyes)
+	}
+	if a.synchro.ConflitsATraiter() != 1 {
+		t.Errorf("%d conflit(s) à traiter sur A, attendu 1", a.synchro.ConflitsATraiter())
+	}
+}
+
+func TestRetourRecuApresLEmprunt(t *testing.T) {
+	a, b := ouvrirPoste(t), ouvrirPoste(t)
+	if err := a.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	b.synchroniser(t, a)
+
+	livreA, _ := a.livres.TrouverLivreParID(1)
+	if membre := b.empruntEnCours(t, livreA.UID); membre != "Zoé Dupont" {
+		t.Fatalf("poste B : livre chez %q, attendu Zoé Dupont", membre)
+	}
+
+	// Le livre est rendu à A : B l'apprend et peut de nouveau le prêter
+	if err := a.emprunts.RetournerLivre(1); err != nil {
+		t.Fatal(err)
+	}
+	b.synchroniser(t, a)
+	if membre := b.empruntEnCours(t, livreA.UID); membre != "" {
+		t.Errorf("poste B : livre toujours chez %q", membre)
+	}
+}
+
+func TestCleRefusee(t *testing.T) {
+	a, b := ouvrirPoste(t), ouvrirPoste(t)
+	if _, err := b.synchro.AjouterPair(a.url); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Synchroniser(b.synchro, a.url, "mauvaise-cle-0123456789", b.verrou)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("erreur attendue pour une clé refusée, obtenu %v", err)
+	}
+	if pair := b.synchro.TrouverPair(a.url); pair.EstJoignable() || pair.DerniereErreur == "" {
+		t.Errorf("l'échec doit être noté : %+v", pair)
+	}
+}
+
+func TestCodesGardesEntrePostes(t *testing.T) {
+	a, b := ouvrirPoste(t), ouvrirPoste(t)
+
+	// Avant de se connaître, les deux postes ont imprimé LIV000001 et MEM000001
+	if err := a.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.livres.AjouterLivre("L'Étranger", "Albert Camus", "9782070360024", "Roman", "01/01/1942"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.membres.AjouterMembre("Marc Durand", "marc@example.com", "0605060708"); err != nil {
+		t.Fatal(err)
+	}
+
+	resultat := b.synchroniser(t, a)
+	for _, bilan := range []services.BilanSynchro{resultat.Recus, resultat.Envoyes} {
+		if strings.Join(bilan.CodesEnDouble, ",") != "LIV000001,MEM000001" {
+			t.Errorf("codes en double %v, attendu [LIV000001 MEM000001]", bilan.CodesEnDouble)
+		}
+	}
+
+	// Aucun code imprimé n'est renuméroté, et un code en double désigne le même livre partout
+	for _, p := range []*poste{a, b} {
+		for _, livre := range p.livres.ListerLivres() {
+			if livre.CodeBarres != "LIV000001" {
+				t.Errorf("« %s » : code %s, attendu LIV000001", livre.Titre, livre.CodeBarres)
+			}
+		}
+	}
+	surA, _ := a.livres.TrouverLivreParCode("LIV000001")
+	surB, _ := b.livres.TrouverLivreParCode("LIV000001")
+	if surA.UID != surB.UID {
+		t.Errorf("LIV000001 : « %s » sur A, « %s » sur B", surA.Titre, surB.Titre)
+	}
+	carteA, _ := a.membres.TrouverMembreParCarte("MEM000001")
+	carteB, _ := b.membres.TrouverMembreParCarte("MEM000001")
+	if carteA.UID != carteB.UID {
+		t.Errorf("MEM000001 : %s sur A, %s sur B", carteA.Nom, carteB.Nom)
+	}
+
+	// Après la synchronisation, chaque poste génère des codes qui lui sont propres
+	if a.synchro.Repere() == "" || a.synchro.Repere() == b.synchro.Repere() {
+		t.Fatalf("repères %q et %q, attendus distincts", a.synchro.Repere(), b.synchro.Repere())
+	}
+	if err := a.livres.AjouterLivre("Candide", "Voltaire", "9782070466962", "Roman", "01/01/1759"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.livres.AjouterLivre("Germinal", "Émile Zola", "9782070411795", "Roman", "01/01/1885"); err != nil {
+		t.Fatal(err)
+	}
+	resultat = b.synchroniser(t, a)
+	if len(resultat.Recus.CodesEnDouble) > 0 || len(resultat.Envoyes.CodesEnDouble) > 0 {
+		t.Errorf("codes en double %v et %v, attendu aucun", resultat.Recus.CodesEnDouble, resultat.Envoyes.CodesEnDouble)
+	}
+	for _, titre := range []string{"Candide", "Germinal"} {
+		var code, uid string
+		for _, livre := range a.livres.ListerLivres() {
+			if livre.Titre == titre {
+				code, uid = livre.CodeBarres, livre.UID
+			}
+		}
+		if !models.EstCodeAutomatique(code, models.PREFIXE_CODE_LIVRE) || !strings.Contains(code, "-") {
+			t.Errorf("« %s » : code %q, attendu un code généré avec le repère du poste", titre, code)
+		}
+		if livre, _ := b.livres.TrouverLivreParCode(code); livre == nil || livre.UID != uid {
+			t.Errorf("« %s » : le code %s ne désigne pas le même livre sur B", titre, code)
+		}
+	}
+}
+
+func TestLotSansEmpreintesNiFichesDejaEnvoyees(t *testing.T) {
+	a := ouvrirPoste(t)
+	if err := a.livres.AjouterLivre("Le Petit Prince", "Antoine de Saint-Exupéry", "9782070612758", "Roman", "06/04/1943"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.membres.AjouterMembre("Zoé Dupont", "zoe@example.com", "0601020304"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.membres.DefinirPIN(1, "4821"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.membres.DefinirMotDePasse(1, "mot-de-passe-zoe"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.emprunts.EmprunterLivre(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	lot := a.synchro.Changements(0)
+	if len(lot.Livres) != 1 || len(lot.Membres) != 1 {
+		t.Fatalf("premier lot : %d livre(s) et %d membre(s), attendu 1 et 1", len(lot.Livres), len(lot.Membres))
+	}
+	if m := lot.Membres[0]; m.PIN != "" || m.MotDePasse != "" {
+		t.Errorf("le lot contient les empreintes de %s : PIN %q, mot de passe %q", m.Nom, m.PIN, m.MotDePasse)
+	}
+	if zoe, _ := a.membres.TrouverMembreParID(1); zoe.PIN == "" || zoe.MotDePasse == "" {
+		t.Error("les empreintes du poste ont été effacées")
+	}
+
+	// Les fiches vues au dernier numéro envoyé repartent tant qu'aucun
+	// événement ne le dépasse ; ensuite, seules celles apparues depuis
+	if err := a.emprunts.RetournerLivre(1); err != nil {
+		t.Fatal(err)
+	}
+	lot = a.synchro.Changements(lot.Dernier)
+	if err := a.membres.AjouterMembre("Marc Durand", "marc@example.com", "0605060708"); err != nil {
+		t.Fatal(err)
+	}
+	lot = a.synchro.Changements(lot.Dernier)
+	if len(lot.Evenements) != 0 || len(lot.Livres) != 0 || len(lot.Membres) != 1 || lot.Membres[0].Nom != "Marc Durand" {
+		t.Errorf("lot suivant : %d événement(s), %d livre(s), membres %v ; attendu Marc Durand seul",
+			len(lot.Evenements), len(lot.Livres), lot.Membres)
+	}
+}
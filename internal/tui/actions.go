@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+// ========================================
+// FORMULAIRES ET FICHES DÉTAILLÉES
+// Les contrôles reprennent ceux des validators pour signaler les erreurs à la frappe ;
+// les gestionnaires restent seuls juges à l'enregistrement
+// ========================================
+
+func obligatoire(message string, valide func(string) bool) func(string) error {
+	return func(valeur string) error {
+		if !valide(valeur) {
+			return fmt.Errorf("%s", message)
+		}
+		return nil
+	}
+}
+
+func (app *App) formulaireLivre() *formulaire {
+	return &formulaire{
+		titre: "➕ Nouveau livre",
+		champs: []*champ{
+			{libelle: "Titre", verifier: obligatoire("le titre est obligatoire", validators.ValiderTitre)},
+			{libelle: "Auteur(s)", aide: "plusieurs auteurs séparés par « ; »", verifier: verifierAuteurs},
+			{libelle: "ISBN", aide: "10 ou 13 caractères", verifier: obligatoire("ISBN invalide (10 ou 13 caractères)", validators.ValiderISBN)},
+			{libelle: "Genre", aide: "nom ou alias d'un genre de la taxonomie", verifier: app.verifierGenre},
+			{libelle: "Date de publication", aide: "JJ/MM/AAAA", verifier: app.verifierDate},
+		},
+		enregistrer: func(v []string) (string, error) {
+			if err := app.gestionnaireLivres.AjouterLivre(v[0], v[1], v[2], v[3], v[4]); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Livre '%s' ajouté", v[0]), nil
+		},
+	}
+}
+
+func verifierAuteurs(valeur string) error {
+	noms := 0
+	for _, nom := range strings.Split(valeur, ";") {
+		nom = strings.TrimSpace(nom)
+		if nom == "" {
+			continue
+		}
+		if !validators.ValiderNom(nom) {
+			return fmt.Errorf("nom d'auteur invalide : '%s'", nom)
+		}
+		noms++
+	}
+	if noms == 0 {
+		return fmt.Errorf("au moins un auteur est obligatoire")
+	}
+	return nil
+}
+
+func (app *App) verifierGenre(valeur string) error {
+	if valeur == "" {
+		return fmt.Errorf("le genre est obligatoire")
+	}
+	if app.gestionnaireGenres.Resoudre(valeur) == nil {
+		return fmt.Errorf("genre inconnu : '%s'", valeur)
+	}
+	return nil
+}
+
+func (app *App) verifierDate(valeur string) error {
+	if _, err := time.Parse("02/01/2006", valeur); err != nil {
+		return fmt.Errorf("format attendu : JJ/MM/AAAA")
+	}
+	_, err := app.validateur.ValiderDatePublication(valeur)
+	return err
+}
+
+func (app *App) formulaireMembre() *formulaire {
+	return &formulaire{
+		titre: "➕ Nouveau membre",
+		champs: []*champ{
+			{libelle: "Nom", verifier: obligatoire("nom invalide (lettres, au moins 2 caractères)", validators.ValiderNom)},
+			{libelle: "Email", verifier: obligatoire("adresse email invalide", validators.ValiderEmail)},
+			{libelle: "Téléphone", aide: "format 06 12 34 56 78 ou +33...", verifier: obligatoire("numéro de téléphone invalide", validators.ValiderTelephone)},
+		},
+		enregistrer: func(v []string) (string, error) {
+			if err := app.gestionnaireMembres.AjouterMembre(v[0], v[1], v[2]); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Membre '%s' inscrit", v[0]), nil
+		},
+	}
+}
+
+// formulaireEmprunt pré-remplit le livre ou le membre sélectionné dans le tableau courant
+func (app *App) formulaireEmprunt() *formulaire {
+	champLivre := &champ{libelle: "Livre", aide: "ID ou code-barres scanné", verifier: app.verifierLivreEmpruntable}
+	champMembre := &champ{libelle: "Membre", aide: "ID ou carte scannée", verifier: app.verifierMembreEmprunteur}
+
+	if id, ok := app.vues[app.vueActive].idSelectionne(); ok {
+		switch app.vueActive {
+		case vueLivres:
+			champLivre.valeur = []rune(strconv.Itoa(id))
+			champLivre.controler()
+		case vueMembres:
+			champMembre.valeur = []rune(strconv.Itoa(id))
+			champMembre.controler()
+		}
+	}
+
+	return &formulaire{
+		titre:  "📖 Nouvel emprunt",
+		champs: []*champ{champLivre, champMembre},
+		enregistrer: func(v []string) (string, error) {
+			emprunt, err := app.gestionnaireEmprunts.EmprunterParCode(v[0], v[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Emprunt enregistré : '%s' (retour le %s)", emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006")), nil
+		},
+	}
+}
+
+// formulaireRetour enregistre un retour à partir du code-barres du livre rendu
+func (app *App) formulaireRetour() *formulaire {
+	return &formulaire{
+		titre:  "📤 Retour d'un livre",
+		champs: []*champ{{libelle: "Livre", aide: "ID ou code-barres scanné", verifier: app.verifierLivreEmprunte}},
+		enregistrer: func(v []string) (string, error) {
+			emprunt, err := app.gestionnaireEmprunts.RetournerParCode(v[0])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Retour enregistré : '%s' (%s)%s", emprunt.TitreLivre, emprunt.NomMembre, app.miseDeCote(emprunt.LivreID)), nil
+		},
+	}
+}
+
+func (app *App) verifierLivreEmprunte(valeur string) error {
+	livre, _ := app.gestionnaireLivres.TrouverLivreParCode(valeur)
+	if livre == nil {
+		return fmt.Errorf("aucun livre ne correspond à '%s'", valeur)
+	}
+	if emprunt, _ := app.gestionnaireEmprunts.TrouverEmpruntActifParLivre(livre.ID); emprunt == nil {
+		return fmt.Errorf("'%s' n'est pas emprunté", livre.Titre)
+	}
+	return nil
+}
+
+func (app *App) verifierLivreEmpruntable(valeur string) error {
+	livre, _ := app.gestionnaireLivres.TrouverLivreParCode(valeur)
+	if livre == nil || livre.EstRetire() {
+		return fmt.Errorf("aucun livre ne correspond à '%s'", valeur)
+	}
+	if !livre.EstDisponible() {
+		return fmt.Errorf("'%s' est déjà emprunté", livre.Titre)
+	}
+	return nil
+}
+
+func (app *App) verifierMembreEmprunteur(valeur string) error {
+	membre, _ := app.gestionnaireMembres.TrouverMembreParCarte(valeur)
+	if membre == nil || membre.EstRetire() {
+		return fmt.Errorf("aucun membre ne correspond à '%s'", valeur)
+	}
+	if !membre.PeutEmprunter(app.gestionnaireMembres.LimiteEmprunts()) {
+		if !membre.Actif {
+			return fmt.Errorf("%s est suspendu", membre.Nom)
+		}
+		return fmt.Errorf("%s a atteint la limite de %d emprunts", membre.Nom, app.gestionnaireMembres.LimiteEmprunts())
+	}
+	return nil
+}
+
+// ouvrirDetails affiche la fiche de l'élément sélectionné
+func (app *App) ouvrirDetails() {
+	id, ok := app.vues[app.vueActive].idSelectionne()
+	if !ok {
+		return
+	}
+
+	var fiche []string
+	switch app.vueActive {
+	case vueLivres:
+		livre, _ := app.gestionnaireLivres.TrouverLivreParID(id)
+		if livre == nil {
+			return
+		}
+		fiche = []string{
+			colorer(ansiGras, fmt.Sprintf(" Livre #%d", livre.ID)), "",
+			" Titre         : " + livre.Titre,
+			" Auteur        : " + livre.Auteur,
+			" ISBN          : " + livre.ISBN,
+			" Code-barres   : " + livre.CodeBarres,
+			" Genre         : " + livre.Genre,
+			" Publication   : " + livre.DatePublication.Format("02/01/2006"),
+			" Ajouté le     : " + livre.DateAjout.Format("02/01/2006"),
+			fmt.Sprintf(" Emprunts      : %d", livre.NombreEmprunts),
+		}
+		if emprunt, _ := app.gestionnaireEmprunts.TrouverEmpruntActifParLivre(id); emprunt != nil {
+			fiche = append(fiche, fmt.Sprintf(" Emprunté par  : %s (retour prévu le %s)",
+				emprunt.NomMembre, emprunt.DateRetourPrevu.Format("02/01/2006")))
+		} else {
+			fiche = append(fiche, " Statut        : disponible")
+		}
+	case vueMembres:
+		membre, _ := app.gestionnaireMembres.TrouverMembreParID(id)
+		if membre == nil {
+			return
+		}
+		fiche = []string{
+			colorer(ansiGras, fmt.Sprintf(" Membre #%d", membre.ID)), "",
+			" Nom           : " + membre.Nom,
+			" Email         : " + membre.Email,
+			" Téléphone     : " + membre.Telephone,
+			" Carte         : " + membre.NumeroCarte,
+			" Inscrit le    : " + membre.DateInscription.Format("02/01/2006"),
+			fmt.Sprintf(" Emprunts      : %d en cours, %d au total", membre.EmpruntsActifs, membre.NombreEmprunts),
+			"", colorer(ansiGras, " Emprunts en cours"),
+		}
+		for _, emprunt := range app.gestionnaireEmprunts.ListerEmpruntsParMembre(id) {
+			if emprunt.DateRetourEffectif != nil {
+				continue
+			}
+			ligne := fmt.Sprintf("   #%d %s – à rendre le %s", emprunt.ID, emprunt.TitreLivre, emprunt.DateRetourPrevu.Format("02/01/2006"))
+			if emprunt.EstEnRetard() {
+				ligne = colorer(ansiRouge, ligne+" (en retard)")
+			}
+			fiche = append(fiche, ligne)
+		}
+	case vueEmprunts:
+		emprunt, _ := app.gestionnaireEmprunts.TrouverEmpruntParID(id)
+		if emprunt == nil {
+			return
+		}
+		fiche = []string{
+			colorer(ansiGras, fmt.Sprintf(" Emprunt #%d", emprunt.ID)), "",
+			" Livre         : " + emprunt.TitreLivre,
+			" Membre        : " + emprunt.NomMembre,
+			" Emprunté le   : " + emprunt.DateEmprunt.Format("02/01/2006 15:04"),
+			" À rendre le   : " + emprunt.DateRetourPrevu.Format("02/01/2006"),
+		}
+		if emprunt.EstPerdu() {
+			fiche = append(fiche, " Perdu le      : "+emprunt.DateRetourEffectif.Format("02/01/2006 15:04"))
+		} else if emprunt.DateRetourEffectif != nil {
+			fiche = append(fiche, " Rendu le      : "+emprunt.DateRetourEffectif.Format("02/01/2006 15:04"))
+		} else if emprunt.EstEnRetard() {
+			fiche = append(fiche, colorer(ansiRouge, fmt.Sprintf(" Retard        : %d jour(s)", int(time.Since(emprunt.DateRetourPrevu).Hours()/24))))
+		}
+	}
+
+	app.details = fiche
+	app.mode = modeDetails
+}
@@ -0,0 +1,467 @@
+// ==========================================
+// internal/tui/app.go
+// INTERFACE PLEIN ÉCRAN DU COMPTOIR
+// ==========================================
+
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+	"github.com/felver-dev/bookstore/internal/services"
+	"github.com/felver-dev/bookstore/internal/validators"
+)
+
+type modeApp int
+
+const (
+	modeTableau modeApp = iota
+	modeRecherche
+	modeFormulaire
+	modeConfirmation
+	modeDetails
+)
+
+// App est l'interface plein écran : trois tableaux (livres, membres, emprunts)
+// pilotés au clavier, au-dessus des mêmes gestionnaires que les menus classiques
+type App struct {
+	gestionnaireLivres   *services.GestionnaireLivres
+	gestionnaireMembres  *services.GestionnaireMembres
+	gestionnaireEmprunts *services.GestionnaireEmprunts
+	gestionnaireGenres   *services.GestionnaireGenres
+	validateur           *validators.Validateur
+
+	vues            []*vueTableau
+	vueActive       int
+	tousLesEmprunts bool // afficher aussi les emprunts rendus
+
+	mode         modeApp
+	formulaire   *formulaire
+	confirmation string
+	action       func() (string, error)
+	details      []string
+
+	message       string
+	messageErreur bool
+	quitter       bool
+
+	// verrou protège les données partagées avec le portail des membres :
+	// l'écran le tient sauf pendant qu'il attend une touche (nil : pas de partage)
+	verrou sync.Locker
+}
+
+// NouvelleApp crée l'interface plein écran et charge les tableaux
+func NouvelleApp(gl *services.GestionnaireLivres, gm *services.GestionnaireMembres, ge *services.GestionnaireEmprunts, gg *services.GestionnaireGenres, validateur *validators.Validateur) *App {
+	app := &App{
+		gestionnaireLivres:   gl,
+		gestionnaireMembres:  gm,
+		gestionnaireEmprunts: ge,
+		gestionnaireGenres:   gg,
+		validateur:           validateur,
+		vues:                 nouvellesVues(),
+	}
+	app.recharger()
+	return app
+}
+
+// PartagerVerrou fait attendre l'écran pendant qu'un autre accès (le portail)
+// modifie les données ; l'appelant doit tenir le verrou avant Run
+func (app *App) PartagerVerrou(verrou sync.Locker) {
+	app.verrou = verrou
+}
+
+// Run prend possession du terminal jusqu'à ce que l'utilisateur quitte.
+// menusClassiques est appelé (terminal restauré) quand l'utilisateur presse « m ».
+func (app *App) Run(menusClassiques func() error) error {
+	entree := os.Stdin
+	sortie := bufio.NewWriter(os.Stdout)
+
+	etat, err := app.ouvrirEcran(entree, sortie)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		app.fermerEcran(entree, sortie, etat)
+	}()
+
+	tampon := make([]byte, 256)
+	for !app.quitter {
+		largeur, hauteur, err := affichage.TailleTerminal(os.Stdout)
+		if err != nil || largeur <= 0 || hauteur <= 0 {
+			largeur, hauteur = 80, 24
+		}
+		if err := dessiner(sortie, app.rendre(largeur, hauteur), hauteur); err != nil {
+			return err
+		}
+
+		n, err := app.lireTouches(entree, tampon)
+		if err != nil {
+			return nil
+		}
+
+		for _, t := range decoderTouches(tampon[:n]) {
+			if t.code == toucheCaractere && t.r == 'm' && app.mode == modeTableau && menusClassiques != nil {
+				app.fermerEcran(entree, sortie, etat)
+				if err := menusClassiques(); err != nil {
+					fmt.Printf("❌ %s\n", err.Error())
+				}
+				if etat, err = app.ouvrirEcran(entree, sortie); err != nil {
+					return err
+				}
+				app.recharger()
+				continue
+			}
+			app.traiterTouche(t)
+		}
+	}
+
+	return nil
+}
+
+// lireTouches attend le clavier sans bloquer le portail, puis recharge les
+// tableaux : les membres ont pu emprunter ou prolonger entre-temps
+func (app *App) lireTouches(entree *os.File, tampon []byte) (int, error) {
+	if app.verrou == nil {
+		return entree.Read(tampon)
+	}
+
+	app.verrou.Unlock()
+	n, err := entree.Read(tampon)
+	app.verrou.Lock()
+
+	if app.mode == modeTableau {
+		app.recharger()
+	}
+	return n, err
+}
+
+func (app *App) ouvrirEcran(entree *os.File, sortie *bufio.Writer) (*etatTerminal, error) {
+	etat, err := activerModeBrut(entree)
+	if err != nil {
+		return nil, err
+	}
+	sortie.WriteString(ansiEcranAlternatif + ansiMasquerCurseur)
+	return etat, sortie.Flush()
+}
+
+func (app *App) fermerEcran(entree *os.File, sortie *bufio.Writer, etat *etatTerminal) {
+	sortie.WriteString(ansiReinitialiser + ansiAfficherCurseur + ansiEcranNormal)
+	sortie.Flush()
+	restaurerTerminal(entree, etat)
+}
+
+// recharger relit les données des gestionnaires dans les tableaux
+func (app *App) recharger() {
+	app.vues[vueLivres].remplacerLignes(lignesLivres(app.gestionnaireLivres.ListerLivres()))
+
+	retards := make(map[int]bool)
+	for _, emprunt := range app.gestionnaireEmprunts.ListerEmpruntsEnRetard() {
+		retards[emprunt.MembreID] = true
+	}
+	app.vues[vueMembres].remplacerLignes(lignesMembres(app.gestionnaireMembres.ListerMembres(), retards))
+
+	if app.tousLesEmprunts {
+		app.vues[vueEmprunts].remplacerLignes(lignesEmprunts(app.gestionnaireEmprunts.ListerEmprunts()))
+	} else {
+		app.vues[vueEmprunts].remplacerLignes(lignesEmprunts(app.gestionnaireEmprunts.ListerEmpruntsEnCours()))
+	}
+}
+
+func (app *App) informer(message string) {
+	app.message = message
+	app.messageErreur = false
+}
+
+func (app *App) signalerErreur(err error) {
+	app.message = err.Error()
+	app.messageErreur = true
+}
+
+// ========================================
+// GESTION DU CLAVIER
+// ========================================
+
+func (app *App) traiterTouche(t touche) {
+	switch app.mode {
+	case modeFormulaire:
+		if ferme, message := app.formulaire.touche(t); ferme {
+			app.mode = modeTableau
+			app.formulaire = nil
+			if message != "" {
+				app.informer(message)
+				app.recharger()
+			}
+		}
+	case modeConfirmation:
+		app.mode = modeTableau
+		if t.code == toucheCaractere && (t.r == 'o' || t.r == 'O') {
+			message, err := app.action()
+			if err != nil {
+				app.signalerErreur(err)
+			} else {
+				app.informer(message)
+			}
+			app.recharger()
+		} else {
+			app.informer("Action annulée")
+		}
+	case modeDetails:
+		app.mode = modeTableau
+	case modeRecherche:
+		app.toucheRecherche(t)
+	default:
+		app.toucheTableau(t)
+	}
+}
+
+// toucheRecherche filtre le tableau à chaque caractère saisi
+func (app *App) toucheRecherche(t touche) {
+	vue := app.vues[app.vueActive]
+
+	switch t.code {
+	case toucheCaractere:
+		vue.filtre += string(t.r)
+	case toucheRetourArriere:
+		if runes := []rune(vue.filtre); len(runes) > 0 {
+			vue.filtre = string(runes[:len(runes)-1])
+		}
+	case toucheEchap, toucheCtrlC:
+		vue.filtre = ""
+		app.mode = modeTableau
+	case toucheEntree:
+		app.mode = modeTableau
+	case toucheHaut, toucheBas, touchePageHaut, touchePageBas:
+		app.naviguer(t)
+		return
+	default:
+		return
+	}
+	vue.curseur = 0
+	vue.appliquer()
+}
+
+func (app *App) naviguer(t touche) bool {
+	vue := app.vues[app.vueActive]
+	page := 10
+
+	switch t.code {
+	case toucheHaut:
+		vue.deplacer(-1)
+	case toucheBas:
+		vue.deplacer(1)
+	case touchePageHaut:
+		vue.deplacer(-page)
+	case touchePageBas:
+		vue.deplacer(page)
+	case toucheDebut:
+		vue.curseur = 0
+	case toucheFin:
+		vue.curseur = len(vue.visibles) - 1
+		vue.borner()
+	default:
+		return false
+	}
+	return true
+}
+
+func (app *App) toucheTableau(t touche) {
+	if app.naviguer(t) {
+		return
+	}
+	vue := app.vues[app.vueActive]
+	app.message = ""
+
+	switch t.code {
+	case toucheCtrlC:
+		app.quitter = true
+	case toucheTab:
+		app.vueActive = (app.vueActive + 1) % len(app.vues)
+	case toucheTabInverse:
+		app.vueActive = (app.vueActive - 1 + len(app.vues)) % len(app.vues)
+	case toucheGauche:
+		vue.changerTri(-1)
+	case toucheDroite:
+		vue.changerTri(1)
+	case toucheEchap:
+		vue.filtre = ""
+		vue.appliquer()
+	case toucheEntree:
+		app.ouvrirDetails()
+	case toucheCaractere:
+		app.commande(t.r)
+	}
+}
+
+func (app *App) commande(r rune) {
+	vue := app.vues[app.vueActive]
+
+	switch r {
+	case 'q':
+		app.quitter = true
+	case '1', '2', '3':
+		app.vueActive = int(r - '1')
+	case '/':
+		app.mode = modeRecherche
+	case 'i':
+		vue.inverserTri()
+	case 'a':
+		switch app.vueActive {
+		case vueLivres:
+			app.ouvrirFormulaire(app.formulaireLivre())
+		case vueMembres:
+			app.ouvrirFormulaire(app.formulaireMembre())
+		default:
+			app.ouvrirFormulaire(app.formulaireEmprunt())
+		}
+	case 'e':
+		app.ouvrirFormulaire(app.formulaireEmprunt())
+	case 'r':
+		app.confirmerRetour()
+	case 't':
+		if app.vueActive == vueEmprunts {
+			app.tousLesEmprunts = !app.tousLesEmprunts
+			app.recharger()
+		}
+	}
+}
+
+func (app *App) ouvrirFormulaire(f *formulaire) {
+	app.formulaire = f
+	app.mode = modeFormulaire
+}
+
+func (app *App) confirmerRetour() {
+	if app.vueActive != vueEmprunts {
+		// Hors de l'onglet Emprunts, le retour se fait en scannant le livre
+		app.ouvrirFormulaire(app.formulaireRetour())
+		return
+	}
+	id, ok := app.vues[vueEmprunts].idSelectionne()
+	if !ok {
+		return
+	}
+	emprunt, _ := app.gestionnaireEmprunts.TrouverEmpruntParID(id)
+	if emprunt == nil || emprunt.DateRetourEffectif != nil {
+		app.signalerErreur(fmt.Errorf("cet emprunt est déjà terminé"))
+		return
+	}
+
+	app.confirmation = fmt.Sprintf("Enregistrer le retour de '%s' par %s ? (o/n)", emprunt.TitreLivre, emprunt.NomMembre)
+	app.action = func() (string, error) {
+		if err := app.gestionnaireEmprunts.RetournerLivre(id); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Retour enregistré : '%s'%s", emprunt.TitreLivre, app.miseDeCote(emprunt.LivreID)), nil
+	}
+	app.mode = modeConfirmation
+}
+
+// miseDeCote complète le message de retour quand le livre est réservé
+func (app *App) miseDeCote(livreID int) string {
+	reservation := app.gestionnaireEmprunts.MiseDeCote(livreID)
+	if reservation == nil {
+		return ""
+	}
+	return fmt.Sprintf(" — 📌 réservé, à mettre de côté pour %s", reservation.NomMembre)
+}
+
+// ========================================
+// RENDU DE L'ÉCRAN
+// ========================================
+
+// rendre compose toutes les lignes de l'écran : onglets, tableau ou fenêtre, barre d'état
+func (app *App) rendre(largeur, hauteur int) []string {
+	lignes := []string{app.rendreOnglets(largeur)}
+
+	piedDePage := []string{app.rendreMessage(largeur), app.rendreBarreEtat(largeur)}
+	hauteurCorps := hauteur - len(lignes) - len(piedDePage)
+
+	var corps []string
+	switch app.mode {
+	case modeFormulaire:
+		corps = app.formulaire.rendre(largeur)
+	case modeDetails:
+		corps = append(app.details, "", " Appuyez sur une touche pour revenir au tableau")
+	default:
+		corps = app.vues[app.vueActive].rendre(largeur, hauteurCorps)
+	}
+
+	for i := 0; i < hauteurCorps; i++ {
+		if i < len(corps) {
+			lignes = append(lignes, corps[i])
+		} else {
+			lignes = append(lignes, "")
+		}
+	}
+
+	return append(lignes, piedDePage...)
+}
+
+func (app *App) rendreOnglets(largeur int) string {
+	var onglets []string
+	for i, vue := range app.vues {
+		libelle := fmt.Sprintf(" %d %s (%d) ", i+1, vue.nom, len(vue.visibles))
+		if i == app.vueActive {
+			libelle = colorer(ansiInverse, libelle)
+		}
+		onglets = append(onglets, libelle)
+	}
+
+	ligne := "📚 " + strings.Join(onglets, " ")
+	if filtre := app.vues[app.vueActive].filtre; filtre != "" || app.mode == modeRecherche {
+		ligne += "   🔍 " + filtre
+		if app.mode == modeRecherche {
+			ligne += "▏"
+		}
+	}
+	return ligne
+}
+
+func (app *App) rendreMessage(largeur int) string {
+	switch {
+	case app.mode == modeFormulaire:
+		return ""
+	case app.mode == modeConfirmation:
+		return colorer(ansiJaune, affichage.Tronquer(" "+app.confirmation, largeur))
+	case app.message != "" && app.messageErreur:
+		return colorer(ansiRouge, affichage.Tronquer(" ❌ "+app.message, largeur))
+	case app.message != "":
+		return colorer(ansiVert, affichage.Tronquer(" ✅ "+app.message, largeur))
+	case app.mode == modeRecherche:
+		return " Tapez pour filtrer   Entrée : garder le filtre   Échap : effacer"
+	}
+	return affichage.Tronquer(" Tab/1-3 : onglet  ↑↓ PgPréc PgSuiv : défiler  ←→ : trier  i : inverser  / : rechercher  "+
+		"a : ajouter  e : emprunter  r : retour  t : tous/en cours  Entrée : détails  m : menus  q : quitter", largeur)
+}
+
+// rendreBarreEtat affiche les compteurs du jour, les retards en rouge
+func (app *App) rendreBarreEtat(largeur int) string {
+	livres := app.gestionnaireLivres.ListerLivres()
+	disponibles := 0
+	for _, livre := range livres {
+		if livre.EstDisponible() {
+			disponibles++
+		}
+	}
+	enCours := len(app.gestionnaireEmprunts.ListerEmpruntsEnCours())
+	enRetard := len(app.gestionnaireEmprunts.ListerEmpruntsEnRetard())
+
+	etat := fmt.Sprintf(" Livres : %d (%d disponibles) │ Membres : %d │ Emprunts en cours : %d │ ",
+		len(livres), disponibles, len(app.gestionnaireMembres.ListerMembres()), enCours)
+	retards := fmt.Sprintf("En retard : %d ", enRetard)
+
+	reste := largeur - affichage.Largeur(etat) - affichage.Largeur(retards)
+	if reste < 0 {
+		return colorer(ansiInverse, affichage.Ajuster(etat+retards, largeur))
+	}
+	retardsColores := colorer(ansiInverse, retards)
+	if enRetard > 0 {
+		retardsColores = colorer(ansiInverse+ansiRouge, retards)
+	}
+	return colorer(ansiInverse, etat) + retardsColores + colorer(ansiInverse, strings.Repeat(" ", reste))
+}
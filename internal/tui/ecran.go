@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// ========================================
+// AFFICHAGE À L'ÉCRAN
+// Séquences ANSI et mise en forme des lignes
+// ========================================
+
+const (
+	ansiEcranAlternatif = "\x1b[?1049h"
+	ansiEcranNormal     = "\x1b[?1049l"
+	ansiMasquerCurseur  = "\x1b[?25l"
+	ansiAfficherCurseur = "\x1b[?25h"
+	ansiEffacerLigne    = "\x1b[K"
+	ansiInverse         = "\x1b[7m"
+	ansiGras            = "\x1b[1m"
+	ansiRouge           = "\x1b[31m"
+	ansiVert            = "\x1b[32m"
+	ansiJaune           = "\x1b[33m"
+	ansiReinitialiser   = "\x1b[0m"
+)
+
+// colorer entoure un texte d'un attribut ANSI
+func colorer(attribut, s string) string {
+	return attribut + s + ansiReinitialiser
+}
+
+// dessiner envoie les lignes au terminal en réécrivant chaque ligne en place,
+// ce qui évite le scintillement d'un effacement complet de l'écran
+func dessiner(sortie *bufio.Writer, lignes []string, hauteur int) error {
+	for i := 0; i < hauteur; i++ {
+		fmt.Fprintf(sortie, "\x1b[%d;1H", i+1)
+		if i < len(lignes) {
+			sortie.WriteString(lignes[i])
+		}
+		sortie.WriteString(ansiReinitialiser + ansiEffacerLigne)
+	}
+	return sortie.Flush()
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// ========================================
+// FORMULAIRES DE SAISIE
+// Chaque champ est validé à la frappe et l'erreur s'affiche sous le champ
+// ========================================
+
+type champ struct {
+	libelle string
+	valeur  []rune
+	aide    string
+	erreur  string
+
+	// verifier retourne une erreur à afficher en ligne, ou nil si la valeur est acceptable
+	verifier func(valeur string) error
+	modifie  bool // l'erreur n'est affichée qu'après une première saisie
+}
+
+type formulaire struct {
+	titre  string
+	champs []*champ
+	focus  int
+	erreur string // erreur retournée par le gestionnaire lors de la validation
+
+	// enregistrer est appelé avec les valeurs saisies quand tous les champs sont valides
+	enregistrer func(valeurs []string) (string, error)
+}
+
+func (c *champ) texte() string {
+	return strings.TrimSpace(string(c.valeur))
+}
+
+func (c *champ) controler() bool {
+	c.erreur = ""
+	if c.verifier != nil {
+		if err := c.verifier(c.texte()); err != nil {
+			c.erreur = err.Error()
+			return false
+		}
+	}
+	return true
+}
+
+// valeurs retourne le contenu de tous les champs, dans l'ordre
+func (f *formulaire) valeurs() []string {
+	valeurs := make([]string, len(f.champs))
+	for i, c := range f.champs {
+		valeurs[i] = c.texte()
+	}
+	return valeurs
+}
+
+// touche traite une touche ; retourne vrai si le formulaire doit être fermé,
+// avec le message de confirmation éventuel
+func (f *formulaire) touche(t touche) (bool, string) {
+	courant := f.champs[f.focus]
+
+	switch t.code {
+	case toucheEchap, toucheCtrlC:
+		return true, ""
+	case toucheTab, toucheBas:
+		f.focus = (f.focus + 1) % len(f.champs)
+	case toucheTabInverse, toucheHaut:
+		f.focus = (f.focus - 1 + len(f.champs)) % len(f.champs)
+	case toucheRetourArriere:
+		if len(courant.valeur) > 0 {
+			courant.valeur = courant.valeur[:len(courant.valeur)-1]
+			courant.modifie = true
+			courant.controler()
+		}
+	case toucheCaractere:
+		courant.valeur = append(courant.valeur, t.r)
+		courant.modifie = true
+		courant.controler()
+	case toucheEntree, toucheCtrlS:
+		// Entrée passe au champ suivant, sauf sur le dernier qui valide le formulaire
+		if t.code == toucheEntree && f.focus < len(f.champs)-1 {
+			f.focus++
+			return false, ""
+		}
+		return f.soumettre()
+	}
+	return false, ""
+}
+
+func (f *formulaire) soumettre() (bool, string) {
+	premiereErreur := -1
+	for i, c := range f.champs {
+		c.modifie = true
+		if !c.controler() && premiereErreur < 0 {
+			premiereErreur = i
+		}
+	}
+	if premiereErreur >= 0 {
+		f.focus = premiereErreur
+		f.erreur = "Corrigez les champs signalés"
+		return false, ""
+	}
+
+	message, err := f.enregistrer(f.valeurs())
+	if err != nil {
+		f.erreur = err.Error()
+		return false, ""
+	}
+	return true, message
+}
+
+// rendre affiche le formulaire centré dans la zone donnée
+func (f *formulaire) rendre(largeur int) []string {
+	largeurLibelle := 0
+	for _, c := range f.champs {
+		if l := affichage.Largeur(c.libelle); l > largeurLibelle {
+			largeurLibelle = l
+		}
+	}
+	largeurValeur := largeur - largeurLibelle - 6
+	if largeurValeur < 10 {
+		largeurValeur = 10
+	}
+
+	lignes := []string{colorer(ansiGras, " "+f.titre), ""}
+	for i, c := range f.champs {
+		valeur := string(c.valeur)
+		// Garder la fin de la saisie visible si elle dépasse la largeur
+		if runes := []rune(valeur); len(runes) >= largeurValeur {
+			valeur = "…" + string(runes[len(runes)-largeurValeur+2:])
+		}
+
+		saisie := affichage.Ajuster(valeur, largeurValeur)
+		if i == f.focus {
+			saisie = colorer(ansiInverse, affichage.Ajuster(valeur+"▏", largeurValeur))
+		}
+		lignes = append(lignes, " "+affichage.Ajuster(c.libelle, largeurLibelle)+" : "+saisie)
+
+		switch {
+		case c.modifie && c.erreur != "":
+			lignes = append(lignes, " "+strings.Repeat(" ", largeurLibelle+3)+colorer(ansiRouge, "✗ "+c.erreur))
+		case i == f.focus && c.aide != "":
+			lignes = append(lignes, " "+strings.Repeat(" ", largeurLibelle+3)+c.aide)
+		default:
+			lignes = append(lignes, "")
+		}
+	}
+
+	if f.erreur != "" {
+		lignes = append(lignes, colorer(ansiRouge, " ❌ "+f.erreur))
+	}
+	lignes = append(lignes, "", " Tab/↑↓ : champ   Entrée : suivant / valider   Ctrl-S : valider   Échap : annuler")
+	return lignes
+}
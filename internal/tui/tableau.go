@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felver-dev/bookstore/internal/affichage"
+)
+
+// ========================================
+// TABLEAUX DÉFILANTS ET TRIABLES
+// ========================================
+
+type colonne struct {
+	titre      string
+	largeur    int  // largeur minimale
+	extensible bool // reçoit l'espace restant
+	aDroite    bool
+}
+
+// cellule associe le texte affiché à une clé de tri
+// (les dates et nombres ne se trient pas correctement sur leur texte)
+type cellule struct {
+	texte string
+	cle   string
+}
+
+type ligneTableau struct {
+	id       int
+	cellules []cellule
+	alerte   bool // affichée en rouge (retard, suspension...)
+}
+
+type vueTableau struct {
+	nom      string
+	colonnes []colonne
+	lignes   []ligneTableau
+
+	visibles   []int // index dans lignes après filtrage et tri
+	filtre     string
+	triColonne int
+	triInverse bool
+	curseur    int // position dans visibles
+	defilement int // première ligne visible
+}
+
+func texte(s string) cellule {
+	return cellule{texte: s, cle: strings.ToLower(s)}
+}
+
+func nombre(n int) cellule {
+	return cellule{texte: fmt.Sprintf("%d", n), cle: fmt.Sprintf("%012d", n)}
+}
+
+func date(t time.Time) cellule {
+	return cellule{texte: t.Format("02/01/2006"), cle: t.Format(time.RFC3339)}
+}
+
+// remplacerLignes met à jour le contenu en conservant la sélection sur le même élément
+func (v *vueTableau) remplacerLignes(lignes []ligneTableau) {
+	idSelectionne, ok := v.idSelectionne()
+	v.lignes = lignes
+	v.appliquer()
+
+	if ok {
+		for i, index := range v.visibles {
+			if v.lignes[index].id == idSelectionne {
+				v.curseur = i
+				return
+			}
+		}
+	}
+	v.borner()
+}
+
+// appliquer recalcule les lignes visibles selon le filtre et le tri
+func (v *vueTableau) appliquer() {
+	filtre := strings.ToLower(v.filtre)
+
+	v.visibles = v.visibles[:0]
+	for i, ligne := range v.lignes {
+		if filtre == "" || ligne.correspond(filtre) {
+			v.visibles = append(v.visibles, i)
+		}
+	}
+
+	colonneTri := v.triColonne
+	sort.SliceStable(v.visibles, func(i, j int) bool {
+		a := v.lignes[v.visibles[i]].cellules[colonneTri].cle
+		b := v.lignes[v.visibles[j]].cellules[colonneTri].cle
+		if v.triInverse {
+			return a > b
+		}
+		return a < b
+	})
+
+	v.borner()
+}
+
+func (l ligneTableau) correspond(filtre string) bool {
+	for _, c := range l.cellules {
+		if strings.Contains(strings.ToLower(c.texte), filtre) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *vueTableau) borner() {
+	if v.curseur >= len(v.visibles) {
+		v.curseur = len(v.visibles) - 1
+	}
+	if v.curseur < 0 {
+		v.curseur = 0
+	}
+}
+
+func (v *vueTableau) idSelectionne() (int, bool) {
+	if v.curseur < 0 || v.curseur >= len(v.visibles) {
+		return 0, false
+	}
+	return v.lignes[v.visibles[v.curseur]].id, true
+}
+
+func (v *vueTableau) deplacer(delta int) {
+	v.curseur += delta
+	v.borner()
+}
+
+// changerTri passe le tri sur la colonne voisine, par ordre croissant
+func (v *vueTableau) changerTri(delta int) {
+	v.triColonne = (v.triColonne + delta + len(v.colonnes)) % len(v.colonnes)
+	v.triInverse = false
+	v.appliquer()
+}
+
+// inverserTri inverse l'ordre de la colonne de tri
+func (v *vueTableau) inverserTri() {
+	v.triInverse = !v.triInverse
+	v.appliquer()
+}
+
+// largeursColonnes répartit la largeur disponible entre les colonnes
+func (v *vueTableau) largeursColonnes(largeurTotale int) []int {
+	largeurs := make([]int, len(v.colonnes))
+	occupe := len(v.colonnes) - 1 // séparateurs
+	extensibles := 0
+	for i, c := range v.colonnes {
+		largeurs[i] = c.largeur
+		occupe += c.largeur
+		if c.extensible {
+			extensibles++
+		}
+	}
+
+	reste := largeurTotale - occupe
+	if reste > 0 && extensibles > 0 {
+		for i, c := range v.colonnes {
+			if c.extensible {
+				largeurs[i] += reste / extensibles
+			}
+		}
+	}
+	return largeurs
+}
+
+// rendre produit l'en-tête et les lignes visibles du tableau pour la hauteur donnée
+func (v *vueTableau) rendre(largeur, hauteur int) []string {
+	largeurs := v.largeursColonnes(largeur)
+	lignes := make([]string, 0, hauteur)
+
+	// En-tête avec l'indicateur de tri
+	var entete []string
+	for i, c := range v.colonnes {
+		titre := c.titre
+		if i == v.triColonne {
+			if v.triInverse {
+				titre += " ▼"
+			} else {
+				titre += " ▲"
+			}
+		}
+		entete = append(entete, affichage.Ajuster(titre, largeurs[i]))
+	}
+	lignes = append(lignes, colorer(ansiGras, affichage.Tronquer(strings.Join(entete, " "), largeur)))
+
+	hauteurLignes := hauteur - 1
+	if hauteurLignes < 1 {
+		return lignes
+	}
+
+	// Faire défiler pour garder le curseur visible
+	if v.curseur < v.defilement {
+		v.defilement = v.curseur
+	}
+	if v.curseur >= v.defilement+hauteurLignes {
+		v.defilement = v.curseur - hauteurLignes + 1
+	}
+
+	if len(v.visibles) == 0 {
+		return append(lignes, "  (aucun résultat)")
+	}
+
+	for i := v.defilement; i < len(v.visibles) && i < v.defilement+hauteurLignes; i++ {
+		ligne := v.lignes[v.visibles[i]]
+		var cellules []string
+		for j, c := range ligne.cellules {
+			if v.colonnes[j].aDroite {
+				cellules = append(cellules, affichage.AjusterADroite(c.texte, largeurs[j]))
+			} else {
+				cellules = append(cellules, affichage.Ajuster(c.texte, largeurs[j]))
+			}
+		}
+		contenu := affichage.Ajuster(strings.Join(cellules, " "), largeur)
+
+		switch {
+		case i == v.curseur:
+			contenu = colorer(ansiInverse, contenu)
+		case ligne.alerte:
+			contenu = colorer(ansiRouge, contenu)
+		}
+		lignes = append(lignes, contenu)
+	}
+
+	return lignes
+}
@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// tableauLivres construit un tableau de livres : titre, année (date) et emprunts (nombre)
+func tableauLivres() *vueTableau {
+	v := &vueTableau{
+		nom: "Livres",
+		colonnes: []colonne{
+			{titre: "Titre", largeur: 10, extensible: true},
+			{titre: "Parution", largeur: 10},
+			{titre: "Emprunts", largeur: 8, aDroite: true},
+		},
+	}
+	annee := func(a int) cellule { return date(time.Date(a, 1, 1, 0, 0, 0, 0, time.UTC)) }
+	v.remplacerLignes([]ligneTableau{
+		{id: 1, cellules: []cellule{texte("Madame Bovary"), annee(1857), nombre(12)}},
+		{id: 2, cellules: []cellule{texte("l'Étranger"), annee(1942), nombre(9)}},
+		{id: 3, cellules: []cellule{texte("Bel-Ami"), annee(1885), nombre(100)}},
+		{id: 4, cellules: []cellule{texte("Germinal"), annee(1885), nombre(9)}},
+	})
+	return v
+}
+
+// ids retourne les ID des lignes visibles, dans l'ordre affiché
+func (v *vueTableau) ids() []int {
+	var ids []int
+	for _, index := range v.visibles {
+		ids = append(ids, v.lignes[index].id)
+	}
+	return ids
+}
+
+func TestTriDuTableau(t *testing.T) {
+	v := tableauLivres()
+
+	etapes := []struct {
+		nom     string
+		action  func()
+		colonne int
+		inverse bool
+		ids     []int
+	}{
+		// Les textes se trient sans tenir compte de la casse
+		{"titre croissant", func() {}, 0, false, []int{3, 4, 2, 1}},
+		{"titre décroissant", v.inverserTri, 0, true, []int{1, 2, 4, 3}},
+		// Changer de colonne repart en ordre croissant ; les ex æquo gardent l'ordre d'origine
+		{"parution", func() { v.changerTri(1) }, 1, false, []int{1, 3, 4, 2}},
+		// Les nombres se trient sur leur valeur, pas sur leur texte
+		{"emprunts", func() { v.changerTri(1) }, 2, false, []int{2, 4, 1, 3}},
+		{"emprunts décroissant", v.inverserTri, 2, true, []int{3, 1, 2, 4}},
+		{"retour au titre par la droite", func() { v.changerTri(1) }, 0, false, []int{3, 4, 2, 1}},
+		{"dernière colonne par la gauche", func() { v.changerTri(-1) }, 2, false, []int{2, 4, 1, 3}},
+	}
+	for _, e := range etapes {
+		e.action()
+		if v.triColonne != e.colonne || v.triInverse != e.inverse {
+			t.Errorf("%s : tri sur la colonne %d (inverse %v), attendu %d (%v)", e.nom, v.triColonne, v.triInverse, e.colonne, e.inverse)
+		}
+		if ids := v.ids(); !reflect.DeepEqual(ids, e.ids) {
+			t.Errorf("%s : ordre %v, attendu %v", e.nom, ids, e.ids)
+		}
+	}
+}
+
+func TestFiltreDuTableau(t *testing.T) {
+	cas := []struct {
+		filtre string
+		ids    []int
+	}{
+		{"", []int{3, 4, 2, 1}},
+		{"ger", []int{4, 2}}, // dans le titre, sans tenir compte de la casse
+		{"ÉTRANGER", []int{2}},
+		{"01/1885", []int{3, 4}},
+		{"1885-01", nil}, // le filtre porte sur le texte affiché, pas sur la clé de tri
+		{"100", []int{3}},
+		{"zola", nil},
+	}
+	for _, c := range cas {
+		v := tableauLivres()
+		v.filtre = c.filtre
+		v.appliquer()
+		if ids := v.ids(); !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("filtre %q : %v, attendu %v", c.filtre, ids, c.ids)
+		}
+	}
+}
+
+func TestSelectionConserveeAuRechargement(t *testing.T) {
+	v := tableauLivres()
+	v.deplacer(2) // l'Étranger
+	if id, _ := v.idSelectionne(); id != 2 {
+		t.Fatalf("sélection %d, attendu 2", id)
+	}
+
+	// Un nouveau livre s'intercale avant : le curseur suit l'Étranger
+	lignes := append([]ligneTableau{{id: 5, cellules: []cellule{texte("Candide"), date(time.Now()), nombre(0)}}}, v.lignes...)
+	v.remplacerLignes(lignes)
+	if id, _ := v.idSelectionne(); id != 2 || v.curseur != 3 {
+		t.Errorf("sélection %d à la position %d, attendu 2 à la position 3", id, v.curseur)
+	}
+
+	// Le livre sélectionné disparaît : le curseur reste dans les bornes
+	v.remplacerLignes(lignes[:2])
+	if id, ok := v.idSelectionne(); !ok || v.curseur != 1 {
+		t.Errorf("sélection %d (%v) à la position %d, attendu la dernière ligne", id, ok, v.curseur)
+	}
+
+	// Plus aucune ligne : rien n'est sélectionné
+	v.remplacerLignes(nil)
+	if _, ok := v.idSelectionne(); ok || v.curseur != 0 {
+		t.Errorf("sélection sur un tableau vide (curseur %d)", v.curseur)
+	}
+}
+
+func TestBorner(t *testing.T) {
+	cas := []struct {
+		nom      string
+		visibles int
+		curseur  int
+		attendu  int
+	}{
+		{"dans les bornes", 4, 2, 2},
+		{"avant la première ligne", 4, -3, 0},
+		{"après la dernière ligne", 4, 9, 3},
+		{"tableau vide", 0, 5, 0},
+	}
+	for _, c := range cas {
+		v := &vueTableau{visibles: make([]int, c.visibles), curseur: c.curseur}
+		v.borner()
+		if v.curseur != c.attendu {
+			t.Errorf("%s : curseur %d, attendu %d", c.nom, v.curseur, c.attendu)
+		}
+	}
+}
+
+func TestDefilement(t *testing.T) {
+	v := &vueTableau{colonnes: []colonne{{titre: "N°", largeur: 4}}}
+	var lignes []ligneTableau
+	for i := 1; i <= 20; i++ {
+		lignes = append(lignes, ligneTableau{id: i, cellules: []cellule{nombre(i)}})
+	}
+	v.remplacerLignes(lignes)
+
+	// Une hauteur de 6 laisse 5 lignes sous l'en-tête
+	etapes := []struct {
+		nom        string
+		delta      int
+		curseur    int
+		defilement int
+	}{
+		{"début", 0, 0, 0},
+		{"dernière ligne visible", 4, 4, 0},
+		{"une ligne plus bas", 1, 5, 1},
+		{"page suivante", 10, 15, 11},
+		{"au-delà de la fin", 10, 19, 15},
+		{"remonter dans la page", -3, 16, 15},
+		{"remonter au-dessus de la page", -5, 11, 11},
+		{"au-delà du début", -50, 0, 0},
+	}
+	for _, e := range etapes {
+		v.deplacer(e.delta)
+		rendu := v.rendre(20, 6)
+		if v.curseur != e.curseur || v.defilement != e.defilement {
+			t.Errorf("%s : curseur %d, défilement %d, attendu %d et %d", e.nom, v.curseur, v.defilement, e.curseur, e.defilement)
+		}
+		if len(rendu) != 6 {
+			t.Errorf("%s : %d lignes rendues, attendu 6", e.nom, len(rendu))
+		}
+	}
+
+	v.filtre = "aucun"
+	v.appliquer()
+	if rendu := v.rendre(20, 6); len(rendu) != 2 || rendu[1] != "  (aucun résultat)" {
+		t.Errorf("tableau filtré vide : %q", rendu)
+	}
+}
+
+func TestLargeursColonnes(t *testing.T) {
+	colonnes := []colonne{
+		{titre: "Titre", largeur: 10, extensible: true},
+		{titre: "Auteur", largeur: 8, extensible: true},
+		{titre: "ID", largeur: 4},
+	}
+	cas := []struct {
+		nom      string
+		largeur  int
+		largeurs []int
+	}{
+		// 10 + 8 + 4 et deux séparateurs occupent 24 colonnes
+		{"juste la place", 24, []int{10, 8, 4}},
+		{"reste partagé entre les colonnes extensibles", 34, []int{15, 13, 4}},
+		{"reste impair arrondi vers le bas", 29, []int{12, 10, 4}},
+		{"écran trop étroit : largeurs minimales", 12, []int{10, 8, 4}},
+	}
+	for _, c := range cas {
+		v := &vueTableau{colonnes: colonnes}
+		if largeurs := v.largeursColonnes(c.largeur); !reflect.DeepEqual(largeurs, c.largeurs) {
+			t.Errorf("%s : %v, attendu %v", c.nom, largeurs, c.largeurs)
+		}
+	}
+
+	fixes := &vueTableau{colonnes: []colonne{{largeur: 5}, {largeur: 6}}}
+	if largeurs := fixes.largeursColonnes(80); !reflect.DeepEqual(largeurs, []int{5, 6}) {
+		t.Errorf("sans colonne extensible : %v, attendu [5 6]", largeurs)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !linux && !darwin && !freebsd
+
+package tui
+
+import (
+	"fmt"
+	"os"
+)
+
+type etatTerminal struct{}
+
+// EstTerminal retourne toujours faux : l'interface plein écran n'est pas disponible sur ce système
+func EstTerminal(f *os.File) bool {
+	return false
+}
+
+func activerModeBrut(f *os.File) (*etatTerminal, error) {
+	return nil, fmt.Errorf("l'interface plein écran n'est pas disponible sur ce système")
+}
+
+func restaurerTerminal(f *os.File, etat *etatTerminal) error {
+	return nil
+}
+
+func MasquerSaisie(f *os.File) (func(), error) {
+	return nil, fmt.Errorf("le masquage de la saisie n'est pas disponible sur ce système")
+}
+
+func VerrouillerTerminal(f *os.File) (func(), error) {
+	return nil, fmt.Errorf("le verrouillage du terminal n'est pas disponible sur ce système")
+}
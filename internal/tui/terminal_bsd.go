@@ -0,0 +1,13 @@
+//go:build darwin || freebsd
+
+package tui
+
+import "syscall"
+
+const (
+	ioctlLireTermios   = syscall.TIOCGETA
+	ioctlEcrireTermios = syscall.TIOCSETA
+
+	// caractereDesactive neutralise une touche de contrôle (_POSIX_VDISABLE)
+	caractereDesactive = 0xff
+)
@@ -0,0 +1,11 @@
+package tui
+
+import "syscall"
+
+const (
+	ioctlLireTermios   = syscall.TCGETS
+	ioctlEcrireTermios = syscall.TCSETS
+
+	// caractereDesactive neutralise une touche de contrôle (_POSIX_VDISABLE)
+	caractereDesactive = 0
+)
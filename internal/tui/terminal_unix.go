@@ -0,0 +1,103 @@
+//go:build linux || darwin || freebsd
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// etatTerminal conserve les réglages d'origine pour pouvoir les restaurer
+type etatTerminal struct {
+	termios syscall.Termios
+}
+
+func lireTermios(fd uintptr) (syscall.Termios, error) {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlLireTermios, uintptr(unsafe.Pointer(&t)))
+	if errno != 0 {
+		return t, errno
+	}
+	return t, nil
+}
+
+func ecrireTermios(fd uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlEcrireTermios, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// EstTerminal indique si le fichier est un terminal interactif
+func EstTerminal(f *os.File) bool {
+	_, err := lireTermios(f.Fd())
+	return err == nil
+}
+
+// activerModeBrut désactive l'écho et la mise en tampon par ligne
+// pour recevoir chaque touche dès qu'elle est pressée
+func activerModeBrut(f *os.File) (*etatTerminal, error) {
+	origine, err := lireTermios(f.Fd())
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire les réglages du terminal : %v", err)
+	}
+
+	brut := origine
+	brut.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	brut.Oflag &^= syscall.OPOST
+	brut.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	brut.Cflag &^= syscall.CSIZE | syscall.PARENB
+	brut.Cflag |= syscall.CS8
+	brut.Cc[syscall.VMIN] = 1
+	brut.Cc[syscall.VTIME] = 0
+
+	if err := ecrireTermios(f.Fd(), &brut); err != nil {
+		return nil, fmt.Errorf("impossible de passer le terminal en mode brut : %v", err)
+	}
+
+	return &etatTerminal{termios: origine}, nil
+}
+
+func restaurerTerminal(f *os.File, etat *etatTerminal) error {
+	return ecrireTermios(f.Fd(), &etat.termios)
+}
+
+// MasquerSaisie coupe l'écho du terminal sans toucher au mode ligne,
+// pour saisir un code PIN ; la fonction retournée rétablit l'écho
+func MasquerSaisie(f *os.File) (func(), error) {
+	origine, err := lireTermios(f.Fd())
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire les réglages du terminal : %v", err)
+	}
+
+	masque := origine
+	masque.Lflag &^= syscall.ECHO
+	masque.Lflag |= syscall.ECHONL // le retour à la ligne reste affiché
+	if err := ecrireTermios(f.Fd(), &masque); err != nil {
+		return nil, fmt.Errorf("impossible de masquer la saisie : %v", err)
+	}
+
+	return func() { ecrireTermios(f.Fd(), &origine) }, nil
+}
+
+// VerrouillerTerminal empêche de quitter l'application au clavier : Ctrl-C, Ctrl-\ et
+// Ctrl-Z n'envoient plus de signal et Ctrl-D ne ferme plus l'entrée.
+// La fonction retournée rétablit les réglages d'origine.
+func VerrouillerTerminal(f *os.File) (func(), error) {
+	origine, err := lireTermios(f.Fd())
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire les réglages du terminal : %v", err)
+	}
+
+	verrou := origine
+	verrou.Lflag &^= syscall.ISIG
+	verrou.Cc[syscall.VEOF] = caractereDesactive
+	if err := ecrireTermios(f.Fd(), &verrou); err != nil {
+		return nil, fmt.Errorf("impossible de verrouiller le terminal : %v", err)
+	}
+
+	return func() { ecrireTermios(f.Fd(), &origine) }, nil
+}
@@ -0,0 +1,114 @@
+package tui
+
+import "unicode/utf8"
+
+// ========================================
+// DÉCODAGE DU CLAVIER
+// Transforme les octets reçus du terminal en touches
+// ========================================
+
+type codeTouche int
+
+const (
+	toucheCaractere codeTouche = iota
+	toucheEntree
+	toucheEchap
+	toucheTab
+	toucheTabInverse
+	toucheRetourArriere
+	toucheHaut
+	toucheBas
+	toucheGauche
+	toucheDroite
+	touchePageHaut
+	touchePageBas
+	toucheDebut
+	toucheFin
+	toucheSupprimer
+	toucheCtrlC
+	toucheCtrlS
+	toucheInconnue
+)
+
+type touche struct {
+	code codeTouche
+	r    rune // renseigné pour toucheCaractere
+}
+
+// sequencesEchappement associe les séquences ANSI courantes (après ESC) aux touches
+var sequencesEchappement = map[string]codeTouche{
+	"[A": toucheHaut, "[B": toucheBas, "[C": toucheDroite, "[D": toucheGauche,
+	"OA": toucheHaut, "OB": toucheBas, "OC": toucheDroite, "OD": toucheGauche,
+	"[H": toucheDebut, "[F": toucheFin, "OH": toucheDebut, "OF": toucheFin,
+	"[1~": toucheDebut, "[4~": toucheFin, "[7~": toucheDebut, "[8~": toucheFin,
+	"[5~": touchePageHaut, "[6~": touchePageBas, "[3~": toucheSupprimer,
+	"[Z": toucheTabInverse,
+}
+
+// decoderTouches découpe un bloc d'octets lu sur le terminal en touches.
+// Le terminal envoie une séquence d'échappement complète en une seule écriture,
+// un ESC isolé en fin de bloc est donc la touche Échap.
+func decoderTouches(octets []byte) []touche {
+	var touches []touche
+
+	for len(octets) > 0 {
+		b := octets[0]
+
+		switch {
+		case b == 0x1b:
+			t, taille := decoderEchappement(octets)
+			touches = append(touches, t)
+			octets = octets[taille:]
+			continue
+		case b == '\r' || b == '\n':
+			touches = append(touches, touche{code: toucheEntree})
+		case b == '\t':
+			touches = append(touches, touche{code: toucheTab})
+		case b == 0x7f || b == 0x08:
+			touches = append(touches, touche{code: toucheRetourArriere})
+		case b == 0x03:
+			touches = append(touches, touche{code: toucheCtrlC})
+		case b == 0x13:
+			touches = append(touches, touche{code: toucheCtrlS})
+		case b < 0x20:
+			touches = append(touches, touche{code: toucheInconnue})
+		default:
+			r, taille := utf8.DecodeRune(octets)
+			touches = append(touches, touche{code: toucheCaractere, r: r})
+			octets = octets[taille:]
+			continue
+		}
+
+		octets = octets[1:]
+	}
+
+	return touches
+}
+
+func decoderEchappement(octets []byte) (touche, int) {
+	if len(octets) == 1 {
+		return touche{code: toucheEchap}, 1
+	}
+
+	// Chercher la plus longue séquence connue (au plus 4 octets après ESC)
+	for longueur := 4; longueur >= 2; longueur-- {
+		if len(octets) < longueur+1 {
+			continue
+		}
+		if code, ok := sequencesEchappement[string(octets[1:longueur+1])]; ok {
+			return touche{code: code}, longueur + 1
+		}
+	}
+
+	// Séquence inconnue : l'ignorer jusqu'à sa lettre finale
+	if octets[1] == '[' || octets[1] == 'O' {
+		for i := 2; i < len(octets); i++ {
+			if octets[i] >= 0x40 && octets[i] <= 0x7e {
+				return touche{code: toucheInconnue}, i + 1
+			}
+		}
+		return touche{code: toucheInconnue}, len(octets)
+	}
+
+	return touche{code: toucheEchap}, 1
+}
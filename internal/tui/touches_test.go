@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecoderTouches(t *testing.T) {
+	cas := []struct {
+		nom     string
+		octets  string
+		touches []touche
+	}{
+		{"caractères", "ab", []touche{{code: toucheCaractere, r: 'a'}, {code: toucheCaractere, r: 'b'}}},
+		{"caractère accentué sur deux octets", "é", []touche{{code: toucheCaractere, r: 'é'}}},
+		{"emoji sur quatre octets", "📚", []touche{{code: toucheCaractere, r: '📚'}}},
+		{"entrée CR et LF", "\r\n", []touche{{code: toucheEntree}, {code: toucheEntree}}},
+		{"tabulation et retour arrière", "\t\x7f\x08", []touche{{code: toucheTab}, {code: toucheRetourArriere}, {code: toucheRetourArriere}}},
+		{"Ctrl-C et Ctrl-S", "\x03\x13", []touche{{code: toucheCtrlC}, {code: toucheCtrlS}}},
+		{"autre contrôle", "\x01", []touche{{code: toucheInconnue}}},
+		{"flèches CSI", "\x1b[A\x1b[B\x1b[C\x1b[D", []touche{{code: toucheHaut}, {code: toucheBas}, {code: toucheDroite}, {code: toucheGauche}}},
+		{"flèches SS3", "\x1bOA\x1bOD", []touche{{code: toucheHaut}, {code: toucheGauche}}},
+		{"séquences à tilde", "\x1b[5~\x1b[6~\x1b[3~\x1b[1~\x1b[4~", []touche{{code: touchePageHaut}, {code: touchePageBas}, {code: toucheSupprimer}, {code: toucheDebut}, {code: toucheFin}}},
+		{"tabulation inverse", "\x1b[Z", []touche{{code: toucheTabInverse}}},
+		{"Échap isolé en fin de bloc", "\x1b", []touche{{code: toucheEchap}}},
+		{"Échap suivi d'un caractère", "\x1bx", []touche{{code: toucheEchap}, {code: toucheCaractere, r: 'x'}}},
+		{"séquence inconnue ignorée jusqu'à sa lettre finale", "\x1b[1;5Cz", []touche{{code: toucheInconnue}, {code: toucheCaractere, r: 'z'}}},
+		{"séquence inconnue tronquée", "\x1b[12", []touche{{code: toucheInconnue}}},
+		{"touches collées après une séquence", "\x1b[Bq", []touche{{code: toucheBas}, {code: toucheCaractere, r: 'q'}}},
+	}
+
+	for _, c := range cas {
+		t.Run(c.nom, func(t *testing.T) {
+			if obtenu := decoderTouches([]byte(c.octets)); !reflect.DeepEqual(obtenu, c.touches) {
+				t.Errorf("decoderTouches(%q) = %+v, attendu %+v", c.octets, obtenu, c.touches)
+			}
+		})
+	}
+}
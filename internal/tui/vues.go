@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"github.com/felver-dev/bookstore/internal/models"
+)
+
+// ========================================
+// CONTENU DES VUES LIVRES, MEMBRES ET EMPRUNTS
+// ========================================
+
+const (
+	vueLivres = iota
+	vueMembres
+	vueEmprunts
+)
+
+func nouvellesVues() []*vueTableau {
+	return []*vueTableau{
+		{
+			nom: "Livres",
+			colonnes: []colonne{
+				{titre: "ID", largeur: 5, aDroite: true},
+				{titre: "Titre", largeur: 16, extensible: true},
+				{titre: "Auteur", largeur: 14, extensible: true},
+				{titre: "Genre", largeur: 12},
+				{titre: "Année", largeur: 5},
+				{titre: "Statut", largeur: 10},
+				{titre: "Emprunts", largeur: 8, aDroite: true},
+			},
+		},
+		{
+			nom: "Membres",
+			colonnes: []colonne{
+				{titre: "ID", largeur: 5, aDroite: true},
+				{titre: "Nom", largeur: 14, extensible: true},
+				{titre: "Email", largeur: 16, extensible: true},
+				{titre: "Téléphone", largeur: 12},
+				{titre: "En cours", largeur: 8, aDroite: true},
+				{titre: "Total", largeur: 5, aDroite: true},
+				{titre: "Statut", largeur: 8},
+			},
+		},
+		{
+			nom: "Emprunts",
+			colonnes: []colonne{
+				{titre: "ID", largeur: 5, aDroite: true},
+				{titre: "Livre", largeur: 16, extensible: true},
+				{titre: "Membre", largeur: 14, extensible: true},
+				{titre: "Emprunté le", largeur: 11},
+				{titre: "À rendre le", largeur: 11},
+				{titre: "Statut", largeur: 9},
+			},
+		},
+	}
+}
+
+func lignesLivres(livres []models.Livre) []ligneTableau {
+	lignes := make([]ligneTableau, 0, len(livres))
+	for _, livre := range livres {
+		statut := "Disponible"
+		if !livre.EstDisponible() {
+			statut = "Emprunté"
+		}
+		lignes = append(lignes, ligneTableau{
+			id: livre.ID,
+			cellules: []cellule{
+				nombre(livre.ID),
+				texte(livre.Titre),
+				texte(livre.Auteur),
+				texte(livre.Genre),
+				nombre(livre.DatePublication.Year()),
+				texte(statut),
+				nombre(livre.NombreEmprunts),
+			},
+		})
+	}
+	return lignes
+}
+
+func lignesMembres(membres []models.Membre, retards map[int]bool) []ligneTableau {
+	lignes := make([]ligneTableau, 0, len(membres))
+	for _, membre := range membres {
+		statut := "Actif"
+		if !membre.Actif {
+			statut = "Suspendu"
+		}
+		lignes = append(lignes, ligneTableau{
+			id: membre.ID,
+			cellules: []cellule{
+				nombre(membre.ID),
+				texte(membre.Nom),
+				texte(membre.Email),
+				texte(membre.Telephone),
+				nombre(membre.EmpruntsActifs),
+				nombre(membre.NombreEmprunts),
+				texte(statut),
+			},
+			alerte: retards[membre.ID] || !membre.Actif,
+		})
+	}
+	return lignes
+}
+
+func lignesEmprunts(emprunts []models.Emprunt) []ligneTableau {
+	lignes := make([]ligneTableau, 0, len(emprunts))
+	for _, emprunt := range emprunts {
+		statut := "En cours"
+		switch emprunt.Statut {
+		case models.STATUT_RENDU:
+			statut = "Rendu"
+		case models.STATUT_EN_RETARD:
+			statut = "En retard"
+		case models.STATUT_PERDU:
+			statut = "Perdu"
+		}
+		lignes = append(lignes, ligneTableau{
+			id: emprunt.ID,
+			cellules: []cellule{
+				nombre(emprunt.ID),
+				texte(emprunt.TitreLivre),
+				texte(emprunt.NomMembre),
+				date(emprunt.DateEmprunt),
+				date(emprunt.DateRetourPrevu),
+				texte(statut),
+			},
+			alerte: emprunt.Statut == models.STATUT_EN_RETARD,
+		})
+	}
+	return lignes
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func ValiderEmail(email string) bool {
@@ -64,7 +65,19 @@ func validerISBN13(isbn string) bool {
 	return true
 }
 
-func ValiderDatePublication(dateStr string) (time.Time, error) {
+// Validateur regroupe les règles de validation qui dépendent de la configuration
+// (les genres sont validés par la taxonomie, voir services.GestionnaireGenres)
+type Validateur struct {
+	anneeMin int
+}
+
+func NouveauValidateur(anneeMin int) *Validateur {
+	return &Validateur{
+		anneeMin: anneeMin,
+	}
+}
+
+func (v *Validateur) ValiderDatePublication(dateStr string) (time.Time, error) {
 
 	date, err := time.Parse("02/01/2006", dateStr)
 	if err != nil {
@@ -75,7 +88,7 @@ func ValiderDatePublication(dateStr string) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("la date de publication ne peut pas être dans le future")
 	}
 
-	anneeMin := time.Date(1440, 1, 1, 0, 0, 0, 0, time.UTC)
+	anneeMin := time.Date(v.anneeMin, 1, 1, 0, 0, 0, 0, time.UTC)
 	if date.Before(anneeMin) {
 		return time.Time{}, fmt.Errorf("la date de publication semble trop ancienne")
 	}
@@ -83,31 +96,48 @@ func ValiderDatePublication(dateStr string) (time.Time, error) {
 	return date, nil
 }
 
-func ValiderGenre(genre string) bool {
-	genresValides := []string{
-		"Roman", "Science-fiction", "Fantasy", "Policier", "Thriller",
-		"Romance", "Historique", "Biographie", "Essai", "Poésie",
-		"Théâtre", "Bande dessinée", "Manga", "Jeunesse", "Documentaire",
-		"Guide pratique", "Cuisine", "Art", "Sport", "Autre",
-	}
-
-	for _, genreValide := range genresValides {
-		if strings.EqualFold(genre, genreValide) {
-			return true
-		}
+// ValiderNom accepte les lettres de toutes les écritures (avec leurs diacritiques),
+// les espaces, traits d'union, apostrophes et points ("J.R.R. Tolkien", "Ngũgĩ wa Thiong'o")
+func ValiderNom(nom string) bool {
+	if utf8.RuneCountInString(strings.TrimSpace(nom)) < 2 {
+		return false
 	}
+	re := regexp.MustCompile(`^[\p{L}\p{M}][\p{L}\p{M}\s\-'’.]*$`)
+	return re.MatchString(strings.TrimSpace(nom))
+}
 
-	return false
+func ValiderTitre(titre string) bool {
+	return len(strings.TrimSpace(titre)) >= 1
 }
 
-func ValiderNom(nom string) bool {
-	if len(strings.TrimSpace(nom)) < 2 {
+// ValiderCodeBarres accepte les codes imprimables en Code 128 (ASCII visible, sans espace)
+func ValiderCodeBarres(code string) bool {
+	if len(code) < 3 || len(code) > 40 {
 		return false
 	}
-	re := regexp.MustCompile(`^[a-zA-ZÀ-ÿ\s\-']+$`)
-	return re.MatchString(nom)
+	re := regexp.MustCompile(`^[!-~]+$`)
+	return re.MatchString(code)
 }
 
-func ValiderTitre(titre string) bool {
-	return len(strings.TrimSpace(titre)) >= 1
+// ValiderPIN accepte un code PIN de 4 à 8 chiffres
+func ValiderPIN(pin string) bool {
+	re := regexp.MustCompile(`^[0-9]{4,8}$`)
+	return re.MatchString(pin)
+}
+
+// ValiderMotDePasse accepte un mot de passe de 8 à 128 caractères
+func ValiderMotDePasse(motDePasse string) bool {
+	n := utf8.RuneCountInString(motDePasse)
+	return n >= 8 && n <= 128
+}
+
+// ValiderCodeSuccursale accepte un code de 2 à 8 lettres ou chiffres ("CTR", "NORD2")
+func ValiderCodeSuccursale(code string) bool {
+	re := regexp.MustCompile(`^[A-Za-z0-9]{2,8}$`)
+	return re.MatchString(strings.TrimSpace(code))
+}
+
+// ValiderCoordonnees vérifie une position GPS en degrés décimaux
+func ValiderCoordonnees(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
 }